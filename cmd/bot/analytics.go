@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newAnalyticsCommand(opts *globalOptions) *cobra.Command {
+	var period, from, to, output string
+
+	cmd := &cobra.Command{
+		Use:   "analytics",
+		Short: "Summarize stored mentions over a quarter, release cycle or custom period",
+		Long: `Aggregate the stored mentions of a period by month, source and topic for planning
+reviews, without collecting new mentions or sending notifications. Name a period with
+--period (2024-Q3, 2024-07, 2024, quarter, last-quarter, release:<name> or
+release:latest) or give --from and optionally --to. Only the storage settings are
+required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			if !cfg.StorageConfigured() {
+				return fmt.Errorf("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING is required")
+			}
+			if !opts.debug {
+				logrus.SetLevel(logrus.WarnLevel)
+			}
+
+			store, err := newStorage(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			service := monitoring.NewService(cfg, store, nil)
+
+			resolved, err := parseAnalyticsPeriod(service, period, from, to, time.Now())
+			if err != nil {
+				return err
+			}
+			report, err := service.Analytics(resolved)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("📊 Analytics: %s (%s to %s)\n", report.Period.Name,
+				report.Period.From.Format("2006-01-02"), report.Period.To.Add(-time.Second).Format("2006-01-02"))
+			fmt.Println(strings.Repeat("-", 46))
+			fmt.Printf("%d mentions", report.TotalMentions)
+			if report.SentimentScore != nil {
+				fmt.Printf(", sentiment score %+.1f", *report.SentimentScore)
+			}
+			fmt.Println()
+
+			fmt.Println("\n📅 By month:")
+			for _, month := range report.Months {
+				fmt.Printf("  • %s: %d (%d positive, %d negative)\n", month.Start.Format("2006-01"),
+					month.Mentions, month.Sentiment["positive"], month.Sentiment["negative"])
+			}
+
+			fmt.Println("\n📈 By source:")
+			for _, source := range report.Sources {
+				fmt.Printf("  • %s: %d (%.1f%%)\n", source.Name, source.Mentions, source.Share)
+			}
+
+			if len(report.Topics) > 0 {
+				fmt.Println("\n🏷️  Topics:")
+				for _, topic := range report.Topics {
+					fmt.Printf("  • %s (%s): %d\n", topic.Name, topic.Kind, topic.Mentions)
+				}
+			}
+
+			if output != "" {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(output, data, 0644); err != nil {
+					return fmt.Errorf("failed to save analytics: %w", err)
+				}
+				fmt.Printf("\n💾 Saved to: %s\n", output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&period, "period", "", "Named period, e.g. 2024-Q3, last-quarter or release:latest")
+	cmd.Flags().StringVar(&from, "from", "", "Start of a custom period, an RFC 3339 time or YYYY-MM-DD date")
+	cmd.Flags().StringVar(&to, "to", "", "End of a custom period, inclusive for a date (default: now)")
+	cmd.Flags().StringVar(&output, "output", "", "Also save the full analytics as JSON to this file")
+	return cmd
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/appconfig"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// appConfigTimeout bounds each load from App Configuration, Key Vault references included
+const appConfigTimeout = 30 * time.Second
+
+// loadAppConfiguration loads settings and feature flags from the App Configuration store at
+// APP_CONFIG_ENDPOINT, if one is set, so every command reads them in preference to the
+// environment. Configuration errors are left to the commands to report.
+func loadAppConfiguration() error {
+	cfg, err := config.Parse()
+	if err != nil || cfg.AppConfigEndpoint == "" {
+		return nil
+	}
+
+	client, err := appconfig.NewClient(cfg.AppConfigEndpoint)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), appConfigTimeout)
+	defer cancel()
+
+	settings, err := client.Load(ctx, cfg.AppConfigKeyPrefix, cfg.AppConfigLabel)
+	if err != nil {
+		return fmt.Errorf("failed to load App Configuration from %s: %w", cfg.AppConfigEndpoint, err)
+	}
+	config.SetRemoteSettings(settings)
+	logrus.Debugf("Loaded %d settings and %d feature flags from App Configuration", len(settings.Values), len(settings.FeatureFlags))
+	return nil
+}
+
+// refreshAppConfiguration reloads App Configuration every APP_CONFIG_REFRESH_INTERVAL until ctx
+// is done. When settings or feature flags change, the current deployment's schedulers stop,
+// its in-flight runs finish, and a deployment built from the new configuration takes over.
+// Changes that fail validation are logged and the current configuration stays.
+func refreshAppConfiguration(ctx context.Context, opts *globalOptions, cfg *config.Config, handler *deploymentHandler) {
+	client, err := appconfig.NewClient(cfg.AppConfigEndpoint)
+	if err != nil {
+		logrus.Errorf("App Configuration refresh disabled: %v", err)
+		return
+	}
+
+	last := config.CurrentRemoteSettings()
+	ticker := time.NewTicker(cfg.AppConfigRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		loadCtx, cancel := context.WithTimeout(ctx, appConfigTimeout)
+		settings, err := client.Load(loadCtx, cfg.AppConfigKeyPrefix, cfg.AppConfigLabel)
+		cancel()
+		if err != nil {
+			logrus.Warnf("Failed to refresh App Configuration, keeping the current settings: %v", err)
+			continue
+		}
+		if reflect.DeepEqual(settings, last) {
+			continue
+		}
+
+		if err := applyRemoteSettings(ctx, opts, settings, handler); err != nil {
+			logrus.Errorf("Not applying App Configuration changes: %v", err)
+			continue
+		}
+		last = settings
+	}
+}
+
+// applyRemoteSettings replaces the current deployment with one built from settings. An error
+// means the settings were not applied and the previous settings are back in effect.
+func applyRemoteSettings(ctx context.Context, opts *globalOptions, settings *config.RemoteSettings, handler *deploymentHandler) error {
+	handler.replaceMu.Lock()
+	defer handler.replaceMu.Unlock()
+
+	previous := config.CurrentRemoteSettings()
+	config.SetRemoteSettings(settings)
+	if err := handler.replace(ctx, opts, "App Configuration"); err != nil {
+		config.SetRemoteSettings(previous)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newEncryptStorageCommand(opts *globalOptions) *cobra.Command {
+	var prefix string
+
+	cmd := &cobra.Command{
+		Use:   "encrypt-storage",
+		Short: "Encrypt blobs stored before storage encryption was enabled",
+		Long: `Rewrite the blobs in Azure Storage that are not encrypted yet with the data key
+wrapped by STORAGE_ENCRYPTION_KEY_ID. Blobs written after encryption was enabled are
+encrypted already and skipped, as are Parquet exports. Only the storage settings and
+STORAGE_ENCRYPTION_KEY_ID are required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			if !cfg.StorageConfigured() {
+				return fmt.Errorf("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING is required")
+			}
+			if cfg.StorageEncryptionKeyID == "" {
+				return fmt.Errorf("STORAGE_ENCRYPTION_KEY_ID is required")
+			}
+
+			store, err := newStorage(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			encrypted, ok := store.(*storage.EncryptedStorage)
+			if !ok {
+				return fmt.Errorf("storage is not encrypted")
+			}
+
+			count, err := encrypted.EncryptExisting(prefix)
+			if err != nil {
+				return fmt.Errorf("failed after encrypting %d blobs: %w", count, err)
+			}
+			logrus.Infof("Encrypted %d blobs", count)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&prefix, "prefix", "", "Only encrypt blobs under this prefix, e.g. mentions/")
+	return cmd
+}
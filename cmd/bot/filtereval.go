@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newFilterEvalCommand(opts *globalOptions) *cobra.Command {
+	var minPrecision, minRecall float64
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "filter-eval",
+		Short: "Replay the labeled filter corpus against the current filters",
+		Long: `Replay the mentions labeled through /api/filter/corpus against the blocklist, context
+filter and spam heuristics as currently configured, and report precision and recall
+with every mention the filters now get wrong. Run it with a proposed configuration
+(for example CONTEXT_THRESHOLD or KEYWORD_PATTERNS in --env-file) before deploying it.
+The exit code is non-zero when precision or recall is below --min-precision or
+--min-recall. Only the storage settings are required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			if !cfg.StorageConfigured() {
+				return fmt.Errorf("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING is required")
+			}
+			if !opts.debug {
+				logrus.SetLevel(logrus.WarnLevel)
+			}
+
+			store, err := newStorage(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			evaluation, err := monitoring.NewService(cfg, store, nil).EvaluateFilters()
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(evaluation); err != nil {
+					return err
+				}
+			} else {
+				printFilterEvaluation(evaluation)
+			}
+
+			if evaluation.Labeled == 0 {
+				return nil
+			}
+			if evaluation.Precision < minPrecision || evaluation.Recall < minRecall {
+				return fmt.Errorf("precision %.3f and recall %.3f, required at least %.3f and %.3f",
+					evaluation.Precision, evaluation.Recall, minPrecision, minRecall)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Float64Var(&minPrecision, "min-precision", 0, "Fail when precision is below this value (0-1)")
+	cmd.Flags().Float64Var(&minRecall, "min-recall", 0, "Fail when recall is below this value (0-1)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the evaluation as JSON")
+	return cmd
+}
+
+func printFilterEvaluation(evaluation *monitoring.FilterEvaluation) {
+	fmt.Printf("🧪 Filter evaluation: %d labeled mentions\n", evaluation.Labeled)
+	fmt.Println(strings.Repeat("-", 46))
+	if evaluation.Labeled == 0 {
+		fmt.Println("No labeled mentions yet; label some with POST /api/filter/corpus")
+		return
+	}
+
+	fmt.Printf("Precision: %.3f  Recall: %.3f\n", evaluation.Precision, evaluation.Recall)
+	fmt.Printf("Kept:    %d relevant, %d false positives\n", evaluation.TruePositives, evaluation.FalsePositives)
+	fmt.Printf("Dropped: %d irrelevant, %d false negatives\n", evaluation.TrueNegatives, evaluation.FalseNegatives)
+
+	if len(evaluation.Misclassified) > 0 {
+		fmt.Println("\n❌ Misclassified:")
+		for _, result := range evaluation.Misclassified {
+			verdict := "false positive"
+			if result.Relevant {
+				verdict = fmt.Sprintf("false negative (dropped by %s, relevance %.2f)", result.Stage, result.Relevance)
+			}
+			fmt.Printf("  • [%s] %s: %s\n", result.Source, result.Title, verdict)
+			fmt.Printf("    %s\n", result.URL)
+			if result.Note != "" {
+				fmt.Printf("    Note: %s\n", result.Note)
+			}
+		}
+	}
+}
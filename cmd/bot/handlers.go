@@ -0,0 +1,1319 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/notifications"
+	"github.com/azure/aks-mentions-bot/internal/reports"
+	"github.com/azure/aks-mentions-bot/internal/scheduler"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// metricsHandler serves the metrics as JSON, or in the Prometheus text format to scrapers,
+// which accept text/plain, and with ?format=prometheus
+func metricsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "prometheus" || strings.Contains(r.Header.Get("Accept"), "text/plain") {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(monitoringService.PrometheusMetrics()))
+			return
+		}
+
+		metrics := monitoringService.GetMetrics()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(metrics))
+	}
+}
+
+func triggerHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if monitoringService.MonitoringRunning() {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": monitoring.ErrRunInProgress.Error()})
+			return
+		}
+
+		go func() {
+			if err := monitoringService.RunMonitoring(); err != nil {
+				logrus.Errorf("Manual monitoring trigger failed: %v", err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"message":"Monitoring triggered successfully"}`))
+	}
+}
+
+func sourcesHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, monitoringService.GetSourceStatuses())
+	}
+}
+
+func sourceTestHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		keyword := r.URL.Query().Get("keyword")
+
+		// Probes can outlast the server-wide write timeout
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(monitoring.SourceProbeTimeout + 5*time.Second)); err != nil {
+			logrus.Debugf("Could not extend write deadline for source probe: %v", err)
+		}
+
+		result, err := monitoringService.TestSource(r.Context(), name, keyword)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, monitoring.ErrUnknownSource) {
+				status = http.StatusNotFound
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func previewHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		keywords := strings.Split(query.Get("keywords"), ",")
+
+		var window time.Duration
+		if raw := query.Get("window"); raw != "" {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil || parsed <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "window must be a positive duration, e.g. 48h"})
+				return
+			}
+			window = parsed
+		}
+
+		samples := 0
+		if raw := query.Get("samples"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "samples must be a positive integer"})
+				return
+			}
+			samples = parsed
+		}
+
+		// Previews search every source and can outlast the server-wide write timeout
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(monitoring.PreviewTimeout + 5*time.Second)); err != nil {
+			logrus.Debugf("Could not extend write deadline for keyword preview: %v", err)
+		}
+
+		preview, err := monitoringService.PreviewKeywords(r.Context(), keywords, window, samples)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, preview)
+	}
+}
+
+// searchHandler queries the stored mentions: a full-text query q, a curated tag or a since
+// time (RFC 3339 time, date or duration ago), narrowed by sentiment and source
+func searchHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		query := monitoring.MentionQuery{
+			Text:      params.Get("q"),
+			Tag:       params.Get("tag"),
+			Sentiment: params.Get("sentiment"),
+			Source:    params.Get("source"),
+			Sort:      params.Get("sort"),
+		}
+		if query.Text == "" && query.Tag == "" && params.Get("since") == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing query parameter q, tag or since"})
+			return
+		}
+
+		if raw := params.Get("since"); raw != "" {
+			since, err := parseSince(raw, time.Now())
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			query.Since = since
+		}
+
+		if raw := params.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+				return
+			}
+			query.Limit = parsed
+		}
+
+		result, err := monitoringService.QueryMentions(query)
+		if err != nil {
+			writeJSON(w, tagErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"query":   query.Text,
+			"tag":     query.Tag,
+			"total":   result.Total,
+			"count":   len(result.Hits),
+			"results": result.Hits,
+		})
+	}
+}
+
+// exportHandler streams a page of stored mentions as NDJSON, oldest first. The cursor of the
+// next page is sent in the X-Next-Cursor header and a Link header before the mentions.
+func exportHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		query := monitoring.ExportQuery{
+			Source: params.Get("source"),
+			Cursor: params.Get("cursor"),
+		}
+
+		now := time.Now()
+		if raw := params.Get("from"); raw != "" {
+			from, err := parseSince(raw, now)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "from: " + err.Error()})
+				return
+			}
+			query.From = from
+		}
+		if raw := params.Get("to"); raw != "" {
+			to, err := parseAnalyticsTime(raw, true)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "to must be an RFC 3339 time or a YYYY-MM-DD date"})
+				return
+			}
+			query.To = to
+		}
+		if raw := params.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+				return
+			}
+			query.Limit = parsed
+		}
+
+		export, err := monitoringService.PlanExport(query)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrInvalidCursor) {
+				status = http.StatusBadRequest
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+
+		// Large pages outlive the server's write timeout
+		controller := http.NewResponseController(w)
+		if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+			logrus.Warnf("Failed to clear the write deadline of a mention export: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Export-Count", strconv.Itoa(export.Count))
+		if export.NextCursor != "" {
+			next := r.URL.Query()
+			next.Set("cursor", export.NextCursor)
+			w.Header().Set("X-Next-Cursor", export.NextCursor)
+			w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+		}
+		w.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(w)
+		written := 0
+		err = export.Each(func(mention models.Mention) error {
+			if err := encoder.Encode(mention); err != nil {
+				return err
+			}
+			if written++; written%exportFlushEvery == 0 {
+				return controller.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			logrus.Warnf("Mention export stopped after %d mentions: %v", written, err)
+		}
+	}
+}
+
+// exportFlushEvery is how many exported mentions are written between flushes
+const exportFlushEvery = 500
+
+// defaultRejectedSince is how far back /api/admin/mentions/rejected looks without a since parameter
+const defaultRejectedSince = 7 * 24 * time.Hour
+
+// rejectedMentionsHandler lists the mentions the context filter or spam detection dropped, with the decision
+// behind each. since is an RFC 3339 time, a date or a duration ago such as "48h".
+func rejectedMentionsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since := time.Now().Add(-defaultRejectedSince)
+		if raw := r.URL.Query().Get("since"); raw != "" {
+			parsed, err := parseSince(raw, time.Now())
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			since = parsed
+		}
+
+		limit := 0
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+				return
+			}
+			limit = parsed
+		}
+
+		rejected, err := monitoringService.RejectedMentions(since, limit)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"since":   since.UTC().Format(time.RFC3339),
+			"count":   len(rejected),
+			"results": rejected,
+		})
+	}
+}
+
+// sentimentTrendHandler returns the rolling community sentiment score with its daily history
+func sentimentTrendHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		trend, err := monitoringService.SentimentTrend()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if trend == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no sentiment score has been recorded yet"})
+			return
+		}
+		writeJSON(w, http.StatusOK, trend)
+	}
+}
+
+// timelineHandler returns mention and sentiment counts per day, week or month for charting,
+// covering the last 90 days unless since says otherwise
+func timelineHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		bucket := params.Get("bucket")
+		if bucket == "" {
+			bucket = monitoring.BucketDay
+		}
+
+		raw := params.Get("since")
+		if raw == "" {
+			raw = "90d"
+		}
+		since, err := parseSince(raw, time.Now())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		timeline, err := monitoringService.MentionTimeline(bucket, since)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrInvalidBucket) {
+				status = http.StatusBadRequest
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, timeline)
+	}
+}
+
+// runCostsHandler returns the paid-API usage and estimated cost of recent runs, with totals
+// for the last 30 days
+func runCostsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 0
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+				return
+			}
+			limit = parsed
+		}
+
+		summary, err := monitoringService.RunCosts(limit)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, summary)
+	}
+}
+
+// parseSince accepts an RFC 3339 time, a YYYY-MM-DD date (UTC) or a duration before now,
+// including a number of days such as 90d
+func parseSince(raw string, now time.Time) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339, raw); err == nil {
+		return since, nil
+	}
+	if since, err := time.Parse("2006-01-02", raw); err == nil {
+		return since, nil
+	}
+	if ago, err := time.ParseDuration(raw); err == nil && ago > 0 {
+		return now.Add(-ago), nil
+	}
+	if days, err := strconv.Atoi(strings.TrimSuffix(raw, "d")); err == nil && days > 0 && strings.HasSuffix(raw, "d") {
+		return now.AddDate(0, 0, -days), nil
+	}
+	return time.Time{}, fmt.Errorf("since must be an RFC 3339 time, a YYYY-MM-DD date or a duration such as 48h or 90d")
+}
+
+// analyticsHandler returns the analytics report of a named period, or of a from..to range
+func analyticsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		period, err := parseAnalyticsPeriod(monitoringService, params.Get("period"), params.Get("from"), params.Get("to"), time.Now())
+		if err == nil {
+			var report *monitoring.AnalyticsReport
+			if report, err = monitoringService.Analytics(period); err == nil {
+				writeJSON(w, http.StatusOK, report)
+				return
+			}
+		}
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, monitoring.ErrInvalidPeriod) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+	}
+}
+
+// parseAnalyticsPeriod resolves a named period, or a from..to range where each end is an
+// RFC 3339 time or a YYYY-MM-DD date; a date as to includes that whole day and to defaults
+// to now
+func parseAnalyticsPeriod(monitoringService *monitoring.Service, name, from, to string, now time.Time) (monitoring.AnalyticsPeriod, error) {
+	if name != "" {
+		if from != "" || to != "" {
+			return monitoring.AnalyticsPeriod{}, fmt.Errorf("%w: give either a period or from and to", monitoring.ErrInvalidPeriod)
+		}
+		return monitoringService.ResolvePeriod(name, now)
+	}
+	if from == "" {
+		return monitoring.AnalyticsPeriod{}, fmt.Errorf("%w: give a period such as 2024-Q3, or from and to", monitoring.ErrInvalidPeriod)
+	}
+
+	period := monitoring.AnalyticsPeriod{To: now}
+	var err error
+	if period.From, err = parseAnalyticsTime(from, false); err != nil {
+		return period, err
+	}
+	if to != "" {
+		if period.To, err = parseAnalyticsTime(to, true); err != nil {
+			return period, err
+		}
+	}
+	return period, nil
+}
+
+func parseAnalyticsTime(raw string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if day, err := time.Parse("2006-01-02", raw); err == nil {
+		if end {
+			return day.AddDate(0, 0, 1), nil
+		}
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %q is not an RFC 3339 time or YYYY-MM-DD date", monitoring.ErrInvalidPeriod, raw)
+}
+
+// reportHandler serves a stored HTML report. The page is self-contained, so the policy only
+// allows inline styles and data URI images; framing is allowed so dashboards can embed it.
+func reportHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := monitoringService.ReportArtifact(mux.Vars(r)["id"])
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrUnknownReport) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'")
+		w.Write(page)
+	}
+}
+
+// mentionPageHandler serves the permalink page of a stored mention
+func mentionPageHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := monitoringService.MentionPage(mux.Vars(r)["id"])
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrUnknownMention) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		w.Write(page)
+	}
+}
+
+// clickHandler records a click on a mention link in a notification and redirects to the post
+func clickHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target, err := monitoringService.RecordClick(mux.Vars(r)["id"], clickClient(r), r.UserAgent())
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrUnknownMention) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, target, http.StatusFound)
+	}
+}
+
+// clickClient identifies the reader of a click by address and user agent, so readers behind
+// the same proxy or NAT are told apart by their browser
+func clickClient(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host + " " + r.UserAgent()
+}
+
+// clickStatsHandler lists the clicks on mention links, most clicked mentions first
+func clickStatsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 0
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+				return
+			}
+			limit = parsed
+		}
+
+		stats, err := monitoringService.ClickStats(limit)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
+
+// reportCompareHandler returns the differences between reports a and b. Without aliases,
+// both must be report IDs, as on the public route that comparison links in notifications
+// open; with them, a and b default to the previous and latest reports.
+func reportCompareHandler(monitoringService *monitoring.Service, aliases bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a, b := r.URL.Query().Get("a"), r.URL.Query().Get("b")
+		if !aliases {
+			for _, id := range []string{a, b} {
+				if id == "" || id == monitoring.ReportLatest || id == monitoring.ReportPrevious {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "a and b must be report IDs"})
+					return
+				}
+			}
+		}
+		if a == "" {
+			a = monitoring.ReportPrevious
+		}
+		if b == "" {
+			b = monitoring.ReportLatest
+		}
+
+		comparison, err := monitoringService.CompareReports(a, b)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrUnknownReport) {
+				status = http.StatusNotFound
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, comparison)
+	}
+}
+
+// reportPDFHandler serves a stored PDF report as a download
+func reportPDFHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		pdf, err := monitoringService.ReportPDF(id)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrUnknownReport) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="aks-mentions-report-%s.pdf"`, id))
+		w.Write(pdf)
+	}
+}
+
+func schedulerStatusHandler(schedulerService *scheduler.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, schedulerService.Status())
+	}
+}
+
+// pauseRequest pauses the scheduler indefinitely, until a time, or for a duration
+type pauseRequest struct {
+	Until    *time.Time `json:"until,omitempty"`
+	Duration string     `json:"duration,omitempty"` // e.g. "48h"
+	Reason   string     `json:"reason,omitempty"`
+}
+
+func schedulerPauseHandler(schedulerService *scheduler.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req pauseRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+				return
+			}
+		}
+
+		until := req.Until
+		if req.Duration != "" {
+			duration, err := time.ParseDuration(req.Duration)
+			if err != nil || duration <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "duration must be a positive Go duration such as \"48h\""})
+				return
+			}
+			end := time.Now().Add(duration)
+			until = &end
+		}
+
+		if err := schedulerService.Pause(until, req.Reason); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, schedulerService.Status())
+	}
+}
+
+func schedulerResumeHandler(schedulerService *scheduler.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := schedulerService.Resume(); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, schedulerService.Status())
+	}
+}
+
+func schedulerRescheduleHandler(schedulerService *scheduler.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Schedule string `json:"schedule"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Schedule == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "body must be JSON with a schedule cron expression"})
+			return
+		}
+
+		if err := schedulerService.Reschedule(mux.Vars(r)["name"], req.Schedule); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, scheduler.ErrUnknownJob):
+				status = http.StatusNotFound
+			case errors.Is(err, scheduler.ErrInvalidSchedule):
+				status = http.StatusBadRequest
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, schedulerService.Status())
+	}
+}
+
+func blocklistHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, monitoringService.GetBlocklist())
+	}
+}
+
+// blocklistEntryRequest adds an author, channel or domain to the blocklist
+type blocklistEntryRequest struct {
+	Value string `json:"value"`
+}
+
+func blocklistAddHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req blocklistEntryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+
+		status, err := monitoringService.AddBlocklistEntry(mux.Vars(r)["kind"], req.Value)
+		if err != nil {
+			writeJSON(w, blocklistErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func blocklistRemoveHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := monitoringService.RemoveBlocklistEntry(mux.Vars(r)["kind"], r.URL.Query().Get("value"))
+		if err != nil {
+			writeJSON(w, blocklistErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func blocklistErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, monitoring.ErrUnknownBlocklistKind), errors.Is(err, monitoring.ErrBlocklistEntryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, monitoring.ErrInvalidBlocklistEntry):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func keywordGroupsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := monitoringService.GetKeywordGroups()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+// keywordGroupRequest sets the keywords of a keyword group
+type keywordGroupRequest struct {
+	Keywords []string `json:"keywords"`
+}
+
+func keywordGroupSetHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req keywordGroupRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+
+		status, err := monitoringService.SetKeywordGroup(mux.Vars(r)["group"], req.Keywords)
+		if err != nil {
+			writeJSON(w, keywordGroupErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func keywordGroupDeleteHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := monitoringService.DeleteKeywordGroup(mux.Vars(r)["group"])
+		if err != nil {
+			writeJSON(w, keywordGroupErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func keywordGroupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, monitoring.ErrKeywordGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, monitoring.ErrInvalidKeywordGroup):
+		return http.StatusBadRequest
+	case errors.Is(err, monitoring.ErrKeywordGroupInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// maxSubmissionBody bounds the body of a mention submission
+const maxSubmissionBody = 1 << 20
+
+// mentionSubmitHandler queues mentions submitted by people or other systems for the next run.
+// The body is a single mention or an array of them.
+func mentionSubmitHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmissionBody)).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+
+		var submissions []monitoring.MentionSubmission
+		if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+			if err := json.Unmarshal(body, &submissions); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+				return
+			}
+		} else {
+			var submission monitoring.MentionSubmission
+			if err := json.Unmarshal(body, &submission); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+				return
+			}
+			submissions = append(submissions, submission)
+		}
+
+		result, err := monitoringService.SubmitMentions(submissions)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrInvalidSubmission) {
+				status = http.StatusBadRequest
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, result)
+	}
+}
+
+// mentionActionRequest is the body Logic Apps or Adaptive Card actions post to act on a mention
+type mentionActionRequest struct {
+	Action string `json:"action"` // "handled" or "escalate"
+	Actor  string `json:"actor"`
+	Note   string `json:"note"`
+}
+
+func mentionStateHandler(monitoringService *monitoring.Service, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !inboundAuthorized(w, r, secret) {
+			return
+		}
+
+		state, err := monitoringService.GetMentionState(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSON(w, mentionActionErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, state)
+	}
+}
+
+func mentionActionHandler(monitoringService *monitoring.Service, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !inboundAuthorized(w, r, secret) {
+			return
+		}
+
+		var req mentionActionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+
+		state, err := monitoringService.ApplyMentionAction(mux.Vars(r)["id"], req.Action, req.Actor, req.Note)
+		if err != nil {
+			writeJSON(w, mentionActionErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, state)
+	}
+}
+
+// mentionActionPageHandler serves the Mark handled and Escalate buttons of alerts. Opening
+// the signed link asks for confirmation, as link previews follow links in messages; the
+// action is applied when the form is posted.
+func mentionActionPageHandler(monitoringService *monitoring.Service, notificationService *notifications.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		id, action := mux.Vars(r)["id"], mux.Vars(r)["action"]
+		token := r.Form.Get("token")
+		if err := notificationService.CheckMentionActionToken(id, action, token); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		var (
+			state *monitoring.MentionState
+			err   error
+		)
+		if r.Method == http.MethodPost {
+			state, err = monitoringService.ApplyMentionAction(id, action, r.PostForm.Get("actor"), r.PostForm.Get("note"))
+		} else {
+			state, err = monitoringService.GetMentionState(id)
+		}
+		if state == nil {
+			http.Error(w, err.Error(), mentionActionErrorStatus(err))
+			return
+		}
+		if err != nil {
+			logrus.Errorf("Mention action %s on %s: %v", action, id, err)
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := notificationService.RenderMentionActionPage(w, state.Title, action, token, r.Method == http.MethodPost); err != nil {
+			logrus.Errorf("Failed to render mention action page: %v", err)
+		}
+	}
+}
+
+// inboundAuthorized checks the shared secret callers pass as a bearer token or in the
+// X-AKS-Mentions-Token header. Inbound actions are disabled when no secret is configured.
+func inboundAuthorized(w http.ResponseWriter, r *http.Request, secret string) bool {
+	if secret == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "inbound mention actions are not enabled"})
+		return false
+	}
+
+	token := r.Header.Get("X-AKS-Mentions-Token")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token = bearer
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or missing token"})
+		return false
+	}
+	return true
+}
+
+func mentionActionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, monitoring.ErrUnknownMention):
+		return http.StatusNotFound
+	case errors.Is(err, monitoring.ErrUnknownAction):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type mentionTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func tagsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		counts, err := monitoringService.TagCounts()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tags": counts})
+	}
+}
+
+func mentionTagsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := monitoringService.GetMentionTags(mux.Vars(r)["id"])
+		if err != nil {
+			writeJSON(w, tagErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, tags)
+	}
+}
+
+func mentionTagsAddHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req mentionTagsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+
+		tags, err := monitoringService.AddMentionTags(mux.Vars(r)["id"], req.Tags)
+		if err != nil {
+			writeJSON(w, tagErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, tags)
+	}
+}
+
+func mentionTagsRemoveHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		tags, err := monitoringService.RemoveMentionTag(vars["id"], vars["tag"])
+		if err != nil {
+			writeJSON(w, tagErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, tags)
+	}
+}
+
+type labelMentionRequest struct {
+	ID       string `json:"id"`
+	Relevant *bool  `json:"relevant"`
+	Note     string `json:"note"`
+}
+
+// filterCorpusHandler lists the mentions labeled as relevant or not
+func filterCorpusHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		corpus, err := monitoringService.FilterCorpus()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"count":   len(corpus),
+			"results": corpus,
+		})
+	}
+}
+
+// filterLabelHandler adds a stored or rejected mention to the filter corpus
+func filterLabelHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req labelMentionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+		if req.ID == "" || req.Relevant == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id and relevant are required"})
+			return
+		}
+
+		labeled, err := monitoringService.LabelMention(req.ID, *req.Relevant, req.Note)
+		if err != nil {
+			writeJSON(w, tagErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, labeled)
+	}
+}
+
+func filterLabelRemoveHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := monitoringService.RemoveLabel(mux.Vars(r)["id"]); err != nil {
+			writeJSON(w, tagErrorStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// filterEvaluationHandler replays the filter corpus against the current filters
+func filterEvaluationHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		evaluation, err := monitoringService.EvaluateFilters()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, evaluation)
+	}
+}
+
+// tagReportHandler renders the stored mentions with a tag as an HTML report
+func tagReportHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := monitoringService.TagReportPage(mux.Vars(r)["tag"])
+		if err != nil {
+			http.Error(w, err.Error(), tagErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'")
+		w.Write(page)
+	}
+}
+
+func tagErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, monitoring.ErrUnknownMention), errors.Is(err, monitoring.ErrTagNotFound), errors.Is(err, monitoring.ErrNotLabeled):
+		return http.StatusNotFound
+	case errors.Is(err, monitoring.ErrInvalidTag), errors.Is(err, monitoring.ErrInvalidQuery):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// feedHandler serves the newest mentions as an Atom feed, or RSS with format=rss, optionally
+// limited to a keyword group
+func feedHandler(monitoringService *monitoring.Service, publicBaseURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		group := query.Get("group")
+
+		limit := 0
+		if raw := query.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+
+		format := query.Get("format")
+		if format == "" {
+			format = "atom"
+		}
+		if format != "atom" && format != "rss" {
+			http.Error(w, "format must be 'atom' or 'rss'", http.StatusBadRequest)
+			return
+		}
+
+		mentions, err := monitoringService.FeedMentions(group, limit)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, monitoring.ErrUnknownKeywordGroup) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		feed := &reports.Feed{
+			ID:       "urn:aks-mentions-bot:feed",
+			Title:    "AKS Mentions",
+			Link:     publicBaseURL,
+			Mentions: mentions,
+		}
+		if group != "" {
+			feed.ID += ":" + group
+			feed.Title += " - " + group
+		}
+		if publicBaseURL != "" {
+			feed.SelfURL = publicBaseURL + r.URL.RequestURI()
+		}
+
+		render, contentType := reports.RenderAtom, "application/atom+xml; charset=utf-8"
+		if format == "rss" {
+			render, contentType = reports.RenderRSS, "application/rss+xml; charset=utf-8"
+		}
+		body, err := render(feed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
+
+// streamHeartbeat is how often /api/stream writes a comment so proxies keep an idle stream open
+const streamHeartbeat = 30 * time.Second
+
+// streamHandler pushes mentions to the client as Server-Sent Events as runs store them,
+// optionally limited to sources (comma-separated), a sentiment or a keyword group
+func streamHandler(monitoringService *monitoring.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := monitoring.LiveFilter{
+			Sentiment: query.Get("sentiment"),
+			Group:     query.Get("group"),
+		}
+		for _, source := range strings.Split(query.Get("source"), ",") {
+			if source = strings.TrimSpace(source); source != "" {
+				filter.Sources = append(filter.Sources, source)
+			}
+		}
+
+		mentions, cancel, err := monitoringService.SubscribeMentions(filter)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		defer cancel()
+
+		// The stream outlives the server's write timeout
+		controller := http.NewResponseController(w)
+		if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+			logrus.Warnf("Failed to clear the write deadline of a mention stream: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, ": connected\n\n")
+		if err := controller.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			case mention, ok := <-mentions:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(mention)
+				if err != nil {
+					logrus.Errorf("Failed to encode streamed mention %s: %v", mention.ID, err)
+					continue
+				}
+				fmt.Fprintf(w, "event: mention\nid: %s\ndata: %s\n\n", mention.ID, data)
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func preferencesHandler(notificationService *notifications.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		email := r.Form.Get("email")
+		token := r.Form.Get("token")
+
+		var (
+			recipient *config.EmailRecipient
+			message   string
+			err       error
+		)
+		if r.Method == http.MethodPost {
+			recipient, err = notificationService.UpdateRecipientPreferences(email, token, config.EmailRecipient{
+				Content:       r.PostForm.Get("content"),
+				Format:        r.PostForm.Get("format"),
+				KeywordGroups: r.PostForm["groups"],
+				AttachPDF:     r.PostForm.Get("attach_pdf") == "true",
+				Unsubscribed:  r.PostForm.Get("unsubscribed") == "true",
+			})
+			message = "Your preferences have been saved."
+		} else {
+			recipient, err = notificationService.RecipientPreferences(email, token)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), preferencesErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := notificationService.RenderPreferencesPage(w, *recipient, token, message); err != nil {
+			logrus.Errorf("Failed to render preferences page: %v", err)
+		}
+	}
+}
+
+func unsubscribeHandler(notificationService *notifications.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		// Email links carry the token and email in the query string; one-click unsubscribe
+		// (RFC 8058) POSTs to the same URL and the confirmation form posts them in the body
+		email := r.Form.Get("email")
+		token := r.Form.Get("token")
+
+		// Opening the link only asks for confirmation, as mail scanners and link previews
+		// follow links in emails
+		var err error
+		if r.Method == http.MethodPost {
+			err = notificationService.Unsubscribe(email, token)
+		} else {
+			_, err = notificationService.RecipientPreferences(email, token)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), preferencesErrorStatus(err))
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			logrus.Infof("Recipient %s unsubscribed from email reports", email)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := notificationService.RenderUnsubscribePage(w, email, token, r.Method == http.MethodPost); err != nil {
+			logrus.Errorf("Failed to render unsubscribe page: %v", err)
+		}
+	}
+}
+
+func notificationQueueHandler(notificationService *notifications.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := notificationService.QueueStatus()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func preferencesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, notifications.ErrPreferencesDisabled), errors.Is(err, notifications.ErrUnknownRecipient):
+		return http.StatusNotFound
+	case errors.Is(err, notifications.ErrInvalidPreferencesToken):
+		return http.StatusForbidden
+	case errors.Is(err, notifications.ErrInvalidPreferences):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.Errorf("Failed to write JSON response: %v", err)
+	}
+}
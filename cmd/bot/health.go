@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// readinessCacheTTL is how long the storage checks of a readiness probe are reused, so
+// frequent probes don't each query storage
+const readinessCacheTTL = 10 * time.Second
+
+// readinessStorageTimeout bounds the storage check of each configuration
+const readinessStorageTimeout = 5 * time.Second
+
+// readinessProbeBlob is listed to check storage connectivity; nothing is stored under it
+const readinessProbeBlob = "readyz/probe"
+
+// healthCheck is one dependency checked by /livez or /readyz
+type healthCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// healthStatus is the response of /livez and /readyz. The probes are served without
+// authentication, so the checks behind the status are logged rather than returned.
+type healthStatus struct {
+	Status    string    `json:"status"` // "ok" or "unavailable"
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// storageChecks caches the last storage connectivity checks of a deployment
+type storageChecks struct {
+	mu      sync.Mutex
+	checked time.Time
+	checks  []healthCheck
+}
+
+// livezHandler reports whether the process is working: its schedulers run, or are stopped
+// while the deployment is replaced. Dependencies are left to /readyz, so a storage outage
+// doesn't restart the bot.
+func (d *deployment) livezHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, "Liveness", d.schedulerChecks())
+}
+
+// readyzHandler reports whether the bot can serve: storage is reachable, required settings
+// are present and schedulers run, for every configuration of the deployment
+func (d *deployment) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := d.storageHealth()
+	for _, s := range d.all {
+		checks = append(checks, requiredConfigCheck(s.config))
+	}
+	writeHealth(w, "Readiness", append(checks, d.schedulerChecks()...))
+}
+
+func (d *deployment) schedulerChecks() []healthCheck {
+	replacing := d.replacing.Load()
+	checks := make([]healthCheck, 0, len(d.all))
+	for _, s := range d.all {
+		check := healthCheck{Name: "scheduler" + healthLabel(s.config), OK: true}
+		status := s.scheduler.Status()
+		switch {
+		case replacing && !status.Running:
+			check.Detail = "stopped while a configuration change is applied"
+		case !status.Running:
+			check.OK = false
+			check.Detail = "not running"
+		case status.Paused:
+			check.Detail = "paused"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// storageHealth lists a blob prefix in each configuration's storage, reusing results younger
+// than readinessCacheTTL
+func (d *deployment) storageHealth() []healthCheck {
+	d.storageChecks.mu.Lock()
+	defer d.storageChecks.mu.Unlock()
+
+	if time.Since(d.storageChecks.checked) < readinessCacheTTL {
+		return append([]healthCheck(nil), d.storageChecks.checks...)
+	}
+
+	checks := make([]healthCheck, 0, len(d.all))
+	for _, s := range d.all {
+		check := healthCheck{Name: "storage" + healthLabel(s.config), OK: true}
+		result := make(chan error, 1)
+		go func(s *services) {
+			_, err := s.storage.List(readinessProbeBlob)
+			result <- err
+		}(s)
+		select {
+		case err := <-result:
+			if err != nil {
+				check.OK = false
+				check.Detail = err.Error()
+			}
+		case <-time.After(readinessStorageTimeout):
+			check.OK = false
+			check.Detail = fmt.Sprintf("no response in %s", readinessStorageTimeout)
+		}
+		checks = append(checks, check)
+	}
+
+	d.storageChecks.checked = time.Now()
+	d.storageChecks.checks = checks
+	return append([]healthCheck(nil), checks...)
+}
+
+// requiredConfigCheck checks the settings every run needs, which validation required when
+// the configuration was loaded
+func requiredConfigCheck(cfg *config.Config) healthCheck {
+	check := healthCheck{Name: "config" + healthLabel(cfg), OK: true}
+	switch {
+	case len(cfg.Keywords) == 0:
+		check.Detail = "no keywords"
+	case !cfg.StorageConfigured():
+		check.Detail = "no storage account"
+	case !cfg.TeamsEnabled() && len(cfg.EmailRecipients) == 0 && len(cfg.OutboundWebhookURLs) == 0 && len(cfg.NotificationChannels) == 0:
+		check.Detail = "no notification targets"
+	}
+	check.OK = check.Detail == ""
+	return check
+}
+
+// writeHealth answers a probe with the overall status, logging the checks that failed
+func writeHealth(w http.ResponseWriter, probe string, checks []healthCheck) {
+	status := healthStatus{Status: "ok", Timestamp: time.Now().UTC()}
+	code := http.StatusOK
+	for _, check := range checks {
+		if !check.OK {
+			logrus.Warnf("%s check %s failed: %s", probe, check.Name, check.Detail)
+			status.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	writeJSON(w, code, status)
+}
+
+// healthLabel names the profile of a check, e.g. "scheduler (fleet-manager)"
+func healthLabel(cfg *config.Config) string {
+	if cfg.Profile == "" {
+		return ""
+	}
+	return " (" + cfg.Profile + ")"
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/scheduler"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// The run, collect, urgent, urgent-digest, backfill and export-parquet commands execute a single job and exit, so the bot can be
+// deployed as a Kubernetes CronJob or ACA Job. A failed job exits non-zero.
+
+func newRunCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Run one monitoring cycle, send the report and exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := newServices(opts)
+			if err != nil {
+				return err
+			}
+			if err := waitForJitter(svc.config.ScheduleJitter); err != nil {
+				return err
+			}
+			stop := stopRunsOnSignal(svc)
+			defer stop()
+
+			if err := svc.monitoring.RunMonitoring(); err != nil {
+				return fmt.Errorf("monitoring run failed: %w", err)
+			}
+			logrus.Info("Monitoring run completed")
+			return nil
+		},
+	}
+}
+
+func newCollectCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "collect <source>",
+		Short: "Collect one source with its own schedule for the next report and exit",
+		Long: `Fetch, filter, enrich and store the mentions of a source scheduled in
+SOURCE_SCHEDULES without sending a report; the next run reports them. Deploy it as
+a CronJob on the source's schedule alongside the run CronJob.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := args[0]
+			svc, err := newServices(opts)
+			if err != nil {
+				return err
+			}
+			schedule, ok := svc.config.SourceSchedules[source]
+			if !ok {
+				return fmt.Errorf("%s has no schedule in SOURCE_SCHEDULES; the run command collects it", source)
+			}
+			window, err := scheduler.Interval(schedule)
+			if err != nil {
+				return err
+			}
+			if err := waitForJitter(svc.config.ScheduleJitter); err != nil {
+				return err
+			}
+			stop := stopRunsOnSignal(svc)
+			defer stop()
+
+			if err := svc.monitoring.CollectSource(source, window); err != nil {
+				return fmt.Errorf("collection of %s failed: %w", source, err)
+			}
+			logrus.Infof("Collection of %s completed", source)
+			return nil
+		},
+	}
+}
+
+func newUrgentCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "urgent",
+		Short: "Run one urgent-mention check and exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := newServices(opts)
+			if err != nil {
+				return err
+			}
+			if err := waitForJitter(svc.config.ScheduleJitter); err != nil {
+				return err
+			}
+			stop := stopRunsOnSignal(svc)
+			defer stop()
+
+			if err := svc.monitoring.RunUrgentCheck(); err != nil {
+				return fmt.Errorf("urgent check failed: %w", err)
+			}
+			logrus.Info("Urgent check completed")
+			return nil
+		},
+	}
+}
+
+func newUrgentDigestCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "urgent-digest",
+		Short: "Send the summary of the last 24 hours of urgent stories and exit",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := newServices(opts)
+			if err != nil {
+				return err
+			}
+
+			if err := svc.monitoring.SendUrgentDigest(); err != nil {
+				return fmt.Errorf("urgent digest failed: %w", err)
+			}
+			logrus.Info("Urgent digest completed")
+			return nil
+		},
+	}
+}
+
+func newBackfillCommand(opts *globalOptions) *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Collect and store historical mentions without sending a report",
+		Long: `Collect and store mentions from the last --days days without sending a report.
+Use it to seed history and the search index for a new deployment. How far back each
+source can search depends on its API.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return fmt.Errorf("--days must be at least 1")
+			}
+
+			svc, err := newServices(opts)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			count, err := svc.monitoring.Backfill(ctx, time.Duration(days)*24*time.Hour)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("Backfill stored %d mentions", count)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 30, "Number of days of history to collect")
+	return cmd
+}
+
+func newExportParquetCommand(opts *globalOptions) *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "export-parquet",
+		Short: "Export stored mentions to partitioned Parquet files",
+		Long: `Write stored mentions to Parquet files partitioned by day and source under
+PARQUET_EXPORT_PREFIX, replacing earlier exports of the same partitions. With
+ENABLE_PARQUET_EXPORT set, runs keep the days they touch up to date; use this
+command to export the history stored before that.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var from time.Time
+			if since != "" {
+				var err error
+				if from, err = parseSince(since, time.Now()); err != nil {
+					return fmt.Errorf("--since: %w", err)
+				}
+			}
+
+			svc, err := newServices(opts)
+			if err != nil {
+				return err
+			}
+
+			start := time.Now()
+			files, err := svc.monitoring.ExportParquet(from)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("Exported %d Parquet files in %v", files, time.Since(start))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Export days from this RFC 3339 time, YYYY-MM-DD date or duration ago (default: all stored days)")
+	return cmd
+}
+
+// waitForJitter delays a single run by up to SCHEDULE_JITTER, since CronJobs deployed from
+// the same template otherwise all start at the same moment. A signal ends the wait.
+func waitForJitter(max time.Duration) error {
+	delay := scheduler.Jitter(max)
+	if delay == 0 {
+		return nil
+	}
+
+	logrus.Infof("Delaying run by %s", delay.Round(time.Second))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("interrupted before the run started")
+	}
+}
+
+// stopRunsOnSignal shuts the monitoring service down on SIGINT or SIGTERM, so a job being
+// terminated stores what it has collected before exiting
+func stopRunsOnSignal(svc *services) (stop func()) {
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	stopShutdown := context.AfterFunc(ctx, func() {
+		logrus.Info("Received shutdown signal")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := svc.monitoring.Shutdown(shutdownCtx); err != nil {
+			logrus.Errorf("Monitoring runs forced to stop: %v", err)
+		}
+	})
+	return func() {
+		stopShutdown()
+		stopSignals()
+	}
+}
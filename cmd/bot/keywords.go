@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newKeywordsCommand(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keywords",
+		Short: "List and change keyword groups without redeploying",
+		Long: `Manage keyword groups at runtime, like /api/admin/keywords. Changes are stored in
+Azure Storage and searched from the next run; a running serve applies them once its
+in-flight runs finish. Only the storage settings are required.`,
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the keywords and groups the next run searches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service, err := newKeywordsService(opts)
+			if err != nil {
+				return err
+			}
+			status, err := service.GetKeywordGroups()
+			if err != nil {
+				return err
+			}
+			printKeywordGroups(status)
+			return nil
+		},
+	}
+
+	set := &cobra.Command{
+		Use:   "set <group> <keyword>...",
+		Short: "Add a keyword group or replace its keywords",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service, err := newKeywordsService(opts)
+			if err != nil {
+				return err
+			}
+			if _, err := service.SetKeywordGroup(args[0], args[1:]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Set keyword group %s, searched from the next run\n", args[0])
+			return nil
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:   "delete <group>",
+		Short: "Remove a runtime group, restore a changed configured group or hide a configured group",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service, err := newKeywordsService(opts)
+			if err != nil {
+				return err
+			}
+			if _, err := service.DeleteKeywordGroup(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Deleted keyword group %s, applied from the next run\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.AddCommand(list, set, remove)
+	return cmd
+}
+
+// newKeywordsService reads configuration with the stored keyword groups applied, as the next
+// run will
+func newKeywordsService(opts *globalOptions) (*monitoring.Service, error) {
+	cfg, err := parseConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.StorageConfigured() {
+		return nil, fmt.Errorf("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING is required")
+	}
+	if !opts.debug {
+		logrus.SetLevel(logrus.WarnLevel)
+	}
+
+	store, err := newStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	overrides, err := monitoring.LoadKeywordOverrides(store)
+	if err != nil {
+		return nil, err
+	}
+	overrides.Apply(cfg)
+	return monitoring.NewService(cfg, store, nil), nil
+}
+
+func printKeywordGroups(status monitoring.KeywordGroupsStatus) {
+	fmt.Println("🔑 Keywords")
+	fmt.Println(strings.Repeat("-", 46))
+	fmt.Println(strings.Join(status.Keywords, ", "))
+
+	fmt.Println("\n🗂️  Keyword groups")
+	fmt.Println(strings.Repeat("-", 46))
+	names := make([]string, 0, len(status.Groups)+len(status.Runtime))
+	for name := range status.Groups {
+		names = append(names, name)
+	}
+	for name, keywords := range status.Runtime {
+		if len(keywords) == 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		runtime, changed := status.Runtime[name]
+		switch {
+		case changed && len(runtime) == 0:
+			fmt.Printf("%s: (hidden at runtime)\n", name)
+		case changed:
+			fmt.Printf("%s: %s (runtime)\n", name, strings.Join(status.Groups[name], ", "))
+		default:
+			fmt.Printf("%s: %s\n", name, strings.Join(status.Groups[name], ", "))
+		}
+	}
+	if status.UpdatedAt != nil {
+		fmt.Printf("\nLast changed %s\n", status.UpdatedAt.Format("2006-01-02 15:04 MST"))
+	}
+}
@@ -1,139 +1,234 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/config"
 	"github.com/azure/aks-mentions-bot/internal/monitoring"
 	"github.com/azure/aks-mentions-bot/internal/notifications"
+	_ "github.com/azure/aks-mentions-bot/internal/plugins"
 	"github.com/azure/aks-mentions-bot/internal/scheduler"
 	"github.com/azure/aks-mentions-bot/internal/storage"
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 )
 
+// globalOptions holds the flags shared by every subcommand
+type globalOptions struct {
+	envFile string
+	profile string
+	debug   bool
+}
+
+// services bundles the components used by the commands that run monitoring jobs, for the
+// default configuration or one profile
+type services struct {
+	config        *config.Config
+	storage       storage.StorageInterface
+	notifications *notifications.Service
+	monitoring    *monitoring.Service
+	scheduler     *scheduler.Service // Set by serve
+
+	keywordsUpdatedAt time.Time // When the applied runtime keyword groups were changed
+}
+
 func main() {
-	// Load environment variables from .env file if it exists
-	if err := godotenv.Load(); err != nil {
-		logrus.Info("No .env file found, using environment variables")
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &globalOptions{}
+
+	root := &cobra.Command{
+		Use:   "aks-mentions-bot",
+		Short: "Monitor community mentions of Azure Kubernetes Service",
+		Long: `AKS Mentions Bot collects mentions of Azure Kubernetes Service from community
+sources and delivers reports and urgent alerts to Teams, email and webhooks.
+
+Running without a subcommand is the same as "serve".`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Load environment variables from .env file if it exists
+			if err := godotenv.Load(opts.envFile); err != nil {
+				logrus.Debugf("No %s file found, using environment variables", opts.envFile)
+			}
+			if opts.debug {
+				logrus.SetLevel(logrus.DebugLevel)
+			}
+
+			// Settings and feature flags from Azure App Configuration override the environment
+			return loadAppConfiguration()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(opts)
+		},
+	}
+
+	root.PersistentFlags().StringVar(&opts.envFile, "env-file", ".env", "File to load environment variables from")
+	root.PersistentFlags().StringVar(&opts.profile, "profile", "", "Run as a monitoring profile listed in PROFILES instead of the default configuration")
+	root.PersistentFlags().BoolVar(&opts.debug, "debug", false, "Enable debug logging (overrides DEBUG)")
+
+	root.AddCommand(
+		newServeCommand(opts),
+		newRunCommand(opts),
+		newCollectCommand(opts),
+		newUrgentCommand(opts),
+		newUrgentDigestCommand(opts),
+		newBackfillCommand(opts),
+		newExportParquetCommand(opts),
+		newReprocessCommand(opts),
+		newReportCommand(),
+		newTestSourcesCommand(opts),
+		newPreviewCommand(opts),
+		newFilterEvalCommand(opts),
+		newValidateConfigCommand(opts),
+		newRebuildSearchIndexCommand(opts),
+		newEncryptStorageCommand(opts),
+		newAnalyticsCommand(opts),
+		newKeywordsCommand(opts),
+		newSetupCommand(opts),
+	)
+
+	return root
+}
+
+// parseConfig reads the configuration of --profile, or the default configuration, without
+// validating it
+func parseConfig(opts *globalOptions) (*config.Config, error) {
+	if opts.profile == "" {
+		return config.Parse()
 	}
+	if err := checkProfile(opts.profile); err != nil {
+		return nil, err
+	}
+	return config.ParseProfile(opts.profile)
+}
 
-	// Initialize configuration
-	cfg, err := config.Load()
+// checkProfile makes sure a profile is listed in PROFILES, so a typo in --profile doesn't run
+// a profile nobody configured
+func checkProfile(name string) error {
+	root, err := config.Parse()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return err
+	}
+	if !containsName(root.Profiles, name) {
+		return fmt.Errorf("unknown profile %q: PROFILES lists %q", name, strings.Join(root.Profiles, ","))
+	}
+	return nil
+}
+
+// loadConfig loads and validates configuration and sets up structured logging
+func loadConfig(opts *globalOptions) (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if opts.profile == "" {
+		cfg, err = config.Load()
+	} else if err = checkProfile(opts.profile); err == nil {
+		cfg, err = config.LoadProfile(opts.profile)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Set up logging
 	logrus.SetLevel(logrus.InfoLevel)
-	if cfg.Debug {
+	if cfg.Debug || opts.debug {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 
-	logrus.Info("Starting AKS Mentions Bot")
+	return cfg, nil
+}
 
+// newServices wires up storage, notifications and monitoring from validated configuration
+func newServices(opts *globalOptions) (*services, error) {
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newServicesFor(cfg)
+}
+
+// newServicesFor wires up storage, notifications and monitoring for the default configuration
+// or a profile
+func newServicesFor(cfg *config.Config) (*services, error) {
 	// Initialize Azure storage
-	storageClient, err := storage.NewAzureStorage(cfg.StorageAccount, cfg.StorageContainer)
+	storageClient, err := newStorage(cfg)
 	if err != nil {
-		logrus.Fatalf("Failed to initialize storage: %v", err)
+		return nil, err
 	}
 
+	// Keyword groups changed at runtime through /api/admin/keywords or the keywords command
+	overrides, err := monitoring.LoadKeywordOverrides(storageClient)
+	if err != nil {
+		return nil, err
+	}
+	overrides.Apply(cfg)
+
 	// Initialize notification services
-	notificationService := notifications.NewService(cfg)
+	notificationService := notifications.NewService(cfg).
+		WithPreferenceStore(storageClient).
+		WithDeliveryQueue(storageClient)
 
 	// Initialize monitoring service
 	monitoringService := monitoring.NewService(cfg, storageClient, notificationService)
 
-	// Initialize scheduler
-	schedulerService := scheduler.NewService(cfg, monitoringService)
+	return &services{
+		config:            cfg,
+		storage:           storageClient,
+		notifications:     notificationService,
+		monitoring:        monitoringService,
+		keywordsUpdatedAt: overrides.UpdatedAt,
+	}, nil
+}
 
-	// Start scheduler
-	if err := schedulerService.Start(); err != nil {
-		logrus.Fatalf("Failed to start scheduler: %v", err)
+// newStorage connects to the configured storage account, keeping a profile's blobs under its
+// own prefix and encrypting them when STORAGE_ENCRYPTION_KEY_ID is set
+func newStorage(cfg *config.Config) (storage.StorageInterface, error) {
+	azureStore, err := newAzureStorage(cfg)
+	if err != nil {
+		return nil, err
 	}
-	defer schedulerService.Stop()
-
-	// Set up HTTP server for health checks and webhooks
-	router := mux.NewRouter()
-	
-	// Health check endpoint
-	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
-	
-	// Metrics endpoint
-	router.HandleFunc("/metrics", metricsHandler(monitoringService)).Methods("GET")
-	
-	// Manual trigger endpoint (for testing)
-	router.HandleFunc("/trigger", triggerHandler(monitoringService)).Methods("POST")
-
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%s", cfg.Port),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	var store storage.StorageInterface = azureStore
+	if prefix := cfg.StoragePrefix(); prefix != "" {
+		store = storage.NewPrefixedStorage(store, prefix)
 	}
-
-	// Start HTTP server in a goroutine
-	go func() {
-		logrus.Infof("HTTP server starting on port %s", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logrus.Fatalf("HTTP server failed: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logrus.Info("Shutting down server...")
-
-	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Shutdown HTTP server
-	if err := server.Shutdown(ctx); err != nil {
-		logrus.Errorf("Server forced to shutdown: %v", err)
+	if cfg.StorageEncryptionKeyID == "" {
+		return store, nil
 	}
 
-	logrus.Info("Server exited")
-}
-
-func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"healthy","timestamp":"` + time.Now().Format(time.RFC3339) + `"}`))
-}
-
-func metricsHandler(monitoringService *monitoring.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		metrics := monitoringService.GetMetrics()
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(metrics))
+	key, err := storage.NewKeyVaultKey(cfg.StorageEncryptionKeyID)
+	if err != nil {
+		return nil, err
 	}
+	// Parquet exports stay readable by the data lake tools that query them
+	var plaintext []string
+	if cfg.EnableParquetExport {
+		plaintext = append(plaintext, cfg.ParquetExportPrefix+"/")
+	}
+	encrypted, err := storage.NewEncryptedStorage(store, key, plaintext...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up storage encryption: %w", err)
+	}
+	if cfg.StorageEncryptionMigration {
+		logrus.Warn("STORAGE_ENCRYPTION_MIGRATION is set: unencrypted blobs are read as they are until encrypt-storage has run")
+		encrypted.WithPlaintextReads()
+	}
+	return encrypted, nil
 }
 
-func triggerHandler(monitoringService *monitoring.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		go func() {
-			if err := monitoringService.RunMonitoring(); err != nil {
-				logrus.Errorf("Manual monitoring trigger failed: %v", err)
-			}
-		}()
-		
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"message":"Monitoring triggered successfully"}`))
-	}
+// newAzureStorage connects to the configured storage account, with a connection string or SAS
+// token when one is set and managed identity otherwise
+func newAzureStorage(cfg *config.Config) (*storage.AzureStorage, error) {
+	return storage.NewAzureStorageWithAuth(cfg.StorageAccount, cfg.StorageContainer, storage.StorageAuth{
+		ConnectionString: cfg.StorageConnectionString,
+		SASToken:         cfg.StorageSASToken,
+		ServiceURL:       cfg.StorageBlobEndpoint,
+	})
 }
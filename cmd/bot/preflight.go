@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/notifications"
+)
+
+// Outcomes of a preflight check
+const (
+	preflightReady   = "ready"
+	preflightFailed  = "failed"
+	preflightSkipped = "skipped"
+)
+
+// preflightCheck is a row of the readiness matrix printed by validate-config --preflight
+type preflightCheck struct {
+	Config string // "default" or the profile name
+	Check  string // storage, app-config, key-vault, notification or source
+	Target string
+	Status string
+	Detail string
+}
+
+// runPreflight checks that a validated configuration can reach everything it depends on
+func runPreflight(ctx context.Context, cfg *config.Config, ping bool) []preflightCheck {
+	name := cfg.Profile
+	if name == "" {
+		name = "default"
+	}
+	check := func(kind, target, status, detail string) preflightCheck {
+		return preflightCheck{Config: name, Check: kind, Target: target, Status: status, Detail: detail}
+	}
+
+	checks := []preflightCheck{checkStorageAccess(cfg, check)}
+
+	// App Configuration, and the Key Vault secrets it references, were loaded before the
+	// command ran; a failure there stops every command
+	if cfg.AppConfigEndpoint != "" && cfg.Profile == "" {
+		settings := config.CurrentRemoteSettings()
+		if settings == nil {
+			checks = append(checks, check("app-config", cfg.AppConfigEndpoint, preflightFailed, "not loaded"))
+		} else {
+			checks = append(checks, check("app-config", cfg.AppConfigEndpoint, preflightReady,
+				fmt.Sprintf("%d settings, %d feature flags", len(settings.Values), len(settings.FeatureFlags))))
+			if len(settings.Secrets) > 0 {
+				checks = append(checks, check("key-vault", strings.Join(settings.Secrets, ", "), preflightReady,
+					fmt.Sprintf("%d secrets resolved", len(settings.Secrets))))
+			}
+		}
+	}
+
+	if ping {
+		for _, result := range notifications.NewService(cfg).Ping() {
+			if result.Err != nil {
+				checks = append(checks, check("notification", result.Target, preflightFailed, result.Err.Error()))
+			} else {
+				checks = append(checks, check("notification", result.Target, preflightReady, "test message sent ("+result.Detail+")"))
+			}
+		}
+	} else {
+		checks = append(checks, check("notification", "all", preflightSkipped, "--no-ping"))
+	}
+
+	// Probe the sources without touching stored state, as test-sources does
+	service := monitoring.NewService(cfg, discardStorage{}, nil)
+	for _, status := range service.GetSourceStatuses() {
+		if !status.Enabled {
+			detail := "not enabled"
+			if status.Credentials == "missing" {
+				detail = "credentials missing"
+			}
+			checks = append(checks, check("source", status.Name, preflightSkipped, detail))
+			continue
+		}
+		result, err := service.TestSource(ctx, status.Name, "")
+		switch {
+		case err != nil:
+			checks = append(checks, check("source", status.Name, preflightFailed, err.Error()))
+		case !result.Success:
+			checks = append(checks, check("source", status.Name, preflightFailed, result.Error))
+		default:
+			checks = append(checks, check("source", status.Name, preflightReady,
+				fmt.Sprintf("%d mentions for %q in %s", result.MentionCount, result.Keyword, result.Duration)))
+		}
+	}
+
+	return checks
+}
+
+// checkStorageAccess writes, reads back and deletes a blob, as every run does with its state
+func checkStorageAccess(cfg *config.Config, check func(kind, target, status, detail string) preflightCheck) preflightCheck {
+	target := cfg.StorageContainer
+	if cfg.StorageAccount != "" {
+		target = cfg.StorageAccount + "/" + target
+	}
+	if prefix := cfg.StoragePrefix(); prefix != "" {
+		target += "/" + strings.TrimSuffix(prefix, "/")
+	}
+
+	store, err := newStorage(cfg)
+	if err != nil {
+		return check("storage", target, preflightFailed, err.Error())
+	}
+
+	blob := fmt.Sprintf("preflight/%d.txt", time.Now().UnixNano())
+	data := []byte("AKS Mentions Bot preflight check\n")
+	if err := store.Store(blob, data); err != nil {
+		return check("storage", target, preflightFailed, "write: "+err.Error())
+	}
+	read, err := store.Retrieve(blob)
+	if err == nil && !bytes.Equal(read, data) {
+		err = fmt.Errorf("read back %d bytes, wrote %d", len(read), len(data))
+	}
+	if err != nil {
+		store.Delete(blob)
+		return check("storage", target, preflightFailed, "read: "+err.Error())
+	}
+	if err := store.Delete(blob); err != nil {
+		return check("storage", target, preflightFailed, "delete: "+err.Error())
+	}
+	return check("storage", target, preflightReady, "read, write and delete")
+}
+
+// printReadiness prints the readiness matrix and fails if any check failed
+func printReadiness(checks []preflightCheck) error {
+	fmt.Println("\n🚦 Readiness")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONFIG\tCHECK\tTARGET\tSTATUS\tDETAIL")
+	failed := 0
+	for _, c := range checks {
+		status := "✅ " + c.Status
+		switch c.Status {
+		case preflightFailed:
+			status = "❌ " + c.Status
+			failed++
+		case preflightSkipped:
+			status = "➖ " + c.Status
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Config, c.Check, c.Target, status, c.Detail)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d preflight checks failed", failed, len(checks))
+	}
+	fmt.Println("\n✅ Ready to run")
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newPreviewCommand(opts *globalOptions) *cobra.Command {
+	var keywords []string
+	var window time.Duration
+	var samples int
+
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Preview what a proposed keyword set would collect",
+		Long: `Search every configured source with a proposed keyword set and apply the usual
+filters, without storing mentions or sending notifications. Prints how many mentions
+each keyword and source would contribute and the highest-scoring samples, so keyword
+changes can be evaluated before KEYWORDS is updated. Notification settings and Azure
+Storage are not required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			if len(keywords) == 0 {
+				keywords = cfg.Keywords
+			}
+			if !opts.debug {
+				logrus.SetLevel(logrus.WarnLevel)
+			}
+
+			service := monitoring.NewService(cfg, discardStorage{}, nil)
+			preview, err := service.PreviewKeywords(context.Background(), keywords, window, samples)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("🔍 Keyword preview: %s (last %s)\n", strings.Join(preview.Keywords, ", "), preview.Window)
+			fmt.Println(strings.Repeat("-", 46))
+			fmt.Printf("Collected %d mentions, %d kept and %d filtered out in %s\n", preview.Collected, preview.Kept, preview.Rejected, preview.Duration)
+
+			fmt.Println("\n🔑 By keyword:")
+			for _, keyword := range preview.Keywords {
+				fmt.Printf("  • %s: %d\n", keyword, preview.ByKeyword[keyword])
+			}
+
+			fmt.Println("\n📈 By source:")
+			for _, source := range sortedKeys(preview.BySource) {
+				fmt.Printf("  • %s: %d\n", source, preview.BySource[source])
+			}
+			for _, source := range sortedKeys(preview.Errors) {
+				fmt.Printf("  ❌ %s: %s\n", source, preview.Errors[source])
+			}
+
+			if len(preview.Samples) > 0 {
+				fmt.Println("\n📝 Samples:")
+				for i, mention := range preview.Samples {
+					fmt.Printf("  %d. [%s] %s\n", i+1, mention.Source, mention.Title)
+					fmt.Printf("     %s\n", mention.URL)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&keywords, "keywords", nil, "Keywords to preview (comma-separated; default KEYWORDS)")
+	cmd.Flags().DurationVar(&window, "window", monitoring.DefaultPreviewWindow, "How far back to search, e.g. 48h (at most 168h)")
+	cmd.Flags().IntVar(&samples, "samples", monitoring.DefaultPreviewSamples, "Number of sample mentions to print")
+	return cmd
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
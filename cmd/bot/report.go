@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,50 +12,68 @@ import (
 	"github.com/azure/aks-mentions-bot/internal/config"
 	"github.com/azure/aks-mentions-bot/internal/models"
 	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/reports"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/spf13/cobra"
 )
 
-// TestStorage implements simple file-based storage for testing
-type TestStorage struct{}
+func newReportCommand() *cobra.Command {
+	var (
+		output string
+		pdf    bool
+	)
 
-func (t *TestStorage) Store(filename string, data []byte) error {
-	dir := "test_output"
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-	return os.WriteFile(filepath.Join(dir, filename), data, 0644)
-}
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate a sample report from built-in mentions and print it",
+		Long: `Generate a report from a built-in set of sample mentions, print it to the terminal
+and save it as JSON and as a standalone HTML page with charts, plus a PDF with --pdf.
+Useful for checking report formatting without API keys or Azure.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := &config.Config{
+				ReportSchedule: "weekly",
+				Keywords:       []string{"aks", "azure kubernetes service", "kubefleet", "kaito"},
+			}
+			printer := &consoleNotifier{outputDir: output}
+			if pdf {
+				printer.pdf = reports.NewPDFRenderer(os.Getenv("CHROME_PATH"))
+			}
+			service := monitoring.NewService(cfg, discardStorage{}, printer)
 
-func (t *TestStorage) Retrieve(filename string) ([]byte, error) {
-	return os.ReadFile(filepath.Join("test_output", filename))
-}
+			mentions := sampleMentions()
+			fmt.Printf("📊 Generating report with %d sample mentions...\n", len(mentions))
 
-func (t *TestStorage) List(prefix string) ([]string, error) {
-	return []string{}, nil
-}
+			return printer.SendReport(service.GenerateTestReport(mentions))
+		},
+	}
 
-func (t *TestStorage) Delete(filename string) error {
-	return os.Remove(filepath.Join("test_output", filename))
+	cmd.Flags().StringVar(&output, "output", "test_output", "Directory to save the JSON and HTML reports in (empty to skip)")
+	cmd.Flags().BoolVar(&pdf, "pdf", false, "Also save a PDF, printed with Chrome or Chromium (CHROME_PATH or on the PATH)")
+	return cmd
 }
 
-// TestNotificationService outputs reports to terminal and files
-type TestNotificationService struct{}
+// consoleNotifier prints reports to the terminal and optionally saves them as JSON, HTML and PDF
+type consoleNotifier struct {
+	outputDir string
+	pdf       *reports.PDFRenderer // Saves a PDF too when set
+}
 
-func (t *TestNotificationService) SendReport(report *models.Report) error {
-	// Print to terminal
+func (c *consoleNotifier) SendReport(report *models.Report) error {
 	fmt.Println("\n" + strings.Repeat("=", 70))
 	fmt.Println("📊 AKS MENTIONS REPORT")
 	fmt.Println(strings.Repeat("=", 70))
 	fmt.Printf("📅 Period: %s\n", report.Period)
 	fmt.Printf("🕒 Generated: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05 UTC"))
 	fmt.Printf("📈 Total Mentions: %d\n", report.TotalMentions)
-	
+
 	if sourceStats, ok := report.Summary["sources"].(map[string]int); ok {
 		fmt.Println("\n📍 Sources:")
 		for source, count := range sourceStats {
 			fmt.Printf("   • %-15s %d mentions\n", source+":", count)
 		}
 	}
-	
+
 	if sentimentStats, ok := report.Summary["sentiment"].(map[string]int); ok {
 		fmt.Println("\n💭 Sentiment Analysis:")
 		for sentiment, count := range sentimentStats {
@@ -68,10 +87,10 @@ func (t *TestNotificationService) SendReport(report *models.Report) error {
 			fmt.Printf("   %s %-10s %d mentions\n", emoji, sentiment+":", count)
 		}
 	}
-	
+
 	fmt.Println("\n📝 Recent Mentions:")
 	for i, mention := range report.Mentions {
-		if i >= 5 { // Show first 5 mentions
+		if i >= 5 {
 			fmt.Printf("   ... and %d more mentions\n", len(report.Mentions)-5)
 			break
 		}
@@ -83,64 +102,95 @@ func (t *TestNotificationService) SendReport(report *models.Report) error {
 		fmt.Printf("      💭 Sentiment: %s | ⭐ Score: %d\n", mention.Sentiment, mention.Score)
 		fmt.Printf("      🕒 Posted: %s\n", mention.CreatedAt.Format("2006-01-02 15:04"))
 	}
-	
-	// Save to JSON file
-	if err := t.saveReportToFile(report); err != nil {
-		fmt.Printf("\n⚠️  Warning: Could not save to file: %v\n", err)
+
+	if c.outputDir != "" {
+		filename, err := c.saveReport(report)
+		if err != nil {
+			return fmt.Errorf("failed to save report: %w", err)
+		}
+		fmt.Printf("\n💾 Report saved to: %s\n", filename)
+
+		htmlFile, err := c.saveHTMLReport(report)
+		if err != nil {
+			return fmt.Errorf("failed to save HTML report: %w", err)
+		}
+		fmt.Printf("📈 HTML report saved to: %s\n", htmlFile)
+
+		if c.pdf != nil {
+			pdfFile, err := c.savePDFReport(htmlFile)
+			if err != nil {
+				return fmt.Errorf("failed to save PDF report: %w", err)
+			}
+			fmt.Printf("📄 PDF report saved to: %s\n", pdfFile)
+		}
 	}
-	
+
 	fmt.Println("\n" + strings.Repeat("=", 70))
 	return nil
 }
 
-func (t *TestNotificationService) SendAlert(alert *models.Alert) error {
+func (c *consoleNotifier) SendAlert(alert *models.Alert) error {
 	fmt.Println("\n🚨 ALERT")
 	fmt.Printf("Type: %s\n", alert.Type)
 	fmt.Printf("Message: %s\n", alert.Message)
 	return nil
 }
 
-func (t *TestNotificationService) saveReportToFile(report *models.Report) error {
-	dir := "test_output"
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+func (c *consoleNotifier) saveReport(report *models.Report) (string, error) {
+	if err := os.MkdirAll(c.outputDir, 0755); err != nil {
+		return "", err
 	}
-	
+
 	timestamp := report.GeneratedAt.Format("2006-01-02_15-04-05")
-	filename := filepath.Join(dir, fmt.Sprintf("aks_mentions_report_%s.json", timestamp))
-	
+	filename := filepath.Join(c.outputDir, fmt.Sprintf("aks_mentions_report_%s.json", timestamp))
+
 	data, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
-		return err
+		return "", err
 	}
-	
-	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return err
+
+	return filename, os.WriteFile(filename, data, 0644)
+}
+
+// saveHTMLReport writes the standalone HTML report next to the JSON one
+func (c *consoleNotifier) saveHTMLReport(report *models.Report) (string, error) {
+	page, err := reports.RenderHTML(report)
+	if err != nil {
+		return "", err
 	}
-	
-	fmt.Printf("\n💾 Report saved to: %s\n", filename)
-	return nil
+
+	timestamp := report.GeneratedAt.Format("2006-01-02_15-04-05")
+	filename := filepath.Join(c.outputDir, fmt.Sprintf("aks_mentions_report_%s.html", timestamp))
+	return filename, os.WriteFile(filename, page, 0644)
 }
 
-func main() {
-	fmt.Println("🤖 AKS Mentions Bot - Test Report Generator")
-	fmt.Println("==========================================")
-	
-	// Create test configuration
-	cfg := &config.Config{
-		ReportSchedule: "weekly",
-		Keywords:       []string{"aks", "azure kubernetes service", "kubefleet", "kaito"},
+// savePDFReport prints the saved HTML report to a PDF next to it
+func (c *consoleNotifier) savePDFReport(htmlFile string) (string, error) {
+	page, err := os.ReadFile(htmlFile)
+	if err != nil {
+		return "", err
 	}
-	
-	// Create test services
-	storage := &TestStorage{}
-	notifications := &TestNotificationService{}
-	
-	// Create monitoring service
-	service := monitoring.NewService(cfg, storage, notifications)
-	
-	// Generate sample mentions data
-	sampleMentions := []models.Mention{
+
+	pdf, err := c.pdf.RenderPDF(context.Background(), page)
+	if err != nil {
+		return "", err
+	}
+
+	filename := strings.TrimSuffix(htmlFile, ".html") + ".pdf"
+	return filename, os.WriteFile(filename, pdf, 0644)
+}
+
+// discardStorage satisfies storage.StorageInterface for commands that never persist mentions
+type discardStorage struct{}
+
+func (discardStorage) Store(filename string, data []byte) error { return nil }
+func (discardStorage) Retrieve(filename string) ([]byte, error) { return nil, storage.ErrNotFound }
+func (discardStorage) List(prefix string) ([]string, error)     { return nil, nil }
+func (discardStorage) Delete(filename string) error             { return nil }
+
+// sampleMentions returns a representative set of mentions across sources
+func sampleMentions() []models.Mention {
+	return []models.Mention{
 		{
 			ID:           "test_reddit_1",
 			Source:       "reddit",
@@ -226,21 +276,4 @@ func main() {
 			Keywords:     []string{"aks", "azure kubernetes service"},
 		},
 	}
-	
-	fmt.Printf("\n📊 Generating report with %d sample mentions...\n", len(sampleMentions))
-	
-	// Generate report using the monitoring service
-	report := service.GenerateTestReport(sampleMentions)
-	
-	// Send the report (outputs to terminal and saves to file)
-	if err := notifications.SendReport(report); err != nil {
-		fmt.Printf("❌ Error sending report: %v\n", err)
-		os.Exit(1)
-	}
-	
-	fmt.Println("\n✅ Test report generation completed!")
-	fmt.Println("\n💡 Next steps:")
-	fmt.Println("   • Check the 'test_output' directory for saved JSON report")
-	fmt.Println("   • Run 'go test ./internal/monitoring -v' for more detailed tests")
-	fmt.Println("   • Configure real API keys and run the full bot with 'go run cmd/bot/main.go'")
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newReprocessCommand(opts *globalOptions) *cobra.Command {
+	var since, stages string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "reprocess",
+		Short: "Re-run enrichment stages over stored mentions",
+		Long: `Re-run enrichment stages over the stored mentions created since --since, e.g.
+after changing the sentiment analysis or the keywords, without fetching them from
+the sources again. Stages: ` + strings.Join(monitoring.ReprocessStages, ", ") + `. The
+mentions blobs are rewritten in place along with the mention and search indexes
+and, with ENABLE_PARQUET_EXPORT, the Parquet partitions of the days they touch.
+Only the storage settings are required, plus the Azure OpenAI settings for LLM
+question detection.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := parseSince(since, time.Now())
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			parsed, err := monitoring.ParseStages(stages)
+			if err != nil {
+				return fmt.Errorf("--stages: %w", err)
+			}
+
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			if !cfg.StorageConfigured() {
+				return fmt.Errorf("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING is required")
+			}
+			store, err := newStorage(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			service := monitoring.NewService(cfg, store, nil)
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			start := time.Now()
+			result, err := service.Reprocess(ctx, from, parsed, dryRun)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				logrus.Infof("Dry run: %d of %d mentions since %s would change (%s), nothing written",
+					result.Changed, result.Mentions, from.Format("2006-01-02"), strings.Join(parsed, ", "))
+				return nil
+			}
+			logrus.Infof("Reprocessed %d mentions since %s (%s) in %v: %d changed, %d blobs rewritten",
+				result.Mentions, from.Format("2006-01-02"), strings.Join(parsed, ", "), time.Since(start), result.Changed, result.Blobs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "30d", "Reprocess mentions created since this RFC 3339 time, YYYY-MM-DD date or duration ago")
+	cmd.Flags().StringVar(&stages, "stages", "", "Comma-separated stages to re-run: "+strings.Join(monitoring.ReprocessStages, ", "))
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Count the mentions that would change without writing anything")
+	return cmd
+}
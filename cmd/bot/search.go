@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newRebuildSearchIndexCommand(opts *globalOptions) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rebuild-search-index",
+		Short: "Rebuild the full-text search index from stored mentions",
+		Long: `Regenerate the full-text search index from the mentions blobs in Azure Storage.
+Run it after changing the index format or if the index is lost. Only the storage
+settings (AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING, and
+AZURE_STORAGE_CONTAINER) are required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			if !cfg.StorageConfigured() {
+				return fmt.Errorf("AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING is required")
+			}
+
+			store, err := newStorage(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+
+			start := time.Now()
+			index, err := storage.RebuildSearchIndex(store)
+			if err != nil {
+				return fmt.Errorf("failed to rebuild search index: %w", err)
+			}
+			logrus.Infof("Indexed %d mentions in %v", index.Len(), time.Since(start))
+
+			if dryRun {
+				logrus.Info("Dry run, index not saved")
+				return nil
+			}
+
+			if err := index.Save(store); err != nil {
+				return fmt.Errorf("failed to save search index: %w", err)
+			}
+			logrus.Infof("Saved search index to %s", storage.SearchIndexBlob)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Build the index without saving it")
+	return cmd
+}
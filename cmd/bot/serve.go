@@ -0,0 +1,517 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/scheduler"
+	"github.com/azure/aks-mentions-bot/internal/teamsbot"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// deploymentDrainTimeout is how long a configuration change waits for in-flight runs before
+// cancelling them; the next run catches up on a cancelled run's window
+const deploymentDrainTimeout = 15 * time.Minute
+
+// keywordGroupsPollInterval is how often serve checks for keyword groups changed at runtime
+const keywordGroupsPollInterval = time.Minute
+
+func newServeCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the scheduler and HTTP API as a long-running service",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(opts)
+		},
+	}
+}
+
+func runServe(opts *globalOptions) error {
+	svc, err := newServices(opts)
+	if err != nil {
+		return err
+	}
+
+	logrus.Info("Starting AKS Mentions Bot")
+
+	current, err := newDeployment(opts, svc)
+	if err != nil {
+		return err
+	}
+	if err := current.start(); err != nil {
+		return err
+	}
+
+	// Send the report of a run the last shutdown interrupted
+	for _, s := range current.all {
+		if interrupted, err := s.monitoring.InterruptedRuns(); err != nil {
+			logrus.Warnf("Failed to check for interrupted runs%s: %v", profileLabel(s.config), err)
+		} else if len(interrupted) > 0 {
+			logrus.Infof("Catching up on %d monitoring runs%s interrupted by the last shutdown", len(interrupted), profileLabel(s.config))
+			monitoringService := s.monitoring
+			go func() {
+				if err := monitoringService.RunMonitoring(); errors.Is(err, monitoring.ErrRunInProgress) {
+					logrus.Info("A scheduled monitoring run is already catching up on the interrupted runs")
+				} else if err != nil {
+					logrus.Errorf("Catch-up monitoring run failed: %v", err)
+				}
+			}()
+		}
+	}
+
+	// Set up HTTP server for health checks and webhooks
+	handler := &deploymentHandler{}
+	handler.set(current)
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%s", svc.config.Port),
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Start HTTP server in a goroutine
+	go func() {
+		logrus.Infof("HTTP server starting on port %s", svc.config.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Fatalf("HTTP server failed: %v", err)
+		}
+	}()
+
+	// Apply changes to the settings and feature flags in App Configuration, and to keyword groups
+	refreshCtx, stopRefresh := context.WithCancel(context.Background())
+	var refreshing sync.WaitGroup
+	if svc.config.AppConfigEndpoint != "" && svc.config.AppConfigRefreshInterval > 0 {
+		refreshing.Add(1)
+		go func() {
+			defer refreshing.Done()
+			refreshAppConfiguration(refreshCtx, opts, svc.config, handler)
+		}()
+	}
+	refreshing.Add(1)
+	go func() {
+		defer refreshing.Done()
+		applyKeywordGroupChanges(refreshCtx, opts, handler)
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logrus.Info("Shutting down server...")
+	stopRefresh()
+	refreshing.Wait()
+	current = handler.current()
+	current.stop()
+
+	// Create a deadline for shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Cancel in-flight runs, which store what they collected before returning
+	current.shutdown(ctx)
+
+	// Shutdown HTTP server
+	if err := server.Shutdown(ctx); err != nil {
+		logrus.Errorf("Server forced to shutdown: %v", err)
+	}
+
+	logrus.Info("Server exited")
+	return nil
+}
+
+// deployment is the default configuration and its profiles as served: their services,
+// schedulers and routes. serve replaces it when App Configuration or keyword groups change.
+type deployment struct {
+	all           []*services // The default configuration first, then the profiles
+	router        *mux.Router
+	stopStream    context.CancelFunc
+	replacing     atomic.Bool // Set once a replacement stops the schedulers
+	storageChecks storageChecks
+}
+
+// newDeployment wires up the profiles hosted alongside the default configuration, each with
+// its own keywords, notification channels, storage prefix, schedules and API, and the routes
+// of them all. Nothing runs until start.
+func newDeployment(opts *globalOptions, svc *services) (*deployment, error) {
+	d := &deployment{all: []*services{svc}, stopStream: func() {}}
+	if opts.profile == "" {
+		for _, name := range svc.config.Profiles {
+			cfg, err := config.LoadProfile(name)
+			if err != nil {
+				return nil, err
+			}
+			profile, err := newServicesFor(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize profile %s: %w", name, err)
+			}
+			d.all = append(d.all, profile)
+		}
+	}
+
+	// Initialize schedulers
+	for _, s := range d.all {
+		s.scheduler = scheduler.NewService(s.config, s.monitoring).WithStateStore(s.storage)
+		if s.config.NotificationQueueMaxAttempts > 0 {
+			s.scheduler.WithDeliveryQueue(s.notifications)
+		}
+	}
+
+	router := mux.NewRouter()
+
+	// Liveness and readiness probes; /health is kept for probes configured before the split
+	router.HandleFunc("/livez", d.livezHandler).Methods("GET")
+	router.HandleFunc("/readyz", d.readyzHandler).Methods("GET")
+	router.HandleFunc("/health", d.livezHandler).Methods("GET")
+
+	// The API of the default configuration, and of each profile under /profiles/<name>/,
+	// behind their API tokens. Email preference links and mention actions carry their own
+	// credentials.
+	for _, s := range d.all {
+		public := router.NewRoute().Subrouter()
+		if s.config.Profile != "" {
+			public = router.PathPrefix("/profiles/" + s.config.Profile).Subrouter()
+		}
+		protected := public.NewRoute().Subrouter()
+		protected.Use(requireAPIToken(s.config.APIToken))
+		registerRoutes(public, protected, s)
+	}
+
+	// Slack /aksmentions slash command
+	if svc.config.SlackSigningSecret != "" {
+		router.HandleFunc("/slack/commands", slackCommandHandler(svc.monitoring, svc.config.SlackSigningSecret)).Methods("POST")
+	}
+
+	// Teams bot that answers questions about stored mentions when @mentioned
+	if svc.config.TeamsBotAppID != "" {
+		botClient, err := teamsbot.NewClient(svc.config.TeamsBotAppID, svc.config.TeamsBotAppPassword, svc.config.TeamsBotTenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Teams bot: %w", err)
+		}
+		router.HandleFunc("/api/messages", teamsBotHandler(svc.monitoring, teamsbot.NewAuthenticator(svc.config.TeamsBotAppID), botClient)).Methods("POST")
+	}
+
+	d.router = router
+	return d, nil
+}
+
+// start starts the schedulers and the X filtered stream
+func (d *deployment) start() error {
+	for _, s := range d.all {
+		if err := s.scheduler.Start(); err != nil {
+			return fmt.Errorf("failed to start scheduler%s: %w", profileLabel(s.config), err)
+		}
+	}
+	if len(d.all) > 1 {
+		logrus.Infof("Serving %d profiles alongside the default configuration", len(d.all)-1)
+	}
+
+	// Consume the X filtered stream for real-time urgent mentions
+	svc := d.all[0]
+	if svc.config.TwitterStreamEnabled && svc.config.SourceEnabled("twitter") {
+		streamCtx, stopStream := context.WithCancel(context.Background())
+		d.stopStream = stopStream
+		go func() {
+			if err := svc.monitoring.RunTwitterStream(streamCtx); err != nil && !errors.Is(err, context.Canceled) {
+				logrus.Errorf("X filtered stream stopped, urgent checks will poll Twitter search: %v", err)
+			}
+		}()
+	}
+	return nil
+}
+
+// stop stops the X filtered stream and the schedulers, leaving in-flight runs alone
+func (d *deployment) stop() {
+	d.stopStream()
+	for _, s := range d.all {
+		s.scheduler.Stop()
+	}
+}
+
+// keywordGroupsChanged reports whether the keyword groups stored for any configuration differ
+// from those it was built with
+func (d *deployment) keywordGroupsChanged() bool {
+	for _, s := range d.all {
+		overrides, err := monitoring.LoadKeywordOverrides(s.storage)
+		if err != nil {
+			logrus.Warnf("Failed to check keyword groups%s: %v", profileLabel(s.config), err)
+			continue
+		}
+		if !overrides.UpdatedAt.Equal(s.keywordsUpdatedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// drain waits until ctx is done for in-flight runs to finish; new runs are refused
+func (d *deployment) drain(ctx context.Context) error {
+	for _, s := range d.all {
+		if err := s.monitoring.Drain(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shutdown cancels in-flight runs, which store what they collected before returning
+func (d *deployment) shutdown(ctx context.Context) {
+	for _, s := range d.all {
+		if err := s.monitoring.Shutdown(ctx); err != nil {
+			logrus.Errorf("Monitoring runs%s forced to stop: %v", profileLabel(s.config), err)
+		}
+	}
+}
+
+// deploymentHandler serves the routes of the current deployment
+type deploymentHandler struct {
+	deployment atomic.Pointer[deployment]
+	replaceMu  sync.Mutex // Held while a deployment is replaced
+}
+
+func (h *deploymentHandler) set(d *deployment) {
+	h.deployment.Store(d)
+}
+
+func (h *deploymentHandler) current() *deployment {
+	return h.deployment.Load()
+}
+
+func (h *deploymentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.current().router.ServeHTTP(w, r)
+}
+
+// replace builds a deployment from the current configuration, waits for the in-flight runs
+// of the current one and hands over to it. Callers must hold replaceMu. An error means the
+// current deployment stays.
+func (h *deploymentHandler) replace(ctx context.Context, opts *globalOptions, change string) error {
+	svc, err := newServices(opts)
+	if err != nil {
+		return err
+	}
+	next, err := newDeployment(opts, svc)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Waiting for in-flight runs before applying %s changes", change)
+	previous := h.current()
+	previous.replacing.Store(true)
+	previous.stop()
+	drainCtx, cancel := context.WithTimeout(ctx, deploymentDrainTimeout)
+	defer cancel()
+	if err := previous.drain(drainCtx); err != nil {
+		logrus.Warnf("Cancelling runs still in flight: %v", err)
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelShutdown()
+	previous.shutdown(shutdownCtx)
+
+	if err := next.start(); err != nil {
+		// The configuration validated, so this is rare; the API is served either way
+		logrus.Errorf("Scheduled runs stopped after the %s change, restart to recover: %v", change, err)
+	}
+	h.set(next)
+	logrus.Infof("Applied %s changes", change)
+	return nil
+}
+
+// applyKeywordGroupChanges checks every keywordGroupsPollInterval until ctx is done whether
+// keyword groups were changed at runtime, through the API or the keywords command, and then
+// replaces the deployment so the next runs search the new keywords
+func applyKeywordGroupChanges(ctx context.Context, opts *globalOptions, handler *deploymentHandler) {
+	ticker := time.NewTicker(keywordGroupsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		handler.replaceMu.Lock()
+		if handler.current().keywordGroupsChanged() {
+			if err := handler.replace(ctx, opts, "keyword group"); err != nil {
+				logrus.Errorf("Not applying keyword group changes: %v", err)
+			}
+		}
+		handler.replaceMu.Unlock()
+	}
+}
+
+// registerRoutes adds the HTTP API of the default configuration or a profile. Routes that
+// check their own credentials go on public, the rest on protected.
+func registerRoutes(public, protected *mux.Router, svc *services) {
+	schedulerService := svc.scheduler
+
+	// Admin routes require ADMIN_API_TOKEN, in place of a profile's API token, and are not
+	// served without one
+	var admin *mux.Router
+	if svc.config.AdminToken != "" {
+		admin = public.PathPrefix("/api/admin").Subrouter()
+		admin.Use(requireAPIToken(svc.config.AdminToken))
+	}
+
+	// Metrics endpoint
+	protected.HandleFunc("/metrics", metricsHandler(svc.monitoring)).Methods("GET")
+
+	// Manual trigger endpoint (for testing)
+	protected.HandleFunc("/trigger", triggerHandler(svc.monitoring)).Methods("POST")
+
+	// Source health and self-test endpoints
+	protected.HandleFunc("/api/sources", sourcesHandler(svc.monitoring)).Methods("GET")
+	protected.HandleFunc("/api/sources/{name}/test", sourceTestHandler(svc.monitoring)).Methods("POST")
+
+	// Noise blocklist for authors, channels and domains; changing it is an admin action
+	protected.HandleFunc("/api/blocklist", blocklistHandler(svc.monitoring)).Methods("GET")
+	if admin != nil {
+		admin.HandleFunc("/blocklist/{kind}", blocklistAddHandler(svc.monitoring)).Methods("POST")
+		admin.HandleFunc("/blocklist/{kind}", blocklistRemoveHandler(svc.monitoring)).Methods("DELETE")
+	}
+
+	// Keyword groups changed at runtime, searched from the next run
+	if admin != nil {
+		admin.HandleFunc("/keywords", keywordGroupsHandler(svc.monitoring)).Methods("GET")
+		admin.HandleFunc("/keywords/{group}", keywordGroupSetHandler(svc.monitoring)).Methods("PUT")
+		admin.HandleFunc("/keywords/{group}", keywordGroupDeleteHandler(svc.monitoring)).Methods("DELETE")
+	}
+
+	// Scheduler status, and admin control endpoints
+	protected.HandleFunc("/api/scheduler", schedulerStatusHandler(schedulerService)).Methods("GET")
+	if admin != nil {
+		admin.HandleFunc("/scheduler/pause", schedulerPauseHandler(schedulerService)).Methods("POST")
+		admin.HandleFunc("/scheduler/resume", schedulerResumeHandler(schedulerService)).Methods("POST")
+		admin.HandleFunc("/scheduler/jobs/{name}", schedulerRescheduleHandler(schedulerService)).Methods("PUT")
+	}
+
+	// Email recipient preferences and unsubscribe links, which carry their own signed tokens
+	public.HandleFunc("/preferences", preferencesHandler(svc.notifications)).Methods("GET", "POST")
+	public.HandleFunc("/unsubscribe", unsubscribeHandler(svc.notifications)).Methods("GET", "POST")
+
+	// Notification deliveries waiting for a retry or given up on
+	protected.HandleFunc("/api/notifications/queue", notificationQueueHandler(svc.notifications)).Methods("GET")
+
+	// Audit trail of mentions the context filter dropped, which holds third-party content the
+	// bot otherwise discards
+	if admin != nil {
+		admin.HandleFunc("/mentions/rejected", rejectedMentionsHandler(svc.monitoring)).Methods("GET")
+	}
+
+	// Mentions submitted by people or other systems, collected by the next run. Submissions
+	// require INGEST_API_TOKEN, in place of a profile's API token.
+	if svc.config.IngestToken != "" {
+		requireIngestToken := requireAPIToken(svc.config.IngestToken)
+		public.Handle("/api/mentions", requireIngestToken(mentionSubmitHandler(svc.monitoring))).Methods("POST")
+	}
+
+	// Mention lifecycle actions from Logic Apps and Adaptive Card buttons, signed with
+	// INBOUND_WEBHOOK_SECRET
+	public.HandleFunc("/api/mentions/{id}/state", mentionStateHandler(svc.monitoring, svc.config.InboundWebhookSecret)).Methods("GET")
+	public.HandleFunc("/api/mentions/{id}/actions", mentionActionHandler(svc.monitoring, svc.config.InboundWebhookSecret)).Methods("POST")
+
+	// Curated mention tags, changed by curators with the API token
+	protected.HandleFunc("/api/tags", tagsHandler(svc.monitoring)).Methods("GET")
+	protected.HandleFunc("/api/mentions/{id}/tags", mentionTagsHandler(svc.monitoring)).Methods("GET")
+	protected.HandleFunc("/api/mentions/{id}/tags", mentionTagsAddHandler(svc.monitoring)).Methods("POST")
+	protected.HandleFunc("/api/mentions/{id}/tags/{tag}", mentionTagsRemoveHandler(svc.monitoring)).Methods("DELETE")
+
+	// Labeled corpus of false positives and negatives, and its replay against the current filters
+	protected.HandleFunc("/api/filter/corpus", filterCorpusHandler(svc.monitoring)).Methods("GET")
+	protected.HandleFunc("/api/filter/corpus", filterLabelHandler(svc.monitoring)).Methods("POST")
+	protected.HandleFunc("/api/filter/corpus/{id}", filterLabelRemoveHandler(svc.monitoring)).Methods("DELETE")
+	protected.HandleFunc("/api/filter/evaluation", filterEvaluationHandler(svc.monitoring)).Methods("GET")
+
+	// Dry run of a proposed keyword set, without storing or notifying
+	protected.HandleFunc("/api/preview", previewHandler(svc.monitoring)).Methods("GET")
+
+	// Rolling community sentiment score, recorded by every run that stores mentions
+	protected.HandleFunc("/api/sentiment", sentimentTrendHandler(svc.monitoring)).Methods("GET")
+
+	// Mention and sentiment counts per day, week or month for dashboards
+	protected.HandleFunc("/api/stats/timeline", timelineHandler(svc.monitoring)).Methods("GET")
+
+	// Aggregates over a quarter, release cycle or custom period for planning reviews
+	protected.HandleFunc("/api/analytics", analyticsHandler(svc.monitoring)).Methods("GET")
+
+	// Paid-API usage and estimated cost of recent runs
+	protected.HandleFunc("/api/costs", runCostsHandler(svc.monitoring)).Methods("GET")
+
+	// Bulk NDJSON export of stored mentions, paged with cursors
+	protected.HandleFunc("/api/mentions/export", exportHandler(svc.monitoring)).Methods("GET")
+
+	// Full-text search over stored mentions
+	protected.HandleFunc("/api/search", searchHandler(svc.monitoring)).Methods("GET")
+
+	// Mentions pushed as Server-Sent Events as runs store them, for live displays
+	protected.HandleFunc("/api/stream", streamHandler(svc.monitoring)).Methods("GET")
+
+	// Standalone HTML reports with charts, linked from notifications, and their PDF exports.
+	// Two reports can be compared. Reports, comparisons and the feed are opened from
+	// notifications and feed readers, which can't send an API token, so public comparisons
+	// need both report IDs; comparing the latest reports requires the API token. Reports of the
+	// mentions with a curated tag are built on request from up to 500 mentions and aren't
+	// linked from notifications, so they require the API token too.
+	protected.HandleFunc("/api/reports/compare/latest", reportCompareHandler(svc.monitoring, true)).Methods("GET")
+	protected.HandleFunc("/reports/tags/{tag}", tagReportHandler(svc.monitoring)).Methods("GET")
+	public.HandleFunc("/api/reports/compare", reportCompareHandler(svc.monitoring, false)).Methods("GET")
+	public.HandleFunc("/reports/{id}.pdf", reportPDFHandler(svc.monitoring)).Methods("GET")
+	public.HandleFunc("/reports/{id}", reportHandler(svc.monitoring)).Methods("GET")
+
+	// Mention pages linked from notifications in place of the original post, which they link to
+	public.HandleFunc("/m/{id}", mentionPageHandler(svc.monitoring)).Methods("GET")
+
+	// Mark handled and Escalate buttons on alerts, signed with INBOUND_WEBHOOK_SECRET
+	public.HandleFunc("/m/{id}/{action:handled|escalate}", mentionActionPageHandler(svc.monitoring, svc.notifications)).Methods("GET", "POST")
+
+	// Redirects in notifications that record which mentions people open, and their counts
+	if svc.config.EnableClickTracking {
+		public.HandleFunc("/r/{id}", clickHandler(svc.monitoring)).Methods("GET")
+		protected.HandleFunc("/api/clicks", clickStatsHandler(svc.monitoring)).Methods("GET")
+	}
+
+	// Atom/RSS feed of the newest mentions, for feed readers
+	public.HandleFunc("/feed.xml", feedHandler(svc.monitoring, svc.config.PublicBaseURL)).Methods("GET")
+}
+
+// requireAPIToken rejects requests that don't carry the token as a bearer token or in the
+// X-AKS-Mentions-Token header, and every request when the token is empty
+func requireAPIToken(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-AKS-Mentions-Token")
+			if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+				provided = strings.TrimPrefix(auth, "Bearer ")
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or missing API token"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// profileLabel names the profile of a configuration in log messages
+func profileLabel(cfg *config.Config) string {
+	if cfg.Profile == "" {
+		return ""
+	}
+	return fmt.Sprintf(" of profile %s", cfg.Profile)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/notifications"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRouter registers the routes of a configuration the way serve does
+func newTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	cfg := &config.Config{APIToken: "api-token", AdminToken: "admin-token"}
+	store := testutil.NewMemoryStorage()
+	notificationService := notifications.NewService(cfg)
+	svc := &services{
+		config:        cfg,
+		storage:       store,
+		notifications: notificationService,
+		monitoring:    monitoring.NewService(cfg, store, notificationService),
+	}
+
+	router := mux.NewRouter()
+	public := router.NewRoute().Subrouter()
+	protected := public.NewRoute().Subrouter()
+	protected.Use(requireAPIToken(cfg.APIToken))
+	registerRoutes(public, protected, svc)
+	return router
+}
+
+func TestRegisterRoutes_tokens(t *testing.T) {
+	router := newTestRouter(t)
+
+	serve := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	protected := []struct{ method, path string }{
+		{"GET", "/metrics"},
+		{"POST", "/trigger"},
+		{"GET", "/api/search?q=aks"},
+		{"GET", "/api/tags"},
+		{"GET", "/api/reports/compare/latest"},
+		{"GET", "/reports/tags/gpu"},
+	}
+	for _, route := range protected {
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, ""), "%s %s without a token", route.method, route.path)
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, "wrong"), "%s %s with a wrong token", route.method, route.path)
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, "admin-token"), "%s %s with the admin token", route.method, route.path)
+	}
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/tags", "api-token"))
+
+	admin := []struct{ method, path string }{
+		{"GET", "/api/admin/keywords"},
+		{"POST", "/api/admin/scheduler/pause"},
+		{"GET", "/api/admin/mentions/rejected"},
+	}
+	for _, route := range admin {
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, ""), "%s %s without a token", route.method, route.path)
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, "wrong"), "%s %s with a wrong token", route.method, route.path)
+		assert.Equal(t, http.StatusUnauthorized, serve(route.method, route.path, "api-token"), "%s %s with the API token", route.method, route.path)
+	}
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/admin/keywords", "admin-token"))
+
+	// Reports, comparisons by ID and the feed are opened from notifications without a token
+	public := []struct{ method, path string }{
+		{"GET", "/reports/20240710-120000"},
+		{"GET", "/api/reports/compare?a=20240703-120000&b=20240710-120000"},
+		{"GET", "/feed.xml"},
+	}
+	for _, route := range public {
+		assert.NotEqual(t, http.StatusUnauthorized, serve(route.method, route.path, ""), "%s %s without a token", route.method, route.path)
+	}
+	assert.Equal(t, http.StatusOK, serve("GET", "/feed.xml", ""))
+}
@@ -0,0 +1,683 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/notifications"
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Formats setup writes the configuration in
+const (
+	setupFormatEnv  = "env"  // A .env file, as loaded by --env-file
+	setupFormatYAML = "yaml" // A Kubernetes Secret to load with envFrom
+)
+
+// errSetupCancelled stops setup when its input ends before every question is answered
+var errSetupCancelled = errors.New("setup cancelled: input ended before every question was answered")
+
+var (
+	storageAccountPattern   = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+	storageContainerPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9]|-[a-z0-9]){2,62}$`)
+)
+
+// setupSetting is a setting a source needs before setup can enable it
+type setupSetting struct {
+	key    string
+	prompt string
+	secret bool
+}
+
+// setupSourceSettings lists the settings of the sources that don't work without them; the
+// other sources in config.KnownSources only need to be left enabled
+var setupSourceSettings = map[string][]setupSetting{
+	"reddit": {
+		{key: "REDDIT_CLIENT_ID", prompt: "Reddit client ID"},
+		{key: "REDDIT_CLIENT_SECRET", prompt: "Reddit client secret", secret: true},
+	},
+	"twitter":   {{key: "TWITTER_BEARER_TOKEN", prompt: "X API bearer token", secret: true}},
+	"youtube":   {{key: "YOUTUBE_API_KEY", prompt: "YouTube Data API key", secret: true}},
+	"linkedin":  {{key: "BING_SEARCH_API_KEY", prompt: "Bing Search API key", secret: true}},
+	"web":       {{key: "BING_SEARCH_API_KEY", prompt: "Bing Search API key", secret: true}},
+	"gitlab":    {{key: "GITLAB_TOKEN", prompt: "GitLab token with read_api scope", secret: true}},
+	"bitbucket": {{key: "BITBUCKET_REPOSITORIES", prompt: "Bitbucket repositories (workspace/repo, comma-separated)"}},
+	"threads":   {{key: "THREADS_ACCESS_TOKEN", prompt: "Threads access token", secret: true}},
+	"podcast":   {{key: "PODCAST_FEEDS", prompt: "Podcast RSS feed URLs (comma-separated)"}},
+	"linkedinpage": {
+		{key: "LINKEDIN_ACCESS_TOKEN", prompt: "LinkedIn page administrator token (r_organization_social)", secret: true},
+		{key: "LINKEDIN_ORGANIZATION_ID", prompt: "LinkedIn organization ID of the page"},
+	},
+	"cnfeeds": {{key: "CN_FEEDS", prompt: "Chinese community RSS or Atom feed URLs (comma-separated)"}},
+}
+
+func newSetupCommand(opts *globalOptions) *cobra.Command {
+	var output, format string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Interactively create a configuration for a first deployment",
+		Long: `Walk through the settings a deployment needs: keywords, notification channels,
+Azure Storage and the sources to search with their API keys. Each answer is checked
+as it is given, and storage access, a test notification and each source's credentials
+can be tried before moving on. Values already in the environment or --env-file are
+offered as defaults.
+
+The configuration is written as a .env file (--format env, to --env-file by default)
+or as a Kubernetes Secret to load with envFrom (--format yaml). The file holds secrets,
+so keep it out of source control.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != setupFormatEnv && format != setupFormatYAML {
+				return fmt.Errorf("--format must be '%s' or '%s'", setupFormatEnv, setupFormatYAML)
+			}
+			if output == "" {
+				output = opts.envFile
+				if format == setupFormatYAML {
+					output = "aks-mentions-bot-config.yaml"
+				}
+			}
+			// Keep client logging out of the questions unless debugging; failed checks are
+			// reported with the question they belong to
+			if !opts.debug {
+				logrus.SetLevel(logrus.FatalLevel)
+			}
+
+			// Settings of an existing .env that setup doesn't ask about are kept
+			existing := make(map[string]string)
+			if format == setupFormatEnv {
+				if values, err := godotenv.Read(output); err == nil {
+					existing = values
+				}
+			}
+
+			w := &setupWizard{
+				prompt:   &prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()},
+				values:   make(map[string]string),
+				existing: existing,
+			}
+			if err := w.run(cmd.Context()); err != nil {
+				return err
+			}
+			return w.write(output, format, force)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write (default --env-file, or aks-mentions-bot-config.yaml with --format yaml)")
+	cmd.Flags().StringVar(&format, "format", setupFormatEnv, "Output format: env or yaml")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the output file without asking")
+	return cmd
+}
+
+// setupWizard collects the settings setup writes, keyed by environment variable
+type setupWizard struct {
+	prompt   *prompter
+	values   map[string]string // Answers; empty for settings to leave out
+	existing map[string]string // Settings of the file being replaced
+}
+
+func (w *setupWizard) run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	w.prompt.printf("🛠️  AKS Mentions Bot - Setup\n%s\n", strings.Repeat("-", 28))
+	w.prompt.printf("Press Enter to keep the value in brackets. Secrets are shown as you type them.\n")
+
+	steps := []func(context.Context) error{w.askKeywords, w.askNotifications, w.askStorage, w.askSources, w.askAPIToken}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+
+	w.prompt.printf("\n🔎 Checking the whole configuration...\n")
+	if _, err := config.LoadValues(w.settings()); err != nil {
+		w.prompt.printf("   ❌ %v\n", err)
+		write, err := w.prompt.confirm("Write the configuration anyway?", false)
+		if err != nil {
+			return err
+		}
+		if !write {
+			return fmt.Errorf("setup stopped: the configuration is not valid")
+		}
+		return nil
+	}
+	w.prompt.printf("   ✅ Configuration is valid\n")
+	return nil
+}
+
+func (w *setupWizard) askKeywords(ctx context.Context) error {
+	w.prompt.printf("\n🔑 Keywords\n")
+	defaults, err := config.ParseValues(nil)
+	if err != nil {
+		return err
+	}
+
+	keywords, err := w.prompt.ask("Keywords to search for (comma-separated)", w.current("KEYWORDS", strings.Join(defaults.Keywords, ",")), false, required)
+	if err != nil {
+		return err
+	}
+	w.values["KEYWORDS"] = keywords
+
+	schedule, err := w.prompt.ask("Report schedule (daily or weekly)", w.current("REPORT_SCHEDULE", defaults.ReportSchedule), false, oneOf("daily", "weekly"))
+	if err != nil {
+		return err
+	}
+	w.values["REPORT_SCHEDULE"] = schedule
+	return nil
+}
+
+// askAPIToken asks for the token the HTTP API requires, suggesting a random one
+func (w *setupWizard) askAPIToken(ctx context.Context) error {
+	w.prompt.printf("\n🔐 HTTP API\n")
+	generated := make([]byte, 24)
+	if _, err := rand.Read(generated); err != nil {
+		return fmt.Errorf("failed to generate an API token: %w", err)
+	}
+	token, err := w.prompt.ask("API token the HTTP API requires", w.current("API_TOKEN", hex.EncodeToString(generated)), true, required)
+	if err != nil {
+		return err
+	}
+	w.values["API_TOKEN"] = token
+	return nil
+}
+
+func (w *setupWizard) askNotifications(ctx context.Context) error {
+	w.prompt.printf("\n📣 Notifications\n")
+	for {
+		if err := w.askTeams(); err != nil {
+			return err
+		}
+		if err := w.askEmail(); err != nil {
+			return err
+		}
+		if err := w.askSlack(); err != nil {
+			return err
+		}
+		cfg, err := config.ParseValues(w.settings())
+		if err != nil {
+			w.prompt.printf("   ❌ %v\n", err)
+			continue
+		}
+		if cfg.TeamsEnabled() || len(cfg.EmailRecipients) > 0 || len(cfg.OutboundWebhookURLs) > 0 || len(cfg.NotificationChannels) > 0 {
+			break
+		}
+		w.prompt.printf("   ❌ Reports need at least one of Teams, email or Slack\n")
+	}
+
+	test, err := w.prompt.confirm("Send a test message to each notification target now?", false)
+	if err != nil || !test {
+		return err
+	}
+	cfg, err := config.ParseValues(w.settings())
+	if err != nil {
+		return err
+	}
+	for _, result := range notifications.NewService(cfg).Ping() {
+		if result.Err != nil {
+			w.prompt.printf("   ❌ %s: %v\n", result.Target, result.Err)
+		} else {
+			w.prompt.printf("   ✅ %s: test message sent (%s)\n", result.Target, result.Detail)
+		}
+	}
+	return nil
+}
+
+func (w *setupWizard) askTeams() error {
+	enabled, err := w.prompt.confirm("Send reports to a Microsoft Teams channel?", w.current("TEAMS_WEBHOOK_URL", "") != "")
+	if err != nil || !enabled {
+		w.values["TEAMS_WEBHOOK_URL"] = ""
+		return err
+	}
+	webhook, err := w.prompt.ask("Teams incoming webhook or workflow URL", w.current("TEAMS_WEBHOOK_URL", ""), true, httpsURL)
+	if err != nil {
+		return err
+	}
+	w.values["TEAMS_WEBHOOK_URL"] = webhook
+	return nil
+}
+
+func (w *setupWizard) askEmail() error {
+	settings := []string{"EMAIL_RECIPIENTS", "SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD"}
+	enabled, err := w.prompt.confirm("Send reports by email over SMTP?", w.current("EMAIL_RECIPIENTS", "") != "")
+	if err != nil || !enabled {
+		for _, key := range settings {
+			w.values[key] = ""
+		}
+		return err
+	}
+
+	questions := []struct {
+		key, prompt, fallback string
+		secret                bool
+		check                 func(string) error
+	}{
+		{"EMAIL_RECIPIENTS", "Email recipients (comma-separated)", "", false, w.validRecipients},
+		{"SMTP_HOST", "SMTP host", "smtp.office365.com", false, required},
+		{"SMTP_PORT", "SMTP port", "587", false, port},
+		{"SMTP_USERNAME", "SMTP username", "", false, required},
+		{"SMTP_PASSWORD", "SMTP password", "", true, required},
+	}
+	for _, q := range questions {
+		value, err := w.prompt.ask(q.prompt, w.current(q.key, q.fallback), q.secret, q.check)
+		if err != nil {
+			return err
+		}
+		w.values[q.key] = value
+	}
+	return nil
+}
+
+func (w *setupWizard) askSlack() error {
+	current := strings.TrimPrefix(w.current("NOTIFICATION_CHANNELS", ""), "slack;url=")
+	if strings.ContainsAny(current, ",;") {
+		// Channels set up by hand are kept as they are
+		w.values["NOTIFICATION_CHANNELS"] = w.current("NOTIFICATION_CHANNELS", "")
+		w.prompt.printf("Keeping NOTIFICATION_CHANNELS from the environment\n")
+		return nil
+	}
+
+	enabled, err := w.prompt.confirm("Send reports to a Slack channel?", current != "")
+	if err != nil || !enabled {
+		w.values["NOTIFICATION_CHANNELS"] = ""
+		return err
+	}
+	webhook, err := w.prompt.ask("Slack incoming webhook URL", current, true, httpsURL)
+	if err != nil {
+		return err
+	}
+	w.values["NOTIFICATION_CHANNELS"] = "slack;url=" + webhook
+	return nil
+}
+
+func (w *setupWizard) askStorage(ctx context.Context) error {
+	w.prompt.printf("\n💾 Azure Storage\n")
+	for {
+		useIdentity := w.current("AZURE_STORAGE_CONNECTION_STRING", "") == ""
+		identity, err := w.prompt.confirm("Reach the storage account with managed or workload identity (no for a connection string, e.g. Azurite)?", useIdentity)
+		if err != nil {
+			return err
+		}
+
+		if identity {
+			w.values["AZURE_STORAGE_CONNECTION_STRING"] = ""
+			account, err := w.prompt.ask("Storage account name", w.current("AZURE_STORAGE_ACCOUNT", ""), false, matches(storageAccountPattern, "3 to 24 lowercase letters and digits"))
+			if err != nil {
+				return err
+			}
+			w.values["AZURE_STORAGE_ACCOUNT"] = account
+		} else {
+			w.values["AZURE_STORAGE_ACCOUNT"] = ""
+			connection, err := w.prompt.ask("Storage connection string", w.current("AZURE_STORAGE_CONNECTION_STRING", ""), true, connectionString)
+			if err != nil {
+				return err
+			}
+			w.values["AZURE_STORAGE_CONNECTION_STRING"] = connection
+		}
+
+		container, err := w.prompt.ask("Blob container", w.current("AZURE_STORAGE_CONTAINER", "mentions"), false,
+			matches(storageContainerPattern, "3 to 63 lowercase letters, digits and single hyphens"))
+		if err != nil {
+			return err
+		}
+		w.values["AZURE_STORAGE_CONTAINER"] = container
+
+		test, err := w.prompt.confirm("Check that the container can be written and read now?", true)
+		if err != nil {
+			return err
+		}
+		if !test {
+			return nil
+		}
+		cfg, err := config.ParseValues(w.settings())
+		if err != nil {
+			return err
+		}
+		result := checkStorageAccess(cfg, func(kind, target, status, detail string) preflightCheck {
+			return preflightCheck{Check: kind, Target: target, Status: status, Detail: detail}
+		})
+		if result.Status == preflightReady {
+			w.prompt.printf("   ✅ %s: %s\n", result.Target, result.Detail)
+			return nil
+		}
+		w.prompt.printf("   ❌ %s: %s\n", result.Target, result.Detail)
+		if retry, err := w.prompt.confirm("Change the storage settings?", true); err != nil || !retry {
+			return err
+		}
+	}
+}
+
+func (w *setupWizard) askSources(ctx context.Context) error {
+	w.prompt.printf("\n🌐 Sources\n")
+	for _, name := range config.KnownSources {
+		if err := w.askSource(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// askSource asks whether to search a source and for the settings it needs, then offers to
+// probe it with a single-keyword search as test-sources does
+func (w *setupWizard) askSource(ctx context.Context, name string) error {
+	enabledKey := strings.ToUpper(name) + "_ENABLED"
+	settings := setupSourceSettings[name]
+
+	configured := true
+	for _, setting := range settings {
+		if w.current(setting.key, "") == "" {
+			configured = false
+		}
+	}
+	enabledByDefault := configured && w.current(enabledKey, "true") != "false"
+
+	enabled, err := w.prompt.confirm(fmt.Sprintf("Search %s?", name), enabledByDefault)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		w.values[enabledKey] = "false"
+		return nil
+	}
+	w.values[enabledKey] = ""
+	if len(settings) == 0 {
+		return nil
+	}
+
+	for {
+		for _, setting := range settings {
+			if _, asked := w.values[setting.key]; asked && !configuredBy(name, setting.key) {
+				continue // Shared with a source asked about earlier, e.g. the Bing key
+			}
+			value, err := w.prompt.ask("   "+setting.prompt, w.current(setting.key, ""), setting.secret, required)
+			if err != nil {
+				return err
+			}
+			w.values[setting.key] = value
+		}
+
+		test, err := w.prompt.confirm(fmt.Sprintf("   Test %s now?", name), true)
+		if err != nil || !test {
+			return err
+		}
+		cfg, err := config.ParseValues(w.settings())
+		if err != nil {
+			return err
+		}
+		result, err := monitoring.NewService(cfg, discardStorage{}, nil).TestSource(ctx, name, "")
+		switch {
+		case err != nil:
+			w.prompt.printf("   ❌ %v\n", err)
+		case !result.Success:
+			w.prompt.printf("   ❌ %s\n", result.Error)
+		default:
+			w.prompt.printf("   ✅ %d mentions for %q in %s\n", result.MentionCount, result.Keyword, result.Duration)
+			return nil
+		}
+		if retry, err := w.prompt.confirm(fmt.Sprintf("   Change the %s settings?", name), true); err != nil || !retry {
+			return err
+		}
+	}
+}
+
+// configuredBy reports whether a setting belongs to the source rather than one it shares with
+// a source earlier in config.KnownSources
+func configuredBy(name, key string) bool {
+	for _, source := range config.KnownSources {
+		for _, setting := range setupSourceSettings[source] {
+			if setting.key == key {
+				return source == name
+			}
+		}
+	}
+	return true
+}
+
+// current returns the value to offer for a setting: the answer given earlier, the value in
+// the file being replaced or the environment, or fallback
+func (w *setupWizard) current(key, fallback string) string {
+	if value, answered := w.values[key]; answered {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+	if value := w.existing[key]; value != "" {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// settings returns what setup writes: the answers, over the settings of the file being
+// replaced that setup didn't ask about
+func (w *setupWizard) settings() map[string]string {
+	settings := make(map[string]string, len(w.existing)+len(w.values))
+	for key, value := range w.existing {
+		settings[key] = value
+	}
+	for key, value := range w.values {
+		if value == "" {
+			delete(settings, key)
+		} else {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// write saves the settings to path, asking before overwriting a file unless force is set
+func (w *setupWizard) write(path, format string, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		overwrite, err := w.prompt.confirm(fmt.Sprintf("%s exists. Overwrite it?", path), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("setup stopped: %s was left unchanged", path)
+		}
+	}
+
+	settings := w.settings()
+	var content string
+	if format == setupFormatYAML {
+		content = secretManifest(settings)
+	} else {
+		env, err := godotenv.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		content = "# Written by aks-mentions-bot setup; see .env.example for every setting\n" + env + "\n"
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	w.prompt.printf("\n✅ Wrote %d settings to %s\n", len(settings), path)
+	if format == setupFormatYAML {
+		w.prompt.printf("   Apply it with kubectl apply -f %s and load it with envFrom: [{secretRef: {name: aks-mentions-bot-config}}]\n", path)
+	} else {
+		w.prompt.printf("   Check it with validate-config --preflight, then start the bot with serve\n")
+	}
+	return nil
+}
+
+// secretManifest renders the settings as a Kubernetes Secret in the aks-mentions-bot namespace
+func secretManifest(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("# Written by aks-mentions-bot setup\napiVersion: v1\nkind: Secret\nmetadata:\n")
+	b.WriteString("  name: aks-mentions-bot-config\n  namespace: aks-mentions-bot\n  labels:\n    app: aks-mentions-bot\ntype: Opaque\nstringData:\n")
+	for _, key := range keys {
+		// A JSON string is a valid double-quoted YAML scalar
+		quoted, _ := json.Marshal(values[key])
+		fmt.Fprintf(&b, "  %s: %s\n", key, quoted)
+	}
+	return b.String()
+}
+
+// prompter asks questions on the command's input and output
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *prompter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// ask reads an answer until check accepts it; an empty answer keeps current. Current secrets
+// are not shown.
+func (p *prompter) ask(question, current string, secret bool, check func(string) error) (string, error) {
+	for {
+		shown := current
+		if secret && current != "" {
+			shown = "keep current"
+		}
+		if shown != "" {
+			p.printf("%s [%s]: ", question, shown)
+		} else {
+			p.printf("%s: ", question)
+		}
+
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = current
+		}
+		if check != nil {
+			if err := check(answer); err != nil {
+				p.printf("   ❌ %v\n", err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// confirm asks a yes or no question, returning fallback for an empty answer
+func (p *prompter) confirm(question string, fallback bool) (bool, error) {
+	options := "y/N"
+	if fallback {
+		options = "Y/n"
+	}
+	for {
+		p.printf("%s [%s]: ", question, options)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return fallback, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		p.printf("   ❌ answer yes or no\n")
+	}
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		p.printf("\n")
+		return "", errSetupCancelled
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func required(value string) error {
+	if value == "" {
+		return fmt.Errorf("a value is required")
+	}
+	return nil
+}
+
+func oneOf(options ...string) func(string) error {
+	return func(value string) error {
+		for _, option := range options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("answer %s", strings.Join(options, " or "))
+	}
+}
+
+func matches(pattern *regexp.Regexp, description string) func(string) error {
+	return func(value string) error {
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("use %s", description)
+		}
+		return nil
+	}
+}
+
+func httpsURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("enter an https:// URL")
+	}
+	return nil
+}
+
+func port(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("enter a port between 1 and 65535")
+	}
+	return nil
+}
+
+func connectionString(value string) error {
+	if !strings.Contains(value, "AccountName=") && !strings.Contains(value, "UseDevelopmentStorage=true") {
+		return fmt.Errorf("enter a connection string with AccountName=, or UseDevelopmentStorage=true for Azurite")
+	}
+	return nil
+}
+
+// validRecipients checks EMAIL_RECIPIENTS entries, including their preferences
+func (w *setupWizard) validRecipients(value string) error {
+	if err := required(value); err != nil {
+		return err
+	}
+	settings := w.settings()
+	settings["EMAIL_RECIPIENTS"] = value
+	cfg, err := config.ParseValues(settings)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range cfg.EmailRecipients {
+		if err := cfg.ValidateRecipient(recipient); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/slack"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// slackMaxBody bounds slash command payloads, which are a few hundred bytes
+	slackMaxBody = 64 * 1024
+	// slackResultLimit is the number of mentions listed in a query reply
+	slackResultLimit = 10
+	// slackTriggerInterval is the least time between monitoring runs triggered from Slack
+	slackTriggerInterval = 15 * time.Minute
+)
+
+// slackCommandHandler serves the /aksmentions slash command. Queries are answered directly;
+// triggered runs are acknowledged at once and their outcome posted to the command's response_url.
+// A run isn't triggered while another is in progress, or within slackTriggerInterval of the
+// last run triggered from Slack.
+func slackCommandHandler(monitoringService *monitoring.Service, secret string) http.HandlerFunc {
+	responder := slack.NewResponder()
+	var triggerMu sync.Mutex
+	var lastTrigger time.Time
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxBody))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
+			return
+		}
+		if err := slack.VerifyRequest(secret, r.Header, body, time.Now()); err != nil {
+			logrus.Warnf("Rejected Slack command: %v", err)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid form body"})
+			return
+		}
+
+		command, err := slack.ParseCommand(form.Get("text"), sourceNames(monitoringService))
+		if err != nil {
+			writeJSON(w, http.StatusOK, slack.Response{
+				ResponseType: slack.ResponseEphemeral,
+				Text:         fmt.Sprintf("%s\n\n%s", slack.Escape(err.Error()), slack.Usage),
+			})
+			return
+		}
+
+		switch command.Action {
+		case slack.ActionHelp:
+			writeJSON(w, http.StatusOK, slack.Response{ResponseType: slack.ResponseEphemeral, Text: slack.Usage})
+		case slack.ActionTrigger:
+			if monitoringService.MonitoringRunning() {
+				writeJSON(w, http.StatusOK, slack.Response{
+					ResponseType: slack.ResponseEphemeral,
+					Text:         "A monitoring run is already in progress; its report is sent when it finishes.",
+				})
+				return
+			}
+			triggerMu.Lock()
+			next := lastTrigger.Add(slackTriggerInterval)
+			allowed := !time.Now().Before(next)
+			if allowed {
+				lastTrigger = time.Now()
+			}
+			triggerMu.Unlock()
+			if !allowed {
+				writeJSON(w, http.StatusOK, slack.Response{
+					ResponseType: slack.ResponseEphemeral,
+					Text:         fmt.Sprintf("A monitoring run was triggered from Slack recently; try again after %s UTC.", next.UTC().Format("15:04")),
+				})
+				return
+			}
+
+			user := form.Get("user_name")
+			responseURL := form.Get("response_url")
+			go func() {
+				logrus.Infof("Monitoring run triggered from Slack by %s", user)
+				reply := slack.Response{ResponseType: slack.ResponseInChannel, Text: "Monitoring run finished."}
+				if err := monitoringService.RunMonitoring(); err != nil {
+					logrus.Errorf("Monitoring run triggered from Slack failed: %v", err)
+					reply = slack.Response{
+						ResponseType: slack.ResponseEphemeral,
+						Text:         "Monitoring run failed: " + slack.Escape(err.Error()),
+					}
+				}
+				if responseURL == "" {
+					return
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+				if err := responder.Reply(ctx, responseURL, reply); err != nil {
+					logrus.Warnf("Failed to post the monitoring run outcome to Slack: %v", err)
+				}
+			}()
+			writeJSON(w, http.StatusOK, slack.Response{
+				ResponseType: slack.ResponseInChannel,
+				Text:         "Monitoring run started. I'll post here when it finishes.",
+			})
+		default:
+			writeJSON(w, http.StatusOK, slackQueryResponse(monitoringService, command))
+		}
+	}
+}
+
+// slackQueryResponse runs a slash command query and formats the matches
+func slackQueryResponse(monitoringService *monitoring.Service, command *slack.Command) slack.Response {
+	responseType := slack.ResponseEphemeral
+	if command.Public {
+		responseType = slack.ResponseInChannel
+	}
+
+	query := monitoring.MentionQuery{
+		Text:      command.Text,
+		Tag:       command.Tag,
+		Sentiment: command.Sentiment,
+		Source:    command.Source,
+		Limit:     slackResultLimit,
+	}
+	if command.Window > 0 {
+		query.Since = time.Now().Add(-command.Window)
+	}
+
+	result, err := monitoringService.QueryMentions(query)
+	if err != nil {
+		if !errors.Is(err, monitoring.ErrInvalidQuery) && !errors.Is(err, monitoring.ErrInvalidTag) {
+			logrus.Errorf("Slack mention query failed: %v", err)
+		}
+		return slack.Response{ResponseType: slack.ResponseEphemeral, Text: "Query failed: " + slack.Escape(err.Error())}
+	}
+
+	var text strings.Builder
+	text.WriteString(describeSlackQuery(command, result.Total))
+	for _, hit := range result.Hits {
+		title := hit.Title
+		if title == "" {
+			title = hit.URL
+		}
+		fmt.Fprintf(&text, "\n• %s – %s, %s", slack.Link(hit.URL, fmt.Sprintf("[%s] %s", hit.Source, title)),
+			hit.CreatedAt.Format("Jan 2 15:04"), valueOr(hit.Sentiment, "unscored"))
+	}
+	if result.Total > len(result.Hits) {
+		fmt.Fprintf(&text, "\n…and %d more", result.Total-len(result.Hits))
+	}
+
+	return slack.Response{ResponseType: responseType, Text: text.String()}
+}
+
+// describeSlackQuery summarizes a query, e.g. "3 negative reddit mentions in the last 7 days"
+func describeSlackQuery(command *slack.Command, total int) string {
+	var parts []string
+	parts = append(parts, fmt.Sprintf("*%d*", total))
+	if command.Sentiment != "" {
+		parts = append(parts, command.Sentiment)
+	}
+	if command.Source != "" {
+		parts = append(parts, command.Source)
+	}
+	if total == 1 {
+		parts = append(parts, "mention")
+	} else {
+		parts = append(parts, "mentions")
+	}
+	if command.Tag != "" {
+		parts = append(parts, "tagged #"+slack.Escape(command.Tag))
+	}
+	if command.Text != "" {
+		parts = append(parts, fmt.Sprintf("matching \"%s\"", slack.Escape(command.Text)))
+	}
+	if command.Window > 0 {
+		parts = append(parts, "in the last "+formatWindow(command.Window))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatWindow(window time.Duration) string {
+	hours := int(window.Hours())
+	if hours%24 != 0 {
+		return pluralize(hours, "hour")
+	}
+	return pluralize(hours/24, "day")
+}
+
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// sourceNames lists the configured source names, which chat queries use as filters
+func sourceNames(monitoringService *monitoring.Service) []string {
+	statuses := monitoringService.GetSourceStatuses()
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, strings.ToLower(status.Name))
+	}
+	return names
+}
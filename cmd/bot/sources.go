@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newTestSourcesCommand(opts *globalOptions) *cobra.Command {
+	var names []string
+	var keyword string
+
+	cmd := &cobra.Command{
+		Use:   "test-sources",
+		Short: "Probe each configured source with a single keyword",
+		Long: `Probe each configured source with a single keyword search over the last 24 hours
+and report whether it succeeded. Notification settings and Azure Storage are not
+required. Exits non-zero if any enabled source fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := parseConfig(opts)
+			if err != nil {
+				return err
+			}
+			// Keep per-fetch logging out of the results unless debugging
+			if !opts.debug {
+				logrus.SetLevel(logrus.WarnLevel)
+			}
+
+			service := monitoring.NewService(cfg, discardStorage{}, nil)
+			statuses := make(map[string]monitoring.SourceStatus)
+			for _, status := range service.GetSourceStatuses() {
+				statuses[status.Name] = status
+				if !cmd.Flags().Changed("source") {
+					names = append(names, status.Name)
+				}
+			}
+
+			fmt.Println("🔍 AKS Mentions Bot - Source Connectivity Test")
+			fmt.Println(strings.Repeat("-", 46))
+
+			failed := 0
+			for _, name := range names {
+				fmt.Printf("🔸 Testing %s... ", name)
+
+				if status, ok := statuses[name]; ok && !status.Enabled {
+					fmt.Printf("⚠️  DISABLED (credentials %s)\n", status.Credentials)
+					continue
+				}
+
+				result, err := service.TestSource(context.Background(), name, keyword)
+				switch {
+				case err != nil:
+					fmt.Printf("❌ ERROR: %v\n", err)
+					failed++
+				case result.Success:
+					fmt.Printf("✅ SUCCESS (%d mentions for %q in %s)\n", result.MentionCount, result.Keyword, result.Duration)
+					if len(result.SampleTitles) > 0 {
+						fmt.Printf("   📝 Sample: %q\n", result.SampleTitles[0])
+					}
+				default:
+					fmt.Printf("❌ ERROR: %s\n", result.Error)
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d sources failed", failed, len(names))
+			}
+			fmt.Println("\n✅ Source connectivity test completed")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&names, "source", nil, "Source to test (repeatable; default all configured sources)")
+	cmd.Flags().StringVar(&keyword, "keyword", "", "Keyword to search for (default the first configured keyword)")
+	return cmd
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/teamsbot"
+	"github.com/sirupsen/logrus"
+)
+
+// teamsBotMaxBody bounds Bot Framework activities
+const teamsBotMaxBody = 256 * 1024
+
+// teamsBotHandler serves the Bot Framework messaging endpoint. Messages that @mention the bot
+// are read as questions about stored mentions and answered in the same conversation.
+func teamsBotHandler(monitoringService *monitoring.Service, auth *teamsbot.Authenticator, client *teamsbot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var activity teamsbot.Activity
+		if err := json.NewDecoder(io.LimitReader(r.Body, teamsBotMaxBody)).Decode(&activity); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid activity"})
+			return
+		}
+
+		if err := auth.Authenticate(r.Context(), r.Header.Get("Authorization"), &activity); err != nil {
+			logrus.Warnf("Rejected Teams bot request: %v", err)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		// Conversation updates, reactions and the like need no answer
+		if activity.Type != teamsbot.ActivityMessage {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		question := activity.MessageText()
+		answer := teamsBotAnswer(monitoringService, teamsbot.ParseQuery(question, sourceNames(monitoringService)))
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+		if err := client.Reply(ctx, &activity, answer); err != nil {
+			logrus.Errorf("Failed to answer Teams bot question %q: %v", question, err)
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": "failed to send reply"})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// teamsBotAnswer runs a question as a mention query and formats the matches as Markdown
+func teamsBotAnswer(monitoringService *monitoring.Service, question *teamsbot.Query) string {
+	if question.Help {
+		return teamsbot.Usage
+	}
+
+	query := monitoring.MentionQuery{
+		Text:      question.Text,
+		Tag:       question.Tag,
+		Sentiment: question.Sentiment,
+		Source:    question.Source,
+		Limit:     question.Limit,
+	}
+	if question.Window > 0 {
+		query.Since = time.Now().Add(-question.Window)
+	}
+	if question.Top {
+		query.Sort = monitoring.SortRelevance
+	}
+
+	result, err := monitoringService.QueryMentions(query)
+	if err == nil && result.Total == 0 && query.Text != teamsbot.Singular(query.Text) {
+		query.Text = teamsbot.Singular(query.Text)
+		result, err = monitoringService.QueryMentions(query)
+	}
+	if err != nil {
+		if !errors.Is(err, monitoring.ErrInvalidQuery) && !errors.Is(err, monitoring.ErrInvalidTag) {
+			logrus.Errorf("Teams bot mention query failed: %v", err)
+		}
+		return fmt.Sprintf("Sorry, I couldn't run that query: %s\n\n%s", err, teamsbot.Usage)
+	}
+
+	var answer strings.Builder
+	answer.WriteString(describeTeamsBotQuery(question, query.Text, result.Total))
+	if len(result.Hits) > 0 {
+		answer.WriteString("\n\n")
+	}
+	for i, hit := range result.Hits {
+		title := hit.Title
+		if title == "" {
+			title = hit.URL
+		}
+		fmt.Fprintf(&answer, "%d. [%s](%s) – %s, %s, %s\n", i+1, markdownEscaper.Replace(title), markdownURLEscaper.Replace(hit.URL),
+			hit.Source, hit.CreatedAt.Format("Jan 2 15:04"), valueOr(hit.Sentiment, "unscored"))
+	}
+	if result.Total > len(result.Hits) {
+		fmt.Fprintf(&answer, "\n…and %d more.", result.Total-len(result.Hits))
+	}
+	return answer.String()
+}
+
+// describeTeamsBotQuery summarizes a question, e.g. "**3** negative mentions about "upgrade" in the last 7 days"
+func describeTeamsBotQuery(question *teamsbot.Query, text string, total int) string {
+	parts := []string{fmt.Sprintf("**%d**", total)}
+	if question.Sentiment != "" {
+		parts = append(parts, question.Sentiment)
+	}
+	if question.Source != "" {
+		parts = append(parts, question.Source)
+	}
+	if total == 1 {
+		parts = append(parts, "mention")
+	} else {
+		parts = append(parts, "mentions")
+	}
+	if question.Tag != "" {
+		parts = append(parts, "tagged "+markdownEscaper.Replace(question.Tag))
+	}
+	if text != "" {
+		parts = append(parts, fmt.Sprintf("about \"%s\"", markdownEscaper.Replace(text)))
+	}
+	if question.Window > 0 {
+		parts = append(parts, "in the last "+formatWindow(question.Window))
+	}
+	if question.Top {
+		parts = append(parts, "(most relevant first)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Escapers for the Markdown characters that would break a list item, link text or link target
+var (
+	markdownEscaper    = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`")
+	markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+)
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newValidateConfigCommand(opts *globalOptions) *cobra.Command {
+	var preflight, noPing bool
+
+	cmd := &cobra.Command{
+		Use:     "validate-config",
+		Aliases: []string{"validate"},
+		Short:   "Validate configuration from the environment and print a summary",
+		Long: `Validate configuration from the environment and print a summary. Without
+--profile, every profile listed in PROFILES is validated too.
+
+With --preflight, also check that the deployment is ready to run: storage is
+readable and writable, Key Vault references in App Configuration resolve, every
+notification target accepts a test message (the first email recipient only; skip
+with --no-ping) and every enabled source answers a single-keyword search. The
+results are printed as a readiness matrix and the exit code is non-zero if any
+check fails, for CI and first-time setup.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfg *config.Config
+			var err error
+			if opts.profile == "" {
+				cfg, err = config.Load()
+			} else if err = checkProfile(opts.profile); err == nil {
+				cfg, err = config.LoadProfile(opts.profile)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := printConfigSummary("✅ Configuration is valid", cfg); err != nil {
+				return err
+			}
+
+			configs := []*config.Config{cfg}
+			for _, name := range cfg.Profiles {
+				profile, err := config.LoadProfile(name)
+				if err != nil {
+					return err
+				}
+				title := fmt.Sprintf("\n👥 Profile %s is valid (%s* settings, storage prefix %s)", name, config.ProfileEnvPrefix(name), profile.StoragePrefix())
+				if err := printConfigSummary(title, profile); err != nil {
+					return err
+				}
+				configs = append(configs, profile)
+			}
+
+			if !preflight {
+				return nil
+			}
+			// Keep per-check logging out of the matrix unless debugging
+			if !opts.debug {
+				logrus.SetLevel(logrus.WarnLevel)
+			}
+			var checks []preflightCheck
+			for _, c := range configs {
+				checks = append(checks, runPreflight(context.Background(), c, !noPing)...)
+			}
+			return printReadiness(checks)
+		},
+	}
+
+	cmd.Flags().BoolVar(&preflight, "preflight", false, "Check storage, Key Vault, notification targets and sources, and print a readiness matrix")
+	cmd.Flags().BoolVar(&noPing, "no-ping", false, "With --preflight, don't send test messages to notification targets")
+	return cmd
+}
+
+// printConfigSummary prints the schedule, keywords, sources and notification channels of a
+// validated configuration under a title
+func printConfigSummary(title string, cfg *config.Config) error {
+	var enabled []string
+	for _, name := range sources.Names() {
+		if cfg.SourceEnabled(name) {
+			enabled = append(enabled, name)
+		}
+	}
+
+	urgent := enabled
+	if len(cfg.UrgentSources) > 0 {
+		urgent = nil
+		for _, name := range cfg.UrgentSources {
+			if !containsName(sources.Names(), name) {
+				return fmt.Errorf("URGENT_SOURCES: unknown source %q", name)
+			}
+			if cfg.SourceEnabled(name) {
+				urgent = append(urgent, name)
+			}
+		}
+	}
+
+	var channels []string
+	if cfg.TeamsDeliveryMode == "graph" && cfg.TeamsEnabled() {
+		description := "teams (graph"
+		if cfg.NotificationLocale != "en" {
+			description += ", " + cfg.NotificationLocale
+		}
+		channels = append(channels, description+")")
+	}
+	if len(cfg.EmailRecipients) > 0 {
+		channels = append(channels, fmt.Sprintf("email (%s, %d recipients)", cfg.EmailDeliveryMode, len(cfg.EmailRecipients)))
+	}
+	for _, channel := range cfg.Channels() {
+		description := channel.Name + " (" + channel.Type
+		if channel.Format != "" && channel.Format != channel.Type {
+			description += ", " + channel.Format
+		}
+		// Generic webhooks get the report data, which isn't localized
+		if channel.Locale != "" && channel.Locale != "en" && channel.Type != config.ChannelWebhook {
+			description += ", " + channel.Locale
+		}
+		channels = append(channels, description+")")
+	}
+
+	fmt.Println(title)
+	fmt.Printf("   Schedule:      %s (%s)\n", cfg.ReportSchedule, cfg.TimeZone)
+	fmt.Printf("   Keywords:      %s\n", strings.Join(cfg.Keywords, ", "))
+	fmt.Printf("   Sources:       %s\n", strings.Join(enabled, ", "))
+	if len(cfg.SourceSchedules) > 0 {
+		scheduled := make([]string, 0, len(cfg.SourceSchedules))
+		for source, schedule := range cfg.SourceSchedules {
+			scheduled = append(scheduled, source+" "+schedule)
+		}
+		sort.Strings(scheduled)
+		fmt.Printf("   Own schedule:  %s\n", strings.Join(scheduled, ", "))
+	}
+	fmt.Printf("   Urgent checks: %s for %s\n", strings.Join(urgent, ", "), strings.Join(cfg.UrgentKeywordList(), ", "))
+	fmt.Printf("   Notifications: %s\n", strings.Join(channels, ", "))
+	storageAuth := "managed identity"
+	switch {
+	case cfg.StorageConnectionString != "":
+		storageAuth = "connection string"
+	case cfg.StorageSASToken != "":
+		storageAuth = "SAS token"
+	}
+	fmt.Printf("   Storage:       %s/%s (%s)\n", cfg.StorageAccount, cfg.StorageContainer, storageAuth)
+	if cfg.StorageEncryptionKeyID != "" {
+		fmt.Printf("   Encryption:    AES-256-GCM, data key wrapped by %s\n", cfg.StorageEncryptionKeyID)
+	}
+	if cfg.AppConfigEndpoint != "" && cfg.Profile == "" {
+		flags := make([]string, 0, len(cfg.FeatureFlags))
+		for name, enabled := range cfg.FeatureFlags {
+			flags = append(flags, fmt.Sprintf("%s=%t", name, enabled))
+		}
+		sort.Strings(flags)
+		fmt.Printf("   App Config:    %s (feature flags: %s)\n", cfg.AppConfigEndpoint, strings.Join(flags, ", "))
+	}
+	if cfg.AzureMonitorConfigured() {
+		fmt.Printf("   Azure Monitor: %s (%s, namespace %s)\n", cfg.AzureMonitorResourceID, cfg.AzureMonitorRegion, cfg.AzureMonitorNamespace)
+	}
+	return nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+// Package appconfig loads settings and feature flags from Azure App Configuration, resolving
+// Key Vault references so secrets stay in Key Vault.
+package appconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	apiVersion         = "1.0"
+	keyVaultAPIVersion = "7.4"
+
+	// featureFlagPrefix is the key prefix App Configuration stores feature flags under
+	featureFlagPrefix = ".appconfig.featureflag/"
+
+	keyVaultRefContentType = "application/vnd.microsoft.appconfig.keyvaultref+json"
+)
+
+// keyVaultDomains are the Key Vault DNS suffixes of the Azure clouds; Key Vault references
+// may only point at secrets in these, so the Key Vault token isn't sent anywhere else
+var keyVaultDomains = []string{"vault.azure.net", "vault.azure.cn", "vault.usgovcloudapi.net", "vault.microsoftazure.de"}
+
+// Client reads key-values and feature flags from an Azure App Configuration store with the
+// default Azure credential chain (workload identity, managed identity, CLI). Key Vault
+// references are resolved with the same credential.
+type Client struct {
+	client     *resty.Client
+	endpoint   string
+	credential azcore.TokenCredential
+
+	mu     sync.Mutex
+	tokens map[string]*azauth.TokenSource // By scope
+}
+
+type keyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ContentType string `json:"content_type"`
+}
+
+type keyValuePage struct {
+	Items    []keyValue `json:"items"`
+	NextLink string     `json:"@nextLink"`
+}
+
+type featureFlag struct {
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NewClient creates a client for the App Configuration store at endpoint, e.g.
+// https://aks-mentions.azconfig.io
+func NewClient(endpoint string) (*Client, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("App Configuration endpoint is required")
+	}
+
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create App Configuration credential: %w", err)
+	}
+
+	return &Client{
+		client:     resty.New().SetTimeout(30 * time.Second),
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		credential: credential,
+	}, nil
+}
+
+// Load reads the settings whose keys start with keyPrefix, keyed without it, and every
+// feature flag, all with the given label (empty for keys without a label)
+func (c *Client) Load(ctx context.Context, keyPrefix, label string) (*config.RemoteSettings, error) {
+	settings := &config.RemoteSettings{
+		Values:       make(map[string]string),
+		FeatureFlags: make(map[string]bool),
+	}
+
+	values, err := c.list(ctx, keyPrefix, label)
+	if err != nil {
+		return nil, err
+	}
+	for _, kv := range values {
+		value := kv.Value
+		if strings.HasPrefix(kv.ContentType, keyVaultRefContentType) {
+			if value, err = c.resolveSecret(ctx, kv); err != nil {
+				return nil, err
+			}
+			settings.Secrets = append(settings.Secrets, strings.TrimPrefix(kv.Key, keyPrefix))
+		}
+		settings.Values[strings.TrimPrefix(kv.Key, keyPrefix)] = value
+	}
+
+	flags, err := c.list(ctx, featureFlagPrefix, label)
+	if err != nil {
+		return nil, err
+	}
+	for _, kv := range flags {
+		var flag featureFlag
+		if err := json.Unmarshal([]byte(kv.Value), &flag); err != nil {
+			return nil, fmt.Errorf("invalid feature flag %s: %w", kv.Key, err)
+		}
+		if flag.ID == "" {
+			flag.ID = strings.TrimPrefix(kv.Key, featureFlagPrefix)
+		}
+		settings.FeatureFlags[flag.ID] = flag.Enabled
+	}
+
+	return settings, nil
+}
+
+// list returns the key-values whose keys start with prefix, following every page
+func (c *Client) list(ctx context.Context, prefix, label string) ([]keyValue, error) {
+	if label == "" {
+		label = "\x00" // Keys without a label
+	}
+	query := url.Values{}
+	query.Set("key", prefix+"*")
+	query.Set("label", label)
+	query.Set("api-version", apiVersion)
+	next := "/kv?" + query.Encode()
+
+	token, err := c.getToken(ctx, c.endpoint+"/.default")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []keyValue
+	for next != "" {
+		var page keyValuePage
+		resp, err := c.client.R().
+			SetContext(ctx).
+			SetAuthToken(token).
+			SetHeader("Accept", "application/vnd.microsoft.appconfig.kvset+json, application/json").
+			SetResult(&page).
+			Get(c.endpoint + next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call App Configuration: %w", err)
+		}
+		if resp.StatusCode() != 200 {
+			return nil, fmt.Errorf("App Configuration returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+		}
+
+		items = append(items, page.Items...)
+		next = page.NextLink
+	}
+	return items, nil
+}
+
+// resolveSecret reads the Key Vault secret a Key Vault reference points to
+func (c *Client) resolveSecret(ctx context.Context, kv keyValue) (string, error) {
+	var reference struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal([]byte(kv.Value), &reference); err != nil || reference.URI == "" {
+		return "", fmt.Errorf("invalid Key Vault reference in %s", kv.Key)
+	}
+	scope, err := keyVaultSecretScope(reference.URI)
+	if err != nil {
+		return "", fmt.Errorf("invalid Key Vault reference in %s: %w", kv.Key, err)
+	}
+
+	token, err := c.getToken(ctx, scope)
+	if err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		Value string `json:"value"`
+	}
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetQueryParam("api-version", keyVaultAPIVersion).
+		SetResult(&secret).
+		Get(reference.URI)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Key Vault secret for %s: %w", kv.Key, err)
+	}
+	if resp.StatusCode() != 200 {
+		return "", fmt.Errorf("Key Vault returned status %d for %s: %s", resp.StatusCode(), kv.Key, string(resp.Body()))
+	}
+	return secret.Value, nil
+}
+
+// keyVaultSecretScope checks that uri names a secret in Key Vault, e.g.
+// https://<vault>.vault.azure.net/secrets/<name>, returning the token scope of its cloud
+func keyVaultSecretScope(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil || parsed.Port() != "" {
+		return "", fmt.Errorf("%q is not an https Key Vault URI", uri)
+	}
+	if !strings.HasPrefix(parsed.Path, "/secrets/") || len(parsed.Path) == len("/secrets/") {
+		return "", fmt.Errorf("%q is not a Key Vault secret", uri)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, domain := range keyVaultDomains {
+		if name := strings.TrimSuffix(host, "."+domain); name != host && name != "" && !strings.Contains(name, ".") {
+			return "https://" + domain + "/.default", nil
+		}
+	}
+	return "", fmt.Errorf("%q is not in a Key Vault domain", uri)
+}
+
+// getToken returns an access token for scope from the token source of that scope
+func (c *Client) getToken(ctx context.Context, scope string) (string, error) {
+	c.mu.Lock()
+	source, ok := c.tokens[scope]
+	if !ok {
+		if c.tokens == nil {
+			c.tokens = make(map[string]*azauth.TokenSource)
+		}
+		source = azauth.NewTokenSource(c.credential, scope)
+		c.tokens[scope] = source
+	}
+	c.mu.Unlock()
+
+	return source.Token(ctx)
+}
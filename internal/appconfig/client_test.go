@@ -0,0 +1,126 @@
+package appconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vaultTransport sends the requests to Key Vault to a test server
+type vaultTransport struct {
+	server *httptest.Server
+}
+
+func (v vaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "contoso.vault.azure.net" {
+		target, _ := url.Parse(v.server.URL)
+		req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// scopeCredential returns a token naming the scope it was requested for
+type scopeCredential struct{}
+
+func (scopeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token-for-" + options.Scopes[0], ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestClient_Load(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/secrets/teams-webhook/1":
+			assert.Equal(t, "Bearer token-for-https://vault.azure.net/.default", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"value": "https://contoso.webhook.office.com/secret"}`))
+		case r.URL.Path == "/kv" && r.URL.Query().Get("key") == "aks-mentions:*":
+			assert.Equal(t, "Bearer token-for-"+server.URL+"/.default", r.Header.Get("Authorization"))
+			assert.Equal(t, "production", r.URL.Query().Get("label"))
+			if r.URL.Query().Get("after") == "" {
+				w.Write([]byte(`{"items": [{"key": "aks-mentions:KEYWORDS", "value": "AKS,KubeFleet"}], "@nextLink": "/kv?key=aks-mentions:*&label=production&after=1&api-version=1.0"}`))
+				return
+			}
+			w.Write([]byte(`{"items": [{"key": "aks-mentions:TEAMS_WEBHOOK_URL", "value": "{\"uri\": \"https://contoso.vault.azure.net/secrets/teams-webhook/1\"}",
+				"content_type": "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"}]}`))
+		case r.URL.Path == "/kv" && r.URL.Query().Get("key") == ".appconfig.featureflag/*":
+			w.Write([]byte(`{"items": [{"key": ".appconfig.featureflag/llm-enrichment", "value": "{\"id\": \"llm-enrichment\", \"enabled\": false}",
+				"content_type": "application/vnd.microsoft.appconfig.ff+json;charset=utf-8"}]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{client: resty.New().SetTransport(vaultTransport{server}), endpoint: server.URL, credential: scopeCredential{}}
+	settings, err := client.Load(context.Background(), "aks-mentions:", "production")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"KEYWORDS":          "AKS,KubeFleet",
+		"TEAMS_WEBHOOK_URL": "https://contoso.webhook.office.com/secret",
+	}, settings.Values)
+	assert.Equal(t, map[string]bool{"llm-enrichment": false}, settings.FeatureFlags)
+	assert.Equal(t, []string{"TEAMS_WEBHOOK_URL"}, settings.Secrets)
+}
+
+func TestClient_Load_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "\x00", r.URL.Query().Get("label"), "keys without a label are loaded by default")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := &Client{client: resty.New(), endpoint: server.URL, credential: scopeCredential{}}
+	_, err := client.Load(context.Background(), "", "")
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestKeyVaultSecretScope(t *testing.T) {
+	scope, err := keyVaultSecretScope("https://contoso.vault.azure.net/secrets/teams-webhook")
+	require.NoError(t, err)
+	assert.Equal(t, "https://vault.azure.net/.default", scope)
+	scope, err = keyVaultSecretScope("https://contoso.vault.usgovcloudapi.net/secrets/teams-webhook/1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://vault.usgovcloudapi.net/.default", scope)
+
+	for _, uri := range []string{
+		"http://contoso.vault.azure.net/secrets/teams-webhook",
+		"https://contoso.vault.azure.net.attacker.example/secrets/teams-webhook",
+		"https://attacker.example/secrets/teams-webhook",
+		"https://vault.azure.net/secrets/teams-webhook",
+		"https://a.b.vault.azure.net/secrets/teams-webhook",
+		"https://contoso.vault.azure.net:8443/secrets/teams-webhook",
+		"https://contoso.vault.azure.net/keys/storage",
+		"https://contoso.vault.azure.net/secrets/",
+	} {
+		_, err := keyVaultSecretScope(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestClient_Load_RejectsForeignSecretURI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/kv" {
+			t.Errorf("the Key Vault token was sent to %s", r.URL)
+		}
+		w.Write([]byte(`{"items": [{"key": "TEAMS_WEBHOOK_URL", "value": "{\"uri\": \"https://attacker.example/secrets/x\"}",
+			"content_type": "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"}]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{client: resty.New(), endpoint: server.URL, credential: scopeCredential{}}
+	_, err := client.Load(context.Background(), "", "")
+	assert.ErrorContains(t, err, "not in a Key Vault domain")
+}
@@ -0,0 +1,59 @@
+// Package azauth shares access tokens for the Azure APIs the bot calls without an SDK client.
+package azauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// tokenURL is the request the bearer token policy authorizes; it is never sent
+const tokenURL = "https://token.aks-mentions.invalid/"
+
+// TokenSource hands out access tokens for one scope. Tokens come from azcore's bearer token
+// policy, which caches them and refreshes them before they expire, so a TokenSource can be
+// shared by concurrent callers.
+type TokenSource struct {
+	scope    string
+	pipeline runtime.Pipeline
+}
+
+// NewTokenSource creates a token source for scope, e.g. https://vault.azure.net/.default
+func NewTokenSource(credential azcore.TokenCredential, scope string) *TokenSource {
+	return &TokenSource{
+		scope: scope,
+		pipeline: runtime.NewPipeline("azauth", "v1.0.0", runtime.PipelineOptions{
+			PerCall: []policy.Policy{runtime.NewBearerTokenPolicy(credential, []string{scope}, nil)},
+		}, &policy.ClientOptions{
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+			Telemetry: policy.TelemetryOptions{Disabled: true},
+			Transport: captureTransport{},
+		}),
+	}
+}
+
+// Token returns a current access token
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, tokenURL)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.pipeline.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire token for %s: %w", s.scope, err)
+	}
+	return strings.TrimPrefix(resp.Request.Header.Get("Authorization"), "Bearer "), nil
+}
+
+// captureTransport ends the pipeline without sending the request, answering with an empty
+// response that carries the authorized request
+type captureTransport struct{}
+
+func (captureTransport) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
@@ -0,0 +1,61 @@
+package azauth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCredential issues numbered tokens valid for ttl
+type countingCredential struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	calls  int
+	scopes []string
+	err    error
+}
+
+func (c *countingCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return azcore.AccessToken{}, c.err
+	}
+	c.calls++
+	c.scopes = options.Scopes
+	return azcore.AccessToken{Token: "token-" + string(rune('0'+c.calls)), ExpiresOn: time.Now().Add(c.ttl)}, nil
+}
+
+func TestTokenSource(t *testing.T) {
+	credential := &countingCredential{ttl: time.Hour}
+	source := NewTokenSource(credential, "https://vault.azure.net/.default")
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, []string{"https://vault.azure.net/.default"}, credential.scopes)
+
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token, "tokens are cached until close to expiry")
+	assert.Equal(t, 1, credential.calls)
+
+	// Expired tokens are refreshed
+	expiring := &countingCredential{}
+	source = NewTokenSource(expiring, "https://monitor.azure.com/.default")
+	_, err = source.Token(context.Background())
+	require.NoError(t, err)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	_, err = NewTokenSource(&countingCredential{err: errors.New("no identity")}, "https://vault.azure.net/.default").Token(context.Background())
+	assert.ErrorContains(t, err, "no identity")
+}
@@ -0,0 +1,131 @@
+// Package azmonitor publishes custom metrics to Azure Monitor, so alerts and workbooks can
+// be built on the bot's run aggregates in Azure Monitor.
+package azmonitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+)
+
+const monitoringScope = "https://monitoring.azure.com/.default"
+
+// Metric is a custom metric with one value per combination of dimension values
+type Metric struct {
+	Name       string
+	Dimensions []string // Dimension names; empty for a metric without dimensions
+	Series     []Series
+}
+
+// Series is the value of a metric for one combination of dimension values
+type Series struct {
+	DimValues []string // In the order of the metric's dimensions
+	Value     float64
+}
+
+// Client sends custom metrics for an Azure resource to the regional Azure Monitor metrics
+// endpoint with the default Azure credential chain (workload identity, managed identity,
+// CLI). The identity needs the Monitoring Metrics Publisher role on the resource.
+type Client struct {
+	client   *resty.Client
+	endpoint string
+	tokens   *azauth.TokenSource
+}
+
+type metricRequest struct {
+	Time time.Time `json:"time"`
+	Data struct {
+		BaseData metricData `json:"baseData"`
+	} `json:"data"`
+}
+
+type metricData struct {
+	Metric    string         `json:"metric"`
+	Namespace string         `json:"namespace"`
+	DimNames  []string       `json:"dimNames,omitempty"`
+	Series    []metricSeries `json:"series"`
+}
+
+type metricSeries struct {
+	DimValues []string `json:"dimValues,omitempty"`
+	Min       float64  `json:"min"`
+	Max       float64  `json:"max"`
+	Sum       float64  `json:"sum"`
+	Count     int      `json:"count"`
+}
+
+// NewClient creates a client publishing metrics for the Azure resource with the given ID,
+// e.g. the AKS cluster the bot runs in, located in region, e.g. eastus
+func NewClient(region, resourceID string) (*Client, error) {
+	if region == "" || resourceID == "" {
+		return nil, fmt.Errorf("Azure Monitor region and resource ID are required")
+	}
+
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Monitor credential: %w", err)
+	}
+
+	return &Client{
+		client:   resty.New().SetTimeout(30 * time.Second),
+		endpoint: fmt.Sprintf("https://%s.monitoring.azure.com/%s/metrics", strings.ToLower(region), strings.Trim(resourceID, "/")),
+		tokens:   azauth.NewTokenSource(credential, monitoringScope),
+	}, nil
+}
+
+// Emit sends the metrics under namespace, timestamped at, one request per metric. Every
+// metric is sent even when an earlier one fails; the first failure is returned.
+func (c *Client) Emit(ctx context.Context, namespace string, at time.Time, metrics []Metric) error {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, metric := range metrics {
+		if len(metric.Series) == 0 {
+			continue
+		}
+		if err := c.emit(ctx, token, namespace, at, metric); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (c *Client) emit(ctx context.Context, token, namespace string, at time.Time, metric Metric) error {
+	request := metricRequest{Time: at.UTC()}
+	request.Data.BaseData = metricData{
+		Metric:    metric.Name,
+		Namespace: namespace,
+		DimNames:  metric.Dimensions,
+	}
+	for _, series := range metric.Series {
+		request.Data.BaseData.Series = append(request.Data.BaseData.Series, metricSeries{
+			DimValues: series.DimValues,
+			Min:       series.Value,
+			Max:       series.Value,
+			Sum:       series.Value,
+			Count:     1,
+		})
+	}
+
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetHeader("Content-Type", "application/json").
+		SetBody(request).
+		Post(c.endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to send metric %s to Azure Monitor: %w", metric.Name, err)
+	}
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("Azure Monitor returned status %d for metric %s: %s", resp.StatusCode(), metric.Name, string(resp.Body()))
+	}
+	return nil
+}
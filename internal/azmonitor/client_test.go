@@ -0,0 +1,76 @@
+package azmonitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scopeCredential returns a token naming the scope it was requested for
+type scopeCredential struct{}
+
+func (scopeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token-for-" + options.Scopes[0], ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestClient_Emit(t *testing.T) {
+	var requests []metricRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token-for-https://monitoring.azure.com/.default", r.Header.Get("Authorization"))
+
+		body, _ := io.ReadAll(r.Body)
+		var request metricRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		requests = append(requests, request)
+
+		if request.Data.BaseData.Metric == "RunDurationSeconds" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "InvalidMetricTime"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &Client{client: resty.New(), endpoint: server.URL, tokens: azauth.NewTokenSource(scopeCredential{}, monitoringScope)}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := client.Emit(context.Background(), "AKSMentionsBot", at, []Metric{
+		{Name: "RunDurationSeconds", Series: []Series{{Value: 42}}},
+		{Name: "Mentions", Dimensions: []string{"KeywordGroup"}, Series: []Series{
+			{DimValues: []string{"all"}, Value: 12},
+			{DimValues: []string{"fleet"}, Value: 3},
+		}},
+		{Name: "NegativeRatio", Dimensions: []string{"KeywordGroup"}},
+	})
+	assert.EqualError(t, err, `Azure Monitor returned status 400 for metric RunDurationSeconds: {"error": "InvalidMetricTime"}`)
+
+	require.Len(t, requests, 2, "later metrics are sent after a failure, metrics without values are not")
+	mentions := requests[1]
+	assert.Equal(t, at, mentions.Time)
+	assert.Equal(t, metricData{
+		Metric:    "Mentions",
+		Namespace: "AKSMentionsBot",
+		DimNames:  []string{"KeywordGroup"},
+		Series: []metricSeries{
+			{DimValues: []string{"all"}, Min: 12, Max: 12, Sum: 12, Count: 1},
+			{DimValues: []string{"fleet"}, Min: 3, Max: 3, Sum: 3, Count: 1},
+		},
+	}, mentions.Data.BaseData)
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient("", "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerService/managedClusters/aks")
+	assert.Error(t, err)
+}
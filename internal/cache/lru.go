@@ -0,0 +1,97 @@
+// Package cache provides a small in-memory least-recently-used cache.
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is a fixed-size cache that evicts the least recently used entry when full. It is
+// safe for concurrent use. A nil LRU caches nothing, so callers can leave it unset.
+type LRU[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Front is the most recently used
+	entries map[K]*list.Element
+	hits    int
+	misses  int
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// NewLRU creates a cache holding up to size entries. It returns nil, a cache that holds
+// nothing, when size is not positive.
+func NewLRU[K comparable, V any](size int) *LRU[K, V] {
+	if size <= 0 {
+		return nil
+	}
+	return &LRU[K, V]{size: size, order: list.New(), entries: make(map[K]*list.Element)}
+}
+
+// Get returns the value cached under key and marks it recently used
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return zero, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Add caches value under key, evicting the least recently used entry if the cache is full
+func (c *LRU[K, V]) Add(key K, value V) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+	}
+}
+
+// Len returns the number of cached entries
+func (c *LRU[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns how many lookups found a cached value and how many did not
+func (c *LRU[K, V]) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
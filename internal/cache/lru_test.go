@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// "b" is now the least recently used
+	c.Add("c", 3)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Add("a", 10)
+	value, _ = c.Get("a")
+	assert.Equal(t, 10, value)
+
+	hits, misses := c.Stats()
+	assert.Equal(t, 2, hits)
+	assert.Equal(t, 1, misses)
+}
+
+func TestLRU_Disabled(t *testing.T) {
+	c := NewLRU[string, int](0)
+	assert.Nil(t, c)
+
+	c.Add("a", 1)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_Concurrent(t *testing.T) {
+	c := NewLRU[string, int](10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%d-%d", worker, j%20)
+				c.Add(key, j)
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, c.Len())
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Notification channel types
+const (
+	ChannelTeams    = "teams"    // Teams incoming webhook or workflow, posting MessageCards or Logic App payloads
+	ChannelLogicApp = "logicapp" // Logic Apps or Power Automate flow, posting Logic App payloads
+	ChannelSlack    = "slack"    // Slack incoming webhook
+	ChannelWebhook  = "webhook"  // Generic JSON webhook, signed with OUTBOUND_WEBHOOK_SECRET
+)
+
+// NotificationLocales lists the languages report notifications can be posted in
+var NotificationLocales = []string{"en", "de", "es", "fr", "ja", "pt"}
+
+// NotificationChannel is a webhook that receives reports and alerts. Each channel is
+// delivered to and retried on its own, so one failing target doesn't hold up the others.
+type NotificationChannel struct {
+	Name    string // Used in logs and errors, "<type>-<n>" unless set
+	Type    string // ChannelTeams, ChannelLogicApp, ChannelSlack or ChannelWebhook
+	URL     string
+	Format  string // Teams payload, TeamsWebhookFormatMessageCard or TeamsWebhookFormatLogicApp
+	Retries int    // Attempts after a failed post, NOTIFICATION_RETRIES unless set
+	Locale  string // Language of the channel's reports, NOTIFICATION_LOCALE unless set
+}
+
+// parseNotificationChannels parses NOTIFICATION_CHANNELS entries such as
+// "teams;url=https://...;format=logicapp;name=aks-team;retries=3;locale=de"
+func parseNotificationChannels(channels string, retries int, locale string) ([]NotificationChannel, error) {
+	var result []NotificationChannel
+	counts := make(map[string]int)
+
+	for _, entry := range strings.Split(channels, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		channel, err := parseNotificationChannel(entry, retries, locale)
+		if err != nil {
+			return nil, err
+		}
+		counts[channel.Type]++
+		if channel.Name == "" {
+			channel.Name = fmt.Sprintf("%s-%d", channel.Type, counts[channel.Type])
+		}
+		result = append(result, channel)
+	}
+
+	return result, nil
+}
+
+func parseNotificationChannel(entry string, retries int, locale string) (NotificationChannel, error) {
+	parts := strings.Split(entry, ";")
+	channel := NotificationChannel{
+		Type:    strings.ToLower(strings.TrimSpace(parts[0])),
+		Retries: retries,
+		Locale:  locale,
+	}
+
+	for _, option := range parts[1:] {
+		key, value, found := strings.Cut(option, "=")
+		if !found {
+			return channel, fmt.Errorf("invalid option %q for %s channel, expected key=value", option, channel.Type)
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "url":
+			channel.URL = value
+		case "name":
+			channel.Name = value
+		case "format":
+			channel.Format = strings.ToLower(value)
+		case "retries":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return channel, fmt.Errorf("retries for %s channel must be a number of at least 0", channel.Type)
+			}
+			channel.Retries = n
+		case "locale":
+			channel.Locale = strings.ToLower(value)
+		default:
+			return channel, fmt.Errorf("unknown option %q for %s channel", key, channel.Type)
+		}
+	}
+
+	switch channel.Type {
+	case ChannelTeams:
+		if channel.Format == "" {
+			channel.Format = TeamsWebhookFormatMessageCard
+		}
+	case ChannelLogicApp:
+		if channel.Format == "" {
+			channel.Format = TeamsWebhookFormatLogicApp
+		}
+	}
+
+	return channel, nil
+}
+
+// validateNotificationChannel checks a channel's type, URL and format
+func validateNotificationChannel(channel NotificationChannel) error {
+	switch channel.Type {
+	case ChannelTeams:
+		if channel.Format != TeamsWebhookFormatMessageCard && channel.Format != TeamsWebhookFormatLogicApp {
+			return fmt.Errorf("format for channel %s must be '%s' or '%s'", channel.Name, TeamsWebhookFormatMessageCard, TeamsWebhookFormatLogicApp)
+		}
+	case ChannelLogicApp:
+		if channel.Format != TeamsWebhookFormatLogicApp {
+			return fmt.Errorf("format for channel %s must be '%s'", channel.Name, TeamsWebhookFormatLogicApp)
+		}
+	case ChannelSlack, ChannelWebhook:
+		if channel.Format != "" {
+			return fmt.Errorf("channel %s does not take a format", channel.Name)
+		}
+	default:
+		return fmt.Errorf("unknown type %q for channel %s, expected teams, logicapp, slack or webhook", channel.Type, channel.Name)
+	}
+
+	parsed, err := url.Parse(channel.URL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("channel %s needs an http(s) url", channel.Name)
+	}
+
+	if channel.Locale != "" && !knownLocale(channel.Locale) {
+		return fmt.Errorf("locale for channel %s must be one of %s", channel.Name, strings.Join(NotificationLocales, ", "))
+	}
+	return nil
+}
+
+// knownLocale reports whether reports can be posted in locale
+func knownLocale(locale string) bool {
+	for _, known := range NotificationLocales {
+		if locale == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Channels returns the webhook channels reports and alerts are posted to: NOTIFICATION_CHANNELS,
+// then TEAMS_WEBHOOK_URL in webhook delivery mode and each of OUTBOUND_WEBHOOK_URLS
+func (c *Config) Channels() []NotificationChannel {
+	channels := append([]NotificationChannel(nil), c.NotificationChannels...)
+
+	if c.TeamsDeliveryMode != "graph" && c.TeamsWebhookURL != "" {
+		channels = append(channels, NotificationChannel{
+			Name:    "teams",
+			Type:    ChannelTeams,
+			URL:     c.TeamsWebhookURL,
+			Format:  c.teamsWebhookFormat(),
+			Retries: c.NotificationRetries,
+			Locale:  c.NotificationLocale,
+		})
+	}
+
+	for i, webhookURL := range c.OutboundWebhookURLs {
+		if webhookURL = strings.TrimSpace(webhookURL); webhookURL != "" {
+			channels = append(channels, NotificationChannel{
+				Name:    fmt.Sprintf("outbound-webhook-%d", i+1),
+				Type:    ChannelWebhook,
+				URL:     webhookURL,
+				Retries: c.NotificationRetries,
+			})
+		}
+	}
+
+	return channels
+}
+
+// teamsWebhookFormat resolves TEAMS_WEBHOOK_FORMAT. "auto" keeps the original behaviour of
+// TEAMS_WEBHOOK_URL, recognising Logic Apps and Power Automate URLs by their host; channels
+// from NOTIFICATION_CHANNELS always name their format instead.
+func (c *Config) teamsWebhookFormat() string {
+	if c.TeamsWebhookFormat != TeamsWebhookFormatAuto && c.TeamsWebhookFormat != "" {
+		return c.TeamsWebhookFormat
+	}
+	if strings.Contains(c.TeamsWebhookURL, "logic.azure.com") ||
+		strings.Contains(c.TeamsWebhookURL, "workflows") ||
+		strings.Contains(c.TeamsWebhookURL, "azurewebsites.net") {
+		return TeamsWebhookFormatLogicApp
+	}
+	return TeamsWebhookFormatMessageCard
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotificationChannels(t *testing.T) {
+	channels, err := parseNotificationChannels(
+		"teams;url=https://contoso.webhook.office.com/a?x=1, logicapp;url=https://prod.logic.azure.com/w;name=aks-flow;retries=5,slack;url=https://hooks.slack.com/services/T/B/C,teams;url=https://contoso.webhook.office.com/b;format=logicapp",
+		2, "en",
+	)
+	require.NoError(t, err)
+	require.Len(t, channels, 4)
+
+	assert.Equal(t, NotificationChannel{
+		Name:    "teams-1",
+		Type:    ChannelTeams,
+		URL:     "https://contoso.webhook.office.com/a?x=1",
+		Format:  TeamsWebhookFormatMessageCard,
+		Retries: 2,
+		Locale:  "en",
+	}, channels[0])
+	assert.Equal(t, NotificationChannel{
+		Name:    "aks-flow",
+		Type:    ChannelLogicApp,
+		URL:     "https://prod.logic.azure.com/w",
+		Format:  TeamsWebhookFormatLogicApp,
+		Retries: 5,
+		Locale:  "en",
+	}, channels[1])
+	assert.Equal(t, "slack-1", channels[2].Name)
+	assert.Empty(t, channels[2].Format)
+	assert.Equal(t, "teams-2", channels[3].Name)
+	assert.Equal(t, TeamsWebhookFormatLogicApp, channels[3].Format)
+
+	_, err = parseNotificationChannels("slack;hook=https://hooks.slack.com", 2, "en")
+	assert.ErrorContains(t, err, "unknown option")
+
+	_, err = parseNotificationChannels("webhook;url=https://n8n.example.com;retries=-1", 2, "en")
+	assert.ErrorContains(t, err, "retries")
+
+	channels, err = parseNotificationChannels("slack;url=https://hooks.slack.com/services/T/B/D;locale=DE", 2, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "de", channels[0].Locale)
+}
+
+func TestValidateNotificationChannel(t *testing.T) {
+	assert.NoError(t, validateNotificationChannel(NotificationChannel{Name: "n8n", Type: ChannelWebhook, URL: "https://n8n.example.com/hook"}))
+	assert.ErrorContains(t, validateNotificationChannel(NotificationChannel{Name: "x", Type: "discord", URL: "https://discord.com"}), "unknown type")
+	assert.ErrorContains(t, validateNotificationChannel(NotificationChannel{Name: "x", Type: ChannelSlack}), "url")
+	assert.ErrorContains(t, validateNotificationChannel(NotificationChannel{Name: "x", Type: ChannelTeams, URL: "https://a.b", Format: "adaptivecard"}), "format")
+	assert.ErrorContains(t, validateNotificationChannel(NotificationChannel{Name: "x", Type: ChannelSlack, URL: "https://a.b", Format: "logicapp"}), "does not take a format")
+	assert.ErrorContains(t, validateNotificationChannel(NotificationChannel{Name: "x", Type: ChannelSlack, URL: "https://a.b", Locale: "klingon"}), "locale")
+}
+
+func TestConfig_Channels(t *testing.T) {
+	cfg := &Config{
+		NotificationChannels: []NotificationChannel{{Name: "slack-1", Type: ChannelSlack, URL: "https://hooks.slack.com/x", Retries: 1}},
+		TeamsDeliveryMode:    "webhook",
+		TeamsWebhookURL:      "https://prod-01.westus.logic.azure.com/workflows/abc/triggers/manual/paths/invoke",
+		TeamsWebhookFormat:   TeamsWebhookFormatAuto,
+		OutboundWebhookURLs:  []string{"https://n8n.example.com/hook", " "},
+		NotificationRetries:  2,
+	}
+
+	channels := cfg.Channels()
+	require.Len(t, channels, 3)
+	assert.Equal(t, "slack-1", channels[0].Name)
+	assert.Equal(t, NotificationChannel{Name: "teams", Type: ChannelTeams, URL: cfg.TeamsWebhookURL, Format: TeamsWebhookFormatLogicApp, Retries: 2}, channels[1])
+	assert.Equal(t, NotificationChannel{Name: "outbound-webhook-1", Type: ChannelWebhook, URL: "https://n8n.example.com/hook", Retries: 2}, channels[2])
+
+	cfg.TeamsWebhookURL = "https://contoso.webhook.office.com/webhookb2/abc"
+	assert.Equal(t, TeamsWebhookFormatMessageCard, cfg.Channels()[1].Format)
+
+	cfg.TeamsWebhookFormat = TeamsWebhookFormatLogicApp
+	assert.Equal(t, TeamsWebhookFormatLogicApp, cfg.Channels()[1].Format)
+
+	// Graph delivery posts through Microsoft Graph instead of the webhook
+	cfg.TeamsDeliveryMode = "graph"
+	assert.Len(t, cfg.Channels(), 2)
+}
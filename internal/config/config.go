@@ -2,72 +2,479 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
 )
 
 // Config holds all configuration for the application
 type Config struct {
 	// Server configuration
-	Port  string
-	Debug bool
+	Port        string
+	Debug       bool
+	AdminToken  string // Bearer token the /api/admin routes require; they are not served without one
+	IngestToken string // Bearer token POST /api/mentions requires; it is not served without one
+
+	// Monitoring profiles sharing the deployment
+	Profiles []string // Names of the profiles the deployment also hosts; set on the default configuration only
+	Profile  string   // Name of the profile this configuration is for; empty for the default one
+	APIToken string   // Bearer token the HTTP API requires, from API_TOKEN or PROFILE_<NAME>_API_TOKEN
+
+	// Azure App Configuration store the settings and feature flags are loaded from
+	AppConfigEndpoint        string
+	AppConfigKeyPrefix       string          // Only keys with this prefix are loaded, without it
+	AppConfigLabel           string          // Label of the loaded keys; empty loads keys without a label
+	AppConfigRefreshInterval time.Duration   // How often serve reloads them; 0 loads them once
+	FeatureFlags             map[string]bool // Feature flags loaded from App Configuration, by name
+
+	// Azure Monitor custom metrics published after each monitoring run
+	AzureMonitorResourceID string // Resource the metrics are published for, e.g. the AKS cluster
+	AzureMonitorRegion     string // Region of that resource, e.g. eastus
+	AzureMonitorNamespace  string // Metric namespace the metrics are grouped under
 
 	// Schedule configuration
 	ReportSchedule string // "daily" or "weekly"
 	TimeZone       string
+	ScheduleJitter time.Duration // Maximum random delay before each scheduled run
+	// Cron schedules of sources collected on their own, by source name. The report run skips
+	// them and reports what they collected since the last report.
+	SourceSchedules map[string]string
 
 	// Azure Storage configuration
-	StorageAccount   string
-	StorageContainer string
+	StorageAccount          string
+	StorageContainer        string
+	StorageConnectionString string // Overrides managed identity, e.g. for Azurite
+	StorageSASToken         string // Overrides managed identity
+	StorageBlobEndpoint     string // Overrides https://<account>.blob.core.windows.net/
+	// Key Vault key wrapping the data key blobs are encrypted with, e.g.
+	// https://<vault>.vault.azure.net/keys/<name>; empty stores blobs unencrypted
+	StorageEncryptionKeyID string
+	// Read unencrypted blobs while encrypt-storage migrates them, instead of rejecting them
+	StorageEncryptionMigration bool
 
 	// Notification configuration
-	TeamsWebhookURL   string
-	NotificationEmail string
-	SMTPHost          string
-	SMTPPort          int
-	SMTPUsername      string
-	SMTPPassword      string
+	TeamsWebhookURL    string
+	TeamsWebhookFormat string // "auto", "messagecard" or "logicapp"
+	TeamsDeliveryMode  string // "webhook" or "graph"
+	TeamsTeamID        string
+	TeamsChannelID     string
+	GraphTenantID      string
+	GraphClientID      string
+	GraphClientSecret  string
+	EmailDeliveryMode  string // "smtp" or "graph"
+	GraphMailSender    string // Mailbox Graph sendMail sends from, by user principal name or ID
+	SMTPHost           string
+	SMTPPort           int
+	SMTPUsername       string
+	SMTPPassword       string
+
+	// Email recipients and preferences
+	EmailRecipients   []EmailRecipient    // Parsed from EMAIL_RECIPIENTS plus the legacy NOTIFICATION_EMAIL
+	KeywordGroups     map[string][]string // Named keyword groups recipients can subscribe to
+	PublicBaseURL     string              // Externally reachable bot URL used in preference links
+	PreferencesSecret string              // Signs preference and unsubscribe links
+	// Link mentions in notifications to /r/{id}, which records the click and redirects to the post
+	EnableClickTracking bool
+
+	// Generic outbound webhooks
+	OutboundWebhookURLs   []string
+	OutboundWebhookSecret string
+
+	// Webhook channels from NOTIFICATION_CHANNELS; Channels adds TEAMS_WEBHOOK_URL and OUTBOUND_WEBHOOK_URLS
+	NotificationChannels []NotificationChannel
+	NotificationRetries  int    // Attempts after a failed post to a channel
+	NotificationLocale   string // Language of reports posted to channels and through Graph, one of NotificationLocales
+
+	// Deliveries that still fail are queued in storage and retried with backoff
+	NotificationQueueMaxAttempts int           // Queued attempts before a delivery is marked failed; 0 disables the queue
+	NotificationQueueBackoff     time.Duration // Wait before the first queued attempt, doubling after each
+
+	// Noise blocklists seeded from configuration; entries can be added at runtime via the API
+	BlockedAuthors  []string // Author names, optionally scoped to a source as "source:author"
+	BlockedChannels []string // YouTube channel IDs or titles
+	BlockedDomains  []string // Mention URL domains, matching subdomains too
+
+	// Inbound mention actions (mark handled, escalate) from Logic Apps or Adaptive Cards
+	InboundWebhookSecret string
+
+	// Slack app signing secret; enables the /aksmentions slash command at /slack/commands
+	SlackSigningSecret string
+
+	// Teams bot (Bot Framework) registration; enables conversational queries at /api/messages
+	TeamsBotAppID       string
+	TeamsBotAppPassword string
+	TeamsBotTenantID    string // Empty for multi-tenant bots
 
 	// API Keys and credentials
 	RedditClientID     string
 	RedditClientSecret string
 	TwitterBearerToken string
 	YouTubeAPIKey      string
+	ThreadsAccessToken string
 
 	// Keywords to monitor
 	Keywords []string
 
+	// Per-source configuration
+	SourcesEnabled      map[string]bool // Explicit enable/disable keyed by source name
+	RedditSubreddits    []string
+	StackOverflowTags   []string
+	StackExchangeSites  []string // Stack Exchange sites the Stack Overflow source searches, e.g. "serverfault"
+	HackerNewsItemLimit int
+	YouTubeMaxResults   int
+	MediumTags          []string
+	MediumPublications  []string // Publication feeds followed in full, e.g. "itnext"
+	MediumAuthors       []string // Author feeds followed in full, e.g. "@jane"
+	CVETerms            []string // NVD keyword searches used by the CVE source
+	NVDAPIKey           string   // Optional NVD API key for higher rate limits
+
+	// Reddit subreddit discovery
+	RedditDiscovery            bool     // Search all of Reddit and add subreddits that yield relevant mentions
+	RedditDiscoveryMax         int      // Maximum number of discovered subreddits searched
+	RedditDiscoveryMinMentions int      // Relevant mentions needed before a subreddit is searched
+	RedditExcludedSubreddits   []string // Subreddits never added by discovery
+
+	// YouTube channel allow list and video filters; noisy channels go in BlockedChannels
+	YouTubeTrustedChannels         []string // Channel IDs whose videos are always kept
+	YouTubeExcludeShorts           bool     // Drop Shorts from search results
+	YouTubeExcludeNonEnglishGaming bool     // Drop Gaming category videos that aren't in English
+	YouTubeCommentChannels         []string // Channel IDs whose latest uploads have their comments scanned
+	YouTubeCommentVideos           int      // Most videos whose comments are scanned per run
+
+	// Code hosting discussion sources
+	GitLabToken           string   // Personal access token; GitLab search requires authentication
+	GitLabURL             string   // GitLab instance to search
+	GitHubToken           string   // Optional token; GitHub code search requires authentication
+	GitHubTerms           []string // Projects whose new repositories are searched for
+	BitbucketToken        string   // Optional access token for private Bitbucket repositories
+	BitbucketRepositories []string // "workspace/repo" repositories whose issues are searched
+
+	// LinkedIn organization page followed through the Community Management API
+	LinkedInAccessToken    string // Page administrator token with r_organization_social
+	LinkedInOrganizationID string // Numeric ID of the page's organization
+	LinkedInPageName       string // Author shown for the page's posts
+	LinkedInPageMaxPosts   int    // Latest posts checked for keywords, reactions and comments each run
+	LinkedInAPIVersion     string // LinkedIn-Version header, e.g. "202601"; empty for the bot's default
+
+	// Japanese and Chinese developer communities
+	QiitaAccessToken string   // Optional; raises Qiita's API limit from 60 to 1,000 requests an hour
+	CNFeeds          []string // RSS or Atom feeds of Chinese communities, e.g. CSDN blogs or RSSHub routes
+
+	// Bing Web Search, used by the Medium, LinkedIn and web sources. The Bing Search APIs were
+	// retired in August 2025, so they need an endpoint serving the same API.
+	BingSearchAPIKey        string // Subscription key of a Bing Search resource
+	BingSearchEndpoint      string // Web Search API endpoint
+	BingMaxResultsPerDomain int    // Most mentions the web source keeps from one domain per run
+
+	// Podcast feeds, and Azure AI Speech for transcribing episodes whose show notes don't
+	// name a keyword
+	PodcastFeeds             []string // RSS feed URLs of the podcasts followed
+	PodcastMaxTranscriptions int      // Episodes transcribed per run; 0 reads only published transcripts
+	SpeechEndpoint           string   // Speech resource endpoint, e.g. https://eastus.api.cognitive.microsoft.com
+	SpeechKey                string
+	SpeechLocale             string // Language episodes are transcribed in
+
+	// ContentFormat is how HTML from Stack Exchange, Hacker News, Medium and YouTube is
+	// written into mention content: ContentFormatMarkdown or ContentFormatText
+	ContentFormat string
+
 	// Context filtering
 	EnableContextFiltering bool
 	ContextThreshold       float64
 
 	// Sentiment analysis
 	EnableSentimentAnalysis bool
+	SentimentScoreWindow    time.Duration // Trailing window of the community sentiment score shown in reports
+
+	// AKS release correlation
+	EnableReleaseCorrelation bool
+	ReleasesURL              string
+
+	// Documentation gaps: questions Azure docs search finds no good match for
+	EnableDocsGapDetection bool
+	DocsSearchURL          string
+	DocsGapMaxLookups      int // Questions looked up in the docs per run
+
+	// Azure OpenAI for optional LLM enrichment
+	AzureOpenAIEndpoint        string
+	AzureOpenAIDeployment      string
+	AzureOpenAIAPIKey          string // Optional; the default Azure credential is used when empty
+	AzureOpenAIAPIVersion      string
+	EnableLLMQuestionDetection bool
+	EnableTranslation          bool // Translate Japanese, Chinese and Korean mentions into English with the LLM
+	TranslationMaxMentions     int  // Mentions translated per run
+
+	// Enrichment (sentiment and question detection) of fetched mentions
+	EnrichmentConcurrency int // Mentions analyzed in parallel within a source's batch
+	EnrichmentCacheSize   int // Results cached by content hash; 0 disables the cache
+
+	// Source fetching
+	SourceConcurrency int                      // Maximum number of sources fetched in parallel
+	SourceTimeout     time.Duration            // Default per-source fetch timeout
+	SourceTimeouts    map[string]time.Duration // Per-source timeout overrides keyed by source name
+
+	// Circuit breaker and error budget of failing sources
+	SourceBreakerThreshold int           // Consecutive failed fetches that open a source's breaker; 0 disables breakers
+	SourceBreakerCooldown  time.Duration // How long a source with an open breaker is skipped before it is tried again
+	SourceErrorBudget      float64       // Share of a week's fetches a source may fail before reports flag it
+
+	// Recorded source fixtures: a directory of <source>.json files replayed instead of calling the
+	// source APIs, or written with what the live sources return
+	SourceFixtures    string
+	SourceFixtureMode string // FixtureModeReplay or FixtureModeRecord
+
+	// Response cache of the feed-style sources (Stack Overflow, Hacker News, Medium, podcasts)
+	HTTPCacheSize int           // Responses kept in memory; 0 disables the in-memory cache
+	HTTPCacheTTL  time.Duration // How long responses are reused before they are revalidated
+	HTTPCacheBlob bool          // Also persist responses in blob storage, shared across processes
+
+	// Urgent checks
+	UrgentSources         []string                 // Sources polled by urgent checks; empty for every enabled source
+	UrgentKeywords        []string                 // Keywords searched by urgent checks; empty to search Keywords
+	UrgentSourceTimeout   time.Duration            // Default per-source fetch timeout of urgent checks
+	UrgentSourceTimeouts  map[string]time.Duration // Per-source urgent timeout overrides keyed by source name
+	ReportAlertedMentions string                   // How reports show mentions urgent checks already alerted: "section" or "suppress"
+	UrgentAlertCooldown   time.Duration            // How long a story is not alerted again after an urgent notification; 0 alerts every check
+	UrgentDigestEnabled   bool                     // Send a daily summary of the urgent stories of the last 24 hours
+
+	// Urgent verification
+	EnableLLMUrgentVerification bool // Ask the LLM whether a keyword hit describes an actual security or breaking issue before paging
+
+	// X recent search scheduling
+	TwitterRequestsPerWindow int           // Search requests allowed per 15-minute rate limit window
+	TwitterMaxPages          int           // Result pages fetched per keyword before the rest is left to a later run
+	TwitterMaxWait           time.Duration // How long a run waits for the request budget before deferring its searches
+
+	// X filtered stream
+	TwitterStreamEnabled     bool          // Consume the X filtered stream for real-time urgent mentions
+	TwitterStreamBatchWindow time.Duration // How long streamed tweets are collected before an urgent check
+
+	// Per-source watermarks for scheduled runs
+	EnableWatermarks   bool          // Search each source from its newest stored mention instead of a fixed window
+	WatermarkOverlap   time.Duration // Extra time searched before the watermark to catch late-indexed mentions
+	WatermarkMaxWindow time.Duration // Upper bound on the window after long downtime
+
+	// HTML report artifacts
+	EnableHTMLReports bool // Store a standalone HTML report with charts for every report run
+
+	// PDF report export
+	EnablePDFReports bool   // Print the HTML report to PDF for storage and email attachments
+	ChromePath       string // Chrome or Chromium binary used to print PDFs; searched on the PATH when empty
+	ChromeNoSandbox  bool   // Run Chrome without its sandbox, only in a locked-down container
+
+	// Parquet export of stored mentions for data lake ingestion
+	EnableParquetExport bool   // Rewrite the date and source partitions each run touched
+	ParquetExportPrefix string // Blob prefix of the partitioned files
+
+	// Keyword group alert thresholds, evaluated after each run
+	AlertThresholds           []AlertThreshold
+	AlertThresholdMinMentions int // Mentions a group needs in a run before percentage thresholds apply
+
+	// Teams report sampling for large runs
+	TeamsMentionsPerSource int    // Mentions listed per source in Teams reports; 0 lists every mention
+	TeamsMentionRanking    string // "engagement", "relevance", "recent" or "rank"
+
+	// Formula ranking the mentions of a report, weighted per factor (see RankFactors)
+	MentionRankWeights map[string]float64
+	MentionSourceTrust map[string]float64 // Trust (0-1) per source, overriding the built-in levels
+
+	// Context filter audit trail
+	EnableRejectionAudit bool // Store the mentions the context filter drops, with the reasons, for /api/admin/mentions/rejected
+
+	// Spam and bot detection
+	EnableSpamDetection    bool // Exclude mentions with spam or bot signals from reports
+	EnableLLMSpamDetection bool // Ask the LLM about mentions with some spam signals but too few to decide
+
+	// Near-duplicate collapsing
+	EnableNearDuplicates  bool          // Collapse mentions with near-identical content into one
+	NearDuplicateDistance int           // Differing fingerprint bits (of 64) at which content still counts as a copy
+	NearDuplicateWindow   time.Duration // How long fingerprints are remembered, to collapse copies in later runs
+
+	// Follow-up on unanswered questions
+	EnableAnswerTracking bool // Recheck questions reported as unanswered and list the ones answered since
+
+	// Unit prices, in US dollars, used to estimate the cost of each run
+	CostTwitterPerRequest     float64 // Per X API search request
+	CostTwitterPerPost        float64 // Per post read through the X API
+	CostOpenAIPromptPer1K     float64 // Per 1,000 Azure OpenAI prompt tokens
+	CostOpenAICompletionPer1K float64 // Per 1,000 Azure OpenAI completion tokens
+
+	// Search query templates overriding the built-in ones, keyed by lowercase keyword, with
+	// the content patterns of each keyword
+	KeywordQueries map[string]models.KeywordQuery
 }
 
-// Load loads configuration from environment variables
+// Mention rankings used to pick the mentions listed in Teams reports
+const (
+	MentionRankingEngagement = "engagement"
+	MentionRankingRelevance  = "relevance"
+	MentionRankingRecent     = "recent"
+	MentionRankingRank       = "rank" // The report rank from MENTION_RANK_WEIGHTS
+)
+
+// Payload formats posted to TEAMS_WEBHOOK_URL
+const (
+	TeamsWebhookFormatAuto        = "auto"        // Logic App payloads for Logic Apps and Power Automate URLs, MessageCards otherwise
+	TeamsWebhookFormatMessageCard = "messagecard" // Office 365 connector MessageCards
+	TeamsWebhookFormatLogicApp    = "logicapp"    // The versioned JSON schema for Logic Apps and Power Automate flows
+)
+
+// How HTML content from sources is written into mentions
+const (
+	ContentFormatMarkdown = "markdown" // Fenced code blocks, inline code and [text](url) links
+	ContentFormatText     = "text"     // Plain text, with links as "text (url)"
+)
+
+// What sources do with SOURCE_FIXTURES
+const (
+	FixtureModeReplay = "replay" // Serve the recorded mentions instead of calling the source APIs
+	FixtureModeRecord = "record" // Call the source APIs and record what they return
+)
+
+// How periodic reports show mentions urgent checks already alerted
+const (
+	ReportAlertedSection  = "section"  // List them apart in a "Previously Alerted" section
+	ReportAlertedSuppress = "suppress" // Leave them out of the mention lists; counts still include them
+)
+
+// Load loads configuration from environment variables and validates it
 func Load() (*Config, error) {
+	cfg, err := Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate required configuration
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Parse reads configuration from environment variables without validating it. It is
+// used by tooling such as source tests that does not need notifications configured.
+func Parse() (*Config, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	cfg, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Profiles = getNamesEnv("PROFILES")
+	return cfg, nil
+}
+
+// ParseValues reads configuration from the given settings alone, keyed by environment
+// variable, without validating it. The environment and App Configuration are ignored, so
+// settings can be checked before they are written, as setup does.
+func ParseValues(values map[string]string) (*Config, error) {
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	saved := remote
+	remote = nil
+	getenv = func(key string) string { return values[key] }
+	defer func() { getenv, remote = lookupEnv, saved }()
+
+	cfg, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Profiles = getNamesEnv("PROFILES")
+	return cfg, nil
+}
+
+// LoadValues reads configuration from the given settings alone, like ParseValues, and
+// validates it
+func LoadValues(values map[string]string) (*Config, error) {
+	cfg, err := ParseValues(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// parse reads the configuration through getenv; callers must hold envMu
+func parse() (*Config, error) {
 	cfg := &Config{
 		Port:           getEnv("PORT", "8080"),
+		AdminToken:     getEnv("ADMIN_API_TOKEN", ""),
+		IngestToken:    getEnv("INGEST_API_TOKEN", ""),
+		APIToken:       getEnv("API_TOKEN", ""),
 		Debug:          getBoolEnv("DEBUG", false),
 		ReportSchedule: getEnv("REPORT_SCHEDULE", "weekly"),
 		TimeZone:       getEnv("TIMEZONE", "UTC"),
+		ScheduleJitter: getDurationEnv("SCHEDULE_JITTER", 0),
+
+		AppConfigEndpoint:        strings.TrimRight(getEnv("APP_CONFIG_ENDPOINT", ""), "/"),
+		AppConfigKeyPrefix:       getEnv("APP_CONFIG_KEY_PREFIX", ""),
+		AppConfigLabel:           getEnv("APP_CONFIG_LABEL", ""),
+		AppConfigRefreshInterval: getDurationEnv("APP_CONFIG_REFRESH_INTERVAL", 5*time.Minute),
+
+		AzureMonitorResourceID: getEnv("AZURE_MONITOR_RESOURCE_ID", ""),
+		AzureMonitorRegion:     strings.ToLower(getEnv("AZURE_MONITOR_REGION", "")),
+		AzureMonitorNamespace:  getEnv("AZURE_MONITOR_NAMESPACE", "AKSMentionsBot"),
+
+		StorageAccount:             getEnv("AZURE_STORAGE_ACCOUNT", ""),
+		StorageContainer:           getEnv("AZURE_STORAGE_CONTAINER", "mentions"),
+		StorageConnectionString:    getEnv("AZURE_STORAGE_CONNECTION_STRING", ""),
+		StorageSASToken:            getEnv("AZURE_STORAGE_SAS_TOKEN", ""),
+		StorageBlobEndpoint:        getEnv("AZURE_STORAGE_BLOB_ENDPOINT", ""),
+		StorageEncryptionKeyID:     getEnv("STORAGE_ENCRYPTION_KEY_ID", ""),
+		StorageEncryptionMigration: getBoolEnv("STORAGE_ENCRYPTION_MIGRATION", false),
 
-		StorageAccount:   getEnv("AZURE_STORAGE_ACCOUNT", ""),
-		StorageContainer: getEnv("AZURE_STORAGE_CONTAINER", "mentions"),
+		TeamsWebhookURL:    getEnv("TEAMS_WEBHOOK_URL", ""),
+		TeamsWebhookFormat: strings.ToLower(getEnv("TEAMS_WEBHOOK_FORMAT", TeamsWebhookFormatAuto)),
+		TeamsDeliveryMode:  getEnv("TEAMS_DELIVERY_MODE", "webhook"),
+		TeamsTeamID:        getEnv("TEAMS_TEAM_ID", ""),
+		TeamsChannelID:     getEnv("TEAMS_CHANNEL_ID", ""),
+		GraphTenantID:      getEnv("GRAPH_TENANT_ID", ""),
+		GraphClientID:      getEnv("GRAPH_CLIENT_ID", ""),
+		GraphClientSecret:  getEnv("GRAPH_CLIENT_SECRET", ""),
+		EmailDeliveryMode:  strings.ToLower(getEnv("EMAIL_DELIVERY_MODE", "smtp")),
+		GraphMailSender:    getEnv("GRAPH_MAIL_SENDER", ""),
+		SMTPHost:           getEnv("SMTP_HOST", ""),
+		SMTPPort:           getIntEnv("SMTP_PORT", 587),
+		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
 
-		TeamsWebhookURL:   getEnv("TEAMS_WEBHOOK_URL", ""),
-		NotificationEmail: getEnv("NOTIFICATION_EMAIL", ""),
-		SMTPHost:          getEnv("SMTP_HOST", ""),
-		SMTPPort:          getIntEnv("SMTP_PORT", 587),
-		SMTPUsername:      getEnv("SMTP_USERNAME", ""),
-		SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
+		KeywordGroups:     getKeywordGroupsEnv("KEYWORD_GROUPS"),
+		PublicBaseURL:     strings.TrimRight(getEnv("PUBLIC_BASE_URL", ""), "/"),
+		PreferencesSecret: getEnv("PREFERENCES_SECRET", ""),
+
+		EnableClickTracking: getBoolEnv("ENABLE_CLICK_TRACKING", false),
+
+		OutboundWebhookURLs:   getSliceEnv("OUTBOUND_WEBHOOK_URLS", nil),
+		OutboundWebhookSecret: getEnv("OUTBOUND_WEBHOOK_SECRET", ""),
+		NotificationRetries:   getIntEnv("NOTIFICATION_RETRIES", 2),
+		NotificationLocale:    strings.ToLower(getEnv("NOTIFICATION_LOCALE", "en")),
+		InboundWebhookSecret:  getEnv("INBOUND_WEBHOOK_SECRET", ""),
+		SlackSigningSecret:    getEnv("SLACK_SIGNING_SECRET", ""),
+		TeamsBotAppID:         getEnv("TEAMS_BOT_APP_ID", ""),
+		TeamsBotAppPassword:   getEnv("TEAMS_BOT_APP_PASSWORD", ""),
+		TeamsBotTenantID:      getEnv("TEAMS_BOT_TENANT_ID", ""),
+
+		NotificationQueueMaxAttempts: getIntEnv("NOTIFICATION_QUEUE_MAX_ATTEMPTS", 6),
+		NotificationQueueBackoff:     getDurationEnv("NOTIFICATION_QUEUE_BACKOFF", 5*time.Minute),
+
+		BlockedAuthors:  getSliceEnv("BLOCKED_AUTHORS", nil),
+		BlockedChannels: getSliceEnv("BLOCKED_CHANNELS", nil),
+		BlockedDomains:  getSliceEnv("BLOCKED_DOMAINS", nil),
 
 		RedditClientID:     getEnv("REDDIT_CLIENT_ID", ""),
 		RedditClientSecret: getEnv("REDDIT_CLIENT_SECRET", ""),
 		TwitterBearerToken: getEnv("TWITTER_BEARER_TOKEN", ""),
 		YouTubeAPIKey:      getEnv("YOUTUBE_API_KEY", ""),
+		ThreadsAccessToken: getEnv("THREADS_ACCESS_TOKEN", ""),
 
 		Keywords: getSliceEnv("KEYWORDS", []string{
 			"Azure Kubernetes Service",
@@ -79,16 +486,193 @@ func Load() (*Config, error) {
 			// "Azure Container Service",
 		}),
 
+		SourcesEnabled:      getSourcesEnabled(),
+		RedditSubreddits:    getSliceEnv("REDDIT_SUBREDDITS", nil),
+		StackOverflowTags:   getSliceEnv("STACKOVERFLOW_TAGS", nil),
+		StackExchangeSites:  getSliceEnv("STACKEXCHANGE_SITES", nil),
+		HackerNewsItemLimit: getIntEnv("HACKERNEWS_ITEM_LIMIT", 500),
+		YouTubeMaxResults:   getIntEnv("YOUTUBE_MAX_RESULTS", 50),
+		MediumTags:          getSliceEnv("MEDIUM_TAGS", nil),
+		MediumPublications:  getSliceEnv("MEDIUM_PUBLICATIONS", nil),
+		MediumAuthors:       getSliceEnv("MEDIUM_AUTHORS", nil),
+		CVETerms:            getSliceEnv("CVE_KEYWORDS", nil),
+		NVDAPIKey:           getEnv("NVD_API_KEY", ""),
+
+		RedditDiscovery:            getBoolEnv("REDDIT_DISCOVERY", true),
+		RedditDiscoveryMax:         getIntEnv("REDDIT_DISCOVERY_MAX", 10),
+		RedditDiscoveryMinMentions: getIntEnv("REDDIT_DISCOVERY_MIN_MENTIONS", 3),
+		RedditExcludedSubreddits:   getSliceEnv("REDDIT_EXCLUDED_SUBREDDITS", nil),
+
+		YouTubeTrustedChannels:         getSliceEnv("YOUTUBE_TRUSTED_CHANNELS", nil),
+		YouTubeExcludeShorts:           getBoolEnv("YOUTUBE_EXCLUDE_SHORTS", true),
+		YouTubeExcludeNonEnglishGaming: getBoolEnv("YOUTUBE_EXCLUDE_NON_ENGLISH_GAMING", true),
+		YouTubeCommentChannels:         getSliceEnv("YOUTUBE_COMMENT_CHANNELS", nil),
+		YouTubeCommentVideos:           getIntEnv("YOUTUBE_COMMENT_VIDEOS", 10),
+
+		GitLabToken:           getEnv("GITLAB_TOKEN", ""),
+		GitLabURL:             getEnv("GITLAB_URL", "https://gitlab.com"),
+		GitHubToken:           getEnv("GITHUB_TOKEN", ""),
+		GitHubTerms:           getSliceEnv("GITHUB_KEYWORDS", nil),
+		BitbucketToken:        getEnv("BITBUCKET_TOKEN", ""),
+		BitbucketRepositories: getSliceEnv("BITBUCKET_REPOSITORIES", nil),
+
+		LinkedInAccessToken:    getEnv("LINKEDIN_ACCESS_TOKEN", ""),
+		LinkedInOrganizationID: getEnv("LINKEDIN_ORGANIZATION_ID", ""),
+		LinkedInPageName:       getEnv("LINKEDIN_PAGE_NAME", "Microsoft Azure"),
+		LinkedInPageMaxPosts:   getIntEnv("LINKEDIN_PAGE_MAX_POSTS", 50),
+		LinkedInAPIVersion:     getEnv("LINKEDIN_API_VERSION", ""),
+
+		QiitaAccessToken: getEnv("QIITA_ACCESS_TOKEN", ""),
+		CNFeeds:          getSliceEnv("CN_FEEDS", nil),
+
+		BingSearchAPIKey:        getEnv("BING_SEARCH_API_KEY", ""),
+		BingSearchEndpoint:      getEnv("BING_SEARCH_ENDPOINT", "https://api.bing.microsoft.com/v7.0/search"),
+		BingMaxResultsPerDomain: getIntEnv("BING_MAX_RESULTS_PER_DOMAIN", 3),
+
+		PodcastFeeds:             getSliceEnv("PODCAST_FEEDS", nil),
+		PodcastMaxTranscriptions: getIntEnv("PODCAST_MAX_TRANSCRIPTIONS", 2),
+		SpeechEndpoint:           getEnv("SPEECH_ENDPOINT", ""),
+		SpeechKey:                getEnv("SPEECH_KEY", ""),
+		SpeechLocale:             getEnv("SPEECH_LOCALE", "en-US"),
+
+		ContentFormat: strings.ToLower(getEnv("CONTENT_FORMAT", ContentFormatMarkdown)),
+
 		EnableContextFiltering:  getBoolEnv("ENABLE_CONTEXT_FILTERING", true),
 		ContextThreshold:        getFloatEnv("CONTEXT_THRESHOLD", 0.7),
 		EnableSentimentAnalysis: getBoolEnv("ENABLE_SENTIMENT_ANALYSIS", true),
+		SentimentScoreWindow:    getDurationEnv("SENTIMENT_SCORE_WINDOW", 30*24*time.Hour),
+
+		EnableReleaseCorrelation: getBoolEnv("ENABLE_RELEASE_CORRELATION", true),
+		ReleasesURL:              getEnv("AKS_RELEASES_URL", "https://api.github.com/repos/Azure/AKS/releases"),
+
+		EnableDocsGapDetection: getBoolEnv("ENABLE_DOCS_GAP_DETECTION", false),
+		DocsSearchURL:          getEnv("DOCS_SEARCH_URL", "https://learn.microsoft.com/api/search"),
+		DocsGapMaxLookups:      getIntEnv("DOCS_GAP_MAX_LOOKUPS", 20),
+
+		AzureOpenAIEndpoint:        getEnv("AZURE_OPENAI_ENDPOINT", ""),
+		AzureOpenAIDeployment:      getEnv("AZURE_OPENAI_DEPLOYMENT", ""),
+		AzureOpenAIAPIKey:          getEnv("AZURE_OPENAI_API_KEY", ""),
+		AzureOpenAIAPIVersion:      getEnv("AZURE_OPENAI_API_VERSION", "2024-06-01"),
+		EnableLLMQuestionDetection: getBoolEnv("ENABLE_LLM_QUESTION_DETECTION", false),
+		EnableTranslation:          getBoolEnv("ENABLE_TRANSLATION", false),
+		TranslationMaxMentions:     getIntEnv("TRANSLATION_MAX_MENTIONS", 50),
+
+		EnrichmentConcurrency: getIntEnv("ENRICHMENT_CONCURRENCY", 4),
+		EnrichmentCacheSize:   getIntEnv("ENRICHMENT_CACHE_SIZE", 10000),
+
+		SourceConcurrency: getIntEnv("SOURCE_CONCURRENCY", 4),
+		SourceTimeout:     getDurationEnv("SOURCE_TIMEOUT", 10*time.Minute),
+		SourceTimeouts:    getDurationMapEnv("SOURCE_TIMEOUTS"),
+
+		SourceBreakerThreshold: getIntEnv("SOURCE_BREAKER_THRESHOLD", 3),
+		SourceBreakerCooldown:  getDurationEnv("SOURCE_BREAKER_COOLDOWN", 6*time.Hour),
+		SourceErrorBudget:      getFloatEnv("SOURCE_ERROR_BUDGET", 0.1),
+
+		SourceFixtures:    getEnv("SOURCE_FIXTURES", ""),
+		SourceFixtureMode: strings.ToLower(getEnv("SOURCE_FIXTURE_MODE", FixtureModeReplay)),
+
+		HTTPCacheSize: getIntEnv("HTTP_CACHE_SIZE", 1000),
+		HTTPCacheTTL:  getDurationEnv("HTTP_CACHE_TTL", 15*time.Minute),
+		HTTPCacheBlob: getBoolEnv("HTTP_CACHE_BLOB", false),
+
+		UrgentSources:         getNamesEnv("URGENT_SOURCES"),
+		UrgentKeywords:        getSliceEnv("URGENT_KEYWORDS", nil),
+		UrgentSourceTimeout:   getDurationEnv("URGENT_SOURCE_TIMEOUT", 3*time.Minute),
+		UrgentSourceTimeouts:  getDurationMapEnv("URGENT_SOURCE_TIMEOUTS"),
+		ReportAlertedMentions: strings.ToLower(getEnv("REPORT_ALERTED_MENTIONS", ReportAlertedSection)),
+		UrgentAlertCooldown:   getDurationEnv("URGENT_ALERT_COOLDOWN", 24*time.Hour),
+		UrgentDigestEnabled:   getBoolEnv("URGENT_DIGEST_ENABLED", true),
+
+		EnableLLMUrgentVerification: getBoolEnv("ENABLE_LLM_URGENT_VERIFICATION", false),
+
+		TwitterRequestsPerWindow: getIntEnv("TWITTER_REQUESTS_PER_WINDOW", 60),
+		TwitterMaxPages:          getIntEnv("TWITTER_MAX_PAGES", 3),
+		TwitterMaxWait:           getDurationEnv("TWITTER_MAX_WAIT", 30*time.Second),
+
+		TwitterStreamEnabled:     getBoolEnv("TWITTER_STREAM_ENABLED", false),
+		TwitterStreamBatchWindow: getDurationEnv("TWITTER_STREAM_BATCH_WINDOW", time.Minute),
+
+		EnableWatermarks:   getBoolEnv("ENABLE_WATERMARKS", true),
+		WatermarkOverlap:   getDurationEnv("WATERMARK_OVERLAP", 2*time.Hour),
+		WatermarkMaxWindow: getDurationEnv("WATERMARK_MAX_WINDOW", 30*24*time.Hour),
+
+		EnableHTMLReports: getBoolEnv("ENABLE_HTML_REPORTS", true),
+
+		EnablePDFReports: getBoolEnv("ENABLE_PDF_REPORTS", false),
+		ChromePath:       getEnv("CHROME_PATH", ""),
+		ChromeNoSandbox:  getBoolEnv("CHROME_NO_SANDBOX", false),
+
+		EnableParquetExport: getBoolEnv("ENABLE_PARQUET_EXPORT", false),
+		ParquetExportPrefix: strings.Trim(getEnv("PARQUET_EXPORT_PREFIX", "exports/mentions"), "/"),
+
+		AlertThresholdMinMentions: getIntEnv("ALERT_THRESHOLD_MIN_MENTIONS", 10),
+
+		TeamsMentionsPerSource: getIntEnv("TEAMS_MENTIONS_PER_SOURCE", 10),
+		TeamsMentionRanking:    strings.ToLower(getEnv("TEAMS_MENTION_RANKING", MentionRankingEngagement)),
+
+		EnableRejectionAudit: getBoolEnv("ENABLE_REJECTION_AUDIT", false),
+
+		EnableSpamDetection:    getBoolEnv("ENABLE_SPAM_DETECTION", true),
+		EnableLLMSpamDetection: getBoolEnv("ENABLE_LLM_SPAM_DETECTION", false),
+
+		EnableNearDuplicates:  getBoolEnv("ENABLE_NEAR_DUPLICATES", true),
+		NearDuplicateDistance: getIntEnv("NEAR_DUPLICATE_DISTANCE", 3),
+		NearDuplicateWindow:   getDurationEnv("NEAR_DUPLICATE_WINDOW", 7*24*time.Hour),
+
+		EnableAnswerTracking: getBoolEnv("ENABLE_ANSWER_TRACKING", true),
+
+		CostTwitterPerRequest:     getFloatEnv("COST_TWITTER_PER_REQUEST", 0),
+		CostTwitterPerPost:        getFloatEnv("COST_TWITTER_PER_POST", 0),
+		CostOpenAIPromptPer1K:     getFloatEnv("COST_OPENAI_PROMPT_PER_1K", 0.00015),
+		CostOpenAICompletionPer1K: getFloatEnv("COST_OPENAI_COMPLETION_PER_1K", 0.0006),
 	}
 
-	// Validate required configuration
-	if err := cfg.validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	recipients, err := parseEmailRecipients(getEnv("EMAIL_RECIPIENTS", ""), getEnv("NOTIFICATION_EMAIL", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EMAIL_RECIPIENTS: %w", err)
+	}
+	cfg.EmailRecipients = recipients
+
+	channels, err := parseNotificationChannels(getEnv("NOTIFICATION_CHANNELS", ""), cfg.NotificationRetries, cfg.NotificationLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_CHANNELS: %w", err)
+	}
+	cfg.NotificationChannels = channels
+
+	thresholds, err := parseAlertThresholds(getEnv("ALERT_THRESHOLDS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALERT_THRESHOLDS: %w", err)
+	}
+	cfg.AlertThresholds = thresholds
+
+	queries, err := parseKeywordQueries(getEnv("KEYWORD_QUERIES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KEYWORD_QUERIES: %w", err)
 	}
+	if err := addKeywordPatterns(getEnv("KEYWORD_PATTERNS", ""), queries); err != nil {
+		return nil, fmt.Errorf("invalid KEYWORD_PATTERNS: %w", err)
+	}
+	cfg.KeywordQueries = queries
+
+	weights, err := parseRankWeights(getEnv("MENTION_RANK_WEIGHTS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MENTION_RANK_WEIGHTS: %w", err)
+	}
+	cfg.MentionRankWeights = weights
+
+	trust, err := parseSourceTrust(getEnv("MENTION_SOURCE_TRUST", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MENTION_SOURCE_TRUST: %w", err)
+	}
+	cfg.MentionSourceTrust = trust
 
+	schedules, err := parseSourceSchedules(getEnv("SOURCE_SCHEDULES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOURCE_SCHEDULES: %w", err)
+	}
+	cfg.SourceSchedules = schedules
+
+	cfg.applyFeatureFlags()
 	return cfg, nil
 }
 
@@ -97,29 +681,404 @@ func (c *Config) validate() error {
 		return fmt.Errorf("REPORT_SCHEDULE must be 'daily' or 'weekly'")
 	}
 
-	if c.TeamsWebhookURL == "" && c.NotificationEmail == "" {
-		return fmt.Errorf("at least one notification method must be configured (TEAMS_WEBHOOK_URL or NOTIFICATION_EMAIL)")
+	if c.ScheduleJitter < 0 {
+		return fmt.Errorf("SCHEDULE_JITTER must not be negative")
+	}
+
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+
+	if c.AppConfigRefreshInterval != 0 && c.AppConfigRefreshInterval < 30*time.Second {
+		return fmt.Errorf("APP_CONFIG_REFRESH_INTERVAL must be 0 or at least 30s")
+	}
+
+	if c.TeamsDeliveryMode != "webhook" && c.TeamsDeliveryMode != "graph" {
+		return fmt.Errorf("TEAMS_DELIVERY_MODE must be 'webhook' or 'graph'")
+	}
+
+	switch c.TeamsWebhookFormat {
+	case TeamsWebhookFormatAuto, TeamsWebhookFormatMessageCard, TeamsWebhookFormatLogicApp:
+	default:
+		return fmt.Errorf("TEAMS_WEBHOOK_FORMAT must be 'auto', 'messagecard' or 'logicapp'")
+	}
+
+	if c.TeamsDeliveryMode == "graph" && (c.TeamsTeamID == "" || c.TeamsChannelID == "") {
+		return fmt.Errorf("TEAMS_TEAM_ID and TEAMS_CHANNEL_ID are required when TEAMS_DELIVERY_MODE is 'graph'")
+	}
+
+	if !c.TeamsEnabled() && len(c.EmailRecipients) == 0 && len(c.OutboundWebhookURLs) == 0 && len(c.NotificationChannels) == 0 {
+		return fmt.Errorf("at least one notification method must be configured (NOTIFICATION_CHANNELS, TEAMS_WEBHOOK_URL, Teams Graph channel, EMAIL_RECIPIENTS or OUTBOUND_WEBHOOK_URLS)")
+	}
+
+	if c.NotificationRetries < 0 {
+		return fmt.Errorf("NOTIFICATION_RETRIES cannot be negative")
+	}
+
+	if c.NotificationQueueMaxAttempts < 0 {
+		return fmt.Errorf("NOTIFICATION_QUEUE_MAX_ATTEMPTS cannot be negative")
+	}
+
+	if c.NotificationQueueMaxAttempts > 0 && c.NotificationQueueBackoff < time.Minute {
+		return fmt.Errorf("NOTIFICATION_QUEUE_BACKOFF must be at least 1m")
 	}
 
-	if c.NotificationEmail != "" {
-		if c.SMTPHost == "" || c.SMTPUsername == "" || c.SMTPPassword == "" {
-			return fmt.Errorf("SMTP configuration is required when NOTIFICATION_EMAIL is set")
+	if !knownLocale(c.NotificationLocale) {
+		return fmt.Errorf("NOTIFICATION_LOCALE must be one of %s", strings.Join(NotificationLocales, ", "))
+	}
+
+	names := make(map[string]bool)
+	for _, channel := range c.NotificationChannels {
+		if err := validateNotificationChannel(channel); err != nil {
+			return err
+		}
+		if names[channel.Name] {
+			return fmt.Errorf("channel name %q is used more than once in NOTIFICATION_CHANNELS", channel.Name)
 		}
+		names[channel.Name] = true
+	}
+
+	if c.EmailDeliveryMode != "smtp" && c.EmailDeliveryMode != "graph" {
+		return fmt.Errorf("EMAIL_DELIVERY_MODE must be 'smtp' or 'graph'")
+	}
+
+	if len(c.EmailRecipients) > 0 {
+		if c.EmailDeliveryMode == "graph" && c.GraphMailSender == "" {
+			return fmt.Errorf("GRAPH_MAIL_SENDER is required when EMAIL_DELIVERY_MODE is 'graph'")
+		}
+		if c.EmailDeliveryMode == "smtp" && (c.SMTPHost == "" || c.SMTPUsername == "" || c.SMTPPassword == "") {
+			return fmt.Errorf("SMTP configuration is required when EMAIL_RECIPIENTS or NOTIFICATION_EMAIL is set")
+		}
+	}
+
+	if c.TeamsBotAppID != "" && c.TeamsBotAppPassword == "" {
+		return fmt.Errorf("TEAMS_BOT_APP_PASSWORD is required when TEAMS_BOT_APP_ID is set")
+	}
+
+	for _, recipient := range c.EmailRecipients {
+		if err := c.ValidateRecipient(recipient); err != nil {
+			return err
+		}
+	}
+
+	for _, threshold := range c.AlertThresholds {
+		if err := c.validateAlertThreshold(threshold); err != nil {
+			return err
+		}
+	}
+
+	if c.AlertThresholdMinMentions < 0 {
+		return fmt.Errorf("ALERT_THRESHOLD_MIN_MENTIONS cannot be negative")
+	}
+
+	if c.TeamsMentionsPerSource < 0 {
+		return fmt.Errorf("TEAMS_MENTIONS_PER_SOURCE cannot be negative")
+	}
+
+	switch c.TeamsMentionRanking {
+	case MentionRankingEngagement, MentionRankingRelevance, MentionRankingRecent, MentionRankingRank:
+	default:
+		return fmt.Errorf("TEAMS_MENTION_RANKING must be 'engagement', 'relevance', 'recent' or 'rank'")
+	}
+
+	if c.SentimentScoreWindow < 24*time.Hour {
+		return fmt.Errorf("SENTIMENT_SCORE_WINDOW must be at least 24h")
+	}
+
+	if c.CostTwitterPerRequest < 0 || c.CostTwitterPerPost < 0 || c.CostOpenAIPromptPer1K < 0 || c.CostOpenAICompletionPer1K < 0 {
+		return fmt.Errorf("COST_* prices cannot be negative")
+	}
+
+	if c.HackerNewsItemLimit < 1 {
+		return fmt.Errorf("HACKERNEWS_ITEM_LIMIT must be at least 1")
+	}
+
+	if c.YouTubeMaxResults < 1 || c.YouTubeMaxResults > 50 {
+		return fmt.Errorf("YOUTUBE_MAX_RESULTS must be between 1 and 50")
+	}
+
+	if c.YouTubeCommentVideos < 0 {
+		return fmt.Errorf("YOUTUBE_COMMENT_VIDEOS cannot be negative")
+	}
+
+	for _, channel := range c.YouTubeTrustedChannels {
+		if channel = strings.TrimSpace(channel); channel != "" && !strings.HasPrefix(channel, "UC") {
+			return fmt.Errorf("YOUTUBE_TRUSTED_CHANNELS must list channel IDs starting with UC, got %q", channel)
+		}
+	}
+
+	if c.LinkedInPageMaxPosts < 1 || c.LinkedInPageMaxPosts > 100 {
+		return fmt.Errorf("LINKEDIN_PAGE_MAX_POSTS must be between 1 and 100")
+	}
+
+	if c.LinkedInAccessToken != "" && c.LinkedInOrganizationID == "" {
+		return fmt.Errorf("LINKEDIN_ORGANIZATION_ID is required with LINKEDIN_ACCESS_TOKEN")
+	}
+
+	if c.BingMaxResultsPerDomain < 1 {
+		return fmt.Errorf("BING_MAX_RESULTS_PER_DOMAIN must be at least 1")
+	}
+
+	if c.PodcastMaxTranscriptions < 0 {
+		return fmt.Errorf("PODCAST_MAX_TRANSCRIPTIONS cannot be negative")
+	}
+
+	if c.DocsGapMaxLookups < 0 {
+		return fmt.Errorf("DOCS_GAP_MAX_LOOKUPS cannot be negative")
+	}
+
+	if (c.SpeechEndpoint == "") != (c.SpeechKey == "") {
+		return fmt.Errorf("SPEECH_ENDPOINT and SPEECH_KEY must be set together")
+	}
+
+	if c.ContentFormat != ContentFormatMarkdown && c.ContentFormat != ContentFormatText {
+		return fmt.Errorf("CONTENT_FORMAT must be 'markdown' or 'text'")
+	}
+
+	if c.EnableClickTracking && c.PublicBaseURL == "" {
+		return fmt.Errorf("ENABLE_CLICK_TRACKING requires PUBLIC_BASE_URL for the redirect links")
+	}
+
+	if (c.AzureMonitorResourceID == "") != (c.AzureMonitorRegion == "") {
+		return fmt.Errorf("AZURE_MONITOR_RESOURCE_ID and AZURE_MONITOR_REGION must be set together")
+	}
+	if c.AzureMonitorConfigured() {
+		if !strings.HasPrefix(c.AzureMonitorResourceID, "/subscriptions/") {
+			return fmt.Errorf("AZURE_MONITOR_RESOURCE_ID must be a resource ID starting with /subscriptions/")
+		}
+		if c.AzureMonitorNamespace == "" {
+			return fmt.Errorf("AZURE_MONITOR_NAMESPACE must not be empty when AZURE_MONITOR_RESOURCE_ID is set")
+		}
+	}
+
+	if c.EnableLLMQuestionDetection && !c.LLMConfigured() {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT and AZURE_OPENAI_DEPLOYMENT are required when ENABLE_LLM_QUESTION_DETECTION is set")
+	}
+
+	if c.EnableLLMSpamDetection && !c.LLMConfigured() {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT and AZURE_OPENAI_DEPLOYMENT are required when ENABLE_LLM_SPAM_DETECTION is set")
+	}
+
+	if c.EnableLLMUrgentVerification && !c.LLMConfigured() {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT and AZURE_OPENAI_DEPLOYMENT are required when ENABLE_LLM_URGENT_VERIFICATION is set")
+	}
+
+	if c.EnableTranslation && !c.LLMConfigured() {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT and AZURE_OPENAI_DEPLOYMENT are required when ENABLE_TRANSLATION is set")
+	}
+
+	if c.TranslationMaxMentions < 0 {
+		return fmt.Errorf("TRANSLATION_MAX_MENTIONS must not be negative")
+	}
+
+	if c.NearDuplicateDistance < 0 || c.NearDuplicateDistance > 16 {
+		return fmt.Errorf("NEAR_DUPLICATE_DISTANCE must be between 0 and 16 bits")
+	}
+
+	if c.NearDuplicateWindow < 0 {
+		return fmt.Errorf("NEAR_DUPLICATE_WINDOW must not be negative")
+	}
+
+	if c.SourceConcurrency < 1 {
+		return fmt.Errorf("SOURCE_CONCURRENCY must be at least 1")
+	}
+
+	if c.EnrichmentConcurrency < 1 {
+		return fmt.Errorf("ENRICHMENT_CONCURRENCY must be at least 1")
+	}
+
+	if c.EnrichmentCacheSize < 0 {
+		return fmt.Errorf("ENRICHMENT_CACHE_SIZE must not be negative")
+	}
+
+	if c.HTTPCacheSize < 0 {
+		return fmt.Errorf("HTTP_CACHE_SIZE must not be negative")
+	}
+
+	if c.HTTPCacheTTL < 0 {
+		return fmt.Errorf("HTTP_CACHE_TTL must not be negative")
+	}
+
+	if c.SourceTimeout <= 0 {
+		return fmt.Errorf("SOURCE_TIMEOUT must be a positive duration")
+	}
+
+	if c.SourceBreakerThreshold < 0 {
+		return fmt.Errorf("SOURCE_BREAKER_THRESHOLD must not be negative")
+	}
+
+	if c.SourceBreakerThreshold > 0 && c.SourceBreakerCooldown <= 0 {
+		return fmt.Errorf("SOURCE_BREAKER_COOLDOWN must be a positive duration")
+	}
+
+	if c.SourceErrorBudget < 0 || c.SourceErrorBudget > 1 {
+		return fmt.Errorf("SOURCE_ERROR_BUDGET must be between 0 and 1")
+	}
+
+	if c.SourceFixtures != "" && c.SourceFixtureMode != FixtureModeReplay && c.SourceFixtureMode != FixtureModeRecord {
+		return fmt.Errorf("SOURCE_FIXTURE_MODE must be 'replay' or 'record'")
+	}
+
+	if c.UrgentSourceTimeout <= 0 {
+		return fmt.Errorf("URGENT_SOURCE_TIMEOUT must be a positive duration")
+	}
+
+	if c.UrgentAlertCooldown < 0 {
+		return fmt.Errorf("URGENT_ALERT_COOLDOWN must not be negative")
+	}
+
+	if c.ReportAlertedMentions != ReportAlertedSection && c.ReportAlertedMentions != ReportAlertedSuppress {
+		return fmt.Errorf("REPORT_ALERTED_MENTIONS must be '%s' or '%s'", ReportAlertedSection, ReportAlertedSuppress)
+	}
+
+	if c.EnableParquetExport && c.ParquetExportPrefix == "" {
+		return fmt.Errorf("PARQUET_EXPORT_PREFIX must not be empty when ENABLE_PARQUET_EXPORT is set")
+	}
+
+	if c.RedditDiscovery && (c.RedditDiscoveryMax < 1 || c.RedditDiscoveryMinMentions < 1) {
+		return fmt.Errorf("REDDIT_DISCOVERY_MAX and REDDIT_DISCOVERY_MIN_MENTIONS must be at least 1")
+	}
+
+	if c.TwitterRequestsPerWindow < 1 || c.TwitterMaxPages < 1 {
+		return fmt.Errorf("TWITTER_REQUESTS_PER_WINDOW and TWITTER_MAX_PAGES must be at least 1")
+	}
+
+	if c.TwitterMaxWait < 0 {
+		return fmt.Errorf("TWITTER_MAX_WAIT must not be negative")
+	}
+
+	if c.TwitterStreamEnabled && c.TwitterBearerToken == "" {
+		return fmt.Errorf("TWITTER_BEARER_TOKEN is required when TWITTER_STREAM_ENABLED is set")
+	}
+
+	if c.TwitterStreamEnabled && c.TwitterStreamBatchWindow <= 0 {
+		return fmt.Errorf("TWITTER_STREAM_BATCH_WINDOW must be a positive duration")
+	}
+
+	if c.WatermarkOverlap < 0 {
+		return fmt.Errorf("WATERMARK_OVERLAP must not be negative")
+	}
+
+	if c.WatermarkMaxWindow <= 0 {
+		return fmt.Errorf("WATERMARK_MAX_WINDOW must be a positive duration")
+	}
+
+	if c.StorageConnectionString != "" && c.StorageSASToken != "" {
+		return fmt.Errorf("set only one of AZURE_STORAGE_CONNECTION_STRING and AZURE_STORAGE_SAS_TOKEN")
+	}
+
+	if c.StorageEncryptionKeyID != "" {
+		keyURL, err := url.Parse(c.StorageEncryptionKeyID)
+		if err != nil || keyURL.Scheme != "https" || !strings.HasPrefix(keyURL.Path, "/keys/") {
+			return fmt.Errorf("STORAGE_ENCRYPTION_KEY_ID must be a Key Vault key identifier, e.g. https://<vault>.vault.azure.net/keys/<name>")
+		}
+	}
+
+	if c.APIToken == "" {
+		key := "API_TOKEN"
+		if c.Profile != "" {
+			key = ProfileEnvPrefix(c.Profile) + key
+		}
+		return fmt.Errorf("%s is required to protect the HTTP API", key)
 	}
 
 	return nil
 }
 
+// TeamsEnabled reports whether Teams notifications are configured for the selected delivery mode
+func (c *Config) TeamsEnabled() bool {
+	if c.TeamsDeliveryMode == "graph" {
+		return c.TeamsTeamID != "" && c.TeamsChannelID != ""
+	}
+	return c.TeamsWebhookURL != ""
+}
+
+// StorageConfigured reports whether enough is set to reach Azure Storage: an account name or
+// blob endpoint, or a connection string
+func (c *Config) StorageConfigured() bool {
+	return c.StorageAccount != "" || c.StorageBlobEndpoint != "" || c.StorageConnectionString != ""
+}
+
+// AzureMonitorConfigured reports whether run metrics are published to Azure Monitor
+func (c *Config) AzureMonitorConfigured() bool {
+	return c.AzureMonitorResourceID != "" && c.AzureMonitorRegion != ""
+}
+
+// LLMConfigured reports whether an Azure OpenAI deployment is configured for LLM enrichment
+func (c *Config) LLMConfigured() bool {
+	return c.AzureOpenAIEndpoint != "" && c.AzureOpenAIDeployment != ""
+}
+
+// SourceEnabled reports whether the named source is enabled; sources are enabled unless
+// explicitly disabled with <NAME>_ENABLED=false when the configuration was parsed
+func (c *Config) SourceEnabled(name string) bool {
+	if enabled, ok := c.SourcesEnabled[name]; ok {
+		return enabled
+	}
+	return true
+}
+
+// UrgentKeywordList returns the keywords urgent checks search for
+func (c *Config) UrgentKeywordList() []string {
+	if len(c.UrgentKeywords) > 0 {
+		return c.UrgentKeywords
+	}
+	return c.Keywords
+}
+
+// KnownSources lists the source names that can be toggled with <NAME>_ENABLED
+var KnownSources = []string{"reddit", "stackoverflow", "hackernews", "twitter", "youtube", "medium", "linkedin", "linkedinpage", "cve", "gitlab", "github", "bitbucket", "threads", "web", "podcast", "qiita", "cnfeeds"}
+
+// pluginSources lists the source plugins registered outside KnownSources, whose toggles are
+// read along with the built-in ones
+var (
+	pluginSourcesMu sync.Mutex
+	pluginSources   []string
+)
+
+// RegisterSourceName makes <NAME>_ENABLED toggle a source plugin; the source registry calls it
+func RegisterSourceName(name string) {
+	pluginSourcesMu.Lock()
+	defer pluginSourcesMu.Unlock()
+
+	for _, known := range KnownSources {
+		if known == name {
+			return
+		}
+	}
+	for _, known := range pluginSources {
+		if known == name {
+			return
+		}
+	}
+	pluginSources = append(pluginSources, name)
+}
+
+func getSourcesEnabled() map[string]bool {
+	pluginSourcesMu.Lock()
+	names := append(append([]string(nil), KnownSources...), pluginSources...)
+	pluginSourcesMu.Unlock()
+
+	enabled := make(map[string]bool)
+	for _, name := range names {
+		key := strings.ToUpper(name) + "_ENABLED"
+		if getenv(key) != "" {
+			enabled[name] = getBoolEnv(key, true)
+		}
+	}
+	return enabled
+}
+
 // Helper functions for environment variable parsing
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
 func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+	if value := getenv(key); value != "" {
 		if parsed, err := strconv.ParseBool(value); err == nil {
 			return parsed
 		}
@@ -128,7 +1087,7 @@ func getBoolEnv(key string, defaultValue bool) bool {
 }
 
 func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := getenv(key); value != "" {
 		if parsed, err := strconv.Atoi(value); err == nil {
 			return parsed
 		}
@@ -137,7 +1096,7 @@ func getIntEnv(key string, defaultValue int) int {
 }
 
 func getFloatEnv(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
+	if value := getenv(key); value != "" {
 		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
 			return parsed
 		}
@@ -145,8 +1104,43 @@ func getFloatEnv(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getDurationMapEnv parses values like "hackernews=5m,twitter=2m"
+func getDurationMapEnv(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, pair := range getSliceEnv(key, nil) {
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		if parsed, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			result[strings.TrimSpace(name)] = parsed
+		}
+	}
+	return result
+}
+
+// getNamesEnv parses a comma-separated list of names, lowercased and trimmed
+func getNamesEnv(key string) []string {
+	var names []string
+	for _, name := range getSliceEnv(key, nil) {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func getSliceEnv(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
+	if value := getenv(key); value != "" {
 		return strings.Split(value, ",")
 	}
 	return defaultValue
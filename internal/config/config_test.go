@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadValues(t *testing.T) {
+	t.Setenv("TEAMS_WEBHOOK_URL", "https://contoso.webhook.office.com/from-env")
+	t.Setenv("REPORT_SCHEDULE", "monthly")
+
+	_, err := LoadValues(map[string]string{"KEYWORDS": "AKS"})
+	assert.ErrorContains(t, err, "at least one notification method", "the environment is ignored")
+
+	_, err = ParseValues(map[string]string{"EMAIL_RECIPIENTS": "alice.contoso.com"})
+	assert.Error(t, err)
+
+	values := map[string]string{
+		"KEYWORDS":          "AKS,KAITO",
+		"TEAMS_WEBHOOK_URL": "https://contoso.webhook.office.com/setup",
+	}
+	_, err = LoadValues(values)
+	assert.ErrorContains(t, err, "API_TOKEN is required")
+
+	values["API_TOKEN"] = "secret"
+	values["YOUTUBE_TRUSTED_CHANNELS"] = "UCsMica-v34Irf9KVTh6xx-g,Microsoft Developer"
+	_, err = LoadValues(values)
+	assert.ErrorContains(t, err, "YOUTUBE_TRUSTED_CHANNELS must list channel IDs")
+
+	values["YOUTUBE_TRUSTED_CHANNELS"] = "UCsMica-v34Irf9KVTh6xx-g, UCvqbFHwN-nwalWPjPUKpvTA,"
+	cfg, err := LoadValues(values)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AKS", "KAITO"}, cfg.Keywords)
+	assert.Equal(t, "weekly", cfg.ReportSchedule)
+
+	cfg, err = Parse()
+	require.NoError(t, err)
+	assert.Equal(t, "https://contoso.webhook.office.com/from-env", cfg.TeamsWebhookURL, "later parses read the environment again")
+}
+
+func TestSourceEnabled(t *testing.T) {
+	RegisterSourceName("forum")
+	cfg, err := LoadValues(map[string]string{
+		"KEYWORDS":          "AKS",
+		"TEAMS_WEBHOOK_URL": "https://contoso.webhook.office.com/setup",
+		"API_TOKEN":         "secret",
+		"REDDIT_ENABLED":    "false",
+		"FORUM_ENABLED":     "false",
+	})
+	require.NoError(t, err)
+	assert.False(t, cfg.SourceEnabled("reddit"))
+	assert.False(t, cfg.SourceEnabled("forum"), "toggles of registered plugin sources are read at parse time")
+	assert.True(t, cfg.SourceEnabled("twitter"))
+
+	t.Setenv("TWITTER_ENABLED", "false")
+	assert.True(t, cfg.SourceEnabled("twitter"), "the environment isn't read after parsing")
+}
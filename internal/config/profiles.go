@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// getenv reads App Configuration and the environment for parse. ParseProfile swaps in a
+// lookup that prefers the profile's overrides; envMu serializes parses so the swap never
+// leaks into another one.
+var (
+	envMu  sync.Mutex
+	getenv = lookupEnv
+)
+
+// profileNamePattern restricts profile names to what fits an environment variable prefix, a
+// blob prefix and a URL path segment
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// profileOnlySettings are not inherited from the default configuration, so a profile never
+// delivers to another team's channels or shares its API token
+var profileOnlySettings = map[string]bool{
+	"TEAMS_WEBHOOK_URL":     true,
+	"TEAMS_WEBHOOK_FORMAT":  true,
+	"TEAMS_TEAM_ID":         true,
+	"TEAMS_CHANNEL_ID":      true,
+	"EMAIL_RECIPIENTS":      true,
+	"NOTIFICATION_EMAIL":    true,
+	"OUTBOUND_WEBHOOK_URLS": true,
+	"NOTIFICATION_CHANNELS": true,
+	"API_TOKEN":             true,
+}
+
+// ProfileEnvPrefix returns the prefix of the environment variables that configure a profile,
+// e.g. PROFILE_FLEET_MANAGER_ for "fleet-manager"
+func ProfileEnvPrefix(name string) string {
+	return "PROFILE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+// ParseProfile reads the configuration of a monitoring profile without validating it. Each
+// setting is read from PROFILE_<NAME>_<SETTING>, falling back to <SETTING> except for the
+// notification targets, which every profile sets for itself.
+func ParseProfile(name string) (*Config, error) {
+	if !profileNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid profile name %q: use up to 32 lowercase letters, digits and '-'", name)
+	}
+	prefix := ProfileEnvPrefix(name)
+
+	envMu.Lock()
+	defer envMu.Unlock()
+
+	getenv = func(key string) string {
+		if value := lookupEnv(prefix + key); value != "" || profileOnlySettings[key] {
+			return value
+		}
+		return lookupEnv(key)
+	}
+	defer func() { getenv = lookupEnv }()
+
+	cfg, err := parse()
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	cfg.Profile = name
+
+	// Links in the profile's notifications lead to its own routes
+	if lookupEnv(prefix+"PUBLIC_BASE_URL") == "" && cfg.PublicBaseURL != "" {
+		cfg.PublicBaseURL += "/profiles/" + name
+	}
+	return cfg, nil
+}
+
+// LoadProfile loads and validates the configuration of a monitoring profile
+func LoadProfile(name string) (*Config, error) {
+	cfg, err := ParseProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("profile %s configuration validation failed: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// StoragePrefix returns the blob prefix the configuration's data is kept under: none for the
+// default configuration and profiles/<name>/ for a profile
+func (c *Config) StoragePrefix() string {
+	if c.Profile == "" {
+		return ""
+	}
+	return "profiles/" + c.Profile + "/"
+}
+
+// validateProfiles checks the names in PROFILES
+func (c *Config) validateProfiles() error {
+	seen := make(map[string]bool)
+	for _, name := range c.Profiles {
+		if !profileNamePattern.MatchString(name) {
+			return fmt.Errorf("PROFILES: invalid profile name %q: use up to 32 lowercase letters, digits and '-'", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("PROFILES: profile %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
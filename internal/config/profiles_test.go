@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileEnvPrefix(t *testing.T) {
+	assert.Equal(t, "PROFILE_KAITO_", ProfileEnvPrefix("kaito"))
+	assert.Equal(t, "PROFILE_FLEET_MANAGER_", ProfileEnvPrefix("fleet-manager"))
+}
+
+func TestParseProfile(t *testing.T) {
+	t.Setenv("PROFILES", "kaito,Fleet-Manager")
+	t.Setenv("KEYWORDS", "AKS")
+	t.Setenv("REPORT_SCHEDULE", "weekly")
+	t.Setenv("TEAMS_WEBHOOK_URL", "https://contoso.webhook.office.com/aks")
+	t.Setenv("SMTP_HOST", "smtp.contoso.com")
+	t.Setenv("PUBLIC_BASE_URL", "https://mentions.contoso.com/")
+	t.Setenv("PROFILE_KAITO_KEYWORDS", "KAITO,Kubernetes AI Toolchain Operator")
+	t.Setenv("PROFILE_KAITO_REPORT_SCHEDULE", "daily")
+	t.Setenv("PROFILE_KAITO_EMAIL_RECIPIENTS", "kaito-team@contoso.com")
+	t.Setenv("PROFILE_KAITO_API_TOKEN", "kaito-secret")
+	t.Setenv("PROFILE_FLEET_MANAGER_TEAMS_WEBHOOK_URL", "https://contoso.webhook.office.com/fleet")
+	t.Setenv("API_TOKEN", "root-secret")
+
+	root, err := Parse()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kaito", "fleet-manager"}, root.Profiles)
+	assert.Empty(t, root.StoragePrefix())
+
+	kaito, err := ParseProfile("kaito")
+	require.NoError(t, err)
+	assert.Equal(t, "kaito", kaito.Profile)
+	assert.Empty(t, kaito.Profiles)
+	assert.Equal(t, []string{"KAITO", "Kubernetes AI Toolchain Operator"}, kaito.Keywords)
+	assert.Equal(t, "daily", kaito.ReportSchedule)
+	assert.Equal(t, "smtp.contoso.com", kaito.SMTPHost, "shared settings are inherited")
+	assert.Empty(t, kaito.TeamsWebhookURL, "notification targets are not inherited")
+	require.Len(t, kaito.EmailRecipients, 1)
+	assert.Equal(t, "kaito-team@contoso.com", kaito.EmailRecipients[0].Email)
+	assert.Equal(t, "kaito-secret", kaito.APIToken)
+	assert.Equal(t, "https://mentions.contoso.com/profiles/kaito", kaito.PublicBaseURL)
+	assert.Equal(t, "profiles/kaito/", kaito.StoragePrefix())
+
+	fleet, err := ParseProfile("fleet-manager")
+	require.NoError(t, err)
+	assert.Empty(t, fleet.APIToken, "API tokens are not inherited")
+	assert.ErrorContains(t, fleet.validate(), "PROFILE_FLEET_MANAGER_API_TOKEN is required")
+
+	// Parsing a profile leaves the default configuration alone
+	root, err = Parse()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AKS"}, root.Keywords)
+	assert.Equal(t, "https://contoso.webhook.office.com/aks", root.TeamsWebhookURL)
+	assert.Equal(t, "root-secret", root.APIToken)
+
+	_, err = ParseProfile("Bad Name")
+	assert.ErrorContains(t, err, "invalid profile name")
+}
+
+func TestConfig_validateProfiles(t *testing.T) {
+	assert.NoError(t, (&Config{Profiles: []string{"kaito", "fleet-manager"}}).validateProfiles())
+	assert.ErrorContains(t, (&Config{Profiles: []string{"kaito", "kaito"}}).validateProfiles(), "listed twice")
+	assert.ErrorContains(t, (&Config{Profiles: []string{"kaito_team"}}).validateProfiles(), "invalid profile name")
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/matching"
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// parseKeywordQueries parses KEYWORD_QUERIES, which overrides the built-in search templates
+// per keyword, e.g. "kaito=terms:Kubernetes AI Toolchain Operator,context:kubernetes|k8s;aks=exclude:rifle|gun".
+// Each option lists values separated by "|"; an option with no values clears the built-in one.
+func parseKeywordQueries(value string) (map[string]models.KeywordQuery, error) {
+	queries := make(map[string]models.KeywordQuery)
+	for _, definition := range strings.Split(value, ";") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		keyword, options, found := strings.Cut(definition, "=")
+		keyword = strings.TrimSpace(keyword)
+		if !found || keyword == "" {
+			return nil, fmt.Errorf("invalid query %q, expected keyword=option:value|value", definition)
+		}
+
+		query := models.KeywordQuery{Keyword: keyword}
+		for _, option := range strings.Split(options, ",") {
+			name, values, found := strings.Cut(strings.TrimSpace(option), ":")
+			if !found {
+				return nil, fmt.Errorf("invalid option %q for keyword %q, expected option:value|value", option, keyword)
+			}
+
+			list := []string{}
+			for _, value := range strings.Split(values, "|") {
+				if value = strings.TrimSpace(value); value != "" {
+					list = append(list, value)
+				}
+			}
+
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "terms":
+				query.Terms = list
+			case "context":
+				query.Context = list
+			case "exclude":
+				query.Exclude = list
+			default:
+				return nil, fmt.Errorf("unknown option %q for keyword %q, expected terms, context or exclude", name, keyword)
+			}
+		}
+		queries[strings.ToLower(keyword)] = query
+	}
+	return queries, nil
+}
+
+// addKeywordPatterns parses KEYWORD_PATTERNS into the query templates, e.g.
+// "kaito=/\bkaito\b.{0,80}(kubernetes|operator)/;azure container service=azure NEAR/3 containers".
+// A keyword listed more than once gets every pattern; content must match one of them.
+func addKeywordPatterns(value string, queries map[string]models.KeywordQuery) error {
+	for _, definition := range strings.Split(value, ";") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+
+		keyword, expr, found := strings.Cut(definition, "=")
+		keyword = strings.TrimSpace(keyword)
+		if !found || keyword == "" {
+			return fmt.Errorf("invalid pattern %q, expected keyword=pattern", definition)
+		}
+		if _, err := matching.Compile(expr); err != nil {
+			return fmt.Errorf("keyword %q: %w", keyword, err)
+		}
+
+		key := strings.ToLower(keyword)
+		query, ok := queries[key]
+		if !ok {
+			query.Keyword = keyword
+		}
+		query.Patterns = append(query.Patterns, strings.TrimSpace(expr))
+		queries[key] = query
+	}
+	return nil
+}
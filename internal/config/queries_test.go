@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeywordQueries(t *testing.T) {
+	queries, err := parseKeywordQueries("KAITO=terms:Kubernetes AI Toolchain Operator,context:kubernetes|k8s; aks=exclude:")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.KeywordQuery{
+		"kaito": {Keyword: "KAITO", Terms: []string{"Kubernetes AI Toolchain Operator"}, Context: []string{"kubernetes", "k8s"}},
+		"aks":   {Keyword: "aks", Exclude: []string{}},
+	}, queries)
+
+	_, err = parseKeywordQueries("kaito")
+	assert.ErrorContains(t, err, "expected keyword=option")
+
+	_, err = parseKeywordQueries("kaito=include:k8s")
+	assert.ErrorContains(t, err, "unknown option")
+}
+
+func TestAddKeywordPatterns(t *testing.T) {
+	queries, err := parseKeywordQueries("kaito=context:kubernetes")
+	require.NoError(t, err)
+
+	require.NoError(t, addKeywordPatterns(`KAITO=/\bkaito\b.{0,80}(kubernetes|operator)/; kaito = kaito NEAR/5 inference; Fleet Manager=fleet NEAR/2 clusters`, queries))
+	assert.Equal(t, map[string]models.KeywordQuery{
+		"kaito": {
+			Keyword:  "kaito",
+			Context:  []string{"kubernetes"},
+			Patterns: []string{`/\bkaito\b.{0,80}(kubernetes|operator)/`, "kaito NEAR/5 inference"},
+		},
+		"fleet manager": {Keyword: "Fleet Manager", Patterns: []string{"fleet NEAR/2 clusters"}},
+	}, queries)
+
+	assert.ErrorContains(t, addKeywordPatterns("kaito", queries), "expected keyword=pattern")
+	assert.ErrorContains(t, addKeywordPatterns("kaito=/kaito(/", queries), "invalid pattern")
+}
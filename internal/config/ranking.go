@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Factors of the mention ranking formula, as named in MENTION_RANK_WEIGHTS
+const (
+	RankFactorEngagement      = "engagement"
+	RankFactorRecency         = "recency"
+	RankFactorSentiment       = "sentiment"
+	RankFactorSourceTrust     = "source_trust"
+	RankFactorAuthorInfluence = "author_influence"
+)
+
+// RankFactors lists the ranking factors in the order they are reported
+var RankFactors = []string{
+	RankFactorEngagement,
+	RankFactorRecency,
+	RankFactorSentiment,
+	RankFactorSourceTrust,
+	RankFactorAuthorInfluence,
+}
+
+// DefaultRankWeights favor discussions that drew engagement, then the newest
+var DefaultRankWeights = map[string]float64{
+	RankFactorEngagement:      0.35,
+	RankFactorRecency:         0.25,
+	RankFactorSentiment:       0.15,
+	RankFactorSourceTrust:     0.15,
+	RankFactorAuthorInfluence: 0.1,
+}
+
+// parseRankWeights parses MENTION_RANK_WEIGHTS entries such as "engagement=0.5,recency=0.5".
+// Factors left out weigh nothing; an empty value keeps DefaultRankWeights.
+func parseRankWeights(value string) (map[string]float64, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultRankWeights, nil
+	}
+
+	weights := make(map[string]float64, len(RankFactors))
+	for _, factor := range RankFactors {
+		weights[factor] = 0
+	}
+	total := 0.0
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		factor, weight, found := strings.Cut(entry, "=")
+		factor = strings.ToLower(strings.TrimSpace(factor))
+		if !found {
+			return nil, fmt.Errorf("invalid weight %q, expected factor=weight", entry)
+		}
+		if _, ok := weights[factor]; !ok {
+			return nil, fmt.Errorf("unknown ranking factor %q, expected one of %s", factor, strings.Join(RankFactors, ", "))
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid weight in %q", entry)
+		}
+		weights[factor] = parsed
+		total += parsed
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("at least one ranking factor needs a positive weight")
+	}
+	return weights, nil
+}
+
+// parseSourceTrust parses MENTION_SOURCE_TRUST entries such as "reddit=0.6,youtube=0.3", the
+// trust (0-1) the ranking formula gives each source's mentions
+func parseSourceTrust(value string) (map[string]float64, error) {
+	trust := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		source, level, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid source trust %q, expected source=trust", entry)
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(level), 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return nil, fmt.Errorf("invalid trust in %q, expected a value between 0 and 1", entry)
+		}
+		trust[strings.ToLower(strings.TrimSpace(source))] = parsed
+	}
+	return trust, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRankWeights(t *testing.T) {
+	weights, err := parseRankWeights("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRankWeights, weights)
+
+	weights, err = parseRankWeights("Engagement=0.6, recency=0.4,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{
+		RankFactorEngagement:      0.6,
+		RankFactorRecency:         0.4,
+		RankFactorSentiment:       0,
+		RankFactorSourceTrust:     0,
+		RankFactorAuthorInfluence: 0,
+	}, weights)
+
+	_, err = parseRankWeights("karma=1")
+	assert.ErrorContains(t, err, "unknown ranking factor")
+
+	_, err = parseRankWeights("recency=-1")
+	assert.ErrorContains(t, err, "invalid weight")
+
+	_, err = parseRankWeights("recency=0")
+	assert.ErrorContains(t, err, "positive weight")
+}
+
+func TestParseSourceTrust(t *testing.T) {
+	trust, err := parseSourceTrust("Reddit=0.3, youtube=0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"reddit": 0.3, "youtube": 0}, trust)
+
+	_, err = parseSourceTrust("reddit=2")
+	assert.ErrorContains(t, err, "between 0 and 1")
+
+	_, err = parseSourceTrust("reddit")
+	assert.ErrorContains(t, err, "expected source=trust")
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Email report content and format preferences
+const (
+	EmailContentFull    = "full"    // Summary plus individual mentions
+	EmailContentSummary = "summary" // Summary statistics only
+	EmailFormatHTML     = "html"
+	EmailFormatText     = "text"
+)
+
+// EmailRecipient is a report recipient with individual delivery preferences
+type EmailRecipient struct {
+	Email         string   `json:"email"`
+	Content       string   `json:"content"`                  // "full" or "summary"
+	Format        string   `json:"format"`                   // "html" or "text"
+	KeywordGroups []string `json:"keyword_groups,omitempty"` // Empty means mentions for every keyword
+	AttachPDF     bool     `json:"attach_pdf,omitempty"`     // Attach the report as a PDF
+	Unsubscribed  bool     `json:"unsubscribed,omitempty"`
+}
+
+// NewEmailRecipient returns a recipient with the default preferences (full HTML report)
+func NewEmailRecipient(email string) EmailRecipient {
+	return EmailRecipient{
+		Email:   strings.TrimSpace(email),
+		Content: EmailContentFull,
+		Format:  EmailFormatHTML,
+	}
+}
+
+// parseEmailRecipients parses EMAIL_RECIPIENTS entries such as
+// "alice@contoso.com;content=summary;format=text;groups=fleet|kaito;attach=pdf" and appends the plain
+// addresses from the legacy NOTIFICATION_EMAIL. The first entry for an address wins.
+func parseEmailRecipients(recipients, legacy string) ([]EmailRecipient, error) {
+	var result []EmailRecipient
+	seen := make(map[string]bool)
+
+	add := func(recipient EmailRecipient) {
+		key := strings.ToLower(recipient.Email)
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, recipient)
+		}
+	}
+
+	for _, entry := range strings.Split(recipients, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		recipient, err := parseEmailRecipient(entry)
+		if err != nil {
+			return nil, err
+		}
+		add(recipient)
+	}
+
+	for _, email := range strings.Split(legacy, ",") {
+		if strings.TrimSpace(email) != "" {
+			add(NewEmailRecipient(email))
+		}
+	}
+
+	return result, nil
+}
+
+func parseEmailRecipient(entry string) (EmailRecipient, error) {
+	parts := strings.Split(entry, ";")
+	recipient := NewEmailRecipient(parts[0])
+	if !strings.Contains(recipient.Email, "@") {
+		return recipient, fmt.Errorf("invalid email address %q", recipient.Email)
+	}
+
+	for _, option := range parts[1:] {
+		key, value, found := strings.Cut(option, "=")
+		if !found {
+			return recipient, fmt.Errorf("invalid option %q for %s, expected key=value", option, recipient.Email)
+		}
+
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "content":
+			recipient.Content = value
+		case "format":
+			recipient.Format = value
+		case "groups":
+			for _, group := range strings.Split(value, "|") {
+				if group = strings.TrimSpace(group); group != "" {
+					recipient.KeywordGroups = append(recipient.KeywordGroups, group)
+				}
+			}
+		case "attach":
+			if value != "pdf" {
+				return recipient, fmt.Errorf("attach for %s must be 'pdf'", recipient.Email)
+			}
+			recipient.AttachPDF = true
+		default:
+			return recipient, fmt.Errorf("unknown option %q for %s", key, recipient.Email)
+		}
+	}
+
+	return recipient, nil
+}
+
+// getKeywordGroupsEnv parses values like "fleet=Azure Kubernetes Fleet Manager|KubeFleet;kaito=KAITO"
+func getKeywordGroupsEnv(key string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, definition := range strings.Split(getEnv(key, ""), ";") {
+		name, keywords, found := strings.Cut(definition, "=")
+		if !found {
+			continue
+		}
+		for _, keyword := range strings.Split(keywords, "|") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				groups[strings.TrimSpace(name)] = append(groups[strings.TrimSpace(name)], keyword)
+			}
+		}
+	}
+	return groups
+}
+
+// ValidateRecipient checks a recipient's preferences against the configured keyword groups
+func (c *Config) ValidateRecipient(recipient EmailRecipient) error {
+	if recipient.Content != EmailContentFull && recipient.Content != EmailContentSummary {
+		return fmt.Errorf("content for %s must be '%s' or '%s'", recipient.Email, EmailContentFull, EmailContentSummary)
+	}
+
+	if recipient.Format != EmailFormatHTML && recipient.Format != EmailFormatText {
+		return fmt.Errorf("format for %s must be '%s' or '%s'", recipient.Email, EmailFormatHTML, EmailFormatText)
+	}
+
+	for _, group := range recipient.KeywordGroups {
+		if _, ok := c.KeywordGroups[group]; !ok {
+			return fmt.Errorf("unknown keyword group %q for %s", group, recipient.Email)
+		}
+	}
+
+	if recipient.AttachPDF && !c.EnablePDFReports {
+		return fmt.Errorf("PDF attachments for %s require ENABLE_PDF_REPORTS", recipient.Email)
+	}
+
+	return nil
+}
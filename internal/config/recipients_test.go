@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmailRecipients(t *testing.T) {
+	recipients, err := parseEmailRecipients(
+		"alice@contoso.com;content=summary;format=text;groups=fleet|kaito, bob@contoso.com",
+		"carol@contoso.com,Alice@contoso.com",
+	)
+	require.NoError(t, err)
+	require.Len(t, recipients, 3)
+
+	assert.Equal(t, EmailRecipient{
+		Email:         "alice@contoso.com",
+		Content:       EmailContentSummary,
+		Format:        EmailFormatText,
+		KeywordGroups: []string{"fleet", "kaito"},
+	}, recipients[0])
+	assert.Equal(t, NewEmailRecipient("bob@contoso.com"), recipients[1])
+	assert.Equal(t, NewEmailRecipient("carol@contoso.com"), recipients[2])
+
+	_, err = parseEmailRecipients("alice@contoso.com;fromat=text", "")
+	assert.ErrorContains(t, err, "unknown option")
+
+	_, err = parseEmailRecipients("not-an-address", "")
+	assert.ErrorContains(t, err, "invalid email address")
+
+	recipients, err = parseEmailRecipients("lead@contoso.com;attach=pdf", "")
+	require.NoError(t, err)
+	assert.True(t, recipients[0].AttachPDF)
+
+	_, err = parseEmailRecipients("lead@contoso.com;attach=docx", "")
+	assert.ErrorContains(t, err, "must be 'pdf'")
+}
+
+func TestConfig_ValidateRecipient(t *testing.T) {
+	cfg := &Config{KeywordGroups: map[string][]string{"fleet": {"KubeFleet"}}}
+
+	recipient := NewEmailRecipient("alice@contoso.com")
+	recipient.KeywordGroups = []string{"fleet"}
+	assert.NoError(t, cfg.ValidateRecipient(recipient))
+
+	recipient.KeywordGroups = []string{"unknown"}
+	assert.ErrorContains(t, cfg.ValidateRecipient(recipient), "unknown keyword group")
+
+	recipient = NewEmailRecipient("alice@contoso.com")
+	recipient.Format = "pdf"
+	assert.Error(t, cfg.ValidateRecipient(recipient))
+
+	recipient = NewEmailRecipient("lead@contoso.com")
+	recipient.AttachPDF = true
+	assert.ErrorContains(t, cfg.ValidateRecipient(recipient), "ENABLE_PDF_REPORTS")
+	cfg.EnablePDFReports = true
+	assert.NoError(t, cfg.ValidateRecipient(recipient))
+}
@@ -0,0 +1,86 @@
+package config
+
+import "os"
+
+// Feature flags that gate expensive features. A disabled flag turns the feature off whatever
+// its settings say; without a flag the settings alone decide.
+const (
+	FeatureLLMEnrichment = "llm-enrichment" // LLM question and spam detection, and translation
+	FeatureTwitterStream = "twitter-stream" // X filtered stream consumption
+)
+
+// RemoteSettings are settings and feature flags loaded from Azure App Configuration. Values
+// are keyed by the environment variable they override, e.g. KEYWORDS, with Key Vault
+// references already resolved.
+type RemoteSettings struct {
+	Values       map[string]string
+	FeatureFlags map[string]bool
+	Secrets      []string // Keys of the values read from Key Vault
+}
+
+// remote holds the settings of the last App Configuration load; guarded by envMu
+var remote *RemoteSettings
+
+// appConfigSettings locate App Configuration itself, so they are only read from the environment
+var appConfigSettings = map[string]bool{
+	"APP_CONFIG_ENDPOINT":         true,
+	"APP_CONFIG_KEY_PREFIX":       true,
+	"APP_CONFIG_LABEL":            true,
+	"APP_CONFIG_REFRESH_INTERVAL": true,
+}
+
+// SetRemoteSettings makes later parses read the settings loaded from App Configuration in
+// preference to the environment. nil goes back to the environment alone.
+func SetRemoteSettings(settings *RemoteSettings) {
+	envMu.Lock()
+	defer envMu.Unlock()
+	remote = settings
+}
+
+// CurrentRemoteSettings returns the settings parses read from App Configuration, or nil
+func CurrentRemoteSettings() *RemoteSettings {
+	envMu.Lock()
+	defer envMu.Unlock()
+	return remote
+}
+
+// lookupEnv reads a setting from App Configuration, falling back to the environment; callers
+// must hold envMu
+func lookupEnv(key string) string {
+	if remote != nil && !appConfigSettings[key] {
+		if value, ok := remote.Values[key]; ok {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
+
+// FeatureEnabled reports whether a feature flag allows a feature; features without a flag
+// are allowed
+func (c *Config) FeatureEnabled(name string) bool {
+	enabled, ok := c.FeatureFlags[name]
+	return !ok || enabled
+}
+
+// applyFeatureFlags records the App Configuration feature flags and turns off the features
+// they disable; callers must hold envMu
+func (c *Config) applyFeatureFlags() {
+	if remote == nil || len(remote.FeatureFlags) == 0 {
+		return
+	}
+
+	c.FeatureFlags = make(map[string]bool, len(remote.FeatureFlags))
+	for name, enabled := range remote.FeatureFlags {
+		c.FeatureFlags[name] = enabled
+	}
+
+	if !c.FeatureEnabled(FeatureLLMEnrichment) {
+		c.EnableLLMQuestionDetection = false
+		c.EnableLLMSpamDetection = false
+		c.EnableTranslation = false
+		c.EnableLLMUrgentVerification = false
+	}
+	if !c.FeatureEnabled(FeatureTwitterStream) {
+		c.TwitterStreamEnabled = false
+	}
+}
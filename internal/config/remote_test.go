@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetRemoteSettings(t *testing.T) {
+	t.Setenv("KEYWORDS", "AKS")
+	t.Setenv("REPORT_SCHEDULE", "weekly")
+	t.Setenv("APP_CONFIG_ENDPOINT", "https://aks-mentions.azconfig.io/")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "https://contoso.openai.azure.com")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "gpt-4o-mini")
+	t.Setenv("ENABLE_LLM_SPAM_DETECTION", "true")
+	t.Setenv("TWITTER_STREAM_ENABLED", "true")
+	t.Cleanup(func() { SetRemoteSettings(nil) })
+
+	SetRemoteSettings(&RemoteSettings{
+		Values: map[string]string{
+			"KEYWORDS":               "AKS,KubeFleet",
+			"PROFILE_KAITO_KEYWORDS": "KAITO",
+			"APP_CONFIG_ENDPOINT":    "https://elsewhere.azconfig.io",
+		},
+		FeatureFlags: map[string]bool{FeatureLLMEnrichment: false, FeatureTwitterStream: true},
+	})
+
+	cfg, err := Parse()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AKS", "KubeFleet"}, cfg.Keywords, "App Configuration overrides the environment")
+	assert.Equal(t, "weekly", cfg.ReportSchedule, "settings missing from App Configuration come from the environment")
+	assert.Equal(t, "https://aks-mentions.azconfig.io", cfg.AppConfigEndpoint, "App Configuration can't move itself")
+	assert.False(t, cfg.EnableLLMSpamDetection, "a disabled flag turns the feature off")
+	assert.True(t, cfg.TwitterStreamEnabled)
+	assert.False(t, cfg.FeatureEnabled(FeatureLLMEnrichment))
+	assert.True(t, cfg.FeatureEnabled("unknown"))
+
+	kaito, err := ParseProfile("kaito")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"KAITO"}, kaito.Keywords)
+
+	SetRemoteSettings(nil)
+	cfg, err = Parse()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AKS"}, cfg.Keywords)
+	assert.True(t, cfg.EnableLLMSpamDetection)
+	assert.Nil(t, cfg.FeatureFlags)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleParser matches the six-field (with seconds) cron format the scheduler uses, and
+// descriptors such as @daily or @every 2h
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseSchedule parses a six-field cron expression (with seconds) or a descriptor, with errors
+// that point out the usual mistakes: five-field crontab expressions and dates that never occur
+func ParseSchedule(schedule string) (cron.Schedule, error) {
+	parsed, err := scheduleParser.Parse(schedule)
+	if err != nil {
+		if fields := strings.Fields(schedule); len(fields) == 5 && !strings.HasPrefix(schedule, "@") {
+			return nil, fmt.Errorf("%q has 5 fields, but schedules start with a seconds field, e.g. %q", schedule, "0 "+strings.Join(fields, " "))
+		}
+		return nil, fmt.Errorf("%q is neither a cron expression (second minute hour day-of-month month day-of-week) nor a descriptor such as @daily or @every 2h: %v", schedule, err)
+	}
+	if parsed.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%q never fires", schedule)
+	}
+	return parsed, nil
+}
+
+// parseSourceSchedules parses SOURCE_SCHEDULES, e.g. "twitter=@every 2h;medium=@daily".
+// Entries are separated by semicolons since cron expressions can contain commas.
+func parseSourceSchedules(value string) (map[string]string, error) {
+	schedules := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		source, schedule, found := strings.Cut(entry, "=")
+		source = strings.ToLower(strings.TrimSpace(source))
+		schedule = strings.TrimSpace(schedule)
+		if !found || source == "" || schedule == "" {
+			return nil, fmt.Errorf("invalid source schedule %q, expected source=schedule", entry)
+		}
+		if _, err := ParseSchedule(schedule); err != nil {
+			return nil, fmt.Errorf("invalid schedule for %s: %w", source, err)
+		}
+		if _, ok := schedules[source]; ok {
+			return nil, fmt.Errorf("source %s is scheduled more than once", source)
+		}
+		schedules[source] = schedule
+	}
+	return schedules, nil
+}
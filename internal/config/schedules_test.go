@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourceSchedules(t *testing.T) {
+	schedules, err := parseSourceSchedules("")
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+
+	schedules, err = parseSourceSchedules("Twitter=@every 2h; hackernews = 0 0 9,15,21 * * * ;medium=@daily;")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"twitter":    "@every 2h",
+		"hackernews": "0 0 9,15,21 * * *",
+		"medium":     "@daily",
+	}, schedules)
+
+	_, err = parseSourceSchedules("twitter")
+	assert.ErrorContains(t, err, "expected source=schedule")
+
+	_, err = parseSourceSchedules("twitter=every two hours")
+	assert.ErrorContains(t, err, "invalid schedule for twitter")
+
+	_, err = parseSourceSchedules("twitter=0 */2 * * *")
+	assert.ErrorContains(t, err, `invalid schedule for twitter: "0 */2 * * *" has 5 fields, but schedules start with a seconds field, e.g. "0 0 */2 * * *"`)
+
+	_, err = parseSourceSchedules("twitter=@hourly;Twitter=@daily")
+	assert.ErrorContains(t, err, "more than once")
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, schedule := range []string{"0 0 9 * * MON", "@daily", "@every 90m", "CRON_TZ=Europe/Berlin 0 30 8 * * *"} {
+		_, err := ParseSchedule(schedule)
+		assert.NoError(t, err, schedule)
+	}
+
+	_, err := ParseSchedule("0 9 * * MON")
+	assert.ErrorContains(t, err, `e.g. "0 0 9 * * MON"`)
+	_, err = ParseSchedule("@fortnightly")
+	assert.ErrorContains(t, err, "neither a cron expression")
+	_, err = ParseSchedule("0 0 9 30 2 *")
+	assert.ErrorContains(t, err, "never fires")
+}
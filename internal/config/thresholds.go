@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AllKeywordsGroup is the alert threshold group covering every mention
+const AllKeywordsGroup = "all"
+
+// Alert threshold metrics
+const (
+	ThresholdMetricMentions = "mentions"
+	ThresholdMetricPositive = "positive"
+	ThresholdMetricNegative = "negative"
+	ThresholdMetricNeutral  = "neutral"
+)
+
+// AlertThreshold raises an alert when a keyword group's mentions cross a limit in a run
+type AlertThreshold struct {
+	Group   string  `json:"group"`   // Keyword group from KEYWORD_GROUPS, or "all"
+	Metric  string  `json:"metric"`  // "mentions", or a sentiment: "positive", "negative" or "neutral"
+	Limit   float64 `json:"limit"`   // Mentions per day, or a percentage when Percent is set
+	Percent bool    `json:"percent"` // Limit is a share of the group's mentions rather than a daily count
+}
+
+// String renders the threshold the way it is configured, e.g. "kaito:mentions>20"
+func (t AlertThreshold) String() string {
+	limit := strconv.FormatFloat(t.Limit, 'f', -1, 64)
+	if t.Percent {
+		limit += "%"
+	}
+	return fmt.Sprintf("%s:%s>%s", t.Group, t.Metric, limit)
+}
+
+// parseAlertThresholds parses ALERT_THRESHOLDS entries such as "kaito:mentions>20,core:negative>30%":
+// more than 20 KAITO mentions a day, or negative mentions above 30% of the core group's mentions
+func parseAlertThresholds(value string) ([]AlertThreshold, error) {
+	var thresholds []AlertThreshold
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		group, condition, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid threshold %q, expected group:metric>limit", entry)
+		}
+		metric, limit, found := strings.Cut(condition, ">")
+		if !found {
+			return nil, fmt.Errorf("invalid threshold %q, expected group:metric>limit", entry)
+		}
+
+		threshold := AlertThreshold{
+			Group:  strings.TrimSpace(group),
+			Metric: strings.ToLower(strings.TrimSpace(metric)),
+		}
+		limit = strings.TrimSpace(limit)
+		if strings.HasSuffix(limit, "%") {
+			threshold.Percent = true
+			limit = strings.TrimSuffix(limit, "%")
+		}
+
+		var err error
+		if threshold.Limit, err = strconv.ParseFloat(limit, 64); err != nil || threshold.Limit < 0 {
+			return nil, fmt.Errorf("invalid limit in threshold %q", entry)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+// validateAlertThreshold checks a threshold against the configured keyword groups
+func (c *Config) validateAlertThreshold(threshold AlertThreshold) error {
+	if threshold.Group != AllKeywordsGroup {
+		if _, ok := c.KeywordGroups[threshold.Group]; !ok {
+			return fmt.Errorf("unknown keyword group %q in alert threshold %s", threshold.Group, threshold)
+		}
+	}
+
+	switch threshold.Metric {
+	case ThresholdMetricMentions, ThresholdMetricPositive, ThresholdMetricNegative, ThresholdMetricNeutral:
+	default:
+		return fmt.Errorf("unknown metric %q in alert threshold %s", threshold.Metric, threshold)
+	}
+
+	if threshold.Percent && threshold.Metric == ThresholdMetricMentions {
+		return fmt.Errorf("alert threshold %s: mentions are limited per day, not as a percentage", threshold)
+	}
+	if threshold.Percent && threshold.Limit > 100 {
+		return fmt.Errorf("alert threshold %s: percentage cannot exceed 100", threshold)
+	}
+	return nil
+}
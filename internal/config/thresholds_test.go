@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlertThresholds(t *testing.T) {
+	thresholds, err := parseAlertThresholds("kaito:mentions>20, core:Negative>30%,")
+	require.NoError(t, err)
+	assert.Equal(t, []AlertThreshold{
+		{Group: "kaito", Metric: ThresholdMetricMentions, Limit: 20},
+		{Group: "core", Metric: ThresholdMetricNegative, Limit: 30, Percent: true},
+	}, thresholds)
+	assert.Equal(t, "core:negative>30%", thresholds[1].String())
+
+	_, err = parseAlertThresholds("kaito>20")
+	assert.ErrorContains(t, err, "expected group:metric>limit")
+
+	_, err = parseAlertThresholds("kaito:mentions>lots")
+	assert.ErrorContains(t, err, "invalid limit")
+}
+
+func TestConfig_validateAlertThreshold(t *testing.T) {
+	cfg := &Config{KeywordGroups: map[string][]string{"kaito": {"KAITO"}}}
+
+	assert.NoError(t, cfg.validateAlertThreshold(AlertThreshold{Group: "kaito", Metric: ThresholdMetricMentions, Limit: 20}))
+	assert.NoError(t, cfg.validateAlertThreshold(AlertThreshold{Group: AllKeywordsGroup, Metric: ThresholdMetricNegative, Limit: 30, Percent: true}))
+
+	assert.ErrorContains(t, cfg.validateAlertThreshold(AlertThreshold{Group: "fleet", Metric: ThresholdMetricMentions}), "unknown keyword group")
+	assert.ErrorContains(t, cfg.validateAlertThreshold(AlertThreshold{Group: "kaito", Metric: "angry"}), "unknown metric")
+	assert.ErrorContains(t, cfg.validateAlertThreshold(AlertThreshold{Group: "kaito", Metric: ThresholdMetricMentions, Limit: 20, Percent: true}), "not as a percentage")
+	assert.ErrorContains(t, cfg.validateAlertThreshold(AlertThreshold{Group: "kaito", Metric: ThresholdMetricNegative, Limit: 120, Percent: true}), "cannot exceed 100")
+}
@@ -0,0 +1,86 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// DefaultSearchURL is the Microsoft Learn search API
+	DefaultSearchURL = "https://learn.microsoft.com/api/search"
+	// maxResults is how many search results are weighed per question
+	maxResults = 5
+	// cacheSize bounds the search results kept, so questions asked again aren't searched again
+	cacheSize = 512
+)
+
+// Result is a documentation page found by a search
+type Result struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+type searchResponse struct {
+	Results []Result `json:"results"`
+}
+
+// Searcher searches the Azure documentation on Microsoft Learn
+type Searcher struct {
+	client *resty.Client
+	url    string
+	cache  *cache.LRU[string, []Result]
+}
+
+// NewSearcher creates a searcher for the given search API URL (DefaultSearchURL if empty)
+func NewSearcher(url string) *Searcher {
+	if url == "" {
+		url = DefaultSearchURL
+	}
+
+	return &Searcher{
+		client: resty.New().SetTimeout(15 * time.Second),
+		url:    url,
+		cache:  cache.NewLRU[string, []Result](cacheSize),
+	}
+}
+
+// Search returns the Azure documentation pages best matching the query, using cached results
+// for queries searched before
+func (s *Searcher) Search(ctx context.Context, query string) ([]Result, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if results, ok := s.cache.Get(strings.ToLower(query)); ok {
+		return results, nil
+	}
+
+	var payload searchResponse
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"search":  query,
+			"locale":  "en-us",
+			"$top":    fmt.Sprint(maxResults),
+			"$filter": "scopes/any(t: t eq 'Azure')",
+		}).
+		SetResult(&payload).
+		Get(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search Azure docs: %w", err)
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("docs search API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	results := payload.Results
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	s.cache.Add(strings.ToLower(query), results)
+	return results, nil
+}
@@ -0,0 +1,47 @@
+package docs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearcher_Search(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "AKS node pool upgrade", r.URL.Query().Get("search"))
+		assert.Equal(t, "en-us", r.URL.Query().Get("locale"))
+		assert.Equal(t, "5", r.URL.Query().Get("$top"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": [
+			{"title": "Upgrade a node pool", "url": "https://learn.microsoft.com/azure/aks/upgrade-node-pool", "description": "Upgrade node pools in AKS."}
+		], "count": 1}`))
+	}))
+	defer server.Close()
+
+	searcher := NewSearcher(server.URL)
+	results, err := searcher.Search(context.Background(), " AKS  node pool upgrade ")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Upgrade a node pool", results[0].Title)
+	assert.Equal(t, "https://learn.microsoft.com/azure/aks/upgrade-node-pool", results[0].URL)
+
+	_, err = searcher.Search(context.Background(), "aks node pool upgrade")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests, "repeat queries are served from the cache")
+}
+
+func TestSearcher_SearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "throttled", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewSearcher(server.URL).Search(context.Background(), "AKS")
+	assert.ErrorContains(t, err, "status 429")
+}
@@ -0,0 +1,234 @@
+// Package httpcache provides an HTTP transport that caches GET responses in memory and,
+// optionally, in blob storage, revalidating stale responses with ETag and Last-Modified.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// blobPrefix is the blob prefix of persisted responses, partitioned by the day they were
+// stored (httpcache/2006-01-02/<hash>.json) so old days can be deleted without reading them
+const blobPrefix = "httpcache/"
+
+// entry is a cached response
+type entry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// response rebuilds the cached response for a request
+func (e *entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// Transport is a read-through cache for GET requests. Responses younger than the TTL are
+// served without a request; older ones are revalidated with If-None-Match or
+// If-Modified-Since, and a 304 Not Modified serves the cached body again. Only 200
+// responses are cached, keyed by URL. It is safe for concurrent use.
+type Transport struct {
+	next   http.RoundTripper
+	ttl    time.Duration
+	memory *cache.LRU[string, *entry]
+	store  storage.StorageInterface
+	now    func() time.Time
+
+	mu        sync.Mutex
+	prunedDay string // Day the persisted responses of earlier days were last deleted
+}
+
+// NewTransport caches the responses of next for ttl in memory, keeping up to size responses
+func NewTransport(next http.RoundTripper, ttl time.Duration, size int) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		next:   next,
+		ttl:    ttl,
+		memory: cache.NewLRU[string, *entry](size),
+		now:    time.Now,
+	}
+}
+
+// WithStore also persists responses in blob storage, so runs in other processes, such as
+// CronJob runs, reuse them. Responses are kept until the end of the day after they were stored.
+func (t *Transport) WithStore(store storage.StorageInterface) *Transport {
+	t.store = store
+	return t
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached := t.lookup(key)
+	if cached != nil && t.now().Sub(cached.StoredAt) < t.ttl {
+		return cached.response(req), nil
+	}
+
+	outgoing := req
+	if cached != nil {
+		etag, lastModified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			// RoundTrippers must not modify the caller's request
+			outgoing = req.Clone(req.Context())
+			if etag != "" {
+				outgoing.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				outgoing.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	resp, err := t.next.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		refreshed := *cached
+		refreshed.StoredAt = t.now()
+		t.save(key, &refreshed)
+		return refreshed.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	t.save(key, &entry{StatusCode: resp.StatusCode, Header: header, Body: body, StoredAt: t.now()})
+	return resp, nil
+}
+
+// cacheKey hashes the request URL, which may carry API keys, so it is never persisted as is
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// blobName returns the blob a response stored on day is persisted in
+func blobName(key string, day time.Time) string {
+	return fmt.Sprintf("%s%s/%s.json", blobPrefix, day.UTC().Format("2006-01-02"), key)
+}
+
+// lookup returns the cached response for key from memory, or from blob storage for
+// responses persisted today or yesterday
+func (t *Transport) lookup(key string) *entry {
+	if cached, ok := t.memory.Get(key); ok {
+		return cached
+	}
+	if t.store == nil {
+		return nil
+	}
+
+	today := t.now()
+	for _, day := range []time.Time{today, today.AddDate(0, 0, -1)} {
+		// A missing blob is an error like any other, and either way the response isn't cached
+		data, err := t.store.Retrieve(blobName(key, day))
+		if err != nil {
+			continue
+		}
+		var cached entry
+		if err := json.Unmarshal(data, &cached); err != nil {
+			logrus.Debugf("Ignoring unreadable cached response %s: %v", key, err)
+			continue
+		}
+		t.memory.Add(key, &cached)
+		return &cached
+	}
+	return nil
+}
+
+// save caches a response in memory and, with a store, persists it. Persisting failures are
+// logged, since the response itself was fetched fine.
+func (t *Transport) save(key string, cached *entry) {
+	t.memory.Add(key, cached)
+	if t.store == nil {
+		return
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		logrus.Warnf("Failed to marshal cached response: %v", err)
+		return
+	}
+	if err := t.store.Store(blobName(key, cached.StoredAt), data); err != nil {
+		logrus.Warnf("Failed to persist cached response: %v", err)
+		return
+	}
+	t.prune(cached.StoredAt)
+}
+
+// prune deletes the responses persisted before yesterday, once a day
+func (t *Transport) prune(now time.Time) {
+	today := now.UTC().Format("2006-01-02")
+
+	t.mu.Lock()
+	if t.prunedDay == today {
+		t.mu.Unlock()
+		return
+	}
+	t.prunedDay = today
+	t.mu.Unlock()
+
+	names, err := t.store.List(blobPrefix)
+	if err != nil {
+		logrus.Warnf("Failed to list cached responses: %v", err)
+		return
+	}
+
+	cutoff := now.UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	deleted := 0
+	for _, name := range names {
+		day, _, ok := strings.Cut(strings.TrimPrefix(name, blobPrefix), "/")
+		if !ok || day >= cutoff {
+			continue
+		}
+		if err := t.store.Delete(name); err != nil {
+			logrus.Warnf("Failed to delete cached response %s: %v", name, err)
+			continue
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		logrus.Debugf("Deleted %d cached responses from before %s", deleted, cutoff)
+	}
+}
@@ -0,0 +1,135 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedServer serves a feed with an ETag, counting full and conditional requests
+type feedServer struct {
+	*httptest.Server
+	requests    atomic.Int32
+	notModified atomic.Int32
+}
+
+func newFeedServer(t *testing.T) *feedServer {
+	server := &feedServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+			return
+		case "/private":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			server.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Set-Cookie", "session=secret")
+		io.WriteString(w, "<rss>feed</rss>")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestTransport(t *testing.T) {
+	server := newFeedServer(t)
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	transport := NewTransport(nil, 10*time.Minute, 10)
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+
+	status, body := get(t, client, server.URL+"/feed")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "<rss>feed</rss>", body)
+
+	// Fresh responses are served without a request
+	_, body = get(t, client, server.URL+"/feed")
+	assert.Equal(t, "<rss>feed</rss>", body)
+	assert.EqualValues(t, 1, server.requests.Load())
+
+	// Stale ones are revalidated, and a 304 serves the cached body
+	now = now.Add(11 * time.Minute)
+	status, body = get(t, client, server.URL+"/feed")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "<rss>feed</rss>", body)
+	assert.EqualValues(t, 2, server.requests.Load())
+	assert.EqualValues(t, 1, server.notModified.Load())
+
+	// Revalidation restarts the TTL
+	get(t, client, server.URL+"/feed")
+	assert.EqualValues(t, 2, server.requests.Load())
+
+	// Errors, no-store responses and other methods are not cached
+	for i := 0; i < 2; i++ {
+		status, _ = get(t, client, server.URL+"/missing")
+		assert.Equal(t, http.StatusNotFound, status)
+		get(t, client, server.URL+"/private")
+		resp, err := client.Post(server.URL+"/feed", "text/plain", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.EqualValues(t, 8, server.requests.Load())
+}
+
+func TestTransport_WithStore(t *testing.T) {
+	server := newFeedServer(t)
+	store := testutil.NewMemoryStorage()
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	first := NewTransport(nil, time.Hour, 10).WithStore(store)
+	first.now = func() time.Time { return now }
+	get(t, &http.Client{Transport: first}, server.URL+"/feed")
+
+	names, err := store.List(blobPrefix)
+	require.NoError(t, err)
+	require.Len(t, names, 1)
+	assert.Contains(t, names[0], "httpcache/2024-06-03/")
+	data, err := store.Retrieve(names[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), server.URL, "URLs may carry API keys")
+	assert.NotContains(t, string(data), "session=secret")
+
+	// Another process, e.g. a CronJob run, reuses the persisted response without a memory cache
+	now = now.Add(30 * time.Minute)
+	second := NewTransport(nil, time.Hour, 0).WithStore(store)
+	second.now = func() time.Time { return now }
+	_, body := get(t, &http.Client{Transport: second}, server.URL+"/feed")
+	assert.Equal(t, "<rss>feed</rss>", body)
+	assert.EqualValues(t, 1, server.requests.Load())
+
+	// A day later the stale response is revalidated from yesterday's blob, and responses
+	// from before yesterday are deleted
+	require.NoError(t, store.Store("httpcache/2024-05-31/old.json", []byte("{}")))
+	now = now.AddDate(0, 0, 1)
+	get(t, &http.Client{Transport: second}, server.URL+"/feed")
+	assert.EqualValues(t, 2, server.requests.Load())
+	assert.EqualValues(t, 1, server.notModified.Load())
+
+	names, err = store.List(blobPrefix)
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	assert.Contains(t, names[0], "httpcache/2024-06-03/")
+	assert.Contains(t, names[1], "httpcache/2024-06-04/")
+}
@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/azure/aks-mentions-bot/internal/usage"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	cognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+	defaultAPIVersion      = "2024-06-01"
+)
+
+// Client calls an Azure OpenAI chat completions deployment. An API key is used when
+// provided, otherwise the default Azure credential chain (workload identity, managed
+// identity, CLI) is used.
+type Client struct {
+	client     *resty.Client
+	endpoint   string
+	deployment string
+	apiVersion string
+	apiKey     string
+	tokens     *azauth.TokenSource // Set when no API key is provided
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// NewClient creates a client for the given Azure OpenAI endpoint and deployment
+func NewClient(endpoint, deployment, apiVersion, apiKey string) (*Client, error) {
+	if endpoint == "" || deployment == "" {
+		return nil, fmt.Errorf("Azure OpenAI endpoint and deployment are required")
+	}
+	if apiVersion == "" {
+		apiVersion = defaultAPIVersion
+	}
+
+	c := &Client{
+		client:     resty.New().SetTimeout(30 * time.Second),
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		deployment: deployment,
+		apiVersion: apiVersion,
+		apiKey:     apiKey,
+	}
+
+	if apiKey == "" {
+		credential, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure OpenAI credential: %w", err)
+		}
+		c.tokens = azauth.NewTokenSource(credential, cognitiveServicesScope)
+	}
+
+	return c, nil
+}
+
+// Complete sends a system and user prompt and returns the model's reply. The tokens used are
+// recorded on the run's usage meter, if ctx has one.
+func (c *Client) Complete(ctx context.Context, system, user string, maxTokens int) (string, error) {
+	req := c.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetQueryParam("api-version", c.apiVersion).
+		SetBody(chatRequest{
+			Messages: []chatMessage{
+				{Role: "system", Content: system},
+				{Role: "user", Content: user},
+			},
+			MaxTokens: maxTokens,
+		})
+
+	if c.apiKey != "" {
+		req.SetHeader("api-key", c.apiKey)
+	} else {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		req.SetAuthToken(token)
+	}
+
+	var result chatResponse
+	resp, err := req.SetResult(&result).
+		Post(fmt.Sprintf("%s/openai/deployments/%s/chat/completions", c.endpoint, c.deployment))
+	if err != nil {
+		return "", fmt.Errorf("failed to call Azure OpenAI: %w", err)
+	}
+
+	if resp.StatusCode() != 200 {
+		return "", fmt.Errorf("Azure OpenAI returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	usage.FromContext(ctx).AddOpenAI(result.Usage.PromptTokens, result.Usage.CompletionTokens)
+
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("Azure OpenAI returned no choices")
+	}
+
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
+
+// YesNo asks a yes/no question and parses the answer
+func (c *Client) YesNo(ctx context.Context, system, user string) (bool, error) {
+	answer, err := c.Complete(ctx, system+" Answer with only \"yes\" or \"no\".", user, 3)
+	if err != nil {
+		return false, err
+	}
+
+	switch normalized := strings.ToLower(strings.Trim(answer, " .!\"'\n")); normalized {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected answer %q", answer)
+	}
+}
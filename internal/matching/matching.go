@@ -0,0 +1,205 @@
+// Package matching decides whether text mentions a keyword: as whole words, ignoring case,
+// plurals and possessives, or through a regular expression or proximity pattern.
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidPattern is returned for a pattern that cannot be compiled
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// Text is content prepared for matching, split into words once however many keywords and
+// patterns are checked against it
+type Text struct {
+	raw    string
+	tokens []string
+}
+
+// NewText prepares text for matching
+func NewText(raw string) *Text {
+	return &Text{raw: raw, tokens: Tokens(raw)}
+}
+
+// ContainsPhrase reports whether the text contains the phrase as whole words
+func (t *Text) ContainsPhrase(phrase string) bool {
+	return indexPhrase(t.tokens, Tokens(phrase), 0) >= 0
+}
+
+// Tokens splits text into lowercase words and stems each one. Chinese, Japanese and Korean
+// characters are words of their own, as those languages don't put spaces around names, e.g.
+// "在AKS上部署" holds "aks".
+func Tokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+	var tokens []string
+	for _, word := range words {
+		for _, part := range splitCJK(word) {
+			if part = Stem(part); part != "" {
+				tokens = append(tokens, part)
+			}
+		}
+	}
+	return tokens
+}
+
+// splitCJK splits a word into its Chinese, Japanese and Korean characters and the runs of
+// other letters and digits between them
+func splitCJK(word string) []string {
+	if !strings.ContainsFunc(word, isCJK) {
+		return []string{word}
+	}
+	var parts []string
+	start := 0
+	for i, r := range word {
+		if !isCJK(r) {
+			continue
+		}
+		if i > start {
+			parts = append(parts, word[start:i])
+		}
+		parts = append(parts, string(r))
+		start = i + utf8.RuneLen(r)
+	}
+	if start < len(word) {
+		parts = append(parts, word[start:])
+	}
+	return parts
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Stem strips possessives and English plural endings, enough for names and phrases to
+// match their inflected forms. Words of three letters or fewer are left alone, so
+// acronyms such as "aks" and "k8s" keep their final s.
+func Stem(word string) string {
+	word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+	word = strings.Trim(word, "'’")
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case len(word) > 4 && (strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes") ||
+		strings.HasSuffix(word, "sses") || strings.HasSuffix(word, "xes")):
+		return strings.TrimSuffix(word, "es")
+	case len(word) > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+// indexPhrase returns the position of the first occurrence of phrase in tokens at or after
+// from, or -1
+func indexPhrase(tokens, phrase []string, from int) int {
+	if len(phrase) == 0 {
+		return -1
+	}
+	for i := from; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, word := range phrase {
+			if tokens[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// Pattern is a compiled keyword pattern
+type Pattern struct {
+	expr string
+
+	regexp *regexp.Regexp // /expression/
+	left   []string       // Phrase, or the left side of a proximity pattern
+	right  []string       // Right side of a proximity pattern
+	within int            // Most words allowed between the two sides of a proximity pattern
+}
+
+// nearOperator matches the proximity operator, e.g. "NEAR/5"
+var nearOperator = regexp.MustCompile(`(?:^|\s+)NEAR/(\d+)(?:\s+|$)`)
+
+// Compile parses a pattern:
+//
+//   - /expression/ is a regular expression in Go syntax, matched case-insensitively against
+//     the raw text, e.g. /\bkaito\b.{0,80}(kubernetes|operator)/
+//   - a NEAR/n b matches when the words or quoted phrases a and b appear, in either order,
+//     with at most n words between them, e.g. azure NEAR/5 kubernetes
+//   - anything else is a phrase matched as whole words
+func Compile(expr string) (*Pattern, error) {
+	expr = strings.TrimSpace(expr)
+	pattern := &Pattern{expr: expr}
+
+	if len(expr) >= 2 && strings.HasPrefix(expr, "/") && strings.HasSuffix(expr, "/") {
+		re, err := regexp.Compile("(?i)" + expr[1:len(expr)-1])
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", ErrInvalidPattern, expr, err)
+		}
+		pattern.regexp = re
+		return pattern, nil
+	}
+
+	if loc := nearOperator.FindStringSubmatchIndex(expr); loc != nil {
+		within, err := strconv.Atoi(expr[loc[2]:loc[3]])
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %v", ErrInvalidPattern, expr, err)
+		}
+		pattern.left = Tokens(strings.Trim(expr[:loc[0]], `"`))
+		pattern.right = Tokens(strings.Trim(expr[loc[1]:], `"`))
+		pattern.within = within
+		if len(pattern.left) == 0 || len(pattern.right) == 0 {
+			return nil, fmt.Errorf("%w %s: expected words on both sides of NEAR", ErrInvalidPattern, expr)
+		}
+		return pattern, nil
+	}
+
+	pattern.left = Tokens(strings.Trim(expr, `"`))
+	if len(pattern.left) == 0 {
+		return nil, fmt.Errorf("%w %q: no words to match", ErrInvalidPattern, expr)
+	}
+	return pattern, nil
+}
+
+// String returns the pattern as written
+func (p *Pattern) String() string {
+	return p.expr
+}
+
+// Match reports whether the text matches the pattern
+func (p *Pattern) Match(text *Text) bool {
+	switch {
+	case p.regexp != nil:
+		return p.regexp.MatchString(text.raw)
+	case p.right != nil:
+		return p.near(text.tokens, p.left, p.right) || p.near(text.tokens, p.right, p.left)
+	default:
+		return indexPhrase(text.tokens, p.left, 0) >= 0
+	}
+}
+
+// near reports whether first is followed by second with at most within words between them
+func (p *Pattern) near(tokens, first, second []string) bool {
+	for i := indexPhrase(tokens, first, 0); i >= 0; i = indexPhrase(tokens, first, i+1) {
+		start := i + len(first)
+		end := start + p.within + len(second)
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		if indexPhrase(tokens[:end], second, start) >= 0 {
+			return true
+		}
+	}
+	return false
+}
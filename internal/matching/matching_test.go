@@ -0,0 +1,69 @@
+package matching
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	for word, expected := range map[string]string{
+		"clusters":   "cluster",
+		"policies":   "policy",
+		"patches":    "patch",
+		"aks":        "aks",
+		"k8s":        "k8s",
+		"status":     "status",
+		"ingress":    "ingress",
+		"service's":  "service",
+		"kubernetes": "kubernete",
+	} {
+		assert.Equal(t, expected, Stem(word), word)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	assert.Equal(t, []string{"aks", "cluster", "upgrade"}, Tokens("AKS clusters: upgrade!"))
+	assert.Equal(t, []string{"在", "aks", "上", "部", "署"}, Tokens("在AKS上部署"))
+	assert.Equal(t, []string{"aks", "の", "ノ", "ー", "ド"}, Tokens("AKSのノード"))
+
+	text := NewText("Azure Kubernetes 服务的节点池升级")
+	assert.True(t, text.ContainsPhrase("Azure Kubernetes 服务"))
+	assert.True(t, NewText("在AKS上部署KAITO").ContainsPhrase("aks"))
+}
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		matches bool
+	}{
+		{`azure NEAR/5 kubernetes`, "Azure makes running Kubernetes easy", true},
+		{`azure NEAR/5 kubernetes`, "Kubernetes on Azure", true},
+		{`azure NEAR/2 kubernetes`, "Azure has a lot of services, and Kubernetes is one", false},
+		{`azure NEAR/0 kubernetes`, "azure kubernetes service", true},
+		{`"fleet manager" NEAR/3 clusters`, "Fleet Manager spreads workloads over member clusters", false},
+		{`"fleet manager" NEAR/4 clusters`, "Fleet Manager spreads workloads over member clusters", true},
+		{`/\bkaito\b.{0,60}(kubernetes|operator|inference)/`, "KAITO is the Kubernetes AI toolchain operator", true},
+		{`/\bkaito\b.{0,60}(kubernetes|operator|inference)/`, "Kaito Tanaka scored twice on Sunday", false},
+		{`/^AKS/`, "aks upgrade failed", true},
+		{`node pools`, "Scaling AKS node pool sizes", true},
+		{`node pools`, "pools of nodes", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.text, func(t *testing.T) {
+			pattern, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, pattern.Match(NewText(tt.text)))
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, expr := range []string{`/kaito(/`, `NEAR/3 kubernetes`, `azure NEAR/3 ""`, `  `, `!!`} {
+		_, err := Compile(expr)
+		assert.ErrorIs(t, err, ErrInvalidPattern, expr)
+	}
+}
@@ -0,0 +1,98 @@
+package matching
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeywordPattern builds a case-insensitive pattern matching any of the keywords as whole
+// words, preferring the longest keyword when several overlap. It returns nil without keywords.
+func KeywordPattern(keywords []string) *regexp.Regexp {
+	sorted := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			sorted = append(sorted, keyword)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil
+	}
+	sort.Slice(sorted, func(a, b int) bool { return len(sorted[a]) > len(sorted[b]) })
+
+	alternatives := make([]string, 0, len(sorted))
+	for _, keyword := range sorted {
+		alternative := regexp.QuoteMeta(keyword)
+		if first, _ := utf8.DecodeRuneInString(keyword); isWordRune(first) {
+			alternative = `\b` + alternative
+		}
+		if last, _ := utf8.DecodeLastRuneInString(keyword); isWordRune(last) {
+			alternative += `\b`
+		}
+		alternatives = append(alternatives, alternative)
+	}
+
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// KeywordSnippet returns up to maxLength characters of content cut at word boundaries,
+// positioned so the first keyword occurrence is near the start instead of always taking the
+// opening characters. Cuts are marked with "...".
+func KeywordSnippet(content string, keywords []string, maxLength int) string {
+	content = strings.TrimSpace(content)
+	runes := []rune(content)
+	if len(runes) <= maxLength {
+		return content
+	}
+
+	matchStart := 0
+	if pattern := KeywordPattern(keywords); pattern != nil {
+		if loc := pattern.FindStringIndex(content); loc != nil {
+			matchStart = utf8.RuneCountInString(content[:loc[0]])
+		}
+	}
+
+	// Keep a little leading context before the keyword
+	start := matchStart - maxLength/4
+	if start < 0 {
+		start = 0
+	}
+	end := start + maxLength
+	if end > len(runes) {
+		end = len(runes)
+		start = end - maxLength
+	}
+
+	// Avoid starting or ending mid-word
+	if start > 0 {
+		for i := start; i < matchStart; i++ {
+			if unicode.IsSpace(runes[i]) {
+				start = i + 1
+				break
+			}
+		}
+	}
+	if end < len(runes) {
+		for i := end; i > matchStart; i-- {
+			if unicode.IsSpace(runes[i]) {
+				end = i
+				break
+			}
+		}
+	}
+
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(runes) {
+		snippet += "..."
+	}
+	return snippet
+}
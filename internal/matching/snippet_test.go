@@ -0,0 +1,37 @@
+package matching
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywordSnippet(t *testing.T) {
+	keywords := []string{"AKS"}
+
+	assert.Equal(t, "Short post about AKS", KeywordSnippet("  Short post about AKS ", keywords, 50))
+
+	content := strings.Repeat("filler words here ", 20) + "we moved our cluster to AKS last week " + strings.Repeat("more text ", 20)
+	snippet := KeywordSnippet(content, keywords, 80)
+	assert.True(t, strings.HasPrefix(snippet, "..."))
+	assert.True(t, strings.HasSuffix(snippet, "..."))
+	assert.Contains(t, snippet, "AKS")
+	assert.LessOrEqual(t, len([]rune(snippet)), 86)
+
+	// Without a keyword match the snippet starts at the beginning
+	assert.True(t, strings.HasPrefix(KeywordSnippet(content, []string{"EKS"}, 80), "filler"))
+
+	// A keyword near the end keeps the window full length
+	tail := strings.Repeat("x ", 100) + "AKS"
+	assert.True(t, strings.HasSuffix(KeywordSnippet(tail, keywords, 40), "AKS"))
+}
+
+func TestKeywordPattern(t *testing.T) {
+	pattern := KeywordPattern([]string{"AKS", " Azure Kubernetes Service ", "C++"})
+	assert.Equal(t, "azure kubernetes service", pattern.FindString("We use azure kubernetes service daily"))
+	assert.False(t, pattern.MatchString("Taking a break from MAKS"), "keywords match whole words")
+	assert.True(t, pattern.MatchString("Written in C++."))
+
+	assert.Nil(t, KeywordPattern([]string{" "}))
+}
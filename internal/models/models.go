@@ -1,39 +1,337 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Mention represents a mention found across various platforms
 type Mention struct {
-	ID          string    `json:"id"`
-	Source      string    `json:"source"`       // "reddit", "stackoverflow", "hackernews", etc.
-	Platform    string    `json:"platform"`     // URL or platform identifier
-	Title       string    `json:"title"`
-	Content     string    `json:"content"`
-	Author      string    `json:"author"`
-	URL         string    `json:"url"`
-	CreatedAt   time.Time `json:"created_at"`
-	Sentiment   string    `json:"sentiment"`    // "positive", "negative", "neutral"
-	Score       int       `json:"score"`        // upvotes, likes, etc.
-	CommentCount int      `json:"comment_count"`
-	Keywords    []string  `json:"keywords"`     // Keywords that matched
-	Relevance   float64   `json:"relevance"`    // Relevance score (0-1)
+	ID           string     `json:"id"`
+	Source       string     `json:"source"`   // "reddit", "stackoverflow", "hackernews", etc.
+	Platform     string     `json:"platform"` // URL or platform identifier
+	Title        string     `json:"title"`
+	Content      string     `json:"content"`
+	Excerpt      string     `json:"excerpt,omitempty"` // Sentences around the first keyword match in long content
+	Author       string     `json:"author"`
+	Channel      string     `json:"channel,omitempty"` // Publishing channel ID where the platform has one, e.g. YouTube
+	URL          string     `json:"url"`
+	Permalink    string     `json:"permalink,omitempty"` // The bot's page for the mention, linked from notifications
+	ClickURL     string     `json:"click_url,omitempty"` // The bot's redirect to URL that records clicks, linked from notifications
+	MediaURL     string     `json:"media_url,omitempty"` // Audio or video file of the mention, e.g. a podcast episode
+	CreatedAt    time.Time  `json:"created_at"`
+	Sentiment    string     `json:"sentiment"` // "positive", "negative", "neutral"
+	Score        int        `json:"score"`     // upvotes, likes, etc.
+	CommentCount int        `json:"comment_count"`
+	Keywords     []string   `json:"keywords"`               // Keywords that matched
+	Relevance    float64    `json:"relevance"`              // Relevance score (0-1)
+	TopComments  []Comment  `json:"top_comments,omitempty"` // Notable keyword-matching comments
+	Advisories   []Advisory `json:"advisories,omitempty"`   // Security advisories the mention refers to
+	IsQuestion   bool       `json:"is_question,omitempty"`  // Mention asks a question rather than making a statement
+	PostType     string     `json:"post_type,omitempty"`    // Kind of post on its platform, e.g. PostTypeAskHN
+	Tags         []string   `json:"tags,omitempty"`         // Tags added by curators, e.g. "pricing"
+	AlertedAt    *time.Time `json:"alerted_at,omitempty"`   // When an urgent notification already sent the mention
+
+	Fingerprint    string   `json:"fingerprint,omitempty"`     // SimHash of the normalized title and content, for near-duplicate detection
+	DuplicateCount int      `json:"duplicate_count,omitempty"` // Times the content was posted, this mention included, when near-duplicates were collapsed into it
+	DuplicateIDs   []string `json:"duplicate_ids,omitempty"`   // IDs of the near-duplicates collapsed into the mention
+
+	Language        string `json:"language,omitempty"`         // Language of the original text when it was translated, e.g. "ja"
+	OriginalTitle   string `json:"original_title,omitempty"`   // Title before translation into English
+	OriginalContent string `json:"original_content,omitempty"` // Content before translation into English
+
+	Filter           *FilterDecision `json:"filter,omitempty"`            // Why the context filter kept the mention
+	Spam             *SpamVerdict    `json:"spam,omitempty"`              // Spam and bot signals found in the mention, if any
+	SentimentPhrases []string        `json:"sentiment_phrases,omitempty"` // Words that made the analyzer label the mention negative
+	Rank             *MentionRank    `json:"rank,omitempty"`              // Position in its report and how it was computed
+}
+
+// MentionRank records where the ranking formula placed a mention in its report and why
+type MentionRank struct {
+	Position int                `json:"position"` // 1 for the top mention
+	Score    float64            `json:"score"`    // Weighted sum of the factors (0-1)
+	Factors  map[string]float64 `json:"factors"`  // Each factor's value (0-1) before weighting, e.g. "recency"
+}
+
+// Filter confidence labels, from how sure the context filter is that a mention is about AKS
+const (
+	ConfidenceHigh   = "high"   // An unambiguous indicator such as "azure kubernetes service"
+	ConfidenceMedium = "medium" // Enough Azure and Kubernetes context to pass the threshold
+	ConfidenceLow    = "low"    // Below the threshold
+)
+
+// FilterDecision records why the context filter kept or dropped a mention
+type FilterDecision struct {
+	Kept              bool     `json:"kept"`
+	Relevance         float64  `json:"relevance"`
+	Threshold         float64  `json:"threshold"`
+	Confidence        string   `json:"confidence"`                   // ConfidenceHigh, ConfidenceMedium or ConfidenceLow
+	Indicators        []string `json:"indicators,omitempty"`         // Matched indicators, e.g. "strong:az aks" or "kubernetes:helm"
+	NegativeIndicator string   `json:"negative_indicator,omitempty"` // Negative keyword that rejected the mention outright
+	Reasons           []string `json:"reasons,omitempty"`            // Score adjustments, e.g. "youtube source trust -0.05"
+}
+
+// SpamVerdict records the spam and bot signals found in a mention and whether it was excluded for them
+type SpamVerdict struct {
+	Spam    bool     `json:"spam"`
+	Score   float64  `json:"score"`             // Heuristic score (0-1); mentions at 0.6 or above are spam
+	Signals []string `json:"signals,omitempty"` // e.g. "duplicate content from 3 authors" or "link farm: 7 links"
+	LLM     bool     `json:"llm,omitempty"`     // The LLM decided an ambiguous score
+}
+
+// RejectedMention is a mention the context filter or spam detection dropped, stored so false negatives can be audited
+type RejectedMention struct {
+	ID         string         `json:"id"`
+	Source     string         `json:"source"`
+	Title      string         `json:"title"`
+	URL        string         `json:"url"`
+	Snippet    string         `json:"snippet,omitempty"`
+	Keywords   []string       `json:"keywords,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	RejectedAt time.Time      `json:"rejected_at"`
+	Filter     FilterDecision `json:"filter"`
+	Spam       *SpamVerdict   `json:"spam,omitempty"`
+}
+
+// LabeledMention is a mention a maintainer flagged as relevant or not, kept in the filter
+// corpus so filter changes can be replayed against known answers
+type LabeledMention struct {
+	Mention   Mention   `json:"mention"`
+	Relevant  bool      `json:"relevant"`
+	Note      string    `json:"note,omitempty"`
+	Kept      bool      `json:"kept"` // Whether the filters kept the mention when it was labeled
+	LabeledAt time.Time `json:"labeled_at"`
+}
+
+// Hacker News post types, weighted differently for relevance and urgency
+const (
+	PostTypeAskHN   = "ask_hn"
+	PostTypeShowHN  = "show_hn"
+	PostTypeStory   = "story"
+	PostTypeComment = "comment"
+)
+
+// PostTypeShort is a YouTube Short
+const PostTypeShort = "short"
+
+// PostTypeLabel returns the display name of a post type, or "" if it has none
+func PostTypeLabel(postType string) string {
+	switch postType {
+	case PostTypeAskHN:
+		return "Ask HN"
+	case PostTypeShowHN:
+		return "Show HN"
+	case PostTypeStory:
+		return "Story"
+	case PostTypeComment:
+		return "Comment"
+	case PostTypeShort:
+		return "Short"
+	default:
+		return ""
+	}
+}
+
+// Advisory is an authoritative security advisory such as an NVD CVE entry
+type Advisory struct {
+	ID       string  `json:"id"` // e.g. "CVE-2024-12345"
+	URL      string  `json:"url"`
+	Severity string  `json:"severity,omitempty"` // "LOW", "MEDIUM", "HIGH", "CRITICAL"
+	Score    float64 `json:"score,omitempty"`    // CVSS base score
+	Summary  string  `json:"summary,omitempty"`
+}
+
+// Comment represents a comment from a mention's discussion thread
+type Comment struct {
+	ID        string    `json:"id"`
+	MentionID string    `json:"mention_id"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	Sentiment string    `json:"sentiment"`
 }
 
 // Report represents a periodic report of mentions
 type Report struct {
-	GeneratedAt   time.Time              `json:"generated_at"`
-	Period        string                 `json:"period"`        // "daily" or "weekly"
-	TotalMentions int                    `json:"total_mentions"`
-	Mentions      []Mention              `json:"mentions"`
-	Summary       map[string]interface{} `json:"summary"`
+	ID                string                 `json:"id,omitempty"` // Stored artifacts and snapshot name, assigned when they are stored
+	GeneratedAt       time.Time              `json:"generated_at"`
+	Period            string                 `json:"period"` // "daily" or "weekly"
+	TotalMentions     int                    `json:"total_mentions"`
+	Mentions          []Mention              `json:"mentions"`
+	Summary           map[string]interface{} `json:"summary"`
+	NegativeComments  []Comment              `json:"negative_comments,omitempty"`  // Notable negative comments surfaced separately
+	ReleaseInsights   []ReleaseInsight       `json:"release_insights,omitempty"`   // Mention activity correlated with AKS releases
+	Unanswered        []Mention              `json:"unanswered,omitempty"`         // Questions with no answers or comments yet, oldest first
+	Resolved          []ResolvedQuestion     `json:"resolved,omitempty"`           // Questions earlier reports listed as unanswered that have since been answered
+	SentimentTrend    *SentimentTrend        `json:"sentiment_trend,omitempty"`    // Rolling community sentiment score and its daily history
+	PreviouslyAlerted []Mention              `json:"previously_alerted,omitempty"` // Mentions urgent notifications already sent, listed apart from Mentions
+	ReportURL         string                 `json:"report_url,omitempty"`         // Standalone HTML report with charts, when published
+	CompareURL        string                 `json:"compare_url,omitempty"`        // Comparison with the previous report, when published
+	DocsGaps          *DocsGapSummary        `json:"docs_gaps,omitempty"`          // Last month's questions Azure docs don't answer, in the first report of a month
+	SkippedSources    []SkippedSource        `json:"skipped_sources,omitempty"`    // Sources whose circuit breaker is open, so runs don't search them
+	Reliability       *SourceReliability     `json:"source_reliability,omitempty"` // The last week's source fetch errors by class, in the first report of a week
+}
+
+// SourceReliability summarizes a week of source fetch errors against the error budget
+type SourceReliability struct {
+	Since   time.Time         `json:"since"`
+	Budget  float64           `json:"error_budget"` // Share of fetches a source may fail
+	Sources []SourceErrorRate `json:"sources"`      // Sources that failed at least once, highest failure rate first
+}
+
+// SourceErrorRate counts the failed fetches of a source by error class
+type SourceErrorRate struct {
+	Source     string         `json:"source"`
+	Fetches    int            `json:"fetches"`
+	Failures   int            `json:"failures"`
+	Classes    map[string]int `json:"error_classes"` // e.g. auth, rate_limit, timeout, parse, server
+	LastError  string         `json:"last_error"`
+	OverBudget bool           `json:"over_budget"`
+}
+
+// Summary describes the failures of a source, e.g. "5 of 12 fetches failed (42%, over budget): 3 auth, 2 skipped"
+func (r SourceErrorRate) Summary() string {
+	rate := fmt.Sprintf("%.0f%%", 100*float64(r.Failures)/float64(max(r.Fetches, 1)))
+	if r.OverBudget {
+		rate += ", over budget"
+	}
+
+	classes := make([]string, 0, len(r.Classes))
+	for class := range r.Classes {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if r.Classes[classes[i]] != r.Classes[classes[j]] {
+			return r.Classes[classes[i]] > r.Classes[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+	for i, class := range classes {
+		classes[i] = fmt.Sprintf("%d %s", r.Classes[class], class)
+	}
+
+	return fmt.Sprintf("%d of %d fetches failed (%s): %s", r.Failures, r.Fetches, rate, strings.Join(classes, ", "))
+}
+
+// SkippedSource is a source skipped after failing in several runs in a row, until its cooldown ends
+type SkippedSource struct {
+	Source    string    `json:"source"`
+	Failures  int       `json:"failures"` // Consecutive failed fetches
+	LastError string    `json:"last_error"`
+	Until     time.Time `json:"until"` // When the source is tried again
+}
+
+// ResolvedQuestion is a question an earlier report listed as unanswered that has since been answered
+type ResolvedQuestion struct {
+	Mention    Mention   `json:"mention"`
+	Answers    int       `json:"answers"`            // Answers or comments it has now
+	Accepted   bool      `json:"accepted,omitempty"` // An answer was accepted, where the platform has accepted answers
+	ReportedAt time.Time `json:"reported_at"`        // When a report first listed it as unanswered
+}
+
+// Resolution describes how a resolved question was answered, e.g. "accepted answer" or "3 answers"
+func (q ResolvedQuestion) Resolution() string {
+	switch {
+	case q.Accepted:
+		return "accepted answer"
+	case q.Answers == 1:
+		return "1 answer"
+	default:
+		return fmt.Sprintf("%d answers", q.Answers)
+	}
+}
+
+// SentimentScore is the community sentiment score for the trailing window ending on a day:
+// the percentage of positive mentions minus the percentage of negative ones, from -100 to 100
+type SentimentScore struct {
+	Date     string  `json:"date"` // Last day of the window, YYYY-MM-DD in UTC
+	Score    float64 `json:"score"`
+	Positive int     `json:"positive"`
+	Neutral  int     `json:"neutral"`
+	Negative int     `json:"negative"`
+}
+
+// SentimentTrend is the current community sentiment score with the daily scores before it
+type SentimentTrend struct {
+	Current    SentimentScore   `json:"current"`
+	WindowDays int              `json:"window_days"`
+	Change     *float64         `json:"change,omitempty"` // Points gained or lost since the score a week earlier, when one was recorded
+	History    []SentimentScore `json:"history"`          // Daily scores, oldest first, ending with the current one
+}
+
+// Summary describes the score for report headers, e.g. "+12.5 over 30 days, up 3.1 from a week ago"
+func (t *SentimentTrend) Summary() string {
+	summary := fmt.Sprintf("%+.1f over %d days", t.Current.Score, t.WindowDays)
+	if t.Change == nil {
+		return summary
+	}
+	switch change := *t.Change; {
+	case change > 0:
+		return fmt.Sprintf("%s, up %.1f from a week ago", summary, change)
+	case change < 0:
+		return fmt.Sprintf("%s, down %.1f from a week ago", summary, -change)
+	default:
+		return summary + ", unchanged from a week ago"
+	}
+}
+
+// DocsGapSummary groups a month's questions that no Azure documentation page covers well by topic
+type DocsGapSummary struct {
+	Month  string    `json:"month"` // e.g. "July 2024"
+	Topics []DocsGap `json:"topics"`
+}
+
+// DocsGap is a topic community questions kept asking about without a matching docs page
+type DocsGap struct {
+	Topic     string    `json:"topic"`
+	Count     int       `json:"count"`     // Questions on the topic in the month
+	Questions []Mention `json:"questions"` // Most recent examples
+}
+
+// ReleaseInsight relates mentions of a Kubernetes version to the AKS release that shipped it
+type ReleaseInsight struct {
+	Version          string    `json:"version"`
+	ReleaseName      string    `json:"release_name"`
+	ReleaseURL       string    `json:"release_url"`
+	ReleasedAt       time.Time `json:"released_at"`
+	MentionCount     int       `json:"mention_count"`
+	NegativeCount    int       `json:"negative_count"`
+	PeakDay          time.Time `json:"peak_day"`
+	PeakCount        int       `json:"peak_count"`
+	DaysAfterRelease int       `json:"days_after_release"`
+	Summary          string    `json:"summary"`
 }
 
 // Alert represents an urgent notification
 type Alert struct {
 	ID        string    `json:"id"`
-	Type      string    `json:"type"`      // "critical", "urgent", "info"
+	Type      string    `json:"type"` // "critical", "urgent", "info"
 	Title     string    `json:"title"`
 	Message   string    `json:"message"`
 	Mention   *Mention  `json:"mention,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+	// Signed links to mark the mention handled or escalate it, set when inbound actions are enabled
+	Actions []AlertAction `json:"actions,omitempty"`
+}
+
+// AlertAction is a button on an alert, e.g. "Mark handled"
+type AlertAction struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// KeywordQuery describes how sources search for a keyword, so each product keyword is
+// defined once instead of in every source's query builder
+type KeywordQuery struct {
+	Keyword string   `json:"keyword"`
+	Terms   []string `json:"terms,omitempty"`   // Other names searched with the keyword, e.g. "Azure Kubernetes Service" for "AKS"
+	Context []string `json:"context,omitempty"` // Words of which at least one must appear, where the platform supports it
+	Exclude []string `json:"exclude,omitempty"` // Words that rule a result out, e.g. "rifle"
+
+	// Regular expressions (/.../) or proximity expressions (a NEAR/5 b) of which content must
+	// match one, instead of containing the keyword or one of its terms
+	Patterns []string `json:"patterns,omitempty"`
 }
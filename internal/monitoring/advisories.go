@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+)
+
+// linkAdvisories cross-references community mentions with security advisories. Mentions
+// citing a CVE ID get the advisory attached (enriched with severity when the CVE source
+// fetched it), and advisories are moved to the front so alerts lead with the authoritative link.
+func linkAdvisories(mentions []models.Mention) {
+	known := make(map[string]models.Advisory)
+	for _, mention := range mentions {
+		for _, advisory := range mention.Advisories {
+			known[advisory.ID] = advisory
+		}
+	}
+
+	for i := range mentions {
+		if mentions[i].Source == "cve" {
+			continue
+		}
+
+		for _, id := range sources.CVEPattern.FindAllString(mentions[i].Title+" "+mentions[i].Content, -1) {
+			advisory, ok := known[strings.ToUpper(id)]
+			if !ok {
+				advisory = sources.NewAdvisory(id)
+			}
+			if !hasAdvisory(mentions[i].Advisories, advisory.ID) {
+				mentions[i].Advisories = append(mentions[i].Advisories, advisory)
+			}
+		}
+	}
+
+	sort.SliceStable(mentions, func(a, b int) bool {
+		return mentions[a].Source == "cve" && mentions[b].Source != "cve"
+	})
+}
+
+func hasAdvisory(advisories []models.Advisory, id string) bool {
+	for _, advisory := range advisories {
+		if advisory.ID == id {
+			return true
+		}
+	}
+	return false
+}
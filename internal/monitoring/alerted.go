@@ -0,0 +1,121 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// urgentAlertsBlob records when mentions were sent in urgent notifications, keyed by mention ID
+const urgentAlertsBlob = "alerts/urgent.json"
+
+// urgentAlertRetention keeps alert records long enough to cover a weekly report with catch-up
+const urgentAlertRetention = 30 * 24 * time.Hour
+
+// recordUrgentAlerts notes that the mentions were sent in an urgent notification, so periodic
+// reports can set them apart
+func (s *Service) recordUrgentAlerts(mentions []models.Mention, at time.Time) {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	alerted, err := s.loadUrgentAlerts()
+	if err != nil {
+		logrus.Warnf("Failed to load urgent alert records: %v", err)
+		return
+	}
+
+	for id, alertedAt := range alerted {
+		if at.Sub(alertedAt) > urgentAlertRetention {
+			delete(alerted, id)
+		}
+	}
+	for _, mention := range mentions {
+		if _, ok := alerted[mention.ID]; !ok {
+			alerted[mention.ID] = at
+		}
+	}
+
+	if err := s.saveUrgentAlerts(alerted); err != nil {
+		logrus.Warnf("Failed to save urgent alert records: %v", err)
+	}
+}
+
+// separateAlerted moves mentions already sent in urgent notifications out of a report's
+// mention list, into its previously alerted section or, when configured, out of the report.
+// Counts and statistics still include them.
+func (s *Service) separateAlerted(report *models.Report) {
+	s.alertsMu.Lock()
+	alerted, err := s.loadUrgentAlerts()
+	s.alertsMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load urgent alert records, reporting every mention as new: %v", err)
+		return
+	}
+	if len(alerted) == 0 {
+		return
+	}
+
+	fresh := make([]models.Mention, 0, len(report.Mentions))
+	var previously []models.Mention
+	for _, mention := range report.Mentions {
+		alertedAt, ok := alerted[mention.ID]
+		if !ok {
+			fresh = append(fresh, mention)
+			continue
+		}
+		mention.AlertedAt = &alertedAt
+		previously = append(previously, mention)
+	}
+	if len(previously) == 0 {
+		return
+	}
+
+	logrus.Infof("%d reported mentions were already sent as urgent alerts", len(previously))
+	report.Mentions = fresh
+	if s.config.ReportAlertedMentions == config.ReportAlertedSuppress {
+		report.Summary["previously_alerted"] = len(previously)
+		return
+	}
+	report.PreviouslyAlerted = previously
+}
+
+// loadUrgentAlerts reads the urgent alert records; callers must hold s.alertsMu
+func (s *Service) loadUrgentAlerts() (map[string]time.Time, error) {
+	alerted := make(map[string]time.Time)
+	if s.storage == nil {
+		return alerted, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, urgentAlertsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve urgent alert records: %w", err)
+	}
+	if !found {
+		return alerted, nil
+	}
+	if err := json.Unmarshal(data, &alerted); err != nil {
+		return nil, fmt.Errorf("failed to parse urgent alert records: %w", err)
+	}
+	return alerted, nil
+}
+
+// saveUrgentAlerts writes the urgent alert records; callers must hold s.alertsMu
+func (s *Service) saveUrgentAlerts(alerted map[string]time.Time) error {
+	if s.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(alerted)
+	if err != nil {
+		return fmt.Errorf("failed to marshal urgent alert records: %w", err)
+	}
+	if err := s.storage.Store(urgentAlertsBlob, data); err != nil {
+		return fmt.Errorf("failed to store urgent alert records: %w", err)
+	}
+	return nil
+}
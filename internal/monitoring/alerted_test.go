@@ -0,0 +1,83 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_separateAlerted(t *testing.T) {
+	alertedAt := time.Now().Add(-2 * time.Hour)
+	newReport := func() *models.Report {
+		return &models.Report{
+			TotalMentions: 3,
+			Mentions: []models.Mention{
+				{ID: "reddit_1", Source: "reddit", Title: "AKS outage"},
+				{ID: "reddit_2", Source: "reddit", Title: "AKS upgrade tips"},
+				{ID: "twitter_3", Source: "twitter", Title: "AKS down?"},
+			},
+			Summary: map[string]interface{}{},
+		}
+	}
+
+	t.Run("section", func(t *testing.T) {
+		service := NewService(&config.Config{ReportAlertedMentions: config.ReportAlertedSection},
+			testutil.NewMemoryStorage(), testutil.NewRecordingNotificationService())
+		service.recordUrgentAlerts([]models.Mention{{ID: "reddit_1"}, {ID: "twitter_3"}}, alertedAt)
+
+		report := newReport()
+		service.separateAlerted(report)
+
+		require.Len(t, report.Mentions, 1)
+		assert.Equal(t, "reddit_2", report.Mentions[0].ID)
+		require.Len(t, report.PreviouslyAlerted, 2)
+		assert.Equal(t, "reddit_1", report.PreviouslyAlerted[0].ID)
+		require.NotNil(t, report.PreviouslyAlerted[0].AlertedAt)
+		assert.True(t, report.PreviouslyAlerted[0].AlertedAt.Equal(alertedAt))
+		assert.Equal(t, 3, report.TotalMentions, "alerted mentions still count")
+	})
+
+	t.Run("suppress", func(t *testing.T) {
+		service := NewService(&config.Config{ReportAlertedMentions: config.ReportAlertedSuppress},
+			testutil.NewMemoryStorage(), testutil.NewRecordingNotificationService())
+		service.recordUrgentAlerts([]models.Mention{{ID: "twitter_3"}}, alertedAt)
+
+		report := newReport()
+		service.separateAlerted(report)
+
+		assert.Len(t, report.Mentions, 2)
+		assert.Empty(t, report.PreviouslyAlerted)
+		assert.Equal(t, 1, report.Summary["previously_alerted"])
+	})
+
+	t.Run("nothing alerted", func(t *testing.T) {
+		service := NewService(&config.Config{}, testutil.NewMemoryStorage(), testutil.NewRecordingNotificationService())
+
+		report := newReport()
+		service.separateAlerted(report)
+
+		assert.Len(t, report.Mentions, 3)
+		assert.Empty(t, report.PreviouslyAlerted)
+	})
+}
+
+func TestService_recordUrgentAlerts(t *testing.T) {
+	service := NewService(&config.Config{}, testutil.NewMemoryStorage(), testutil.NewRecordingNotificationService())
+	service.recordUrgentAlerts([]models.Mention{{ID: "reddit_1"}}, time.Now().Add(-40*24*time.Hour))
+	first := time.Now().Add(-7 * 24 * time.Hour)
+	service.recordUrgentAlerts([]models.Mention{{ID: "reddit_2"}}, first)
+
+	now := time.Now()
+	service.recordUrgentAlerts([]models.Mention{{ID: "reddit_2"}, {ID: "twitter_3"}}, now)
+
+	alerted, err := service.loadUrgentAlerts()
+	require.NoError(t, err)
+	assert.NotContains(t, alerted, "reddit_1", "records past the retention are pruned")
+	assert.True(t, alerted["reddit_2"].Equal(first), "the first alert time is kept")
+	assert.True(t, alerted["twitter_3"].Equal(now))
+}
@@ -0,0 +1,282 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// ErrInvalidPeriod is returned for an analytics period that can't be parsed, is empty or is
+// longer than MaxAnalyticsPeriod
+var ErrInvalidPeriod = errors.New("invalid analytics period")
+
+// MaxAnalyticsPeriod bounds an analytics period, since every mentions blob in it is read
+const MaxAnalyticsPeriod = 366 * 24 * time.Hour
+
+// maxAnalyticsTopics and maxAnalyticsAuthors bound the lists of an analytics report
+const (
+	maxAnalyticsTopics  = 30
+	maxAnalyticsAuthors = 20
+)
+
+var (
+	quarterPattern = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+	monthPattern   = regexp.MustCompile(`^\d{4}-\d{2}$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+)
+
+// AnalyticsPeriod is the period an analytics report covers, from From up to but excluding To
+type AnalyticsPeriod struct {
+	Name string    `json:"name"`
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// AnalyticsMonth counts the mentions created in one calendar month (UTC) of the period
+type AnalyticsMonth struct {
+	Start     time.Time      `json:"start"`
+	Mentions  int            `json:"mentions"`
+	Sentiment map[string]int `json:"sentiment"`
+	Sources   map[string]int `json:"sources"`
+}
+
+// AnalyticsBreakdown counts the mentions of one source or topic over the period. Monthly
+// lists its mentions per month, in the order of the report's months.
+type AnalyticsBreakdown struct {
+	Name           string         `json:"name"`
+	Kind           string         `json:"kind,omitempty"` // Topics only: "keyword", "tag" or "term"
+	Mentions       int            `json:"mentions"`
+	Share          float64        `json:"share"` // Percentage of all mentions in the period
+	Sentiment      map[string]int `json:"sentiment"`
+	SentimentScore *float64       `json:"sentiment_score,omitempty"`
+	Monthly        []int          `json:"monthly"`
+}
+
+// AnalyticsReport aggregates the stored mentions of a period for planning reviews. Unlike
+// scheduled reports it is built on request from storage and sends nothing.
+type AnalyticsReport struct {
+	Period         AnalyticsPeriod      `json:"period"`
+	GeneratedAt    time.Time            `json:"generated_at"`
+	TotalMentions  int                  `json:"total_mentions"`
+	Sentiment      map[string]int       `json:"sentiment"`
+	SentimentScore *float64             `json:"sentiment_score,omitempty"`
+	Months         []AnalyticsMonth     `json:"months"`
+	Sources        []AnalyticsBreakdown `json:"sources"`
+	Topics         []AnalyticsBreakdown `json:"topics"`      // Most mentioned keywords, tags and title terms
+	TopAuthors     []AuthorCount        `json:"top_authors"` // Authors with the most mentions
+}
+
+// ResolvePeriod parses a named analytics period: a quarter ("2024-Q3"), month ("2024-07"),
+// year ("2024"), "quarter" or "last-quarter" relative to now, or the cycle of an AKS release
+// ("release:2024-07-15" or "release:latest"), which runs until the next release.
+func (s *Service) ResolvePeriod(spec string, now time.Time) (AnalyticsPeriod, error) {
+	spec = strings.TrimSpace(spec)
+	now = now.UTC()
+
+	switch {
+	case quarterPattern.MatchString(spec):
+		parts := quarterPattern.FindStringSubmatch(spec)
+		year, _ := strconv.Atoi(parts[1])
+		quarter, _ := strconv.Atoi(parts[2])
+		from := time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, time.UTC)
+		return AnalyticsPeriod{Name: fmt.Sprintf("%d-Q%d", year, quarter), From: from, To: from.AddDate(0, 3, 0)}, nil
+	case monthPattern.MatchString(spec):
+		from, err := time.Parse("2006-01", spec)
+		if err != nil {
+			return AnalyticsPeriod{}, fmt.Errorf("%w %q: %v", ErrInvalidPeriod, spec, err)
+		}
+		return AnalyticsPeriod{Name: spec, From: from, To: from.AddDate(0, 1, 0)}, nil
+	case yearPattern.MatchString(spec):
+		year, _ := strconv.Atoi(spec)
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return AnalyticsPeriod{Name: spec, From: from, To: from.AddDate(1, 0, 0)}, nil
+	case spec == "quarter" || spec == "last-quarter":
+		from := time.Date(now.Year(), time.Month((int(now.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+		if spec == "last-quarter" {
+			from = from.AddDate(0, -3, 0)
+		}
+		quarter := (int(from.Month())-1)/3 + 1
+		return AnalyticsPeriod{Name: fmt.Sprintf("%d-Q%d", from.Year(), quarter), From: from, To: from.AddDate(0, 3, 0)}, nil
+	case strings.HasPrefix(spec, "release:"):
+		return s.releasePeriod(strings.TrimPrefix(spec, "release:"), now)
+	}
+	return AnalyticsPeriod{}, fmt.Errorf("%w %q: use a quarter such as 2024-Q3, a month such as 2024-07, a year, quarter, last-quarter or release:<name>", ErrInvalidPeriod, spec)
+}
+
+// releasePeriod is the cycle of the named AKS release: from its publication until the next
+// release, or until now for the latest
+func (s *Service) releasePeriod(name string, now time.Time) (AnalyticsPeriod, error) {
+	if s.releases == nil {
+		return AnalyticsPeriod{}, fmt.Errorf("%w: release periods need ENABLE_RELEASE_CORRELATION", ErrInvalidPeriod)
+	}
+	releases, err := s.releases.Releases()
+	if err != nil {
+		return AnalyticsPeriod{}, err
+	}
+
+	for i, release := range releases {
+		matches := strings.EqualFold(release.Name, name) ||
+			strings.EqualFold(strings.TrimPrefix(release.Name, "Release "), name) ||
+			(name == "latest" && i == len(releases)-1)
+		if !matches {
+			continue
+		}
+		to := now
+		if i+1 < len(releases) {
+			to = releases[i+1].PublishedAt.UTC()
+		}
+		return AnalyticsPeriod{Name: "release " + release.Name, From: release.PublishedAt.UTC(), To: to}, nil
+	}
+	return AnalyticsPeriod{}, fmt.Errorf("%w: no recent AKS release named %q", ErrInvalidPeriod, name)
+}
+
+// Analytics aggregates the stored mentions created in the period by month, source and topic.
+// Mentions are found through the mention index, so each is counted once however many runs
+// stored it.
+func (s *Service) Analytics(period AnalyticsPeriod) (*AnalyticsReport, error) {
+	if !period.To.After(period.From) {
+		return nil, fmt.Errorf("%w: %s is not after %s", ErrInvalidPeriod, period.To.Format(time.RFC3339), period.From.Format(time.RFC3339))
+	}
+	if period.To.Sub(period.From) > MaxAnalyticsPeriod {
+		return nil, fmt.Errorf("%w: periods are limited to %d days", ErrInvalidPeriod, int(MaxAnalyticsPeriod.Hours()/24))
+	}
+	if period.Name == "" {
+		period.Name = fmt.Sprintf("%s to %s", period.From.Format("2006-01-02"), period.To.Format("2006-01-02"))
+	}
+
+	mentions, err := s.mentionsBetween(period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTags(mentions)
+
+	report := &AnalyticsReport{
+		Period:        period,
+		GeneratedAt:   time.Now(),
+		TotalMentions: len(mentions),
+		Sentiment:     make(map[string]int),
+		Sources:       []AnalyticsBreakdown{},
+		Topics:        []AnalyticsBreakdown{},
+		TopAuthors:    []AuthorCount{},
+	}
+
+	months := make(map[time.Time]int)
+	for start := bucketStart(period.From, BucketMonth); start.Before(period.To); start = nextBucket(start, BucketMonth) {
+		months[start] = len(report.Months)
+		report.Months = append(report.Months, AnalyticsMonth{Start: start, Sentiment: make(map[string]int), Sources: make(map[string]int)})
+	}
+
+	sources := make(map[string]*AnalyticsBreakdown)
+	topics := make(map[string]*AnalyticsBreakdown)
+	authors := make(map[string]int)
+	breakdown := func(entries map[string]*AnalyticsBreakdown, key, name, kind string) *AnalyticsBreakdown {
+		if entries[key] == nil {
+			entries[key] = &AnalyticsBreakdown{Name: name, Kind: kind, Sentiment: make(map[string]int), Monthly: make([]int, len(report.Months))}
+		}
+		return entries[key]
+	}
+
+	keywordTokens := s.keywordTokens()
+	for _, mention := range mentions {
+		month := months[bucketStart(mention.CreatedAt, BucketMonth)]
+		counts := &report.Months[month]
+		counts.Mentions++
+		counts.Sources[mention.Source]++
+
+		touched := []*AnalyticsBreakdown{breakdown(sources, mention.Source, mention.Source, "")}
+		for _, topic := range mentionTopics(mention, keywordTokens) {
+			kind, name, _ := strings.Cut(topic, ":")
+			touched = append(touched, breakdown(topics, topic, name, kind))
+		}
+		for _, entry := range touched {
+			entry.Mentions++
+			entry.Monthly[month]++
+			if mention.Sentiment != "" {
+				entry.Sentiment[mention.Sentiment]++
+			}
+		}
+
+		if mention.Sentiment != "" {
+			report.Sentiment[mention.Sentiment]++
+			counts.Sentiment[mention.Sentiment]++
+		}
+		if author := strings.TrimSpace(mention.Author); author != "" {
+			authors[mention.Source+":"+author]++
+		}
+	}
+	report.SentimentScore = sentimentScoreOf(report.Sentiment)
+
+	report.Sources = rankBreakdowns(sources, len(mentions), 1, len(sources))
+	report.Topics = rankBreakdowns(topics, len(mentions), topicMinMentions, maxAnalyticsTopics)
+
+	for key, count := range authors {
+		source, author, _ := strings.Cut(key, ":")
+		report.TopAuthors = append(report.TopAuthors, AuthorCount{Source: source, Author: author, Mentions: count})
+	}
+	sortAuthors(report.TopAuthors)
+	if len(report.TopAuthors) > maxAnalyticsAuthors {
+		report.TopAuthors = report.TopAuthors[:maxAnalyticsAuthors]
+	}
+
+	return report, nil
+}
+
+// mentionsBetween reads the stored mentions created in [from, to) through the mention index
+func (s *Service) mentionsBetween(from, to time.Time) ([]models.Mention, error) {
+	if s.index == nil {
+		return nil, nil
+	}
+
+	// Days after today have no index files to read
+	end := to
+	if now := time.Now(); now.Before(end) {
+		end = now
+	}
+	entries, err := s.index.Range(from, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the mention index: %w", err)
+	}
+
+	wanted := make(map[string]map[string]bool)
+	for id, entry := range entries {
+		if entry.CreatedAt.Before(from) || !entry.CreatedAt.Before(to) {
+			continue
+		}
+		if wanted[entry.Blob] == nil {
+			wanted[entry.Blob] = make(map[string]bool)
+		}
+		wanted[entry.Blob][id] = true
+	}
+	return s.retrieveMentions(wanted)
+}
+
+// rankBreakdowns orders breakdowns by mentions, keeping at most limit with at least min
+// mentions, and fills in their shares and sentiment scores
+func rankBreakdowns(entries map[string]*AnalyticsBreakdown, total, min, limit int) []AnalyticsBreakdown {
+	ranked := []AnalyticsBreakdown{}
+	for _, entry := range entries {
+		if entry.Mentions < min {
+			continue
+		}
+		entry.Share = math.Round(float64(entry.Mentions)*1000/float64(total)) / 10
+		entry.SentimentScore = sentimentScoreOf(entry.Sentiment)
+		ranked = append(ranked, *entry)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Mentions != ranked[j].Mentions {
+			return ranked[i].Mentions > ranked[j].Mentions
+		}
+		return ranked[i].Kind+ranked[i].Name < ranked[j].Kind+ranked[j].Name
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Analytics(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{Keywords: []string{"AKS"}}, storage: store, index: storage.NewMentionIndex(store)}
+
+	july := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	august := time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, service.storeMentionBatch("run1", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Author: "alice", Title: "AKS upgrade failed", CreatedAt: july, Sentiment: "negative", Keywords: []string{"AKS"}},
+		{ID: "reddit_2", Source: "reddit", Author: "alice", Title: "AKS upgrade planning", CreatedAt: august, Sentiment: "neutral", Keywords: []string{"AKS"}},
+		{ID: "reddit_old", Source: "reddit", Title: "AKS in June", CreatedAt: time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), Keywords: []string{"AKS"}},
+	}))
+	require.NoError(t, service.storeMentionBatch("run1", "hackernews", []models.Mention{
+		{ID: "hackernews_1", Source: "hackernews", Author: "bob", Title: "KAITO on AKS", CreatedAt: august, Sentiment: "positive", Keywords: []string{"AKS", "KAITO"}},
+	}))
+	// Found again by a later run, counted once
+	require.NoError(t, service.storeMentionBatch("run2", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Author: "alice", Title: "AKS upgrade failed", CreatedAt: july, Sentiment: "negative", Keywords: []string{"AKS"}},
+	}))
+
+	period, err := service.ResolvePeriod("2024-q3", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, AnalyticsPeriod{Name: "2024-Q3", From: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)}, period)
+
+	report, err := service.Analytics(period)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.TotalMentions)
+	assert.Equal(t, 0.0, *report.SentimentScore)
+
+	require.Len(t, report.Months, 3)
+	assert.Equal(t, 1, report.Months[0].Mentions)
+	assert.Equal(t, map[string]int{"reddit": 1, "hackernews": 1}, report.Months[1].Sources)
+	assert.Zero(t, report.Months[2].Mentions)
+
+	require.Len(t, report.Sources, 2)
+	assert.Equal(t, "reddit", report.Sources[0].Name)
+	assert.Equal(t, 66.7, report.Sources[0].Share)
+	assert.Equal(t, []int{1, 1, 0}, report.Sources[0].Monthly)
+
+	// Topics need two mentions; KAITO has one
+	assert.Equal(t, []AnalyticsBreakdown{
+		{Name: "aks", Kind: "keyword", Mentions: 3, Share: 100, Sentiment: map[string]int{"negative": 1, "neutral": 1, "positive": 1}, SentimentScore: floatPtr(0), Monthly: []int{1, 2, 0}},
+		{Name: "upgrade", Kind: "term", Mentions: 2, Share: 66.7, Sentiment: map[string]int{"negative": 1, "neutral": 1}, SentimentScore: floatPtr(-50), Monthly: []int{1, 1, 0}},
+	}, report.Topics)
+	assert.Equal(t, AuthorCount{Source: "reddit", Author: "alice", Mentions: 2}, report.TopAuthors[0])
+}
+
+func TestService_ResolvePeriod(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+	now := time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC)
+
+	period, err := service.ResolvePeriod("last-quarter", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-Q3", period.Name)
+
+	period, err = service.ResolvePeriod("quarter", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), period.From)
+
+	period, err = service.ResolvePeriod("2024-02", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), period.To)
+
+	for _, spec := range []string{"2024-Q5", "2024-13", "fortnight", "release:latest"} {
+		_, err := service.ResolvePeriod(spec, now)
+		assert.ErrorIs(t, err, ErrInvalidPeriod, spec)
+	}
+
+	_, err = service.Analytics(AnalyticsPeriod{From: now, To: now.AddDate(2, 0, 0)})
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
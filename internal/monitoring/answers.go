@@ -0,0 +1,163 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// trackedQuestionsBlob holds the questions reports listed as unanswered, so later reports
+	// can tell which have been answered since
+	trackedQuestionsBlob = "questions/tracked.json"
+	// trackedQuestionMaxAge is how long a reported question is followed before giving up on it
+	trackedQuestionMaxAge = 30 * 24 * time.Hour
+	// answerCheckTimeout bounds looking up the answer state of tracked questions
+	answerCheckTimeout = 2 * time.Minute
+)
+
+// TrackedQuestion is a question a report listed as unanswered
+type TrackedQuestion struct {
+	Mention    models.Mention `json:"mention"`
+	ReportedAt time.Time      `json:"reported_at"`
+}
+
+// resolveQuestions checks the questions earlier reports listed as unanswered and returns
+// those answered since, newest report first, along with the questions still open. Sources
+// that can look up answers are asked; otherwise a question counts as answered when this
+// run found it again with comments or answers.
+func (s *Service) resolveQuestions(ctx context.Context, mentions []models.Mention) ([]models.ResolvedQuestion, map[string]TrackedQuestion) {
+	if !s.config.EnableAnswerTracking || s.storage == nil {
+		return nil, nil
+	}
+
+	s.questionsMu.Lock()
+	tracked, err := s.loadTrackedQuestions()
+	s.questionsMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load tracked questions: %v", err)
+		return nil, nil
+	}
+	if len(tracked) == 0 {
+		return nil, tracked
+	}
+
+	states := make(map[string]sources.AnswerState)
+	for _, mention := range mentions {
+		if _, ok := tracked[mention.ID]; ok {
+			states[mention.ID] = sources.AnswerState{Answers: mention.CommentCount}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, answerCheckTimeout)
+	defer cancel()
+	for source, ids := range trackedIDsBySource(tracked) {
+		checker, ok := s.findSource(source).(sources.AnswerChecker)
+		if !ok {
+			continue
+		}
+		checked, err := checker.CheckAnswers(ctx, ids)
+		if err != nil {
+			logrus.Warnf("Failed to check answers on %s questions: %v", source, err)
+		}
+		for id, state := range checked {
+			states[id] = state
+		}
+	}
+
+	var resolved []models.ResolvedQuestion
+	now := time.Now()
+	for id, question := range tracked {
+		if state, ok := states[id]; ok && state.Answered() {
+			resolved = append(resolved, models.ResolvedQuestion{
+				Mention:    question.Mention,
+				Answers:    state.Answers,
+				Accepted:   state.Accepted,
+				ReportedAt: question.ReportedAt,
+			})
+			delete(tracked, id)
+			continue
+		}
+		if now.Sub(question.ReportedAt) > trackedQuestionMaxAge {
+			delete(tracked, id)
+		}
+	}
+
+	sort.Slice(resolved, func(a, b int) bool {
+		return resolved[a].ReportedAt.After(resolved[b].ReportedAt)
+	})
+	return resolved, tracked
+}
+
+// trackQuestions saves the questions still open along with the ones the report just listed
+// as unanswered. Call it only once the report has been sent, so questions resolved in a
+// report that failed are reported again.
+func (s *Service) trackQuestions(open map[string]TrackedQuestion, unanswered []models.Mention) {
+	if !s.config.EnableAnswerTracking || s.storage == nil {
+		return
+	}
+	if open == nil {
+		open = make(map[string]TrackedQuestion)
+	}
+
+	now := time.Now()
+	for _, mention := range unanswered {
+		if _, ok := open[mention.ID]; ok {
+			continue
+		}
+		// Only the fields reports show are kept
+		mention.Content = ""
+		mention.Excerpt = ""
+		mention.TopComments = nil
+		mention.Filter = nil
+		open[mention.ID] = TrackedQuestion{Mention: mention, ReportedAt: now}
+	}
+
+	data, err := json.Marshal(open)
+	if err != nil {
+		logrus.Warnf("Failed to marshal tracked questions: %v", err)
+		return
+	}
+
+	s.questionsMu.Lock()
+	defer s.questionsMu.Unlock()
+	if err := s.storage.Store(trackedQuestionsBlob, data); err != nil {
+		logrus.Warnf("Failed to store tracked questions: %v", err)
+	}
+}
+
+// loadTrackedQuestions reads the tracked questions keyed by mention ID; callers must hold s.questionsMu
+func (s *Service) loadTrackedQuestions() (map[string]TrackedQuestion, error) {
+	tracked := make(map[string]TrackedQuestion)
+
+	data, found, err := storage.RetrieveIfExists(s.storage, trackedQuestionsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tracked questions: %w", err)
+	}
+	if !found {
+		return tracked, nil
+	}
+	if err := json.Unmarshal(data, &tracked); err != nil {
+		return nil, fmt.Errorf("failed to parse tracked questions: %w", err)
+	}
+	return tracked, nil
+}
+
+// trackedIDsBySource groups tracked question IDs by the source that returned them
+func trackedIDsBySource(tracked map[string]TrackedQuestion) map[string][]string {
+	bySource := make(map[string][]string)
+	for id, question := range tracked {
+		bySource[question.Mention.Source] = append(bySource[question.Mention.Source], id)
+	}
+	for _, ids := range bySource {
+		sort.Strings(ids)
+	}
+	return bySource
+}
@@ -0,0 +1,110 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answeringSource is a stub source that reports the answer state of earlier questions
+type answeringSource struct {
+	stubSource
+	states  map[string]sources.AnswerState
+	checked []string
+}
+
+func (a *answeringSource) CheckAnswers(ctx context.Context, mentionIDs []string) (map[string]sources.AnswerState, error) {
+	a.checked = append(a.checked, mentionIDs...)
+	states := make(map[string]sources.AnswerState)
+	for _, id := range mentionIDs {
+		if state, ok := a.states[id]; ok {
+			states[id] = state
+		}
+	}
+	return states, nil
+}
+
+func storeTrackedQuestions(t *testing.T, storage *testutil.MemoryStorage, tracked map[string]TrackedQuestion) {
+	t.Helper()
+	data, err := json.Marshal(tracked)
+	require.NoError(t, err)
+	require.NoError(t, storage.Store(trackedQuestionsBlob, data))
+}
+
+func TestService_resolveQuestions(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	reportedAt := time.Now().Add(-24 * time.Hour)
+	storeTrackedQuestions(t, storage, map[string]TrackedQuestion{
+		"stackoverflow_1": {Mention: models.Mention{ID: "stackoverflow_1", Source: "stackoverflow", Title: "Accepted"}, ReportedAt: reportedAt},
+		"stackoverflow_2": {Mention: models.Mention{ID: "stackoverflow_2", Source: "stackoverflow", Title: "Still open"}, ReportedAt: reportedAt},
+		"stackoverflow_3": {Mention: models.Mention{ID: "stackoverflow_3", Source: "stackoverflow", Title: "Abandoned"}, ReportedAt: time.Now().Add(-40 * 24 * time.Hour)},
+		"github_4":        {Mention: models.Mention{ID: "github_4", Source: "github", Title: "Commented"}, ReportedAt: reportedAt},
+	})
+
+	service := NewService(&config.Config{EnableAnswerTracking: true}, storage, testutil.NewRecordingNotificationService())
+	stackOverflow := &answeringSource{
+		stubSource: stubSource{name: "stackoverflow"},
+		states:     map[string]sources.AnswerState{"stackoverflow_1": {Answers: 1, Accepted: true}},
+	}
+	service.sources = []sources.Source{stackOverflow, &stubSource{name: "github"}}
+
+	// Sources without answer lookups resolve questions this run found again with comments
+	mentions := []models.Mention{{ID: "github_4", Source: "github", CommentCount: 3}}
+	resolved, open := service.resolveQuestions(context.Background(), mentions)
+
+	assert.ElementsMatch(t, []string{"stackoverflow_1", "stackoverflow_2", "stackoverflow_3"}, stackOverflow.checked)
+	require.Len(t, resolved, 2)
+	titles := map[string]models.ResolvedQuestion{}
+	for _, question := range resolved {
+		titles[question.Mention.Title] = question
+	}
+	assert.Equal(t, "accepted answer", titles["Accepted"].Resolution())
+	assert.Equal(t, "3 answers", titles["Commented"].Resolution())
+
+	// Resolved and expired questions are no longer tracked
+	assert.Len(t, open, 1)
+	assert.Contains(t, open, "stackoverflow_2")
+}
+
+func TestService_RunMonitoring_answerTracking(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	notifications := testutil.NewRecordingNotificationService()
+	cfg := &config.Config{ReportSchedule: "daily", SourceConcurrency: 1, SourceTimeout: time.Minute, Keywords: []string{"aks"}, EnableAnswerTracking: true}
+	question := models.Mention{
+		ID: "stackoverflow_1", Source: "stackoverflow", Title: "How do I upgrade AKS?",
+		Content: "Azure Kubernetes Service upgrade fails", IsQuestion: true, CreatedAt: time.Now().Add(-time.Hour),
+	}
+	stackOverflow := &answeringSource{
+		stubSource: stubSource{name: "stackoverflow", mentions: []models.Mention{question}},
+		states:     map[string]sources.AnswerState{},
+	}
+
+	service := NewService(cfg, storage, notifications)
+	service.sources = []sources.Source{stackOverflow}
+	require.NoError(t, service.RunMonitoring())
+	require.Len(t, notifications.Reports(), 1)
+	require.Len(t, notifications.Reports()[0].Unanswered, 1)
+	assert.Empty(t, notifications.Reports()[0].Resolved)
+
+	// The question is answered before the next report
+	stackOverflow.mentions = nil
+	stackOverflow.states["stackoverflow_1"] = sources.AnswerState{Answers: 2}
+	require.NoError(t, service.RunMonitoring())
+	require.Len(t, notifications.Reports(), 2)
+	resolved := notifications.Reports()[1].Resolved
+	require.Len(t, resolved, 1)
+	assert.Equal(t, "How do I upgrade AKS?", resolved[0].Mention.Title)
+	assert.Equal(t, 2, resolved[0].Answers)
+
+	// A question is only reported as resolved once
+	require.NoError(t, service.RunMonitoring())
+	assert.Empty(t, notifications.Reports()[2].Resolved)
+}
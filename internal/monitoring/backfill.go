@@ -0,0 +1,33 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Backfill collects and stores mentions from the given window without sending a report,
+// so history and the search index can be seeded before the first scheduled run.
+// It returns the number of mentions stored.
+func (s *Service) Backfill(ctx context.Context, window time.Duration) (int, error) {
+	if window <= 0 {
+		return 0, fmt.Errorf("backfill window must be positive")
+	}
+
+	start := time.Now()
+	logrus.Infof("Backfilling mentions from the last %v across %d sources", window, len(s.sources))
+
+	runID := start.Format(runIDLayout)
+	result := s.runPipeline(ctx, runID, s.config.Keywords, fixedWindow(window))
+	if result.storeErr != nil {
+		return len(result.mentions), fmt.Errorf("failed to store backfilled mentions: %w", result.storeErr)
+	}
+	s.saveSearchIndex()
+	s.recordSubreddits(result.mentions)
+	s.exportRunParquet(result.mentions)
+
+	logrus.Infof("Backfilled %d mentions in %v (%d source errors)", len(result.mentions), time.Since(start), result.fetchErrors)
+	return len(result.mentions), nil
+}
@@ -0,0 +1,289 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// blocklistBlob holds blocklist entries added at runtime through the API
+const blocklistBlob = "blocklist/entries.json"
+
+// Blocklist kinds accepted by the blocklist API
+const (
+	BlocklistAuthors  = "authors"
+	BlocklistChannels = "channels"
+	BlocklistDomains  = "domains"
+)
+
+var (
+	// ErrUnknownBlocklistKind is returned for kinds other than authors, channels and domains
+	ErrUnknownBlocklistKind = errors.New("unknown blocklist kind")
+	// ErrBlocklistEntryNotFound is returned when removing an entry that was not added at runtime
+	ErrBlocklistEntryNotFound = errors.New("blocklist entry not found")
+	// ErrInvalidBlocklistEntry is returned for empty entries
+	ErrInvalidBlocklistEntry = errors.New("invalid blocklist entry")
+)
+
+// Blocklist lists noise sources whose mentions are dropped before analysis
+type Blocklist struct {
+	Authors  []string `json:"authors"`
+	Channels []string `json:"channels"`
+	Domains  []string `json:"domains"`
+}
+
+// BlocklistStatus separates entries from configuration, which can only be changed by
+// redeploying, from entries managed at runtime
+type BlocklistStatus struct {
+	Configured Blocklist `json:"configured"`
+	Runtime    Blocklist `json:"runtime"`
+}
+
+// blocklist holds the normalized entries; runtime entries are persisted to blocklistBlob
+type blocklist struct {
+	mu         sync.RWMutex
+	configured map[string]map[string]bool
+	runtime    map[string]map[string]bool
+}
+
+func newBlocklist(authors, channels, domains []string) *blocklist {
+	b := &blocklist{
+		configured: emptyBlocklistSets(),
+		runtime:    emptyBlocklistSets(),
+	}
+	for kind, entries := range map[string][]string{BlocklistAuthors: authors, BlocklistChannels: channels, BlocklistDomains: domains} {
+		for _, entry := range entries {
+			if entry = normalizeBlocklistEntry(kind, entry); entry != "" {
+				b.configured[kind][entry] = true
+			}
+		}
+	}
+	return b
+}
+
+func emptyBlocklistSets() map[string]map[string]bool {
+	return map[string]map[string]bool{
+		BlocklistAuthors:  {},
+		BlocklistChannels: {},
+		BlocklistDomains:  {},
+	}
+}
+
+// normalizeBlocklistEntry lowercases entries and reduces domains to a bare host name
+func normalizeBlocklistEntry(kind, entry string) string {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if kind != BlocklistDomains || entry == "" {
+		return entry
+	}
+
+	if strings.Contains(entry, "://") {
+		if parsed, err := url.Parse(entry); err == nil {
+			entry = parsed.Hostname()
+		}
+	}
+	entry, _, _ = strings.Cut(entry, "/")
+	return strings.TrimPrefix(entry, "www.")
+}
+
+// blocks reports whether any entry matches the mention and describes the match
+func (b *blocklist) blocks(mention models.Mention) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	author := strings.ToLower(strings.TrimSpace(mention.Author))
+	if author != "" {
+		for _, entry := range []string{author, strings.ToLower(mention.Source) + ":" + author} {
+			if b.contains(BlocklistAuthors, entry) {
+				return "author " + mention.Author, true
+			}
+		}
+	}
+
+	if channel := strings.ToLower(mention.Channel); channel != "" && b.contains(BlocklistChannels, channel) {
+		return "channel " + mention.Channel, true
+	}
+	// YouTube reports the channel title as the author, so channels can be listed by name too
+	if mention.Source == "youtube" && author != "" && b.contains(BlocklistChannels, author) {
+		return "channel " + mention.Author, true
+	}
+
+	if parsed, err := url.Parse(mention.URL); err == nil && parsed.Hostname() != "" {
+		host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+		for {
+			if b.contains(BlocklistDomains, host) {
+				return "domain " + host, true
+			}
+			_, parent, found := strings.Cut(host, ".")
+			if !found || !strings.Contains(parent, ".") {
+				break
+			}
+			host = parent
+		}
+	}
+
+	return "", false
+}
+
+// contains checks configured and runtime entries; callers must hold b.mu
+func (b *blocklist) contains(kind, entry string) bool {
+	return b.configured[kind][entry] || b.runtime[kind][entry]
+}
+
+func (b *blocklist) status() BlocklistStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return BlocklistStatus{
+		Configured: blocklistFromSets(b.configured),
+		Runtime:    blocklistFromSets(b.runtime),
+	}
+}
+
+func blocklistFromSets(sets map[string]map[string]bool) Blocklist {
+	list := func(set map[string]bool) []string {
+		entries := make([]string, 0, len(set))
+		for entry := range set {
+			entries = append(entries, entry)
+		}
+		sort.Strings(entries)
+		return entries
+	}
+
+	return Blocklist{
+		Authors:  list(sets[BlocklistAuthors]),
+		Channels: list(sets[BlocklistChannels]),
+		Domains:  list(sets[BlocklistDomains]),
+	}
+}
+
+// blocklist returns the noise blocklist, loading runtime entries from storage on first use
+func (s *Service) blocklist() *blocklist {
+	s.blocklistOnce.Do(func() {
+		s.blocked = newBlocklist(s.config.BlockedAuthors, s.config.BlockedChannels, s.config.BlockedDomains)
+		if s.storage == nil {
+			return
+		}
+
+		runtime, err := s.loadRuntimeBlocklist()
+		if err != nil {
+			logrus.Warnf("Failed to load runtime blocklist, using configured entries only: %v", err)
+			return
+		}
+		for kind, entries := range map[string][]string{BlocklistAuthors: runtime.Authors, BlocklistChannels: runtime.Channels, BlocklistDomains: runtime.Domains} {
+			for _, entry := range entries {
+				s.blocked.runtime[kind][entry] = true
+			}
+		}
+	})
+
+	return s.blocked
+}
+
+// GetBlocklist returns the configured and runtime blocklist entries
+func (s *Service) GetBlocklist() BlocklistStatus {
+	return s.blocklist().status()
+}
+
+// AddBlocklistEntry blocks an author, channel or domain and persists the entry
+func (s *Service) AddBlocklistEntry(kind, entry string) (BlocklistStatus, error) {
+	return s.updateBlocklist(kind, entry, true)
+}
+
+// RemoveBlocklistEntry unblocks an entry that was added at runtime
+func (s *Service) RemoveBlocklistEntry(kind, entry string) (BlocklistStatus, error) {
+	return s.updateBlocklist(kind, entry, false)
+}
+
+func (s *Service) updateBlocklist(kind, entry string, add bool) (BlocklistStatus, error) {
+	blocked := s.blocklist()
+	if _, ok := blocked.runtime[kind]; !ok {
+		return BlocklistStatus{}, fmt.Errorf("%w %q", ErrUnknownBlocklistKind, kind)
+	}
+
+	entry = normalizeBlocklistEntry(kind, entry)
+	if entry == "" {
+		return BlocklistStatus{}, fmt.Errorf("%w: entry must not be empty", ErrInvalidBlocklistEntry)
+	}
+
+	blocked.mu.Lock()
+	if add {
+		blocked.runtime[kind][entry] = true
+	} else {
+		if !blocked.runtime[kind][entry] {
+			blocked.mu.Unlock()
+			return BlocklistStatus{}, fmt.Errorf("%w: %s %q", ErrBlocklistEntryNotFound, kind, entry)
+		}
+		delete(blocked.runtime[kind], entry)
+	}
+	runtime := blocklistFromSets(blocked.runtime)
+	blocked.mu.Unlock()
+
+	if err := s.saveRuntimeBlocklist(runtime); err != nil {
+		return BlocklistStatus{}, err
+	}
+
+	if add {
+		logrus.Infof("Blocked %s %q", kind, entry)
+	} else {
+		logrus.Infof("Unblocked %s %q", kind, entry)
+	}
+	return blocked.status(), nil
+}
+
+// filterBlocked drops mentions from blocked authors, channels and domains
+func (s *Service) filterBlocked(mentions []models.Mention) []models.Mention {
+	blocked := s.blocklist()
+
+	filtered := make([]models.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		if reason, ok := blocked.blocks(mention); ok {
+			logrus.Debugf("Dropping mention %s from blocked %s", mention.ID, reason)
+			continue
+		}
+		filtered = append(filtered, mention)
+	}
+
+	if dropped := len(mentions) - len(filtered); dropped > 0 {
+		logrus.Infof("Blocklist dropped %d of %d mentions", dropped, len(mentions))
+	}
+	return filtered
+}
+
+func (s *Service) loadRuntimeBlocklist() (Blocklist, error) {
+	var runtime Blocklist
+
+	data, found, err := storage.RetrieveIfExists(s.storage, blocklistBlob)
+	if err != nil {
+		return runtime, fmt.Errorf("failed to retrieve blocklist: %w", err)
+	}
+	if !found {
+		return runtime, nil
+	}
+	if err := json.Unmarshal(data, &runtime); err != nil {
+		return runtime, fmt.Errorf("failed to parse blocklist: %w", err)
+	}
+	return runtime, nil
+}
+
+func (s *Service) saveRuntimeBlocklist(runtime Blocklist) error {
+	if s.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(runtime)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blocklist: %w", err)
+	}
+	if err := s.storage.Store(blocklistBlob, data); err != nil {
+		return fmt.Errorf("failed to store blocklist: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_filterBlocked(t *testing.T) {
+	cfg := &config.Config{
+		BlockedAuthors:  []string{" AutoModerator ", "youtube:DocsMirror"},
+		BlockedChannels: []string{"UCcontentfarm"},
+		BlockedDomains:  []string{"https://www.spamblog.io/"},
+	}
+	service := &Service{config: cfg}
+
+	mentions := []models.Mention{
+		{ID: "kept", Source: "reddit", Author: "jane", URL: "https://reddit.com/r/AZURE/1"},
+		{ID: "author", Source: "reddit", Author: "automoderator", URL: "https://reddit.com/r/AZURE/2"},
+		{ID: "scoped_author", Source: "youtube", Author: "DocsMirror", URL: "https://www.youtube.com/watch?v=1"},
+		{ID: "scoped_author_other_source", Source: "reddit", Author: "DocsMirror", URL: "https://reddit.com/r/AZURE/3"},
+		{ID: "channel", Source: "youtube", Author: "AKS Tips", Channel: "UCcontentfarm", URL: "https://www.youtube.com/watch?v=2"},
+		{ID: "subdomain", Source: "medium", Author: "x", URL: "https://blog.spamblog.io/aks"},
+		{ID: "lookalike_domain", Source: "medium", Author: "x", URL: "https://notspamblog.io/aks"},
+	}
+
+	var ids []string
+	for _, mention := range service.filterBlocked(mentions) {
+		ids = append(ids, mention.ID)
+	}
+	assert.Equal(t, []string{"kept", "scoped_author_other_source", "lookalike_domain"}, ids)
+}
+
+func TestService_blocklistRuntimeEntries(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	cfg := &config.Config{BlockedDomains: []string{"spamblog.io"}}
+	service := &Service{config: cfg, storage: storage}
+
+	status, err := service.AddBlocklistEntry(BlocklistChannels, "AKS Shorts Daily")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aks shorts daily"}, status.Runtime.Channels)
+	assert.Equal(t, []string{"spamblog.io"}, status.Configured.Domains)
+
+	// Runtime entries are persisted and loaded by a new service
+	reloaded := &Service{config: cfg, storage: storage}
+	assert.Equal(t, []string{"aks shorts daily"}, reloaded.GetBlocklist().Runtime.Channels)
+	assert.Empty(t, reloaded.filterBlocked([]models.Mention{{ID: "v", Source: "youtube", Author: "AKS Shorts Daily"}}))
+
+	_, err = service.AddBlocklistEntry("subreddits", "AZURE")
+	assert.ErrorIs(t, err, ErrUnknownBlocklistKind)
+	_, err = service.AddBlocklistEntry(BlocklistAuthors, "  ")
+	assert.ErrorIs(t, err, ErrInvalidBlocklistEntry)
+
+	// Configured entries can only be changed by redeploying
+	_, err = service.RemoveBlocklistEntry(BlocklistDomains, "spamblog.io")
+	assert.ErrorIs(t, err, ErrBlocklistEntryNotFound)
+
+	status, err = service.RemoveBlocklistEntry(BlocklistChannels, "aks shorts daily")
+	require.NoError(t, err)
+	assert.Empty(t, status.Runtime.Channels)
+}
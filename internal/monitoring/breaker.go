@@ -0,0 +1,181 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// sourceBreakersBlob holds the circuit breaker of each failing source, so the runs of separate
+// processes such as the run and urgent CronJobs share them
+const sourceBreakersBlob = "breakers/sources.json"
+
+// ErrSourceSkipped is the fetch error of a source skipped because its circuit breaker is open
+var ErrSourceSkipped = errors.New("source skipped")
+
+// SourceBreaker counts the consecutive failed fetches of a source. Once they reach
+// SOURCE_BREAKER_THRESHOLD the breaker opens and runs skip the source until OpenUntil. The
+// first fetch after that closes the breaker if it succeeds, or opens it for another cooldown.
+type SourceBreaker struct {
+	Failures  int        `json:"failures"`
+	LastError string     `json:"last_error"`
+	OpenUntil *time.Time `json:"open_until,omitempty"`
+}
+
+// open reports whether runs skip the source at the given time
+func (b *SourceBreaker) open(now time.Time) bool {
+	return b.OpenUntil != nil && now.Before(*b.OpenUntil)
+}
+
+// skippedResult returns the fetch result standing in for a source whose breaker is open, so a
+// run counts it as one error without calling the source
+func (s *Service) skippedResult(name string) (fetchResult, bool) {
+	if s.config.SourceBreakerThreshold <= 0 {
+		return fetchResult{}, false
+	}
+	s.loadBreakers()
+
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	breaker, ok := s.breakers[name]
+	if !ok || !breaker.open(time.Now()) {
+		return fetchResult{}, false
+	}
+
+	until := breaker.OpenUntil.Format(time.RFC3339)
+	logrus.Infof("Skipping %s until %s: circuit breaker open after %d consecutive failures", name, until, breaker.Failures)
+	return fetchResult{
+		source: name,
+		err:    fmt.Errorf("%w until %s after %d consecutive failures: %s", ErrSourceSkipped, until, breaker.Failures, breaker.LastError),
+	}, true
+}
+
+// recordBreaker counts a fetch towards the breaker of its source. Fetches cancelled by a
+// shutdown are not counted.
+func (s *Service) recordBreaker(result fetchResult) {
+	if s.config.SourceBreakerThreshold <= 0 || errors.Is(result.err, context.Canceled) {
+		return
+	}
+	s.loadBreakers()
+
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	breaker, ok := s.breakers[result.source]
+	if result.err == nil {
+		if !ok {
+			return
+		}
+		if breaker.OpenUntil != nil {
+			logrus.Infof("Closed circuit breaker for %s after a successful fetch", result.source)
+		}
+		delete(s.breakers, result.source)
+		s.saveBreakers()
+		return
+	}
+
+	if !ok {
+		breaker = &SourceBreaker{}
+		s.breakers[result.source] = breaker
+	}
+	breaker.Failures++
+	breaker.LastError = result.err.Error()
+	if breaker.Failures >= s.config.SourceBreakerThreshold {
+		until := time.Now().Add(s.config.SourceBreakerCooldown).UTC()
+		breaker.OpenUntil = &until
+		logrus.Warnf("Opened circuit breaker for %s after %d consecutive failures, skipping it until %s",
+			result.source, breaker.Failures, until.Format(time.RFC3339))
+	}
+	s.saveBreakers()
+}
+
+// skippedSources lists the sources runs currently skip, by name
+func (s *Service) skippedSources() []models.SkippedSource {
+	if s.config.SourceBreakerThreshold <= 0 {
+		return nil
+	}
+	s.loadBreakers()
+
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	now := time.Now()
+	var skipped []models.SkippedSource
+	for name, breaker := range s.breakers {
+		if breaker.open(now) {
+			skipped = append(skipped, models.SkippedSource{
+				Source:    name,
+				Failures:  breaker.Failures,
+				LastError: breaker.LastError,
+				Until:     *breaker.OpenUntil,
+			})
+		}
+	}
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].Source < skipped[j].Source })
+	return skipped
+}
+
+// sourceBreaker returns a copy of the breaker of a source, nil while it has no failures
+func (s *Service) sourceBreaker(name string) *SourceBreaker {
+	if s.config.SourceBreakerThreshold <= 0 {
+		return nil
+	}
+	s.loadBreakers()
+
+	s.breakersMu.Lock()
+	defer s.breakersMu.Unlock()
+
+	breaker, ok := s.breakers[name]
+	if !ok {
+		return nil
+	}
+	copied := *breaker
+	return &copied
+}
+
+// loadBreakers reads the stored breakers on first use. A failure to read them starts every
+// source closed rather than failing runs.
+func (s *Service) loadBreakers() {
+	s.breakersOnce.Do(func() {
+		s.breakers = make(map[string]*SourceBreaker)
+		if s.storage == nil {
+			return
+		}
+
+		data, found, err := storage.RetrieveIfExists(s.storage, sourceBreakersBlob)
+		if err != nil {
+			logrus.Warnf("Failed to retrieve source circuit breakers: %v", err)
+			return
+		}
+		if !found {
+			return
+		}
+		if err := json.Unmarshal(data, &s.breakers); err != nil {
+			logrus.Warnf("Failed to parse source circuit breakers: %v", err)
+			s.breakers = make(map[string]*SourceBreaker)
+		}
+	})
+}
+
+// saveBreakers stores the breakers; callers hold breakersMu
+func (s *Service) saveBreakers() {
+	if s.storage == nil {
+		return
+	}
+	data, err := json.Marshal(s.breakers)
+	if err != nil {
+		logrus.Warnf("Failed to marshal source circuit breakers: %v", err)
+		return
+	}
+	if err := s.storage.Store(sourceBreakersBlob, data); err != nil {
+		logrus.Warnf("Failed to store source circuit breakers: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSource fails every fetch while err is set, counting the fetches it serves
+type failingSource struct {
+	name    string
+	err     error
+	fetches atomic.Int32
+}
+
+func (s *failingSource) GetName() string { return s.name }
+func (s *failingSource) IsEnabled() bool { return true }
+
+func (s *failingSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	s.fetches.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []models.Mention{{ID: s.name + "_1", Source: s.name}}, nil
+}
+
+func breakerConfig() *config.Config {
+	return &config.Config{
+		SourceTimeout:          time.Second,
+		SourceBreakerThreshold: 2,
+		SourceBreakerCooldown:  time.Hour,
+	}
+}
+
+func TestService_breakerSkipsFailingSource(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	reddit := &failingSource{name: "reddit", err: errors.New("401 Unauthorized")}
+	service := &Service{config: breakerConfig(), storage: store}
+	service.sources = []sources.Source{reddit, &failingSource{name: "hackernews"}}
+
+	// Failures below the threshold are fetched and counted
+	result := service.runPipeline(context.Background(), "run-1", []string{"aks"}, fixedWindow(time.Hour))
+	assert.Equal(t, 1, result.fetchErrors)
+	assert.Empty(t, service.skippedSources())
+
+	result = service.runPipeline(context.Background(), "run-2", []string{"aks"}, fixedWindow(time.Hour))
+	assert.Equal(t, 1, result.fetchErrors)
+	skipped := service.skippedSources()
+	require.Len(t, skipped, 1)
+	assert.Equal(t, "reddit", skipped[0].Source)
+	assert.Equal(t, 2, skipped[0].Failures)
+	assert.Equal(t, "401 Unauthorized", skipped[0].LastError)
+
+	// While the breaker is open the source is not called, and counts as a single error
+	result = service.runPipeline(context.Background(), "run-3", []string{"aks"}, fixedWindow(time.Hour))
+	assert.Equal(t, 1, result.fetchErrors)
+	assert.Equal(t, int32(2), reddit.fetches.Load())
+	assert.Len(t, result.mentions, 1)
+
+	// Another process sharing the storage skips the source too
+	next := &Service{config: breakerConfig(), storage: store}
+	fetched := next.fetchFromSources(context.Background(), []sources.Source{reddit}, []string{"aks"}, time.Hour, next.sourceTimeout)
+	require.Len(t, fetched, 1)
+	assert.ErrorIs(t, fetched[0].err, ErrSourceSkipped)
+	assert.Equal(t, int32(2), reddit.fetches.Load())
+
+	statuses := service.GetSourceStatuses()
+	assert.Equal(t, 2, statuses[0].ConsecutiveFailures)
+	assert.NotNil(t, statuses[0].SkippedUntil)
+}
+
+func TestService_breakerRetriesAfterCooldown(t *testing.T) {
+	reddit := &failingSource{name: "reddit", err: errors.New("401 Unauthorized")}
+	service := &Service{config: breakerConfig(), storage: testutil.NewMemoryStorage()}
+
+	expired := time.Now().Add(-time.Minute)
+	service.loadBreakers()
+	service.breakers["reddit"] = &SourceBreaker{Failures: 2, LastError: "401 Unauthorized", OpenUntil: &expired}
+
+	// A failed retry opens the breaker for another cooldown
+	service.fetchFromSources(context.Background(), []sources.Source{reddit}, []string{"aks"}, time.Hour, service.sourceTimeout)
+	assert.Equal(t, int32(1), reddit.fetches.Load())
+	require.Len(t, service.skippedSources(), 1)
+	assert.Equal(t, 3, service.skippedSources()[0].Failures)
+
+	// A successful retry closes it
+	service.breakers["reddit"].OpenUntil = &expired
+	reddit.err = nil
+	results := service.fetchFromSources(context.Background(), []sources.Source{reddit}, []string{"aks"}, time.Hour, service.sourceTimeout)
+	assert.NoError(t, results[0].err)
+	assert.Empty(t, service.skippedSources())
+	assert.Nil(t, service.sourceBreaker("reddit"))
+}
+
+func TestService_breakerDisabled(t *testing.T) {
+	cfg := breakerConfig()
+	cfg.SourceBreakerThreshold = 0
+	reddit := &failingSource{name: "reddit", err: errors.New("401 Unauthorized")}
+	service := &Service{config: cfg, storage: testutil.NewMemoryStorage()}
+
+	for i := 0; i < 3; i++ {
+		service.fetchFromSources(context.Background(), []sources.Source{reddit}, []string{"aks"}, time.Hour, service.sourceTimeout)
+	}
+	assert.Equal(t, int32(3), reddit.fetches.Load())
+	assert.Empty(t, service.skippedSources())
+}
@@ -0,0 +1,192 @@
+package monitoring
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// Page sizes of the bulk mention export
+const (
+	DefaultExportLimit = 10000
+	MaxExportLimit     = 100000
+)
+
+// exportBlobCacheSize bounds the mentions blobs kept in memory while exporting a page
+const exportBlobCacheSize = 16
+
+// ErrInvalidCursor is returned for export cursors the bot did not issue
+var ErrInvalidCursor = errors.New("invalid export cursor")
+
+// ExportQuery selects a page of stored mentions for a bulk export
+type ExportQuery struct {
+	From   time.Time // Created at or after; zero for the oldest stored mention
+	To     time.Time // Created before; zero for now
+	Source string
+	Cursor string // NextCursor of the previous page, empty for the first
+	Limit  int    // Page size, DefaultExportLimit when 0
+}
+
+// MentionExport is a page of a bulk export, planned from the mention index so the cursor of
+// the next page is known before the mentions are read
+type MentionExport struct {
+	NextCursor string // Empty on the last page
+	Count      int    // Mentions indexed for the page; any missing from their blob are skipped
+
+	service *Service
+	entries []exportEntry
+}
+
+type exportEntry struct {
+	id   string
+	blob string
+	at   time.Time
+}
+
+// exportPosition is what a cursor encodes: the last mention of a page in export order
+type exportPosition struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// PlanExport selects a page of stored mentions in creation order, oldest first. Mentions
+// created at the same time are ordered by ID, so a cursor resumes exactly after the last
+// mention of its page.
+func (s *Service) PlanExport(query ExportQuery) (*MentionExport, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultExportLimit
+	}
+	if limit > MaxExportLimit {
+		limit = MaxExportLimit
+	}
+	after, err := decodeExportCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := query.From
+	if after != nil && after.CreatedAt.After(from) {
+		from = after.CreatedAt
+	}
+
+	export := &MentionExport{service: s}
+	if s.index == nil {
+		return export, nil
+	}
+
+	days, err := s.index.Days()
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		if !day.Add(24 * time.Hour).After(from) {
+			continue
+		}
+		if !day.Before(to) || len(export.entries) > limit {
+			break
+		}
+
+		index, err := s.index.Load(day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the mention index for %s: %w", day.Format("2006-01-02"), err)
+		}
+		var entries []exportEntry
+		for id, entry := range index {
+			if entry.CreatedAt.Before(from) || !entry.CreatedAt.Before(to) {
+				continue
+			}
+			if query.Source != "" && entry.Source != query.Source {
+				continue
+			}
+			if after != nil && !exportBefore(after.CreatedAt, after.ID, entry.CreatedAt, id) {
+				continue
+			}
+			entries = append(entries, exportEntry{id: id, blob: entry.Blob, at: entry.CreatedAt})
+		}
+		sort.Slice(entries, func(a, b int) bool {
+			return exportBefore(entries[a].at, entries[a].id, entries[b].at, entries[b].id)
+		})
+		export.entries = append(export.entries, entries...)
+	}
+
+	if len(export.entries) > limit {
+		export.entries = export.entries[:limit]
+		last := export.entries[limit-1]
+		export.NextCursor = encodeExportCursor(exportPosition{CreatedAt: last.at, ID: last.id})
+	}
+	export.Count = len(export.entries)
+	return export, nil
+}
+
+// Each reads the page's mentions with their tags and passes them to emit in export order,
+// stopping at the first error emit returns. Mentions missing from their blob are skipped.
+func (e *MentionExport) Each(emit func(models.Mention) error) error {
+	var tags map[string][]string
+	if e.service.storage != nil && len(e.entries) > 0 {
+		e.service.tagsMu.Lock()
+		loaded, err := e.service.loadTags()
+		e.service.tagsMu.Unlock()
+		if err != nil {
+			return err
+		}
+		tags = loaded
+	}
+
+	blobs := cache.NewLRU[string, map[string]models.Mention](exportBlobCacheSize)
+	for _, entry := range e.entries {
+		stored, ok := blobs.Get(entry.blob)
+		if !ok {
+			stored = e.service.readMentionsBlob(entry.blob)
+			blobs.Add(entry.blob, stored)
+		}
+		mention, ok := stored[entry.id]
+		if !ok {
+			continue
+		}
+		if mentionTags, ok := tags[mention.ID]; ok {
+			mention.Tags = mentionTags
+		}
+		if err := emit(mention); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportBefore orders mentions by creation time, then ID
+func exportBefore(at time.Time, id string, otherAt time.Time, otherID string) bool {
+	if !at.Equal(otherAt) {
+		return at.Before(otherAt)
+	}
+	return id < otherID
+}
+
+func encodeExportCursor(position exportPosition) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeExportCursor(cursor string) (*exportPosition, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var position exportPosition
+	if err := json.Unmarshal(data, &position); err != nil || position.CreatedAt.IsZero() || position.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &position, nil
+}
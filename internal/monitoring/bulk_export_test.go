@@ -0,0 +1,73 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportIDs(t *testing.T, export *MentionExport) []string {
+	t.Helper()
+	var ids []string
+	require.NoError(t, export.Each(func(mention models.Mention) error {
+		ids = append(ids, mention.ID)
+		return nil
+	}))
+	return ids
+}
+
+func TestService_PlanExport(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{}, storage: store, index: storage.NewMentionIndex(store)}
+
+	day := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, service.storeMentionBatch("run1", "reddit", []models.Mention{
+		{ID: "reddit_b", Source: "reddit", CreatedAt: day},
+		{ID: "reddit_a", Source: "reddit", CreatedAt: day},
+		{ID: "reddit_c", Source: "reddit", CreatedAt: day.Add(48 * time.Hour)},
+	}))
+	require.NoError(t, service.storeMentionBatch("run1", "hackernews", []models.Mention{
+		{ID: "hn_1", Source: "hackernews", CreatedAt: day.Add(time.Hour)},
+		{ID: "hn_2", Source: "hackernews", CreatedAt: day.Add(-24 * time.Hour)},
+	}))
+	_, err := service.AddMentionTags("hn_1", []string{"pricing"})
+	require.NoError(t, err)
+
+	// Pages follow creation order across days, and the cursor resumes after the last mention
+	var ids []string
+	cursor := ""
+	pages := 0
+	for {
+		export, err := service.PlanExport(ExportQuery{Cursor: cursor, Limit: 2})
+		require.NoError(t, err)
+		ids = append(ids, exportIDs(t, export)...)
+		pages++
+		if export.NextCursor == "" {
+			break
+		}
+		cursor = export.NextCursor
+	}
+	assert.Equal(t, []string{"hn_2", "reddit_a", "reddit_b", "hn_1", "reddit_c"}, ids)
+	assert.Equal(t, 3, pages)
+
+	export, err := service.PlanExport(ExportQuery{From: day, To: day.Add(24 * time.Hour), Source: "hackernews"})
+	require.NoError(t, err)
+	var exported []models.Mention
+	require.NoError(t, export.Each(func(mention models.Mention) error {
+		exported = append(exported, mention)
+		return nil
+	}))
+	require.Len(t, exported, 1)
+	assert.Equal(t, "hn_1", exported[0].ID)
+	assert.Equal(t, []string{"pricing"}, exported[0].Tags)
+	assert.Empty(t, export.NextCursor)
+
+	_, err = service.PlanExport(ExportQuery{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
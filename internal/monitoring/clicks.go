@@ -0,0 +1,299 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// clicksBlob holds the clicks on mention links in notifications, keyed by mention ID
+	clicksBlob = "clicks/mentions.json"
+	// clickRetention is how long a mention's clicks are kept after its last click
+	clickRetention = 180 * 24 * time.Hour
+	// clickFlushInterval is how often buffered clicks are written to clicksBlob
+	clickFlushInterval = time.Minute
+	// clickDedupeWindow is how long repeated clicks by a client on a mention count once
+	clickDedupeWindow = time.Hour
+	// clickClientLimit is the clicks counted per client within clickDedupeWindow
+	clickClientLimit = 30
+	// maxClickClients bounds the clients tracked between flushes; clicks by further clients
+	// are redirected without counting
+	maxClickClients = 10000
+)
+
+// Bounds on the mentions listed by ClickStats
+const (
+	DefaultClickStatsLimit = 20
+	MaxClickStatsLimit     = 500
+)
+
+// linkPreviewAgents identify the crawlers that fetch links to preview or scan them, e.g. when
+// a report is posted to Slack; their requests are redirected without counting as clicks
+var linkPreviewAgents = []string{
+	"slackbot", "slack-imgproxy", "twitterbot", "facebookexternalhit", "linkedinbot", "discordbot",
+	"skypeuripreview", "microsoft office", "googlebot", "bingbot", "bot/", "crawler", "spider", "preview",
+}
+
+// MentionClicks counts the clicks on a mention's link in notifications
+type MentionClicks struct {
+	ID         string    `json:"id"`
+	Source     string    `json:"source"`
+	Title      string    `json:"title"`
+	Clicks     int       `json:"clicks"`
+	FirstClick time.Time `json:"first_click"`
+	LastClick  time.Time `json:"last_click"`
+}
+
+// clickBuffer holds the clicks counted since the last write to clicksBlob, and the clients
+// seen within clickDedupeWindow. It is guarded by Service.clicksMu; the zero value is ready to
+// use.
+type clickBuffer struct {
+	pending map[string]*MentionClicks // Clicks not yet written, by mention ID
+	seen    map[string]time.Time      // Last counted click by client and mention
+	clients map[string]*clientClicks
+	flushed time.Time
+}
+
+// clientClicks counts a client's clicks since the start of its window
+type clientClicks struct {
+	since  time.Time
+	clicks int
+}
+
+// ClickStats shows which reported mentions people open: clicks in total and by source, and
+// the most clicked mentions
+type ClickStats struct {
+	Clicks   int             `json:"clicks"`
+	Mentions int             `json:"mentions"` // Mentions clicked at least once
+	BySource map[string]int  `json:"by_source"`
+	Top      []MentionClicks `json:"top"` // Most clicked first
+}
+
+// mentionClickURL is the bot's redirect for a mention that records clicks, "" when click
+// tracking is off or the bot isn't publicly reachable
+func (s *Service) mentionClickURL(id string) string {
+	if !s.config.EnableClickTracking || s.config.PublicBaseURL == "" || id == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/r/%s", s.config.PublicBaseURL, url.PathEscape(id))
+}
+
+// RecordClick counts a click on a stored mention's link and returns the URL of its post to
+// redirect to. client identifies the reader, e.g. by address and user agent: repeated clicks
+// by a client on a mention count once an hour, and a client's clicks beyond clickClientLimit
+// an hour aren't counted. Link previews and scanners, recognized by their user agent, are
+// redirected without counting. Clicks are buffered and written at most once a minute; a
+// write that fails is logged rather than failing the redirect.
+func (s *Service) RecordClick(id, client, userAgent string) (string, error) {
+	doc, ok := s.searchIndex().Document(id)
+	if !ok || doc.URL == "" {
+		return "", fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+	if isLinkPreview(userAgent) {
+		return doc.URL, nil
+	}
+
+	s.clicksMu.Lock()
+	defer s.clicksMu.Unlock()
+
+	now := time.Now().UTC()
+	if !s.clicks.count(id, client, now) {
+		return doc.URL, nil
+	}
+
+	if s.clicks.pending == nil {
+		s.clicks.pending = make(map[string]*MentionClicks)
+	}
+	entry, ok := s.clicks.pending[id]
+	if !ok {
+		entry = &MentionClicks{ID: id, FirstClick: now}
+		s.clicks.pending[id] = entry
+	}
+	entry.Source, entry.Title = doc.Source, doc.Title
+	entry.Clicks++
+	entry.LastClick = now
+
+	if now.Sub(s.clicks.flushed) >= clickFlushInterval {
+		if err := s.flushClicks(now); err != nil {
+			logrus.Warnf("Failed to record clicks: %v", err)
+		}
+	}
+	return doc.URL, nil
+}
+
+// FlushClicks writes the buffered clicks to storage; Shutdown calls it so no click is lost
+func (s *Service) FlushClicks() error {
+	s.clicksMu.Lock()
+	defer s.clicksMu.Unlock()
+	return s.flushClicks(time.Now().UTC())
+}
+
+// flushClicks adds the buffered clicks to clicksBlob and forgets clients outside the dedupe
+// window; callers must hold s.clicksMu. Clicks that can't be written stay buffered for the
+// next flush.
+func (s *Service) flushClicks(now time.Time) error {
+	s.clicks.flushed = now
+	s.clicks.prune(now)
+	if len(s.clicks.pending) == 0 || s.storage == nil {
+		return nil
+	}
+
+	clicks, err := s.loadClicks()
+	if err != nil {
+		return err
+	}
+	mergeClicks(clicks, s.clicks.pending)
+	for mentionID, entry := range clicks {
+		if now.Sub(entry.LastClick) > clickRetention {
+			delete(clicks, mentionID)
+		}
+	}
+
+	data, err := json.Marshal(clicks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mention clicks: %w", err)
+	}
+	if err := s.storage.Store(clicksBlob, data); err != nil {
+		return fmt.Errorf("failed to store mention clicks: %w", err)
+	}
+	s.clicks.pending = nil
+	return nil
+}
+
+// ClickStats returns the clicks on mention links, listing up to limit of the most clicked
+// mentions
+func (s *Service) ClickStats(limit int) (*ClickStats, error) {
+	if limit <= 0 {
+		limit = DefaultClickStatsLimit
+	}
+	if limit > MaxClickStatsLimit {
+		limit = MaxClickStatsLimit
+	}
+
+	s.clicksMu.Lock()
+	clicks, err := s.loadClicks()
+	if err == nil {
+		mergeClicks(clicks, s.clicks.pending)
+	}
+	s.clicksMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ClickStats{BySource: make(map[string]int), Top: []MentionClicks{}}
+	for _, entry := range clicks {
+		stats.Clicks += entry.Clicks
+		stats.Mentions++
+		stats.BySource[entry.Source] += entry.Clicks
+		stats.Top = append(stats.Top, *entry)
+	}
+	sort.Slice(stats.Top, func(i, j int) bool {
+		if stats.Top[i].Clicks != stats.Top[j].Clicks {
+			return stats.Top[i].Clicks > stats.Top[j].Clicks
+		}
+		return stats.Top[i].LastClick.After(stats.Top[j].LastClick)
+	})
+	if len(stats.Top) > limit {
+		stats.Top = stats.Top[:limit]
+	}
+	return stats, nil
+}
+
+// count reports whether a click by client on a mention counts, recording it if so
+func (b *clickBuffer) count(id, client string, now time.Time) bool {
+	if b.seen == nil {
+		b.seen = make(map[string]time.Time)
+		b.clients = make(map[string]*clientClicks)
+	}
+
+	key := client + "\x00" + id
+	if last, ok := b.seen[key]; ok && now.Sub(last) < clickDedupeWindow {
+		return false
+	}
+	window, ok := b.clients[client]
+	if !ok || now.Sub(window.since) >= clickDedupeWindow {
+		if !ok && len(b.clients) >= maxClickClients {
+			return false
+		}
+		window = &clientClicks{since: now}
+		b.clients[client] = window
+	}
+	if window.clicks >= clickClientLimit {
+		return false
+	}
+
+	window.clicks++
+	b.seen[key] = now
+	return true
+}
+
+// prune forgets the clicks and clients outside the dedupe window
+func (b *clickBuffer) prune(now time.Time) {
+	for key, last := range b.seen {
+		if now.Sub(last) >= clickDedupeWindow {
+			delete(b.seen, key)
+		}
+	}
+	for client, window := range b.clients {
+		if now.Sub(window.since) >= clickDedupeWindow {
+			delete(b.clients, client)
+		}
+	}
+}
+
+// mergeClicks adds the buffered clicks in pending to clicks
+func mergeClicks(clicks, pending map[string]*MentionClicks) {
+	for id, buffered := range pending {
+		entry, ok := clicks[id]
+		if !ok {
+			copied := *buffered
+			clicks[id] = &copied
+			continue
+		}
+		entry.Source, entry.Title = buffered.Source, buffered.Title
+		entry.Clicks += buffered.Clicks
+		entry.LastClick = buffered.LastClick
+	}
+}
+
+// isLinkPreview reports whether a request comes from a link preview or scanner rather than a
+// person; requests without a user agent are treated as such
+func isLinkPreview(userAgent string) bool {
+	agent := strings.ToLower(userAgent)
+	if agent == "" {
+		return true
+	}
+	for _, preview := range linkPreviewAgents {
+		if strings.Contains(agent, preview) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadClicks reads the clicks on mention links; callers must hold s.clicksMu
+func (s *Service) loadClicks() (map[string]*MentionClicks, error) {
+	clicks := make(map[string]*MentionClicks)
+	if s.storage == nil {
+		return clicks, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, clicksBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mention clicks: %w", err)
+	}
+	if !found {
+		return clicks, nil
+	}
+	if err := json.Unmarshal(data, &clicks); err != nil {
+		return nil, fmt.Errorf("failed to parse mention clicks: %w", err)
+	}
+	return clicks, nil
+}
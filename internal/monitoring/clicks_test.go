@@ -0,0 +1,86 @@
+package monitoring
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const browserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"
+
+func TestService_RecordClick(t *testing.T) {
+	service, _ := newLifecycleService(t)
+
+	mentions := []models.Mention{{ID: "reddit_1"}}
+	service.linkMentionPages(mentions)
+	assert.Empty(t, mentions[0].ClickURL, "click tracking is off by default")
+	service.config.PublicBaseURL = "https://bot.example.com"
+	service.config.EnableClickTracking = true
+	service.linkMentionPages(mentions)
+	assert.Equal(t, "https://bot.example.com/r/reddit_1", mentions[0].ClickURL)
+
+	target, err := service.RecordClick("reddit_1", "10.0.0.1", browserAgent)
+	require.NoError(t, err)
+	assert.Equal(t, "https://reddit.com/r/AZURE/1", target)
+	_, err = service.RecordClick("reddit_1", "10.0.0.1", browserAgent)
+	require.NoError(t, err)
+	_, err = service.RecordClick("reddit_1", "10.0.0.2", browserAgent)
+	require.NoError(t, err)
+	_, err = service.RecordClick("reddit_2", "10.0.0.1", browserAgent)
+	require.NoError(t, err)
+
+	// Link previews are redirected without counting
+	target, err = service.RecordClick("reddit_2", "10.0.0.3", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)")
+	require.NoError(t, err)
+	assert.NotEmpty(t, target)
+	_, err = service.RecordClick("reddit_2", "10.0.0.3", "")
+	require.NoError(t, err)
+
+	_, err = service.RecordClick("missing", "10.0.0.1", browserAgent)
+	assert.ErrorIs(t, err, ErrUnknownMention)
+
+	// The first click is written at once, later ones are buffered until the next flush
+	stored, err := service.loadClicks()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored["reddit_1"].Clicks)
+
+	stats, err := service.ClickStats(1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Clicks, "a repeated click by the same client counts once")
+	assert.Equal(t, 2, stats.Mentions)
+	assert.Equal(t, map[string]int{"reddit": 3}, stats.BySource)
+	require.Len(t, stats.Top, 1)
+	assert.Equal(t, "reddit_1", stats.Top[0].ID)
+	assert.Equal(t, 2, stats.Top[0].Clicks)
+
+	require.NoError(t, service.FlushClicks())
+	stored, err = service.loadClicks()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored["reddit_1"].Clicks)
+	assert.Equal(t, 1, stored["reddit_2"].Clicks)
+}
+
+func TestClickBuffer_Count(t *testing.T) {
+	var buffer clickBuffer
+	now := time.Now()
+
+	assert.True(t, buffer.count("reddit_1", "client", now))
+	assert.False(t, buffer.count("reddit_1", "client", now.Add(time.Minute)))
+	assert.True(t, buffer.count("reddit_1", "client", now.Add(clickDedupeWindow)))
+
+	// A client's clicks beyond the limit aren't counted until its window ends
+	for i := 0; i < clickClientLimit; i++ {
+		buffer.count(fmt.Sprintf("mention_%d", i), "scraper", now)
+	}
+	assert.False(t, buffer.count("reddit_1", "scraper", now))
+	assert.True(t, buffer.count("reddit_1", "reader", now))
+	assert.True(t, buffer.count("reddit_1", "scraper", now.Add(clickDedupeWindow)))
+
+	buffer.prune(now.Add(3 * clickDedupeWindow))
+	assert.Empty(t, buffer.seen)
+	assert.Empty(t, buffer.clients)
+}
@@ -0,0 +1,170 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/usage"
+	"github.com/sirupsen/logrus"
+)
+
+// collectionsPrefix holds the mentions each collection of a source with its own schedule
+// stored, until a report includes them
+const collectionsPrefix = "runs/collected/"
+
+// SourceCollection is what a collection of a source with its own schedule stored, kept until
+// the next report includes it
+type SourceCollection struct {
+	RunID       string           `json:"run_id"`
+	Source      string           `json:"source"`
+	CollectedAt time.Time        `json:"collected_at"`
+	Mentions    []models.Mention `json:"mentions"`
+}
+
+// reportSources returns the sources monitoring runs fetch: those without a schedule of their
+// own in SOURCE_SCHEDULES
+func (s *Service) reportSources() []sources.Source {
+	if len(s.config.SourceSchedules) == 0 {
+		return s.sources
+	}
+
+	var srcs []sources.Source
+	for _, src := range s.sources {
+		if _, ok := s.config.SourceSchedules[src.GetName()]; !ok {
+			srcs = append(srcs, src)
+		}
+	}
+	return srcs
+}
+
+// CollectSource fetches, filters, enriches and stores the mentions of one source without
+// reporting them, for sources collected on their own schedule. A source with a watermark
+// searches from it, others the given window. The next monitoring run reports what was
+// collected along with the mentions of the sources it fetches itself.
+func (s *Service) CollectSource(name string, window time.Duration) error {
+	src := s.findSource(name)
+	if src == nil {
+		return fmt.Errorf("%w %q", ErrUnknownSource, name)
+	}
+
+	runCtx, done, err := s.runs.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	start := time.Now()
+	logrus.Infof("Collecting mentions from %s", name)
+
+	ctx, cancel := context.WithTimeout(runCtx, 30*time.Minute)
+	defer cancel()
+
+	runID := start.Format(runIDLayout)
+	meter := usage.NewMeter()
+	ctx = usage.WithMeter(ctx, meter)
+	defer s.recordRunCost(runID, RunKindCollection, start, meter)
+
+	result := s.runSourcesPipeline(ctx, []sources.Source{src}, runID, s.config.Keywords, s.watermarkWindow(window))
+	s.recordRunErrors(runID, RunKindCollection, start, result.outcomes)
+	s.saveSearchIndex()
+	s.advanceWatermarks(result.latest)
+	s.recordSubreddits(result.mentions)
+	s.exportRunParquet(result.mentions)
+
+	// Whatever was collected waits for the next report, even from an interrupted collection
+	if err := s.recordCollection(SourceCollection{RunID: runID, Source: name, CollectedAt: start, Mentions: result.mentions}); err != nil {
+		return err
+	}
+
+	switch {
+	case runCtx.Err() != nil:
+		return ErrRunInterrupted
+	case result.storeErr != nil:
+		return fmt.Errorf("failed to store mentions from %s: %w", name, result.storeErr)
+	case result.outcomes[name] != nil:
+		return fmt.Errorf("failed to collect %s: %w", name, result.outcomes[name])
+	}
+
+	logrus.Infof("Collected %d mentions from %s in %v for the next report", len(result.mentions), name, time.Since(start))
+	return nil
+}
+
+// recordCollection keeps the mentions of a collection for the next report
+func (s *Service) recordCollection(collection SourceCollection) error {
+	if len(collection.Mentions) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(collection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection: %w", err)
+	}
+	if err := s.storage.Store(collectionsPrefix+collection.RunID+"-"+collection.Source+".json", data); err != nil {
+		return fmt.Errorf("failed to record collection of %s: %w", collection.Source, err)
+	}
+	return nil
+}
+
+// SourceCollections returns the collections of sources with their own schedule that no
+// report has included yet, oldest first
+func (s *Service) SourceCollections() ([]SourceCollection, error) {
+	if s.storage == nil {
+		return nil, nil
+	}
+
+	names, err := s.storage.List(collectionsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source collections: %w", err)
+	}
+	sort.Strings(names)
+
+	var collections []SourceCollection
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := s.storage.Retrieve(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+		var collection SourceCollection
+		if err := json.Unmarshal(data, &collection); err != nil {
+			logrus.Warnf("Skipping unreadable source collection %s: %v", name, err)
+			continue
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
+// withCollected adds the mentions of source collections to a run's mentions, once each
+func withCollected(mentions []models.Mention, collections []SourceCollection) []models.Mention {
+	seen := make(map[string]bool, len(mentions))
+	for _, mention := range mentions {
+		seen[mention.ID] = true
+	}
+	for _, collection := range collections {
+		for _, mention := range collection.Mentions {
+			if !seen[mention.ID] {
+				seen[mention.ID] = true
+				mentions = append(mentions, mention)
+			}
+		}
+	}
+	return mentions
+}
+
+// clearCollections deletes the collections a report has included
+func (s *Service) clearCollections(collections []SourceCollection) {
+	for _, collection := range collections {
+		if err := s.storage.Delete(collectionsPrefix + collection.RunID + "-" + collection.Source + ".json"); err != nil {
+			logrus.Warnf("Failed to clear collection %s of %s: %v", collection.RunID, collection.Source, err)
+		}
+	}
+}
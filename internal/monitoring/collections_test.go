@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CollectSource(t *testing.T) {
+	cfg := &config.Config{
+		ReportSchedule:    "daily",
+		SourceConcurrency: 1,
+		SourceTimeout:     time.Minute,
+		Keywords:          []string{"aks"},
+		SourceSchedules:   map[string]string{"twitter": "@every 2h"},
+	}
+	notifications := testutil.NewRecordingNotificationService()
+	service := NewService(cfg, testutil.NewMemoryStorage(), notifications)
+
+	reddit := &stubSource{name: "reddit", mentions: []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade", CreatedAt: time.Now().Add(-time.Hour)},
+	}}
+	twitter := &stubSource{name: "twitter", mentions: []models.Mention{
+		{ID: "twitter_1", Source: "twitter", Content: "Loving AKS", CreatedAt: time.Now().Add(-time.Hour)},
+	}}
+	service.sources = []sources.Source{reddit, twitter}
+
+	// Scheduled collections store their mentions without reporting them
+	require.NoError(t, service.CollectSource("twitter", 2*time.Hour))
+	assert.Equal(t, 2*time.Hour, twitter.since)
+	twitter.mentions = append(twitter.mentions, models.Mention{ID: "twitter_2", Source: "twitter", Content: "AKS outage?", CreatedAt: time.Now()})
+	require.NoError(t, service.CollectSource("twitter", 2*time.Hour))
+	assert.Empty(t, notifications.Reports())
+
+	collections, err := service.SourceCollections()
+	require.NoError(t, err)
+	require.NotEmpty(t, collections)
+	assert.Equal(t, "twitter", collections[0].Source)
+
+	// The report run skips the scheduled source and reports what it collected, once each
+	twitter.since = 0
+	require.NoError(t, service.RunMonitoring())
+	assert.Zero(t, twitter.since, "scheduled sources are not fetched by the report run")
+	require.Len(t, notifications.Reports(), 1)
+	var ids []string
+	for _, mention := range notifications.Reports()[0].Mentions {
+		ids = append(ids, mention.ID)
+	}
+	assert.ElementsMatch(t, []string{"reddit_1", "twitter_1", "twitter_2"}, ids)
+
+	collections, err = service.SourceCollections()
+	require.NoError(t, err)
+	assert.Empty(t, collections, "reported collections are cleared")
+
+	assert.ErrorIs(t, service.CollectSource("medium", time.Hour), ErrUnknownSource)
+}
+
+func TestService_CollectSource_RecordsSentiment(t *testing.T) {
+	cfg := &config.Config{
+		SourceConcurrency:       1,
+		SourceTimeout:           time.Minute,
+		Keywords:                []string{"aks"},
+		SourceSchedules:         map[string]string{"twitter": "@every 2h"},
+		EnableSentimentAnalysis: true,
+	}
+	service := NewService(cfg, testutil.NewMemoryStorage(), testutil.NewRecordingNotificationService())
+	service.sources = []sources.Source{&stubSource{name: "twitter", mentions: []models.Mention{
+		{ID: "twitter_1", Source: "twitter", Content: "AKS is great, I love it", CreatedAt: time.Now().Add(-time.Hour)},
+	}}}
+
+	// Collections between reports record the day's score too
+	require.NoError(t, service.CollectSource("twitter", 2*time.Hour))
+	trend, err := service.SentimentTrend()
+	require.NoError(t, err)
+	require.NotNil(t, trend)
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), trend.Current.Date)
+	assert.Equal(t, 1, trend.Current.Positive+trend.Current.Neutral+trend.Current.Negative)
+}
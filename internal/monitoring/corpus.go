@@ -0,0 +1,273 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// corpusBlob holds the mentions maintainers labeled as relevant or not, keyed by mention ID
+const corpusBlob = "filter-corpus/labels.json"
+
+// ErrNotLabeled is returned when removing a label from a mention that has none
+var ErrNotLabeled = errors.New("mention not labeled")
+
+// Filter stages a mention can be dropped at, in the order the pipeline applies them
+const (
+	StageBlocklist = "blocklist"
+	StageContext   = "context"
+	StageSpam      = "spam"
+)
+
+// FilterResult is how the current filters treat one labeled mention
+type FilterResult struct {
+	MentionID string  `json:"mention_id"`
+	Source    string  `json:"source"`
+	Title     string  `json:"title"`
+	URL       string  `json:"url"`
+	Relevant  bool    `json:"relevant"`
+	Kept      bool    `json:"kept"`
+	Stage     string  `json:"stage,omitempty"` // StageBlocklist, StageContext or StageSpam for dropped mentions
+	Relevance float64 `json:"relevance"`
+	Note      string  `json:"note,omitempty"`
+}
+
+// FilterEvaluation scores the current filters against the labeled corpus. Relevant mentions
+// are the positive class: precision is the share of kept mentions that are relevant and
+// recall the share of relevant mentions that are kept, both 0 when undefined.
+type FilterEvaluation struct {
+	Labeled        int            `json:"labeled"`
+	TruePositives  int            `json:"true_positives"`
+	FalsePositives int            `json:"false_positives"`
+	TrueNegatives  int            `json:"true_negatives"`
+	FalseNegatives int            `json:"false_negatives"`
+	Precision      float64        `json:"precision"`
+	Recall         float64        `json:"recall"`
+	Misclassified  []FilterResult `json:"misclassified"`
+}
+
+// LabelMention adds a mention to the filter corpus as relevant or not, replacing any earlier
+// label. The mention is looked up among the stored mentions, then the rejection audit, so both
+// false positives and false negatives can be flagged. Rejected mentions only keep a snippet of
+// their content, which is what the filters are replayed against.
+func (s *Service) LabelMention(id string, relevant bool, note string) (*models.LabeledMention, error) {
+	mention, kept, err := s.findLabelCandidate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.corpusMu.Lock()
+	defer s.corpusMu.Unlock()
+
+	corpus, err := s.loadCorpus()
+	if err != nil {
+		return nil, err
+	}
+
+	labeled := models.LabeledMention{
+		Mention:   mention,
+		Relevant:  relevant,
+		Note:      strings.TrimSpace(note),
+		Kept:      kept,
+		LabeledAt: time.Now().UTC(),
+	}
+	corpus[id] = labeled
+	if err := s.saveCorpus(corpus); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Mention %s labeled relevant=%t (kept=%t)", id, relevant, kept)
+	return &labeled, nil
+}
+
+// RemoveLabel drops a mention from the filter corpus
+func (s *Service) RemoveLabel(id string) error {
+	s.corpusMu.Lock()
+	defer s.corpusMu.Unlock()
+
+	corpus, err := s.loadCorpus()
+	if err != nil {
+		return err
+	}
+	if _, ok := corpus[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrNotLabeled, id)
+	}
+	delete(corpus, id)
+	return s.saveCorpus(corpus)
+}
+
+// FilterCorpus returns the labeled mentions, most recently labeled first
+func (s *Service) FilterCorpus() ([]models.LabeledMention, error) {
+	s.corpusMu.Lock()
+	corpus, err := s.loadCorpus()
+	s.corpusMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	labeled := make([]models.LabeledMention, 0, len(corpus))
+	for _, mention := range corpus {
+		labeled = append(labeled, mention)
+	}
+	sort.Slice(labeled, func(i, j int) bool {
+		if !labeled[i].LabeledAt.Equal(labeled[j].LabeledAt) {
+			return labeled[i].LabeledAt.After(labeled[j].LabeledAt)
+		}
+		return labeled[i].Mention.ID < labeled[j].Mention.ID
+	})
+	return labeled, nil
+}
+
+// EvaluateFilters replays the labeled corpus through the current blocklist, context filter and
+// spam heuristics, as configured. Each mention is replayed on its own and without the LLM, so
+// spam signals that depend on the rest of a run (copies and prolific authors) are not replayed.
+func (s *Service) EvaluateFilters() (*FilterEvaluation, error) {
+	corpus, err := s.FilterCorpus()
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := &FilterEvaluation{Labeled: len(corpus), Misclassified: []FilterResult{}}
+	for _, labeled := range corpus {
+		result := s.replayFilters(labeled.Mention)
+		result.Relevant = labeled.Relevant
+		result.Note = labeled.Note
+
+		switch {
+		case result.Kept && labeled.Relevant:
+			evaluation.TruePositives++
+		case result.Kept:
+			evaluation.FalsePositives++
+		case labeled.Relevant:
+			evaluation.FalseNegatives++
+		default:
+			evaluation.TrueNegatives++
+		}
+		if result.Kept != labeled.Relevant {
+			evaluation.Misclassified = append(evaluation.Misclassified, result)
+		}
+	}
+
+	evaluation.Precision = ratio(evaluation.TruePositives, evaluation.TruePositives+evaluation.FalsePositives)
+	evaluation.Recall = ratio(evaluation.TruePositives, evaluation.TruePositives+evaluation.FalseNegatives)
+	return evaluation, nil
+}
+
+// replayFilters runs one mention through the filters the pipeline applies, stopping at the
+// first that drops it
+func (s *Service) replayFilters(mention models.Mention) FilterResult {
+	result := FilterResult{MentionID: mention.ID, Source: mention.Source, Title: mention.Title, URL: mention.URL}
+
+	if _, blocked := s.blocklist().blocks(mention); blocked {
+		result.Stage = StageBlocklist
+		return result
+	}
+
+	decision := s.relevanceDecision(mention)
+	result.Relevance = decision.Relevance
+	if s.config.EnableContextFiltering && !decision.Kept {
+		result.Stage = StageContext
+		return result
+	}
+
+	if s.config.EnableSpamDetection {
+		if verdict := newSpamDetector().evaluate([]models.Mention{mention})[0]; verdict != nil && verdict.Spam {
+			result.Stage = StageSpam
+			return result
+		}
+	}
+
+	result.Kept = true
+	return result
+}
+
+// ratio returns part/whole rounded to three decimals, or 0 when whole is 0
+func ratio(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*1000) / 1000
+}
+
+// findLabelCandidate returns a stored mention, or failing that a mention from the rejection
+// audit, and whether the filters kept it
+func (s *Service) findLabelCandidate(id string) (models.Mention, bool, error) {
+	if doc, ok := s.searchIndex().Document(id); ok {
+		if mention, ok := s.readMentionsBlob(doc.Blob)[id]; ok {
+			return mention, true, nil
+		}
+	}
+
+	names, err := s.storage.List(rejectedPrefix)
+	if err != nil {
+		return models.Mention{}, false, fmt.Errorf("failed to list rejected mentions: %w", err)
+	}
+	// Run IDs sort by time, so the newest rejection of a mention is found first
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for _, name := range names {
+		data, err := s.storage.Retrieve(name)
+		if err != nil {
+			return models.Mention{}, false, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+		var batch []models.RejectedMention
+		if err := json.Unmarshal(data, &batch); err != nil {
+			logrus.Warnf("Skipping unreadable rejected mentions in %s: %v", name, err)
+			continue
+		}
+		for _, rejected := range batch {
+			if rejected.ID == id {
+				return models.Mention{
+					ID:        rejected.ID,
+					Source:    rejected.Source,
+					Title:     rejected.Title,
+					URL:       rejected.URL,
+					Content:   rejected.Snippet,
+					Keywords:  rejected.Keywords,
+					CreatedAt: rejected.CreatedAt,
+				}, false, nil
+			}
+		}
+	}
+
+	return models.Mention{}, false, fmt.Errorf("%w %q", ErrUnknownMention, id)
+}
+
+// loadCorpus reads the labeled mentions keyed by mention ID; callers must hold s.corpusMu
+func (s *Service) loadCorpus() (map[string]models.LabeledMention, error) {
+	corpus := make(map[string]models.LabeledMention)
+	if s.storage == nil {
+		return corpus, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, corpusBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve filter corpus: %w", err)
+	}
+	if !found {
+		return corpus, nil
+	}
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		return nil, fmt.Errorf("failed to parse filter corpus: %w", err)
+	}
+	return corpus, nil
+}
+
+// saveCorpus persists the labeled mentions; callers must hold s.corpusMu
+func (s *Service) saveCorpus(corpus map[string]models.LabeledMention) error {
+	data, err := json.Marshal(corpus)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filter corpus: %w", err)
+	}
+	if err := s.storage.Store(corpusBlob, data); err != nil {
+		return fmt.Errorf("failed to store filter corpus: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_FilterCorpus(t *testing.T) {
+	cfg := &config.Config{ReportSchedule: "daily", EnableContextFiltering: true, EnableRejectionAudit: true}
+	service := &Service{config: cfg, storage: testutil.NewMemoryStorage()}
+
+	require.NoError(t, service.storeMentions([]models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "Upgrading Azure Kubernetes Service clusters", Content: "az aks upgrade went fine"},
+		{ID: "reddit_2", Source: "reddit", Title: "Azure DevOps pipeline tips", Content: "YAML templates for azure devops builds"},
+	}))
+	service.storeRejected("2024-06-03-09-00-00", "reddit", []models.Mention{
+		{ID: "reddit_3", Source: "reddit", Title: "Node pool question", Content: "Our AKS nodes keep running out of memory"},
+	})
+
+	// A true positive, a false positive and a false negative
+	labeled, err := service.LabelMention("reddit_1", true, "")
+	require.NoError(t, err)
+	assert.True(t, labeled.Kept)
+	assert.Equal(t, "az aks upgrade went fine", labeled.Mention.Content)
+	_, err = service.LabelMention("reddit_2", false, " not about AKS ")
+	require.NoError(t, err)
+	labeled, err = service.LabelMention("reddit_3", true, "")
+	require.NoError(t, err)
+	assert.False(t, labeled.Kept)
+	assert.Equal(t, "Our AKS nodes keep running out of memory", labeled.Mention.Content, "rejected mentions are labeled from their snippet")
+
+	_, err = service.LabelMention("missing", true, "")
+	assert.ErrorIs(t, err, ErrUnknownMention)
+
+	corpus, err := service.FilterCorpus()
+	require.NoError(t, err)
+	require.Len(t, corpus, 3)
+	assert.Equal(t, "not about AKS", corpus[1].Note)
+
+	evaluation, err := service.EvaluateFilters()
+	require.NoError(t, err)
+	assert.Equal(t, 3, evaluation.Labeled)
+	assert.Equal(t, 1, evaluation.TruePositives)
+	assert.Equal(t, 1, evaluation.FalsePositives)
+	assert.Equal(t, 1, evaluation.FalseNegatives)
+	assert.Equal(t, 0, evaluation.TrueNegatives)
+	assert.Equal(t, 0.5, evaluation.Precision)
+	assert.Equal(t, 0.5, evaluation.Recall)
+	require.Len(t, evaluation.Misclassified, 2)
+	assert.Equal(t, "reddit_3", evaluation.Misclassified[0].MentionID)
+	assert.Equal(t, StageContext, evaluation.Misclassified[0].Stage)
+	assert.Equal(t, "reddit_2", evaluation.Misclassified[1].MentionID)
+	assert.Empty(t, evaluation.Misclassified[1].Stage)
+
+	// Relabeling replaces the label, and a relevant mention without context filtering is kept
+	_, err = service.LabelMention("reddit_2", true, "")
+	require.NoError(t, err)
+	cfg.EnableContextFiltering = false
+	evaluation, err = service.EvaluateFilters()
+	require.NoError(t, err)
+	assert.Equal(t, 3, evaluation.TruePositives)
+	assert.Equal(t, 1.0, evaluation.Precision)
+	assert.Equal(t, 1.0, evaluation.Recall)
+	assert.Empty(t, evaluation.Misclassified)
+
+	require.NoError(t, service.RemoveLabel("reddit_3"))
+	assert.ErrorIs(t, service.RemoveLabel("reddit_3"), ErrNotLabeled)
+	corpus, err = service.FilterCorpus()
+	require.NoError(t, err)
+	assert.Len(t, corpus, 2)
+}
+
+func TestService_EvaluateFilters_Empty(t *testing.T) {
+	service := &Service{config: &config.Config{}, storage: testutil.NewMemoryStorage()}
+
+	evaluation, err := service.EvaluateFilters()
+	require.NoError(t, err)
+	assert.Zero(t, evaluation.Labeled)
+	assert.Zero(t, evaluation.Precision)
+	assert.Zero(t, evaluation.Recall)
+}
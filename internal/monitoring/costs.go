@@ -0,0 +1,174 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/usage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// runCostsBlob holds the paid-API usage and estimated cost of recent runs
+	runCostsBlob = "costs/runs.json"
+	// runCostRetention is how long run costs are kept
+	runCostRetention = 90 * 24 * time.Hour
+	// costSummaryWindow is the trailing window cost totals cover
+	costSummaryWindow = 30 * 24 * time.Hour
+)
+
+// Kinds of runs whose cost is recorded
+const (
+	RunKindMonitoring = "monitoring"
+	RunKindUrgent     = "urgent"
+	RunKindCollection = "collection" // A source collected on its own schedule
+)
+
+// Bounds on the runs listed by RunCosts
+const (
+	DefaultRunCostLimit = 50
+	MaxRunCostLimit     = 500
+)
+
+// RunCost is the paid-API usage of one run and its estimated cost
+type RunCost struct {
+	RunID     string      `json:"run_id"`
+	Kind      string      `json:"kind"`
+	StartedAt time.Time   `json:"started_at"`
+	Usage     usage.Usage `json:"usage"`
+	Cost      usage.Cost  `json:"estimated_cost"`
+}
+
+// CostSummary totals the usage and estimated cost of the runs in the last 30 days, with the
+// most recent runs newest first
+type CostSummary struct {
+	Since  time.Time   `json:"since"`
+	Runs   int         `json:"runs"`
+	Usage  usage.Usage `json:"usage"`
+	Cost   usage.Cost  `json:"estimated_cost"`
+	Recent []RunCost   `json:"recent"`
+}
+
+// prices returns the configured unit prices
+func (s *Service) prices() usage.Prices {
+	return usage.Prices{
+		TwitterPerRequest:           s.config.CostTwitterPerRequest,
+		TwitterPerPost:              s.config.CostTwitterPerPost,
+		OpenAIPromptPer1KTokens:     s.config.CostOpenAIPromptPer1K,
+		OpenAICompletionPer1KTokens: s.config.CostOpenAICompletionPer1K,
+	}
+}
+
+// recordRunCost estimates the cost of the usage metered during a run, adds it to the metrics
+// and stores it. Failures are logged so they never fail a run.
+func (s *Service) recordRunCost(runID, kind string, start time.Time, meter *usage.Meter) {
+	run := RunCost{RunID: runID, Kind: kind, StartedAt: start, Usage: meter.Usage()}
+	run.Cost = s.prices().Estimate(run.Usage)
+	logrus.Infof("Estimated cost of %s run %s: $%.4f (%d X API requests, %d OpenAI tokens)",
+		kind, runID, run.Cost.Total, run.Usage.TwitterRequests, run.Usage.OpenAIPromptTokens+run.Usage.OpenAICompletionTokens)
+
+	var summary *CostSummary
+	if s.storage != nil {
+		summary = s.storeRunCost(run)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kind == RunKindMonitoring {
+		s.metrics.LastRunUsage = &run.Usage
+		s.metrics.LastRunCost = &run.Cost
+	}
+	if summary != nil {
+		s.metrics.EstimatedCost30Days = &summary.Cost
+	}
+}
+
+// storeRunCost adds a run to the stored run costs, dropping those past retention, and
+// summarizes them. Nothing is stored when the history can't be loaded, since storing the
+// run alone would replace it.
+func (s *Service) storeRunCost(run RunCost) *CostSummary {
+	s.costsMu.Lock()
+	defer s.costsMu.Unlock()
+
+	runs, err := s.loadRunCosts()
+	if err != nil {
+		logrus.Warnf("Failed to load run costs, not recording run %s: %v", run.RunID, err)
+		return nil
+	}
+
+	cutoff := time.Now().Add(-runCostRetention)
+	kept := runs[:0]
+	for _, previous := range runs {
+		if previous.StartedAt.After(cutoff) {
+			kept = append(kept, previous)
+		}
+	}
+	runs = append(kept, run)
+
+	if data, err := json.Marshal(runs); err != nil {
+		logrus.Warnf("Failed to marshal run costs: %v", err)
+	} else if err := s.storage.Store(runCostsBlob, data); err != nil {
+		logrus.Warnf("Failed to store run costs: %v", err)
+	}
+	return summarizeRunCosts(runs, 0)
+}
+
+// RunCosts returns the usage and estimated cost of the runs in the last 30 days, listing up
+// to limit of the most recent ones
+func (s *Service) RunCosts(limit int) (*CostSummary, error) {
+	if limit <= 0 {
+		limit = DefaultRunCostLimit
+	}
+	if limit > MaxRunCostLimit {
+		limit = MaxRunCostLimit
+	}
+
+	s.costsMu.Lock()
+	runs, err := s.loadRunCosts()
+	s.costsMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return summarizeRunCosts(runs, limit), nil
+}
+
+// summarizeRunCosts totals the runs, oldest first, started in the summary window and lists
+// up to limit of them newest first
+func summarizeRunCosts(runs []RunCost, limit int) *CostSummary {
+	summary := &CostSummary{Since: time.Now().Add(-costSummaryWindow), Recent: []RunCost{}}
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.StartedAt.Before(summary.Since) {
+			continue
+		}
+		summary.Runs++
+		summary.Usage = summary.Usage.Add(run.Usage)
+		summary.Cost = summary.Cost.Add(run.Cost)
+		if len(summary.Recent) < limit {
+			summary.Recent = append(summary.Recent, run)
+		}
+	}
+	return summary
+}
+
+// loadRunCosts reads the stored run costs, oldest first; callers must hold s.costsMu
+func (s *Service) loadRunCosts() ([]RunCost, error) {
+	if s.storage == nil {
+		return nil, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, runCostsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve run costs: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var runs []RunCost
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse run costs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,114 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/azure/aks-mentions-bot/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCostsService() *Service {
+	return &Service{
+		config: &config.Config{
+			CostTwitterPerPost:        0.01,
+			CostOpenAIPromptPer1K:     0.001,
+			CostOpenAICompletionPer1K: 0.002,
+		},
+		storage: testutil.NewMemoryStorage(),
+		metrics: &Metrics{},
+	}
+}
+
+func TestService_RecordRunCost(t *testing.T) {
+	service := newCostsService()
+
+	meter := usage.NewMeter()
+	meter.AddTwitter(2, 150)
+	meter.AddOpenAI(4000, 500)
+	service.recordRunCost("2024-06-03-09-00-00", RunKindMonitoring, time.Now().Add(-time.Hour), meter)
+
+	urgent := usage.NewMeter()
+	urgent.AddTwitter(1, 10)
+	service.recordRunCost("2024-06-03-10-00-00", RunKindUrgent, time.Now(), urgent)
+
+	var metrics Metrics
+	require.NoError(t, json.Unmarshal([]byte(service.GetMetrics()), &metrics))
+	require.NotNil(t, metrics.LastRunCost)
+	assert.Equal(t, usage.Cost{Twitter: 1.5, OpenAI: 0.005, Total: 1.505}, *metrics.LastRunCost)
+	assert.Equal(t, 2, metrics.LastRunUsage.TwitterRequests)
+	require.NotNil(t, metrics.EstimatedCost30Days)
+	assert.Equal(t, 1.605, metrics.EstimatedCost30Days.Total)
+
+	summary, err := service.RunCosts(0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Runs)
+	assert.Equal(t, 160, summary.Usage.TwitterPosts)
+	assert.Equal(t, 1.605, summary.Cost.Total)
+	require.Len(t, summary.Recent, 2)
+	assert.Equal(t, RunKindUrgent, summary.Recent[0].Kind)
+
+	summary, err = service.RunCosts(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Runs)
+	assert.Len(t, summary.Recent, 1)
+}
+
+func TestService_RecordRunCostKeepsUnreadableHistory(t *testing.T) {
+	service := newCostsService()
+	require.NoError(t, service.storage.Store(runCostsBlob, []byte("{")))
+
+	meter := usage.NewMeter()
+	meter.AddTwitter(1, 10)
+	service.recordRunCost("2024-06-03-09-00-00", RunKindMonitoring, time.Now(), meter)
+
+	stored, err := service.storage.Retrieve(runCostsBlob)
+	require.NoError(t, err)
+	assert.Equal(t, "{", string(stored), "a transient load error doesn't replace the history")
+
+	var metrics Metrics
+	require.NoError(t, json.Unmarshal([]byte(service.GetMetrics()), &metrics))
+	require.NotNil(t, metrics.LastRunCost, "the run's own cost is still reported")
+	assert.Nil(t, metrics.EstimatedCost30Days)
+}
+
+func TestService_RecordRunCostDropsExpiredRuns(t *testing.T) {
+	service := newCostsService()
+
+	old := usage.NewMeter()
+	old.AddTwitter(1, 100)
+	service.recordRunCost("old", RunKindMonitoring, time.Now().Add(-runCostRetention-time.Hour), old)
+	service.recordRunCost("last-month", RunKindMonitoring, time.Now().Add(-costSummaryWindow-time.Hour), old)
+	service.recordRunCost("today", RunKindUrgent, time.Now(), usage.NewMeter())
+
+	service.costsMu.Lock()
+	runs, err := service.loadRunCosts()
+	service.costsMu.Unlock()
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "last-month", runs[0].RunID)
+
+	// Runs older than the summary window are kept but not totalled
+	summary, err := service.RunCosts(0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Runs)
+	assert.Zero(t, summary.Cost.Total)
+}
+
+func TestService_RunMonitoringRecordsCost(t *testing.T) {
+	service := newCostsService()
+	service.config.ReportSchedule = "daily"
+	service.notificationService = testutil.NewRecordingNotificationService()
+	service.sourceHealth = make(map[string]*SourceStatus)
+
+	require.NoError(t, service.RunMonitoring())
+
+	summary, err := service.RunCosts(0)
+	require.NoError(t, err)
+	require.Len(t, summary.Recent, 1)
+	assert.Equal(t, RunKindMonitoring, summary.Recent[0].Kind)
+}
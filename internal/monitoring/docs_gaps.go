@@ -0,0 +1,280 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/docs"
+	"github.com/azure/aks-mentions-bot/internal/matching"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// DocsGapTag is the curated tag on questions no Azure documentation page covers well
+const DocsGapTag = "docs-gap"
+
+// docsGapsStateBlob records the last month a report listed documentation gaps for
+const docsGapsStateBlob = "docs/gaps_reported.json"
+
+const (
+	// docsMatchThreshold is the share of a question's title terms a docs page's title and
+	// description must contain to cover the question
+	docsMatchThreshold = 0.5
+	// docsMinTerms is how many title terms a question needs to be judged at all
+	docsMinTerms = 2
+	// docsGapMinQuestions is how many gap questions a topic needs in a month to be listed
+	docsGapMinQuestions = 2
+	// Bounds on the documentation gaps section
+	maxDocsGapTopics   = 10
+	maxDocsGapExamples = 3
+	// docsCheckedSize bounds the questions remembered as checked, so repeat finds skip the search
+	docsCheckedSize = 5000
+)
+
+type docsGapsState struct {
+	Month string `json:"month"` // "2006-01"
+}
+
+// questionTerms are the notable title terms of a question, as used for topics
+func questionTerms(mention models.Mention, keywordTokens map[string]bool) []string {
+	var terms []string
+	for _, topic := range mentionTopics(models.Mention{Title: mention.Title}, keywordTokens) {
+		terms = append(terms, strings.TrimPrefix(topic, "term:"))
+	}
+	return terms
+}
+
+// docsCoverage is the largest share of the terms found in any result's title and description
+func docsCoverage(terms []string, results []docs.Result) float64 {
+	best := 0.0
+	for _, result := range results {
+		words := make(map[string]bool)
+		for _, token := range matching.Tokens(result.Title + " " + result.Description) {
+			words[token] = true
+		}
+		found := 0
+		for _, term := range terms {
+			if words[term] {
+				found++
+			}
+		}
+		if coverage := float64(found) / float64(len(terms)); coverage > best {
+			best = coverage
+		}
+	}
+	return best
+}
+
+// detectDocsGaps searches the docs for questions in a batch, up to *lookups searches, and
+// tags the questions no page covers with DocsGapTag. Failures are logged so they never block
+// a run.
+func (s *Service) detectDocsGaps(ctx context.Context, mentions []models.Mention, lookups *int) {
+	if s.docs == nil || !paidEnrichment(ctx) {
+		return
+	}
+
+	keywordTokens := s.keywordTokens()
+	var gaps []string
+	for i := range mentions {
+		mention := &mentions[i]
+		if !mention.IsQuestion {
+			continue
+		}
+
+		gap, checked := s.docsChecked.Get(mention.ID)
+		if !checked {
+			terms := questionTerms(*mention, keywordTokens)
+			if len(terms) < docsMinTerms {
+				continue
+			}
+			if *lookups <= 0 {
+				logrus.Debugf("Docs gap lookups capped at %d per run", s.config.DocsGapMaxLookups)
+				continue
+			}
+			*lookups--
+
+			results, err := s.docs.Search(ctx, mention.Title)
+			if err != nil {
+				logrus.Warnf("Failed to search docs for %s: %v", mention.ID, err)
+				continue
+			}
+			gap = docsCoverage(terms, results) < docsMatchThreshold
+			s.docsChecked.Add(mention.ID, gap)
+		}
+
+		if gap {
+			gaps = append(gaps, mention.ID)
+			if !containsString(mention.Tags, DocsGapTag) {
+				mention.Tags = append(mention.Tags, DocsGapTag)
+			}
+		}
+	}
+
+	if len(gaps) > 0 {
+		s.tagDocsGaps(gaps)
+	}
+}
+
+// tagDocsGaps adds DocsGapTag to the curated tags of the mentions
+func (s *Service) tagDocsGaps(ids []string) {
+	if s.storage == nil {
+		return
+	}
+
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+
+	tags, err := s.loadTags()
+	if err != nil {
+		logrus.Warnf("Failed to load mention tags: %v", err)
+		return
+	}
+	added := 0
+	for _, id := range ids {
+		if !containsString(tags[id], DocsGapTag) {
+			tags[id] = append(tags[id], DocsGapTag)
+			sort.Strings(tags[id])
+			added++
+		}
+	}
+	if added == 0 {
+		return
+	}
+	if err := s.saveTags(tags); err != nil {
+		logrus.Warnf("Failed to save docs gap tags: %v", err)
+		return
+	}
+	logrus.Infof("Tagged %d questions as documentation gaps", added)
+}
+
+// monthlyDocsGaps summarizes the previous month's documentation gaps for the first report of
+// a month. It returns the month too, for markDocsGapsReported once the report is sent, or
+// nil and "" when a report already listed them or docs gap detection is off.
+func (s *Service) monthlyDocsGaps(now time.Time) (*models.DocsGapSummary, string) {
+	if s.docs == nil || s.storage == nil {
+		return nil, ""
+	}
+
+	to := bucketStart(now, BucketMonth)
+	from := to.AddDate(0, -1, 0)
+	month := from.Format("2006-01")
+
+	reported, err := s.docsGapsReportedMonth()
+	if err != nil {
+		logrus.Warnf("Failed to load documentation gaps state: %v", err)
+		return nil, ""
+	}
+	if reported == month {
+		return nil, ""
+	}
+
+	mentions, err := s.mentionsBetween(from, to)
+	if err != nil {
+		logrus.Warnf("Failed to load mentions for documentation gaps: %v", err)
+		return nil, ""
+	}
+	s.applyTags(mentions)
+
+	var questions []models.Mention
+	for _, mention := range mentions {
+		if containsString(mention.Tags, DocsGapTag) {
+			questions = append(questions, mention)
+		}
+	}
+	topics := groupDocsGaps(questions, s.keywordTokens())
+	if len(topics) == 0 {
+		return nil, month
+	}
+	return &models.DocsGapSummary{Month: from.Format("January 2006"), Topics: topics}, month
+}
+
+// docsGapsReportedMonth returns the last month a report covered, "" before the first
+func (s *Service) docsGapsReportedMonth() (string, error) {
+	data, found, err := storage.RetrieveIfExists(s.storage, docsGapsStateBlob)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve %s: %w", docsGapsStateBlob, err)
+	}
+	if !found {
+		return "", nil
+	}
+	var state docsGapsState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", docsGapsStateBlob, err)
+	}
+	return state.Month, nil
+}
+
+// markDocsGapsReported records that a report covered the documentation gaps of the month
+func (s *Service) markDocsGapsReported(month string) {
+	if month == "" {
+		return
+	}
+
+	data, err := json.Marshal(docsGapsState{Month: month})
+	if err == nil {
+		err = s.storage.Store(docsGapsStateBlob, data)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to record documentation gaps report: %v", err)
+	}
+}
+
+// groupDocsGaps assigns each question to its title term asked about most often and lists the
+// terms with at least docsGapMinQuestions questions, most asked first
+func groupDocsGaps(questions []models.Mention, keywordTokens map[string]bool) []models.DocsGap {
+	terms := make([][]string, len(questions))
+	counts := make(map[string]int)
+	for i, question := range questions {
+		terms[i] = questionTerms(question, keywordTokens)
+		for _, term := range terms[i] {
+			counts[term]++
+		}
+	}
+
+	groups := make(map[string]*models.DocsGap)
+	for i, question := range questions {
+		topic := ""
+		for _, term := range terms[i] {
+			if topic == "" || counts[term] > counts[topic] || (counts[term] == counts[topic] && term < topic) {
+				topic = term
+			}
+		}
+		if topic == "" {
+			continue
+		}
+		if groups[topic] == nil {
+			groups[topic] = &models.DocsGap{Topic: topic}
+		}
+		groups[topic].Count++
+		groups[topic].Questions = append(groups[topic].Questions, question)
+	}
+
+	gaps := []models.DocsGap{}
+	for _, group := range groups {
+		if group.Count < docsGapMinQuestions {
+			continue
+		}
+		sort.Slice(group.Questions, func(a, b int) bool {
+			return group.Questions[a].CreatedAt.After(group.Questions[b].CreatedAt)
+		})
+		if len(group.Questions) > maxDocsGapExamples {
+			group.Questions = group.Questions[:maxDocsGapExamples]
+		}
+		gaps = append(gaps, *group)
+	}
+	sort.Slice(gaps, func(a, b int) bool {
+		if gaps[a].Count != gaps[b].Count {
+			return gaps[a].Count > gaps[b].Count
+		}
+		return gaps[a].Topic < gaps[b].Topic
+	})
+	if len(gaps) > maxDocsGapTopics {
+		gaps = gaps[:maxDocsGapTopics]
+	}
+	return gaps
+}
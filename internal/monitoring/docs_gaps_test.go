@@ -0,0 +1,109 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/docs"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDocsServer(t *testing.T, searches *int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*searches++
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Query().Get("search"), "upgrade") {
+			w.Write([]byte(`{"results": [{"title": "Upgrade an AKS cluster", "url": "https://learn.microsoft.com/azure/aks/upgrade-cluster",
+				"description": "Learn how to upgrade the Kubernetes version of an AKS cluster and its node pools."}]}`))
+			return
+		}
+		w.Write([]byte(`{"results": [{"title": "What is AKS?", "url": "https://learn.microsoft.com/azure/aks/what-is-aks", "description": "An overview."}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestService_detectDocsGaps(t *testing.T) {
+	var searches int
+	server := newDocsServer(t, &searches)
+	store := testutil.NewMemoryStorage()
+	service := &Service{
+		config:      &config.Config{Keywords: []string{"AKS"}, DocsGapMaxLookups: 2},
+		storage:     store,
+		docs:        docs.NewSearcher(server.URL),
+		docsChecked: cache.NewLRU[string, bool](10),
+	}
+
+	mentions := []models.Mention{
+		{ID: "so_1", Title: "How to upgrade AKS node pools?", IsQuestion: true},
+		{ID: "so_2", Title: "GPU quota errors when scaling AKS", IsQuestion: true},
+		{ID: "so_3", Title: "Private link DNS resolution fails", IsQuestion: true},
+		{ID: "reddit_1", Title: "We love AKS networking", IsQuestion: false},
+		{ID: "reddit_2", Title: "AKS?", IsQuestion: true},
+	}
+	lookups := service.config.DocsGapMaxLookups
+	service.detectDocsGaps(context.Background(), mentions, &lookups)
+
+	assert.Equal(t, 2, searches, "lookups are capped per run")
+	assert.Empty(t, mentions[0].Tags, "the upgrade docs cover the question")
+	assert.Equal(t, []string{DocsGapTag}, mentions[1].Tags)
+	assert.Empty(t, mentions[2].Tags, "over the lookup budget")
+	assert.Empty(t, mentions[4].Tags, "too few terms to judge")
+
+	tags, err := service.loadTags()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"so_2": {DocsGapTag}}, tags)
+
+	// Questions already checked are not searched again
+	lookups = 5
+	again := []models.Mention{{ID: "so_2", Title: "GPU quota errors when scaling AKS", IsQuestion: true}}
+	service.detectDocsGaps(context.Background(), again, &lookups)
+	assert.Equal(t, 2, searches)
+	assert.Equal(t, []string{DocsGapTag}, again[0].Tags)
+}
+
+func TestService_monthlyDocsGaps(t *testing.T) {
+	var searches int
+	server := newDocsServer(t, &searches)
+	store := testutil.NewMemoryStorage()
+	service := &Service{
+		config:  &config.Config{Keywords: []string{"AKS"}},
+		storage: store,
+		index:   storage.NewMentionIndex(store),
+		docs:    docs.NewSearcher(server.URL),
+	}
+
+	july := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, service.storeMentionBatch("run1", "stackoverflow", []models.Mention{
+		{ID: "so_1", Source: "stackoverflow", Title: "GPU quota errors on AKS", CreatedAt: july, IsQuestion: true},
+		{ID: "so_2", Source: "stackoverflow", Title: "Requesting GPU quota for AKS node pools", CreatedAt: july.Add(time.Hour), IsQuestion: true},
+		{ID: "so_3", Source: "stackoverflow", Title: "Private link DNS resolution fails", CreatedAt: july, IsQuestion: true},
+		{ID: "so_4", Source: "stackoverflow", Title: "GPU drivers", CreatedAt: time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC), IsQuestion: true},
+	}))
+	service.tagDocsGaps([]string{"so_1", "so_2", "so_3", "so_4"})
+
+	summary, month := service.monthlyDocsGaps(time.Date(2024, 8, 5, 9, 0, 0, 0, time.UTC))
+	require.NotNil(t, summary)
+	assert.Equal(t, "2024-07", month)
+	assert.Equal(t, "July 2024", summary.Month)
+	require.Len(t, summary.Topics, 1, "topics asked about once are left out")
+	assert.Equal(t, "gpu", summary.Topics[0].Topic)
+	assert.Equal(t, 2, summary.Topics[0].Count)
+	assert.Equal(t, "so_2", summary.Topics[0].Questions[0].ID, "newest first")
+
+	// Only the first report of the month lists them
+	service.markDocsGapsReported(month)
+	summary, month = service.monthlyDocsGaps(time.Date(2024, 8, 12, 9, 0, 0, 0, time.UTC))
+	assert.Nil(t, summary)
+	assert.Empty(t, month)
+}
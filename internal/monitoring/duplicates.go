@@ -0,0 +1,285 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// duplicatesBlob remembers the fingerprints of recent mentions, so copies posted in later
+	// runs are collapsed into the mention reported first
+	duplicatesBlob = "duplicates/fingerprints.json"
+	// nearDuplicateMinLength is the shortest normalized text fingerprinted, so short posts
+	// such as "AKS is down?" are not collapsed into each other
+	nearDuplicateMinLength = 80
+	// fingerprintShingle is the number of consecutive words hashed together
+	fingerprintShingle = 3
+)
+
+// duplicateCluster is a mention and the near-duplicates collapsed into it
+type duplicateCluster struct {
+	Fingerprint string    `json:"fingerprint"`
+	ID          string    `json:"id"` // The mention reported for the cluster
+	Source      string    `json:"source"`
+	Title       string    `json:"title"`
+	Count       int       `json:"count"`         // Times the content was posted, the first post included
+	IDs         []string  `json:"ids,omitempty"` // Collapsed copies
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+
+	hash    uint64
+	earlier bool     // Loaded from an earlier run
+	added   []string // Copies collapsed into it this run
+}
+
+// duplicateCollapser groups the mentions of a run by content fingerprint, keeping the first
+// mention of each group and counting the rest. It is not safe for concurrent use.
+type duplicateCollapser struct {
+	maxDistance int
+	clusters    []*duplicateCluster
+	members     map[string]*duplicateCluster // By mention ID, reported mentions and copies alike
+}
+
+func newDuplicateCollapser(maxDistance int) *duplicateCollapser {
+	return &duplicateCollapser{maxDistance: maxDistance, members: make(map[string]*duplicateCluster)}
+}
+
+// add records a cluster and its members
+func (c *duplicateCollapser) add(cluster *duplicateCluster) {
+	c.clusters = append(c.clusters, cluster)
+	c.members[cluster.ID] = cluster
+	for _, id := range cluster.IDs {
+		c.members[id] = cluster
+	}
+}
+
+// collapse fingerprints a batch and returns the mentions to keep: those whose content was not
+// seen before in this run or, with earlier fingerprints loaded, recent runs. Copies are
+// counted on the mention they duplicate. Mentions seen again, e.g. when fetch windows
+// overlap, are kept or dropped as they were the first time without being counted again.
+func (c *duplicateCollapser) collapse(mentions []models.Mention) []models.Mention {
+	now := time.Now().UTC()
+	kept := mentions[:0]
+	for _, mention := range mentions {
+		hash, ok := contentFingerprint(mention.Title, mention.Content)
+		if !ok {
+			kept = append(kept, mention)
+			continue
+		}
+		mention.Fingerprint = fmt.Sprintf("%016x", hash)
+
+		if cluster, seen := c.members[mention.ID]; seen {
+			if cluster.ID == mention.ID {
+				kept = append(kept, mention)
+			}
+			continue
+		}
+
+		if cluster := c.nearest(hash); cluster != nil {
+			cluster.Count++
+			cluster.IDs = append(cluster.IDs, mention.ID)
+			cluster.added = append(cluster.added, mention.ID)
+			cluster.LastSeen = now
+			c.members[mention.ID] = cluster
+			logrus.Debugf("Collapsed %s into near-duplicate %s (%d copies)", mention.ID, cluster.ID, cluster.Count)
+			continue
+		}
+
+		c.add(&duplicateCluster{
+			Fingerprint: mention.Fingerprint,
+			ID:          mention.ID,
+			Source:      mention.Source,
+			Title:       mention.Title,
+			Count:       1,
+			FirstSeen:   now,
+			LastSeen:    now,
+			hash:        hash,
+		})
+		kept = append(kept, mention)
+	}
+	return kept
+}
+
+// nearest returns the cluster whose fingerprint is closest to hash within the maximum
+// distance, or nil
+func (c *duplicateCollapser) nearest(hash uint64) *duplicateCluster {
+	var best *duplicateCluster
+	bestDistance := c.maxDistance + 1
+	for _, cluster := range c.clusters {
+		if distance := bits.OnesCount64(cluster.hash ^ hash); distance < bestDistance {
+			best, bestDistance = cluster, distance
+		}
+	}
+	return best
+}
+
+// annotate records on the kept mentions how often their content was posted
+func (c *duplicateCollapser) annotate(mentions []models.Mention) {
+	for i := range mentions {
+		cluster := c.members[mentions[i].ID]
+		if cluster == nil || cluster.ID != mentions[i].ID || cluster.Count < 2 {
+			continue
+		}
+		mentions[i].DuplicateCount = cluster.Count
+		mentions[i].DuplicateIDs = append([]string(nil), cluster.IDs...)
+	}
+}
+
+// collapsed returns how many mentions this run collapsed
+func (c *duplicateCollapser) collapsed() int {
+	total := 0
+	for _, cluster := range c.clusters {
+		total += len(cluster.added)
+	}
+	return total
+}
+
+// contentFingerprint returns the 64-bit SimHash of a mention's words, hashed in overlapping
+// shingles so reordered or lightly edited copies land a few bits apart. Links are ignored,
+// as copies often differ only in tracking parameters. Text too short to compare reports false.
+func contentFingerprint(title, content string) (uint64, bool) {
+	text := linkPattern.ReplaceAllString(strings.ToLower(title+" "+content), " ")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(strings.Join(words, " ")) < nearDuplicateMinLength {
+		return 0, false
+	}
+
+	var weights [64]int
+	for i := 0; i+fingerprintShingle <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+fingerprintShingle], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash, true
+}
+
+// newRunDuplicates returns the collapser for a monitoring run, primed with the fingerprints
+// remembered from recent runs, or nil when near-duplicate collapsing is off
+func (s *Service) newRunDuplicates() *duplicateCollapser {
+	if !s.config.EnableNearDuplicates {
+		return nil
+	}
+	collapser := newDuplicateCollapser(s.config.NearDuplicateDistance)
+
+	s.duplicatesMu.Lock()
+	clusters, err := s.loadDuplicates()
+	s.duplicatesMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load near-duplicate fingerprints, collapsing within this run only: %v", err)
+		return collapser
+	}
+	for _, cluster := range clusters {
+		cluster.earlier = true
+		collapser.add(cluster)
+	}
+	return collapser
+}
+
+// saveDuplicates remembers the run's fingerprints for later runs, merging them with those
+// stored by runs that finished in the meantime and forgetting those not seen within
+// NEAR_DUPLICATE_WINDOW
+func (s *Service) saveDuplicates(collapser *duplicateCollapser) {
+	if collapser == nil || s.storage == nil || s.config.NearDuplicateWindow == 0 {
+		return
+	}
+
+	s.duplicatesMu.Lock()
+	defer s.duplicatesMu.Unlock()
+
+	stored, err := s.loadDuplicates()
+	if err != nil {
+		logrus.Warnf("Failed to save near-duplicate fingerprints: %v", err)
+		return
+	}
+	byID := make(map[string]*duplicateCluster, len(stored))
+	for _, cluster := range stored {
+		byID[cluster.ID] = cluster
+	}
+	for _, cluster := range collapser.clusters {
+		existing, ok := byID[cluster.ID]
+		if !ok {
+			stored = append(stored, cluster)
+			byID[cluster.ID] = cluster
+			continue
+		}
+		if cluster.earlier && len(cluster.added) > 0 {
+			existing.Count += len(cluster.added)
+			existing.IDs = append(existing.IDs, cluster.added...)
+			existing.LastSeen = cluster.LastSeen
+		}
+	}
+
+	cutoff := time.Now().Add(-s.config.NearDuplicateWindow)
+	recent := stored[:0]
+	for _, cluster := range stored {
+		if cluster.LastSeen.After(cutoff) {
+			recent = append(recent, cluster)
+		}
+	}
+
+	data, err := json.Marshal(recent)
+	if err != nil {
+		logrus.Warnf("Failed to marshal near-duplicate fingerprints: %v", err)
+		return
+	}
+	if err := s.storage.Store(duplicatesBlob, data); err != nil {
+		logrus.Warnf("Failed to save near-duplicate fingerprints: %v", err)
+	}
+}
+
+// loadDuplicates reads the remembered fingerprints; callers must hold s.duplicatesMu
+func (s *Service) loadDuplicates() ([]*duplicateCluster, error) {
+	if s.storage == nil || s.config.NearDuplicateWindow == 0 {
+		return nil, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, duplicatesBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve near-duplicate fingerprints: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var clusters []*duplicateCluster
+	if err := json.Unmarshal(data, &clusters); err != nil {
+		return nil, fmt.Errorf("failed to parse near-duplicate fingerprints: %w", err)
+	}
+
+	cutoff := time.Now().Add(-s.config.NearDuplicateWindow)
+	recent := clusters[:0]
+	for _, cluster := range clusters {
+		hash, err := strconv.ParseUint(cluster.Fingerprint, 16, 64)
+		if err != nil || !cluster.LastSeen.After(cutoff) {
+			continue
+		}
+		cluster.hash = hash
+		recent = append(recent, cluster)
+	}
+	return recent, nil
+}
@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"context"
+	"math/bits"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const announcement = "Announcing the general availability of node auto provisioning in Azure Kubernetes Service. " +
+	"AKS now picks the right virtual machine sizes for your pending pods and scales them down when idle. " +
+	"Read the full announcement on the Azure blog to get started today."
+
+func TestContentFingerprint(t *testing.T) {
+	original, ok := contentFingerprint("Node auto provisioning is GA", announcement+" https://aka.ms/aks/nap?utm_source=x")
+	require.True(t, ok)
+	copied, ok := contentFingerprint("Node auto provisioning is GA!", announcement+" https://aka.ms/aks/nap?utm_source=linkedin #AKS")
+	require.True(t, ok)
+	unrelated, ok := contentFingerprint("AKS upgrade broke our ingress",
+		"After upgrading the cluster to 1.29 the NGINX ingress controller stopped routing traffic to half of our services and we had to roll back.")
+	require.True(t, ok)
+
+	assert.LessOrEqual(t, bits.OnesCount64(original^copied), 3, "copies with other links and punctuation land a few bits apart")
+	assert.Greater(t, bits.OnesCount64(original^unrelated), 10)
+
+	_, ok = contentFingerprint("AKS is down?", "Anyone else?")
+	assert.False(t, ok, "short posts are not fingerprinted")
+}
+
+func TestService_runPipeline_collapsesNearDuplicates(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{
+		config: &config.Config{
+			EnableNearDuplicates:  true,
+			NearDuplicateDistance: 3,
+			NearDuplicateWindow:   24 * time.Hour,
+			SourceConcurrency:     1,
+			SourceTimeout:         time.Second,
+		},
+		storage: storage,
+	}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "Node auto provisioning is GA", Content: announcement},
+			{ID: "reddit_2", Source: "reddit", Title: "AKS upgrade", Content: "Azure Kubernetes Service upgrade went fine"},
+		}},
+		&stubSource{name: "twitter", mentions: []models.Mention{
+			{ID: "twitter_1", Source: "twitter", Title: "Node auto provisioning is GA!", Content: announcement + " #AKS"},
+			{ID: "twitter_2", Source: "twitter", Title: "Node auto provisioning is GA", Content: announcement},
+		}},
+	}
+
+	result := service.runPipeline(context.Background(), time.Now().Format(runIDLayout), []string{"aks"}, fixedWindow(time.Hour))
+	require.Len(t, result.mentions, 2)
+	assert.Equal(t, "reddit_1", result.mentions[0].ID)
+	assert.Equal(t, 3, result.mentions[0].DuplicateCount)
+	assert.Equal(t, []string{"twitter_1", "twitter_2"}, result.mentions[0].DuplicateIDs)
+	assert.NotEmpty(t, result.mentions[0].Fingerprint)
+	assert.Zero(t, result.mentions[1].DuplicateCount)
+
+	// A later run collapses another copy into the mention reported first, and doesn't count
+	// mentions fetched again because fetch windows overlap
+	service.sources = []sources.Source{
+		&stubSource{name: "linkedin", mentions: []models.Mention{
+			{ID: "linkedin_1", Source: "linkedin", Title: "Node auto provisioning is GA", Content: announcement},
+		}},
+		&stubSource{name: "twitter", mentions: []models.Mention{
+			{ID: "twitter_1", Source: "twitter", Title: "Node auto provisioning is GA!", Content: announcement + " #AKS"},
+		}},
+	}
+	result = service.runPipeline(context.Background(), time.Now().Format(runIDLayout), []string{"aks"}, fixedWindow(time.Hour))
+	assert.Empty(t, result.mentions)
+
+	service.duplicatesMu.Lock()
+	clusters, err := service.loadDuplicates()
+	service.duplicatesMu.Unlock()
+	require.NoError(t, err)
+	require.Len(t, clusters, 1, "mentions too short to fingerprint are not remembered")
+	assert.Equal(t, "reddit_1", clusters[0].ID)
+	assert.Equal(t, 4, clusters[0].Count)
+	assert.Equal(t, []string{"twitter_1", "twitter_2", "linkedin_1"}, clusters[0].IDs)
+}
@@ -0,0 +1,119 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// llmMaxInput is the content, in characters, sent to the LLM for a yes/no classification
+const llmMaxInput = 1500
+
+// skipPaidKey marks contexts under which the pipeline skips the calls that cost money
+type skipPaidKey struct{}
+
+// withoutPaidEnrichment returns a context under which the pipeline skips the LLM and docs
+// search calls, e.g. for keyword previews anyone with the API token can run
+func withoutPaidEnrichment(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipPaidKey{}, true)
+}
+
+// paidEnrichment reports whether the LLM and docs search may be called under ctx
+func paidEnrichment(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipPaidKey{}).(bool)
+	return !skip
+}
+
+// truncateRunes cuts text to at most limit characters without splitting a multi-byte one,
+// bounding the content of LLM prompts
+func truncateRunes(text string, limit int) string {
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return text
+}
+
+// enrichment is what the enrichment stage derives from a mention's text, cached by content
+// hash so mentions processed again (repeat finds, backfills) skip the analysis, including
+// the paid LLM question classification
+type enrichment struct {
+	sentiment  string
+	phrases    []string
+	isQuestion bool
+}
+
+// enrichmentKey hashes what the enrichment depends on: the source (Stack Overflow only hosts
+// questions), the title and the content
+func enrichmentKey(mention models.Mention) string {
+	hash := sha256.New()
+	for _, part := range []string{mention.Source, mention.Title, mention.Content} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// enrichMentions sets sentiment and question labels on a batch of mentions, working through
+// them with up to EnrichmentConcurrency workers and reusing cached results
+func (s *Service) enrichMentions(ctx context.Context, mentions []models.Mention) {
+	if len(mentions) == 0 {
+		return
+	}
+
+	workers := s.config.EnrichmentConcurrency
+	if workers <= 0 || workers > len(mentions) {
+		workers = len(mentions)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				s.enrichMention(ctx, &mentions[index])
+			}
+		}()
+	}
+	for i := range mentions {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	hits, misses := s.enrichCache.Stats()
+	logrus.Debugf("Enriched %d mentions (cache: %d hits, %d misses so far)", len(mentions), hits, misses)
+}
+
+// enrichMention labels one mention from the cache, or analyzes it and caches the result
+func (s *Service) enrichMention(ctx context.Context, mention *models.Mention) {
+	if s.config.EnableSentimentAnalysis {
+		// Comment sentiment is local and cheap, and comments change between fetches
+		for j := range mention.TopComments {
+			mention.TopComments[j].Sentiment = s.basicSentimentAnalysis(mention.TopComments[j].Content)
+		}
+	}
+
+	key := enrichmentKey(*mention)
+	result, ok := s.enrichCache.Get(key)
+	if !ok {
+		if s.config.EnableSentimentAnalysis {
+			result.sentiment, result.phrases = s.explainSentiment(mention.Content)
+		}
+		var final bool
+		result.isQuestion, final = s.detectQuestion(ctx, *mention)
+		if final {
+			s.enrichCache.Add(key, result)
+		}
+	}
+
+	if s.config.EnableSentimentAnalysis {
+		mention.Sentiment, mention.SentimentPhrases = result.sentiment, result.phrases
+	}
+	mention.IsQuestion = result.isQuestion
+}
@@ -0,0 +1,72 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_enrichMentions(t *testing.T) {
+	cfg := &config.Config{EnableSentimentAnalysis: true, EnrichmentConcurrency: 3}
+	service := &Service{config: cfg, enrichCache: cache.NewLRU[string, enrichment](100)}
+
+	var mentions []models.Mention
+	for i := 0; i < 10; i++ {
+		mentions = append(mentions,
+			models.Mention{ID: fmt.Sprintf("reddit_%d", i), Source: "reddit", Title: "How do I upgrade AKS?", Content: "The upgrade is broken"},
+			models.Mention{ID: fmt.Sprintf("hackernews_%d", i), Source: "hackernews", Title: "AKS 1.30 released", Content: "Great release",
+				TopComments: []models.Comment{{Content: "Upgrades fail for us"}}},
+		)
+	}
+
+	service.enrichMentions(context.Background(), mentions)
+
+	for _, mention := range mentions {
+		if mention.Source == "reddit" {
+			assert.Equal(t, "negative", mention.Sentiment, mention.ID)
+			assert.Equal(t, []string{"broken"}, mention.SentimentPhrases, mention.ID)
+			assert.True(t, mention.IsQuestion, mention.ID)
+		} else {
+			assert.Equal(t, "positive", mention.Sentiment, mention.ID)
+			assert.False(t, mention.IsQuestion, mention.ID)
+			assert.Equal(t, "negative", mention.TopComments[0].Sentiment, "comments are always analyzed")
+		}
+	}
+
+	// Two distinct contents, so every other lookup is a hit however the workers interleave
+	assert.Equal(t, 2, service.enrichCache.Len())
+	hits, misses := service.enrichCache.Stats()
+	assert.Equal(t, 20, hits+misses)
+	assert.GreaterOrEqual(t, hits, 20-cfg.EnrichmentConcurrency*2)
+
+	// A changed title is a different mention as far as the cache is concerned
+	edited := []models.Mention{{Source: "reddit", Title: "AKS upgrade notes", Content: "The upgrade is broken"}}
+	service.enrichMentions(context.Background(), edited)
+	assert.False(t, edited[0].IsQuestion)
+	assert.Equal(t, 3, service.enrichCache.Len())
+}
+
+func TestService_enrichMentions_SentimentDisabled(t *testing.T) {
+	service := &Service{config: &config.Config{EnrichmentConcurrency: 1}}
+
+	mentions := []models.Mention{{Source: "stackoverflow", Title: "AKS ingress", Content: "Ingress is broken",
+		TopComments: []models.Comment{{Content: "Same error here"}}}}
+	service.enrichMentions(context.Background(), mentions)
+
+	assert.Empty(t, mentions[0].Sentiment)
+	assert.Empty(t, mentions[0].SentimentPhrases)
+	assert.Empty(t, mentions[0].TopComments[0].Sentiment)
+	assert.True(t, mentions[0].IsQuestion, "Stack Overflow only hosts questions")
+}
+
+func TestTruncateRunes(t *testing.T) {
+	assert.Equal(t, "AKS", truncateRunes("AKS", 5))
+	assert.Equal(t, "AKS u", truncateRunes("AKS upgrade", 5))
+	// Multi-byte characters are kept whole
+	assert.Equal(t, "アップグ", truncateRunes("アップグレード", 4))
+}
@@ -0,0 +1,99 @@
+package monitoring
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/azure/aks-mentions-bot/internal/matching"
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// excerptMaxLength is the longest excerpt stored on a mention. Content at or below this
+// length is shown in full, so no excerpt is extracted for it.
+const excerptMaxLength = 300
+
+// extractExcerpts stores the sentences around the first keyword occurrence as each mention's
+// excerpt, so notifications show the relevant part of long posts instead of their opening
+func (s *Service) extractExcerpts(mentions []models.Mention) {
+	for i := range mentions {
+		excerpt := extractExcerpt(mentions[i].Content, mentions[i].Keywords, excerptMaxLength)
+		if excerpt == "" {
+			// Source keywords are often tags that do not appear in the text
+			excerpt = extractExcerpt(mentions[i].Content, s.config.Keywords, excerptMaxLength)
+		}
+		mentions[i].Excerpt = excerpt
+	}
+}
+
+// extractExcerpt returns the first sentence containing a keyword, followed by as many of the
+// next sentences as fit in maxLength. It returns "" when content is short enough to show in
+// full or no keyword occurs in it.
+func extractExcerpt(content string, keywords []string, maxLength int) string {
+	content = strings.TrimSpace(content)
+	if len([]rune(content)) <= maxLength {
+		return ""
+	}
+
+	pattern := matching.KeywordPattern(keywords)
+	if pattern == nil {
+		return ""
+	}
+
+	sentences := splitSentences(content)
+	first := -1
+	for i, sentence := range sentences {
+		if pattern.MatchString(sentence) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	excerpt := sentences[first]
+	last := first
+	if len([]rune(excerpt)) > maxLength {
+		excerpt = matching.KeywordSnippet(excerpt, keywords, maxLength)
+	} else {
+		for last+1 < len(sentences) && len([]rune(excerpt))+1+len([]rune(sentences[last+1])) <= maxLength {
+			last++
+			excerpt += " " + sentences[last]
+		}
+	}
+
+	if first > 0 && !strings.HasPrefix(excerpt, "...") {
+		excerpt = "..." + excerpt
+	}
+	if last < len(sentences)-1 && !strings.HasSuffix(excerpt, "...") {
+		if strings.HasSuffix(excerpt, ".") || strings.HasSuffix(excerpt, "!") || strings.HasSuffix(excerpt, "?") {
+			excerpt += " "
+		}
+		excerpt += "..."
+	}
+	return excerpt
+}
+
+// splitSentences splits text at sentence punctuation followed by whitespace and at line breaks
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	flush := func(end int) {
+		if sentence := strings.Join(strings.Fields(string(runes[start:end])), " "); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '\n':
+			flush(i + 1)
+		case (r == '.' || r == '!' || r == '?') && i+1 < len(runes) && unicode.IsSpace(runes[i+1]):
+			flush(i + 1)
+		}
+	}
+	flush(len(runes))
+	return sentences
+}
@@ -0,0 +1,46 @@
+package monitoring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractExcerpt(t *testing.T) {
+	keywords := []string{"AKS"}
+	filler := strings.Repeat("This sentence is only here to pad the post. ", 8)
+
+	// Short content is shown in full
+	assert.Empty(t, extractExcerpt("We run AKS in production.", keywords, 100))
+
+	content := filler + "Our team breaks things often. We moved the cluster to AKS last week! Upgrades were smooth. " + filler
+	assert.Equal(t, "...We moved the cluster to AKS last week! Upgrades were smooth. This sentence is only here to pad the post. ...",
+		extractExcerpt(content, keywords, 110))
+
+	// A single long sentence is cut at word boundaries around the keyword
+	long := filler + strings.Repeat("word ", 60) + "then AKS appeared " + strings.Repeat("word ", 60)
+	excerpt := extractExcerpt(long, keywords, 80)
+	assert.True(t, strings.HasPrefix(excerpt, "..."))
+	assert.True(t, strings.HasSuffix(excerpt, "..."))
+	assert.Contains(t, excerpt, "then AKS appeared")
+	assert.LessOrEqual(t, len([]rune(excerpt)), 86)
+
+	assert.Empty(t, extractExcerpt(filler, keywords, 80), "no keyword, no excerpt")
+}
+
+func TestService_extractExcerpts(t *testing.T) {
+	service := &Service{config: &config.Config{Keywords: []string{"Azure Kubernetes Service"}}}
+	content := strings.Repeat("Setting the scene for a while. ", 12) + "Then Azure Kubernetes Service saved the day."
+
+	mentions := []models.Mention{
+		{ID: "tagged", Content: content, Keywords: []string{"azure-aks"}},
+		{ID: "short", Content: "Azure Kubernetes Service is great."},
+	}
+	service.extractExcerpts(mentions)
+
+	assert.Contains(t, mentions[0].Excerpt, "Then Azure Kubernetes Service saved the day.")
+	assert.Empty(t, mentions[1].Excerpt)
+}
@@ -0,0 +1,165 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/reports"
+	"github.com/sirupsen/logrus"
+)
+
+// parquetPartitionBlob is where the mentions of one source created on one day (UTC) are
+// exported, in the Hive layout Synapse, Fabric and ADX recognise as date and source columns
+func parquetPartitionBlob(prefix string, day time.Time, source string) string {
+	return parquetDayPrefix(prefix, day) + "source=" + source + "/mentions.parquet"
+}
+
+// parquetDayPrefix is the prefix of the Parquet partitions of one day
+func parquetDayPrefix(prefix string, day time.Time) string {
+	return fmt.Sprintf("%s/date=%s/", prefix, day.UTC().Format("2006-01-02"))
+}
+
+// exportRunParquet rewrites the Parquet partitions of the days a run stored mentions for.
+// Failures are logged so they never block the run.
+func (s *Service) exportRunParquet(mentions []models.Mention) {
+	if !s.config.EnableParquetExport || len(mentions) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var days []time.Time
+	for _, mention := range mentions {
+		day := mention.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if key := day.Format("2006-01-02"); !seen[key] {
+			seen[key] = true
+			days = append(days, day)
+		}
+	}
+
+	files, err := s.exportParquetDays(days)
+	if err != nil {
+		logrus.Warnf("Failed to export mentions to Parquet: %v", err)
+		return
+	}
+	logrus.Infof("Exported %d Parquet partitions for %d days", files, len(days))
+}
+
+// ExportParquet rewrites the Parquet partitions of every indexed day from since onwards,
+// e.g. to seed a data lake with the history stored before the export was enabled, and
+// deletes the partitions of later days the index no longer has. It returns the number of
+// files written.
+func (s *Service) ExportParquet(since time.Time) (int, error) {
+	if s.index == nil {
+		return 0, nil
+	}
+
+	indexed, err := s.index.Days()
+	if err != nil {
+		return 0, err
+	}
+	from := since.UTC().Truncate(24 * time.Hour)
+	var days []time.Time
+	exported := make(map[string]bool)
+	for _, day := range indexed {
+		if !day.Before(from) {
+			days = append(days, day)
+			exported[parquetDayPrefix(s.config.ParquetExportPrefix, day)] = true
+		}
+	}
+
+	files, err := s.exportParquetDays(days)
+	if err != nil {
+		return files, err
+	}
+
+	names, err := s.storage.List(s.config.ParquetExportPrefix + "/date=")
+	if err != nil {
+		return files, fmt.Errorf("failed to list Parquet partitions: %w", err)
+	}
+	for _, name := range names {
+		date, _, _ := strings.Cut(strings.TrimPrefix(name, s.config.ParquetExportPrefix+"/date="), "/")
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil || day.Before(from) || exported[parquetDayPrefix(s.config.ParquetExportPrefix, day)] {
+			continue
+		}
+		if err := s.storage.Delete(name); err != nil {
+			return files, fmt.Errorf("failed to delete stale partition %s: %w", name, err)
+		}
+		logrus.Infof("Deleted stale Parquet partition %s", name)
+	}
+	return files, nil
+}
+
+// exportParquetDays writes one file per source for each day, from the mention index, so
+// a mention found by several runs is exported once and each export replaces the last.
+// Partitions of the day for sources without mentions left are deleted.
+func (s *Service) exportParquetDays(days []time.Time) (int, error) {
+	if s.index == nil {
+		return 0, nil
+	}
+
+	files := 0
+	for _, day := range days {
+		entries, err := s.index.Load(day)
+		if err != nil {
+			return files, fmt.Errorf("failed to load the mention index for %s: %w", day.Format("2006-01-02"), err)
+		}
+
+		blobs := make(map[string]map[string]models.Mention)
+		bySource := make(map[string][]models.Mention)
+		for id, entry := range entries {
+			stored, ok := blobs[entry.Blob]
+			if !ok {
+				stored = s.readMentionsBlob(entry.Blob)
+				blobs[entry.Blob] = stored
+			}
+			if mention, ok := stored[id]; ok {
+				bySource[mention.Source] = append(bySource[mention.Source], mention)
+			}
+		}
+
+		written := make(map[string]bool)
+		for source, mentions := range bySource {
+			sort.Slice(mentions, func(a, b int) bool { return mentions[a].CreatedAt.Before(mentions[b].CreatedAt) })
+			s.applyTags(mentions)
+
+			data, err := reports.RenderParquet(mentions)
+			if err != nil {
+				return files, fmt.Errorf("failed to render %s mentions for %s: %w", source, day.Format("2006-01-02"), err)
+			}
+			name := parquetPartitionBlob(s.config.ParquetExportPrefix, day, source)
+			if err := s.storage.Store(name, data); err != nil {
+				return files, fmt.Errorf("failed to store %s: %w", name, err)
+			}
+			written[name] = true
+			files++
+		}
+
+		if err := s.pruneParquetDay(day, written); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// pruneParquetDay deletes the partitions of day that the last export didn't write, so the
+// data lake stops serving mentions the index no longer holds for that source
+func (s *Service) pruneParquetDay(day time.Time, written map[string]bool) error {
+	names, err := s.storage.List(parquetDayPrefix(s.config.ParquetExportPrefix, day))
+	if err != nil {
+		return fmt.Errorf("failed to list Parquet partitions for %s: %w", day.Format("2006-01-02"), err)
+	}
+	for _, name := range names {
+		if written[name] {
+			continue
+		}
+		if err := s.storage.Delete(name); err != nil {
+			return fmt.Errorf("failed to delete stale partition %s: %w", name, err)
+		}
+		logrus.Infof("Deleted stale Parquet partition %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ExportParquet(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{
+		config:  &config.Config{EnableParquetExport: true, ParquetExportPrefix: "exports/mentions"},
+		storage: store,
+		index:   storage.NewMentionIndex(store),
+	}
+
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, service.storeMentionBatch("2024-06-03-09-00-00", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade", CreatedAt: day.Add(8 * time.Hour)},
+		{ID: "reddit_2", Source: "reddit", Title: "Yesterday's thread", CreatedAt: day.Add(-2 * time.Hour)},
+	}))
+	// A later run finding the same mention again must not duplicate it in the export
+	require.NoError(t, service.storeMentionBatch("2024-06-03-13-00-00", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade (edited)", CreatedAt: day.Add(8 * time.Hour)},
+	}))
+	require.NoError(t, service.storeMentionBatch("2024-06-03-13-00-00", "hackernews", []models.Mention{
+		{ID: "hackernews_1", Source: "hackernews", Title: "AKS on HN", CreatedAt: day.Add(10 * time.Hour)},
+	}))
+
+	files, err := service.ExportParquet(day)
+	require.NoError(t, err)
+	assert.Equal(t, 2, files, "days before since are skipped")
+
+	reddit, err := store.Retrieve("exports/mentions/date=2024-06-03/source=reddit/mentions.parquet")
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(reddit, []byte("PAR1")))
+	assert.Contains(t, string(reddit), "AKS upgrade (edited)")
+	assert.Equal(t, 1, bytes.Count(reddit, []byte("reddit_1")))
+	_, err = store.Retrieve("exports/mentions/date=2024-06-03/source=hackernews/mentions.parquet")
+	assert.NoError(t, err)
+
+	// Partitions of sources, or days, without indexed mentions are deleted
+	require.NoError(t, store.Store("exports/mentions/date=2024-06-03/source=medium/mentions.parquet", []byte("PAR1")))
+	require.NoError(t, store.Store("exports/mentions/date=2024-06-05/source=reddit/mentions.parquet", []byte("PAR1")))
+	require.NoError(t, store.Store("exports/mentions/date=2024-05-01/source=reddit/mentions.parquet", []byte("PAR1")))
+	_, err = service.ExportParquet(day)
+	require.NoError(t, err)
+	names, err := store.List("exports/mentions/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"exports/mentions/date=2024-05-01/source=reddit/mentions.parquet",
+		"exports/mentions/date=2024-06-03/source=hackernews/mentions.parquet",
+		"exports/mentions/date=2024-06-03/source=reddit/mentions.parquet",
+	}, names, "partitions before since are left alone")
+
+	// Runs rewrite the partitions of the days their mentions were created on
+	service.exportRunParquet([]models.Mention{{ID: "reddit_2", CreatedAt: day.Add(-2 * time.Hour)}})
+	_, err = store.Retrieve("exports/mentions/date=2024-06-02/source=reddit/mentions.parquet")
+	assert.NoError(t, err)
+}
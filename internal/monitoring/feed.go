@@ -0,0 +1,105 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// FeedWindow is how far back the mentions feed reaches
+const FeedWindow = 7 * 24 * time.Hour
+
+// Bounds on the mentions listed in the feed
+const (
+	DefaultFeedLimit = 50
+	MaxFeedLimit     = 200
+)
+
+// ErrUnknownKeywordGroup is returned for a keyword group that is not configured
+var ErrUnknownKeywordGroup = errors.New("unknown keyword group")
+
+// FeedMentions returns the newest mentions stored in the last week, newest first, keeping
+// only those that matched a keyword of the group when one is given
+func (s *Service) FeedMentions(group string, limit int) ([]models.Mention, error) {
+	if group != "" {
+		if _, ok := s.config.KeywordGroups[group]; !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownKeywordGroup, group)
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultFeedLimit
+	}
+	if limit > MaxFeedLimit {
+		limit = MaxFeedLimit
+	}
+
+	if s.index == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	entries, err := s.index.Range(now.Add(-FeedWindow), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the mention index: %w", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		return entries[ids[a]].CreatedAt.After(entries[ids[b]].CreatedAt)
+	})
+
+	// Walk the index newest first, reading each mentions blob only when it is first needed
+	blobs := make(map[string]map[string]models.Mention)
+	var mentions []models.Mention
+	for _, id := range ids {
+		blob := entries[id].Blob
+		stored, ok := blobs[blob]
+		if !ok {
+			stored = s.readMentionsBlob(blob)
+			blobs[blob] = stored
+		}
+		mention, ok := stored[id]
+		if !ok {
+			continue
+		}
+		if group != "" && len(s.groupMentions([]models.Mention{mention}, group)) == 0 {
+			continue
+		}
+		mentions = append(mentions, mention)
+		if len(mentions) == limit {
+			break
+		}
+	}
+
+	s.applyTags(mentions)
+	return mentions, nil
+}
+
+// readMentionsBlob reads a stored mentions blob keyed by mention ID. Unreadable blobs are
+// logged and skipped so one bad blob does not break the feed.
+func (s *Service) readMentionsBlob(blob string) map[string]models.Mention {
+	byID := make(map[string]models.Mention)
+
+	data, err := s.storage.Retrieve(blob)
+	if err != nil {
+		logrus.Warnf("Failed to retrieve %s: %v", blob, err)
+		return byID
+	}
+	var stored []models.Mention
+	if err := json.Unmarshal(data, &stored); err != nil {
+		logrus.Warnf("Skipping unreadable mentions in %s: %v", blob, err)
+		return byID
+	}
+	for _, mention := range stored {
+		byID[mention.ID] = mention
+	}
+	return byID
+}
@@ -0,0 +1,55 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_FeedMentions(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{
+		config: &config.Config{
+			KeywordGroups: map[string][]string{"fleet": {"KubeFleet"}},
+		},
+		storage: store,
+		index:   storage.NewMentionIndex(store),
+	}
+
+	now := time.Now()
+	require.NoError(t, service.storeMentionBatch("2024-06-03-09-00-00", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade", Keywords: []string{"AKS"}, CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "reddit_2", Source: "reddit", Title: "KubeFleet rollout", Keywords: []string{"kubefleet"}, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "reddit_old", Source: "reddit", Title: "Old news", Keywords: []string{"KubeFleet"}, CreatedAt: now.Add(-FeedWindow - 48*time.Hour)},
+	}))
+	require.NoError(t, service.storeMentionBatch("2024-06-03-09-00-00", "hackernews", []models.Mention{
+		{ID: "hackernews_1", Source: "hackernews", Title: "AKS on HN", Keywords: []string{"AKS"}, CreatedAt: now.Add(-time.Hour)},
+	}))
+	_, err := service.AddMentionTags("reddit_2", []string{"rollout"})
+	require.NoError(t, err)
+
+	mentions, err := service.FeedMentions("", 0)
+	require.NoError(t, err)
+	require.Len(t, mentions, 3)
+	assert.Equal(t, []string{"hackernews_1", "reddit_2", "reddit_1"}, []string{mentions[0].ID, mentions[1].ID, mentions[2].ID})
+	assert.Equal(t, []string{"rollout"}, mentions[1].Tags)
+
+	mentions, err = service.FeedMentions("", 1)
+	require.NoError(t, err)
+	require.Len(t, mentions, 1)
+	assert.Equal(t, "hackernews_1", mentions[0].ID)
+
+	mentions, err = service.FeedMentions("fleet", 0)
+	require.NoError(t, err)
+	require.Len(t, mentions, 1)
+	assert.Equal(t, "reddit_2", mentions[0].ID)
+
+	_, err = service.FeedMentions("kaito", 0)
+	assert.ErrorIs(t, err, ErrUnknownKeywordGroup)
+}
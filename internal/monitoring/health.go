@@ -0,0 +1,163 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/sources"
+)
+
+// ErrUnknownSource is returned when a source name does not match any configured source
+var ErrUnknownSource = errors.New("unknown source")
+
+// SourceProbeTimeout bounds how long a single-source self-test may run
+const SourceProbeTimeout = 60 * time.Second
+
+// SourceStatus describes the health of a single data source
+type SourceStatus struct {
+	Name             string     `json:"name"`
+	Enabled          bool       `json:"enabled"`
+	Credentials      string     `json:"credentials"` // "configured", "missing" or "not_required"
+	LastSuccess      *time.Time `json:"last_success,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+	LastErrorAt      *time.Time `json:"last_error_at,omitempty"`
+	LastDuration     string     `json:"last_duration,omitempty"`
+	LastMentionCount int        `json:"last_mention_count"`
+	// Consecutive failed fetches, and until when runs skip the source once they open its circuit breaker
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+	SkippedUntil        *time.Time `json:"skipped_until,omitempty"`
+}
+
+// SourceTestResult is the outcome of a single-keyword probe against one source
+type SourceTestResult struct {
+	Source       string   `json:"source"`
+	Keyword      string   `json:"keyword"`
+	Success      bool     `json:"success"`
+	Error        string   `json:"error,omitempty"`
+	Duration     string   `json:"duration"`
+	MentionCount int      `json:"mention_count"`
+	SampleTitles []string `json:"sample_titles,omitempty"`
+}
+
+// GetSourceStatuses returns the health of every configured source
+func (s *Service) GetSourceStatuses() []SourceStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]SourceStatus, 0, len(s.sources))
+	for _, src := range s.sources {
+		status := SourceStatus{Name: src.GetName()}
+		if recorded, ok := s.sourceHealth[src.GetName()]; ok {
+			status = *recorded
+		}
+		status.Enabled = src.IsEnabled()
+		status.Credentials = s.credentialsStatus(src.GetName())
+		if breaker := s.sourceBreaker(src.GetName()); breaker != nil {
+			status.ConsecutiveFailures = breaker.Failures
+			if breaker.open(time.Now()) {
+				status.SkippedUntil = breaker.OpenUntil
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// TestSource runs a single-keyword probe against the named source
+func (s *Service) TestSource(ctx context.Context, name, keyword string) (*SourceTestResult, error) {
+	src := s.findSource(name)
+	if src == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSource, name)
+	}
+
+	if keyword == "" {
+		if len(s.config.Keywords) == 0 {
+			return nil, fmt.Errorf("no keyword provided and none configured")
+		}
+		keyword = s.config.Keywords[0]
+	}
+
+	result := &SourceTestResult{Source: name, Keyword: keyword}
+	if !src.IsEnabled() {
+		result.Error = "source is disabled"
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SourceProbeTimeout)
+	defer cancel()
+
+	fetched := s.fetchFromSource(ctx, src, []string{keyword}, 24*time.Hour, s.sourceTimeout(name))
+	result.Duration = fetched.duration.String()
+	result.MentionCount = len(fetched.mentions)
+	result.Success = fetched.err == nil
+	if fetched.err != nil {
+		result.Error = fetched.err.Error()
+	}
+
+	for i, mention := range fetched.mentions {
+		if i >= 3 {
+			break
+		}
+		result.SampleTitles = append(result.SampleTitles, mention.Title)
+	}
+
+	return result, nil
+}
+
+func (s *Service) findSource(name string) sources.Source {
+	for _, src := range s.sources {
+		if src.GetName() == name {
+			return src
+		}
+	}
+	return nil
+}
+
+// recordSourceHealth updates the health of a source after a fetch
+func (s *Service) recordSourceHealth(result fetchResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sourceHealth == nil {
+		s.sourceHealth = make(map[string]*SourceStatus)
+	}
+
+	status, ok := s.sourceHealth[result.source]
+	if !ok {
+		status = &SourceStatus{Name: result.source}
+		s.sourceHealth[result.source] = status
+	}
+
+	now := time.Now()
+	status.LastDuration = result.duration.String()
+	status.LastMentionCount = len(result.mentions)
+	if result.err != nil {
+		status.LastError = result.err.Error()
+		status.LastErrorAt = &now
+	} else {
+		status.LastSuccess = &now
+	}
+}
+
+// credentialsStatus reports whether the credentials a source needs are present in config
+func (s *Service) credentialsStatus(name string) string {
+	var configured bool
+	switch name {
+	case "reddit":
+		configured = s.config.RedditClientID != "" && s.config.RedditClientSecret != ""
+	case "twitter":
+		configured = s.config.TwitterBearerToken != ""
+	case "youtube":
+		configured = s.config.YouTubeAPIKey != ""
+	default:
+		return "not_required"
+	}
+
+	if configured {
+		return "configured"
+	}
+	return "missing"
+}
@@ -11,46 +11,11 @@ import (
 
 	"github.com/azure/aks-mentions-bot/internal/config"
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// MockFileStorage implements StorageInterface for testing
-type MockFileStorage struct {
-	data map[string][]byte
-}
-
-func NewMockFileStorage() *MockFileStorage {
-	return &MockFileStorage{
-		data: make(map[string][]byte),
-	}
-}
-
-func (m *MockFileStorage) Store(filename string, data []byte) error {
-	m.data[filename] = data
-	return nil
-}
-
-func (m *MockFileStorage) Retrieve(filename string) ([]byte, error) {
-	if data, exists := m.data[filename]; exists {
-		return data, nil
-	}
-	return nil, fmt.Errorf("file not found: %s", filename)
-}
-
-func (m *MockFileStorage) List(prefix string) ([]string, error) {
-	var files []string
-	for filename := range m.data {
-		files = append(files, filename)
-	}
-	return files, nil
-}
-
-func (m *MockFileStorage) Delete(filename string) error {
-	delete(m.data, filename)
-	return nil
-}
-
 // MockFileNotificationService for file output testing
 type MockFileNotificationService struct {
 	reports []models.Report
@@ -166,7 +131,7 @@ func TestReportGeneration(t *testing.T) {
 	}
 	
 	// Create mock services
-	mockStorage := NewMockFileStorage()
+	mockStorage := testutil.NewMemoryStorage()
 	mockNotifications := NewMockFileNotificationService()
 	
 	// Create monitoring service
@@ -247,7 +212,10 @@ func TestReportGeneration(t *testing.T) {
 	// Verify report structure
 	assert.Equal(t, "weekly", report.Period)
 	assert.Equal(t, 5, report.TotalMentions)
-	assert.Equal(t, sampleMentions, report.Mentions)
+	assert.Len(t, report.Mentions, len(sampleMentions))
+	for i, mention := range report.Mentions {
+		assert.Equal(t, i+1, mention.Rank.Position)
+	}
 	assert.NotNil(t, report.Summary)
 	
 	// Verify summary data
@@ -315,7 +283,7 @@ func TestFullWorkflow(t *testing.T) {
 		Keywords:       []string{"aks", "azure kubernetes service", "kubefleet", "kaito"},
 	}
 	
-	mockStorage := NewMockFileStorage()
+	mockStorage := testutil.NewMemoryStorage()
 	mockNotifications := NewMockFileNotificationService()
 	service := NewService(cfg, mockStorage, mockNotifications)
 	
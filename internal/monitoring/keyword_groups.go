@@ -0,0 +1,254 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// keywordGroupsBlob holds keyword groups changed at runtime through the API or the keywords
+// command
+const keywordGroupsBlob = "keywords/groups.json"
+
+var (
+	// ErrInvalidKeywordGroup is returned for group names or keyword lists that can't be stored
+	ErrInvalidKeywordGroup = errors.New("invalid keyword group")
+	// ErrKeywordGroupNotFound is returned when deleting a group that is not active
+	ErrKeywordGroupNotFound = errors.New("keyword group not found")
+	// ErrKeywordGroupInUse is returned when deleting a group email recipients or alert
+	// thresholds are configured with
+	ErrKeywordGroupInUse = errors.New("keyword group in use")
+)
+
+// KeywordOverrides are keyword groups changed at runtime, applied over KEYWORD_GROUPS when
+// services are built. A group with no keywords hides the configured group of that name.
+type KeywordOverrides struct {
+	Groups    map[string][]string `json:"groups"`
+	UpdatedAt time.Time           `json:"updated_at,omitempty"`
+}
+
+// KeywordGroupsStatus lists the keywords and groups the current runs use next to the runtime
+// changes, which apply from the next run
+type KeywordGroupsStatus struct {
+	Keywords  []string            `json:"keywords"`
+	Groups    map[string][]string `json:"groups"`
+	Runtime   map[string][]string `json:"runtime"`
+	UpdatedAt *time.Time          `json:"updated_at,omitempty"`
+}
+
+// LoadKeywordOverrides reads the runtime keyword groups, or none when nothing was changed
+func LoadKeywordOverrides(store storage.StorageInterface) (*KeywordOverrides, error) {
+	overrides := &KeywordOverrides{Groups: make(map[string][]string)}
+
+	data, found, err := storage.RetrieveIfExists(store, keywordGroupsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve keyword groups: %w", err)
+	}
+	if !found {
+		return overrides, nil
+	}
+	if err := json.Unmarshal(data, overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse keyword groups: %w", err)
+	}
+	if overrides.Groups == nil {
+		overrides.Groups = make(map[string][]string)
+	}
+	return overrides, nil
+}
+
+// Apply sets the runtime groups on cfg before it is shared with services. The keywords of
+// runtime groups are searched along with KEYWORDS, which are always searched, so hiding a
+// configured group doesn't stop searching for its keywords if KEYWORDS lists them.
+func (o *KeywordOverrides) Apply(cfg *config.Config) {
+	if len(o.Groups) == 0 {
+		return
+	}
+
+	groups := make(map[string][]string, len(cfg.KeywordGroups)+len(o.Groups))
+	for name, keywords := range cfg.KeywordGroups {
+		groups[name] = keywords
+	}
+	keywords := append([]string(nil), cfg.Keywords...)
+	for _, name := range sortedGroupNames(o.Groups) {
+		if len(o.Groups[name]) == 0 {
+			delete(groups, name)
+			continue
+		}
+		groups[name] = o.Groups[name]
+		keywords = appendKeywords(keywords, o.Groups[name])
+	}
+
+	cfg.KeywordGroups = groups
+	cfg.Keywords = keywords
+}
+
+// GetKeywordGroups returns the active keyword groups and the runtime changes
+func (s *Service) GetKeywordGroups() (KeywordGroupsStatus, error) {
+	overrides, err := s.keywordOverrides()
+	if err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+	return s.keywordGroupsStatus(overrides), nil
+}
+
+// SetKeywordGroup adds a keyword group or replaces the keywords of one, from the next run
+func (s *Service) SetKeywordGroup(name string, keywords []string) (KeywordGroupsStatus, error) {
+	name, err := validKeywordGroupName(name)
+	if err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+	keywords = appendKeywords(nil, keywords)
+	if len(keywords) == 0 {
+		return KeywordGroupsStatus{}, fmt.Errorf("%w: group %q needs at least one keyword", ErrInvalidKeywordGroup, name)
+	}
+
+	s.keywordGroupsMu.Lock()
+	defer s.keywordGroupsMu.Unlock()
+
+	overrides, err := s.keywordOverrides()
+	if err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+	overrides.Groups[name] = keywords
+	if err := s.saveKeywordOverrides(overrides); err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+
+	logrus.Infof("Set keyword group %q to %q, applied from the next run", name, strings.Join(keywords, ", "))
+	return s.keywordGroupsStatus(overrides), nil
+}
+
+// DeleteKeywordGroup removes a group added at runtime, restores the configured keywords of a
+// configured group changed at runtime, or hides a configured group, from the next run
+func (s *Service) DeleteKeywordGroup(name string) (KeywordGroupsStatus, error) {
+	name, err := validKeywordGroupName(name)
+	if err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+
+	s.keywordGroupsMu.Lock()
+	defer s.keywordGroupsMu.Unlock()
+
+	overrides, err := s.keywordOverrides()
+	if err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+	keywords, changed := overrides.Groups[name]
+	switch {
+	case changed && len(keywords) > 0:
+		delete(overrides.Groups, name)
+	case !changed && len(s.config.KeywordGroups[name]) > 0:
+		if err := s.checkKeywordGroupUnused(name); err != nil {
+			return KeywordGroupsStatus{}, err
+		}
+		overrides.Groups[name] = []string{}
+	default:
+		return KeywordGroupsStatus{}, fmt.Errorf("%w: %q", ErrKeywordGroupNotFound, name)
+	}
+	if err := s.saveKeywordOverrides(overrides); err != nil {
+		return KeywordGroupsStatus{}, err
+	}
+
+	logrus.Infof("Deleted keyword group %q, applied from the next run", name)
+	return s.keywordGroupsStatus(overrides), nil
+}
+
+// checkKeywordGroupUnused refuses to hide a configured group email recipients or alert
+// thresholds refer to, which would otherwise match nothing
+func (s *Service) checkKeywordGroupUnused(name string) error {
+	for _, recipient := range s.config.EmailRecipients {
+		for _, group := range recipient.KeywordGroups {
+			if group == name {
+				return fmt.Errorf("%w: email recipient %s receives group %q", ErrKeywordGroupInUse, recipient.Email, name)
+			}
+		}
+	}
+	for _, threshold := range s.config.AlertThresholds {
+		if threshold.Group == name {
+			return fmt.Errorf("%w: alert threshold %s checks group %q", ErrKeywordGroupInUse, threshold, name)
+		}
+	}
+	return nil
+}
+
+func (s *Service) keywordOverrides() (*KeywordOverrides, error) {
+	if s.storage == nil {
+		return &KeywordOverrides{Groups: make(map[string][]string)}, nil
+	}
+	return LoadKeywordOverrides(s.storage)
+}
+
+func (s *Service) saveKeywordOverrides(overrides *KeywordOverrides) error {
+	if s.storage == nil {
+		return nil
+	}
+
+	overrides.UpdatedAt = time.Now().UTC()
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return fmt.Errorf("failed to marshal keyword groups: %w", err)
+	}
+	if err := s.storage.Store(keywordGroupsBlob, data); err != nil {
+		return fmt.Errorf("failed to store keyword groups: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) keywordGroupsStatus(overrides *KeywordOverrides) KeywordGroupsStatus {
+	status := KeywordGroupsStatus{
+		Keywords: s.config.Keywords,
+		Groups:   s.config.KeywordGroups,
+		Runtime:  overrides.Groups,
+	}
+	if status.Groups == nil {
+		status.Groups = map[string][]string{}
+	}
+	if !overrides.UpdatedAt.IsZero() {
+		updated := overrides.UpdatedAt
+		status.UpdatedAt = &updated
+	}
+	return status
+}
+
+// validKeywordGroupName trims a group name and rejects names KEYWORD_GROUPS and
+// EMAIL_RECIPIENTS could not refer to
+func validKeywordGroupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == config.AllKeywordsGroup || strings.ContainsAny(name, "=;,|:/ ") {
+		return "", fmt.Errorf("%w: name %q", ErrInvalidKeywordGroup, name)
+	}
+	return name, nil
+}
+
+// appendKeywords appends trimmed keywords not already in the list, ignoring case
+func appendKeywords(list []string, keywords []string) []string {
+	seen := make(map[string]bool, len(list)+len(keywords))
+	for _, keyword := range list {
+		seen[strings.ToLower(keyword)] = true
+	}
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		list = append(list, keyword)
+	}
+	return list
+}
+
+func sortedGroupNames(groups map[string][]string) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keywordGroupsConfig() *config.Config {
+	return &config.Config{
+		Keywords: []string{"AKS", "Azure Kubernetes Fleet Manager", "KAITO"},
+		KeywordGroups: map[string][]string{
+			"fleet": {"Azure Kubernetes Fleet Manager"},
+			"kaito": {"KAITO"},
+		},
+		EmailRecipients: []config.EmailRecipient{{Email: "fleet@contoso.com", KeywordGroups: []string{"fleet"}}},
+	}
+}
+
+func TestService_keywordGroupsAppliedFromNextRun(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: keywordGroupsConfig(), storage: store}
+
+	status, err := service.SetKeywordGroup(" istio ", []string{"Istio add-on", "istio add-on", " ", "Istio ingress"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Istio add-on", "Istio ingress"}, status.Runtime["istio"])
+	assert.NotContains(t, status.Groups, "istio", "the current runs keep their keywords")
+	assert.NotNil(t, status.UpdatedAt)
+
+	_, err = service.DeleteKeywordGroup("kaito")
+	require.NoError(t, err)
+
+	// The next run's configuration has the runtime groups and searches their keywords
+	cfg := keywordGroupsConfig()
+	overrides, err := LoadKeywordOverrides(store)
+	require.NoError(t, err)
+	overrides.Apply(cfg)
+	assert.Equal(t, map[string][]string{
+		"fleet": {"Azure Kubernetes Fleet Manager"},
+		"istio": {"Istio add-on", "Istio ingress"},
+	}, cfg.KeywordGroups)
+	assert.Equal(t, []string{"AKS", "Azure Kubernetes Fleet Manager", "KAITO", "Istio add-on", "Istio ingress"}, cfg.Keywords)
+
+	next := &Service{config: cfg, storage: store}
+	status, err = next.GetKeywordGroups()
+	require.NoError(t, err)
+	assert.Contains(t, status.Groups, "istio")
+	assert.Equal(t, []string{}, status.Runtime["kaito"])
+
+	// Deleting a runtime group removes it; a hidden group is no longer active
+	_, err = next.DeleteKeywordGroup("istio")
+	require.NoError(t, err)
+	_, err = next.DeleteKeywordGroup("kaito")
+	assert.ErrorIs(t, err, ErrKeywordGroupNotFound)
+
+	overrides, err = LoadKeywordOverrides(store)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"kaito": {}}, overrides.Groups)
+}
+
+func TestService_keywordGroupsValidation(t *testing.T) {
+	service := &Service{config: keywordGroupsConfig(), storage: testutil.NewMemoryStorage()}
+
+	_, err := service.SetKeywordGroup("fleet;kaito", []string{"KubeFleet"})
+	assert.ErrorIs(t, err, ErrInvalidKeywordGroup)
+	_, err = service.SetKeywordGroup(config.AllKeywordsGroup, []string{"KubeFleet"})
+	assert.ErrorIs(t, err, ErrInvalidKeywordGroup)
+	_, err = service.SetKeywordGroup("fleet", []string{" "})
+	assert.ErrorIs(t, err, ErrInvalidKeywordGroup)
+
+	_, err = service.DeleteKeywordGroup("unknown")
+	assert.ErrorIs(t, err, ErrKeywordGroupNotFound)
+
+	// A configured group recipients receive can't be hidden, but a runtime change to it can be undone
+	_, err = service.DeleteKeywordGroup("fleet")
+	assert.ErrorIs(t, err, ErrKeywordGroupInUse)
+	_, err = service.SetKeywordGroup("fleet", []string{"Azure Kubernetes Fleet Manager", "KubeFleet"})
+	require.NoError(t, err)
+	status, err := service.DeleteKeywordGroup("fleet")
+	require.NoError(t, err)
+	assert.Empty(t, status.Runtime)
+}
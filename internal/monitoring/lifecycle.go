@@ -0,0 +1,226 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// lifecycleBlob holds the handling state of mentions acted on from notifications
+const lifecycleBlob = "lifecycle/mentions.json"
+
+// Mention statuses tracked in the lifecycle store. Mentions without a record are new.
+const (
+	MentionStatusNew       = "new"
+	MentionStatusHandled   = "handled"
+	MentionStatusEscalated = "escalated"
+)
+
+// Actions that can be applied to a mention through the inbound actions endpoint
+const (
+	ActionHandled  = "handled"
+	ActionEscalate = "escalate"
+)
+
+var (
+	// ErrUnknownAction is returned for actions other than ActionHandled and ActionEscalate
+	ErrUnknownAction = errors.New("unknown mention action")
+	// ErrUnknownMention is returned when a mention ID has not been stored by the bot
+	ErrUnknownMention = errors.New("unknown mention")
+)
+
+// MentionState is the lifecycle record of a single mention
+type MentionState struct {
+	MentionID   string         `json:"mention_id"`
+	Title       string         `json:"title,omitempty"`
+	Status      string         `json:"status"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+	UpdatedBy   string         `json:"updated_by,omitempty"`
+	EscalatedAt *time.Time     `json:"escalated_at,omitempty"` // When the escalation alert was sent; mentions are escalated once
+	History     []MentionEvent `json:"history,omitempty"`
+}
+
+// MentionEvent records one action taken on a mention
+type MentionEvent struct {
+	Action string    `json:"action"`
+	Actor  string    `json:"actor,omitempty"`
+	Note   string    `json:"note,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// GetMentionState returns the lifecycle state of a stored mention
+func (s *Service) GetMentionState(id string) (*MentionState, error) {
+	doc, ok := s.searchIndex().Document(id)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+
+	states, err := s.loadLifecycle()
+	if err != nil {
+		return nil, err
+	}
+
+	if state, ok := states[id]; ok {
+		state.Title = doc.Title
+		return state, nil
+	}
+	return &MentionState{MentionID: id, Title: doc.Title, Status: MentionStatusNew}, nil
+}
+
+// ApplyMentionAction marks a mention handled or escalates it. Escalations are sent as
+// critical alerts so they reach the configured alert channels, once per mention: escalating
+// it again, e.g. from a second click or a repeated Logic App run, only records the action.
+func (s *Service) ApplyMentionAction(id, action, actor, note string) (*MentionState, error) {
+	var status string
+	switch strings.ToLower(action) {
+	case ActionHandled:
+		status = MentionStatusHandled
+	case ActionEscalate:
+		status = MentionStatusEscalated
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownAction, action)
+	}
+
+	doc, ok := s.searchIndex().Document(id)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+
+	s.lifecycleMu.Lock()
+	states, err := s.loadLifecycle()
+	if err != nil {
+		s.lifecycleMu.Unlock()
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	state, ok := states[id]
+	if !ok {
+		state = &MentionState{MentionID: id}
+		states[id] = state
+	}
+	state.Title = doc.Title
+	state.Status = status
+	state.UpdatedAt = &now
+	state.UpdatedBy = actor
+	state.History = append(state.History, MentionEvent{Action: strings.ToLower(action), Actor: actor, Note: note, At: now})
+	// The escalation is recorded before the alert is sent, so concurrent escalations send
+	// one alert; deliveries that fail are queued for retry
+	sendEscalation := status == MentionStatusEscalated && state.EscalatedAt == nil
+	if sendEscalation {
+		state.EscalatedAt = &now
+	}
+
+	err = s.saveLifecycle(states)
+	s.lifecycleMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Mention %s marked %s by %q", id, status, actor)
+
+	if status == MentionStatusEscalated && !sendEscalation {
+		logrus.Infof("Mention %s was already escalated at %s, not alerting again", id, state.EscalatedAt.Format(time.RFC3339))
+	}
+	if sendEscalation && s.notificationService != nil {
+		alert := &models.Alert{
+			ID:      "escalation-" + id,
+			Type:    "critical",
+			Title:   "Mention escalated: " + doc.Title,
+			Message: escalationMessage(actor, note),
+			Mention: &models.Mention{
+				ID:        doc.ID,
+				Source:    doc.Source,
+				Title:     doc.Title,
+				URL:       doc.URL,
+				Permalink: s.mentionPermalink(doc.ID),
+				Author:    doc.Author,
+				CreatedAt: doc.CreatedAt,
+			},
+			CreatedAt: now,
+		}
+		if err := s.notificationService.SendAlert(alert); err != nil {
+			return state, fmt.Errorf("mention escalated but alert failed: %w", err)
+		}
+	}
+
+	return state, nil
+}
+
+// withoutHandled drops mentions that have already been marked handled so urgent checks
+// do not alert on them again
+func (s *Service) withoutHandled(mentions []models.Mention) []models.Mention {
+	s.lifecycleMu.Lock()
+	states, err := s.loadLifecycle()
+	s.lifecycleMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load mention lifecycle state, not filtering handled mentions: %v", err)
+		return mentions
+	}
+
+	filtered := mentions[:0]
+	for _, mention := range mentions {
+		if state, ok := states[mention.ID]; ok && state.Status == MentionStatusHandled {
+			logrus.Debugf("Skipping handled mention %s", mention.ID)
+			continue
+		}
+		filtered = append(filtered, mention)
+	}
+	return filtered
+}
+
+func escalationMessage(actor, note string) string {
+	message := "Escalated from a notification"
+	if actor != "" {
+		message += " by " + actor
+	}
+	if note != "" {
+		message += ": " + note
+	}
+	return message
+}
+
+// loadLifecycle reads the persisted lifecycle records; callers must hold s.lifecycleMu
+func (s *Service) loadLifecycle() (map[string]*MentionState, error) {
+	states := make(map[string]*MentionState)
+	if s.storage == nil {
+		return states, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, lifecycleBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mention lifecycle state: %w", err)
+	}
+	if !found {
+		return states, nil
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse mention lifecycle state: %w", err)
+	}
+	return states, nil
+}
+
+// saveLifecycle persists the lifecycle records; callers must hold s.lifecycleMu
+func (s *Service) saveLifecycle(states map[string]*MentionState) error {
+	if s.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mention lifecycle state: %w", err)
+	}
+	if err := s.storage.Store(lifecycleBlob, data); err != nil {
+		return fmt.Errorf("failed to store mention lifecycle state: %w", err)
+	}
+	return nil
+}
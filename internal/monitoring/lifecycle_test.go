@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLifecycleService(t *testing.T) (*Service, *testutil.RecordingNotificationService) {
+	t.Helper()
+	notifications := testutil.NewRecordingNotificationService()
+	service := &Service{config: &config.Config{}, storage: testutil.NewMemoryStorage(), notificationService: notifications}
+	require.NoError(t, service.storeMentions([]models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS node pool upgrade stuck", URL: "https://reddit.com/r/AZURE/1"},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS outage in westeurope", URL: "https://reddit.com/r/AZURE/2"},
+	}))
+	return service, notifications
+}
+
+func TestService_ApplyMentionAction(t *testing.T) {
+	service, notifications := newLifecycleService(t)
+
+	state, err := service.GetMentionState("reddit_1")
+	require.NoError(t, err)
+	assert.Equal(t, MentionStatusNew, state.Status)
+
+	state, err = service.ApplyMentionAction("reddit_1", "handled", "jane@contoso.com", "answered in thread")
+	require.NoError(t, err)
+	assert.Equal(t, MentionStatusHandled, state.Status)
+	assert.Equal(t, "jane@contoso.com", state.UpdatedBy)
+	require.Len(t, state.History, 1)
+	assert.Equal(t, "answered in thread", state.History[0].Note)
+	assert.Empty(t, notifications.Alerts(), "handling a mention must not alert")
+
+	// State is persisted and survives a reload
+	state, err = service.GetMentionState("reddit_1")
+	require.NoError(t, err)
+	assert.Equal(t, MentionStatusHandled, state.Status)
+
+	state, err = service.ApplyMentionAction("reddit_2", "escalate", "jane@contoso.com", "needs PG")
+	require.NoError(t, err)
+	assert.Equal(t, MentionStatusEscalated, state.Status)
+	require.Len(t, notifications.Alerts(), 1)
+	assert.Equal(t, "critical", notifications.Alerts()[0].Type)
+	assert.Equal(t, "https://reddit.com/r/AZURE/2", notifications.Alerts()[0].Mention.URL)
+	assert.Contains(t, notifications.Alerts()[0].Message, "needs PG")
+	assert.NotNil(t, state.EscalatedAt)
+	assert.Equal(t, "AKS outage in westeurope", state.Title)
+
+	// Escalating again records the action without alerting twice
+	state, err = service.ApplyMentionAction("reddit_2", "escalate", "sam@contoso.com", "")
+	require.NoError(t, err)
+	assert.Len(t, state.History, 2)
+	assert.Len(t, notifications.Alerts(), 1)
+}
+
+func TestService_ApplyMentionAction_errors(t *testing.T) {
+	service, _ := newLifecycleService(t)
+
+	_, err := service.ApplyMentionAction("reddit_1", "delete", "", "")
+	assert.ErrorIs(t, err, ErrUnknownAction)
+
+	_, err = service.ApplyMentionAction("missing", "handled", "", "")
+	assert.ErrorIs(t, err, ErrUnknownMention)
+
+	_, err = service.GetMentionState("missing")
+	assert.ErrorIs(t, err, ErrUnknownMention)
+}
+
+func TestService_withoutHandled(t *testing.T) {
+	service, _ := newLifecycleService(t)
+	_, err := service.ApplyMentionAction("reddit_1", "handled", "", "")
+	require.NoError(t, err)
+	_, err = service.ApplyMentionAction("reddit_2", "escalate", "", "")
+	require.NoError(t, err)
+
+	filtered := service.withoutHandled([]models.Mention{{ID: "reddit_1"}, {ID: "reddit_2"}, {ID: "reddit_3"}})
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "reddit_2", filtered[0].ID, "escalated mentions keep alerting")
+	assert.Equal(t, "reddit_3", filtered[1].ID)
+}
@@ -0,0 +1,123 @@
+package monitoring
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// liveBuffer is how many mentions a live subscriber can fall behind before further mentions
+// are dropped for it, so a slow client never holds up a run
+const liveBuffer = 256
+
+// LiveFilter narrows the mentions a live subscriber receives; empty fields match everything
+type LiveFilter struct {
+	Sources   []string
+	Sentiment string
+	Group     string // Keyword group the mention must match
+}
+
+// liveHub fans mentions out to live subscribers as runs store them
+type liveHub struct {
+	mu          sync.Mutex
+	subscribers map[*liveSubscriber]struct{}
+	closed      bool
+}
+
+type liveSubscriber struct {
+	filter   LiveFilter
+	mentions chan models.Mention
+	dropped  int
+}
+
+// SubscribeMentions streams mentions as monitoring runs and urgent checks store them, after
+// filtering, until cancel is called or the service shuts down, when the channel is closed.
+// A subscriber that falls behind misses mentions rather than slowing runs down.
+func (s *Service) SubscribeMentions(filter LiveFilter) (<-chan models.Mention, func(), error) {
+	if filter.Group != "" {
+		if _, ok := s.config.KeywordGroups[filter.Group]; !ok {
+			return nil, nil, fmt.Errorf("%w %q", ErrUnknownKeywordGroup, filter.Group)
+		}
+	}
+
+	subscriber := &liveSubscriber{filter: filter, mentions: make(chan models.Mention, liveBuffer)}
+
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	if s.live.closed {
+		close(subscriber.mentions)
+		return subscriber.mentions, func() {}, nil
+	}
+	if s.live.subscribers == nil {
+		s.live.subscribers = make(map[*liveSubscriber]struct{})
+	}
+	s.live.subscribers[subscriber] = struct{}{}
+
+	cancel := func() {
+		s.live.mu.Lock()
+		defer s.live.mu.Unlock()
+		if _, ok := s.live.subscribers[subscriber]; ok {
+			delete(s.live.subscribers, subscriber)
+			close(subscriber.mentions)
+		}
+	}
+	return subscriber.mentions, cancel, nil
+}
+
+// LiveSubscribers returns how many clients are subscribed to live mentions
+func (s *Service) LiveSubscribers() int {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	return len(s.live.subscribers)
+}
+
+// publishMentions sends stored mentions to the live subscribers whose filter they match
+func (s *Service) publishMentions(mentions []models.Mention) {
+	if len(mentions) == 0 {
+		return
+	}
+
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	for subscriber := range s.live.subscribers {
+		for _, mention := range mentions {
+			if !s.liveMatch(subscriber.filter, mention) {
+				continue
+			}
+			select {
+			case subscriber.mentions <- mention:
+			default:
+				if subscriber.dropped == 0 {
+					logrus.Warnf("Live mentions subscriber is falling behind, dropping mentions")
+				}
+				subscriber.dropped++
+			}
+		}
+	}
+}
+
+// closeLive ends every live subscription and refuses new ones
+func (s *Service) closeLive() {
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+	s.live.closed = true
+	for subscriber := range s.live.subscribers {
+		delete(s.live.subscribers, subscriber)
+		close(subscriber.mentions)
+	}
+}
+
+func (s *Service) liveMatch(filter LiveFilter, mention models.Mention) bool {
+	if len(filter.Sources) > 0 && !containsString(filter.Sources, mention.Source) {
+		return false
+	}
+	if filter.Sentiment != "" && mention.Sentiment != filter.Sentiment {
+		return false
+	}
+	if filter.Group != "" && len(s.groupMentions([]models.Mention{mention}, filter.Group)) == 0 {
+		return false
+	}
+	return true
+}
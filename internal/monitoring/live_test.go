@@ -0,0 +1,98 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receive drains the mentions already delivered to a live subscription
+func receive(mentions <-chan models.Mention) []string {
+	var ids []string
+	for {
+		select {
+		case mention := <-mentions:
+			ids = append(ids, mention.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestService_SubscribeMentions(t *testing.T) {
+	service := &Service{
+		config: &config.Config{
+			EnableContextFiltering: true,
+			SourceTimeout:          time.Second,
+			KeywordGroups:          map[string][]string{"fleet": {"KubeFleet"}},
+		},
+		storage: testutil.NewMemoryStorage(),
+	}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "Great AKS cluster upgrade", Content: "Azure Kubernetes Service works great"},
+			{ID: "reddit_2", Source: "reddit", Title: "AKS-47 rifle review", Content: "gun range day"},
+		}},
+		&stubSource{name: "hackernews", mentions: []models.Mention{
+			{ID: "hackernews_1", Source: "hackernews", Title: "Running KubeFleet on AKS", Content: "kubernetes fleet", Keywords: []string{"kubefleet"}},
+		}},
+	}
+
+	all, cancelAll, err := service.SubscribeMentions(LiveFilter{})
+	require.NoError(t, err)
+	reddit, cancelReddit, err := service.SubscribeMentions(LiveFilter{Sources: []string{"reddit"}})
+	require.NoError(t, err)
+	defer cancelReddit()
+	fleet, cancelFleet, err := service.SubscribeMentions(LiveFilter{Group: "fleet"})
+	require.NoError(t, err)
+	defer cancelFleet()
+	assert.Equal(t, 3, service.LiveSubscribers())
+
+	_, _, err = service.SubscribeMentions(LiveFilter{Group: "unknown"})
+	assert.ErrorIs(t, err, ErrUnknownKeywordGroup)
+
+	service.runPipeline(context.Background(), "run", []string{"aks"}, fixedWindow(time.Hour))
+
+	// Only mentions that passed the filters are streamed
+	assert.ElementsMatch(t, []string{"reddit_1", "hackernews_1"}, receive(all))
+	assert.Equal(t, []string{"reddit_1"}, receive(reddit))
+	assert.Equal(t, []string{"hackernews_1"}, receive(fleet))
+
+	cancelAll()
+	_, open := <-all
+	assert.False(t, open)
+	assert.Equal(t, 2, service.LiveSubscribers())
+
+	// Shutdown ends the remaining subscriptions and refuses new ones
+	require.NoError(t, service.Shutdown(context.Background()))
+	_, open = <-reddit
+	assert.False(t, open)
+	late, _, err := service.SubscribeMentions(LiveFilter{})
+	require.NoError(t, err)
+	_, open = <-late
+	assert.False(t, open)
+}
+
+func TestService_publishMentionsDropsForSlowSubscribers(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+	mentions, cancel, err := service.SubscribeMentions(LiveFilter{Sentiment: "negative"})
+	require.NoError(t, err)
+	defer cancel()
+
+	batch := make([]models.Mention, liveBuffer+10)
+	for i := range batch {
+		batch[i] = models.Mention{ID: "m", Sentiment: "negative"}
+	}
+	batch = append(batch, models.Mention{ID: "positive", Sentiment: "positive"})
+
+	// Publishing never blocks on a full subscriber
+	service.publishMentions(batch)
+	assert.Len(t, receive(mentions), liveBuffer)
+}
@@ -0,0 +1,63 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/reports"
+)
+
+// mentionPermalink is the bot's page for a mention, "" when the bot isn't publicly reachable
+func (s *Service) mentionPermalink(id string) string {
+	if s.config.PublicBaseURL == "" || id == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/m/%s", s.config.PublicBaseURL, url.PathEscape(id))
+}
+
+// linkMentionPages points the mentions' notification links at their permalink pages, or at
+// the redirect recording clicks with click tracking on
+func (s *Service) linkMentionPages(mentions []models.Mention) {
+	for i := range mentions {
+		mentions[i].Permalink = s.mentionPermalink(mentions[i].ID)
+		mentions[i].ClickURL = s.mentionClickURL(mentions[i].ID)
+	}
+}
+
+// MentionPage renders the permalink page of a stored mention with its metadata, topics and
+// lifecycle state
+func (s *Service) MentionPage(id string) ([]byte, error) {
+	doc, ok := s.searchIndex().Document(id)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+
+	mentions, err := s.retrieveMentions(map[string]map[string]bool{doc.Blob: {id: true}})
+	if err != nil {
+		return nil, err
+	}
+	if len(mentions) == 0 {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+	s.applyTags(mentions)
+	mention := mentions[0]
+
+	page := &reports.MentionPage{Mention: mention, Status: MentionStatusNew}
+	for _, topic := range mentionTopics(models.Mention{Title: mention.Title}, s.keywordTokens()) {
+		page.Topics = append(page.Topics, strings.TrimPrefix(topic, "term:"))
+	}
+
+	s.lifecycleMu.Lock()
+	states, err := s.loadLifecycle()
+	s.lifecycleMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if state, ok := states[id]; ok {
+		page.Status, page.UpdatedAt = state.Status, state.UpdatedAt
+	}
+
+	return reports.RenderMention(page)
+}
@@ -0,0 +1,49 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_MentionPage(t *testing.T) {
+	service, _ := newLifecycleService(t)
+	service.config.Keywords = []string{"AKS"}
+	_, err := service.AddMentionTags("reddit_1", []string{"upgrades"})
+	require.NoError(t, err)
+	_, err = service.ApplyMentionAction("reddit_1", "handled", "jane@contoso.com", "answered in thread")
+	require.NoError(t, err)
+
+	page, err := service.MentionPage("reddit_1")
+	require.NoError(t, err)
+	html := string(page)
+	assert.Contains(t, html, "AKS node pool upgrade stuck")
+	assert.Contains(t, html, `href="https://reddit.com/r/AZURE/1"`, "the page links to the original post")
+	assert.Contains(t, html, "upgrades", "curated tags are shown")
+	assert.Contains(t, html, "node, pool, upgrade, stuck", "title topics are shown")
+	assert.Contains(t, html, "<dd>Handled, ")
+	assert.NotContains(t, html, "jane@contoso.com", "the public page doesn't show who acted")
+	assert.NotContains(t, html, "answered in thread", "the public page doesn't show triage notes")
+
+	page, err = service.MentionPage("reddit_2")
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<dd>New</dd>")
+
+	_, err = service.MentionPage("missing")
+	assert.ErrorIs(t, err, ErrUnknownMention)
+}
+
+func TestService_linkMentionPages(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+	mentions := []models.Mention{{ID: "reddit_1", URL: "https://reddit.com/r/AZURE/1"}}
+
+	service.linkMentionPages(mentions)
+	assert.Empty(t, mentions[0].Permalink, "without a public URL notifications link the original post")
+
+	service.config.PublicBaseURL = "https://bot.example.com"
+	service.linkMentionPages(mentions)
+	assert.Equal(t, "https://bot.example.com/m/reddit_1", mentions[0].Permalink)
+}
@@ -0,0 +1,175 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/sirupsen/logrus"
+)
+
+// mentionBatch is a group of mentions from a single source flowing through the pipeline
+type mentionBatch struct {
+	source     string
+	mentions   []models.Mention
+	rejected   []models.Mention // mentions the context filter or spam detection dropped, kept for the audit trail
+	fetchErr   error
+	storeErr   error
+	fetchCount int       // number of mentions returned by the source before filtering
+	latest     time.Time // newest mention returned by the source before filtering
+}
+
+// pipelineResult aggregates the outcome of a streaming monitoring run
+type pipelineResult struct {
+	mentions    []models.Mention
+	fetchErrors int
+	storeErr    error
+	latest      map[string]time.Time // newest mention per source that fetched and stored cleanly
+	outcomes    map[string]error     // fetch error of each source, nil when it succeeded
+}
+
+// runPipeline streams mentions through fetch → filter → enrich → store → aggregate.
+// Each source's mentions are persisted as soon as they have been processed, so only
+// relevant mentions are held in memory and partial results survive a failure mid-run.
+func (s *Service) runPipeline(ctx context.Context, runID string, keywords []string, window fetchWindow) *pipelineResult {
+	return s.runSourcesPipeline(ctx, s.sources, runID, keywords, window)
+}
+
+// runSourcesPipeline runs the pipeline over the given sources only
+func (s *Service) runSourcesPipeline(ctx context.Context, srcs []sources.Source, runID string, keywords []string, window fetchWindow) *pipelineResult {
+	duplicates := s.newRunDuplicates()
+	fetched := s.streamFromSources(ctx, srcs, keywords, window, s.sourceTimeout)
+	processed := s.processStage(ctx, fetched, duplicates, s.reportedMentions())
+	stored := s.storeStage(runID, processed)
+
+	result := &pipelineResult{latest: make(map[string]time.Time), outcomes: make(map[string]error)}
+	collected := 0
+	for batch := range stored {
+		collected += batch.fetchCount
+		result.outcomes[batch.source] = batch.fetchErr
+		if batch.fetchErr != nil {
+			result.fetchErrors++
+		}
+		if batch.storeErr != nil && result.storeErr == nil {
+			result.storeErr = batch.storeErr
+		}
+		if batch.fetchErr == nil && batch.storeErr == nil && !batch.latest.IsZero() {
+			result.latest[batch.source] = batch.latest
+		}
+		result.mentions = append(result.mentions, batch.mentions...)
+	}
+
+	if duplicates != nil {
+		// Copies in later batches were counted after earlier ones were stored
+		duplicates.annotate(result.mentions)
+		if collapsed := duplicates.collapsed(); collapsed > 0 {
+			logrus.Infof("Collapsed %d near-duplicate mentions", collapsed)
+		}
+		s.saveDuplicates(duplicates)
+	}
+
+	logrus.Infof("Collected %d total mentions from %d sources, %d after processing", collected, len(srcs), len(result.mentions))
+
+	// Every run that enriches and stores mentions updates today's sentiment score, so
+	// collections between reports leave no gaps in the trend
+	s.recordSentimentScore(time.Now())
+	return result
+}
+
+// processStage drops blocklisted mentions and those in reported, which an earlier run's overlapping window already reported,
+// and translates the rest into English, then applies context filtering, spam detection, near-duplicate collapsing
+// (when duplicates is not nil) and enrichment (sentiment, question detection, documentation gaps, keyword excerpts)
+// to each fetched batch
+func (s *Service) processStage(ctx context.Context, in <-chan fetchResult, duplicates *duplicateCollapser, reported map[string]time.Time) <-chan mentionBatch {
+	out := make(chan mentionBatch)
+
+	go func() {
+		defer close(out)
+		detector := newSpamDetector()
+		docsLookups := s.config.DocsGapMaxLookups
+		translations := s.config.TranslationMaxMentions
+		for result := range in {
+			mentions := dropReported(s.filterBlocked(result.mentions), reported)
+			// Translate first, so filtering, enrichment and excerpts work on English text
+			s.translateMentions(ctx, mentions, &translations)
+
+			var rejected []models.Mention
+			if s.config.EnableContextFiltering {
+				mentions, rejected = s.partitionByContext(mentions)
+				logrus.Debugf("After context filtering %s: %d of %d mentions", result.source, len(mentions), len(result.mentions))
+			}
+
+			if s.config.EnableSpamDetection {
+				var spam []models.Mention
+				mentions, spam = s.filterSpam(ctx, detector, mentions)
+				rejected = append(rejected, spam...)
+			}
+
+			if duplicates != nil {
+				mentions = duplicates.collapse(mentions)
+				duplicates.annotate(mentions)
+			}
+
+			s.enrichMentions(ctx, mentions)
+			s.detectDocsGaps(ctx, mentions, &docsLookups)
+			s.extractExcerpts(mentions)
+
+			out <- mentionBatch{
+				source:     result.source,
+				mentions:   mentions,
+				rejected:   rejected,
+				fetchErr:   result.err,
+				fetchCount: len(result.mentions),
+				latest:     latestMention(result.mentions),
+			}
+		}
+	}()
+
+	return out
+}
+
+// storeStage persists each processed batch, and the mentions it rejected, before passing
+// it on for aggregation
+func (s *Service) storeStage(runID string, in <-chan mentionBatch) <-chan mentionBatch {
+	out := make(chan mentionBatch)
+
+	go func() {
+		defer close(out)
+		for batch := range in {
+			if err := s.storeMentionBatch(runID, batch.source, batch.mentions); err != nil {
+				logrus.Errorf("Failed to store mentions from %s: %v", batch.source, err)
+				batch.storeErr = err
+			} else {
+				s.publishMentions(batch.mentions)
+			}
+			s.storeRejected(runID, batch.source, batch.rejected)
+			out <- batch
+		}
+		s.pruneRejected(time.Now())
+	}()
+
+	return out
+}
+
+// storeMentionBatch stores one source's mentions for a run under a per-source blob name
+func (s *Service) storeMentionBatch(runID, source string, mentions []models.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(mentions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mentions: %w", err)
+	}
+
+	filename := fmt.Sprintf("mentions-%s-%s.json", runID, source)
+	if err := s.storage.Store(filename, data); err != nil {
+		return err
+	}
+
+	s.indexMentions(filename, mentions)
+	return nil
+}
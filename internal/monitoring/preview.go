@@ -0,0 +1,122 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// PreviewTimeout bounds how long a keyword preview may search
+const PreviewTimeout = 5 * time.Minute
+
+// Bounds on keyword previews, which search live sources on demand
+const (
+	DefaultPreviewWindow  = 24 * time.Hour
+	MaxPreviewWindow      = 7 * 24 * time.Hour
+	DefaultPreviewSamples = 5
+	maxPreviewSamples     = 50
+)
+
+// KeywordPreview summarizes what a proposed keyword set would collect
+type KeywordPreview struct {
+	Keywords  []string          `json:"keywords"`
+	Window    string            `json:"window"`
+	Duration  string            `json:"duration"`
+	Collected int               `json:"collected"` // mentions returned by the sources, before filtering
+	Kept      int               `json:"kept"`      // mentions that would be reported
+	Rejected  int               `json:"rejected"`  // mentions the context filter, blocklist or spam detection would drop
+	ByKeyword map[string]int    `json:"by_keyword"`
+	BySource  map[string]int    `json:"by_source"`
+	Errors    map[string]string `json:"errors,omitempty"` // sources that failed, by name
+	Samples   []models.Mention  `json:"samples"`          // highest-scoring kept mentions
+}
+
+// PreviewKeywords runs the sources and filters with the keywords, without storing mentions,
+// sending notifications or calling the LLM and docs search, so keyword changes can be
+// evaluated before they are configured. Previews search their own sources and leave source
+// health and circuit breakers alone, so they don't change what the next run does.
+func (s *Service) PreviewKeywords(ctx context.Context, keywords []string, window time.Duration, samples int) (*KeywordPreview, error) {
+	var cleaned []string
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			cleaned = append(cleaned, keyword)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("at least one keyword is required")
+	}
+	if window <= 0 {
+		window = DefaultPreviewWindow
+	}
+	if window > MaxPreviewWindow {
+		return nil, fmt.Errorf("window must be at most %v", MaxPreviewWindow)
+	}
+	if samples <= 0 {
+		samples = DefaultPreviewSamples
+	}
+	if samples > maxPreviewSamples {
+		samples = maxPreviewSamples
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PreviewTimeout)
+	defer cancel()
+	ctx = withoutPaidEnrichment(ctx)
+	ctx = withoutSourceRecording(ctx)
+
+	start := time.Now()
+	preview := &KeywordPreview{
+		Keywords:  cleaned,
+		Window:    window.String(),
+		ByKeyword: make(map[string]int),
+		BySource:  make(map[string]int),
+	}
+
+	var kept []models.Mention
+	for batch := range s.processStage(ctx, s.streamFromSources(ctx, s.previewSources, cleaned, fixedWindow(window), s.sourceTimeout), nil, nil) {
+		preview.Collected += batch.fetchCount
+		preview.BySource[batch.source] += len(batch.mentions)
+		if batch.fetchErr != nil {
+			if preview.Errors == nil {
+				preview.Errors = make(map[string]string)
+			}
+			preview.Errors[batch.source] = batch.fetchErr.Error()
+		}
+		kept = append(kept, batch.mentions...)
+	}
+
+	for _, mention := range kept {
+		for _, keyword := range mention.Keywords {
+			preview.ByKeyword[keyword]++
+		}
+	}
+	preview.Kept = len(kept)
+	preview.Rejected = preview.Collected - preview.Kept
+
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Score > kept[j].Score })
+	if len(kept) > samples {
+		kept = kept[:samples]
+	}
+	preview.Samples = kept
+	preview.Duration = time.Since(start).Round(time.Millisecond).String()
+
+	return preview, nil
+}
+
+// skipRecordingKey marks contexts whose fetches are not recorded in source health or breakers
+type skipRecordingKey struct{}
+
+// withoutSourceRecording returns a context under which fetch outcomes don't count towards
+// source health or the circuit breakers, e.g. for keyword previews
+func withoutSourceRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipRecordingKey{}, true)
+}
+
+// sourceRecording reports whether fetch outcomes under ctx are recorded
+func sourceRecording(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipRecordingKey{}).(bool)
+	return !skip
+}
@@ -0,0 +1,102 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/llm"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PreviewKeywords(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	notifications := testutil.NewRecordingNotificationService()
+	cfg := &config.Config{EnableContextFiltering: true, SourceTimeout: time.Second}
+	service := &Service{config: cfg, storage: storage, notificationService: notifications}
+	service.previewSources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "KubeFleet multi-cluster rollout", Content: "Azure Kubernetes Fleet Manager on AKS", Score: 5, Keywords: []string{"KubeFleet"}},
+			{ID: "reddit_2", Source: "reddit", Title: "AKS-47 rifle review", Content: "gun range day", Keywords: []string{"AKS"}},
+		}},
+		&stubSource{name: "hackernews", mentions: []models.Mention{
+			{ID: "hackernews_1", Source: "hackernews", Title: "Running KAITO on AKS", Content: "kubernetes inference", Score: 20, Keywords: []string{"KAITO", "AKS"}},
+		}},
+	}
+
+	preview, err := service.PreviewKeywords(context.Background(), []string{"KubeFleet", " KAITO", ""}, 48*time.Hour, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"KubeFleet", "KAITO"}, preview.Keywords)
+	assert.Equal(t, "48h0m0s", preview.Window)
+	assert.Equal(t, 3, preview.Collected)
+	assert.Equal(t, 2, preview.Kept)
+	assert.Equal(t, 1, preview.Rejected)
+	assert.Equal(t, map[string]int{"KubeFleet": 1, "KAITO": 1, "AKS": 1}, preview.ByKeyword)
+	assert.Equal(t, map[string]int{"reddit": 1, "hackernews": 1}, preview.BySource)
+	require.Len(t, preview.Samples, 1)
+	assert.Equal(t, "hackernews_1", preview.Samples[0].ID)
+	assert.Equal(t, 48*time.Hour, service.previewSources[0].(*stubSource).since)
+
+	// Nothing is stored or sent
+	assert.Empty(t, storage.Blobs())
+	assert.Empty(t, notifications.Reports())
+	assert.Empty(t, notifications.Alerts())
+
+	_, err = service.PreviewKeywords(context.Background(), []string{" "}, 0, 0)
+	assert.ErrorContains(t, err, "at least one keyword")
+	_, err = service.PreviewKeywords(context.Background(), []string{"AKS"}, 30*24*time.Hour, 0)
+	assert.ErrorContains(t, err, "window must be at most")
+}
+
+func TestService_PreviewKeywords_skipsLLM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("previews call the LLM: %s", r.URL)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client, err := llm.NewClient(server.URL, "gpt", "2024-06-01", "key")
+	require.NoError(t, err)
+
+	cfg := &config.Config{
+		SourceTimeout: time.Second, EnableTranslation: true, TranslationMaxMentions: 10,
+		EnableSpamDetection: true, EnableLLMSpamDetection: true, EnableLLMQuestionDetection: true,
+	}
+	service := &Service{config: cfg, llm: client}
+	service.previewSources = []sources.Source{
+		&stubSource{name: "qiita", mentions: []models.Mention{
+			{ID: "qiita_1", Source: "qiita", Title: "AKS のノード自動プロビジョニング", Content: "AKS で NAP を有効にしました", Keywords: []string{"AKS"}},
+			{ID: "qiita_2", Source: "qiita", Title: "AKS upgrade notes", Content: "Notes from upgrading AKS", Keywords: []string{"AKS"}},
+		}},
+	}
+
+	preview, err := service.PreviewKeywords(context.Background(), []string{"AKS"}, time.Hour, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, preview.Kept)
+}
+
+func TestService_PreviewKeywords_leavesSourcesAlone(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	reddit := &failingSource{name: "reddit", err: errors.New("401 Unauthorized")}
+	service := &Service{config: breakerConfig(), storage: store}
+	service.previewSources = []sources.Source{reddit}
+
+	// Failing previews neither open the breaker nor show up in source health
+	for i := 0; i < 3; i++ {
+		preview, err := service.PreviewKeywords(context.Background(), []string{"AKS"}, time.Hour, 0)
+		require.NoError(t, err)
+		assert.Contains(t, preview.Errors, "reddit")
+	}
+	assert.Equal(t, int32(3), reddit.fetches.Load())
+	assert.Empty(t, service.skippedSources())
+	assert.Empty(t, service.sourceHealth)
+	assert.Empty(t, store.Blobs())
+}
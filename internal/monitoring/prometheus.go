@@ -0,0 +1,112 @@
+package monitoring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// keywordMetrics counts a run's mentions per keyword group and per keyword by source. Every
+// searched keyword gets an entry, empty when nothing matched it; matched keywords are counted
+// under their configured spelling.
+func (s *Service) keywordMetrics(mentions []models.Mention) (map[string]int, map[string]map[string]int) {
+	groups := make(map[string]int, len(s.config.KeywordGroups))
+	for group := range s.config.KeywordGroups {
+		groups[group] = len(s.groupMentions(mentions, group))
+	}
+
+	keywords := make(map[string]map[string]int, len(s.config.Keywords))
+	configured := make(map[string]string, len(s.config.Keywords))
+	for _, keyword := range s.config.Keywords {
+		keywords[keyword] = make(map[string]int)
+		configured[strings.ToLower(keyword)] = keyword
+	}
+	for _, mention := range mentions {
+		for _, keyword := range mention.Keywords {
+			if name, ok := configured[strings.ToLower(keyword)]; ok {
+				keyword = name
+			} else if keywords[keyword] == nil {
+				keywords[keyword] = make(map[string]int)
+			}
+			keywords[keyword][mention.Source]++
+		}
+	}
+	return groups, keywords
+}
+
+// PrometheusMetrics returns the metrics of the last monitoring run in the Prometheus text
+// exposition format
+func (s *Service) PrometheusMetrics() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := s.metrics
+
+	var b strings.Builder
+	writeMetric := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	writeMetric("aks_mentions_last_run_mentions", "Mentions found by the last monitoring run.")
+	fmt.Fprintf(&b, "aks_mentions_last_run_mentions %d\n", m.TotalMentions)
+	writeMetric("aks_mentions_last_run_errors", "Source errors of the last monitoring run.")
+	fmt.Fprintf(&b, "aks_mentions_last_run_errors %d\n", m.ErrorCount)
+	if !m.LastRun.IsZero() {
+		writeMetric("aks_mentions_last_run_timestamp_seconds", "Unix time the last monitoring run completed.")
+		fmt.Fprintf(&b, "aks_mentions_last_run_timestamp_seconds %d\n", m.LastRun.Unix())
+	}
+
+	writeMetric("aks_mentions_source_mentions", "Mentions of the last monitoring run by source.")
+	for _, source := range sortedKeys(m.SourceMetrics) {
+		fmt.Fprintf(&b, "aks_mentions_source_mentions{source=%s} %d\n", promLabel(source), m.SourceMetrics[source])
+	}
+	writeMetric("aks_mentions_sentiment_mentions", "Mentions of the last monitoring run by sentiment.")
+	for _, sentiment := range sortedKeys(m.SentimentBreakdown) {
+		fmt.Fprintf(&b, "aks_mentions_sentiment_mentions{sentiment=%s} %d\n", promLabel(sentiment), m.SentimentBreakdown[sentiment])
+	}
+
+	writeMetric("aks_mentions_keyword_group_mentions", "Mentions of the last monitoring run by keyword group.")
+	for _, group := range sortedKeys(m.KeywordGroupMetrics) {
+		fmt.Fprintf(&b, "aks_mentions_keyword_group_mentions{group=%s} %d\n", promLabel(group), m.KeywordGroupMetrics[group])
+	}
+
+	// Totals list every searched keyword, those without matches at 0
+	var keywords []string
+	for keyword := range m.KeywordMetrics {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	writeMetric("aks_mentions_keyword_mentions", "Mentions of the last monitoring run matching each keyword.")
+	for _, keyword := range keywords {
+		total := 0
+		for _, count := range m.KeywordMetrics[keyword] {
+			total += count
+		}
+		fmt.Fprintf(&b, "aks_mentions_keyword_mentions{keyword=%s} %d\n", promLabel(keyword), total)
+	}
+	writeMetric("aks_mentions_keyword_source_mentions", "Mentions of the last monitoring run matching each keyword, by source.")
+	for _, keyword := range keywords {
+		bySource := m.KeywordMetrics[keyword]
+		for _, source := range sortedKeys(bySource) {
+			fmt.Fprintf(&b, "aks_mentions_keyword_source_mentions{keyword=%s,source=%s} %d\n", promLabel(keyword), promLabel(source), bySource[source])
+		}
+	}
+
+	return b.String()
+}
+
+// sortedKeys returns the keys of a count map in order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// promLabel quotes a Prometheus label value, escaping backslashes, quotes and newlines
+func promLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
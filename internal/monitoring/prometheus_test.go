@@ -0,0 +1,47 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_KeywordMetrics(t *testing.T) {
+	service := &Service{
+		config: &config.Config{
+			Keywords:      []string{"AKS", "KAITO", "Azure Kubernetes Fleet Manager"},
+			KeywordGroups: map[string][]string{"kaito": {"KAITO"}, "fleet": {"Azure Kubernetes Fleet Manager"}},
+		},
+		metrics: &Metrics{},
+	}
+
+	service.updateMetrics([]models.Mention{
+		{ID: "1", Source: "reddit", Keywords: []string{"AKS"}, Sentiment: "negative"},
+		{ID: "2", Source: "reddit", Keywords: []string{"aks", "kaito"}, Sentiment: "positive"},
+		{ID: "3", Source: "hackernews", Keywords: []string{"KAITO"}, Sentiment: "neutral"},
+	}, time.Minute, 0)
+
+	var metrics Metrics
+	require.NoError(t, json.Unmarshal([]byte(service.GetMetrics()), &metrics))
+	assert.Equal(t, map[string]int{"kaito": 2, "fleet": 0}, metrics.KeywordGroupMetrics)
+	assert.Equal(t, map[string]map[string]int{
+		"AKS":                            {"reddit": 2},
+		"KAITO":                          {"reddit": 1, "hackernews": 1},
+		"Azure Kubernetes Fleet Manager": {},
+	}, metrics.KeywordMetrics, "keywords are counted under their configured spelling, dead ones included")
+
+	exposition := service.PrometheusMetrics()
+	assert.Contains(t, exposition, "# TYPE aks_mentions_keyword_mentions gauge\n")
+	assert.Contains(t, exposition, "aks_mentions_last_run_mentions 3\n")
+	assert.Contains(t, exposition, `aks_mentions_source_mentions{source="reddit"} 2`+"\n")
+	assert.Contains(t, exposition, `aks_mentions_keyword_group_mentions{group="fleet"} 0`+"\n")
+	assert.Contains(t, exposition, `aks_mentions_keyword_mentions{keyword="Azure Kubernetes Fleet Manager"} 0`+"\n")
+	assert.Contains(t, exposition, `aks_mentions_keyword_mentions{keyword="KAITO"} 2`+"\n")
+	assert.Contains(t, exposition, `aks_mentions_keyword_source_mentions{keyword="KAITO",source="hackernews"} 1`+"\n")
+	assert.Equal(t, `"say \"hi\"\n"`, promLabel("say \"hi\"\n"))
+}
@@ -0,0 +1,117 @@
+package monitoring
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxUnansweredQuestions caps the "needs an answer" report section
+	maxUnansweredQuestions = 20
+	// questionLLMTimeout bounds a single LLM question classification
+	questionLLMTimeout = 15 * time.Second
+)
+
+// questionOpeners are words that start a question when they lead a title
+var questionOpeners = []string{
+	"how", "what", "why", "when", "where", "which", "who", "is", "are", "can", "could",
+	"does", "do", "should", "would", "will", "has", "have", "anyone", "help",
+}
+
+// questionPhrases signal a request for help anywhere in the text
+var questionPhrases = []string{
+	"how do i", "how can i", "how to", "is there a way", "does anyone", "has anyone",
+	"anyone know", "any ideas", "any suggestions", "need help", "please help",
+	"what am i missing", "what's the best way", "what is the best way",
+}
+
+// detectQuestion classifies a mention as a question or a statement. Heuristics decide the
+// clear cases; ambiguous ones go to the LLM when LLM question detection is enabled. It reports
+// whether the answer is final: false when the LLM should have been asked but failed or was
+// skipped, so the heuristic guess is not worth caching
+func (s *Service) detectQuestion(ctx context.Context, mention models.Mention) (isQuestion, final bool) {
+	isQuestion, certain := classifyQuestion(mention)
+	if !certain && s.llm != nil && s.config.EnableLLMQuestionDetection {
+		if !paidEnrichment(ctx) {
+			return isQuestion, false
+		}
+		answer, err := s.classifyQuestionWithLLM(ctx, mention)
+		if err != nil {
+			logrus.Debugf("LLM question detection failed for %s, using heuristics: %v", mention.ID, err)
+			return isQuestion, false
+		}
+		isQuestion = answer
+	}
+	return isQuestion, true
+}
+
+// classifyQuestion applies question heuristics, reporting whether the answer is certain
+func classifyQuestion(mention models.Mention) (isQuestion, certain bool) {
+	// Stack Overflow and the other Stack Exchange sites only host questions
+	if mention.Source == "stackoverflow" {
+		return true, true
+	}
+
+	title := strings.ToLower(strings.TrimSpace(mention.Title))
+	if strings.HasSuffix(title, "?") {
+		return true, true
+	}
+
+	if fields := strings.Fields(title); len(fields) > 0 {
+		first := strings.Trim(fields[0], "[]():,")
+		for _, opener := range questionOpeners {
+			if first == opener {
+				return true, true
+			}
+		}
+	}
+
+	content := strings.ToLower(mention.Title + " " + mention.Content)
+	for _, phrase := range questionPhrases {
+		if strings.Contains(content, phrase) {
+			return true, true
+		}
+	}
+
+	// A question mark in the body may be rhetorical; leave it to the LLM when available
+	if strings.Contains(mention.Content, "?") {
+		return true, false
+	}
+
+	return false, false
+}
+
+func (s *Service) classifyQuestionWithLLM(ctx context.Context, mention models.Mention) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, questionLLMTimeout)
+	defer cancel()
+
+	content := truncateRunes(mention.Content, llmMaxInput)
+
+	return s.llm.YesNo(ctx,
+		"You classify community posts about Azure Kubernetes Service. Decide whether the author is asking a question or requesting help that someone could answer.",
+		"Title: "+mention.Title+"\n\n"+content)
+}
+
+// collectUnansweredQuestions lists questions that have no answers or comments yet, oldest first
+func (s *Service) collectUnansweredQuestions(mentions []models.Mention) []models.Mention {
+	var unanswered []models.Mention
+	for _, mention := range mentions {
+		if mention.IsQuestion && mention.CommentCount == 0 {
+			unanswered = append(unanswered, mention)
+		}
+	}
+
+	sort.Slice(unanswered, func(a, b int) bool {
+		return unanswered[a].CreatedAt.Before(unanswered[b].CreatedAt)
+	})
+
+	if len(unanswered) > maxUnansweredQuestions {
+		unanswered = unanswered[:maxUnansweredQuestions]
+	}
+	return unanswered
+}
@@ -0,0 +1,164 @@
+package monitoring
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// defaultSourceTrust is how much the ranking formula trusts each source's mentions when
+// MENTION_SOURCE_TRUST doesn't say; unlisted sources get defaultTrust
+var defaultSourceTrust = map[string]float64{
+	"cve":           1,
+	"stackoverflow": 0.8,
+	"submitted":     0.8,
+	"hackernews":    0.7,
+	"gitlab":        0.7,
+	"github":        0.7,
+	"bitbucket":     0.7,
+	"reddit":        0.6,
+	"podcast":       0.6,
+	"linkedin":      0.5,
+	"linkedinpage":  0.5,
+	"medium":        0.5,
+	"qiita":         0.5,
+	"cnfeeds":       0.5,
+	"twitter":       0.5,
+	"threads":       0.4,
+	"youtube":       0.4,
+	"web":           0.4,
+}
+
+// defaultTrust is the trust of sources without a configured or built-in level
+const defaultTrust = 0.5
+
+// sentimentRank puts the mentions most likely to need a response first
+var sentimentRank = map[string]float64{
+	"negative": 1,
+	"neutral":  0.5,
+	"positive": 0,
+}
+
+// rankMentions orders mentions by the weighted MENTION_RANK_WEIGHTS formula, recording each
+// mention's position, score and factor values on it. Every factor is scaled to 0-1 within the
+// report: engagement and author influence on a log scale against the report's busiest mention
+// and author, recency from the oldest mention (0) to the newest (1). Ties keep the newest first.
+// The mentions are copied, so the caller's slice keeps its order.
+func (s *Service) rankMentions(mentions []models.Mention) []models.Mention {
+	ranked := make([]models.Mention, len(mentions))
+	copy(ranked, mentions)
+	if len(ranked) == 0 {
+		return ranked
+	}
+
+	weights := s.config.MentionRankWeights
+	if len(weights) == 0 {
+		weights = config.DefaultRankWeights
+	}
+	totalWeight := 0.0
+	for _, factor := range config.RankFactors {
+		totalWeight += weights[factor]
+	}
+
+	authorEngagement := make(map[string]int)
+	maxEngagement, maxAuthor := 0, 0
+	newest, oldest := ranked[0].CreatedAt, ranked[0].CreatedAt
+	for _, mention := range ranked {
+		maxEngagement = max(maxEngagement, rankEngagement(mention))
+		if author := rankAuthor(mention); author != "" {
+			authorEngagement[author] += rankEngagement(mention) + 1
+			maxAuthor = max(maxAuthor, authorEngagement[author])
+		}
+		if mention.CreatedAt.After(newest) {
+			newest = mention.CreatedAt
+		}
+		if mention.CreatedAt.Before(oldest) {
+			oldest = mention.CreatedAt
+		}
+	}
+	span := newest.Sub(oldest)
+
+	for i := range ranked {
+		mention := &ranked[i]
+
+		recency := 1.0
+		if span > 0 {
+			recency = 1 - float64(newest.Sub(mention.CreatedAt))/float64(span)
+		}
+		sentiment, ok := sentimentRank[mention.Sentiment]
+		if !ok {
+			sentiment = sentimentRank["neutral"]
+		}
+
+		factors := map[string]float64{
+			config.RankFactorEngagement:      logShare(rankEngagement(*mention), maxEngagement),
+			config.RankFactorRecency:         recency,
+			config.RankFactorSentiment:       sentiment,
+			config.RankFactorSourceTrust:     s.sourceTrust(mention.Source),
+			config.RankFactorAuthorInfluence: logShare(authorEngagement[rankAuthor(*mention)], maxAuthor),
+		}
+
+		score := 0.0
+		for _, factor := range config.RankFactors {
+			factors[factor] = roundRank(factors[factor])
+			score += weights[factor] * factors[factor]
+		}
+		if totalWeight > 0 {
+			score /= totalWeight
+		}
+		mention.Rank = &models.MentionRank{Score: roundRank(score), Factors: factors}
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool {
+		if ranked[a].Rank.Score != ranked[b].Rank.Score {
+			return ranked[a].Rank.Score > ranked[b].Rank.Score
+		}
+		return ranked[a].CreatedAt.After(ranked[b].CreatedAt)
+	})
+	for i := range ranked {
+		ranked[i].Rank.Position = i + 1
+	}
+	return ranked
+}
+
+// sourceTrust returns the configured, built-in or default trust of a source
+func (s *Service) sourceTrust(source string) float64 {
+	if trust, ok := s.config.MentionSourceTrust[source]; ok {
+		return trust
+	}
+	if trust, ok := defaultSourceTrust[source]; ok {
+		return trust
+	}
+	return defaultTrust
+}
+
+// rankEngagement is a mention's upvotes or likes plus its comments, never negative
+func rankEngagement(mention models.Mention) int {
+	return max(0, mention.Score+mention.CommentCount)
+}
+
+// rankAuthor identifies a mention's author within its source, or "" when it has none
+func rankAuthor(mention models.Mention) string {
+	author := strings.ToLower(strings.TrimSpace(mention.Author))
+	if author == "" {
+		return ""
+	}
+	return mention.Source + "/" + author
+}
+
+// logShare scales value against the largest value on a log scale, so one viral post doesn't
+// flatten every other mention to 0
+func logShare(value, largest int) float64 {
+	if largest <= 0 {
+		return 0
+	}
+	return math.Log1p(float64(value)) / math.Log1p(float64(largest))
+}
+
+// roundRank rounds a ranking value to three decimals
+func roundRank(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
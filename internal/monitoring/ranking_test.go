@@ -0,0 +1,68 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_rankMentions(t *testing.T) {
+	now := time.Now()
+	mentions := []models.Mention{
+		{ID: "old_popular", Source: "reddit", Author: "alice", Score: 500, CommentCount: 99, CreatedAt: now.Add(-48 * time.Hour), Sentiment: "positive"},
+		{ID: "new_quiet", Source: "youtube", Author: "bob", CreatedAt: now, Sentiment: "neutral"},
+		{ID: "mid", Source: "stackoverflow", Author: "carol", Score: 5, CreatedAt: now.Add(-24 * time.Hour), Sentiment: "negative"},
+	}
+
+	// Engagement only
+	service := NewService(&config.Config{MentionRankWeights: map[string]float64{config.RankFactorEngagement: 1}}, nil, nil)
+	ranked := service.rankMentions(mentions)
+	require.Len(t, ranked, 3)
+	assert.Equal(t, []string{"old_popular", "mid", "new_quiet"}, mentionIDs(ranked))
+	assert.Equal(t, 1.0, ranked[0].Rank.Score)
+	assert.Equal(t, 1, ranked[0].Rank.Position)
+	assert.Equal(t, "old_popular", mentions[0].ID, "the caller's order is kept")
+	assert.Nil(t, mentions[0].Rank)
+
+	// Recency only
+	service.config.MentionRankWeights = map[string]float64{config.RankFactorRecency: 2}
+	ranked = service.rankMentions(mentions)
+	assert.Equal(t, []string{"new_quiet", "mid", "old_popular"}, mentionIDs(ranked))
+	assert.Equal(t, 0.5, ranked[1].Rank.Factors[config.RankFactorRecency])
+	assert.Equal(t, 0.5, ranked[1].Rank.Score, "scores are normalized by the total weight")
+
+	// Sentiment and configured source trust
+	service.config.MentionRankWeights = map[string]float64{config.RankFactorSentiment: 1, config.RankFactorSourceTrust: 1}
+	service.config.MentionSourceTrust = map[string]float64{"youtube": 1}
+	ranked = service.rankMentions(mentions)
+	assert.Equal(t, []string{"mid", "new_quiet", "old_popular"}, mentionIDs(ranked))
+	assert.Equal(t, 1.0, ranked[1].Rank.Factors[config.RankFactorSourceTrust])
+	assert.Equal(t, 0.6, ranked[2].Rank.Factors[config.RankFactorSourceTrust])
+	assert.Len(t, ranked[0].Rank.Factors, len(config.RankFactors))
+}
+
+func TestService_rankMentions_AuthorInfluence(t *testing.T) {
+	now := time.Now()
+	service := NewService(&config.Config{MentionRankWeights: map[string]float64{config.RankFactorAuthorInfluence: 1}}, nil, nil)
+
+	ranked := service.rankMentions([]models.Mention{
+		{ID: "one_off", Source: "reddit", Author: "newcomer", Score: 3, CreatedAt: now},
+		{ID: "regular_1", Source: "reddit", Author: "Regular", Score: 2, CreatedAt: now.Add(-time.Hour)},
+		{ID: "regular_2", Source: "reddit", Author: "regular", Score: 2, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "anonymous", Source: "hackernews", Score: 50, CreatedAt: now},
+	})
+	assert.Equal(t, []string{"regular_1", "regular_2", "one_off", "anonymous"}, mentionIDs(ranked))
+	assert.Zero(t, ranked[3].Rank.Score)
+}
+
+func mentionIDs(mentions []models.Mention) []string {
+	ids := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		ids = append(ids, mention.ID)
+	}
+	return ids
+}
@@ -0,0 +1,167 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// rejectedPrefix is where each run's context-filter rejections are stored, one blob per source
+const rejectedPrefix = "rejected/"
+
+// rejectedRetention is how long a run's rejected mentions are kept
+const rejectedRetention = 30 * 24 * time.Hour
+
+// runIDLayout is the format of run IDs, which prefix each run's blob names
+const runIDLayout = "2006-01-02-15-04-05"
+
+// Limits on the rejected mentions returned by RejectedMentions
+const (
+	DefaultRejectedLimit = 100
+	MaxRejectedLimit     = 1000
+)
+
+// storeRejected records the mentions a run's context filter or spam detection dropped, with the decision
+// behind each, so maintainers can audit false negatives. Failures are logged so they
+// never block the run.
+func (s *Service) storeRejected(runID, source string, mentions []models.Mention) {
+	if !s.config.EnableRejectionAudit || s.storage == nil || len(mentions) == 0 {
+		return
+	}
+
+	now := time.Now()
+	rejected := make([]models.RejectedMention, 0, len(mentions))
+	for _, mention := range mentions {
+		record := models.RejectedMention{
+			ID:         mention.ID,
+			Source:     mention.Source,
+			Title:      mention.Title,
+			URL:        mention.URL,
+			Snippet:    s.rejectedSnippet(mention),
+			Keywords:   mention.Keywords,
+			CreatedAt:  mention.CreatedAt,
+			RejectedAt: now,
+			Spam:       mention.Spam,
+		}
+		if mention.Filter != nil {
+			record.Filter = *mention.Filter
+		}
+		rejected = append(rejected, record)
+	}
+
+	data, err := json.Marshal(rejected)
+	if err != nil {
+		logrus.Warnf("Failed to marshal rejected mentions: %v", err)
+		return
+	}
+
+	name := fmt.Sprintf("%s%s-%s.json", rejectedPrefix, runID, source)
+	if err := s.storage.Store(name, data); err != nil {
+		logrus.Warnf("Failed to store rejected mentions from %s: %v", source, err)
+		return
+	}
+	logrus.Debugf("Recorded %d rejected mentions from %s", len(rejected), source)
+}
+
+// rejectedSnippet keeps enough of a rejected mention's content to judge the decision
+// without storing whole posts
+func (s *Service) rejectedSnippet(mention models.Mention) string {
+	if excerpt := extractExcerpt(mention.Content, mention.Keywords, excerptMaxLength); excerpt != "" {
+		return excerpt
+	}
+	if excerpt := extractExcerpt(mention.Content, s.config.Keywords, excerptMaxLength); excerpt != "" {
+		return excerpt
+	}
+
+	content := []rune(strings.TrimSpace(mention.Content))
+	if len(content) > excerptMaxLength {
+		return string(content[:excerptMaxLength-3]) + "..."
+	}
+	return string(content)
+}
+
+// pruneRejected deletes the rejected mentions of runs older than rejectedRetention. It runs
+// even with the audit disabled, so blobs from before it was turned off don't linger.
+func (s *Service) pruneRejected(now time.Time) {
+	if s.storage == nil {
+		return
+	}
+
+	names, err := s.storage.List(rejectedPrefix)
+	if err != nil {
+		logrus.Warnf("Failed to list rejected mentions: %v", err)
+		return
+	}
+
+	cutoff := now.Add(-rejectedRetention)
+	for _, name := range names {
+		if started, ok := rejectedRunStart(name); !ok || !started.Before(cutoff) {
+			continue
+		}
+		if err := s.storage.Delete(name); err != nil {
+			logrus.Warnf("Failed to delete expired rejected mentions %s: %v", name, err)
+		}
+	}
+}
+
+// RejectedMentions returns the mentions the context filter dropped since the given time,
+// newest first
+func (s *Service) RejectedMentions(since time.Time, limit int) ([]models.RejectedMention, error) {
+	if limit <= 0 {
+		limit = DefaultRejectedLimit
+	}
+	if limit > MaxRejectedLimit {
+		limit = MaxRejectedLimit
+	}
+
+	names, err := s.storage.List(rejectedPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rejected mentions: %w", err)
+	}
+
+	var rejected []models.RejectedMention
+	for _, name := range names {
+		// Runs finish well within an hour of their ID, so older runs can be skipped unread
+		if started, ok := rejectedRunStart(name); ok && started.Before(since.Add(-time.Hour)) {
+			continue
+		}
+
+		data, err := s.storage.Retrieve(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+		var batch []models.RejectedMention
+		if err := json.Unmarshal(data, &batch); err != nil {
+			logrus.Warnf("Skipping unreadable rejected mentions in %s: %v", name, err)
+			continue
+		}
+		for _, mention := range batch {
+			if !mention.RejectedAt.Before(since) {
+				rejected = append(rejected, mention)
+			}
+		}
+	}
+
+	sort.SliceStable(rejected, func(i, j int) bool {
+		return rejected[i].RejectedAt.After(rejected[j].RejectedAt)
+	})
+	if len(rejected) > limit {
+		rejected = rejected[:limit]
+	}
+	return rejected, nil
+}
+
+// rejectedRunStart parses the run start time from a rejected mentions blob name
+func rejectedRunStart(name string) (time.Time, bool) {
+	name = strings.TrimPrefix(name, rejectedPrefix)
+	if len(name) < len(runIDLayout) {
+		return time.Time{}, false
+	}
+	started, err := time.ParseInLocation(runIDLayout, name[:len(runIDLayout)], time.Local)
+	return started, err == nil
+}
@@ -0,0 +1,139 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_relevanceDecision(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+
+	strong := service.relevanceDecision(models.Mention{Source: "reddit", Title: "Azure Kubernetes Service upgrade notes"})
+	assert.True(t, strong.Kept)
+	assert.Equal(t, models.ConfidenceHigh, strong.Confidence)
+	assert.Equal(t, []string{"strong:azure kubernetes service"}, strong.Indicators)
+	assert.Contains(t, strong.Reasons, "strong indicator in title +0.10")
+
+	noisy := service.relevanceDecision(models.Mention{Source: "youtube", Title: "Question", Content: "Our aks setup on azure uses helm"})
+	assert.False(t, noisy.Kept)
+	assert.Equal(t, 0.65, noisy.Relevance)
+	assert.Equal(t, 0.7, noisy.Threshold)
+	assert.Equal(t, models.ConfidenceLow, noisy.Confidence)
+	assert.Equal(t, []string{"azure:azure", "kubernetes:helm"}, noisy.Indicators)
+	assert.Equal(t, []string{"youtube source trust -0.05"}, noisy.Reasons)
+
+	weapon := service.relevanceDecision(models.Mention{Source: "reddit", Title: "AKS rifle", Content: "azure paint"})
+	assert.False(t, weapon.Kept)
+	assert.Equal(t, "rifle", weapon.NegativeIndicator)
+
+	contextual := service.relevanceDecision(models.Mention{Source: "reddit", Title: "Question", Content: "Our aks setup on azure uses helm"})
+	assert.True(t, contextual.Kept)
+	assert.Equal(t, models.ConfidenceMedium, contextual.Confidence)
+}
+
+func TestService_relevanceDecision_TrustedYouTubeChannel(t *testing.T) {
+	service := &Service{config: &config.Config{YouTubeTrustedChannels: []string{"UCsMica"}}}
+
+	trusted := service.relevanceDecision(models.Mention{Source: "youtube", Channel: "UCsMica", Author: "Microsoft Developer", Title: "AKS automatic in 10 minutes"})
+	assert.True(t, trusted.Kept)
+	assert.Equal(t, []string{"trusted youtube channel Microsoft Developer"}, trusted.Reasons)
+
+	// Channels are trusted by ID, so another channel or a commenter can't claim trust by name
+	impostor := service.relevanceDecision(models.Mention{Source: "youtube", Channel: "UCother", Author: "Microsoft Developer", Title: "AKS automatic in 10 minutes"})
+	assert.False(t, impostor.Kept)
+	comment := service.relevanceDecision(models.Mention{Source: "youtube", Author: "Microsoft Developer", Title: "AKS automatic in 10 minutes"})
+	assert.False(t, comment.Kept)
+}
+
+func TestService_runPipeline_recordsRejected(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{
+		config: &config.Config{
+			EnableContextFiltering: true,
+			EnableRejectionAudit:   true,
+			SourceTimeout:          time.Second,
+		},
+		storage: storage,
+	}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "Great AKS cluster upgrade", Content: "Azure Kubernetes Service works great"},
+			{ID: "reddit_2", Source: "reddit", Title: "AKS-47 rifle review", Content: "gun range day", Keywords: []string{"AKS"}},
+		}},
+	}
+
+	runID := time.Now().Format(runIDLayout)
+	result := service.runPipeline(context.Background(), runID, []string{"aks"}, fixedWindow(time.Hour))
+	require.Len(t, result.mentions, 1)
+	require.NotNil(t, result.mentions[0].Filter, "kept mentions carry their decision")
+	assert.Equal(t, models.ConfidenceHigh, result.mentions[0].Filter.Confidence)
+
+	var stored []models.RejectedMention
+	require.NoError(t, json.Unmarshal(storage.Blobs()["rejected/"+runID+"-reddit.json"], &stored))
+	require.Len(t, stored, 1)
+	assert.Equal(t, "reddit_2", stored[0].ID)
+	assert.Equal(t, "gun range day", stored[0].Snippet)
+	assert.Equal(t, "rifle", stored[0].Filter.NegativeIndicator)
+
+	rejected, err := service.RejectedMentions(time.Now().Add(-time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "reddit_2", rejected[0].ID)
+
+	rejected, err = service.RejectedMentions(time.Now().Add(time.Minute), 0)
+	require.NoError(t, err)
+	assert.Empty(t, rejected)
+}
+
+func TestService_RejectedMentions(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{}, storage: storage}
+	now := time.Now()
+
+	store := func(name string, mentions ...models.RejectedMention) {
+		data, err := json.Marshal(mentions)
+		require.NoError(t, err)
+		require.NoError(t, storage.Store(name, data))
+	}
+	store("rejected/"+now.Add(-10*24*time.Hour).Format(runIDLayout)+"-reddit.json",
+		models.RejectedMention{ID: "old", RejectedAt: now.Add(-10 * 24 * time.Hour)})
+	store("rejected/"+now.Add(-2*time.Hour).Format(runIDLayout)+"-reddit.json",
+		models.RejectedMention{ID: "earlier", RejectedAt: now.Add(-2 * time.Hour)},
+		models.RejectedMention{ID: "latest", RejectedAt: now.Add(-time.Hour)})
+
+	rejected, err := service.RejectedMentions(now.Add(-24*time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, rejected, 2)
+	assert.Equal(t, "latest", rejected[0].ID, "newest first")
+
+	rejected, err = service.RejectedMentions(now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Len(t, rejected, 1)
+}
+
+func TestService_pruneRejected(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{}, storage: storage}
+	now := time.Now()
+
+	expired := "rejected/" + now.Add(-rejectedRetention-time.Hour).Format(runIDLayout) + "-reddit.json"
+	recent := "rejected/" + now.Add(-2*time.Hour).Format(runIDLayout) + "-reddit.json"
+	for _, name := range []string{expired, recent} {
+		require.NoError(t, storage.Store(name, []byte("[]")))
+	}
+
+	service.pruneRejected(now)
+
+	names, err := storage.List(rejectedPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent}, names)
+}
@@ -0,0 +1,309 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Classes of source fetch errors
+const (
+	ErrorClassAuth      = "auth"
+	ErrorClassRateLimit = "rate_limit"
+	ErrorClassTimeout   = "timeout"
+	ErrorClassParse     = "parse"
+	ErrorClassServer    = "server"
+	ErrorClassSkipped   = "skipped" // the source's circuit breaker was open
+	ErrorClassOther     = "other"
+)
+
+const (
+	// runErrorsBlob holds the classified source errors of recent runs
+	runErrorsBlob = "reliability/runs.json"
+	// runErrorRetention is how long the source errors of a run are kept
+	runErrorRetention = 30 * 24 * time.Hour
+	// reliabilityWindow is the trailing window the source reliability section and counters cover
+	reliabilityWindow = 7 * 24 * time.Hour
+	// reliabilityStateBlob records the last week a report had a source reliability section
+	reliabilityStateBlob = "reliability/reported.json"
+)
+
+// statusCodePattern finds the HTTP status sources put in their errors, e.g. "returned status 429"
+var statusCodePattern = regexp.MustCompile(`status (\d{3})\b`)
+
+// RunErrors records which sources a run fetched and the class of each failure
+type RunErrors struct {
+	RunID     string                 `json:"run_id"`
+	Kind      string                 `json:"kind"`
+	StartedAt time.Time              `json:"started_at"`
+	Sources   []string               `json:"sources"`
+	Errors    map[string]SourceError `json:"errors,omitempty"`
+}
+
+// SourceError is the classified fetch error of a source in one run
+type SourceError struct {
+	Class   string `json:"class"`
+	Message string `json:"message"`
+}
+
+type reliabilityState struct {
+	Week string `json:"week"` // ISO week, e.g. "2024-W07"
+}
+
+// classifySourceError sorts a fetch error into the error taxonomy. Sources report HTTP
+// failures as "... returned status <code>", so the status decides where one is present.
+func classifySourceError(err error) string {
+	var (
+		netErr    net.Error
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		xmlErr    *xml.SyntaxError
+	)
+	switch {
+	case errors.Is(err, ErrSourceSkipped):
+		return ErrorClassSkipped
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.As(err, &xmlErr):
+		return ErrorClassParse
+	}
+
+	message := strings.ToLower(err.Error())
+	if match := statusCodePattern.FindStringSubmatch(message); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		switch {
+		case code == 401 || code == 403:
+			return ErrorClassAuth
+		case code == 429:
+			return ErrorClassRateLimit
+		case code >= 500:
+			return ErrorClassServer
+		}
+	}
+
+	switch {
+	case strings.Contains(message, "authentication") || strings.Contains(message, "unauthorized") || strings.Contains(message, "forbidden"):
+		return ErrorClassAuth
+	case strings.Contains(message, "rate limit") || strings.Contains(message, "too many requests"):
+		return ErrorClassRateLimit
+	case strings.Contains(message, "timeout") || strings.Contains(message, "timed out"):
+		return ErrorClassTimeout
+	case strings.Contains(message, "failed to parse"):
+		return ErrorClassParse
+	}
+	return ErrorClassOther
+}
+
+// recordRunErrors classifies the fetch error of each source a run fetched, keyed by source
+// with nil for a success, adds the counts to the metrics and stores them. Fetches cancelled by
+// a shutdown are left out. Failures are logged so they never fail a run.
+func (s *Service) recordRunErrors(runID, kind string, start time.Time, outcomes map[string]error) {
+	run := RunErrors{RunID: runID, Kind: kind, StartedAt: start, Errors: make(map[string]SourceError)}
+	classes := make(map[string]int)
+	for source, err := range outcomes {
+		if errors.Is(err, context.Canceled) {
+			continue
+		}
+		run.Sources = append(run.Sources, source)
+		if err == nil {
+			continue
+		}
+		class := classifySourceError(err)
+		run.Errors[source] = SourceError{Class: class, Message: err.Error()}
+		classes[class]++
+	}
+	sort.Strings(run.Sources)
+
+	var week map[string]int
+	if s.storage != nil {
+		week = s.storeRunErrors(run)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kind == RunKindMonitoring {
+		s.metrics.LastRunErrorClasses = classes
+	}
+	if week != nil {
+		s.metrics.ErrorClasses7Days = week
+	}
+}
+
+// storeRunErrors adds a run to the stored run errors, dropping those past retention, and
+// counts the error classes of the last week. Nothing is stored when the history can't be
+// loaded, since storing the run alone would replace it.
+func (s *Service) storeRunErrors(run RunErrors) map[string]int {
+	s.reliabilityMu.Lock()
+	defer s.reliabilityMu.Unlock()
+
+	runs, err := s.loadRunErrors()
+	if err != nil {
+		logrus.Warnf("Failed to load run errors, not recording run %s: %v", run.RunID, err)
+		return nil
+	}
+
+	cutoff := time.Now().Add(-runErrorRetention)
+	kept := runs[:0]
+	for _, previous := range runs {
+		if previous.StartedAt.After(cutoff) {
+			kept = append(kept, previous)
+		}
+	}
+	runs = append(kept, run)
+
+	if data, err := json.Marshal(runs); err != nil {
+		logrus.Warnf("Failed to marshal run errors: %v", err)
+	} else if err := s.storage.Store(runErrorsBlob, data); err != nil {
+		logrus.Warnf("Failed to store run errors: %v", err)
+	}
+	return countErrorClasses(runs, time.Now().Add(-reliabilityWindow))
+}
+
+// countErrorClasses totals the source errors of the runs started since the given time by class
+func countErrorClasses(runs []RunErrors, since time.Time) map[string]int {
+	classes := make(map[string]int)
+	for _, run := range runs {
+		if run.StartedAt.Before(since) {
+			continue
+		}
+		for _, sourceErr := range run.Errors {
+			classes[sourceErr.Class]++
+		}
+	}
+	return classes
+}
+
+// weeklyReliability summarizes the last week's source errors for the first report of each ISO
+// week, returning the week to mark reported once the report is sent. It returns no summary
+// when no source failed.
+func (s *Service) weeklyReliability(now time.Time) (*models.SourceReliability, string) {
+	if s.storage == nil {
+		return nil, ""
+	}
+
+	year, number := now.UTC().ISOWeek()
+	week := fmt.Sprintf("%d-W%02d", year, number)
+
+	reported, err := s.reliabilityReportedWeek()
+	if err != nil {
+		logrus.Warnf("Failed to load source reliability state: %v", err)
+		return nil, ""
+	}
+	if reported == week {
+		return nil, ""
+	}
+
+	s.reliabilityMu.Lock()
+	runs, err := s.loadRunErrors()
+	s.reliabilityMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load run errors for source reliability: %v", err)
+		return nil, ""
+	}
+
+	reliability := summarizeReliability(runs, now.Add(-reliabilityWindow), s.config.SourceErrorBudget)
+	if len(reliability.Sources) == 0 {
+		return nil, week
+	}
+	return reliability, week
+}
+
+// summarizeReliability counts the fetches and errors of each source in the runs, oldest first,
+// started since the given time and lists the sources that failed, highest failure rate first
+func summarizeReliability(runs []RunErrors, since time.Time, budget float64) *models.SourceReliability {
+	rates := make(map[string]*models.SourceErrorRate)
+	for _, run := range runs {
+		if run.StartedAt.Before(since) {
+			continue
+		}
+		for _, source := range run.Sources {
+			rate, ok := rates[source]
+			if !ok {
+				rate = &models.SourceErrorRate{Source: source, Classes: make(map[string]int)}
+				rates[source] = rate
+			}
+			rate.Fetches++
+			if sourceErr, failed := run.Errors[source]; failed {
+				rate.Failures++
+				rate.Classes[sourceErr.Class]++
+				rate.LastError = sourceErr.Message
+			}
+		}
+	}
+
+	reliability := &models.SourceReliability{Since: since, Budget: budget, Sources: []models.SourceErrorRate{}}
+	for _, rate := range rates {
+		if rate.Failures == 0 {
+			continue
+		}
+		rate.OverBudget = float64(rate.Failures) > budget*float64(rate.Fetches)
+		reliability.Sources = append(reliability.Sources, *rate)
+	}
+	sort.Slice(reliability.Sources, func(i, j int) bool {
+		a, b := reliability.Sources[i], reliability.Sources[j]
+		if a.Failures*b.Fetches != b.Failures*a.Fetches {
+			return a.Failures*b.Fetches > b.Failures*a.Fetches
+		}
+		return a.Source < b.Source
+	})
+	return reliability
+}
+
+// reliabilityReportedWeek returns the last week a report covered, "" before the first
+func (s *Service) reliabilityReportedWeek() (string, error) {
+	data, found, err := storage.RetrieveIfExists(s.storage, reliabilityStateBlob)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve %s: %w", reliabilityStateBlob, err)
+	}
+	if !found {
+		return "", nil
+	}
+	var state reliabilityState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", reliabilityStateBlob, err)
+	}
+	return state.Week, nil
+}
+
+// markReliabilityReported records that a sent report covered the week
+func (s *Service) markReliabilityReported(week string) {
+	if week == "" {
+		return
+	}
+
+	data, err := json.Marshal(reliabilityState{Week: week})
+	if err == nil {
+		err = s.storage.Store(reliabilityStateBlob, data)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to record source reliability report: %v", err)
+	}
+}
+
+// loadRunErrors reads the stored run errors, oldest first; callers must hold s.reliabilityMu
+func (s *Service) loadRunErrors() ([]RunErrors, error) {
+	data, found, err := storage.RetrieveIfExists(s.storage, runErrorsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve run errors: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var runs []RunErrors
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return nil, fmt.Errorf("failed to parse run errors: %w", err)
+	}
+	return runs, nil
+}
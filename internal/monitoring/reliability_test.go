@@ -0,0 +1,90 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifySourceError(t *testing.T) {
+	syntaxErr := json.Unmarshal([]byte("<html>"), &struct{}{})
+
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{fmt.Errorf("reddit authentication failed: %w", errors.New("invalid_grant")), ErrorClassAuth},
+		{errors.New("twitter API returned status 401: Unauthorized"), ErrorClassAuth},
+		{errors.New("youtube API returned status 403: quotaExceeded"), ErrorClassAuth},
+		{errors.New("reddit API returned status 429"), ErrorClassRateLimit},
+		{errors.New("stack overflow API returned status 503: Service Unavailable"), ErrorClassServer},
+		{fmt.Errorf("fetch: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{fmt.Errorf("failed to parse Stack Overflow response: %w", syntaxErr), ErrorClassParse},
+		{fmt.Errorf("%w until 2024-01-01T00:00:00Z after 3 consecutive failures: 401", ErrSourceSkipped), ErrorClassSkipped},
+		{errors.New("repository or issue tracker not found"), ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.class, classifySourceError(tt.err), tt.err.Error())
+	}
+}
+
+func TestService_recordRunErrors(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{SourceErrorBudget: 0.25}, storage: store, metrics: &Metrics{}}
+
+	start := time.Now().Add(-time.Hour)
+	service.recordRunErrors("run-1", RunKindMonitoring, start, map[string]error{
+		"reddit":     errors.New("reddit API returned status 401"),
+		"hackernews": nil,
+		"twitter":    context.Canceled,
+	})
+	service.recordRunErrors("run-2", RunKindUrgent, start, map[string]error{
+		"reddit":     errors.New("reddit API returned status 429"),
+		"hackernews": fmt.Errorf("fetch: %w", context.DeadlineExceeded),
+	})
+	for i := 0; i < 3; i++ {
+		service.recordRunErrors(fmt.Sprintf("run-%d", i+3), RunKindUrgent, start, map[string]error{"hackernews": nil})
+	}
+
+	assert.Equal(t, map[string]int{ErrorClassAuth: 1}, service.metrics.LastRunErrorClasses, "urgent checks leave the last run counters")
+	assert.Equal(t, map[string]int{ErrorClassAuth: 1, ErrorClassRateLimit: 1, ErrorClassTimeout: 1}, service.metrics.ErrorClasses7Days)
+
+	// The first report of a week lists the failing sources, worst first
+	now := time.Now()
+	reliability, week := service.weeklyReliability(now)
+	require.NotNil(t, reliability)
+	require.Len(t, reliability.Sources, 2)
+	assert.Equal(t, "reddit", reliability.Sources[0].Source)
+	assert.Equal(t, 2, reliability.Sources[0].Fetches)
+	assert.True(t, reliability.Sources[0].OverBudget)
+	assert.Equal(t, "2 of 2 fetches failed (100%, over budget): 1 auth, 1 rate_limit", reliability.Sources[0].Summary())
+	assert.Equal(t, "hackernews", reliability.Sources[1].Source)
+	assert.False(t, reliability.Sources[1].OverBudget, "1 of 5 fetches is within a 25% budget")
+
+	// Later reports that week leave the section out
+	service.markReliabilityReported(week)
+	reliability, week = service.weeklyReliability(now)
+	assert.Nil(t, reliability)
+	assert.Empty(t, week)
+}
+
+func TestService_recordRunErrorsKeepsUnreadableHistory(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{}, storage: store, metrics: &Metrics{}}
+	require.NoError(t, store.Store(runErrorsBlob, []byte("{")))
+
+	service.recordRunErrors("run-1", RunKindMonitoring, time.Now(), map[string]error{"reddit": errors.New("reddit API returned status 401")})
+
+	assert.Equal(t, "{", string(store.Blobs()[runErrorsBlob]), "a transient load error doesn't replace the history")
+	assert.Equal(t, map[string]int{ErrorClassAuth: 1}, service.metrics.LastRunErrorClasses)
+	assert.Nil(t, service.metrics.ErrorClasses7Days)
+}
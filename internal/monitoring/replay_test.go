@@ -0,0 +1,59 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestService_replayedRun runs the whole pipeline, from fetching through filtering, sentiment
+// analysis and storage to the report, on the recorded fixtures in testdata/fixtures
+func TestService_replayedRun(t *testing.T) {
+	cfg := &config.Config{
+		ReportSchedule:          "daily",
+		Keywords:                []string{"AKS", "KAITO"},
+		EnableContextFiltering:  true,
+		EnableSentimentAnalysis: true,
+		EnableRejectionAudit:    true,
+		SourceConcurrency:       2,
+		SourceTimeout:           time.Minute,
+		SourceErrorBudget:       0.1,
+		SourceFixtures:          "testdata/fixtures",
+		SourceFixtureMode:       config.FixtureModeReplay,
+	}
+	notifications := testutil.NewRecordingNotificationService()
+	service := NewService(cfg, testutil.NewMemoryStorage(), notifications)
+	require.NoError(t, service.RunMonitoring())
+
+	require.Len(t, notifications.Reports(), 1)
+	report := notifications.Reports()[0]
+
+	// The rifle post is filtered out and the Stack Overflow question from April is outside the window
+	sentiments := make(map[string]string)
+	for _, mention := range report.Mentions {
+		sentiments[mention.ID] = mention.Sentiment
+	}
+	assert.Equal(t, map[string]string{
+		"reddit_1cl0aks": "negative",
+		"hn_40270001":    "positive",
+		"so_78441234":    "neutral",
+	}, sentiments)
+	assert.Equal(t, 3, report.TotalMentions)
+	require.Len(t, report.Unanswered, 1)
+	assert.Equal(t, "so_78441234", report.Unanswered[0].ID)
+
+	rejected, err := service.RejectedMentions(time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "reddit_1cl1rfl", rejected[0].ID)
+
+	// The recorded X API failure is counted and classified
+	require.NotNil(t, report.Reliability)
+	require.Len(t, report.Reliability.Sources, 1)
+	assert.Equal(t, "twitter", report.Reliability.Sources[0].Source)
+	assert.Equal(t, map[string]int{ErrorClassRateLimit: 1}, report.Reliability.Sources[0].Classes)
+}
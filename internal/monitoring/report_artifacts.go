@@ -0,0 +1,93 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/reports"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnknownReport is returned when no report is stored under the requested ID
+var ErrUnknownReport = errors.New("unknown report")
+
+// reportIDPattern matches report IDs: the report's generation time and a random suffix
+var reportIDPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-\d{2}-\d{2}-\d{2}-[0-9a-f]{16}$`)
+
+// reportBlobName is where the report with the given ID and extension ("html", "pdf" or
+// "json" for its snapshot) is stored
+func reportBlobName(id, ext string) string {
+	return fmt.Sprintf("reports/%s.%s", id, ext)
+}
+
+// publishReportArtifact renders the report as standalone HTML and, when enabled, as PDF,
+// stores them and links the HTML page from the report. Failures are logged so they never
+// block the report itself.
+func (s *Service) publishReportArtifact(report *models.Report) {
+	if (!s.config.EnableHTMLReports && !s.config.EnablePDFReports) || s.storage == nil {
+		return
+	}
+
+	page, err := reports.RenderHTML(report)
+	if err != nil {
+		logrus.Warnf("Failed to render HTML report: %v", err)
+		return
+	}
+
+	id := reportID(report)
+	if s.config.EnableHTMLReports {
+		if err := s.storage.Store(reportBlobName(id, "html"), page); err != nil {
+			logrus.Warnf("Failed to store HTML report: %v", err)
+		} else {
+			if s.config.PublicBaseURL != "" {
+				report.ReportURL = fmt.Sprintf("%s/reports/%s", s.config.PublicBaseURL, url.PathEscape(id))
+			}
+			logrus.Infof("Stored HTML report %s", reportBlobName(id, "html"))
+		}
+	}
+
+	if s.config.EnablePDFReports {
+		pdf, err := reports.NewPDFRenderer(s.config.ChromePath).WithNoSandbox(s.config.ChromeNoSandbox).RenderPDF(context.Background(), page)
+		if err != nil {
+			logrus.Warnf("Failed to render PDF report: %v", err)
+			return
+		}
+		if err := s.storage.Store(reportBlobName(id, "pdf"), pdf); err != nil {
+			logrus.Warnf("Failed to store PDF report: %v", err)
+			return
+		}
+		logrus.Infof("Stored PDF report %s", reportBlobName(id, "pdf"))
+	}
+}
+
+// ReportArtifact returns the stored HTML report with the given ID
+func (s *Service) ReportArtifact(id string) ([]byte, error) {
+	return s.reportArtifact(id, "html")
+}
+
+// ReportPDF returns the stored PDF report with the given ID
+func (s *Service) ReportPDF(id string) ([]byte, error) {
+	return s.reportArtifact(id, "pdf")
+}
+
+func (s *Service) reportArtifact(id, ext string) ([]byte, error) {
+	if !reportIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownReport, id)
+	}
+
+	name := reportBlobName(id, ext)
+	names, err := s.storage.List(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check report: %w", err)
+	}
+	for _, existing := range names {
+		if existing == name {
+			return s.storage.Retrieve(name)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownReport, id)
+}
@@ -0,0 +1,50 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_publishReportArtifact(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{
+		config:  &config.Config{EnableHTMLReports: true, PublicBaseURL: "https://bot.example.com"},
+		storage: storage,
+	}
+
+	generated := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	report := service.generateReport([]models.Mention{{Source: "reddit", Sentiment: "positive", CreatedAt: generated}})
+	report.GeneratedAt = generated
+
+	service.publishReportArtifact(report)
+	assert.Regexp(t, `^2024-06-03-09-00-00-[0-9a-f]{16}$`, report.ID)
+	assert.Equal(t, "https://bot.example.com/reports/"+report.ID, report.ReportURL)
+
+	page, err := service.ReportArtifact(report.ID)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<!DOCTYPE html>")
+
+	// The generation time alone doesn't find the report
+	_, err = service.ReportArtifact("2024-06-03-09-00-00")
+	assert.ErrorIs(t, err, ErrUnknownReport)
+	_, err = service.ReportArtifact("2024-06-03-09-00-00-0000000000000000")
+	assert.ErrorIs(t, err, ErrUnknownReport)
+	_, err = service.ReportArtifact("../preferences/recipients")
+	assert.ErrorIs(t, err, ErrUnknownReport)
+}
+
+func TestService_publishReportArtifact_Disabled(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{PublicBaseURL: "https://bot.example.com"}, storage: storage}
+
+	report := &models.Report{GeneratedAt: time.Now()}
+	service.publishReportArtifact(report)
+	assert.Empty(t, report.ReportURL)
+	assert.Empty(t, storage.Blobs())
+}
@@ -0,0 +1,383 @@
+package monitoring
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/matching"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Report aliases accepted by CompareReports in place of an ID
+const (
+	ReportLatest   = "latest"
+	ReportPrevious = "previous"
+)
+
+// topicMinMentions is how many mentions a topic needs in a report to count as present, so
+// a single stray title word is not reported as a new topic
+const topicMinMentions = 2
+
+// Bounds on the lists in a report comparison
+const (
+	maxCompareTopics  = 15
+	maxCompareAuthors = 10
+)
+
+// titleStopWords are left out of the title terms used as topics
+var titleStopWords = map[string]bool{
+	"and": true, "any": true, "are": true, "but": true, "can": true, "did": true, "for": true,
+	"get": true, "got": true, "has": true, "how": true, "its": true, "new": true, "not": true,
+	"now": true, "one": true, "our": true, "out": true, "the": true, "use": true, "was": true,
+	"way": true, "who": true, "why": true, "you": true,
+	"about": true, "after": true, "again": true, "also": true, "been": true, "being": true,
+	"best": true, "can't": true, "could": true, "doe": true, "doesn't": true,
+	"don't": true, "from": true, "have": true, "help": true, "here": true, "into": true,
+	"just": true, "more": true, "most": true, "need": true, "only": true, "other": true,
+	"over": true, "should": true, "some": true, "than": true, "that": true, "their": true,
+	"them": true, "then": true, "there": true, "these": true, "they": true, "this": true,
+	"using": true, "very": true, "want": true, "what": true, "when": true, "where": true,
+	"which": true, "while": true, "will": true, "with": true, "without": true, "would": true,
+	"your": true, "azure": true, "kubernete": true, "cluster": true, "question": true,
+}
+
+// reportSnapshot is the counts of a report kept for comparisons; mentions themselves are
+// stored with their runs
+type reportSnapshot struct {
+	ID            string         `json:"id"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Period        string         `json:"period"`
+	TotalMentions int            `json:"total_mentions"`
+	Sources       map[string]int `json:"sources"`
+	Sentiment     map[string]int `json:"sentiment"`
+	Topics        map[string]int `json:"topics"`  // "keyword:aks", "tag:gpu" or "term:upgrade" to mention counts
+	Authors       map[string]int `json:"authors"` // "source:author" to mention counts
+}
+
+// ReportRef identifies one side of a report comparison
+type ReportRef struct {
+	ID            string    `json:"-"` // Not returned, as it opens the public report page
+	GeneratedAt   time.Time `json:"generated_at"`
+	Period        string    `json:"period"`
+	TotalMentions int       `json:"total_mentions"`
+}
+
+// CountChange is how a count moved between two reports
+type CountChange struct {
+	Before  int      `json:"before"`
+	After   int      `json:"after"`
+	Change  int      `json:"change"`
+	Percent *float64 `json:"percent,omitempty"` // Relative change; absent when Before is zero
+}
+
+// TopicCount is a keyword, tag or title term and how many mentions of a report carried it
+type TopicCount struct {
+	Topic    string `json:"topic"`
+	Kind     string `json:"kind"` // "keyword", "tag" or "term"
+	Mentions int    `json:"mentions"`
+}
+
+// AuthorCount is an author and how many mentions of a report they wrote
+type AuthorCount struct {
+	Source   string `json:"source"`
+	Author   string `json:"author"`
+	Mentions int    `json:"mentions"`
+}
+
+// ReportComparison is the difference between two reports, from A to B
+type ReportComparison struct {
+	A                 ReportRef              `json:"a"`
+	B                 ReportRef              `json:"b"`
+	Volume            CountChange            `json:"volume"`
+	Sentiment         map[string]CountChange `json:"sentiment"`
+	SentimentScore    [2]*float64            `json:"sentiment_score"` // Percentage positive minus negative in A and B
+	Sources           map[string]CountChange `json:"sources"`
+	NewTopics         []TopicCount           `json:"new_topics"`
+	DisappearedTopics []TopicCount           `json:"disappeared_topics"`
+	NewAuthors        []AuthorCount          `json:"new_authors"` // Most active authors of B who wrote nothing in A
+}
+
+// reportID is the ID a report's artifacts and snapshot are stored under: its generation time,
+// so IDs sort chronologically, followed by a random suffix, so the public report pages can't
+// be found by guessing times. It is assigned on first use.
+func reportID(report *models.Report) string {
+	if report.ID == "" {
+		suffix := make([]byte, 8)
+		_, _ = rand.Read(suffix)
+		report.ID = report.GeneratedAt.UTC().Format("2006-01-02-15-04-05") + "-" + hex.EncodeToString(suffix)
+	}
+	return report.ID
+}
+
+// storeReportSnapshot keeps the counts of a report for later comparisons and, with a public
+// base URL, links the report to its comparison with the previous one. Failures are logged so
+// they never block the report itself.
+func (s *Service) storeReportSnapshot(report *models.Report) {
+	if s.storage == nil {
+		return
+	}
+
+	previous, err := s.reportSnapshotIDs()
+	if err != nil {
+		logrus.Warnf("Failed to list report snapshots: %v", err)
+	}
+
+	id := reportID(report)
+	data, err := json.Marshal(s.snapshotReport(report))
+	if err != nil {
+		logrus.Warnf("Failed to marshal report snapshot: %v", err)
+		return
+	}
+	if err := s.storage.Store(reportBlobName(id, "json"), data); err != nil {
+		logrus.Warnf("Failed to store report snapshot: %v", err)
+		return
+	}
+
+	if s.config.PublicBaseURL != "" && len(previous) > 0 && previous[len(previous)-1] != id {
+		report.CompareURL = fmt.Sprintf("%s/api/reports/compare?a=%s&b=%s", s.config.PublicBaseURL,
+			url.QueryEscape(previous[len(previous)-1]), url.QueryEscape(id))
+	}
+}
+
+func (s *Service) snapshotReport(report *models.Report) *reportSnapshot {
+	snapshot := &reportSnapshot{
+		ID:            reportID(report),
+		GeneratedAt:   report.GeneratedAt,
+		Period:        report.Period,
+		TotalMentions: report.TotalMentions,
+		Sources:       make(map[string]int),
+		Sentiment:     make(map[string]int),
+		Topics:        make(map[string]int),
+		Authors:       make(map[string]int),
+	}
+
+	keywordTokens := s.keywordTokens()
+	mentions := append(append([]models.Mention(nil), report.Mentions...), report.PreviouslyAlerted...)
+	for _, mention := range mentions {
+		snapshot.Sources[mention.Source]++
+		if mention.Sentiment != "" {
+			snapshot.Sentiment[mention.Sentiment]++
+		}
+		if author := strings.TrimSpace(mention.Author); author != "" {
+			snapshot.Authors[mention.Source+":"+author]++
+		}
+		for _, topic := range mentionTopics(mention, keywordTokens) {
+			snapshot.Topics[topic]++
+		}
+	}
+	return snapshot
+}
+
+// keywordTokens are the tokens of the configured keywords. Title terms that only restate a
+// keyword are not topics of their own.
+func (s *Service) keywordTokens() map[string]bool {
+	tokens := make(map[string]bool)
+	for _, keyword := range s.config.Keywords {
+		for _, token := range matching.Tokens(keyword) {
+			tokens[token] = true
+		}
+	}
+	return tokens
+}
+
+// mentionTopics lists the topics of a mention once each, as "kind:topic": its keywords, tags
+// and the notable terms of its title
+func mentionTopics(mention models.Mention, keywordTokens map[string]bool) []string {
+	seen := make(map[string]bool)
+	var topics []string
+	add := func(topic string) {
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+
+	for _, keyword := range mention.Keywords {
+		add("keyword:" + strings.ToLower(keyword))
+	}
+	for _, tag := range mention.Tags {
+		add("tag:" + strings.ToLower(tag))
+	}
+	for _, token := range matching.Tokens(mention.Title) {
+		if len(token) >= 3 && !titleStopWords[token] && !keywordTokens[token] && !isNumber(token) {
+			add("term:" + token)
+		}
+	}
+	return topics
+}
+
+func isNumber(token string) bool {
+	return strings.Trim(token, "0123456789.") == ""
+}
+
+// CompareReports compares the reports with IDs a and b, either of which can be
+// ReportLatest or ReportPrevious. Only reports generated since snapshots were introduced
+// can be compared.
+func (s *Service) CompareReports(a, b string) (*ReportComparison, error) {
+	before, err := s.loadReportSnapshot(a)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.loadReportSnapshot(b)
+	if err != nil {
+		return nil, err
+	}
+	return compareSnapshots(before, after), nil
+}
+
+func (s *Service) loadReportSnapshot(id string) (*reportSnapshot, error) {
+	if id == ReportLatest || id == ReportPrevious {
+		ids, err := s.reportSnapshotIDs()
+		if err != nil {
+			return nil, err
+		}
+		index := len(ids) - 1
+		if id == ReportPrevious {
+			index--
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%w: no %s report", ErrUnknownReport, id)
+		}
+		id = ids[index]
+	}
+
+	data, err := s.reportArtifact(id, "json")
+	if err != nil {
+		return nil, err
+	}
+	var snapshot reportSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// reportSnapshotIDs lists the IDs of the reports with snapshots, oldest first
+func (s *Service) reportSnapshotIDs() ([]string, error) {
+	names, err := s.storage.List("reports/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	var ids []string
+	for _, name := range names {
+		id := strings.TrimSuffix(strings.TrimPrefix(name, "reports/"), ".json")
+		if strings.HasSuffix(name, ".json") && reportIDPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	// IDs are timestamps, so they sort chronologically
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func compareSnapshots(a, b *reportSnapshot) *ReportComparison {
+	comparison := &ReportComparison{
+		A:                 a.ref(),
+		B:                 b.ref(),
+		Volume:            countChange(a.TotalMentions, b.TotalMentions),
+		Sentiment:         changes(a.Sentiment, b.Sentiment),
+		SentimentScore:    [2]*float64{a.sentimentScore(), b.sentimentScore()},
+		Sources:           changes(a.Sources, b.Sources),
+		NewTopics:         topicsOnlyIn(b.Topics, a.Topics),
+		DisappearedTopics: topicsOnlyIn(a.Topics, b.Topics),
+		NewAuthors:        []AuthorCount{},
+	}
+
+	for key, count := range b.Authors {
+		if a.Authors[key] > 0 {
+			continue
+		}
+		source, author, _ := strings.Cut(key, ":")
+		comparison.NewAuthors = append(comparison.NewAuthors, AuthorCount{Source: source, Author: author, Mentions: count})
+	}
+	sortAuthors(comparison.NewAuthors)
+	if len(comparison.NewAuthors) > maxCompareAuthors {
+		comparison.NewAuthors = comparison.NewAuthors[:maxCompareAuthors]
+	}
+
+	return comparison
+}
+
+func (r *reportSnapshot) ref() ReportRef {
+	return ReportRef{ID: r.ID, GeneratedAt: r.GeneratedAt, Period: r.Period, TotalMentions: r.TotalMentions}
+}
+
+func (r *reportSnapshot) sentimentScore() *float64 {
+	return sentimentScoreOf(r.Sentiment)
+}
+
+// sentimentScoreOf is the percentage of positive minus negative mentions in sentiment
+// counts, nil without sentiment
+func sentimentScoreOf(sentiment map[string]int) *float64 {
+	total := sentiment["positive"] + sentiment["neutral"] + sentiment["negative"]
+	if total == 0 {
+		return nil
+	}
+	score := math.Round(float64(sentiment["positive"]-sentiment["negative"])*1000/float64(total)) / 10
+	return &score
+}
+
+// sortAuthors orders authors by mentions, most first
+func sortAuthors(authors []AuthorCount) {
+	sort.Slice(authors, func(i, j int) bool {
+		x, y := authors[i], authors[j]
+		if x.Mentions != y.Mentions {
+			return x.Mentions > y.Mentions
+		}
+		return x.Source+":"+x.Author < y.Source+":"+y.Author
+	})
+}
+
+func countChange(before, after int) CountChange {
+	change := CountChange{Before: before, After: after, Change: after - before}
+	if before > 0 {
+		percent := math.Round(float64(after-before)*1000/float64(before)) / 10
+		change.Percent = &percent
+	}
+	return change
+}
+
+func changes(before, after map[string]int) map[string]CountChange {
+	result := make(map[string]CountChange)
+	for key, count := range before {
+		result[key] = countChange(count, after[key])
+	}
+	for key, count := range after {
+		if _, ok := before[key]; !ok {
+			result[key] = countChange(0, count)
+		}
+	}
+	return result
+}
+
+// topicsOnlyIn returns the topics present in one report and absent from the other, most
+// mentioned first
+func topicsOnlyIn(present, absent map[string]int) []TopicCount {
+	topics := []TopicCount{}
+	for key, count := range present {
+		if count < topicMinMentions || absent[key] > 0 {
+			continue
+		}
+		kind, topic, _ := strings.Cut(key, ":")
+		topics = append(topics, TopicCount{Topic: topic, Kind: kind, Mentions: count})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].Mentions != topics[j].Mentions {
+			return topics[i].Mentions > topics[j].Mentions
+		}
+		return topics[i].Kind+topics[i].Topic < topics[j].Kind+topics[j].Topic
+	})
+	if len(topics) > maxCompareTopics {
+		topics = topics[:maxCompareTopics]
+	}
+	return topics
+}
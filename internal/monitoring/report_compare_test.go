@@ -0,0 +1,103 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CompareReports(t *testing.T) {
+	service := &Service{
+		config:  &config.Config{Keywords: []string{"AKS"}, PublicBaseURL: "https://bot.example.com"},
+		storage: testutil.NewMemoryStorage(),
+	}
+
+	first := &models.Report{
+		GeneratedAt: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+		Period:      "weekly",
+		Mentions: []models.Mention{
+			{Source: "reddit", Author: "alice", Sentiment: "positive", Title: "AKS upgrade went smoothly", Keywords: []string{"AKS"}},
+			{Source: "reddit", Author: "bob", Sentiment: "negative", Title: "AKS upgrade stuck", Keywords: []string{"AKS"}},
+			{Source: "hackernews", Author: "carol", Sentiment: "neutral", Title: "Is AKS any good?", Keywords: []string{"AKS"}},
+		},
+	}
+	first.TotalMentions = len(first.Mentions)
+	service.storeReportSnapshot(first)
+	assert.Empty(t, first.CompareURL)
+
+	second := &models.Report{
+		GeneratedAt: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC),
+		Period:      "weekly",
+		Mentions: []models.Mention{
+			{Source: "reddit", Author: "alice", Sentiment: "negative", Title: "AKS GPU quotas", Keywords: []string{"AKS"}, Tags: []string{"gpu"}},
+			{Source: "reddit", Author: "dave", Sentiment: "negative", Title: "GPU nodes on AKS", Keywords: []string{"AKS"}, Tags: []string{"gpu"}},
+		},
+		PreviouslyAlerted: []models.Mention{
+			{Source: "stackoverflow", Author: "dave", Sentiment: "negative", Title: "AKS GPU driver crash", Keywords: []string{"AKS"}},
+		},
+	}
+	second.TotalMentions = 3
+	service.storeReportSnapshot(second)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, "https://bot.example.com/api/reports/compare?a="+first.ID+"&b="+second.ID, second.CompareURL)
+
+	comparison, err := service.CompareReports(ReportPrevious, ReportLatest)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, comparison.A.ID)
+	assert.Equal(t, second.ID, comparison.B.ID)
+	data, err := json.Marshal(comparison)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), second.ID, "comparisons don't reveal the report pages")
+
+	assert.Equal(t, 3, comparison.Volume.Before)
+	assert.Equal(t, 3, comparison.Volume.After)
+	require.NotNil(t, comparison.Volume.Percent)
+	assert.Equal(t, 0.0, *comparison.Volume.Percent)
+
+	assert.Equal(t, CountChange{Before: 1, After: 3, Change: 2, Percent: floatPtr(200)}, comparison.Sentiment["negative"])
+	assert.Equal(t, CountChange{Before: 0, After: 1, Change: 1}, comparison.Sources["stackoverflow"])
+	assert.Equal(t, 0.0, *comparison.SentimentScore[0])
+	assert.Equal(t, -100.0, *comparison.SentimentScore[1])
+
+	assert.Equal(t, []TopicCount{
+		{Topic: "gpu", Kind: "term", Mentions: 3},
+		{Topic: "gpu", Kind: "tag", Mentions: 2},
+	}, comparison.NewTopics)
+	assert.Equal(t, []TopicCount{{Topic: "upgrade", Kind: "term", Mentions: 2}}, comparison.DisappearedTopics)
+
+	// Authors are per source, so dave is new on both
+	assert.Equal(t, []AuthorCount{
+		{Source: "reddit", Author: "dave", Mentions: 1},
+		{Source: "stackoverflow", Author: "dave", Mentions: 1},
+	}, comparison.NewAuthors)
+}
+
+func TestService_CompareReports_Unknown(t *testing.T) {
+	service := &Service{config: &config.Config{}, storage: testutil.NewMemoryStorage()}
+
+	_, err := service.CompareReports(ReportPrevious, ReportLatest)
+	assert.ErrorIs(t, err, ErrUnknownReport)
+
+	report := &models.Report{GeneratedAt: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)}
+	service.storeReportSnapshot(report)
+	_, err = service.CompareReports(ReportPrevious, ReportLatest)
+	assert.ErrorIs(t, err, ErrUnknownReport)
+
+	_, err = service.CompareReports(report.ID, "../preferences/recipients")
+	assert.ErrorIs(t, err, ErrUnknownReport)
+
+	comparison, err := service.CompareReports(report.ID, ReportLatest)
+	require.NoError(t, err)
+	assert.Nil(t, comparison.Volume.Percent)
+	assert.Empty(t, comparison.NewTopics)
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
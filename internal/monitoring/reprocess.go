@@ -0,0 +1,190 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/sirupsen/logrus"
+)
+
+// Enrichment stages Reprocess can re-run over stored mentions
+const (
+	StageSentiment = "sentiment" // Mention and comment sentiment, with the phrases behind it
+	StageQuestions = "questions" // Question labels, asking the LLM when ENABLE_LLM_QUESTION_DETECTION is set
+	StageTopics    = "topics"    // Matched keywords, which report topics and keyword groups derive from
+	StageRelevance = "relevance" // Context filter relevance score and decision
+)
+
+// ReprocessStages lists the stages in the order Reprocess runs them
+var ReprocessStages = []string{StageSentiment, StageQuestions, StageTopics, StageRelevance}
+
+// ErrInvalidStage is returned for a stage name Reprocess doesn't know
+var ErrInvalidStage = errors.New("invalid stage")
+
+// ReprocessResult counts what Reprocess read and rewrote
+type ReprocessResult struct {
+	Mentions int // Stored mentions created in the period
+	Changed  int // Mentions a stage labeled differently
+	Blobs    int // Mentions blobs rewritten
+}
+
+// ParseStages parses a comma-separated list of stages, e.g. "sentiment,topics"
+func ParseStages(value string) ([]string, error) {
+	var stages []string
+	for _, stage := range strings.Split(value, ",") {
+		stage = strings.ToLower(strings.TrimSpace(stage))
+		if stage == "" {
+			continue
+		}
+		if !containsString(ReprocessStages, stage) {
+			return nil, fmt.Errorf("%w %q, expected %s", ErrInvalidStage, stage, strings.Join(ReprocessStages, ", "))
+		}
+		if !containsString(stages, stage) {
+			stages = append(stages, stage)
+		}
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("%w: no stages given, expected %s", ErrInvalidStage, strings.Join(ReprocessStages, ", "))
+	}
+	return stages, nil
+}
+
+// Reprocess re-runs enrichment stages over the stored mentions created since the given time,
+// e.g. after changing the sentiment analysis or the keywords, without fetching them again.
+// Mentions blobs holding relabeled mentions are rewritten in place, along with the mention
+// and search indexes and, with ENABLE_PARQUET_EXPORT, the Parquet partitions of their days.
+// With dryRun nothing is written. Reprocessing never drops stored mentions, even those the
+// relevance stage would now filter out.
+func (s *Service) Reprocess(ctx context.Context, since time.Time, stages []string, dryRun bool) (ReprocessResult, error) {
+	var result ReprocessResult
+	for _, stage := range stages {
+		if !containsString(ReprocessStages, stage) {
+			return result, fmt.Errorf("%w %q", ErrInvalidStage, stage)
+		}
+	}
+
+	// The index points each mention at the blob holding its latest copy
+	indexed, err := s.index.Days()
+	if err != nil {
+		return result, err
+	}
+	from := since.UTC().Truncate(24 * time.Hour)
+	byBlob := make(map[string]map[string]bool)
+	for _, day := range indexed {
+		if day.Before(from) {
+			continue
+		}
+		entries, err := s.index.Load(day)
+		if err != nil {
+			return result, fmt.Errorf("failed to load the mention index for %s: %w", day.Format("2006-01-02"), err)
+		}
+		for id, entry := range entries {
+			if entry.CreatedAt.Before(since) {
+				continue
+			}
+			if byBlob[entry.Blob] == nil {
+				byBlob[entry.Blob] = make(map[string]bool)
+			}
+			byBlob[entry.Blob][id] = true
+		}
+	}
+
+	blobs := make([]string, 0, len(byBlob))
+	for blob := range byBlob {
+		blobs = append(blobs, blob)
+	}
+	sort.Strings(blobs)
+
+	var changed []models.Mention
+	for _, blob := range blobs {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("reprocessing stopped after %d of %d blobs: %w", result.Blobs, len(blobs), err)
+		}
+
+		data, err := s.storage.Retrieve(blob)
+		if err != nil {
+			return result, fmt.Errorf("failed to retrieve %s: %w", blob, err)
+		}
+		var mentions []models.Mention
+		if err := json.Unmarshal(data, &mentions); err != nil {
+			logrus.Warnf("Skipping unreadable mentions in %s: %v", blob, err)
+			continue
+		}
+
+		var blobChanged []models.Mention
+		for i := range mentions {
+			if !byBlob[blob][mentions[i].ID] {
+				continue
+			}
+			result.Mentions++
+
+			before, _ := json.Marshal(mentions[i])
+			s.reprocessMention(ctx, &mentions[i], stages)
+			if after, _ := json.Marshal(mentions[i]); !bytes.Equal(before, after) {
+				blobChanged = append(blobChanged, mentions[i])
+			}
+		}
+		if len(blobChanged) == 0 {
+			continue
+		}
+		result.Changed += len(blobChanged)
+		changed = append(changed, blobChanged...)
+		if dryRun {
+			continue
+		}
+
+		data, err = json.Marshal(mentions)
+		if err != nil {
+			return result, fmt.Errorf("failed to marshal mentions: %w", err)
+		}
+		if err := s.storage.Store(blob, data); err != nil {
+			return result, fmt.Errorf("failed to store %s: %w", blob, err)
+		}
+		s.indexMentions(blob, blobChanged)
+		result.Blobs++
+	}
+
+	if !dryRun && len(changed) > 0 {
+		s.saveSearchIndex()
+		s.exportRunParquet(changed)
+	}
+	return result, nil
+}
+
+// reprocessMention re-runs the given stages on one mention
+func (s *Service) reprocessMention(ctx context.Context, mention *models.Mention, stages []string) {
+	for _, stage := range stages {
+		switch stage {
+		case StageSentiment:
+			for j := range mention.TopComments {
+				mention.TopComments[j].Sentiment = s.basicSentimentAnalysis(mention.TopComments[j].Content)
+			}
+			mention.Sentiment, mention.SentimentPhrases = s.explainSentiment(mention.Content)
+		case StageQuestions:
+			// Keep the stored label when the LLM should have been asked but failed
+			if isQuestion, final := s.detectQuestion(ctx, *mention); final {
+				mention.IsQuestion = isQuestion
+			}
+		case StageTopics:
+			// Match the text against the current keywords; mentions found through text the
+			// bot doesn't store, such as a comment, keep their keywords
+			unmatched := *mention
+			unmatched.Keywords = nil
+			if keywords := sources.MatchKeywords(unmatched, s.config.Keywords); len(keywords) > 0 {
+				mention.Keywords = keywords
+			}
+		case StageRelevance:
+			decision := s.relevanceDecision(*mention)
+			mention.Relevance = decision.Relevance
+			mention.Filter = &decision
+		}
+	}
+}
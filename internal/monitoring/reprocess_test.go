@@ -0,0 +1,82 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStages(t *testing.T) {
+	stages, err := ParseStages(" Sentiment,topics,sentiment ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{StageSentiment, StageTopics}, stages)
+
+	_, err = ParseStages("sentiment,emotions")
+	assert.ErrorIs(t, err, ErrInvalidStage)
+	_, err = ParseStages("")
+	assert.ErrorIs(t, err, ErrInvalidStage)
+}
+
+func TestService_Reprocess(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{
+		config:  &config.Config{Keywords: []string{"AKS", "Fleet Manager"}},
+		storage: store,
+		index:   storage.NewMentionIndex(store),
+	}
+
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, service.storeMentionBatch("2024-06-03-09-00-00", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS Fleet Manager upgrade", Content: "The upgrade is broken again",
+			CreatedAt: day.Add(8 * time.Hour), Sentiment: "neutral", Keywords: []string{"AKS"}},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS is fine", Content: "Nothing to see",
+			CreatedAt: day.Add(9 * time.Hour), Sentiment: "neutral", Keywords: []string{"AKS"}},
+		{ID: "reddit_3", Source: "reddit", Title: "Older AKS thread", Content: "Everything is broken",
+			CreatedAt: day.Add(-48 * time.Hour), Sentiment: "neutral", Keywords: []string{"AKS"}},
+	}))
+
+	stored := func() map[string]models.Mention {
+		data, err := store.Retrieve("mentions-2024-06-03-09-00-00-reddit.json")
+		require.NoError(t, err)
+		var mentions []models.Mention
+		require.NoError(t, json.Unmarshal(data, &mentions))
+		byID := make(map[string]models.Mention)
+		for _, mention := range mentions {
+			byID[mention.ID] = mention
+		}
+		return byID
+	}
+
+	_, err := service.Reprocess(context.Background(), day, []string{"emotions"}, false)
+	assert.ErrorIs(t, err, ErrInvalidStage)
+
+	result, err := service.Reprocess(context.Background(), day, []string{StageSentiment, StageTopics}, true)
+	require.NoError(t, err)
+	assert.Equal(t, ReprocessResult{Mentions: 2, Changed: 1}, result)
+	assert.Equal(t, "neutral", stored()["reddit_1"].Sentiment, "dry runs write nothing")
+
+	result, err = service.Reprocess(context.Background(), day, []string{StageSentiment, StageTopics}, false)
+	require.NoError(t, err)
+	assert.Equal(t, ReprocessResult{Mentions: 2, Changed: 1, Blobs: 1}, result)
+
+	mentions := stored()
+	assert.Equal(t, "negative", mentions["reddit_1"].Sentiment)
+	assert.Equal(t, []string{"AKS", "Fleet Manager"}, mentions["reddit_1"].Keywords)
+	assert.Equal(t, "neutral", mentions["reddit_2"].Sentiment)
+	assert.Equal(t, "neutral", mentions["reddit_3"].Sentiment, "mentions created before since are left alone")
+
+	index, err := service.index.Load(day)
+	require.NoError(t, err)
+	assert.Equal(t, "negative", index["reddit_1"].Sentiment, "the mention index is updated")
+	hits := service.Search("fleet manager", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "negative", hits[0].Sentiment)
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/azmonitor"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// publishTimeout bounds how long a run waits for Azure Monitor to accept its metrics
+const publishTimeout = 30 * time.Second
+
+// publishRunMetrics sends a monitoring run's aggregates to Azure Monitor as custom metrics when
+// it is configured. Failures are logged so they never fail a run.
+func (s *Service) publishRunMetrics(ctx context.Context, mentions []models.Mention, duration time.Duration, errorCount int) {
+	if s.azureMonitor == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	metrics := s.runMetrics(mentions, duration, errorCount)
+	if err := s.azureMonitor.Emit(ctx, s.config.AzureMonitorNamespace, time.Now(), metrics); err != nil {
+		logrus.Warnf("Failed to publish run metrics to Azure Monitor: %v", err)
+		return
+	}
+	logrus.Debugf("Published %d run metrics to Azure Monitor", len(metrics))
+}
+
+// runMetrics returns the aggregates of a run: mentions and the share of negative mentions for
+// every keyword and each keyword group, by a KeywordGroup dimension, the run duration and the
+// source errors. Groups without mentions have no negative ratio.
+func (s *Service) runMetrics(mentions []models.Mention, duration time.Duration, errorCount int) []azmonitor.Metric {
+	groups := []string{config.AllKeywordsGroup}
+	var named []string
+	for group := range s.config.KeywordGroups {
+		named = append(named, group)
+	}
+	sort.Strings(named)
+	groups = append(groups, named...)
+
+	counts := azmonitor.Metric{Name: "Mentions", Dimensions: []string{"KeywordGroup"}}
+	negative := azmonitor.Metric{Name: "NegativeRatio", Dimensions: []string{"KeywordGroup"}}
+	for _, group := range groups {
+		matched := s.groupMentions(mentions, group)
+		counts.Series = append(counts.Series, azmonitor.Series{DimValues: []string{group}, Value: float64(len(matched))})
+		if len(matched) == 0 {
+			continue
+		}
+
+		negatives := 0
+		for _, mention := range matched {
+			if mention.Sentiment == "negative" {
+				negatives++
+			}
+		}
+		negative.Series = append(negative.Series, azmonitor.Series{DimValues: []string{group}, Value: float64(negatives) / float64(len(matched))})
+	}
+
+	return []azmonitor.Metric{
+		counts,
+		negative,
+		{Name: "RunDurationSeconds", Series: []azmonitor.Series{{Value: duration.Seconds()}}},
+		{Name: "SourceErrors", Series: []azmonitor.Series{{Value: float64(errorCount)}}},
+	}
+}
@@ -0,0 +1,41 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/azmonitor"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_runMetrics(t *testing.T) {
+	service := &Service{config: &config.Config{KeywordGroups: map[string][]string{
+		"kaito": {"KAITO"},
+		"fleet": {"Azure Kubernetes Fleet Manager", "KubeFleet"},
+	}}}
+
+	mentions := []models.Mention{
+		{ID: "1", Keywords: []string{"AKS"}, Sentiment: "negative"},
+		{ID: "2", Keywords: []string{"AKS", "kaito"}, Sentiment: "positive"},
+		{ID: "3", Keywords: []string{"KAITO"}, Sentiment: "negative"},
+		{ID: "4", Keywords: []string{"AKS"}, Sentiment: "neutral"},
+	}
+
+	metrics := service.runMetrics(mentions, 90*time.Second, 2)
+	require.Len(t, metrics, 4)
+
+	assert.Equal(t, azmonitor.Metric{Name: "Mentions", Dimensions: []string{"KeywordGroup"}, Series: []azmonitor.Series{
+		{DimValues: []string{"all"}, Value: 4},
+		{DimValues: []string{"fleet"}, Value: 0},
+		{DimValues: []string{"kaito"}, Value: 2},
+	}}, metrics[0])
+	assert.Equal(t, azmonitor.Metric{Name: "NegativeRatio", Dimensions: []string{"KeywordGroup"}, Series: []azmonitor.Series{
+		{DimValues: []string{"all"}, Value: 0.5},
+		{DimValues: []string{"kaito"}, Value: 0.5},
+	}}, metrics[1], "groups without mentions have no ratio")
+	assert.Equal(t, 90.0, metrics[2].Series[0].Value)
+	assert.Equal(t, 2.0, metrics[3].Series[0].Value)
+}
@@ -0,0 +1,212 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSearchLimit is the number of hits returned when no limit is given
+	DefaultSearchLimit = 20
+	// MaxSearchLimit caps the number of hits a single search may return
+	MaxSearchLimit = 200
+)
+
+// searchIndex lazily loads the persisted full-text index on first use, falling back to an
+// empty index if it cannot be read. The fallback only holds the mentions of this process, so
+// it is never saved over the stored index.
+func (s *Service) searchIndex() *storage.SearchIndex {
+	s.searchOnce.Do(func() {
+		if s.storage == nil {
+			s.search = storage.NewSearchIndex()
+			return
+		}
+
+		index, err := storage.LoadSearchIndex(s.storage)
+		if err != nil {
+			logrus.Warnf("Failed to load search index, searching only new mentions until restarted (run 'aks-mentions-bot rebuild-search-index' if it persists): %v", err)
+			index = storage.NewSearchIndex()
+			s.searchUnloaded = true
+		} else {
+			logrus.Infof("Loaded search index with %d mentions", index.Len())
+		}
+		s.search = index
+	})
+
+	return s.search
+}
+
+// saveSearchIndex persists the full-text index after a run that added mentions to it.
+// Failures are logged since the index can always be rebuilt from the stored mentions.
+func (s *Service) saveSearchIndex() {
+	if s.search == nil || s.storage == nil {
+		return
+	}
+	if s.searchUnloaded {
+		logrus.Warn("Not saving the search index, since the stored one failed to load")
+		return
+	}
+
+	if err := s.search.Save(s.storage); err != nil {
+		logrus.Warnf("Failed to persist search index: %v", err)
+	}
+}
+
+// Search runs a full-text query over every stored mention
+func (s *Service) Search(query string, limit int) []storage.SearchHit {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	return s.searchIndex().Search(query, limit)
+}
+
+// ErrInvalidQuery is returned for a mention query with nothing to bound it or an unknown sort order
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Mention query sort orders. By default text queries are ranked by how well mentions match
+// and other queries list the newest mentions first.
+const (
+	SortNewest    = "newest"
+	SortRelevance = "relevance" // Highest mention relevance score first
+)
+
+// MentionQuery selects stored mentions. At least one of Text, Tag or Since is required;
+// the other fields narrow the matches.
+type MentionQuery struct {
+	Text      string    // Full-text query; matches are ranked by relevance instead of recency
+	Tag       string    // Curated tag
+	Since     time.Time // Created at or after
+	Sentiment string
+	Source    string
+	Sort      string // SortNewest or SortRelevance; empty for the default order
+	Limit     int
+}
+
+// QueryResult is the first Limit hits of a mention query with the number of matches
+type QueryResult struct {
+	Total int               `json:"total"`
+	Hits  []TaggedSearchHit `json:"hits"`
+}
+
+// QueryMentions runs a mention query over the stored mentions
+func (s *Service) QueryMentions(query MentionQuery) (*QueryResult, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+	return s.queryMentions(query, limit)
+}
+
+func (s *Service) queryMentions(query MentionQuery, limit int) (*QueryResult, error) {
+	s.tagsMu.Lock()
+	tags, err := s.loadTags()
+	s.tagsMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Tag != "" {
+		if query.Tag, err = normalizeTag(query.Tag); err != nil {
+			return nil, err
+		}
+	}
+
+	if query.Sort != "" && query.Sort != SortNewest && query.Sort != SortRelevance {
+		return nil, fmt.Errorf("%w: sort must be %q or %q", ErrInvalidQuery, SortNewest, SortRelevance)
+	}
+
+	var hits []storage.SearchHit
+	switch {
+	case strings.TrimSpace(query.Text) != "":
+		// Search the whole index, since the filters may drop most hits
+		hits = s.searchIndex().Search(query.Text, s.searchIndex().Len())
+	case query.Tag != "":
+		for id := range tags {
+			if doc, ok := s.searchIndex().Document(id); ok {
+				hits = append(hits, storage.SearchHit{SearchDocument: doc})
+			}
+		}
+	case !query.Since.IsZero():
+		if hits, err = s.indexedSince(query.Since); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: a text query, tag or start time is required", ErrInvalidQuery)
+	}
+	switch {
+	case query.Sort == SortRelevance:
+		sort.SliceStable(hits, func(a, b int) bool {
+			if hits[a].Relevance != hits[b].Relevance {
+				return hits[a].Relevance > hits[b].Relevance
+			}
+			return hits[a].CreatedAt.After(hits[b].CreatedAt)
+		})
+	case query.Sort == SortNewest || strings.TrimSpace(query.Text) == "":
+		sort.SliceStable(hits, func(a, b int) bool { return hits[a].CreatedAt.After(hits[b].CreatedAt) })
+	}
+
+	result := &QueryResult{Hits: make([]TaggedSearchHit, 0, limit)}
+	for _, hit := range hits {
+		if query.Tag != "" && !containsString(tags[hit.ID], query.Tag) {
+			continue
+		}
+		if !query.Since.IsZero() && hit.CreatedAt.Before(query.Since) {
+			continue
+		}
+		if query.Sentiment != "" && !strings.EqualFold(hit.Sentiment, query.Sentiment) {
+			continue
+		}
+		if query.Source != "" && !strings.EqualFold(hit.Source, query.Source) {
+			continue
+		}
+		result.Total++
+		if len(result.Hits) < limit {
+			result.Hits = append(result.Hits, TaggedSearchHit{SearchHit: hit, Tags: tags[hit.ID]})
+		}
+	}
+	return result, nil
+}
+
+// indexedSince lists the mentions created since a time from the daily mention index
+func (s *Service) indexedSince(since time.Time) ([]storage.SearchHit, error) {
+	if s.index == nil {
+		return nil, nil
+	}
+
+	entries, err := s.index.Range(since, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the mention index: %w", err)
+	}
+
+	hits := make([]storage.SearchHit, 0, len(entries))
+	for id, entry := range entries {
+		doc, ok := s.searchIndex().Document(id)
+		if !ok {
+			doc = storage.SearchDocument{
+				ID:        id,
+				Blob:      entry.Blob,
+				Source:    entry.Source,
+				Title:     entry.Title,
+				URL:       entry.URL,
+				CreatedAt: entry.CreatedAt,
+				Sentiment: entry.Sentiment,
+				Relevance: entry.Relevance,
+			}
+		}
+		hits = append(hits, storage.SearchHit{SearchDocument: doc})
+	}
+	return hits, nil
+}
@@ -0,0 +1,82 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_QueryMentions(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{ReportSchedule: "daily"}, storage: store, index: storage.NewMentionIndex(store)}
+	now := time.Now()
+	require.NoError(t, service.storeMentionBatch("run-1", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade broke my cluster", Sentiment: "negative", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS networking with Cilium", Sentiment: "positive", CreatedAt: now.Add(-time.Hour)},
+		{ID: "reddit_3", Source: "reddit", Title: "AKS upgrade stuck again", Sentiment: "negative", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+	}))
+	require.NoError(t, service.storeMentionBatch("run-1", "twitter", []models.Mention{
+		{ID: "twitter_1", Source: "twitter", Title: "AKS upgrade failing today", Sentiment: "negative", CreatedAt: now},
+	}))
+
+	result, err := service.QueryMentions(MentionQuery{Since: now.Add(-7 * 24 * time.Hour), Sentiment: "negative"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	require.Len(t, result.Hits, 2)
+	assert.Equal(t, "twitter_1", result.Hits[0].ID, "newest first without a text query")
+	assert.Equal(t, "reddit_1", result.Hits[1].ID)
+
+	result, err = service.QueryMentions(MentionQuery{Since: now.Add(-7 * 24 * time.Hour), Source: "reddit", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	require.Len(t, result.Hits, 1)
+	assert.Equal(t, "reddit_2", result.Hits[0].ID)
+
+	result, err = service.QueryMentions(MentionQuery{Text: "upgrade", Source: "reddit"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+
+	_, err = service.QueryMentions(MentionQuery{Sentiment: "negative"})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestService_QueryMentions_SortByRelevance(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{ReportSchedule: "daily"}, storage: store, index: storage.NewMentionIndex(store)}
+	now := time.Now()
+	require.NoError(t, service.storeMentionBatch("run-1", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade question", Relevance: 0.4, CreatedAt: now},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS upgrade outage", Relevance: 0.9, CreatedAt: now.Add(-time.Hour)},
+	}))
+
+	result, err := service.QueryMentions(MentionQuery{Since: now.Add(-24 * time.Hour), Sort: SortRelevance})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 2)
+	assert.Equal(t, "reddit_2", result.Hits[0].ID)
+
+	result, err = service.QueryMentions(MentionQuery{Text: "upgrade", Sort: SortNewest})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 2)
+	assert.Equal(t, "reddit_1", result.Hits[0].ID)
+
+	_, err = service.QueryMentions(MentionQuery{Text: "upgrade", Sort: "oldest"})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestService_saveSearchIndex_UnreadableIndex(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	require.NoError(t, store.Store(storage.SearchIndexBlob, []byte("{")))
+	service := &Service{config: &config.Config{}, storage: store}
+
+	require.NoError(t, service.storeMentionBatch("run-1", "reddit", []models.Mention{{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade"}}))
+	assert.Len(t, service.Search("upgrade", 0), 1, "new mentions are still searchable")
+
+	service.saveSearchIndex()
+	assert.Equal(t, "{", string(store.Blobs()[storage.SearchIndexBlob]), "the stored index isn't replaced by the new mentions alone")
+}
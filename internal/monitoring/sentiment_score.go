@@ -0,0 +1,170 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sentimentScoresBlob holds one community sentiment score per day
+	sentimentScoresBlob = "sentiment/scores.json"
+	// defaultSentimentScoreWindow is used when no score window has been configured
+	defaultSentimentScoreWindow = 30 * 24 * time.Hour
+	// sentimentScoreRetention is how long daily scores are kept
+	sentimentScoreRetention = 365 * 24 * time.Hour
+	// sentimentTrendDays is how many daily scores reports chart
+	sentimentTrendDays = 90
+	// sentimentChangeDays is how far back the score change is measured
+	sentimentChangeDays = 7
+)
+
+// recordSentimentScore computes the community sentiment score over the trailing window from
+// the mention index, stores it as today's score and returns it with the scores before it.
+// Mentions are counted once however many runs collected them. Failures are logged so they
+// never block a run.
+func (s *Service) recordSentimentScore(now time.Time) *models.SentimentTrend {
+	if !s.config.EnableSentimentAnalysis || s.storage == nil || s.index == nil {
+		return nil
+	}
+
+	window := s.sentimentScoreWindow()
+	days := int(window / (24 * time.Hour))
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	entries, err := s.index.Range(today.AddDate(0, 0, 1-days), now)
+	if err != nil {
+		logrus.Warnf("Failed to load mentions for the sentiment score: %v", err)
+		return nil
+	}
+	score := models.SentimentScore{Date: today.Format("2006-01-02")}
+	for _, entry := range entries {
+		switch entry.Sentiment {
+		case "positive":
+			score.Positive++
+		case "negative":
+			score.Negative++
+		case "neutral":
+			score.Neutral++
+		}
+	}
+	total := score.Positive + score.Neutral + score.Negative
+	if total == 0 {
+		return nil
+	}
+	score.Score = math.Round(float64(score.Positive-score.Negative)*1000/float64(total)) / 10
+
+	s.sentimentMu.Lock()
+	defer s.sentimentMu.Unlock()
+
+	history, err := s.loadSentimentScores()
+	if err != nil {
+		// Storing today's score alone would replace the whole history
+		logrus.Warnf("Failed to load sentiment scores, not recording today's score: %v", err)
+		return nil
+	}
+
+	cutoff := today.Add(-sentimentScoreRetention).Format("2006-01-02")
+	kept := history[:0]
+	for _, day := range history {
+		if day.Date != score.Date && day.Date >= cutoff {
+			kept = append(kept, day)
+		}
+	}
+	history = append(kept, score)
+	sort.Slice(history, func(a, b int) bool { return history[a].Date < history[b].Date })
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		logrus.Warnf("Failed to marshal sentiment scores: %v", err)
+	} else if err := s.storage.Store(sentimentScoresBlob, data); err != nil {
+		logrus.Warnf("Failed to store sentiment scores: %v", err)
+	}
+
+	return sentimentTrend(history, days, today)
+}
+
+// SentimentTrend returns the latest stored community sentiment score with the daily scores
+// before it, or nil when no score has been recorded yet
+func (s *Service) SentimentTrend() (*models.SentimentTrend, error) {
+	s.sentimentMu.Lock()
+	history, err := s.loadSentimentScores()
+	s.sentimentMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, nil
+	}
+
+	latest, err := time.Parse("2006-01-02", history[len(history)-1].Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentiment score date %q: %w", history[len(history)-1].Date, err)
+	}
+	return sentimentTrend(history, int(s.sentimentScoreWindow()/(24*time.Hour)), latest), nil
+}
+
+// sentimentTrend builds the trend ending on day from daily scores sorted oldest first
+func sentimentTrend(history []models.SentimentScore, windowDays int, day time.Time) *models.SentimentTrend {
+	first := day.AddDate(0, 0, 1-sentimentTrendDays).Format("2006-01-02")
+	weekAgo := day.AddDate(0, 0, -sentimentChangeDays).Format("2006-01-02")
+	end := day.Format("2006-01-02")
+
+	trend := &models.SentimentTrend{WindowDays: windowDays}
+	var previous *models.SentimentScore
+	for i, score := range history {
+		if score.Date > end {
+			break
+		}
+		if score.Date <= weekAgo {
+			previous = &history[i]
+		}
+		if score.Date >= first {
+			trend.History = append(trend.History, score)
+		}
+	}
+	if len(trend.History) == 0 {
+		return nil
+	}
+
+	trend.Current = trend.History[len(trend.History)-1]
+	if previous != nil {
+		change := math.Round((trend.Current.Score-previous.Score)*10) / 10
+		trend.Change = &change
+	}
+	return trend
+}
+
+// sentimentScoreWindow returns the configured score window in whole days, at least one
+func (s *Service) sentimentScoreWindow() time.Duration {
+	window := s.config.SentimentScoreWindow
+	if window <= 0 {
+		window = defaultSentimentScoreWindow
+	}
+	if window < 24*time.Hour {
+		window = 24 * time.Hour
+	}
+	return window.Truncate(24 * time.Hour)
+}
+
+// loadSentimentScores reads the stored daily scores, oldest first; callers must hold s.sentimentMu
+func (s *Service) loadSentimentScores() ([]models.SentimentScore, error) {
+	data, found, err := storage.RetrieveIfExists(s.storage, sentimentScoresBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sentiment scores: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var history []models.SentimentScore
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse sentiment scores: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,81 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_recordSentimentScore(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{
+		config:  &config.Config{EnableSentimentAnalysis: true, SentimentScoreWindow: 30 * 24 * time.Hour},
+		storage: store,
+		index:   storage.NewMentionIndex(store),
+	}
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	// No mentions in the window, no score
+	assert.Nil(t, service.recordSentimentScore(now))
+
+	mentions := []models.Mention{
+		{ID: "1", Sentiment: "positive", CreatedAt: now.Add(-time.Hour)},
+		{ID: "2", Sentiment: "positive", CreatedAt: now.AddDate(0, 0, -10)},
+		{ID: "3", Sentiment: "positive", CreatedAt: now.AddDate(0, 0, -29)},
+		{ID: "4", Sentiment: "negative", CreatedAt: now.AddDate(0, 0, -3)},
+		{ID: "5", Sentiment: "neutral", CreatedAt: now.AddDate(0, 0, -3)},
+		{ID: "6", Sentiment: "negative", CreatedAt: now.AddDate(0, 0, -45)}, // outside the window
+	}
+	require.NoError(t, service.index.Add("mentions-1.json", mentions))
+	// A mention collected again by a later run is counted once
+	require.NoError(t, service.index.Add("mentions-2.json", mentions[:1]))
+
+	// A score recorded a week earlier gives the change
+	earlier, err := json.Marshal([]models.SentimentScore{
+		{Date: "2023-01-01", Score: 90}, // past retention
+		{Date: "2024-06-23", Score: 50},
+		{Date: "2024-06-30", Score: -10}, // replaced by today's run
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Store(sentimentScoresBlob, earlier))
+
+	trend := service.recordSentimentScore(now)
+	require.NotNil(t, trend)
+	assert.Equal(t, models.SentimentScore{Date: "2024-06-30", Score: 40, Positive: 3, Neutral: 1, Negative: 1}, trend.Current)
+	assert.Equal(t, 30, trend.WindowDays)
+	require.NotNil(t, trend.Change)
+	assert.Equal(t, -10.0, *trend.Change)
+	assert.Equal(t, "+40.0 over 30 days, down 10.0 from a week ago", trend.Summary())
+
+	var stored []models.SentimentScore
+	require.NoError(t, json.Unmarshal(store.Blobs()[sentimentScoresBlob], &stored))
+	require.Len(t, stored, 2)
+	assert.Equal(t, "2024-06-23", stored[0].Date)
+	assert.Equal(t, trend.Current, stored[1])
+
+	served, err := service.SentimentTrend()
+	require.NoError(t, err)
+	assert.Equal(t, trend, served)
+
+	// An unreadable history is left alone rather than replaced by today's score
+	require.NoError(t, store.Store(sentimentScoresBlob, []byte("{")))
+	assert.Nil(t, service.recordSentimentScore(now))
+	assert.Equal(t, "{", string(store.Blobs()[sentimentScoresBlob]))
+}
+
+func TestSentimentTrend_noEarlierScore(t *testing.T) {
+	day := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	trend := sentimentTrend([]models.SentimentScore{{Date: "2024-06-29", Score: 5}, {Date: "2024-06-30", Score: 0}}, 30, day)
+
+	require.NotNil(t, trend)
+	assert.Nil(t, trend.Change, "no score from a week earlier")
+	assert.Len(t, trend.History, 2)
+	assert.Equal(t, "+0.0 over 30 days", trend.Summary())
+}
@@ -4,15 +4,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/azure/aks-mentions-bot/internal/azmonitor"
+	"github.com/azure/aks-mentions-bot/internal/cache"
 	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/docs"
+	"github.com/azure/aks-mentions-bot/internal/httpcache"
+	"github.com/azure/aks-mentions-bot/internal/llm"
 	"github.com/azure/aks-mentions-bot/internal/models"
 	"github.com/azure/aks-mentions-bot/internal/notifications"
+	"github.com/azure/aks-mentions-bot/internal/releases"
 	"github.com/azure/aks-mentions-bot/internal/sources"
 	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/usage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,10 +33,59 @@ type Service struct {
 	storage             storage.StorageInterface
 	notificationService notifications.NotificationInterface
 	sources             []sources.Source
+	previewSources      []sources.Source // Built without the Twitter schedule store, so previews leave the run's schedule alone
+	index               *storage.MentionIndex
+	search              *storage.SearchIndex
+	searchOnce          sync.Once
+	searchUnloaded      bool // The stored search index failed to load, so it is never saved over
+	lifecycleMu         sync.Mutex
+	blocked             *blocklist
+	blocklistOnce       sync.Once
+	keywordGroupsMu     sync.Mutex
+	breakersOnce        sync.Once
+	breakersMu          sync.Mutex
+	twitterStreaming    atomic.Bool
+	subredditsMu        sync.Mutex
+	questionsMu         sync.Mutex
+	sentimentMu         sync.Mutex
+	tagsMu              sync.Mutex
+	tagsVersion         atomic.Uint64 // Bumped on every tag change, to invalidate tagPages
+	corpusMu            sync.Mutex
+	costsMu             sync.Mutex
+	reliabilityMu       sync.Mutex
+	submissionsMu       sync.Mutex
+	monitoringRun       sync.Mutex // Held by the monitoring run in progress
+	clicksMu            sync.Mutex
+	clicks              clickBuffer
+	duplicatesMu        sync.Mutex
+	alertsMu            sync.Mutex
+	watermarksMu        sync.Mutex
+	live                liveHub
+	releases            *releases.Tracker
+	docs                *docs.Searcher
+	docsChecked         *cache.LRU[string, bool] // Question IDs searched in the docs, and whether they are gaps
+	llm                 *llm.Client
+	azureMonitor        *azmonitor.Client
+	enrichCache         *cache.LRU[string, enrichment]
+	translations        *cache.LRU[string, mentionTranslation] // English translations by mention ID
+	urgentVerdicts      *cache.LRU[string, bool]               // LLM urgent verification verdicts by mention ID
+	tagPages            *cache.LRU[string, tagReportPage]      // Rendered tag report pages by tag
 	metrics             *Metrics
+	sourceHealth        map[string]*SourceStatus
+	breakers            map[string]*SourceBreaker
+	runs                runTracker
 	mu                  sync.RWMutex
 }
 
+// MonitoringRunning reports whether a monitoring run is in progress
+func (s *Service) MonitoringRunning() bool {
+	if !s.monitoringRun.TryLock() {
+		return true
+	}
+	s.monitoringRun.Unlock()
+	return false
+}
+
 // Metrics holds monitoring metrics
 type Metrics struct {
 	TotalMentions      int            `json:"total_mentions"`
@@ -34,57 +94,149 @@ type Metrics struct {
 	SourceMetrics      map[string]int `json:"source_metrics"`
 	SentimentBreakdown map[string]int `json:"sentiment_breakdown"`
 	ErrorCount         int            `json:"error_count"`
+	// Paid-API usage and estimated cost of the last monitoring run, and of all runs in the last 30 days
+	LastRunUsage        *usage.Usage `json:"last_run_usage,omitempty"`
+	LastRunCost         *usage.Cost  `json:"last_run_estimated_cost,omitempty"`
+	EstimatedCost30Days *usage.Cost  `json:"estimated_cost_30d,omitempty"`
+	// Notification deliveries waiting in the retry queue, and those given up on in the last 30 days
+	PendingDeliveries *int `json:"pending_deliveries,omitempty"`
+	FailedDeliveries  *int `json:"failed_deliveries,omitempty"`
+	// Sources skipped until their circuit breaker's cooldown ends; each counts as one error per run
+	SkippedSources []models.SkippedSource `json:"skipped_sources,omitempty"`
+	// Source fetch errors by class in the last monitoring run, and in every run of the last 7 days
+	LastRunErrorClasses map[string]int `json:"last_run_error_classes,omitempty"`
+	ErrorClasses7Days   map[string]int `json:"error_classes_7d,omitempty"`
+	// Mentions of the last monitoring run per keyword group, and per keyword by source. Every
+	// searched keyword is listed, so terms that pull in nothing stand out.
+	KeywordGroupMetrics map[string]int            `json:"keyword_group_metrics,omitempty"`
+	KeywordMetrics      map[string]map[string]int `json:"keyword_metrics,omitempty"`
+}
+
+// deliveryQueue is implemented by notification services that queue failed deliveries for retry
+type deliveryQueue interface {
+	QueueStatus() (*notifications.QueueStatus, error)
 }
 
 // NewService creates a new monitoring service
-func NewService(cfg *config.Config, storage storage.StorageInterface, notificationService notifications.NotificationInterface) *Service {
+func NewService(cfg *config.Config, store storage.StorageInterface, notificationService notifications.NotificationInterface) *Service {
 	service := &Service{
 		config:              cfg,
-		storage:             storage,
+		storage:             store,
 		notificationService: notificationService,
+		index:               storage.NewMentionIndex(store),
+		enrichCache:         cache.NewLRU[string, enrichment](cfg.EnrichmentCacheSize),
+		tagPages:            cache.NewLRU[string, tagReportPage](tagReportCacheSize),
+		sourceHealth:        make(map[string]*SourceStatus),
 		metrics: &Metrics{
 			SourceMetrics:      make(map[string]int),
 			SentimentBreakdown: make(map[string]int),
 		},
 	}
 
+	if cfg.EnableReleaseCorrelation {
+		service.releases = releases.NewTracker(cfg.ReleasesURL)
+	}
+
+	if cfg.EnableDocsGapDetection {
+		service.docs = docs.NewSearcher(cfg.DocsSearchURL)
+		service.docsChecked = cache.NewLRU[string, bool](docsCheckedSize)
+	}
+
+	if cfg.LLMConfigured() {
+		client, err := llm.NewClient(cfg.AzureOpenAIEndpoint, cfg.AzureOpenAIDeployment, cfg.AzureOpenAIAPIVersion, cfg.AzureOpenAIAPIKey)
+		if err != nil {
+			logrus.Warnf("LLM enrichment disabled: %v", err)
+		} else {
+			service.llm = client
+		}
+	}
+
+	if cfg.EnableTranslation {
+		service.translations = cache.NewLRU[string, mentionTranslation](translationCacheSize)
+	}
+
+	if cfg.EnableLLMUrgentVerification {
+		service.urgentVerdicts = cache.NewLRU[string, bool](urgentVerdictCacheSize)
+	}
+
+	if cfg.AzureMonitorConfigured() {
+		client, err := azmonitor.NewClient(cfg.AzureMonitorRegion, cfg.AzureMonitorResourceID)
+		if err != nil {
+			logrus.Warnf("Azure Monitor metrics disabled: %v", err)
+		} else {
+			service.azureMonitor = client
+		}
+	}
+
 	// Initialize data sources
 	service.initializeSources()
 
 	return service
 }
 
+// initializeSources builds the registered sources the configuration enables, including any
+// source plugins compiled in
 func (s *Service) initializeSources() {
-	s.sources = []sources.Source{
-		sources.NewRedditSource(s.config.RedditClientID, s.config.RedditClientSecret),
-		sources.NewStackOverflowSource(),
-		sources.NewHackerNewsSource(),
-		sources.NewTwitterSource(s.config.TwitterBearerToken),
-		sources.NewYouTubeSource(s.config.YouTubeAPIKey),
-		sources.NewMediumSource(),
-		// LinkedIn source uses a hybrid approach:
-		// 1. LinkedIn's direct APIs require restricted permissions and only allow
-		//    accessing content you own or have explicit permissions for
-		// 2. Public content monitoring requires Google search for LinkedIn Pulse articles,
-		//    company page posts, and discussions - this provides real, relevant content
-		// 3. Alternative: Could implement LinkedIn Company Pages API if we get
-		//    organization-level access to specific companies (Microsoft, etc.)
-		sources.NewLinkedInSource(),
-	}
-}
-
-// RunMonitoring performs the main monitoring task
+	opts := sources.Options{
+		Queries:              sources.NewQueryBuilder(s.config.KeywordQueries),
+		DiscoveredSubreddits: s.discoveredSubreddits,
+	}
+	if s.storage != nil {
+		opts.TwitterSchedule = twitterScheduleStore{storage: s.storage}
+		opts.Submissions = submissionStore{service: s}
+	}
+
+	// Urgent checks and report runs often search overlapping windows, so the feed-style
+	// sources reuse recent responses instead of fetching them again
+	if s.config.HTTPCacheSize > 0 || (s.config.HTTPCacheBlob && s.storage != nil) {
+		transport := httpcache.NewTransport(nil, s.config.HTTPCacheTTL, s.config.HTTPCacheSize)
+		if s.config.HTTPCacheBlob && s.storage != nil {
+			transport.WithStore(s.storage)
+		}
+		opts.HTTPCache = transport
+	}
+
+	built, err := sources.Build(s.config, opts)
+	if err != nil {
+		logrus.Errorf("Failed to build sources, no sources will be searched: %v", err)
+	}
+	s.sources = built
+
+	// Keyword previews get their own sources, so they neither defer queries to the next run
+	// nor spend the search budget the runs share
+	opts.TwitterSchedule = nil
+	preview, err := sources.Build(s.config, opts)
+	if err != nil {
+		logrus.Errorf("Failed to build preview sources, previews will search no sources: %v", err)
+	}
+	s.previewSources = preview
+}
+
+// RunMonitoring performs the main monitoring task. It returns ErrRunInProgress while another
+// monitoring run is in progress.
 func (s *Service) RunMonitoring() error {
+	runCtx, done, err := s.runs.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if !s.monitoringRun.TryLock() {
+		return ErrRunInProgress
+	}
+	defer s.monitoringRun.Unlock()
+
 	start := time.Now()
 	logrus.Info("Starting monitoring run")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	ctx, cancel := context.WithTimeout(runCtx, 30*time.Minute)
 	defer cancel()
 
-	var allMentions []models.Mention
-	var wg sync.WaitGroup
-	mentionsChan := make(chan []models.Mention, len(s.sources))
-	errorsChan := make(chan error, len(s.sources))
+	// Meter paid API calls made anywhere in the run, interrupted or failed runs included
+	runID := start.Format(runIDLayout)
+	meter := usage.NewMeter()
+	ctx = usage.WithMeter(ctx, meter)
+	defer s.recordRunCost(runID, RunKindMonitoring, start, meter)
 
 	// Determine the time window to search
 	// For consistency, always search the configured period regardless of last run time
@@ -108,92 +260,156 @@ func (s *Service) RunMonitoring() error {
 		}
 	}
 
-	logrus.Infof("Searching %d sources for mentions in the last %v", len(s.sources), searchWindow)
-
-	// Fetch mentions from all sources concurrently
-	for _, source := range s.sources {
-		wg.Add(1)
-		go func(src sources.Source) {
-			defer wg.Done()
-
-			logrus.Infof("Fetching mentions from %s (window: %v)", src.GetName(), searchWindow)
-			mentions, err := src.FetchMentions(ctx, s.config.Keywords, searchWindow)
-
-			if err != nil {
-				logrus.Errorf("Error fetching from %s: %v", src.GetName(), err)
-				errorsChan <- err
-				return
-			}
-
-			logrus.Infof("Found %d mentions from %s", len(mentions), src.GetName())
-			mentionsChan <- mentions
-		}(source)
+	// Cover the windows of runs a shutdown interrupted before they reported
+	interrupted, err := s.InterruptedRuns()
+	if err != nil {
+		logrus.Warnf("Failed to load interrupted runs: %v", err)
 	}
-
-	// Close channels when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(mentionsChan)
-		close(errorsChan)
-	}()
-
-	// Collect results
-	for mentions := range mentionsChan {
-		allMentions = append(allMentions, mentions...)
+	if len(interrupted) > 0 {
+		searchWindow = s.catchUpWindow(searchWindow, interrupted)
+		logrus.Infof("Catching up on %d interrupted runs", len(interrupted))
 	}
 
-	// Count errors
-	errorCount := 0
-	for range errorsChan {
-		errorCount++
+	// Sources with their own schedule are collected apart; the report includes what they
+	// collected since the last one
+	srcs := s.reportSources()
+	logrus.Infof("Searching %d sources for mentions in the last %v", len(srcs), searchWindow)
+
+	// Stream mentions through the fetch → filter → enrich → store pipeline. Sources with a
+	// watermark search from it instead, so nothing is missed after a failed run or downtime.
+	result := s.runSourcesPipeline(ctx, srcs, runID, s.config.Keywords, s.watermarkWindow(searchWindow))
+	allMentions := result.mentions
+	errorCount := result.fetchErrors
+	s.recordRunErrors(runID, RunKindMonitoring, start, result.outcomes)
+
+	// On shutdown, keep what was stored and leave the report to the run that catches up
+	if runCtx.Err() != nil {
+		s.saveSearchIndex()
+		s.advanceWatermarks(result.latest)
+		s.recordInterruptedRun(runID, start, searchWindow, result)
+		return ErrRunInterrupted
 	}
 
-	logrus.Infof("Collected %d total mentions from all sources", len(allMentions))
-
-	// Filter mentions for context relevance
-	if s.config.EnableContextFiltering {
-		allMentions = s.filterByContext(allMentions)
-		logrus.Infof("After context filtering: %d mentions", len(allMentions))
+	if result.storeErr != nil {
+		logrus.Errorf("Failed to store mentions: %v", result.storeErr)
+		return result.storeErr
 	}
+	s.saveSearchIndex()
+	s.advanceWatermarks(result.latest)
+	s.recordSubreddits(allMentions)
+	s.exportRunParquet(allMentions)
 
-	// Perform sentiment analysis
-	if s.config.EnableSentimentAnalysis {
-		s.analyzeSentiment(allMentions)
+	collections, err := s.SourceCollections()
+	if err != nil {
+		logrus.Warnf("Failed to load the mentions of scheduled sources: %v", err)
 	}
-
-	// Store mentions
-	if err := s.storeMentions(allMentions); err != nil {
-		logrus.Errorf("Failed to store mentions: %v", err)
-		return err
+	if len(collections) > 0 {
+		allMentions = withCollected(allMentions, collections)
+		logrus.Infof("Reporting %d collections of sources with their own schedule", len(collections))
 	}
 
 	// Update metrics
 	s.updateMetrics(allMentions, time.Since(start), errorCount)
+	s.publishRunMetrics(ctx, allMentions, time.Since(start), errorCount)
+
+	// Alert on keyword group thresholds separately from the periodic report
+	s.checkThresholds(allMentions, searchWindow)
 
 	// Generate and send report
-	if err := s.generateAndSendReport(allMentions); err != nil {
+	if err := s.generateAndSendReport(ctx, allMentions); err != nil {
 		logrus.Errorf("Failed to send report: %v", err)
 		return err
 	}
+	s.recordReported(allMentions)
+	s.clearInterruptedRuns(interrupted)
+	s.clearCollections(collections)
 
 	logrus.Infof("Monitoring run completed in %v", time.Since(start))
 	return nil
 }
 
+// defaultContextThreshold is used when no relevance threshold has been configured
+const defaultContextThreshold = 0.7
+
+// sourceTrust adjusts the relevance score for how noisy each platform tends to be
+var sourceTrust = map[string]float64{
+	"stackoverflow": 0.05,  // Questions are almost always technical
+	"youtube":       -0.05, // High noise platform - requires extra context to pass
+}
+
+// postTypeWeight adjusts the relevance score for the kind of post. An Ask HN thread is
+// someone asking the community directly; a passing comment is mostly incidental.
+var postTypeWeight = map[string]float64{
+	models.PostTypeAskHN:   0.1,
+	models.PostTypeShowHN:  0.05,
+	models.PostTypeComment: -0.1,
+}
+
+// askReliabilityKeywords flag Ask HN threads complaining about reliability as urgent even
+// without incident language
+var askReliabilityKeywords = []string{
+	"reliability", "unreliable", "unstable", "flaky", "outage", "keeps failing",
+	"keeps crashing", "went down", "is down", "production issue", "regret",
+}
+
 func (s *Service) filterByContext(mentions []models.Mention) []models.Mention {
-	var filtered []models.Mention
+	filtered, _ := s.partitionByContext(mentions)
+	return filtered
+}
 
+// partitionByContext splits mentions into those relevant enough to report and those
+// dropped, recording the filter decision on each
+func (s *Service) partitionByContext(mentions []models.Mention) (kept, rejected []models.Mention) {
 	for _, mention := range mentions {
-		if s.isRelevantMention(mention) {
-			filtered = append(filtered, mention)
+		decision := s.relevanceDecision(mention)
+		mention.Relevance = decision.Relevance
+		mention.Filter = &decision
+		if decision.Kept {
+			kept = append(kept, mention)
+		} else {
+			rejected = append(rejected, mention)
 		}
 	}
 
-	return filtered
+	return kept, rejected
+}
+
+// contextThreshold returns the minimum relevance score a mention needs to be reported
+func (s *Service) contextThreshold() float64 {
+	if s.config.ContextThreshold <= 0 {
+		return defaultContextThreshold
+	}
+	return s.config.ContextThreshold
 }
 
 func (s *Service) isRelevantMention(mention models.Mention) bool {
+	return s.relevanceScore(mention) >= s.contextThreshold()
+}
+
+// relevanceScore rates how likely a mention is to be about Azure Kubernetes Service, from 0 to 1
+func (s *Service) relevanceScore(mention models.Mention) float64 {
+	return s.relevanceDecision(mention).Relevance
+}
+
+// relevanceDecision scores a mention and records what the score is based on. It combines
+// indicator hits, where the keyword appears (title vs body) and per-source trust.
+func (s *Service) relevanceDecision(mention models.Mention) models.FilterDecision {
+	decision := models.FilterDecision{Threshold: s.contextThreshold()}
+
+	// Advisories from the CVE source are authoritative and already scoped to Kubernetes
+	if mention.Source == "cve" {
+		decision.Reasons = append(decision.Reasons, "cve source is authoritative")
+		return s.decide(decision, 1.0)
+	}
+
+	// Operators vouch for trusted YouTube channels, which may not spell out Azure context
+	if mention.Source == "youtube" && sources.YouTubeChannelListed(s.config.YouTubeTrustedChannels, mention.Channel) {
+		decision.Reasons = append(decision.Reasons, "trusted youtube channel "+mention.Author)
+		return s.decide(decision, 1.0)
+	}
+
 	content := strings.ToLower(mention.Content + " " + mention.Title)
+	title := strings.ToLower(mention.Title)
 
 	// Strong Azure Kubernetes Service indicators - these are unambiguous
 	strongAzureIndicators := []string{
@@ -231,103 +447,174 @@ func (s *Service) isRelevantMention(mention models.Mention) bool {
 	// Check for negative indicators first - immediate rejection
 	for _, indicator := range negativeIndicators {
 		if strings.Contains(content, indicator) {
-			return false
+			decision.NegativeIndicator = indicator
+			return s.decide(decision, 0)
 		}
 	}
 
-	// Check for strong Azure indicators - immediate acceptance
+	score := 0.0
+
+	// Strong Azure indicators are unambiguous on their own
 	for _, indicator := range strongAzureIndicators {
 		if strings.Contains(content, indicator) {
-			return true
+			decision.Indicators = append(decision.Indicators, "strong:"+indicator)
+			score = 0.9
+			if strings.Contains(title, indicator) {
+				decision.Reasons = append(decision.Reasons, "strong indicator in title +0.10")
+				score += 0.1
+			}
+			break
 		}
 	}
 
-	// Now handle the ambiguous "aks" case with contextual analysis
-	hasAKS := strings.Contains(content, "aks")
-	if !hasAKS {
-		return false // No AKS mention at all
-	}
+	// Otherwise handle the ambiguous "aks" case with contextual analysis
+	if score == 0 {
+		if !strings.Contains(content, "aks") {
+			decision.Reasons = append(decision.Reasons, "no AKS mention")
+			return s.decide(decision, 0)
+		}
 
-	// AKS mentioned - now we need strong context to prove it's Azure Kubernetes Service
-	azureContextScore := 0
-	kubernetesContextScore := 0
+		// AKS mentioned - now we need strong context to prove it's Azure Kubernetes Service
+		azureContextScore := 0
+		kubernetesContextScore := 0
 
-	// Count Azure context indicators
-	for _, indicator := range azureContextIndicators {
-		if strings.Contains(content, indicator) {
-			azureContextScore++
+		// Count Azure context indicators
+		for _, indicator := range azureContextIndicators {
+			if strings.Contains(content, indicator) {
+				decision.Indicators = append(decision.Indicators, "azure:"+indicator)
+				azureContextScore++
+			}
 		}
-	}
 
-	// Count Kubernetes context indicators
-	for _, indicator := range kubernetesIndicators {
-		if strings.Contains(content, indicator) {
-			kubernetesContextScore++
+		// Count Kubernetes context indicators
+		for _, indicator := range kubernetesIndicators {
+			if strings.Contains(content, indicator) {
+				decision.Indicators = append(decision.Indicators, "kubernetes:"+indicator)
+				kubernetesContextScore++
+			}
+		}
+
+		// Both Azure and Kubernetes context are needed to reach the default threshold;
+		// additional context on either side raises confidence further
+		score = 0.3 + contextContribution(azureContextScore) + contextContribution(kubernetesContextScore)
+
+		// Keyword in the title is a stronger signal than a passing mention in the body
+		if strings.Contains(title, "aks") {
+			decision.Reasons = append(decision.Reasons, "AKS in title +0.05")
+			score += 0.05
 		}
 	}
 
-	// Different thresholds based on source reliability
-	switch mention.Source {
-	case "reddit", "stackoverflow", "hackernews":
-		// Technical platforms - require both Azure and Kubernetes context
-		return azureContextScore >= 1 && kubernetesContextScore >= 1
-		
-	case "medium", "linkedin":
-		// Professional platforms - require strong context but allow reasonable thresholds
-		return azureContextScore >= 1 && kubernetesContextScore >= 1
-		
-	case "youtube":
-		// High noise platform - require strong evidence but not unreasonable
-		// Either strong Azure context OR strong Kubernetes context with some Azure presence
-		return (azureContextScore >= 1 && kubernetesContextScore >= 2) || 
-		       (azureContextScore >= 2 && kubernetesContextScore >= 1)
-		
-	case "twitter":
-		// Mixed platform - require moderate context
-		return azureContextScore >= 1 && kubernetesContextScore >= 1
-		
+	if trust, ok := sourceTrust[mention.Source]; ok {
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf("%s source trust %+.2f", mention.Source, trust))
+		score += trust
+	}
+	if weight, ok := postTypeWeight[mention.PostType]; ok && score > 0 {
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf("%s post %+.2f", mention.PostType, weight))
+		score += weight
+	}
+
+	return s.decide(decision, score)
+}
+
+// decide clamps the score into the decision and labels it against the context threshold
+func (s *Service) decide(decision models.FilterDecision, score float64) models.FilterDecision {
+	// Round to avoid floating point noise right at the threshold
+	score = math.Round(score*100) / 100
+	decision.Relevance = math.Max(0, math.Min(1, score))
+	decision.Kept = decision.Relevance >= decision.Threshold
+	decision.Confidence = confidenceLabel(decision.Relevance, decision.Threshold)
+	return decision
+}
+
+// highConfidenceRelevance is the score of a mention with an unambiguous AKS indicator
+const highConfidenceRelevance = 0.9
+
+// confidenceLabel describes how sure the filter is that a mention is about AKS
+func confidenceLabel(relevance, threshold float64) string {
+	switch {
+	case relevance >= highConfidenceRelevance:
+		return models.ConfidenceHigh
+	case relevance >= threshold:
+		return models.ConfidenceMedium
 	default:
-		// Unknown sources - require reasonable evidence
-		// If we have explicit "aks" mention plus good context, accept it
-		return azureContextScore >= 1 && kubernetesContextScore >= 1
+		return models.ConfidenceLow
 	}
 }
 
-func (s *Service) analyzeSentiment(mentions []models.Mention) {
-	// Basic sentiment analysis - in production, you'd use Azure Cognitive Services
-	for i := range mentions {
-		mentions[i].Sentiment = s.basicSentimentAnalysis(mentions[i].Content)
+// contextContribution converts a context indicator hit count into a relevance contribution
+func contextContribution(hits int) float64 {
+	switch {
+	case hits >= 2:
+		return 0.3
+	case hits == 1:
+		return 0.2
+	default:
+		return 0
 	}
 }
 
+// Lexicon of the basic sentiment analysis
+var (
+	positiveWords = []string{"good", "great", "excellent", "love", "awesome", "fantastic", "helpful", "works", "solved", "success"}
+	negativeWords = []string{"bad", "terrible", "awful", "hate", "broken", "error", "fail", "problem", "issue", "bug"}
+)
+
 func (s *Service) basicSentimentAnalysis(content string) string {
-	content = strings.ToLower(content)
+	sentiment, _ := s.explainSentiment(content)
+	return sentiment
+}
 
-	positiveWords := []string{"good", "great", "excellent", "love", "awesome", "fantastic", "helpful", "works", "solved", "success"}
-	negativeWords := []string{"bad", "terrible", "awful", "hate", "broken", "error", "fail", "problem", "issue", "bug"}
+// explainSentiment classifies content and, when it is negative, returns the words that
+// matched the negative lexicon as written in the content, e.g. "failed" for "fail", so
+// readers can check the label at a glance
+func (s *Service) explainSentiment(content string) (string, []string) {
+	content = strings.ToLower(content)
 
 	positiveCount := 0
-	negativeCount := 0
-
 	for _, word := range positiveWords {
 		if strings.Contains(content, word) {
 			positiveCount++
 		}
 	}
 
+	var phrases []string
 	for _, word := range negativeWords {
-		if strings.Contains(content, word) {
-			negativeCount++
+		if index := strings.Index(content, word); index >= 0 {
+			phrases = append(phrases, wordAt(content, index, len(word)))
 		}
 	}
 
-	if positiveCount > negativeCount {
-		return "positive"
-	} else if negativeCount > positiveCount {
-		return "negative"
+	if positiveCount > len(phrases) {
+		return "positive", nil
+	} else if len(phrases) > positiveCount {
+		return "negative", phrases
 	}
 
-	return "neutral"
+	return "neutral", nil
+}
+
+// wordAt widens the match at content[index:index+length] to the whole word around it
+func wordAt(content string, index, length int) string {
+	isLetter := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '\'' }
+
+	start := index
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(content[:start])
+		if !isLetter(r) {
+			break
+		}
+		start -= size
+	}
+	end := index + length
+	for end < len(content) {
+		r, size := utf8.DecodeRuneInString(content[end:])
+		if !isLetter(r) {
+			break
+		}
+		end += size
+	}
+	return content[start:end]
 }
 
 func (s *Service) storeMentions(mentions []models.Mention) error {
@@ -341,12 +628,57 @@ func (s *Service) storeMentions(mentions []models.Mention) error {
 	}
 
 	filename := fmt.Sprintf("mentions-%s.json", time.Now().Format("2006-01-02-15-04-05"))
-	return s.storage.Store(filename, data)
+	if err := s.storage.Store(filename, data); err != nil {
+		return err
+	}
+
+	s.indexMentions(filename, mentions)
+	return nil
 }
 
-func (s *Service) generateAndSendReport(mentions []models.Mention) error {
+// indexMentions records stored mentions in the daily and full-text indexes. Index failures
+// are logged rather than returned since the mentions themselves have already been persisted.
+func (s *Service) indexMentions(filename string, mentions []models.Mention) {
+	s.searchIndex().Add(filename, mentions)
+
+	if s.index == nil {
+		return
+	}
+
+	if err := s.index.Add(filename, mentions); err != nil {
+		logrus.Warnf("Failed to update mention index for %s: %v", filename, err)
+	}
+}
+
+func (s *Service) generateAndSendReport(ctx context.Context, mentions []models.Mention) error {
+	s.applyTags(mentions)
 	report := s.generateReport(mentions)
-	return s.notificationService.SendReport(report)
+	s.separateAlerted(report)
+	resolved, open := s.resolveQuestions(ctx, mentions)
+	report.Resolved = resolved
+	if s.config.EnableSentimentAnalysis && s.storage != nil {
+		// The pipeline that stored the mentions recorded today's score
+		trend, err := s.SentimentTrend()
+		if err != nil {
+			logrus.Warnf("Failed to load the sentiment trend: %v", err)
+		}
+		report.SentimentTrend = trend
+	}
+	var docsGapsMonth, reliabilityWeek string
+	report.DocsGaps, docsGapsMonth = s.monthlyDocsGaps(time.Now())
+	report.Reliability, reliabilityWeek = s.weeklyReliability(time.Now())
+	s.publishReportArtifact(report)
+	s.storeReportSnapshot(report)
+	s.linkMentionPages(report.Mentions)
+	s.linkMentionPages(report.PreviouslyAlerted)
+	report.SkippedSources = s.skippedSources()
+	if err := s.notificationService.SendReport(report); err != nil {
+		return err
+	}
+	s.trackQuestions(open, report.Unanswered)
+	s.markDocsGapsReported(docsGapsMonth)
+	s.markReliabilityReported(reliabilityWeek)
+	return nil
 }
 
 func (s *Service) generateReport(mentions []models.Mention) *models.Report {
@@ -354,7 +686,7 @@ func (s *Service) generateReport(mentions []models.Mention) *models.Report {
 		GeneratedAt:   time.Now(),
 		Period:        s.config.ReportSchedule,
 		TotalMentions: len(mentions),
-		Mentions:      mentions,
+		Mentions:      s.rankMentions(mentions),
 		Summary:       make(map[string]interface{}),
 	}
 
@@ -370,10 +702,42 @@ func (s *Service) generateReport(mentions []models.Mention) *models.Report {
 	report.Summary["sources"] = sourceCount
 	report.Summary["sentiment"] = sentimentCount
 	report.Summary["top_sources"] = s.getTopSources(sourceCount)
+	report.NegativeComments = s.collectNegativeComments(mentions)
+	report.ReleaseInsights = s.correlateReleases(mentions)
+	report.Unanswered = s.collectUnansweredQuestions(mentions)
 
 	return report
 }
 
+// correlateReleases relates mentions of Kubernetes versions to AKS releases. Release
+// tracker failures are logged so they never block a report.
+func (s *Service) correlateReleases(mentions []models.Mention) []models.ReleaseInsight {
+	if s.releases == nil {
+		return nil
+	}
+
+	insights, err := s.releases.Correlate(mentions)
+	if err != nil {
+		logrus.Warnf("Failed to correlate mentions with AKS releases: %v", err)
+		return nil
+	}
+	return insights
+}
+
+// collectNegativeComments gathers negative discussion comments so they can be surfaced
+// separately from the mentions they belong to
+func (s *Service) collectNegativeComments(mentions []models.Mention) []models.Comment {
+	var negative []models.Comment
+	for _, mention := range mentions {
+		for _, comment := range mention.TopComments {
+			if comment.Sentiment == "negative" {
+				negative = append(negative, comment)
+			}
+		}
+	}
+	return negative
+}
+
 func (s *Service) getTopSources(sourceCount map[string]int) []string {
 	type sourceScore struct {
 		source string
@@ -423,6 +787,8 @@ func (s *Service) updateMetrics(mentions []models.Mention, duration time.Duratio
 		s.metrics.SourceMetrics[mention.Source]++
 		s.metrics.SentimentBreakdown[mention.Sentiment]++
 	}
+
+	s.metrics.KeywordGroupMetrics, s.metrics.KeywordMetrics = s.keywordMetrics(mentions)
 }
 
 func (s *Service) getLastRunTime() time.Time {
@@ -440,9 +806,20 @@ func (s *Service) getLastRunTime() time.Time {
 // GetMetrics returns current metrics as JSON
 func (s *Service) GetMetrics() string {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	metrics := *s.metrics
+	s.mu.RUnlock()
+
+	if queue, ok := s.notificationService.(deliveryQueue); ok {
+		if status, err := queue.QueueStatus(); err != nil {
+			logrus.Warnf("Failed to read the notification queue: %v", err)
+		} else if status.Enabled {
+			pending, failed := len(status.Pending), len(status.Failed)
+			metrics.PendingDeliveries, metrics.FailedDeliveries = &pending, &failed
+		}
+	}
+	metrics.SkippedSources = s.skippedSources()
 
-	data, _ := json.MarshalIndent(s.metrics, "", "  ")
+	data, _ := json.MarshalIndent(&metrics, "", "  ")
 	return string(data)
 }
 
@@ -451,7 +828,7 @@ func (s *Service) GenerateTestReport(mentions []models.Mention) *models.Report {
 	// Apply sentiment analysis to mentions that don't have it
 	for i := range mentions {
 		if mentions[i].Sentiment == "" {
-			mentions[i].Sentiment = s.basicSentimentAnalysis(mentions[i].Content)
+			mentions[i].Sentiment, mentions[i].SentimentPhrases = s.explainSentiment(mentions[i].Content)
 		}
 	}
 
@@ -461,60 +838,88 @@ func (s *Service) GenerateTestReport(mentions []models.Mention) *models.Report {
 // RunUrgentCheck performs a focused check for urgent mentions (security issues, breaking changes, etc.)
 // This runs every 4 hours and only notifies about truly urgent content
 func (s *Service) RunUrgentCheck() error {
+	runCtx, done, err := s.runs.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	start := time.Now()
 	logrus.Info("Starting urgent mentions check")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	ctx, cancel := context.WithTimeout(runCtx, 10*time.Minute)
 	defer cancel()
 
-	var allMentions []models.Mention
-	var wg sync.WaitGroup
-	mentionsChan := make(chan []models.Mention, len(s.sources))
-	errorsChan := make(chan error, len(s.sources))
+	runID := start.Format(runIDLayout)
+	meter := usage.NewMeter()
+	ctx = usage.WithMeter(ctx, meter)
+	defer s.recordRunCost(runID, RunKindUrgent, start, meter)
 
 	// For urgent checks, only look at the last 4 hours
 	searchWindow := 4 * time.Hour
 	logrus.Info("Searching for urgent mentions in the last 4 hours")
 
-	// Fetch mentions from all sources concurrently
-	for _, source := range s.sources {
-		wg.Add(1)
-		go func(src sources.Source) {
-			defer wg.Done()
+	// Fetch mentions from the urgent sources through the bounded worker pool, under the
+	// tighter urgent timeouts
+	var allMentions []models.Mention
+	outcomes := make(map[string]error)
+	for _, result := range s.fetchFromSources(ctx, s.urgentSources(), s.config.UrgentKeywordList(), searchWindow, s.urgentSourceTimeout) {
+		allMentions = append(allMentions, result.mentions...)
+		outcomes[result.source] = result.err
+	}
+	s.recordRunErrors(runID, RunKindUrgent, start, outcomes)
 
-			logrus.Infof("Checking %s for urgent mentions (4h window)", src.GetName())
-			mentions, err := src.FetchMentions(ctx, s.config.Keywords, searchWindow)
+	if runCtx.Err() != nil {
+		return fmt.Errorf("urgent check stopped: %w", ErrShuttingDown)
+	}
 
-			if err != nil {
-				logrus.Errorf("Error fetching urgent mentions from %s: %v", src.GetName(), err)
-				errorsChan <- err
-				return
-			}
+	logrus.Infof("Found %d total mentions for urgent check", len(allMentions))
 
-			mentionsChan <- mentions
-		}(source)
+	sent, err := s.notifyUrgent(ctx, allMentions, "4-hour urgent check")
+	if err != nil {
+		return err
 	}
 
-	// Close channels when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(mentionsChan)
-		close(errorsChan)
-	}()
+	logrus.Infof("Urgent check completed in %v, sent %d urgent alerts", time.Since(start), sent)
+	return nil
+}
+
+// urgentSources returns the sources polled by urgent checks: those in URGENT_SOURCES, or all
+// of them when it is empty. Twitter is left out while the filtered stream is delivering its
+// mentions in real time.
+func (s *Service) urgentSources() []sources.Source {
+	streaming := s.twitterStreaming.Load()
 
-	// Collect results
-	for mentions := range mentionsChan {
-		allMentions = append(allMentions, mentions...)
+	polled := make([]sources.Source, 0, len(s.sources))
+	for _, source := range s.sources {
+		name := source.GetName()
+		if len(s.config.UrgentSources) > 0 && !containsString(s.config.UrgentSources, name) {
+			continue
+		}
+		if streaming && name == "twitter" {
+			continue
+		}
+		polled = append(polled, source)
 	}
+	return polled
+}
 
-	logrus.Infof("Found %d total mentions for urgent check", len(allMentions))
+// notifyUrgent filters mentions down to unhandled urgent ones whose story is not cooling down,
+// verified by the LLM when enabled, stores them and sends an urgent notification, returning how
+// many were sent
+func (s *Service) notifyUrgent(ctx context.Context, mentions []models.Mention, period string) (int, error) {
+	// Filter for urgent mentions only, then link community chatter to the advisories it cites
+	urgentMentions := s.withoutHandled(s.filterUrgentMentions(s.filterBlocked(mentions)))
+	linkAdvisories(urgentMentions)
 
-	// Filter for urgent mentions only
-	urgentMentions := s.filterUrgentMentions(allMentions)
+	// Stories alerted recently are left to the daily digest rather than paging again
+	urgentMentions = s.withoutCooledDown(urgentMentions, time.Now())
+	urgentMentions = s.verifyUrgentMentions(ctx, urgentMentions)
+	s.extractExcerpts(urgentMentions)
 
 	if len(urgentMentions) == 0 {
 		logrus.Info("No urgent mentions found")
-		return nil
+		return 0, nil
 	}
 
 	logrus.Infof("Found %d urgent mentions requiring immediate notification", len(urgentMentions))
@@ -522,17 +927,21 @@ func (s *Service) RunUrgentCheck() error {
 	// Store urgent mentions
 	if err := s.storeMentions(urgentMentions); err != nil {
 		logrus.Errorf("Failed to store urgent mentions: %v", err)
-		return err
+		return 0, err
 	}
+	s.saveSearchIndex()
+	s.publishMentions(urgentMentions)
 
 	// Send urgent notification
-	if err := s.sendUrgentNotification(urgentMentions); err != nil {
+	if err := s.sendUrgentNotification(urgentMentions, period); err != nil {
 		logrus.Errorf("Failed to send urgent notification: %v", err)
-		return err
+		return 0, err
 	}
+	now := time.Now()
+	s.recordUrgentAlerts(urgentMentions, now)
+	s.recordUrgentStories(urgentMentions, now)
 
-	logrus.Infof("Urgent check completed in %v, sent %d urgent alerts", time.Since(start), len(urgentMentions))
-	return nil
+	return len(urgentMentions), nil
 }
 
 // filterUrgentMentions identifies mentions that require immediate attention
@@ -556,8 +965,23 @@ func (s *Service) filterUrgentMentions(mentions []models.Mention) []models.Menti
 
 // isUrgentMention determines if a mention requires immediate notification
 func (s *Service) isUrgentMention(mention models.Mention) bool {
+	// Every Kubernetes CVE from the advisory feed warrants an urgent look
+	if mention.Source == "cve" {
+		logrus.Infof("Urgent mention detected (advisory): %s", mention.Title)
+		return true
+	}
+
 	content := strings.ToLower(mention.Content + " " + mention.Title)
 
+	if mention.PostType == models.PostTypeAskHN {
+		for _, keyword := range askReliabilityKeywords {
+			if strings.Contains(content, keyword) {
+				logrus.Infof("Urgent mention detected (Ask HN reliability): %s in %s", keyword, mention.Title)
+				return true
+			}
+		}
+	}
+
 	// Security-related urgent keywords
 	securityKeywords := []string{
 		"security vulnerability", "cve", "exploit", "breach", "attack",
@@ -604,8 +1028,27 @@ func (s *Service) isUrgentMention(mention models.Mention) bool {
 	return false
 }
 
+// urgentDescription summarizes an urgent alert, calling out security advisories separately
+func urgentDescription(mentions []models.Mention) string {
+	advisories := 0
+	for _, mention := range mentions {
+		if mention.Source == "cve" {
+			advisories++
+		}
+	}
+
+	description := fmt.Sprintf("Found %d urgent AKS-related mentions requiring immediate attention", len(mentions))
+	switch {
+	case advisories == 1:
+		description += ", including 1 security advisory"
+	case advisories > 1:
+		description += fmt.Sprintf(", including %d security advisories", advisories)
+	}
+	return description
+}
+
 // sendUrgentNotification sends immediate notifications for urgent mentions
-func (s *Service) sendUrgentNotification(mentions []models.Mention) error {
+func (s *Service) sendUrgentNotification(mentions []models.Mention, period string) error {
 	if len(mentions) == 0 {
 		return nil
 	}
@@ -613,16 +1056,18 @@ func (s *Service) sendUrgentNotification(mentions []models.Mention) error {
 	// Create urgent report with correct structure
 	report := &models.Report{
 		GeneratedAt:   time.Now(),
-		Period:        "4-hour urgent check",
+		Period:        period,
 		TotalMentions: len(mentions),
 		Mentions:      mentions,
 		Summary: map[string]interface{}{
 			"title":       "🚨 URGENT AKS Mentions Alert",
-			"description": fmt.Sprintf("Found %d urgent AKS-related mentions requiring immediate attention", len(mentions)),
+			"description": urgentDescription(mentions),
 			"type":        "urgent",
 		},
 	}
 
+	s.linkMentionPages(report.Mentions)
+
 	// Send notification
 	if err := s.notificationService.SendReport(report); err != nil {
 		return fmt.Errorf("failed to send urgent notification: %w", err)
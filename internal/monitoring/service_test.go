@@ -1,58 +1,22 @@
 package monitoring
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/config"
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// MockStorage is a mock implementation of the storage interface
-type MockStorage struct {
-	mock.Mock
-}
-
-func (m *MockStorage) Store(filename string, data []byte) error {
-	args := m.Called(filename, data)
-	return args.Error(0)
-}
-
-func (m *MockStorage) Retrieve(filename string) ([]byte, error) {
-	args := m.Called(filename)
-	return args.Get(0).([]byte), args.Error(1)
-}
-
-func (m *MockStorage) List(prefix string) ([]string, error) {
-	args := m.Called(prefix)
-	return args.Get(0).([]string), args.Error(1)
-}
-
-func (m *MockStorage) Delete(filename string) error {
-	args := m.Called(filename)
-	return args.Error(0)
-}
-
-// MockNotificationService is a mock implementation of the notification service
-type MockNotificationService struct {
-	mock.Mock
-}
-
-func (m *MockNotificationService) SendReport(report *models.Report) error {
-	args := m.Called(report)
-	return args.Error(0)
-}
-
-func (m *MockNotificationService) SendAlert(alert *models.Alert) error {
-	args := m.Called(alert)
-	return args.Error(0)
-}
-
 func TestService_isRelevantMention(t *testing.T) {
 	cfg := &config.Config{}
-	mockStorage := &MockStorage{}
-	mockNotifications := &MockNotificationService{}
+	mockStorage := &testutil.MockStorage{}
+	mockNotifications := &testutil.MockNotificationService{}
 	
 	service := NewService(cfg, mockStorage, mockNotifications)
 
@@ -105,8 +69,8 @@ func TestService_isRelevantMention(t *testing.T) {
 
 func TestService_basicSentimentAnalysis(t *testing.T) {
 	cfg := &config.Config{}
-	mockStorage := &MockStorage{}
-	mockNotifications := &MockNotificationService{}
+	mockStorage := &testutil.MockStorage{}
+	mockNotifications := &testutil.MockNotificationService{}
 	
 	service := NewService(cfg, mockStorage, mockNotifications)
 
@@ -140,12 +104,29 @@ func TestService_basicSentimentAnalysis(t *testing.T) {
 	}
 }
 
+func TestService_explainSentiment(t *testing.T) {
+	service := &Service{config: &config.Config{EnableSentimentAnalysis: true}}
+
+	sentiment, phrases := service.explainSentiment("Upgrade FAILED with a timeout error, node pools are broken")
+	assert.Equal(t, "negative", sentiment)
+	assert.Equal(t, []string{"broken", "error", "failed"}, phrases)
+
+	sentiment, phrases = service.explainSentiment("Great docs, the fix works")
+	assert.Equal(t, "positive", sentiment)
+	assert.Empty(t, phrases)
+
+	mentions := []models.Mention{{Content: "Another bug in the autoscaler's scale-down issues"}}
+	service.enrichMentions(context.Background(), mentions)
+	assert.Equal(t, "negative", mentions[0].Sentiment)
+	assert.Equal(t, []string{"issues", "bug"}, mentions[0].SentimentPhrases)
+}
+
 func TestService_generateReport(t *testing.T) {
 	cfg := &config.Config{
 		ReportSchedule: "weekly",
 	}
-	mockStorage := &MockStorage{}
-	mockNotifications := &MockNotificationService{}
+	mockStorage := &testutil.MockStorage{}
+	mockNotifications := &testutil.MockNotificationService{}
 	
 	service := NewService(cfg, mockStorage, mockNotifications)
 
@@ -171,7 +152,10 @@ func TestService_generateReport(t *testing.T) {
 
 	assert.Equal(t, "weekly", report.Period)
 	assert.Equal(t, 3, report.TotalMentions)
-	assert.Equal(t, mentions, report.Mentions)
+	require.Len(t, report.Mentions, 3)
+	assert.Equal(t, "2", report.Mentions[0].ID, "negative Stack Overflow mentions rank first")
+	assert.Equal(t, "3", report.Mentions[1].ID)
+	assert.Equal(t, 3, report.Mentions[2].Rank.Position)
 	
 	// Check summary
 	sources := report.Summary["sources"].(map[string]int)
@@ -183,3 +167,308 @@ func TestService_generateReport(t *testing.T) {
 	assert.Equal(t, 1, sentiment["negative"])
 	assert.Equal(t, 1, sentiment["neutral"])
 }
+
+func TestService_generateReport_negativeComments(t *testing.T) {
+	cfg := &config.Config{ReportSchedule: "weekly", EnableSentimentAnalysis: true}
+	service := &Service{config: cfg}
+
+	mentions := []models.Mention{
+		{
+			ID:     "hackernews_1",
+			Source: "hackernews",
+			TopComments: []models.Comment{
+				{ID: "hackernews_2", Content: "AKS upgrades are broken again, terrible experience"},
+				{ID: "hackernews_3", Content: "AKS has been great for us"},
+			},
+		},
+	}
+
+	service.enrichMentions(context.Background(), mentions)
+	report := service.generateReport(mentions)
+
+	assert.Len(t, report.NegativeComments, 1)
+	assert.Equal(t, "hackernews_2", report.NegativeComments[0].ID)
+}
+
+// stubSource is a configurable source used to exercise the fetch worker pool
+type stubSource struct {
+	name     string
+	delay    time.Duration
+	mentions []models.Mention
+	since    time.Duration // window of the last fetch
+}
+
+func (s *stubSource) GetName() string { return s.name }
+func (s *stubSource) IsEnabled() bool { return true }
+
+func (s *stubSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	s.since = since
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(s.delay):
+		return s.mentions, nil
+	}
+}
+
+func TestService_fetchFromSources(t *testing.T) {
+	cfg := &config.Config{
+		SourceConcurrency: 2,
+		SourceTimeout:     time.Second,
+		SourceTimeouts:    map[string]time.Duration{"slow": 50 * time.Millisecond},
+	}
+	service := &Service{config: cfg}
+
+	srcs := []sources.Source{
+		&stubSource{name: "fast", mentions: []models.Mention{{ID: "fast_1"}}},
+		&stubSource{name: "slow", delay: time.Minute, mentions: []models.Mention{{ID: "slow_1"}}},
+		&stubSource{name: "other", mentions: []models.Mention{{ID: "other_1"}, {ID: "other_2"}}},
+	}
+
+	start := time.Now()
+	results := service.fetchFromSources(context.Background(), srcs, []string{"aks"}, time.Hour, service.sourceTimeout)
+	assert.Less(t, time.Since(start), time.Second, "slow source should be cut off by its own timeout")
+
+	bySource := make(map[string]fetchResult)
+	for _, result := range results {
+		bySource[result.source] = result
+	}
+
+	assert.Len(t, results, 3)
+	assert.NoError(t, bySource["fast"].err)
+	assert.Len(t, bySource["fast"].mentions, 1)
+	assert.Len(t, bySource["other"].mentions, 2)
+	assert.ErrorIs(t, bySource["slow"].err, context.DeadlineExceeded)
+	assert.Empty(t, bySource["slow"].mentions)
+}
+
+func TestService_runPipeline(t *testing.T) {
+	cfg := &config.Config{
+		EnableContextFiltering:  true,
+		EnableSentimentAnalysis: true,
+		SourceTimeout:           time.Second,
+	}
+	storage := testutil.NewMemoryStorage()
+	service := &Service{config: cfg, storage: storage}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "Great AKS cluster upgrade", Content: "Azure Kubernetes Service works great"},
+			{ID: "reddit_2", Source: "reddit", Title: "AKS-47 rifle review", Content: "gun range day"},
+		}},
+		&stubSource{name: "hackernews", mentions: []models.Mention{
+			{ID: "hackernews_1", Source: "hackernews", Title: "Running KAITO on AKS", Content: "kubernetes inference"},
+		}},
+		&stubSource{name: "medium"},
+	}
+
+	result := service.runPipeline(context.Background(), "run", []string{"aks"}, fixedWindow(time.Hour))
+
+	assert.NoError(t, result.storeErr)
+	assert.Equal(t, 0, result.fetchErrors)
+	assert.Len(t, result.mentions, 2)
+	for _, mention := range result.mentions {
+		assert.NotEmpty(t, mention.Sentiment)
+	}
+
+	// Each source with relevant mentions is persisted as its own batch
+	assert.Contains(t, storage.Blobs(), "mentions-run-reddit.json")
+	assert.Contains(t, storage.Blobs(), "mentions-run-hackernews.json")
+	assert.NotContains(t, storage.Blobs(), "mentions-run-medium.json")
+}
+
+func TestService_Backfill(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	notifications := testutil.NewRecordingNotificationService()
+	service := &Service{config: &config.Config{SourceTimeout: time.Second}, storage: storage, notificationService: notifications}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade notes", Content: "Azure Kubernetes Service 1.30"},
+		}},
+	}
+
+	count, err := service.Backfill(context.Background(), 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Empty(t, notifications.Reports(), "backfill must not send a report")
+	assert.Len(t, service.Search("upgrade", 10), 1)
+
+	_, err = service.Backfill(context.Background(), 0)
+	assert.Error(t, err)
+}
+
+func TestService_relevanceScore(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+
+	strong := models.Mention{Source: "reddit", Title: "Azure Kubernetes Service upgrade notes", Content: "Upgrading clusters"}
+	contextual := models.Mention{Source: "reddit", Title: "Question", Content: "Our aks setup on azure uses helm"}
+	noisy := models.Mention{Source: "youtube", Title: "Question", Content: "Our aks setup on azure uses helm"}
+	weapon := models.Mention{Source: "reddit", Title: "AKS rifle", Content: "azure paint on a gun"}
+
+	assert.Equal(t, 1.0, service.relevanceScore(strong))
+	assert.Equal(t, 0.7, service.relevanceScore(contextual))
+	assert.Equal(t, 0.65, service.relevanceScore(noisy))
+	assert.Equal(t, 0.0, service.relevanceScore(weapon))
+}
+
+func TestService_postTypeWeighting(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+
+	ask := models.Mention{Source: "hackernews", PostType: models.PostTypeAskHN, Title: "Ask HN: aks on azure?", Content: "Our aks setup uses helm"}
+	story := models.Mention{Source: "hackernews", PostType: models.PostTypeStory, Title: "Question", Content: "Our aks setup on azure uses helm"}
+	comment := models.Mention{Source: "hackernews", PostType: models.PostTypeComment, Title: "Question", Content: "Our aks setup on azure uses helm"}
+
+	assert.Equal(t, 0.85, service.relevanceScore(ask))
+	assert.Equal(t, 0.7, service.relevanceScore(story))
+	assert.Equal(t, 0.6, service.relevanceScore(comment))
+
+	complaint := models.Mention{Source: "hackernews", PostType: models.PostTypeAskHN, Title: "Ask HN: Is AKS reliability getting worse?"}
+	assert.True(t, service.isUrgentMention(complaint))
+	complaint.PostType = models.PostTypeStory
+	assert.False(t, service.isUrgentMention(complaint), "reliability talk is only urgent in Ask HN threads")
+}
+
+func TestService_filterByContext_threshold(t *testing.T) {
+	mentions := []models.Mention{
+		{ID: "strong", Source: "reddit", Title: "Azure Kubernetes Service upgrade notes"},
+		{ID: "borderline", Source: "youtube", Title: "Question", Content: "Our aks setup on azure uses helm"},
+	}
+
+	strict := &Service{config: &config.Config{ContextThreshold: 0.7}}
+	filtered := strict.filterByContext(mentions)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "strong", filtered[0].ID)
+	assert.Equal(t, 1.0, filtered[0].Relevance)
+
+	lenient := &Service{config: &config.Config{ContextThreshold: 0.6}}
+	filtered = lenient.filterByContext(mentions)
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, 0.65, filtered[1].Relevance)
+}
+
+func TestService_sourceHealth(t *testing.T) {
+	cfg := &config.Config{
+		Keywords:       []string{"AKS"},
+		SourceTimeout:  time.Second,
+		SourceTimeouts: map[string]time.Duration{"slow": 20 * time.Millisecond},
+	}
+	service := &Service{config: cfg}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{{ID: "reddit_1", Title: "AKS question"}}},
+		&stubSource{name: "slow", delay: time.Minute},
+	}
+
+	service.fetchFromSources(context.Background(), service.sources, cfg.Keywords, time.Hour, service.sourceTimeout)
+
+	statuses := service.GetSourceStatuses()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "reddit", statuses[0].Name)
+	assert.Equal(t, "missing", statuses[0].Credentials)
+	assert.NotNil(t, statuses[0].LastSuccess)
+	assert.Equal(t, 1, statuses[0].LastMentionCount)
+	assert.Nil(t, statuses[1].LastSuccess)
+	assert.NotEmpty(t, statuses[1].LastError)
+
+	result, err := service.TestSource(context.Background(), "reddit", "")
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "AKS", result.Keyword)
+	assert.Equal(t, []string{"AKS question"}, result.SampleTitles)
+
+	_, err = service.TestSource(context.Background(), "unknown", "AKS")
+	assert.ErrorIs(t, err, ErrUnknownSource)
+}
+
+func TestLinkAdvisories(t *testing.T) {
+	advisory := models.Advisory{ID: "CVE-2024-12345", URL: "https://nvd.nist.gov/vuln/detail/CVE-2024-12345", Severity: "HIGH", Score: 8.8}
+	mentions := []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "Patch your AKS nodes for cve-2024-12345 now"},
+		{ID: "hackernews_1", Source: "hackernews", Content: "Is CVE-2024-99999 exploitable on AKS? CVE-2024-99999 again"},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS outage"},
+		{ID: "cve_CVE-2024-12345", Source: "cve", Title: "CVE-2024-12345 (HIGH 8.8)", Advisories: []models.Advisory{advisory}},
+	}
+
+	linkAdvisories(mentions)
+
+	assert.Equal(t, "cve", mentions[0].Source, "advisories lead the alert")
+	assert.Equal(t, "reddit_1", mentions[1].ID)
+	assert.Equal(t, []models.Advisory{advisory}, mentions[1].Advisories, "known advisories carry severity")
+	assert.Equal(t, []models.Advisory{{ID: "CVE-2024-99999", URL: "https://nvd.nist.gov/vuln/detail/CVE-2024-99999"}}, mentions[2].Advisories)
+	assert.Empty(t, mentions[3].Advisories)
+}
+
+func TestClassifyQuestion(t *testing.T) {
+	tests := []struct {
+		name       string
+		mention    models.Mention
+		isQuestion bool
+		certain    bool
+	}{
+		{"stack overflow", models.Mention{Source: "stackoverflow", Title: "AKS ingress 502 errors"}, true, true},
+		{"question mark title", models.Mention{Source: "reddit", Title: "Is AKS worth it?"}, true, true},
+		{"interrogative opener", models.Mention{Source: "reddit", Title: "How to scale AKS node pools"}, true, true},
+		{"help phrase", models.Mention{Source: "reddit", Title: "AKS networking", Content: "Does anyone run Cilium on AKS"}, true, true},
+		{"rhetorical", models.Mention{Source: "medium", Title: "Our AKS journey", Content: "Why did we move? Cost."}, true, false},
+		{"statement", models.Mention{Source: "hackernews", Title: "AKS now supports KEDA", Content: "Announced today."}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isQuestion, certain := classifyQuestion(tt.mention)
+			assert.Equal(t, tt.isQuestion, isQuestion)
+			assert.Equal(t, tt.certain, certain)
+		})
+	}
+}
+
+func TestService_collectUnansweredQuestions(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+	now := time.Now()
+
+	mentions := []models.Mention{
+		{ID: "so_new", Source: "stackoverflow", CreatedAt: now},
+		{ID: "so_answered", Source: "stackoverflow", CreatedAt: now.Add(-3 * time.Hour), CommentCount: 2},
+		{ID: "reddit_old", Source: "reddit", Title: "Why is my AKS cluster slow?", CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "reddit_statement", Source: "reddit", Title: "AKS 1.30 is out", CreatedAt: now.Add(-time.Hour)},
+	}
+	service.enrichMentions(context.Background(), mentions)
+
+	unanswered := service.collectUnansweredQuestions(mentions)
+	require.Len(t, unanswered, 2)
+	assert.Equal(t, "reddit_old", unanswered[0].ID, "oldest questions come first")
+	assert.Equal(t, "so_new", unanswered[1].ID)
+}
+
+func TestService_urgentSources(t *testing.T) {
+	service := &Service{config: &config.Config{}}
+	service.sources = []sources.Source{&stubSource{name: "reddit"}, &stubSource{name: "twitter"}}
+
+	assert.Len(t, service.urgentSources(), 2)
+
+	// The filtered stream delivers tweets, so urgent checks stop polling Twitter search
+	service.twitterStreaming.Store(true)
+	polled := service.urgentSources()
+	require.Len(t, polled, 1)
+	assert.Equal(t, "reddit", polled[0].GetName())
+}
+
+func TestService_urgentSources_Subset(t *testing.T) {
+	service := &Service{config: &config.Config{
+		UrgentSources:        []string{"twitter", "hackernews"},
+		UrgentSourceTimeout:  2 * time.Minute,
+		UrgentSourceTimeouts: map[string]time.Duration{"twitter": 30 * time.Second},
+	}}
+	service.sources = []sources.Source{&stubSource{name: "reddit"}, &stubSource{name: "twitter"}, &stubSource{name: "hackernews"}}
+
+	polled := service.urgentSources()
+	require.Len(t, polled, 2)
+	assert.Equal(t, "twitter", polled[0].GetName())
+	assert.Equal(t, "hackernews", polled[1].GetName())
+
+	service.twitterStreaming.Store(true)
+	polled = service.urgentSources()
+	require.Len(t, polled, 1)
+	assert.Equal(t, "hackernews", polled[0].GetName())
+
+	assert.Equal(t, 30*time.Second, service.urgentSourceTimeout("twitter"))
+	assert.Equal(t, 2*time.Minute, service.urgentSourceTimeout("hackernews"))
+}
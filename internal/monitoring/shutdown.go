@@ -0,0 +1,217 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// interruptedRunsPrefix holds a record of each monitoring run cut short by shutdown
+const interruptedRunsPrefix = "runs/interrupted/"
+
+var (
+	// ErrShuttingDown is returned for runs started after Shutdown
+	ErrShuttingDown = errors.New("monitoring service is shutting down")
+	// ErrRunInterrupted is returned by a monitoring run stopped by Shutdown. What it had
+	// collected is stored and the next run catches up on its window.
+	ErrRunInterrupted = errors.New("monitoring run interrupted by shutdown")
+	// ErrRunInProgress is returned for a monitoring run started while another is running, so
+	// the scheduler, catch-up and manual triggers never run two at once
+	ErrRunInProgress = errors.New("a monitoring run is already in progress")
+)
+
+// InterruptedRun records a monitoring run stopped by shutdown before its report was sent
+type InterruptedRun struct {
+	RunID            string    `json:"run_id"`
+	StartedAt        time.Time `json:"started_at"`
+	InterruptedAt    time.Time `json:"interrupted_at"`
+	Window           string    `json:"window"`
+	StoredMentions   int       `json:"stored_mentions"`
+	CompletedSources []string  `json:"completed_sources,omitempty"`
+}
+
+// runTracker cancels in-flight runs on shutdown and waits for them to finish storing. The
+// zero value is ready to use.
+type runTracker struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	active   sync.WaitGroup
+	stopping bool
+}
+
+// begin registers a run, returning the context cancelled on shutdown and the function to
+// call when the run is done
+func (r *runTracker) begin() (context.Context, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopping {
+		return nil, nil, ErrShuttingDown
+	}
+	if r.ctx == nil {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+	}
+	r.active.Add(1)
+	return r.ctx, r.active.Done, nil
+}
+
+// shutdown cancels the active runs and waits for them until ctx is done
+func (r *runTracker) shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopping = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.mu.Unlock()
+
+	return r.wait(ctx)
+}
+
+// drain stops new runs and waits until ctx is done for the active ones to finish, without
+// cancelling them
+func (r *runTracker) drain(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopping = true
+	r.mu.Unlock()
+
+	return r.wait(ctx)
+}
+
+// wait waits for the active runs until ctx is done
+func (r *runTracker) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("in-flight runs did not finish: %w", ctx.Err())
+	}
+}
+
+// Shutdown stops new runs and cancels in-flight monitoring and urgent runs. Sources stop
+// fetching, and what has been collected is stored before the runs return; an interrupted
+// monitoring run is recorded so the next run catches up on its window. Shutdown waits for
+// the runs until ctx is done. Live mention subscriptions are closed and buffered clicks are
+// written.
+func (s *Service) Shutdown(ctx context.Context) error {
+	logrus.Info("Stopping in-flight monitoring runs")
+	s.closeLive()
+	if err := s.FlushClicks(); err != nil {
+		logrus.Warnf("Failed to record clicks: %v", err)
+	}
+	return s.runs.shutdown(ctx)
+}
+
+// Drain stops new runs and lets in-flight runs finish, returning an error if they are still
+// running when ctx is done. serve drains the services it replaces when their configuration
+// changes, then shuts them down.
+func (s *Service) Drain(ctx context.Context) error {
+	return s.runs.drain(ctx)
+}
+
+// recordInterruptedRun stores the record the next monitoring run catches up from
+func (s *Service) recordInterruptedRun(runID string, start time.Time, window time.Duration, result *pipelineResult) {
+	var completed []string
+	for source := range result.latest {
+		completed = append(completed, source)
+	}
+	sort.Strings(completed)
+
+	run := InterruptedRun{
+		RunID:            runID,
+		StartedAt:        start,
+		InterruptedAt:    time.Now(),
+		Window:           window.String(),
+		StoredMentions:   len(result.mentions),
+		CompletedSources: completed,
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		logrus.Errorf("Failed to marshal interrupted run %s: %v", runID, err)
+		return
+	}
+	if err := s.storage.Store(interruptedRunsPrefix+runID+".json", data); err != nil {
+		logrus.Errorf("Failed to record interrupted run %s: %v", runID, err)
+		return
+	}
+	logrus.Warnf("Monitoring run %s interrupted after storing %d mentions; the next run will catch up", runID, len(result.mentions))
+}
+
+// InterruptedRuns returns the monitoring runs interrupted by shutdown that no run has
+// caught up on yet, oldest first
+func (s *Service) InterruptedRuns() ([]InterruptedRun, error) {
+	if s.storage == nil {
+		return nil, nil
+	}
+
+	names, err := s.storage.List(interruptedRunsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interrupted runs: %w", err)
+	}
+	sort.Strings(names)
+
+	var runs []InterruptedRun
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := s.storage.Retrieve(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+		var run InterruptedRun
+		if err := json.Unmarshal(data, &run); err != nil {
+			logrus.Warnf("Skipping unreadable interrupted run %s: %v", name, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// catchUpWindow widens the search window to cover the windows of interrupted runs, bounded
+// by the watermark maximum
+func (s *Service) catchUpWindow(window time.Duration, interrupted []InterruptedRun) time.Duration {
+	maxWindow := s.config.WatermarkMaxWindow
+	if maxWindow <= 0 {
+		maxWindow = defaultWatermarkMaxWindow
+	}
+
+	now := time.Now()
+	for _, run := range interrupted {
+		runWindow, err := time.ParseDuration(run.Window)
+		if err != nil {
+			continue
+		}
+		if needed := now.Sub(run.StartedAt) + runWindow; needed > window {
+			window = needed.Round(time.Minute)
+		}
+	}
+	if window > maxWindow {
+		logrus.Warnf("Catch-up window %v exceeds the maximum, limiting it to %v", window, maxWindow)
+		window = maxWindow
+	}
+	return window
+}
+
+// clearInterruptedRuns deletes the records of runs a completed run has caught up on
+func (s *Service) clearInterruptedRuns(runs []InterruptedRun) {
+	for _, run := range runs {
+		if err := s.storage.Delete(interruptedRunsPrefix + run.RunID + ".json"); err != nil {
+			logrus.Warnf("Failed to clear interrupted run %s: %v", run.RunID, err)
+		}
+	}
+}
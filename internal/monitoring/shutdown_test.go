@@ -0,0 +1,122 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingSource fetches until its context is cancelled
+type blockingSource struct {
+	started chan struct{}
+}
+
+func (b *blockingSource) GetName() string { return "youtube" }
+func (b *blockingSource) IsEnabled() bool { return true }
+
+func (b *blockingSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newShutdownTestService(storage *testutil.MemoryStorage, notifications *testutil.RecordingNotificationService) *Service {
+	// Sources run one at a time, so the first has finished when the second blocks
+	cfg := &config.Config{ReportSchedule: "daily", SourceConcurrency: 1, SourceTimeout: time.Minute, Keywords: []string{"aks"}}
+	service := NewService(cfg, storage, notifications)
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade notes", Content: "Azure Kubernetes Service 1.30", CreatedAt: time.Now().Add(-time.Hour)},
+		}},
+	}
+	return service
+}
+
+func TestService_Shutdown(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	notifications := testutil.NewRecordingNotificationService()
+	service := newShutdownTestService(storage, notifications)
+	blocking := &blockingSource{started: make(chan struct{})}
+	service.sources = append(service.sources, blocking)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- service.RunMonitoring() }()
+	<-blocking.started
+
+	// A catch-up or manual run waits for the next turn instead of running alongside
+	assert.True(t, service.MonitoringRunning())
+	assert.ErrorIs(t, service.RunMonitoring(), ErrRunInProgress)
+
+	require.NoError(t, service.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-runErr, ErrRunInterrupted)
+	assert.ErrorIs(t, service.RunMonitoring(), ErrShuttingDown)
+
+	// The completed source was stored, the report was left to the catch-up run
+	assert.Empty(t, notifications.Reports())
+	names, err := storage.List("mentions-")
+	require.NoError(t, err)
+	assert.Len(t, names, 1)
+
+	runs, err := service.InterruptedRuns()
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, 1, runs[0].StoredMentions)
+	assert.Equal(t, []string{"reddit"}, runs[0].CompletedSources)
+	assert.Equal(t, "24h0m0s", runs[0].Window)
+
+	// A new service catches up on the interrupted run's window and reports. Backdate the
+	// interruption so its window reaches further back than the regular one.
+	runs[0].StartedAt = runs[0].StartedAt.Add(-12 * time.Hour)
+	data, err := json.Marshal(runs[0])
+	require.NoError(t, err)
+	require.NoError(t, storage.Store(interruptedRunsPrefix+runs[0].RunID+".json", data))
+
+	restarted := newShutdownTestService(storage, notifications)
+	require.NoError(t, restarted.RunMonitoring())
+	assert.Len(t, notifications.Reports(), 1)
+	assert.Equal(t, 36*time.Hour, restarted.sources[0].(*stubSource).since)
+
+	runs, err = restarted.InterruptedRuns()
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestService_Drain(t *testing.T) {
+	service := newShutdownTestService(testutil.NewMemoryStorage(), testutil.NewRecordingNotificationService())
+	blocking := &blockingSource{started: make(chan struct{})}
+	service.sources = append(service.sources, blocking)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- service.RunMonitoring() }()
+	<-blocking.started
+
+	// Draining leaves the in-flight run alone but refuses new ones
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, service.Drain(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, service.RunMonitoring(), ErrShuttingDown)
+
+	require.NoError(t, service.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-runErr, ErrRunInterrupted)
+}
+
+func TestService_catchUpWindow(t *testing.T) {
+	service := &Service{config: &config.Config{WatermarkMaxWindow: 72 * time.Hour}}
+	started := time.Now().Add(-24 * time.Hour)
+
+	window := service.catchUpWindow(24*time.Hour, []InterruptedRun{{StartedAt: started, Window: "24h0m0s"}})
+	assert.Equal(t, 48*time.Hour, window)
+
+	window = service.catchUpWindow(24*time.Hour, []InterruptedRun{{StartedAt: started.Add(-7 * 24 * time.Hour), Window: "24h0m0s"}})
+	assert.Equal(t, 72*time.Hour, window, "catch-up is bounded by the maximum window")
+
+	assert.Equal(t, 24*time.Hour, service.catchUpWindow(24*time.Hour, []InterruptedRun{{Window: "bad"}}))
+}
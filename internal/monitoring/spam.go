@@ -0,0 +1,205 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// spamThreshold is the heuristic score at which a mention is tagged as spam without asking the LLM
+	spamThreshold = 0.6
+	// spamMinDuplicateLength is the shortest normalized content compared for duplicates, so
+	// short replies such as "thanks, this worked" are not mistaken for copy-paste spam
+	spamMinDuplicateLength = 80
+	// spamMaxLinks is the number of links at which content looks like a link farm
+	spamMaxLinks = 5
+	// spamAuthorMaxPosts is the number of mentions an author can post in one run before it looks automated
+	spamAuthorMaxPosts = 5
+	// spamLLMTimeout bounds a single LLM spam classification
+	spamLLMTimeout = 15 * time.Second
+)
+
+// Weights of the spam signals, summed into the heuristic score
+const (
+	spamWeightDuplicate = 0.6
+	spamWeightLinkFarm  = 0.4
+	spamWeightShortener = 0.3
+	spamWeightFrequency = 0.4
+)
+
+// linkShorteners hide link destinations and are favoured by link farms
+var linkShorteners = []string{
+	"bit.ly", "tinyurl.com", "goo.gl", "ow.ly", "is.gd", "buff.ly", "cutt.ly", "rebrand.ly", "shorturl.at",
+}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+
+// spamDetector scores mentions for spam and bot signals. It remembers content and authors
+// across the batches of one run, so copies and prolific authors are caught across sources.
+// It is not safe for concurrent use.
+type spamDetector struct {
+	copies map[[sha256.Size]byte]*contentCopies
+	posts  map[string]int // mentions per source and author
+}
+
+// contentCopies records who posted a piece of content, and who posted it first
+type contentCopies struct {
+	authors    map[string]bool
+	original   string // Author of the earliest copy, who isn't flagged for the copies of others
+	originalAt time.Time
+}
+
+func newSpamDetector() *spamDetector {
+	return &spamDetector{
+		copies: make(map[[sha256.Size]byte]*contentCopies),
+		posts:  make(map[string]int),
+	}
+}
+
+// evaluate records a batch and returns the verdict for each of its mentions, in order.
+// Mentions with no spam signals get a nil verdict.
+func (d *spamDetector) evaluate(mentions []models.Mention) []*models.SpamVerdict {
+	// Record the whole batch first so copies within it flag each other
+	fingerprints := make([]*[sha256.Size]byte, len(mentions))
+	for i, mention := range mentions {
+		author := spamAuthorKey(mention)
+		if author == "" {
+			continue
+		}
+		d.posts[author]++
+
+		if content := normalizeSpamContent(mention.Content); len(content) >= spamMinDuplicateLength {
+			fingerprint := sha256.Sum256([]byte(content))
+			fingerprints[i] = &fingerprint
+			copies := d.copies[fingerprint]
+			if copies == nil {
+				copies = &contentCopies{authors: make(map[string]bool)}
+				d.copies[fingerprint] = copies
+			}
+			copies.authors[author] = true
+			if copies.original == "" || (!mention.CreatedAt.IsZero() && (copies.originalAt.IsZero() || mention.CreatedAt.Before(copies.originalAt))) {
+				copies.original, copies.originalAt = author, mention.CreatedAt
+			}
+		}
+	}
+
+	verdicts := make([]*models.SpamVerdict, len(mentions))
+	for i, mention := range mentions {
+		var score float64
+		var signals []string
+
+		if fingerprints[i] != nil {
+			copies := d.copies[*fingerprints[i]]
+			if authors := len(copies.authors); authors > 1 && copies.original != spamAuthorKey(mention) {
+				score += spamWeightDuplicate
+				signals = append(signals, fmt.Sprintf("duplicate content from %d authors", authors))
+			}
+		}
+
+		links := linkPattern.FindAllString(mention.Content, -1)
+		if len(links) >= spamMaxLinks {
+			score += spamWeightLinkFarm
+			signals = append(signals, fmt.Sprintf("link farm: %d links", len(links)))
+		}
+		if shortener := findLinkShortener(links); shortener != "" {
+			score += spamWeightShortener
+			signals = append(signals, "link shortener "+shortener)
+		}
+
+		if author := spamAuthorKey(mention); author != "" && d.posts[author] > spamAuthorMaxPosts {
+			score += spamWeightFrequency
+			signals = append(signals, fmt.Sprintf("%d posts by %s this run", d.posts[author], mention.Author))
+		}
+
+		if len(signals) == 0 {
+			continue
+		}
+		score = math.Min(score, 1)
+		verdicts[i] = &models.SpamVerdict{
+			Spam:    score >= spamThreshold,
+			Score:   math.Round(score*100) / 100,
+			Signals: signals,
+		}
+	}
+	return verdicts
+}
+
+// filterSpam tags likely spam and bot mentions and separates them from the rest. Heuristics
+// decide the clear cases; ambiguous ones go to the LLM when LLM spam detection is enabled.
+func (s *Service) filterSpam(ctx context.Context, detector *spamDetector, mentions []models.Mention) (kept, spam []models.Mention) {
+	for i, verdict := range detector.evaluate(mentions) {
+		mention := mentions[i]
+		if verdict != nil && !verdict.Spam && s.llm != nil && s.config.EnableLLMSpamDetection && paidEnrichment(ctx) {
+			if isSpam, err := s.classifySpamWithLLM(ctx, mention); err != nil {
+				logrus.Debugf("LLM spam detection failed for %s, using heuristics: %v", mention.ID, err)
+			} else {
+				verdict.Spam = isSpam
+				verdict.LLM = true
+			}
+		}
+		mention.Spam = verdict
+
+		if verdict != nil && verdict.Spam {
+			spam = append(spam, mention)
+		} else {
+			kept = append(kept, mention)
+		}
+	}
+
+	if len(spam) > 0 {
+		logrus.Infof("Excluded %d likely spam or bot mentions", len(spam))
+	}
+	return kept, spam
+}
+
+func (s *Service) classifySpamWithLLM(ctx context.Context, mention models.Mention) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, spamLLMTimeout)
+	defer cancel()
+
+	content := truncateRunes(mention.Content, llmMaxInput)
+
+	return s.llm.YesNo(ctx,
+		"You review community posts collected while monitoring mentions of Azure Kubernetes Service. Decide whether the post is spam, advertising, SEO link bait or automated bot content rather than a genuine post by a person.",
+		"Source: "+mention.Source+"\nAuthor: "+mention.Author+"\nTitle: "+mention.Title+"\n\n"+content)
+}
+
+// spamAuthorKey identifies an author within their platform, or returns "" for anonymous mentions
+func spamAuthorKey(mention models.Mention) string {
+	author := strings.ToLower(strings.TrimSpace(mention.Author))
+	if author == "" || author == "[deleted]" {
+		return ""
+	}
+	return mention.Source + ":" + author
+}
+
+// normalizeSpamContent drops links, case and spacing so lightly edited copies compare equal
+func normalizeSpamContent(content string) string {
+	content = linkPattern.ReplaceAllString(strings.ToLower(content), "")
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// findLinkShortener returns the first link shortener domain among the links, or ""
+func findLinkShortener(links []string) string {
+	for _, link := range links {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+		for _, shortener := range linkShorteners {
+			if host == shortener {
+				return shortener
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const copiedPost = "Get cheap Azure Kubernetes Service certification dumps today, guaranteed pass on the first try with our practice questions"
+
+func TestSpamDetector_evaluate(t *testing.T) {
+	detector := newSpamDetector()
+	links := strings.Repeat("https://example.com/aks ", spamMaxLinks)
+	now := time.Now()
+
+	verdicts := detector.evaluate([]models.Mention{
+		{ID: "1", Source: "reddit", Author: "alice", Content: "Our AKS upgrade to 1.30 went smoothly after draining the node pools one at a time."},
+		{ID: "2", Source: "reddit", Author: "bob", Content: copiedPost, CreatedAt: now.Add(-time.Hour)},
+		{ID: "3", Source: "reddit", Author: "carol", Content: strings.ToUpper(copiedPost) + " https://example.com/offer", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "4", Source: "reddit", Author: "dave", Content: "AKS resources: " + links},
+		{ID: "5", Source: "reddit", Author: "erin", Content: "AKS guide " + links + "https://bit.ly/aks"},
+	})
+
+	assert.Nil(t, verdicts[0])
+
+	// Copies of another author's post are flagged, ignoring case and links; the earliest
+	// post is the original and isn't
+	require.NotNil(t, verdicts[1])
+	assert.True(t, verdicts[1].Spam)
+	assert.Equal(t, []string{"duplicate content from 2 authors"}, verdicts[1].Signals)
+	assert.Nil(t, verdicts[2])
+
+	// Links alone are ambiguous; a shortener on top tips the score over
+	require.NotNil(t, verdicts[3])
+	assert.False(t, verdicts[3].Spam)
+	assert.Equal(t, 0.4, verdicts[3].Score)
+	assert.Equal(t, []string{"link farm: 5 links"}, verdicts[3].Signals)
+	assert.True(t, verdicts[4].Spam)
+	assert.Equal(t, 0.7, verdicts[4].Score)
+	assert.Contains(t, verdicts[4].Signals, "link shortener bit.ly")
+
+	// A copy in a later batch is still caught, and one author reposting is not a duplicate
+	later := detector.evaluate([]models.Mention{
+		{ID: "6", Source: "reddit", Author: "frank", Content: copiedPost, CreatedAt: now},
+		{ID: "7", Source: "reddit", Author: "alice", Content: "Our AKS upgrade to 1.30 went smoothly after draining the node pools one at a time."},
+	})
+	assert.Equal(t, []string{"duplicate content from 3 authors"}, later[0].Signals)
+	assert.Nil(t, later[1])
+}
+
+func TestSpamDetector_postingFrequency(t *testing.T) {
+	detector := newSpamDetector()
+
+	var mentions []models.Mention
+	for i := 0; i <= spamAuthorMaxPosts; i++ {
+		mentions = append(mentions, models.Mention{ID: fmt.Sprint(i), Source: "twitter", Author: "aks_news_bot", Content: fmt.Sprintf("AKS update %d", i)})
+	}
+	mentions = append(mentions, models.Mention{ID: "other", Source: "reddit", Author: "aks_news_bot", Content: "AKS update"})
+
+	verdicts := detector.evaluate(mentions)
+	require.NotNil(t, verdicts[0])
+	assert.Equal(t, []string{"6 posts by aks_news_bot this run"}, verdicts[0].Signals)
+	assert.False(t, verdicts[0].Spam, "frequency alone is left to the LLM")
+
+	// Authors are counted per platform
+	assert.Nil(t, verdicts[len(verdicts)-1])
+}
+
+func TestService_runPipeline_excludesSpam(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{
+		config: &config.Config{
+			EnableSpamDetection:  true,
+			EnableRejectionAudit: true,
+			SourceConcurrency:    1,
+			SourceTimeout:        time.Second,
+		},
+		storage: storage,
+	}
+	service.sources = []sources.Source{
+		&stubSource{name: "reddit", mentions: []models.Mention{
+			{ID: "reddit_1", Source: "reddit", Author: "alice", Title: "AKS upgrade", Content: "Azure Kubernetes Service upgrade went fine"},
+			{ID: "reddit_2", Source: "reddit", Author: "bob", Title: "AKS dumps", Content: copiedPost},
+		}},
+		&stubSource{name: "hackernews", mentions: []models.Mention{
+			{ID: "hackernews_3", Source: "hackernews", Author: "carol", Title: "AKS dumps", Content: copiedPost},
+		}},
+	}
+
+	runID := time.Now().Format(runIDLayout)
+	result := service.runPipeline(context.Background(), runID, []string{"aks"}, fixedWindow(time.Hour))
+
+	// The original is reported, the copy from another author in a later source is not
+	var ids []string
+	for _, mention := range result.mentions {
+		ids = append(ids, mention.ID)
+	}
+	assert.ElementsMatch(t, []string{"reddit_1", "reddit_2"}, ids)
+
+	var stored []models.RejectedMention
+	require.NoError(t, json.Unmarshal(storage.Blobs()["rejected/"+runID+"-hackernews.json"], &stored))
+	require.Len(t, stored, 1)
+	require.NotNil(t, stored[0].Spam)
+	assert.True(t, stored[0].Spam.Spam)
+	assert.Equal(t, []string{"duplicate content from 2 authors"}, stored[0].Spam.Signals)
+}
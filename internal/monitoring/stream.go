@@ -0,0 +1,81 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultStreamBatchWindow groups streamed tweets so a burst becomes one urgent notification
+const defaultStreamBatchWindow = time.Minute
+
+// RunTwitterStream consumes the X filtered stream for the urgent keywords until ctx is cancelled,
+// feeding batches of tweets into the urgent pipeline. While it is connected, urgent checks stop
+// polling Twitter search; they poll it again while the stream reconnects.
+func (s *Service) RunTwitterStream(ctx context.Context) error {
+	if s.config.TwitterBearerToken == "" {
+		return fmt.Errorf("X filtered stream requires TWITTER_BEARER_TOKEN")
+	}
+
+	stream := sources.NewTwitterStream(s.config.TwitterBearerToken).
+		WithQueries(sources.NewQueryBuilder(s.config.KeywordQueries)).
+		WithConnectionHandler(s.twitterStreaming.Store)
+	if err := stream.SyncRules(ctx, s.config.UrgentKeywordList()); err != nil {
+		return fmt.Errorf("failed to sync X stream rules: %w", err)
+	}
+
+	defer s.twitterStreaming.Store(false)
+
+	window := s.config.TwitterStreamBatchWindow
+	if window <= 0 {
+		window = defaultStreamBatchWindow
+	}
+
+	var (
+		mu      sync.Mutex
+		pending []models.Mention
+	)
+	flush := func() {
+		mu.Lock()
+		batch := pending
+		pending = nil
+		mu.Unlock()
+
+		if len(batch) == 0 {
+			return
+		}
+		logrus.Infof("Checking %d streamed tweets for urgent mentions", len(batch))
+		if _, err := s.notifyUrgent(ctx, batch, "real-time X stream"); err != nil {
+			logrus.Errorf("Failed to process streamed tweets: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				flush()
+			case <-done:
+				flush()
+				return
+			}
+		}
+	}()
+
+	logrus.Infof("Starting X filtered stream for %d keywords (batch window %v)", len(s.config.UrgentKeywordList()), window)
+	err := stream.Run(ctx, func(mention models.Mention) {
+		mu.Lock()
+		pending = append(pending, mention)
+		mu.Unlock()
+	})
+	close(done)
+	return err
+}
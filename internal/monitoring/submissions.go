@@ -0,0 +1,209 @@
+package monitoring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+)
+
+const (
+	// submissionsBlob holds the mentions submitted through POST /api/mentions, oldest first
+	submissionsBlob = "submissions/mentions.json"
+	// submissionRetention is how long submissions are kept for runs to collect
+	submissionRetention = 30 * 24 * time.Hour
+
+	maxSubmissionsPerRequest = 100
+	maxSubmissionTitle       = 300   // Characters
+	maxSubmissionContent     = 10000 // Characters
+	maxSubmissionOrigin      = 64    // Characters
+)
+
+// ErrInvalidSubmission is returned for a submitted mention that can't be queued
+var ErrInvalidSubmission = errors.New("invalid submission")
+
+// MentionSubmission is a mention a person or another system submits, e.g. from a support
+// ticket, a field report or a conference feedback form
+type MentionSubmission struct {
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	URL      string   `json:"url,omitempty"`
+	Author   string   `json:"author,omitempty"`
+	Origin   string   `json:"origin,omitempty"`   // Where it came from, e.g. "support-ticket"; shown as its platform
+	Keywords []string `json:"keywords,omitempty"` // Configured keywords it is about; matched in its text when empty
+}
+
+// SubmissionResult lists the mentions a submission queued for the next run
+type SubmissionResult struct {
+	Queued     []models.Mention `json:"queued"`
+	Duplicates []string         `json:"duplicates,omitempty"` // IDs of mentions submitted before, left as they were
+}
+
+// SubmitMentions queues submitted mentions for the next monitoring run, which filters,
+// enriches, stores and reports them like the mentions of any source. Each needs a title or
+// content about one of the configured keywords. A mention with the URL, or without one the
+// title and content, of an earlier submission is a duplicate and not queued again.
+func (s *Service) SubmitMentions(submissions []MentionSubmission) (*SubmissionResult, error) {
+	if len(submissions) == 0 {
+		return nil, fmt.Errorf("%w: no mentions submitted", ErrInvalidSubmission)
+	}
+	if len(submissions) > maxSubmissionsPerRequest {
+		return nil, fmt.Errorf("%w: at most %d mentions can be submitted at once", ErrInvalidSubmission, maxSubmissionsPerRequest)
+	}
+
+	now := time.Now().UTC()
+	mentions := make([]models.Mention, 0, len(submissions))
+	for i, submission := range submissions {
+		mention, err := s.submittedMention(submission, now)
+		if err != nil {
+			return nil, fmt.Errorf("%w: mention %d: %v", ErrInvalidSubmission, i+1, err)
+		}
+		mentions = append(mentions, mention)
+	}
+
+	s.submissionsMu.Lock()
+	defer s.submissionsMu.Unlock()
+
+	queued, err := s.loadSubmissions()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-submissionRetention)
+	kept := queued[:0]
+	seen := make(map[string]bool)
+	for _, mention := range queued {
+		if mention.CreatedAt.After(cutoff) {
+			kept = append(kept, mention)
+			seen[mention.ID] = true
+		}
+	}
+
+	result := &SubmissionResult{Queued: []models.Mention{}}
+	for _, mention := range mentions {
+		if seen[mention.ID] {
+			result.Duplicates = append(result.Duplicates, mention.ID)
+			continue
+		}
+		seen[mention.ID] = true
+		kept = append(kept, mention)
+		result.Queued = append(result.Queued, mention)
+	}
+
+	if len(result.Queued) > 0 {
+		data, err := json.Marshal(kept)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal submissions: %w", err)
+		}
+		if err := s.storage.Store(submissionsBlob, data); err != nil {
+			return nil, fmt.Errorf("failed to store submissions: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// submittedMention checks a submission and turns it into a mention of the submitted source
+func (s *Service) submittedMention(submission MentionSubmission, now time.Time) (models.Mention, error) {
+	title := strings.TrimSpace(submission.Title)
+	content := strings.TrimSpace(submission.Content)
+	origin := strings.TrimSpace(submission.Origin)
+	switch {
+	case title == "" && content == "":
+		return models.Mention{}, errors.New("title or content is required")
+	case utf8.RuneCountInString(title) > maxSubmissionTitle:
+		return models.Mention{}, fmt.Errorf("title is longer than %d characters", maxSubmissionTitle)
+	case utf8.RuneCountInString(content) > maxSubmissionContent:
+		return models.Mention{}, fmt.Errorf("content is longer than %d characters", maxSubmissionContent)
+	case utf8.RuneCountInString(origin) > maxSubmissionOrigin:
+		return models.Mention{}, fmt.Errorf("origin is longer than %d characters", maxSubmissionOrigin)
+	}
+
+	link := strings.TrimSpace(submission.URL)
+	if link != "" {
+		parsed, err := url.Parse(link)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return models.Mention{}, fmt.Errorf("url %q is not an http or https URL", link)
+		}
+	}
+
+	mention := models.Mention{
+		Source:    sources.SubmittedSourceName,
+		Platform:  origin,
+		Title:     title,
+		Content:   content,
+		Author:    strings.TrimSpace(submission.Author),
+		URL:       link,
+		CreatedAt: now,
+	}
+	if mention.Platform == "" {
+		mention.Platform = "Submitted"
+	}
+
+	// Keywords the submitter named must be configured; otherwise they are matched in the text
+	if len(submission.Keywords) > 0 {
+		mention.Keywords = submission.Keywords
+	}
+	mention.Keywords = sources.MatchKeywords(mention, s.config.Keywords)
+	if len(mention.Keywords) == 0 {
+		if len(submission.Keywords) > 0 {
+			return models.Mention{}, fmt.Errorf("none of the keywords %q is configured", strings.Join(submission.Keywords, ", "))
+		}
+		return models.Mention{}, errors.New("title and content mention none of the configured keywords; name them in keywords")
+	}
+
+	identity := strings.ToLower(link)
+	if identity == "" {
+		identity = strings.ToLower(title + "\n" + content)
+	}
+	sum := sha256.Sum256([]byte(identity))
+	mention.ID = "submitted_" + hex.EncodeToString(sum[:8])
+	return mention, nil
+}
+
+// submissionStore lets the submitted source read the queued submissions
+type submissionStore struct {
+	service *Service
+}
+
+// Submissions returns the mentions submitted since the given time, oldest first
+func (s submissionStore) Submissions(since time.Time) ([]models.Mention, error) {
+	s.service.submissionsMu.Lock()
+	queued, err := s.service.loadSubmissions()
+	s.service.submissionsMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var mentions []models.Mention
+	for _, mention := range queued {
+		if mention.CreatedAt.After(since) {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions, nil
+}
+
+// loadSubmissions reads the queued submissions, oldest first; callers must hold s.submissionsMu
+func (s *Service) loadSubmissions() ([]models.Mention, error) {
+	data, found, err := storage.RetrieveIfExists(s.storage, submissionsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve submissions: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var mentions []models.Mention
+	if err := json.Unmarshal(data, &mentions); err != nil {
+		return nil, fmt.Errorf("failed to parse submissions: %w", err)
+	}
+	return mentions, nil
+}
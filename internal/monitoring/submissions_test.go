@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SubmitMentions(t *testing.T) {
+	cfg := &config.Config{Keywords: []string{"AKS", "KAITO"}}
+	service := &Service{config: cfg, storage: testutil.NewMemoryStorage()}
+
+	result, err := service.SubmitMentions([]MentionSubmission{
+		{Title: "Customer escalation", Content: "Node pool upgrades on AKS stall at 50%", Origin: "support-ticket", Author: " alice "},
+		{Content: "Booth visitors asked about GPU inference", URL: "https://example.com/feedback/42", Keywords: []string{"kaito"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Queued, 2)
+	assert.Empty(t, result.Duplicates)
+	assert.Equal(t, sources.SubmittedSourceName, result.Queued[0].Source)
+	assert.Equal(t, "support-ticket", result.Queued[0].Platform)
+	assert.Equal(t, "alice", result.Queued[0].Author)
+	assert.Equal(t, []string{"AKS"}, result.Queued[0].Keywords)
+	assert.Equal(t, "Submitted", result.Queued[1].Platform)
+	assert.Equal(t, []string{"KAITO"}, result.Queued[1].Keywords, "named keywords are matched case-insensitively")
+
+	// Resubmitting the same URL is a duplicate
+	result, err = service.SubmitMentions([]MentionSubmission{
+		{Content: "Same feedback, KAITO", URL: "https://EXAMPLE.com/feedback/42"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Queued)
+	assert.Len(t, result.Duplicates, 1)
+
+	for name, submission := range map[string]MentionSubmission{
+		"empty":           {Origin: "support-ticket"},
+		"no keyword":      {Content: "Nothing relevant here"},
+		"unknown keyword": {Content: "Nothing relevant here", Keywords: []string{"EKS"}},
+		"relative URL":    {Content: "AKS feedback", URL: "/feedback/42"},
+		"non-http URL":    {Content: "AKS feedback", URL: "ftp://example.com/feedback"},
+		"origin too long": {Content: "AKS feedback", Origin: string(make([]byte, maxSubmissionOrigin+1))},
+	} {
+		_, err := service.SubmitMentions([]MentionSubmission{submission})
+		assert.ErrorIs(t, err, ErrInvalidSubmission, name)
+	}
+	_, err = service.SubmitMentions(nil)
+	assert.ErrorIs(t, err, ErrInvalidSubmission)
+
+	// The submitted source collects the submissions within a run's window that match its keywords
+	source := sources.NewSubmittedSource(submissionStore{service: service})
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mentions, 1)
+	assert.Equal(t, "Customer escalation", mentions[0].Title)
+
+	mentions, err = source.FetchMentions(context.Background(), []string{"AKS", "KAITO"}, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, mentions, 2)
+
+	assert.False(t, sources.NewSubmittedSource(nil).IsEnabled())
+}
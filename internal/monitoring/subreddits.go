@@ -0,0 +1,209 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// subredditsBlob holds how many relevant mentions each subreddit has produced
+const subredditsBlob = "reddit/subreddits.json"
+
+// Discovery defaults used when the configuration leaves them unset
+const (
+	defaultSubredditDiscoveryMax         = 10
+	defaultSubredditDiscoveryMinMentions = 3
+)
+
+// subredditDiscoveryWindow is how long relevant mentions count towards promoting a subreddit,
+// so subreddits that stopped discussing the keywords drop out of the rotation
+const subredditDiscoveryWindow = 30 * 24 * time.Hour
+
+// SubredditStats records the relevant mentions a subreddit has yielded within the discovery
+// window, counted per UTC day
+type SubredditStats struct {
+	Name             string         `json:"name"`
+	RelevantMentions int            `json:"relevant_mentions"`
+	Days             map[string]int `json:"days,omitempty"`
+	FirstSeen        time.Time      `json:"first_seen"`
+	LastSeen         time.Time      `json:"last_seen"`
+}
+
+// expire drops the days that left the discovery window and recounts the relevant mentions
+func (stat *SubredditStats) expire(now time.Time) {
+	if stat.Days == nil {
+		// Stats recorded before daily counts are attributed to the day last seen
+		stat.Days = map[string]int{}
+		if stat.RelevantMentions > 0 {
+			stat.Days[stat.LastSeen.UTC().Format("2006-01-02")] = stat.RelevantMentions
+		}
+	}
+
+	oldest := now.UTC().Add(-subredditDiscoveryWindow).Format("2006-01-02")
+	stat.RelevantMentions = 0
+	for day, count := range stat.Days {
+		if day < oldest {
+			delete(stat.Days, day)
+			continue
+		}
+		stat.RelevantMentions += count
+	}
+}
+
+// discoveredSubreddits returns the subreddits promoted into the Reddit search rotation: the
+// ones with the most relevant mentions, at least the configured minimum, up to the cap and
+// excluding configured subreddits
+func (s *Service) discoveredSubreddits() []string {
+	if s.storage == nil {
+		return nil
+	}
+
+	s.subredditsMu.Lock()
+	stats, err := s.loadSubredditStats()
+	s.subredditsMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load discovered subreddits: %v", err)
+		return nil
+	}
+
+	return promoteSubreddits(stats, s.config.RedditExcludedSubreddits, s.subredditDiscoveryMinMentions(), s.subredditDiscoveryMax(), time.Now())
+}
+
+func promoteSubreddits(stats map[string]*SubredditStats, excluded []string, minMentions, max int, now time.Time) []string {
+	skip := make(map[string]bool)
+	for _, subreddit := range excluded {
+		skip[normalizeSubreddit(subreddit)] = true
+	}
+
+	var candidates []*SubredditStats
+	for key, stat := range stats {
+		stat.expire(now)
+		if !skip[key] && stat.RelevantMentions >= minMentions {
+			candidates = append(candidates, stat)
+		}
+	}
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].RelevantMentions != candidates[b].RelevantMentions {
+			return candidates[a].RelevantMentions > candidates[b].RelevantMentions
+		}
+		return candidates[a].Name < candidates[b].Name
+	})
+
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+	promoted := make([]string, 0, len(candidates))
+	for _, stat := range candidates {
+		promoted = append(promoted, stat.Name)
+	}
+	return promoted
+}
+
+// recordSubreddits counts relevant Reddit mentions per subreddit and day for discovery,
+// forgetting subreddits without mentions in the discovery window
+func (s *Service) recordSubreddits(mentions []models.Mention) {
+	if !s.config.RedditDiscovery || s.storage == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	for _, mention := range mentions {
+		if mention.Source != "reddit" || mention.Channel == "" {
+			continue
+		}
+		key := normalizeSubreddit(mention.Channel)
+		counts[key]++
+		if _, ok := names[key]; !ok {
+			names[key] = mention.Channel
+		}
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	s.subredditsMu.Lock()
+	defer s.subredditsMu.Unlock()
+
+	stats, err := s.loadSubredditStats()
+	if err != nil {
+		logrus.Warnf("Failed to load subreddit stats, not recording: %v", err)
+		return
+	}
+
+	for key, count := range counts {
+		stat, ok := stats[key]
+		if !ok {
+			stat = &SubredditStats{Name: names[key], Days: map[string]int{}, FirstSeen: now}
+			stats[key] = stat
+		}
+		stat.expire(now)
+		stat.Days[now.Format("2006-01-02")] += count
+		stat.LastSeen = now
+	}
+	for key, stat := range stats {
+		stat.expire(now)
+		if stat.RelevantMentions == 0 {
+			delete(stats, key)
+		}
+	}
+
+	if err := s.saveSubredditStats(stats); err != nil {
+		logrus.Warnf("Failed to save subreddit stats: %v", err)
+	}
+}
+
+func normalizeSubreddit(name string) string {
+	name = strings.TrimSpace(strings.ToLower(name))
+	return strings.TrimPrefix(strings.TrimPrefix(name, "/"), "r/")
+}
+
+func (s *Service) subredditDiscoveryMax() int {
+	if s.config.RedditDiscoveryMax > 0 {
+		return s.config.RedditDiscoveryMax
+	}
+	return defaultSubredditDiscoveryMax
+}
+
+func (s *Service) subredditDiscoveryMinMentions() int {
+	if s.config.RedditDiscoveryMinMentions > 0 {
+		return s.config.RedditDiscoveryMinMentions
+	}
+	return defaultSubredditDiscoveryMinMentions
+}
+
+// loadSubredditStats reads the discovery stats keyed by lowercase name; callers must hold s.subredditsMu
+func (s *Service) loadSubredditStats() (map[string]*SubredditStats, error) {
+	stats := make(map[string]*SubredditStats)
+
+	data, found, err := storage.RetrieveIfExists(s.storage, subredditsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve subreddit stats: %w", err)
+	}
+	if !found {
+		return stats, nil
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse subreddit stats: %w", err)
+	}
+	return stats, nil
+}
+
+// saveSubredditStats persists the discovery stats; callers must hold s.subredditsMu
+func (s *Service) saveSubredditStats(stats map[string]*SubredditStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subreddit stats: %w", err)
+	}
+	if err := s.storage.Store(subredditsBlob, data); err != nil {
+		return fmt.Errorf("failed to store subreddit stats: %w", err)
+	}
+	return nil
+}
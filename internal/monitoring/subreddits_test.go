@@ -0,0 +1,74 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func redditMention(subreddit string) models.Mention {
+	return models.Mention{Source: "reddit", Channel: subreddit}
+}
+
+func TestService_recordSubreddits(t *testing.T) {
+	cfg := &config.Config{
+		RedditDiscovery:            true,
+		RedditDiscoveryMax:         2,
+		RedditDiscoveryMinMentions: 2,
+		RedditExcludedSubreddits:   []string{"r/Memes"},
+	}
+	service := &Service{config: cfg, storage: testutil.NewMemoryStorage()}
+
+	service.recordSubreddits([]models.Mention{
+		redditMention("homelab"), redditMention("homelab"), redditMention("HomeLab"),
+		redditMention("k8s"), redditMention("k8s"),
+		redditMention("memes"), redditMention("memes"), redditMention("memes"),
+		redditMention("selfhosted"),
+		{Source: "youtube", Channel: "homelab"},
+	})
+	assert.Equal(t, []string{"homelab", "k8s"}, service.discoveredSubreddits())
+
+	stats, err := service.loadSubredditStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats["homelab"].RelevantMentions, "counts are case-insensitive")
+	assert.Equal(t, 1, stats["selfhosted"].RelevantMentions)
+
+	// Counts accumulate across runs and the cap keeps the busiest subreddits
+	service.recordSubreddits([]models.Mention{redditMention("selfhosted"), redditMention("selfhosted"), redditMention("selfhosted")})
+	assert.Equal(t, []string{"selfhosted", "homelab"}, service.discoveredSubreddits())
+}
+
+func TestService_recordSubreddits_Window(t *testing.T) {
+	cfg := &config.Config{RedditDiscovery: true, RedditDiscoveryMinMentions: 2}
+	service := &Service{config: cfg, storage: testutil.NewMemoryStorage()}
+
+	old := time.Now().UTC().Add(-subredditDiscoveryWindow - 24*time.Hour)
+	require.NoError(t, service.saveSubredditStats(map[string]*SubredditStats{
+		"homelab":    {Name: "homelab", Days: map[string]int{old.Format("2006-01-02"): 50}, FirstSeen: old, LastSeen: old},
+		"selfhosted": {Name: "selfhosted", RelevantMentions: 40, FirstSeen: old, LastSeen: old},
+		"k8s":        {Name: "k8s", RelevantMentions: 1, FirstSeen: old, LastSeen: time.Now().UTC()},
+	}))
+	assert.Empty(t, service.discoveredSubreddits(), "mentions older than the window no longer count")
+
+	service.recordSubreddits([]models.Mention{redditMention("k8s"), redditMention("homelab")})
+	assert.Equal(t, []string{"k8s"}, service.discoveredSubreddits())
+
+	stats, err := service.loadSubredditStats()
+	require.NoError(t, err)
+	assert.NotContains(t, stats, "selfhosted", "subreddits without recent mentions are forgotten")
+	assert.Equal(t, 1, stats["homelab"].RelevantMentions)
+	assert.Equal(t, 2, stats["k8s"].RelevantMentions)
+}
+
+func TestService_recordSubreddits_Disabled(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{}, storage: storage}
+
+	service.recordSubreddits([]models.Mention{redditMention("homelab")})
+	assert.Empty(t, storage.Blobs())
+}
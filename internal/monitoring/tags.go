@@ -0,0 +1,354 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/reports"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// tagsBlob holds the tags curators have added to mentions, keyed by mention ID
+const tagsBlob = "tags/mentions.json"
+
+// Limits on curated tags
+const (
+	maxTagLength      = 40
+	maxTagsPerMention = 20
+	// MaxTagReportMentions caps the mentions gathered into a tag report
+	MaxTagReportMentions = 500
+)
+
+// Rendered tag report pages are cached, as the page is public and each render reads every
+// tagged mention's blob. Pages are rendered again after a tag change or once they are
+// tagReportCacheTTL old, so mentions stored by later runs show up.
+const (
+	tagReportCacheSize = 50
+	tagReportCacheTTL  = 5 * time.Minute
+)
+
+// tagReportPage is a rendered tag report and the tags version it was rendered from
+type tagReportPage struct {
+	page       []byte
+	version    uint64
+	renderedAt time.Time
+}
+
+var (
+	// ErrInvalidTag is returned for empty or overlong tags and tags with characters other
+	// than letters, digits, spaces, '-', '_' and '.'
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrTagNotFound is returned when removing a tag the mention does not have
+	ErrTagNotFound = errors.New("tag not found")
+)
+
+// MentionTags is the set of tags on a mention
+type MentionTags struct {
+	MentionID string   `json:"mention_id"`
+	Tags      []string `json:"tags"`
+}
+
+// GetMentionTags returns the tags on a stored mention
+func (s *Service) GetMentionTags(id string) (*MentionTags, error) {
+	if _, ok := s.searchIndex().Document(id); !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+
+	tags, err := s.loadTags()
+	if err != nil {
+		return nil, err
+	}
+	return &MentionTags{MentionID: id, Tags: nonNilTags(tags[id])}, nil
+}
+
+// AddMentionTags tags a stored mention. Tags are lowercased so "GB200" and "gb200" are the
+// same tag; tags the mention already has are ignored.
+func (s *Service) AddMentionTags(id string, add []string) (*MentionTags, error) {
+	var normalized []string
+	for _, tag := range add {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag is required", ErrInvalidTag)
+	}
+
+	return s.updateTags(id, func(current []string) ([]string, error) {
+		for _, tag := range normalized {
+			if !containsString(current, tag) {
+				current = append(current, tag)
+			}
+		}
+		if len(current) > maxTagsPerMention {
+			return nil, fmt.Errorf("%w: a mention can have at most %d tags", ErrInvalidTag, maxTagsPerMention)
+		}
+		return current, nil
+	})
+}
+
+// RemoveMentionTag removes a tag from a stored mention
+func (s *Service) RemoveMentionTag(id, tag string) (*MentionTags, error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateTags(id, func(current []string) ([]string, error) {
+		for i, existing := range current {
+			if existing == tag {
+				return append(current[:i], current[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q", ErrTagNotFound, tag)
+	})
+}
+
+// updateTags applies change to a stored mention's tags and persists the result
+func (s *Service) updateTags(id string, change func([]string) ([]string, error)) (*MentionTags, error) {
+	if _, ok := s.searchIndex().Document(id); !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMention, id)
+	}
+
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+
+	tags, err := s.loadTags()
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := change(append([]string(nil), tags[id]...))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(updated)
+	if len(updated) == 0 {
+		delete(tags, id)
+	} else {
+		tags[id] = updated
+	}
+
+	if err := s.saveTags(tags); err != nil {
+		return nil, err
+	}
+	s.tagsVersion.Add(1)
+	logrus.Infof("Mention %s tagged %v", id, updated)
+	return &MentionTags{MentionID: id, Tags: nonNilTags(updated)}, nil
+}
+
+// TagCounts returns how many mentions carry each tag
+func (s *Service) TagCounts() (map[string]int, error) {
+	s.tagsMu.Lock()
+	defer s.tagsMu.Unlock()
+
+	tags, err := s.loadTags()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, mentionTags := range tags {
+		for _, tag := range mentionTags {
+			counts[tag]++
+		}
+	}
+	return counts, nil
+}
+
+// TaggedSearchHit is a search result with the mention's tags
+type TaggedSearchHit struct {
+	storage.SearchHit
+	Tags []string `json:"tags,omitempty"`
+}
+
+// SearchTagged runs a full-text query over stored mentions, keeping only mentions with the
+// tag when one is given. Without a query, it lists the tagged mentions newest first.
+func (s *Service) SearchTagged(query, tag string, limit int) ([]TaggedSearchHit, error) {
+	result, err := s.QueryMentions(MentionQuery{Text: query, Tag: tag, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return result.Hits, nil
+}
+
+// TagReport builds a report from the stored mentions with a tag, newest first, so curated
+// topics can be reviewed and shared like a scheduled report
+func (s *Service) TagReport(tag string) (*models.Report, error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.queryMentions(MentionQuery{Tag: tag}, MaxTagReportMentions)
+	if err != nil {
+		return nil, err
+	}
+	hits := result.Hits
+
+	wanted := make(map[string]map[string]bool)
+	for _, hit := range hits {
+		if wanted[hit.Blob] == nil {
+			wanted[hit.Blob] = make(map[string]bool)
+		}
+		wanted[hit.Blob][hit.ID] = true
+	}
+	mentions, err := s.retrieveMentions(wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(mentions, func(a, b int) bool { return mentions[a].CreatedAt.After(mentions[b].CreatedAt) })
+	s.applyTags(mentions)
+
+	report := s.generateReport(mentions)
+	report.Period = "tag: " + tag
+	return report, nil
+}
+
+// TagReportPage returns the HTML page of the tag report, from the cache when it is current
+func (s *Service) TagReportPage(tag string) ([]byte, error) {
+	tag, err := normalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	version := s.tagsVersion.Load()
+	if cached, ok := s.tagPages.Get(tag); ok && cached.version == version && time.Since(cached.renderedAt) < tagReportCacheTTL {
+		return cached.page, nil
+	}
+
+	report, err := s.TagReport(tag)
+	if err != nil {
+		return nil, err
+	}
+	page, err := reports.RenderHTML(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the %s tag report: %w", tag, err)
+	}
+	s.tagPages.Add(tag, tagReportPage{page: page, version: version, renderedAt: time.Now()})
+	return page, nil
+}
+
+// retrieveMentions reads the mentions with the wanted IDs from each mentions blob, reading
+// every blob once; wanted maps blob names to mention IDs
+func (s *Service) retrieveMentions(wanted map[string]map[string]bool) ([]models.Mention, error) {
+	var mentions []models.Mention
+	for blob, ids := range wanted {
+		data, err := s.storage.Retrieve(blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s: %w", blob, err)
+		}
+		var stored []models.Mention
+		if err := json.Unmarshal(data, &stored); err != nil {
+			logrus.Warnf("Skipping unreadable mentions in %s: %v", blob, err)
+			continue
+		}
+		for _, mention := range stored {
+			if ids[mention.ID] {
+				mentions = append(mentions, mention)
+				delete(ids, mention.ID)
+			}
+		}
+	}
+	return mentions, nil
+}
+
+// applyTags sets the curated tags on mentions that have any. Failures are logged so they
+// never block a report.
+func (s *Service) applyTags(mentions []models.Mention) {
+	if s.storage == nil {
+		return
+	}
+
+	s.tagsMu.Lock()
+	tags, err := s.loadTags()
+	s.tagsMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load mention tags: %v", err)
+		return
+	}
+
+	for i := range mentions {
+		if mentionTags, ok := tags[mentions[i].ID]; ok {
+			mentions[i].Tags = mentionTags
+		}
+	}
+}
+
+// normalizeTag lowercases and trims a tag and checks it is usable in URLs and reports
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+	if tag == "" || len(tag) > maxTagLength {
+		return "", fmt.Errorf("%w %q: tags must be 1-%d characters", ErrInvalidTag, tag, maxTagLength)
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" -_.", r) {
+			return "", fmt.Errorf("%w %q: use letters, digits, spaces, '-', '_' or '.'", ErrInvalidTag, tag)
+		}
+	}
+	return tag, nil
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTags reads the curated tags keyed by mention ID; callers must hold s.tagsMu
+func (s *Service) loadTags() (map[string][]string, error) {
+	tags := make(map[string][]string)
+	if s.storage == nil {
+		return tags, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, tagsBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mention tags: %w", err)
+	}
+	if !found {
+		return tags, nil
+	}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse mention tags: %w", err)
+	}
+	return tags, nil
+}
+
+// saveTags persists the curated tags; callers must hold s.tagsMu
+func (s *Service) saveTags(tags map[string][]string) error {
+	if s.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mention tags: %w", err)
+	}
+	if err := s.storage.Store(tagsBlob, data); err != nil {
+		return fmt.Errorf("failed to store mention tags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package monitoring
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTagsService(t *testing.T) *Service {
+	t.Helper()
+	service := &Service{config: &config.Config{ReportSchedule: "daily"}, storage: testutil.NewMemoryStorage()}
+	now := time.Now()
+	require.NoError(t, service.storeMentions([]models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS pricing for GPU node pools", Sentiment: "negative", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS networking with Cilium", Sentiment: "positive", CreatedAt: now.Add(-time.Hour)},
+		{ID: "reddit_3", Source: "reddit", Title: "AKS pricing calculator", Sentiment: "neutral", CreatedAt: now},
+	}))
+	return service
+}
+
+func TestService_MentionTags(t *testing.T) {
+	service := newTagsService(t)
+
+	tags, err := service.AddMentionTags("reddit_1", []string{" Pricing ", "GB200", "pricing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gb200", "pricing"}, tags.Tags)
+
+	_, err = service.AddMentionTags("reddit_3", []string{"pricing"})
+	require.NoError(t, err)
+	_, err = service.AddMentionTags("reddit_2", []string{"networking"})
+	require.NoError(t, err)
+
+	counts, err := service.TagCounts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"gb200": 1, "pricing": 2, "networking": 1}, counts)
+
+	tags, err = service.RemoveMentionTag("reddit_1", "GB200")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pricing"}, tags.Tags)
+
+	tags, err = service.GetMentionTags("reddit_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pricing"}, tags.Tags)
+
+	_, err = service.RemoveMentionTag("reddit_1", "gb200")
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	_, err = service.AddMentionTags("reddit_1", []string{"a,b"})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = service.AddMentionTags("reddit_1", nil)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = service.AddMentionTags("missing", []string{"pricing"})
+	assert.ErrorIs(t, err, ErrUnknownMention)
+}
+
+func TestService_SearchTagged(t *testing.T) {
+	service := newTagsService(t)
+	_, err := service.AddMentionTags("reddit_1", []string{"pricing"})
+	require.NoError(t, err)
+	_, err = service.AddMentionTags("reddit_3", []string{"pricing", "calculator"})
+	require.NoError(t, err)
+
+	// A query is narrowed to the tag
+	hits, err := service.SearchTagged("aks", "Pricing", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	for _, hit := range hits {
+		assert.Contains(t, hit.Tags, "pricing")
+	}
+
+	// Without a query the tagged mentions are listed newest first
+	hits, err = service.SearchTagged("", "pricing", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "reddit_3", hits[0].ID)
+	assert.Equal(t, []string{"calculator", "pricing"}, hits[0].Tags)
+
+	// Without a tag, hits still carry their tags
+	hits, err = service.SearchTagged("calculator", "", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, []string{"calculator", "pricing"}, hits[0].Tags)
+}
+
+func TestService_TagReport(t *testing.T) {
+	service := newTagsService(t)
+	_, err := service.AddMentionTags("reddit_1", []string{"pricing"})
+	require.NoError(t, err)
+	_, err = service.AddMentionTags("reddit_3", []string{"pricing"})
+	require.NoError(t, err)
+
+	report, err := service.TagReport("Pricing")
+	require.NoError(t, err)
+	assert.Equal(t, "tag: pricing", report.Period)
+	assert.Equal(t, 2, report.TotalMentions)
+	require.Len(t, report.Mentions, 2)
+	assert.Equal(t, "reddit_3", report.Mentions[0].ID)
+	assert.Equal(t, []string{"pricing"}, report.Mentions[0].Tags)
+	assert.Equal(t, map[string]int{"negative": 1, "neutral": 1}, report.Summary["sentiment"])
+
+	report, err = service.TagReport("unused")
+	require.NoError(t, err)
+	assert.Zero(t, report.TotalMentions)
+}
+
+func TestService_TagReportPage(t *testing.T) {
+	service := newTagsService(t)
+	service.tagPages = cache.NewLRU[string, tagReportPage](tagReportCacheSize)
+	_, err := service.AddMentionTags("reddit_1", []string{"pricing"})
+	require.NoError(t, err)
+
+	page, err := service.TagReportPage("pricing")
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(page), "AKS pricing for GPU node pools"))
+
+	// Later requests are served from the cache
+	_, err = service.TagReportPage("Pricing")
+	require.NoError(t, err)
+	hits, _ := service.tagPages.Stats()
+	assert.Equal(t, 1, hits)
+
+	// Tag changes render the page again
+	_, err = service.AddMentionTags("reddit_3", []string{"pricing"})
+	require.NoError(t, err)
+	page, err = service.TagReportPage("pricing")
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(page), "AKS pricing calculator"))
+
+	_, err = service.TagReportPage("no/slashes")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+}
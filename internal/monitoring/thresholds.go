@@ -0,0 +1,141 @@
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// checkThresholds evaluates the configured alert thresholds against a run's mentions and
+// sends an alert for each one crossed. Failures are logged so they never block the report.
+func (s *Service) checkThresholds(mentions []models.Mention, window time.Duration) {
+	if len(s.config.AlertThresholds) == 0 || s.notificationService == nil {
+		return
+	}
+
+	for _, alert := range s.evaluateThresholds(mentions, window, time.Now()) {
+		logrus.Warnf("Alert threshold crossed: %s", alert.Title)
+		if err := s.notificationService.SendAlert(alert); err != nil {
+			logrus.Errorf("Failed to send threshold alert: %v", err)
+		}
+	}
+}
+
+// evaluateThresholds returns an alert for each threshold the mentions created within the
+// window cross. Daily limits are compared with the average per day over the window.
+func (s *Service) evaluateThresholds(mentions []models.Mention, window time.Duration, now time.Time) []*models.Alert {
+	cutoff := now.Add(-window)
+	var recent []models.Mention
+	for _, mention := range mentions {
+		// Watermarked sources can return older mentions after downtime; they would skew the rate
+		if !mention.CreatedAt.Before(cutoff) {
+			recent = append(recent, mention)
+		}
+	}
+
+	days := window.Hours() / 24
+	if days <= 0 {
+		days = 1
+	}
+
+	var alerts []*models.Alert
+	for _, threshold := range s.config.AlertThresholds {
+		group := s.groupMentions(recent, threshold.Group)
+
+		count := len(group)
+		if threshold.Metric != config.ThresholdMetricMentions {
+			count = 0
+			for _, mention := range group {
+				if mention.Sentiment == threshold.Metric {
+					count++
+				}
+			}
+		}
+
+		var value float64
+		var observed string
+		if threshold.Percent {
+			if len(group) == 0 || len(group) < s.config.AlertThresholdMinMentions {
+				continue
+			}
+			value = float64(count) * 100 / float64(len(group))
+			observed = fmt.Sprintf("%.0f%% of %d %s mentions were %s", value, len(group), thresholdGroupLabel(threshold.Group), threshold.Metric)
+		} else {
+			value = float64(count) / days
+			observed = fmt.Sprintf("%.1f %s a day", value, thresholdSubject(threshold))
+		}
+
+		if value <= threshold.Limit {
+			continue
+		}
+
+		limit := fmt.Sprintf("%g", threshold.Limit)
+		if threshold.Percent {
+			limit += "%"
+		} else {
+			limit += " a day"
+		}
+
+		alerts = append(alerts, &models.Alert{
+			ID:        fmt.Sprintf("threshold-%s-%s-%s", threshold.Group, threshold.Metric, now.UTC().Format("2006-01-02-15")),
+			Type:      "urgent",
+			Title:     fmt.Sprintf("Mention threshold crossed: %s above %s", thresholdSubject(threshold), limit),
+			Message:   fmt.Sprintf("%s over the last %s (threshold %s).", observed, windowLabel(window), threshold),
+			CreatedAt: now,
+		})
+	}
+	return alerts
+}
+
+// groupMentions keeps the mentions that matched a keyword of the given group
+func (s *Service) groupMentions(mentions []models.Mention, group string) []models.Mention {
+	if group == config.AllKeywordsGroup {
+		return mentions
+	}
+
+	keywords := make(map[string]bool)
+	for _, keyword := range s.config.KeywordGroups[group] {
+		keywords[strings.ToLower(keyword)] = true
+	}
+
+	var matched []models.Mention
+	for _, mention := range mentions {
+		for _, keyword := range mention.Keywords {
+			if keywords[strings.ToLower(keyword)] {
+				matched = append(matched, mention)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// thresholdSubject describes what a threshold counts, e.g. "negative kaito mentions"
+func thresholdSubject(threshold config.AlertThreshold) string {
+	if threshold.Metric == config.ThresholdMetricMentions {
+		return thresholdGroupLabel(threshold.Group) + " mentions"
+	}
+	return fmt.Sprintf("%s %s mentions", threshold.Metric, thresholdGroupLabel(threshold.Group))
+}
+
+// windowLabel describes a search window in whole days where possible, e.g. "7 days"
+func windowLabel(window time.Duration) string {
+	if window%(24*time.Hour) != 0 {
+		return window.String()
+	}
+	if days := int(window / (24 * time.Hour)); days != 1 {
+		return fmt.Sprintf("%d days", days)
+	}
+	return "day"
+}
+
+func thresholdGroupLabel(group string) string {
+	if group == config.AllKeywordsGroup {
+		return "keyword"
+	}
+	return group
+}
@@ -0,0 +1,68 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func thresholdMentions(n int, keyword, sentiment string, createdAt time.Time) []models.Mention {
+	mentions := make([]models.Mention, n)
+	for i := range mentions {
+		mentions[i] = models.Mention{Keywords: []string{keyword}, Sentiment: sentiment, CreatedAt: createdAt}
+	}
+	return mentions
+}
+
+func TestService_evaluateThresholds(t *testing.T) {
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		KeywordGroups: map[string][]string{"kaito": {"KAITO"}, "core": {"AKS"}},
+		AlertThresholds: []config.AlertThreshold{
+			{Group: "kaito", Metric: config.ThresholdMetricMentions, Limit: 20},
+			{Group: "core", Metric: config.ThresholdMetricNegative, Limit: 30, Percent: true},
+		},
+		AlertThresholdMinMentions: 10,
+	}
+	service := &Service{config: cfg}
+
+	// 42 KAITO mentions over two days is 21 a day
+	var mentions []models.Mention
+	mentions = append(mentions, thresholdMentions(42, "kaito", "neutral", now.Add(-time.Hour))...)
+	mentions = append(mentions, thresholdMentions(6, "AKS", "negative", now.Add(-time.Hour))...)
+	mentions = append(mentions, thresholdMentions(4, "AKS", "positive", now.Add(-time.Hour))...)
+
+	alerts := service.evaluateThresholds(mentions, 48*time.Hour, now)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "Mention threshold crossed: kaito mentions above 20 a day", alerts[0].Title)
+	assert.Equal(t, "21.0 kaito mentions a day over the last 2 days (threshold kaito:mentions>20).", alerts[0].Message)
+	assert.Equal(t, "threshold-kaito-mentions-2024-06-03-09", alerts[0].ID)
+	assert.Equal(t, "urgent", alerts[0].Type)
+	assert.Equal(t, "Mention threshold crossed: negative core mentions above 30%", alerts[1].Title)
+	assert.Equal(t, "60% of 10 core mentions were negative over the last 2 days (threshold core:negative>30%).", alerts[1].Message)
+
+	// Mentions older than the window don't count towards the rate
+	old := thresholdMentions(42, "KAITO", "neutral", now.Add(-72*time.Hour))
+	assert.Empty(t, service.evaluateThresholds(old, 48*time.Hour, now))
+
+	// Percentages need enough mentions to be meaningful
+	few := thresholdMentions(5, "AKS", "negative", now.Add(-time.Hour))
+	assert.Empty(t, service.evaluateThresholds(few, 24*time.Hour, now))
+}
+
+func TestService_evaluateThresholds_allGroup(t *testing.T) {
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	service := &Service{config: &config.Config{
+		AlertThresholds: []config.AlertThreshold{{Group: config.AllKeywordsGroup, Metric: config.ThresholdMetricMentions, Limit: 5}},
+	}}
+
+	mentions := append(thresholdMentions(3, "AKS", "neutral", now), thresholdMentions(3, "KAITO", "neutral", now)...)
+	alerts := service.evaluateThresholds(mentions, 24*time.Hour, now)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "Mention threshold crossed: keyword mentions above 5 a day", alerts[0].Title)
+	assert.Equal(t, "6.0 keyword mentions a day over the last day (threshold all:mentions>5).", alerts[0].Message)
+}
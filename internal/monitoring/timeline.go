@@ -0,0 +1,134 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Timeline bucket sizes. Weeks start on Monday; all buckets are in UTC.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// ErrInvalidBucket is returned for a timeline bucket size other than day, week or month, or
+// for a period split into more than MaxTimelineBuckets buckets
+var ErrInvalidBucket = errors.New("invalid timeline bucket")
+
+// MaxTimelineBuckets bounds the size of a timeline, e.g. about 2.7 years of daily buckets
+const MaxTimelineBuckets = 1000
+
+// TimelineBucket counts the mentions created in one bucket, by sentiment and by source
+type TimelineBucket struct {
+	Start    time.Time      `json:"start"`
+	Mentions int            `json:"mentions"`
+	Positive int            `json:"positive"`
+	Neutral  int            `json:"neutral"`
+	Negative int            `json:"negative"`
+	Sources  map[string]int `json:"sources,omitempty"`
+}
+
+// Timeline is the mention count per bucket since a point in time, oldest first. Every
+// bucket in the period is listed, including empty ones, so it can be charted as is.
+type Timeline struct {
+	Bucket  string           `json:"bucket"`
+	Since   time.Time        `json:"since"`
+	Buckets []TimelineBucket `json:"buckets"`
+}
+
+// MentionTimeline counts the stored mentions created since the given time per bucket. Counts
+// come from the mention index, so a mention is counted once however many runs found it.
+func (s *Service) MentionTimeline(bucket string, since time.Time) (*Timeline, error) {
+	if bucket != BucketDay && bucket != BucketWeek && bucket != BucketMonth {
+		return nil, fmt.Errorf("%w %q: use %q, %q or %q", ErrInvalidBucket, bucket, BucketDay, BucketWeek, BucketMonth)
+	}
+
+	now := time.Now().UTC()
+	since = since.UTC()
+	timeline := &Timeline{Bucket: bucket, Since: since}
+
+	positions := make(map[time.Time]int)
+	for start := bucketStart(since, bucket); !start.After(now); start = nextBucket(start, bucket) {
+		if len(timeline.Buckets) == MaxTimelineBuckets {
+			return nil, fmt.Errorf("%w: more than %d %s buckets since %s, use a larger bucket", ErrInvalidBucket, MaxTimelineBuckets, bucket, since.Format("2006-01-02"))
+		}
+		positions[start] = len(timeline.Buckets)
+		timeline.Buckets = append(timeline.Buckets, TimelineBucket{Start: start})
+	}
+
+	if s.index == nil || len(timeline.Buckets) == 0 {
+		return timeline, nil
+	}
+
+	// Skip the days before the first index file rather than looking each of them up
+	days, err := s.index.Days()
+	if err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return timeline, nil
+	}
+	from := since
+	if days[0].After(from) {
+		from = days[0]
+	}
+
+	entries, err := s.index.Range(from, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the mention index: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(since) {
+			continue
+		}
+		position, ok := positions[bucketStart(entry.CreatedAt, bucket)]
+		if !ok {
+			continue
+		}
+
+		counts := &timeline.Buckets[position]
+		counts.Mentions++
+		switch entry.Sentiment {
+		case "positive":
+			counts.Positive++
+		case "neutral":
+			counts.Neutral++
+		case "negative":
+			counts.Negative++
+		}
+		if counts.Sources == nil {
+			counts.Sources = make(map[string]int)
+		}
+		counts.Sources[entry.Source]++
+	}
+
+	return timeline, nil
+}
+
+// bucketStart returns the start of the bucket holding t
+func bucketStart(t time.Time, bucket string) time.Time {
+	day := t.UTC().Truncate(24 * time.Hour)
+	switch bucket {
+	case BucketWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case BucketMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextBucket returns the start of the bucket after the one starting at start
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,71 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_MentionTimeline(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := &Service{config: &config.Config{}, storage: store, index: storage.NewMentionIndex(store)}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	require.NoError(t, service.storeMentionBatch("run1", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", CreatedAt: yesterday.Add(time.Hour), Sentiment: "negative"},
+		{ID: "reddit_2", Source: "reddit", CreatedAt: today, Sentiment: "positive"},
+		{ID: "reddit_old", Source: "reddit", CreatedAt: today.AddDate(0, 0, -30), Sentiment: "neutral"},
+	}))
+	require.NoError(t, service.storeMentionBatch("run1", "hackernews", []models.Mention{
+		{ID: "hackernews_1", Source: "hackernews", CreatedAt: yesterday.Add(2 * time.Hour), Sentiment: "negative"},
+	}))
+	// Found again by a later run, counted once
+	require.NoError(t, service.storeMentionBatch("run2", "reddit", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", CreatedAt: yesterday.Add(time.Hour), Sentiment: "negative"},
+	}))
+
+	timeline, err := service.MentionTimeline(BucketDay, today.AddDate(0, 0, -6))
+	require.NoError(t, err)
+	require.Len(t, timeline.Buckets, 7, "empty days are listed too")
+	assert.Zero(t, timeline.Buckets[0].Mentions)
+
+	assert.Equal(t, yesterday, timeline.Buckets[5].Start)
+	assert.Equal(t, 2, timeline.Buckets[5].Mentions)
+	assert.Equal(t, 2, timeline.Buckets[5].Negative)
+	assert.Equal(t, map[string]int{"reddit": 1, "hackernews": 1}, timeline.Buckets[5].Sources)
+
+	assert.Equal(t, today, timeline.Buckets[6].Start)
+	assert.Equal(t, 1, timeline.Buckets[6].Positive)
+
+	monthly, err := service.MentionTimeline(BucketMonth, today.AddDate(0, 0, -40))
+	require.NoError(t, err)
+	total := 0
+	for _, bucket := range monthly.Buckets {
+		assert.Equal(t, 1, bucket.Start.Day())
+		total += bucket.Mentions
+	}
+	assert.Equal(t, 4, total)
+
+	_, err = service.MentionTimeline("hour", today)
+	assert.ErrorIs(t, err, ErrInvalidBucket)
+	_, err = service.MentionTimeline(BucketDay, today.AddDate(-5, 0, 0))
+	assert.ErrorIs(t, err, ErrInvalidBucket)
+}
+
+func TestBucketStart(t *testing.T) {
+	// Wednesday 12 June 2024
+	at := time.Date(2024, 6, 12, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), bucketStart(at, BucketDay))
+	assert.Equal(t, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), bucketStart(at, BucketWeek))
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), bucketStart(at, BucketMonth))
+
+	sunday := time.Date(2024, 6, 16, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), bucketStart(sunday, BucketWeek))
+}
@@ -0,0 +1,151 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	translationLLMTimeout = 30 * time.Second
+	// translationMaxInput caps the content sent for translation, in characters
+	translationMaxInput = 3000
+	// translationMaxTokens bounds the reply; CJK text translates to roughly as many tokens as characters
+	translationMaxTokens = 2000
+	// translationCacheSize bounds the translations remembered, so mentions fetched again skip the LLM
+	translationCacheSize = 2000
+	// cjkMinShare is the share of a text's letters that must be Chinese, Japanese or Korean
+	// for it to be translated; English posts quoting a term or two stay as they are
+	cjkMinShare = 0.2
+)
+
+// mentionTranslation is the LLM's English rendering of a mention
+type mentionTranslation struct {
+	Language string `json:"-"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+}
+
+// detectLanguage returns "ja", "zh" or "ko" for text mostly written in Japanese, Chinese or
+// Korean, or "" for anything else. Kana tells Japanese apart from Chinese, as both use Han.
+func detectLanguage(text string) string {
+	var letters, han, kana, hangul int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.IsLetter(r):
+			letters++
+		}
+	}
+
+	cjk := han + kana + hangul
+	// A Latin word counts as several letters against a single CJK character; weigh words instead
+	total := cjk + letters/5
+	if cjk == 0 || float64(cjk)/float64(total) < cjkMinShare {
+		return ""
+	}
+	switch {
+	case kana > 0:
+		return "ja"
+	case hangul > han:
+		return "ko"
+	default:
+		return "zh"
+	}
+}
+
+// translateMentions translates mentions written in Japanese, Chinese or Korean into English,
+// keeping the original text. At most *budget mentions are sent to the LLM per run; cached
+// translations don't count against it.
+func (s *Service) translateMentions(ctx context.Context, mentions []models.Mention, budget *int) {
+	if !s.config.EnableTranslation || s.llm == nil || !paidEnrichment(ctx) {
+		return
+	}
+
+	translated := 0
+	for i := range mentions {
+		mention := &mentions[i]
+		if mention.OriginalTitle != "" || mention.OriginalContent != "" {
+			continue
+		}
+		language := detectLanguage(mention.Title + " " + mention.Content)
+		if language == "" {
+			continue
+		}
+
+		translation, cached := s.translations.Get(mention.ID)
+		if !cached {
+			if *budget <= 0 {
+				logrus.Debugf("Translations capped at %d per run", s.config.TranslationMaxMentions)
+				continue
+			}
+			*budget--
+
+			var err error
+			translation, err = s.translateWithLLM(ctx, *mention)
+			if err != nil {
+				logrus.Warnf("Failed to translate %s: %v", mention.ID, err)
+				continue
+			}
+			translation.Language = language
+			s.translations.Add(mention.ID, translation)
+		}
+
+		mention.Language = translation.Language
+		mention.OriginalTitle = mention.Title
+		mention.OriginalContent = mention.Content
+		if translation.Title != "" {
+			mention.Title = translation.Title
+		}
+		if translation.Content != "" {
+			mention.Content = translation.Content
+		}
+		translated++
+	}
+
+	if translated > 0 {
+		logrus.Infof("Translated %d mentions into English", translated)
+	}
+}
+
+func (s *Service) translateWithLLM(ctx context.Context, mention models.Mention) (mentionTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, translationLLMTimeout)
+	defer cancel()
+
+	content := truncateRunes(mention.Content, translationMaxInput)
+
+	reply, err := s.llm.Complete(ctx,
+		"You translate community posts about Azure Kubernetes Service into English for a team that monitors them. "+
+			"Keep product names, commands and code as they are. "+
+			`Reply with only a JSON object: {"title": "<translated title>", "content": "<translated content>"}.`,
+		"Title: "+mention.Title+"\n\n"+content,
+		translationMaxTokens)
+	if err != nil {
+		return mentionTranslation{}, err
+	}
+
+	// Models sometimes wrap JSON in a code fence
+	reply = strings.TrimSpace(reply)
+	reply = strings.TrimPrefix(reply, "```json")
+	reply = strings.Trim(reply, "`\n ")
+
+	var translation mentionTranslation
+	if err := json.Unmarshal([]byte(reply), &translation); err != nil {
+		return mentionTranslation{}, fmt.Errorf("failed to parse translation: %w", err)
+	}
+	if translation.Title == "" && translation.Content == "" {
+		return mentionTranslation{}, fmt.Errorf("translation is empty")
+	}
+	return translation, nil
+}
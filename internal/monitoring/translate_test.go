@@ -0,0 +1,72 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/llm"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	assert.Equal(t, "ja", detectLanguage("AKS のノード自動プロビジョニングを試してみた"))
+	assert.Equal(t, "zh", detectLanguage("AKS 集群升级踩坑记录"))
+	assert.Equal(t, "ko", detectLanguage("AKS 클러스터 업그레이드 후기"))
+	assert.Equal(t, "", detectLanguage("Upgrading AKS clusters without downtime"))
+	assert.Equal(t, "", detectLanguage("Our team in Tokyo (東京) moved everything to Azure Kubernetes Service last quarter and the migration went smoothly"),
+		"English posts quoting a word or two are not translated")
+}
+
+func TestService_translateMentions(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "key", r.Header.Get("api-key"))
+		reply, _ := json.Marshal(map[string]string{"title": "Notes on AKS node auto provisioning", "content": "I enabled NAP on AKS"})
+		response, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": "```json\n" + string(reply) + "\n```"}}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	client, err := llm.NewClient(server.URL, "gpt", "2024-06-01", "key")
+	require.NoError(t, err)
+	service := &Service{
+		config:       &config.Config{EnableTranslation: true, TranslationMaxMentions: 1},
+		llm:          client,
+		translations: cache.NewLRU[string, mentionTranslation](10),
+	}
+
+	mentions := []models.Mention{
+		{ID: "qiita_1", Title: "AKS のノード自動プロビジョニングを試す", Content: "AKS で NAP を有効にしました"},
+		{ID: "reddit_1", Title: "AKS upgrade went fine", Content: "No issues"},
+		{ID: "cnfeeds_1", Title: "AKS 集群升级踩坑记录", Content: "升级之后 ingress 不工作"},
+	}
+	budget := service.config.TranslationMaxMentions
+	service.translateMentions(context.Background(), mentions, &budget)
+
+	assert.Equal(t, 1, calls, "translations are capped per run")
+	assert.Equal(t, "Notes on AKS node auto provisioning", mentions[0].Title)
+	assert.Equal(t, "I enabled NAP on AKS", mentions[0].Content)
+	assert.Equal(t, "ja", mentions[0].Language)
+	assert.Equal(t, "AKS のノード自動プロビジョニングを試す", mentions[0].OriginalTitle)
+	assert.Equal(t, "AKS で NAP を有効にしました", mentions[0].OriginalContent)
+	assert.Empty(t, mentions[1].Language, "English mentions are left alone")
+	assert.Equal(t, "AKS 集群升级踩坑记录", mentions[2].Title, "over the translation budget")
+
+	// Mentions fetched again reuse their translation
+	budget = 0
+	again := []models.Mention{{ID: "qiita_1", Title: "AKS のノード自動プロビジョニングを試す", Content: "AKS で NAP を有効にしました"}}
+	service.translateMentions(context.Background(), again, &budget)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "Notes on AKS node auto provisioning", again[0].Title)
+}
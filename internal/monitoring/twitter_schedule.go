@@ -0,0 +1,45 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+)
+
+// twitterScheduleBlob holds the Twitter searches runs left unfinished and the request budget
+const twitterScheduleBlob = "twitter/schedule.json"
+
+// twitterScheduleStore persists the Twitter search schedule in blob storage
+type twitterScheduleStore struct {
+	storage storage.StorageInterface
+}
+
+// LoadTwitterSchedule returns the stored schedule, nil before the first run saves one
+func (s twitterScheduleStore) LoadTwitterSchedule() (*sources.TwitterSchedule, error) {
+	data, found, err := storage.RetrieveIfExists(s.storage, twitterScheduleBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s: %w", twitterScheduleBlob, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var schedule sources.TwitterSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", twitterScheduleBlob, err)
+	}
+	return &schedule, nil
+}
+
+// SaveTwitterSchedule stores the schedule for the next run
+func (s twitterScheduleStore) SaveTwitterSchedule(schedule *sources.TwitterSchedule) error {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Twitter schedule: %w", err)
+	}
+	if err := s.storage.Store(twitterScheduleBlob, data); err != nil {
+		return fmt.Errorf("failed to store %s: %w", twitterScheduleBlob, err)
+	}
+	return nil
+}
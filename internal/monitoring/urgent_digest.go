@@ -0,0 +1,265 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// urgentStoriesBlob records the stories urgent checks have alerted on, keyed by story
+const urgentStoriesBlob = "alerts/stories.json"
+
+// urgentDigestWindow is the period the daily urgent digest covers
+const urgentDigestWindow = 24 * time.Hour
+
+// urgentStory tracks the urgent mentions of one story: a security advisory, or a URL, however
+// many sources and checks surface it
+type urgentStory struct {
+	Mention        models.Mention `json:"mention"` // First mention of the story that was alerted
+	FirstAlertedAt time.Time      `json:"first_alerted_at"`
+	LastAlertedAt  time.Time      `json:"last_alerted_at"`
+	LastSeenAt     time.Time      `json:"last_seen_at"`
+	// When urgent notifications included the story, and when the cooldown kept sightings of it
+	// out of notifications, within the digest window
+	AlertedAt    []time.Time `json:"alerted_at,omitempty"`
+	SuppressedAt []time.Time `json:"suppressed_at,omitempty"`
+}
+
+// withinDigestWindow keeps the times of the last urgentDigestWindow before now
+func withinDigestWindow(times []time.Time, now time.Time) []time.Time {
+	kept := times[:0]
+	for _, at := range times {
+		if now.Sub(at) <= urgentDigestWindow {
+			kept = append(kept, at)
+		}
+	}
+	return kept
+}
+
+// urgentStoryKey identifies the story a mention is about. Mentions citing an advisory belong
+// to the advisory's story, so discussions of one CVE across sources alert once; other
+// mentions are identified by their URL.
+func urgentStoryKey(mention models.Mention) string {
+	if len(mention.Advisories) > 0 {
+		ids := make([]string, 0, len(mention.Advisories))
+		for _, advisory := range mention.Advisories {
+			ids = append(ids, advisory.ID)
+		}
+		sort.Strings(ids)
+		return "advisory:" + ids[0]
+	}
+
+	if parsed, err := url.Parse(mention.URL); err == nil && parsed.Host != "" {
+		host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+		key := "url:" + host + strings.TrimSuffix(parsed.Path, "/")
+		if parsed.RawQuery != "" {
+			key += "?" + parsed.RawQuery
+		}
+		return key
+	}
+	return "mention:" + mention.ID
+}
+
+// withoutCooledDown drops the mentions of stories alerted within URGENT_ALERT_COOLDOWN,
+// counting each as a suppressed sighting for the daily digest
+func (s *Service) withoutCooledDown(mentions []models.Mention, now time.Time) []models.Mention {
+	if s.config.UrgentAlertCooldown <= 0 || len(mentions) == 0 {
+		return mentions
+	}
+
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	stories, err := s.loadUrgentStories()
+	if err != nil {
+		logrus.Warnf("Failed to load urgent stories, alerting without a cooldown: %v", err)
+		return mentions
+	}
+
+	fresh := make([]models.Mention, 0, len(mentions))
+	suppressed := 0
+	for _, mention := range mentions {
+		story, ok := stories[urgentStoryKey(mention)]
+		if !ok || now.Sub(story.LastAlertedAt) >= s.config.UrgentAlertCooldown {
+			fresh = append(fresh, mention)
+			continue
+		}
+		story.SuppressedAt = append(withinDigestWindow(story.SuppressedAt, now), now)
+		story.LastSeenAt = now
+		suppressed++
+	}
+	if suppressed == 0 {
+		return fresh
+	}
+
+	logrus.Infof("Held back %d urgent mentions of stories alerted in the last %s", suppressed, s.config.UrgentAlertCooldown)
+	if err := s.saveUrgentStories(stories); err != nil {
+		logrus.Warnf("Failed to save urgent stories: %v", err)
+	}
+	return fresh
+}
+
+// recordUrgentStories notes that the stories of the mentions were sent in an urgent
+// notification, starting their cooldown
+func (s *Service) recordUrgentStories(mentions []models.Mention, at time.Time) {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	stories, err := s.loadUrgentStories()
+	if err != nil {
+		logrus.Warnf("Failed to load urgent stories: %v", err)
+		return
+	}
+
+	for key, story := range stories {
+		if at.Sub(story.LastSeenAt) > urgentAlertRetention {
+			delete(stories, key)
+		}
+	}
+
+	alerted := make(map[string]bool)
+	for _, mention := range mentions {
+		key := urgentStoryKey(mention)
+		if alerted[key] {
+			continue
+		}
+		alerted[key] = true
+
+		story, ok := stories[key]
+		if !ok {
+			story = &urgentStory{Mention: mention, FirstAlertedAt: at}
+			stories[key] = story
+		}
+		story.LastAlertedAt = at
+		story.LastSeenAt = at
+		story.AlertedAt = append(withinDigestWindow(story.AlertedAt, at), at)
+	}
+
+	if err := s.saveUrgentStories(stories); err != nil {
+		logrus.Warnf("Failed to save urgent stories: %v", err)
+	}
+}
+
+// SendUrgentDigest sends a summary of the urgent stories alerted or seen again in the last
+// 24 hours, with how often each was alerted and held back. Nothing is sent on a quiet day.
+func (s *Service) SendUrgentDigest() error {
+	_, done, err := s.runs.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	s.alertsMu.Lock()
+	stories, err := s.loadUrgentStories()
+	s.alertsMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var recent []*urgentStory
+	for _, story := range stories {
+		if now.Sub(story.LastSeenAt) <= urgentDigestWindow {
+			recent = append(recent, story)
+		}
+	}
+	if len(recent) == 0 {
+		logrus.Info("No urgent stories in the last 24 hours, skipping the urgent digest")
+		return nil
+	}
+
+	// Advisories first, then the most recently alerted
+	sort.Slice(recent, func(i, j int) bool {
+		if a, b := recent[i].Mention.Source == "cve", recent[j].Mention.Source == "cve"; a != b {
+			return a
+		}
+		return recent[i].LastAlertedAt.After(recent[j].LastAlertedAt)
+	})
+
+	mentions := make([]models.Mention, 0, len(recent))
+	alerts, suppressed := 0, 0
+	for _, story := range recent {
+		mention := story.Mention
+		alertedAt := story.FirstAlertedAt
+		mention.AlertedAt = &alertedAt
+		mentions = append(mentions, mention)
+		alerts += len(withinDigestWindow(story.AlertedAt, now))
+		suppressed += len(withinDigestWindow(story.SuppressedAt, now))
+	}
+
+	report := &models.Report{
+		GeneratedAt:   now,
+		Period:        "last 24 hours",
+		TotalMentions: len(mentions),
+		Mentions:      mentions,
+		Summary: map[string]interface{}{
+			"title":       "📋 Daily Urgent Items Summary",
+			"description": urgentDigestDescription(len(recent), suppressed, s.config.UrgentAlertCooldown),
+			"type":        "urgent_digest",
+			"alerts":      alerts,
+			"suppressed":  suppressed,
+		},
+	}
+	s.linkMentionPages(report.Mentions)
+	if err := s.notificationService.SendReport(report); err != nil {
+		return fmt.Errorf("failed to send urgent digest: %w", err)
+	}
+
+	logrus.Infof("Sent urgent digest of %d stories", len(recent))
+	return nil
+}
+
+// urgentDigestDescription summarizes the urgent digest
+func urgentDigestDescription(stories, suppressed int, cooldown time.Duration) string {
+	description := fmt.Sprintf("%d urgent AKS-related stories in the last 24 hours", stories)
+	if stories == 1 {
+		description = "1 urgent AKS-related story in the last 24 hours"
+	}
+	if suppressed > 0 {
+		description += fmt.Sprintf("; %d repeat sightings were not alerted again within the %s cooldown", suppressed, cooldown)
+	}
+	return description
+}
+
+// loadUrgentStories reads the alerted urgent stories; callers must hold s.alertsMu
+func (s *Service) loadUrgentStories() (map[string]*urgentStory, error) {
+	stories := make(map[string]*urgentStory)
+	if s.storage == nil {
+		return stories, nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.storage, urgentStoriesBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve urgent stories: %w", err)
+	}
+	if !found {
+		return stories, nil
+	}
+	if err := json.Unmarshal(data, &stories); err != nil {
+		return nil, fmt.Errorf("failed to parse urgent stories: %w", err)
+	}
+	return stories, nil
+}
+
+// saveUrgentStories writes the alerted urgent stories; callers must hold s.alertsMu
+func (s *Service) saveUrgentStories(stories map[string]*urgentStory) error {
+	if s.storage == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(stories)
+	if err != nil {
+		return fmt.Errorf("failed to marshal urgent stories: %w", err)
+	}
+	if err := s.storage.Store(urgentStoriesBlob, data); err != nil {
+		return fmt.Errorf("failed to store urgent stories: %w", err)
+	}
+	return nil
+}
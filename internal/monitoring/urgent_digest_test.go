@@ -0,0 +1,104 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUrgentStoryKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		mention  models.Mention
+		expected string
+	}{
+		{"advisory", models.Mention{ID: "reddit_1", URL: "https://reddit.com/r/kubernetes/1",
+			Advisories: []models.Advisory{{ID: "CVE-2024-9"}, {ID: "CVE-2024-1"}}}, "advisory:CVE-2024-1"},
+		{"url", models.Mention{ID: "reddit_2", URL: "https://www.Reddit.com/r/AZURE/comments/abc/aks_down/#top"}, "url:reddit.com/r/AZURE/comments/abc/aks_down"},
+		{"url with query", models.Mention{ID: "hackernews_3", URL: "https://news.ycombinator.com/item?id=3"}, "url:news.ycombinator.com/item?id=3"},
+		{"no url", models.Mention{ID: "twitter_4"}, "mention:twitter_4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, urgentStoryKey(tt.mention))
+		})
+	}
+}
+
+func TestService_urgentCooldownAndDigest(t *testing.T) {
+	notifier := testutil.NewRecordingNotificationService()
+	cfg := &config.Config{ReportSchedule: "daily", UrgentAlertCooldown: 24 * time.Hour}
+	service := NewService(cfg, testutil.NewMemoryStorage(), notifier)
+
+	advisory := models.Mention{ID: "cve_CVE-2024-1234", Source: "cve", Title: "CVE-2024-1234 in kubelet",
+		URL: "https://nvd.nist.gov/vuln/detail/CVE-2024-1234", Advisories: []models.Advisory{{ID: "CVE-2024-1234"}}}
+	discussion := models.Mention{ID: "reddit_1", Source: "reddit", Title: "Azure Kubernetes Service nodes affected by CVE-2024-1234?",
+		URL: "https://reddit.com/r/AZURE/comments/1"}
+
+	sent, err := service.notifyUrgent(context.Background(), []models.Mention{advisory, discussion}, "4-hour urgent check")
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	// The same CVE surfacing in another source stays quiet during the cooldown, new stories don't
+	repost := models.Mention{ID: "hackernews_2", Source: "hackernews", Title: "Azure Kubernetes Service and CVE-2024-1234",
+		URL: "https://news.ycombinator.com/item?id=2"}
+	outage := models.Mention{ID: "reddit_3", Source: "reddit", Title: "Azure Kubernetes Service outage in West Europe",
+		URL: "https://reddit.com/r/AZURE/comments/3"}
+	sent, err = service.notifyUrgent(context.Background(), []models.Mention{discussion, repost, outage}, "4-hour urgent check")
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	reports := notifier.Reports()
+	require.Len(t, reports, 2)
+	require.Len(t, reports[1].Mentions, 1)
+	assert.Equal(t, "reddit_3", reports[1].Mentions[0].ID)
+
+	// Once the cooldown is over the story alerts again
+	assert.Len(t, service.withoutCooledDown([]models.Mention{repost}, time.Now().Add(25*time.Hour)), 1)
+
+	require.NoError(t, service.SendUrgentDigest())
+	reports = notifier.Reports()
+	require.Len(t, reports, 3)
+	digest := reports[2]
+	assert.Equal(t, "urgent_digest", digest.Summary["type"])
+	assert.Equal(t, 2, digest.Summary["suppressed"])
+	assert.Equal(t, "2 urgent AKS-related stories in the last 24 hours; 2 repeat sightings were not alerted again within the 24h0m0s cooldown", digest.Summary["description"])
+	require.Len(t, digest.Mentions, 2)
+	assert.Equal(t, "cve_CVE-2024-1234", digest.Mentions[0].ID, "advisories lead the digest")
+	assert.Equal(t, "reddit_3", digest.Mentions[1].ID)
+	assert.NotNil(t, digest.Mentions[0].AlertedAt)
+}
+
+func TestService_SendUrgentDigest_Quiet(t *testing.T) {
+	notifier := testutil.NewRecordingNotificationService()
+	service := NewService(&config.Config{ReportSchedule: "daily"}, testutil.NewMemoryStorage(), notifier)
+	service.recordUrgentStories([]models.Mention{{ID: "reddit_1", URL: "https://reddit.com/r/AZURE/comments/1"}}, time.Now().Add(-48*time.Hour))
+
+	require.NoError(t, service.SendUrgentDigest())
+	assert.Empty(t, notifier.Reports(), "stories older than a day are left out")
+}
+
+func TestService_SendUrgentDigest_CountsLastDay(t *testing.T) {
+	notifier := testutil.NewRecordingNotificationService()
+	cfg := &config.Config{ReportSchedule: "daily", UrgentAlertCooldown: 72 * time.Hour}
+	service := NewService(cfg, testutil.NewMemoryStorage(), notifier)
+	outage := models.Mention{ID: "reddit_1", Source: "reddit", URL: "https://reddit.com/r/AZURE/comments/1"}
+
+	// Alerted and seen again two days ago, seen again within the last day
+	service.recordUrgentStories([]models.Mention{outage}, time.Now().Add(-50*time.Hour))
+	assert.Empty(t, service.withoutCooledDown([]models.Mention{outage}, time.Now().Add(-49*time.Hour)))
+	assert.Empty(t, service.withoutCooledDown([]models.Mention{outage}, time.Now().Add(-time.Hour)))
+
+	require.NoError(t, service.SendUrgentDigest())
+	require.Len(t, notifier.Reports(), 1)
+	digest := notifier.Reports()[0]
+	assert.Equal(t, 0, digest.Summary["alerts"], "alerts before the last 24 hours aren't counted")
+	assert.Equal(t, 1, digest.Summary["suppressed"])
+}
@@ -0,0 +1,67 @@
+package monitoring
+
+import (
+	"context"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// urgentVerifyLLMTimeout bounds a single LLM urgent verification
+	urgentVerifyLLMTimeout = 15 * time.Second
+	// urgentVerdictCacheSize bounds the verdicts remembered, so mentions seen again by later
+	// checks or the X stream skip the LLM
+	urgentVerdictCacheSize = 2000
+)
+
+// verifyUrgentMentions asks the LLM whether each keyword hit describes an actual AKS security
+// issue, outage or breaking change, and drops the ones that don't, such as a blog about
+// security best practices. Advisories are always urgent. Mentions the LLM fails on are kept,
+// as a false page is cheaper than a missed incident.
+func (s *Service) verifyUrgentMentions(ctx context.Context, mentions []models.Mention) []models.Mention {
+	if !s.config.EnableLLMUrgentVerification || s.llm == nil {
+		return mentions
+	}
+
+	var verified []models.Mention
+	for _, mention := range mentions {
+		if mention.Source == "cve" {
+			verified = append(verified, mention)
+			continue
+		}
+
+		urgent, cached := s.urgentVerdicts.Get(mention.ID)
+		if !cached {
+			var err error
+			urgent, err = s.verifyUrgentWithLLM(ctx, mention)
+			if err != nil {
+				logrus.Warnf("LLM urgent verification failed for %s, alerting anyway: %v", mention.ID, err)
+				verified = append(verified, mention)
+				continue
+			}
+			s.urgentVerdicts.Add(mention.ID, urgent)
+		}
+
+		if urgent {
+			verified = append(verified, mention)
+		} else {
+			logrus.Infof("Urgent keyword hit dismissed by LLM verification: %s", mention.Title)
+		}
+	}
+	return verified
+}
+
+func (s *Service) verifyUrgentWithLLM(ctx context.Context, mention models.Mention) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, urgentVerifyLLMTimeout)
+	defer cancel()
+
+	content := truncateRunes(mention.Content, llmMaxInput)
+
+	return s.llm.YesNo(ctx,
+		"You triage community posts that matched urgent keywords while monitoring mentions of Azure Kubernetes Service (AKS), before the on-call team is paged. "+
+			"Decide whether the post reports an actual AKS security vulnerability, exploit, outage or breaking change affecting users now, "+
+			"rather than general security best practices, tutorials, news roundups or routine deprecation notices.",
+		"Source: "+mention.Source+"\nAuthor: "+mention.Author+"\nTitle: "+mention.Title+"\n\n"+content)
+}
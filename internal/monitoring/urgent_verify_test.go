@@ -0,0 +1,76 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/llm"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_verifyUrgentMentions(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var request struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		prompt := request.Messages[len(request.Messages)-1].Content
+
+		if strings.Contains(prompt, "LLM down") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		answer := "no"
+		if strings.Contains(prompt, "outage") {
+			answer = "yes"
+		}
+		response, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": answer}}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(response)
+	}))
+	defer server.Close()
+
+	client, err := llm.NewClient(server.URL, "gpt", "2024-06-01", "key")
+	require.NoError(t, err)
+	service := &Service{
+		config:         &config.Config{EnableLLMUrgentVerification: true},
+		llm:            client,
+		urgentVerdicts: cache.NewLRU[string, bool](10),
+	}
+
+	mentions := []models.Mention{
+		{ID: "cve_CVE-2024-1", Source: "cve", Title: "CVE-2024-1 in kubelet"},
+		{ID: "medium_1", Source: "medium", Title: "10 security best practices for AKS", Content: "Avoid the next breach by hardening your cluster"},
+		{ID: "reddit_1", Source: "reddit", Title: "AKS outage in West Europe", Content: "API server unreachable since 9:00"},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS CVE patch", Content: "LLM down"},
+	}
+	verified := service.verifyUrgentMentions(context.Background(), mentions)
+
+	var ids []string
+	for _, mention := range verified {
+		ids = append(ids, mention.ID)
+	}
+	assert.Equal(t, []string{"cve_CVE-2024-1", "reddit_1", "reddit_2"}, ids, "advisories and failed verifications still page")
+	assert.Equal(t, 3, calls, "advisories are not sent to the LLM")
+
+	// Mentions seen again reuse their verdict
+	service.verifyUrgentMentions(context.Background(), mentions[1:3])
+	assert.Equal(t, 3, calls)
+
+	service.config.EnableLLMUrgentVerification = false
+	assert.Len(t, service.verifyUrgentMentions(context.Background(), mentions), 4)
+}
@@ -0,0 +1,236 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// watermarksBlob holds the per-source high-watermarks used to size scheduled fetch windows
+const watermarksBlob = "watermarks/sources.json"
+
+// reportedBlob holds the IDs of the mentions reported while watermarks are on, so mentions
+// found again by the overlap of the next window aren't reported twice
+const reportedBlob = "watermarks/reported.json"
+
+// defaultWatermarkMaxWindow bounds the fetch window when no maximum has been configured
+const defaultWatermarkMaxWindow = 30 * 24 * time.Hour
+
+// SourceWatermark records the newest mention a source has returned in a successful run
+type SourceWatermark struct {
+	LatestMention time.Time `json:"latest_mention"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// fetchWindow returns how far back to search a source
+type fetchWindow func(source string) time.Duration
+
+// fixedWindow searches every source over the same window
+func fixedWindow(window time.Duration) fetchWindow {
+	return func(string) time.Duration { return window }
+}
+
+// watermarkWindow searches each source from its watermark minus the configured overlap, so
+// mentions published while a run was failing or the bot was down are still picked up.
+// Sources without a watermark use the fallback window.
+func (s *Service) watermarkWindow(fallback time.Duration) fetchWindow {
+	if !s.config.EnableWatermarks || s.storage == nil {
+		return fixedWindow(fallback)
+	}
+
+	s.watermarksMu.Lock()
+	watermarks, err := s.loadWatermarks()
+	s.watermarksMu.Unlock()
+	if err != nil {
+		logrus.Warnf("Failed to load source watermarks, using %v window: %v", fallback, err)
+		return fixedWindow(fallback)
+	}
+
+	maxWindow := s.config.WatermarkMaxWindow
+	if maxWindow <= 0 {
+		maxWindow = defaultWatermarkMaxWindow
+	}
+
+	now := time.Now()
+	return func(source string) time.Duration {
+		watermark, ok := watermarks[source]
+		if !ok || watermark.LatestMention.IsZero() {
+			return fallback
+		}
+
+		window := (now.Sub(watermark.LatestMention) + s.config.WatermarkOverlap).Round(time.Minute)
+		if window > maxWindow {
+			logrus.Warnf("Watermark for %s is %v old, limiting window to %v", source, now.Sub(watermark.LatestMention).Round(time.Minute), maxWindow)
+			return maxWindow
+		}
+		return window
+	}
+}
+
+// reportedMentions returns when each mention reported within the maximum window was
+// reported, nil when watermarks are off
+func (s *Service) reportedMentions() map[string]time.Time {
+	if !s.config.EnableWatermarks || s.storage == nil {
+		return nil
+	}
+
+	reported, err := s.loadReported()
+	if err != nil {
+		logrus.Warnf("Failed to load reported mentions, overlapping mentions may be reported again: %v", err)
+		return nil
+	}
+	return reported
+}
+
+// recordReported adds the mentions of a report to the reported mentions, forgetting those
+// reported before the maximum window, which no later window reaches back to
+func (s *Service) recordReported(mentions []models.Mention) {
+	if !s.config.EnableWatermarks || s.storage == nil {
+		return
+	}
+
+	reported, err := s.loadReported()
+	if err != nil {
+		logrus.Warnf("Failed to load reported mentions, not recording: %v", err)
+		return
+	}
+
+	maxWindow := s.config.WatermarkMaxWindow
+	if maxWindow <= 0 {
+		maxWindow = defaultWatermarkMaxWindow
+	}
+	now := time.Now().UTC()
+	for id, at := range reported {
+		if now.Sub(at) > maxWindow+s.config.WatermarkOverlap {
+			delete(reported, id)
+		}
+	}
+	for _, mention := range mentions {
+		reported[mention.ID] = now
+	}
+
+	data, err := json.Marshal(reported)
+	if err != nil {
+		logrus.Warnf("Failed to marshal reported mentions: %v", err)
+		return
+	}
+	if err := s.storage.Store(reportedBlob, data); err != nil {
+		logrus.Warnf("Failed to store reported mentions: %v", err)
+	}
+}
+
+func (s *Service) loadReported() (map[string]time.Time, error) {
+	reported := make(map[string]time.Time)
+
+	data, found, err := storage.RetrieveIfExists(s.storage, reportedBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve reported mentions: %w", err)
+	}
+	if !found {
+		return reported, nil
+	}
+	if err := json.Unmarshal(data, &reported); err != nil {
+		return nil, fmt.Errorf("failed to parse reported mentions: %w", err)
+	}
+	return reported, nil
+}
+
+// dropReported removes the mentions already reported
+func dropReported(mentions []models.Mention, reported map[string]time.Time) []models.Mention {
+	if len(reported) == 0 {
+		return mentions
+	}
+
+	kept := mentions[:0:0]
+	for _, mention := range mentions {
+		if _, ok := reported[mention.ID]; !ok {
+			kept = append(kept, mention)
+		}
+	}
+	if dropped := len(mentions) - len(kept); dropped > 0 {
+		logrus.Debugf("Skipping %d mentions already reported", dropped)
+	}
+	return kept
+}
+
+// latestMention returns the newest mention timestamp, ignoring timestamps in the future
+func latestMention(mentions []models.Mention) time.Time {
+	now := time.Now()
+	var latest time.Time
+	for _, mention := range mentions {
+		if mention.CreatedAt.After(latest) && !mention.CreatedAt.After(now) {
+			latest = mention.CreatedAt
+		}
+	}
+	return latest
+}
+
+// advanceWatermarks moves each source's watermark forward to the newest mention it returned.
+// Watermarks never move backwards, so a run that finds only older mentions keeps the window,
+// and runs advancing them at the same time, such as a report run and a source collection,
+// don't overwrite each other.
+func (s *Service) advanceWatermarks(latest map[string]time.Time) {
+	if !s.config.EnableWatermarks || s.storage == nil || len(latest) == 0 {
+		return
+	}
+
+	s.watermarksMu.Lock()
+	defer s.watermarksMu.Unlock()
+
+	watermarks, err := s.loadWatermarks()
+	if err != nil {
+		logrus.Warnf("Failed to load source watermarks, not advancing: %v", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	changed := false
+	for source, at := range latest {
+		if at.IsZero() || !at.After(watermarks[source].LatestMention) {
+			continue
+		}
+		watermarks[source] = SourceWatermark{LatestMention: at.UTC(), UpdatedAt: now}
+		changed = true
+		logrus.Debugf("Advanced %s watermark to %s", source, at.UTC().Format(time.RFC3339))
+	}
+	if !changed {
+		return
+	}
+
+	if err := s.saveWatermarks(watermarks); err != nil {
+		logrus.Warnf("Failed to save source watermarks: %v", err)
+	}
+}
+
+// loadWatermarks reads the source watermarks; callers must hold s.watermarksMu
+func (s *Service) loadWatermarks() (map[string]SourceWatermark, error) {
+	watermarks := make(map[string]SourceWatermark)
+
+	data, found, err := storage.RetrieveIfExists(s.storage, watermarksBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve source watermarks: %w", err)
+	}
+	if !found {
+		return watermarks, nil
+	}
+	if err := json.Unmarshal(data, &watermarks); err != nil {
+		return nil, fmt.Errorf("failed to parse source watermarks: %w", err)
+	}
+	return watermarks, nil
+}
+
+// saveWatermarks persists the source watermarks; callers must hold s.watermarksMu
+func (s *Service) saveWatermarks(watermarks map[string]SourceWatermark) error {
+	data, err := json.Marshal(watermarks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal source watermarks: %w", err)
+	}
+	if err := s.storage.Store(watermarksBlob, data); err != nil {
+		return fmt.Errorf("failed to store source watermarks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_watermarkWindow(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	cfg := &config.Config{
+		EnableWatermarks:   true,
+		WatermarkOverlap:   time.Hour,
+		WatermarkMaxWindow: 10 * 24 * time.Hour,
+	}
+	service := &Service{config: cfg, storage: storage}
+
+	now := time.Now()
+	require.NoError(t, service.saveWatermarks(map[string]SourceWatermark{
+		"reddit":     {LatestMention: now.Add(-3 * 24 * time.Hour)},
+		"hackernews": {LatestMention: now.Add(-60 * 24 * time.Hour)},
+	}))
+
+	window := service.watermarkWindow(24 * time.Hour)
+	assert.InDelta(t, (3*24*time.Hour + time.Hour).Seconds(), window("reddit").Seconds(), 60)
+	assert.Equal(t, 10*24*time.Hour, window("hackernews"), "long downtime is bounded by the maximum window")
+	assert.Equal(t, 24*time.Hour, window("medium"), "sources without a watermark use the fallback")
+
+	cfg.EnableWatermarks = false
+	assert.Equal(t, 24*time.Hour, service.watermarkWindow(24*time.Hour)("reddit"))
+}
+
+func TestService_advanceWatermarks(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	cfg := &config.Config{
+		EnableWatermarks:       true,
+		EnableContextFiltering: true,
+		SourceTimeout:          time.Second,
+	}
+	service := &Service{config: cfg, storage: storage}
+
+	older := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	newer := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, service.saveWatermarks(map[string]SourceWatermark{
+		"reddit": {LatestMention: older},
+		"medium": {LatestMention: newer},
+	}))
+
+	reddit := &stubSource{name: "reddit", mentions: []models.Mention{
+		// Irrelevant mentions still advance the watermark; the source returned them
+		{ID: "reddit_1", Source: "reddit", Title: "AKS-47 rifle review", CreatedAt: newer},
+		{ID: "reddit_2", Source: "reddit", Title: "From the future", CreatedAt: time.Now().Add(time.Hour)},
+	}}
+	medium := &stubSource{name: "medium", mentions: []models.Mention{
+		{ID: "medium_1", Source: "medium", Title: "Old AKS post", CreatedAt: older},
+	}}
+	service.sources = []sources.Source{reddit, medium}
+
+	result := service.runPipeline(context.Background(), "run", []string{"aks"}, service.watermarkWindow(24*time.Hour))
+	require.NoError(t, result.storeErr)
+	assert.GreaterOrEqual(t, reddit.since, 48*time.Hour)
+	service.advanceWatermarks(result.latest)
+
+	watermarks, err := service.loadWatermarks()
+	require.NoError(t, err)
+	assert.True(t, watermarks["reddit"].LatestMention.Equal(newer))
+	assert.True(t, watermarks["medium"].LatestMention.Equal(newer), "watermarks never move backwards")
+}
+
+func TestService_advanceWatermarksConcurrently(t *testing.T) {
+	service := &Service{config: &config.Config{EnableWatermarks: true}, storage: testutil.NewMemoryStorage()}
+	at := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	// A report run and source collections advancing their own sources at the same time
+	var wg sync.WaitGroup
+	for _, source := range []string{"reddit", "twitter", "hackernews", "medium"} {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				service.advanceWatermarks(map[string]time.Time{source: at.Add(time.Duration(i) * time.Second)})
+			}
+		}(source)
+	}
+	wg.Wait()
+
+	watermarks, err := service.loadWatermarks()
+	require.NoError(t, err)
+	for _, source := range []string{"reddit", "twitter", "hackernews", "medium"} {
+		assert.True(t, watermarks[source].LatestMention.Equal(at.Add(19*time.Second)), source)
+	}
+}
+
+func TestService_reportedMentions(t *testing.T) {
+	storage := testutil.NewMemoryStorage()
+	cfg := &config.Config{EnableWatermarks: true, WatermarkMaxWindow: 10 * 24 * time.Hour, SourceTimeout: time.Second}
+	service := &Service{config: cfg, storage: storage}
+
+	service.recordReported([]models.Mention{{ID: "reddit_1"}})
+	reported := service.reportedMentions()
+	assert.Contains(t, reported, "reddit_1")
+
+	// The overlap of the next window finds reddit_1 again; only the new mention is reported
+	service.sources = []sources.Source{&stubSource{name: "reddit", mentions: []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade", CreatedAt: time.Now().Add(-2 * time.Hour)},
+		{ID: "reddit_2", Source: "reddit", Title: "AKS networking", CreatedAt: time.Now().Add(-time.Hour)},
+	}}}
+	result := service.runPipeline(context.Background(), "run", []string{"aks"}, service.watermarkWindow(24*time.Hour))
+	require.Len(t, result.mentions, 1)
+	assert.Equal(t, "reddit_2", result.mentions[0].ID)
+	assert.False(t, result.latest["reddit"].IsZero(), "reported mentions still advance the watermark")
+
+	// Mentions reported before the maximum window are forgotten
+	reported["reddit_old"] = time.Now().Add(-11 * 24 * time.Hour)
+	data, err := json.Marshal(reported)
+	require.NoError(t, err)
+	require.NoError(t, storage.Store(reportedBlob, data))
+	service.recordReported(result.mentions)
+	reported = service.reportedMentions()
+	assert.Contains(t, reported, "reddit_2")
+	assert.NotContains(t, reported, "reddit_old")
+
+	cfg.EnableWatermarks = false
+	assert.Nil(t, service.reportedMentions())
+}
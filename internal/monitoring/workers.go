@@ -0,0 +1,146 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sources"
+	"github.com/sirupsen/logrus"
+)
+
+// fetchResult holds the outcome of fetching mentions from a single source
+type fetchResult struct {
+	source   string
+	mentions []models.Mention
+	err      error
+	duration time.Duration
+}
+
+// fetchTimeout returns the fetch timeout of a source
+type fetchTimeout func(source string) time.Duration
+
+// fetchFromSources fetches mentions from the given sources using a bounded worker pool
+// and returns once every source has completed.
+func (s *Service) fetchFromSources(ctx context.Context, srcs []sources.Source, keywords []string, window time.Duration, timeout fetchTimeout) []fetchResult {
+	var collected []fetchResult
+	for result := range s.streamFromSources(ctx, srcs, keywords, fixedWindow(window), timeout) {
+		collected = append(collected, result)
+	}
+	return collected
+}
+
+// streamFromSources fetches mentions from the given sources using a bounded worker pool.
+// Each source runs under its own timeout so a slow source cannot consume the whole run budget.
+// Results are emitted as soon as each source completes; the channel is closed when all are done.
+// Sources whose circuit breaker is open are not fetched and emit a single skip error.
+func (s *Service) streamFromSources(ctx context.Context, srcs []sources.Source, keywords []string, window fetchWindow, timeout fetchTimeout) <-chan fetchResult {
+	results := make(chan fetchResult, len(srcs))
+
+	var fetched []sources.Source
+	for _, src := range srcs {
+		if skipped, ok := s.skippedResult(src.GetName()); ok {
+			results <- skipped
+			continue
+		}
+		fetched = append(fetched, src)
+	}
+	srcs = fetched
+
+	concurrency := s.config.SourceConcurrency
+	if concurrency <= 0 || concurrency > len(srcs) {
+		concurrency = len(srcs)
+	}
+
+	jobs := make(chan sources.Source)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for src := range jobs {
+				results <- s.fetchFromSource(ctx, src, keywords, window(src.GetName()), timeout(src.GetName()))
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, src := range srcs {
+			select {
+			case jobs <- src:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// fetchFromSource fetches mentions from a single source under the given timeout
+func (s *Service) fetchFromSource(ctx context.Context, src sources.Source, keywords []string, window, timeout time.Duration) fetchResult {
+	name := src.GetName()
+	start := time.Now()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	logrus.Infof("Fetching mentions from %s (window: %v)", name, window)
+	mentions, err := src.FetchMentions(ctx, keywords, window)
+	duration := time.Since(start)
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && len(mentions) > 0 {
+			// Keep whatever the source managed to collect before timing out
+			logrus.Warnf("Source %s timed out after %v, keeping %d partial mentions", name, duration, len(mentions))
+		} else {
+			logrus.Errorf("Error fetching from %s: %v", name, err)
+		}
+		result := fetchResult{source: name, mentions: mentions, err: err, duration: duration}
+		s.recordFetch(ctx, result)
+		return result
+	}
+
+	logrus.Infof("Found %d mentions from %s in %v", len(mentions), name, duration)
+	result := fetchResult{source: name, mentions: mentions, duration: duration}
+	s.recordFetch(ctx, result)
+	return result
+}
+
+// recordFetch records the outcome of a fetch in source health and the circuit breakers,
+// unless ctx says otherwise
+func (s *Service) recordFetch(ctx context.Context, result fetchResult) {
+	if !sourceRecording(ctx) {
+		return
+	}
+	s.recordSourceHealth(result)
+	s.recordBreaker(result)
+}
+
+// sourceTimeout returns the fetch timeout for the named source, falling back to the default
+func (s *Service) sourceTimeout(name string) time.Duration {
+	if timeout, ok := s.config.SourceTimeouts[name]; ok {
+		return timeout
+	}
+	return s.config.SourceTimeout
+}
+
+// urgentSourceTimeout returns the tighter fetch timeout of a source during urgent checks
+func (s *Service) urgentSourceTimeout(name string) time.Duration {
+	if timeout, ok := s.config.UrgentSourceTimeouts[name]; ok {
+		return timeout
+	}
+	return s.config.UrgentSourceTimeout
+}
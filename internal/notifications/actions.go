@@ -0,0 +1,108 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// Mention actions offered on alerts; the monitoring service applies them
+const (
+	alertActionHandled  = "handled"
+	alertActionEscalate = "escalate"
+)
+
+// ErrInvalidActionToken is returned when a mention action link has been tampered with
+var ErrInvalidActionToken = errors.New("invalid mention action token")
+
+// MentionActionToken signs the link applying action to a mention, so it can't be reused for
+// another mention or action
+func MentionActionToken(secret, id, action string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(action + "\n" + id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckMentionActionToken verifies a mention action link
+func (s *Service) CheckMentionActionToken(id, action, token string) error {
+	if s.config.InboundWebhookSecret == "" {
+		return ErrInvalidActionToken
+	}
+	expected := MentionActionToken(s.config.InboundWebhookSecret, id, action)
+	if !hmac.Equal([]byte(expected), []byte(token)) {
+		return ErrInvalidActionToken
+	}
+	return nil
+}
+
+// withMentionActions returns a copy of an alert about a mention with signed links to mark it
+// handled or, unless it is an escalation already, to escalate it. Links are only added when
+// inbound actions are enabled and the bot is reachable.
+func (s *Service) withMentionActions(alert *models.Alert) *models.Alert {
+	if alert.Mention == nil || alert.Mention.ID == "" || len(alert.Actions) > 0 ||
+		s.config.PublicBaseURL == "" || s.config.InboundWebhookSecret == "" {
+		return alert
+	}
+
+	withActions := *alert
+	withActions.Actions = []models.AlertAction{{Name: "Mark handled", URL: s.mentionActionLink(alert.Mention.ID, alertActionHandled)}}
+	if alert.Type == "urgent" {
+		withActions.Actions = append(withActions.Actions, models.AlertAction{Name: "Escalate", URL: s.mentionActionLink(alert.Mention.ID, alertActionEscalate)})
+	}
+	return &withActions
+}
+
+// mentionActionLink is the signed page confirming an action on a mention
+func (s *Service) mentionActionLink(id, action string) string {
+	query := url.Values{}
+	query.Set("token", MentionActionToken(s.config.InboundWebhookSecret, id, action))
+	return fmt.Sprintf("%s/m/%s/%s?%s", s.config.PublicBaseURL, url.PathEscape(id), action, query.Encode())
+}
+
+// mentionActionPage is the data rendered by the mention action page
+type mentionActionPage struct {
+	Title   string
+	Action  string
+	Token   string
+	Applied bool
+}
+
+// mentionActionTemplate asks to confirm an action from an alert, since link previews and
+// scanners follow the links in messages with GET requests
+var mentionActionTemplate = template.Must(template.New("mention-action").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>AKS Mentions Bot - {{if eq .Action "escalate"}}Escalate{{else}}Mark handled{{end}}</title>
+    <style>
+        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px; color: #333; }
+    </style>
+</head>
+<body>
+    {{if .Applied}}
+    <p><strong>{{.Title}}</strong> has been {{if eq .Action "escalate"}}escalated{{else}}marked handled{{end}}.</p>
+    {{else}}
+    <p>{{if eq .Action "escalate"}}Escalate{{else}}Mark as handled{{end}} <strong>{{.Title}}</strong>?</p>
+    <form method="POST">
+        <input type="hidden" name="token" value="{{.Token}}">
+        <label>Your name <input type="text" name="actor"></label><br>
+        <label>Note <input type="text" name="note"></label><br>
+        <button type="submit">{{if eq .Action "escalate"}}Escalate{{else}}Mark handled{{end}}</button>
+    </form>
+    {{end}}
+</body>
+</html>
+`))
+
+// RenderMentionActionPage writes the confirmation form of an action on a mention, or the
+// confirmation that it has been applied
+func (s *Service) RenderMentionActionPage(w io.Writer, title, action, token string, applied bool) error {
+	return mentionActionTemplate.Execute(w, mentionActionPage{Title: title, Action: action, Token: token, Applied: applied})
+}
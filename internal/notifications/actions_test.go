@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_withMentionActions(t *testing.T) {
+	service := NewService(&config.Config{PublicBaseURL: "https://bot.example.com", InboundWebhookSecret: "secret"})
+	alert := &models.Alert{Type: "urgent", Title: "AKS outage", Mention: &models.Mention{ID: "reddit_2", Source: "reddit"}}
+
+	withActions := service.withMentionActions(alert)
+	assert.Empty(t, alert.Actions, "the alert passed in is not modified")
+	require.Len(t, withActions.Actions, 2)
+	assert.Equal(t, "Mark handled", withActions.Actions[0].Name)
+	assert.Equal(t, "https://bot.example.com/m/reddit_2/handled?token="+MentionActionToken("secret", "reddit_2", "handled"), withActions.Actions[0].URL)
+	assert.Equal(t, "https://bot.example.com/m/reddit_2/escalate?token="+MentionActionToken("secret", "reddit_2", "escalate"), withActions.Actions[1].URL)
+
+	assert.NoError(t, service.CheckMentionActionToken("reddit_2", "escalate", MentionActionToken("secret", "reddit_2", "escalate")))
+	assert.ErrorIs(t, service.CheckMentionActionToken("reddit_1", "escalate", MentionActionToken("secret", "reddit_2", "escalate")), ErrInvalidActionToken)
+	assert.ErrorIs(t, service.CheckMentionActionToken("reddit_2", "handled", MentionActionToken("secret", "reddit_2", "escalate")), ErrInvalidActionToken)
+
+	// Escalations can only be marked handled
+	critical := service.withMentionActions(&models.Alert{Type: "critical", Mention: alert.Mention})
+	require.Len(t, critical.Actions, 1)
+	assert.Equal(t, "Mark handled", critical.Actions[0].Name)
+
+	card := buildTeamsAlert(withActions)
+	require.Len(t, card.PotentialAction, 2)
+	assert.Equal(t, "OpenUri", card.PotentialAction[1].Type)
+	assert.Equal(t, withActions.Actions[1].URL, card.PotentialAction[1].Targets[0].URI)
+
+	// Without a secret the links couldn't be verified
+	unsigned := NewService(&config.Config{PublicBaseURL: "https://bot.example.com"})
+	assert.Empty(t, unsigned.withMentionActions(alert).Actions)
+	assert.ErrorIs(t, unsigned.CheckMentionActionToken("reddit_2", "handled", ""), ErrInvalidActionToken)
+}
+
+func TestService_RenderMentionActionPage(t *testing.T) {
+	service := NewService(&config.Config{})
+
+	var confirm strings.Builder
+	require.NoError(t, service.RenderMentionActionPage(&confirm, "Create fails <eastus>", "escalate", "abc", false))
+	assert.Contains(t, confirm.String(), `<form method="POST">`)
+	assert.Contains(t, confirm.String(), `name="token" value="abc"`)
+	assert.Contains(t, confirm.String(), "Create fails &lt;eastus&gt;")
+
+	var applied strings.Builder
+	require.NoError(t, service.RenderMentionActionPage(&applied, "Create fails", "handled", "abc", true))
+	assert.Contains(t, applied.String(), "has been marked handled")
+	assert.NotContains(t, applied.String(), "<form")
+}
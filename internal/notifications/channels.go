@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRetryDelay is the wait before the first retry of a failed post; each later retry
+// waits twice as long
+const defaultRetryDelay = 2 * time.Second
+
+// statusError is a response from a channel outside the 2xx range
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook returned status %d: %s", e.status, e.body)
+}
+
+// retryable reports whether a failed post may succeed later: connection errors, throttling
+// and server errors are retried, while rejected payloads and URLs are not
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.status == 429 || status.status >= 500
+	}
+	return true
+}
+
+// sendReportToChannel posts a report in the channel's format. Teams, Logic Apps and Slack get
+// the top mentions per source, with headers in the channel's locale; generic webhooks get the
+// whole report.
+func (s *Service) sendReportToChannel(channel config.NotificationChannel, report *models.Report) error {
+	switch channel.Type {
+	case config.ChannelWebhook:
+		return s.retry(channel, func() error {
+			return s.webhookSender.sendTo(channel.URL, "report", report)
+		})
+	case config.ChannelSlack:
+		return s.postChannel(channel, s.buildSlackMessage(s.sampleReport(report), catalog(channel.Locale)))
+	}
+
+	report = s.sampleReport(report)
+	if channel.Format == config.TeamsWebhookFormatLogicApp {
+		return s.sendToLogicApps(channel, report)
+	}
+	return s.postChannel(channel, s.buildTeamsMessage(report, catalog(channel.Locale)))
+}
+
+// sendAlertToChannel posts an alert in the channel's format
+func (s *Service) sendAlertToChannel(channel config.NotificationChannel, alert *models.Alert) error {
+	switch channel.Type {
+	case config.ChannelWebhook:
+		return s.retry(channel, func() error {
+			return s.webhookSender.sendTo(channel.URL, "alert", alert)
+		})
+	case config.ChannelSlack:
+		return s.postChannel(channel, buildSlackAlert(alert))
+	}
+
+	if channel.Format == config.TeamsWebhookFormatLogicApp {
+		return s.postChannel(channel, s.buildLogicAppAlert(alert))
+	}
+	return s.postChannel(channel, buildTeamsAlert(alert))
+}
+
+// postChannel posts a JSON payload to a channel, retrying failures that may pass later
+func (s *Service) postChannel(channel config.NotificationChannel, payload interface{}) error {
+	return s.retry(channel, func() error {
+		resp, err := s.client.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(payload).
+			Post(channel.URL)
+		if err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+		if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+			return &statusError{status: resp.StatusCode(), body: string(resp.Body())}
+		}
+		return nil
+	})
+}
+
+// retry calls send until it succeeds, fails in a way retrying won't fix, or the channel's
+// retries are used up, backing off between attempts
+func (s *Service) retry(channel config.NotificationChannel, send func() error) error {
+	delay := s.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := send()
+		if err == nil || !retryable(err) || attempt >= channel.Retries {
+			return err
+		}
+		logrus.Warnf("Failed to post to channel %s (attempt %d of %d), retrying in %s: %v", channel.Name, attempt+1, channel.Retries+1, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
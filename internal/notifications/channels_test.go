@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SendReportToChannels(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]int)
+		bodies   = make(map[string][]byte)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.URL.Path]++
+		bodies[r.URL.Path] = body
+
+		switch r.URL.Path {
+		case "/flaky":
+			// Fails twice before accepting
+			if requests[r.URL.Path] <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/rejected":
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewService(&config.Config{
+		NotificationChannels: []config.NotificationChannel{
+			{Name: "rejected", Type: config.ChannelTeams, URL: server.URL + "/rejected", Format: config.TeamsWebhookFormatMessageCard, Retries: 3},
+			{Name: "flow", Type: config.ChannelLogicApp, URL: server.URL + "/flaky", Format: config.TeamsWebhookFormatLogicApp, Retries: 2},
+			{Name: "slack", Type: config.ChannelSlack, URL: server.URL + "/slack"},
+			{Name: "n8n", Type: config.ChannelWebhook, URL: server.URL + "/webhook"},
+		},
+		OutboundWebhookSecret: "secret",
+	})
+	service.retryDelay = time.Millisecond
+
+	report := &models.Report{
+		GeneratedAt:   time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+		Period:        "daily",
+		TotalMentions: 1,
+		Summary:       map[string]interface{}{"sentiment": map[string]int{"negative": 1}},
+		Mentions:      []models.Mention{{ID: "r1", Source: "reddit", Title: "AKS <upgrade> stuck", URL: "https://reddit.com/r/AZURE/1"}},
+	}
+
+	err := service.SendReport(report)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected: webhook returned status 400")
+	assert.NotContains(t, err.Error(), "flow")
+
+	assert.Equal(t, 1, requests["/rejected"], "client errors are not retried")
+	assert.Equal(t, 3, requests["/flaky"], "server errors are retried")
+	assert.Equal(t, 1, requests["/slack"])
+	assert.Equal(t, 1, requests["/webhook"])
+
+	var logicApp LogicAppMessage
+	require.NoError(t, json.Unmarshal(bodies["/flaky"], &logicApp))
+	assert.Equal(t, LogicAppSchemaVersion, logicApp.SchemaVersion)
+
+	var slack SlackMessage
+	require.NoError(t, json.Unmarshal(bodies["/slack"], &slack))
+	require.Len(t, slack.Blocks, 4)
+	assert.Equal(t, "header", slack.Blocks[0].Type)
+	assert.Equal(t, "*<https://reddit.com/r/AZURE/1|AKS &lt;upgrade&gt; stuck>* - reddit (Jan 1)", slack.Blocks[3].Text.Text)
+
+	var event WebhookEvent
+	require.NoError(t, json.Unmarshal(bodies["/webhook"], &event))
+	assert.Equal(t, "report", event.Type)
+}
+
+func TestService_SendAlertToChannels(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service := NewService(&config.Config{
+		NotificationChannels: []config.NotificationChannel{
+			{Name: "teams", Type: config.ChannelTeams, URL: server.URL + "/teams", Format: config.TeamsWebhookFormatMessageCard},
+			{Name: "slack", Type: config.ChannelSlack, URL: server.URL + "/slack", Retries: 1},
+		},
+	})
+	service.retryDelay = time.Millisecond
+
+	err := service.SendAlert(&models.Alert{Type: "critical", Title: "Negative spike", Message: "12 negative mentions"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "teams:")
+	assert.Contains(t, err.Error(), "slack:")
+	assert.Equal(t, []string{"/teams", "/slack", "/slack"}, paths)
+}
+
+func TestBuildSlackAlert(t *testing.T) {
+	message := buildSlackAlert(&models.Alert{
+		Type:    "urgent",
+		Title:   "AKS outage",
+		Message: "Cluster creation failing in eastus",
+		Mention: &models.Mention{Source: "reddit", Title: "Create fails | eastus", URL: "https://reddit.com/r/AZURE/2"},
+	})
+
+	assert.Equal(t, "AKS outage", message.Text)
+	require.Len(t, message.Blocks, 1)
+	assert.Equal(t, ":warning: *AKS outage*\nCluster creation failing in eastus\n<https://reddit.com/r/AZURE/2|Create fails - eastus> - reddit", message.Blocks[0].Text.Text)
+}
@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Email delivery modes
+const (
+	EmailDeliverySMTP  = "smtp"
+	EmailDeliveryGraph = "graph"
+)
+
+// emailMessage is a rendered report email, independent of how it is delivered
+type emailMessage struct {
+	To             string
+	Subject        string
+	Text           string
+	HTML           string // Empty for recipients who asked for plain text
+	UnsubscribeURL string
+	Attachment     *emailAttachment
+}
+
+// emailAttachment is a file attached to an email
+type emailAttachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// emailSender delivers report emails
+type emailSender interface {
+	SendEmail(message *emailMessage) error
+}
+
+// smtpEmailSender delivers email through an SMTP server with basic authentication
+type smtpEmailSender struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+// SendEmail sends the message as a multipart email from the SMTP account
+func (s *smtpEmailSender) SendEmail(message *emailMessage) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.username)
+	m.SetHeader("To", message.To)
+	m.SetHeader("Subject", message.Subject)
+	if message.UnsubscribeURL != "" {
+		m.SetHeader("List-Unsubscribe", fmt.Sprintf("<%s>", message.UnsubscribeURL))
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	m.SetBody("text/plain", message.Text)
+	if message.HTML != "" {
+		m.AddAlternative("text/html", message.HTML)
+	}
+
+	if attachment := message.Attachment; attachment != nil {
+		m.Attach(attachment.Name, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(attachment.Data)
+			return err
+		}))
+	}
+
+	d := gomail.NewDialer(s.host, s.port, s.username, s.password)
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
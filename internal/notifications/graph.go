@@ -0,0 +1,233 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	graphBaseURL = "https://graph.microsoft.com/v1.0"
+	graphScope   = "https://graph.microsoft.com/.default"
+)
+
+// GraphTeamsSender posts channel messages to Microsoft Teams through Microsoft Graph.
+// It replaces the Office 365 connector webhooks, which are being retired.
+type GraphTeamsSender struct {
+	client    *resty.Client
+	tokens    *azauth.TokenSource
+	baseURL   string
+	teamID    string
+	channelID string
+	messages  messages // Translations of NOTIFICATION_LOCALE
+}
+
+// GraphChatMessage represents the body of a Graph channel message
+type GraphChatMessage struct {
+	Subject    string           `json:"subject,omitempty"`
+	Importance string           `json:"importance,omitempty"` // "normal", "high" or "urgent"
+	Body       GraphMessageBody `json:"body"`
+}
+
+type GraphMessageBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+// newGraphTokenSource hands out app-only Graph access tokens, using the app registration
+// client secret when provided, otherwise the default Azure credential chain (workload
+// identity, managed identity, CLI)
+func newGraphTokenSource(cfg *config.Config) (*azauth.TokenSource, error) {
+	var credential azcore.TokenCredential
+	var err error
+
+	if cfg.GraphClientSecret != "" {
+		credential, err = azidentity.NewClientSecretCredential(cfg.GraphTenantID, cfg.GraphClientID, cfg.GraphClientSecret, nil)
+	} else {
+		credential, err = azidentity.NewDefaultAzureCredential(nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Graph credential: %w", err)
+	}
+
+	return azauth.NewTokenSource(credential, graphScope), nil
+}
+
+// NewGraphTeamsSender creates a Graph sender for the configured team and channel.
+// An app registration client secret is used when provided, otherwise the default
+// Azure credential chain (workload identity, managed identity, CLI) is used.
+func NewGraphTeamsSender(cfg *config.Config) (*GraphTeamsSender, error) {
+	tokens, err := newGraphTokenSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphTeamsSender{
+		client:    resty.New().SetTimeout(30 * time.Second),
+		tokens:    tokens,
+		baseURL:   graphBaseURL,
+		teamID:    cfg.TeamsTeamID,
+		channelID: cfg.TeamsChannelID,
+		messages:  catalog(cfg.NotificationLocale),
+	}, nil
+}
+
+// Send posts the report to the configured Teams channel
+func (g *GraphTeamsSender) Send(report *models.Report) error {
+	if err := g.post(buildGraphMessage(report, g.messages)); err != nil {
+		return err
+	}
+
+	logrus.Info("Successfully posted report to Teams channel via Microsoft Graph")
+	return nil
+}
+
+// SendAlert posts an alert to the configured Teams channel
+func (g *GraphTeamsSender) SendAlert(alert *models.Alert) error {
+	if err := g.post(buildGraphAlertMessage(alert)); err != nil {
+		return err
+	}
+
+	logrus.Infof("Posted %s alert to Teams channel via Microsoft Graph", alert.Type)
+	return nil
+}
+
+func (g *GraphTeamsSender) post(message *GraphChatMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	token, err := g.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/teams/%s/channels/%s/messages", g.baseURL, url.PathEscape(g.teamID), url.PathEscape(g.channelID))
+
+	resp, err := g.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetHeader("Content-Type", "application/json").
+		SetBody(message).
+		Post(endpoint)
+
+	if err != nil {
+		return fmt.Errorf("failed to post Graph channel message: %w", err)
+	}
+
+	if resp.StatusCode() != 201 && resp.StatusCode() != 200 {
+		return fmt.Errorf("graph API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+// buildGraphAlertMessage renders an alert as an HTML channel message
+func buildGraphAlertMessage(alert *models.Alert) *GraphChatMessage {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("<h2>%s</h2><p>%s</p>", html.EscapeString(alert.Title), html.EscapeString(alert.Message)))
+	if alert.Mention != nil {
+		content.WriteString(fmt.Sprintf(`<p><a href="%s">%s</a> - %s</p>`,
+			html.EscapeString(mentionLink(*alert.Mention)), html.EscapeString(alert.Mention.Title), html.EscapeString(alert.Mention.Source)))
+	}
+	if len(alert.Actions) > 0 {
+		links := make([]string, 0, len(alert.Actions))
+		for _, action := range alert.Actions {
+			links = append(links, fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(action.URL), html.EscapeString(action.Name)))
+		}
+		content.WriteString("<p>" + strings.Join(links, " | ") + "</p>")
+	}
+
+	return &GraphChatMessage{
+		Subject:    alert.Title,
+		Importance: alertImportance(alert),
+		Body: GraphMessageBody{
+			ContentType: "html",
+			Content:     content.String(),
+		},
+	}
+}
+
+// buildGraphMessage renders the report as an HTML channel message, with its headers and
+// labels in the language of m
+func buildGraphMessage(report *models.Report, m messages) *GraphChatMessage {
+	title := reportTitle(report, m)
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("<h2>%s</h2>", html.EscapeString(title)))
+	content.WriteString(fmt.Sprintf("<p>%s</p>", html.EscapeString(m.sprintf("Found %d mentions in the last %s", report.TotalMentions, m.text(report.Period)))))
+
+	if summary, ok := report.Summary["sentiment"].(map[string]int); ok && len(summary) > 0 {
+		content.WriteString("<p>")
+		var parts []string
+		for sentiment, count := range summary {
+			parts = append(parts, fmt.Sprintf("%s: %d", html.EscapeString(m.sentiment(sentiment)), count))
+		}
+		content.WriteString(strings.Join(parts, " | "))
+		content.WriteString("</p>")
+	}
+
+	if len(report.Mentions) > 0 {
+		limit := 10
+		if len(report.Mentions) < limit {
+			limit = len(report.Mentions)
+		}
+
+		content.WriteString("<ul>")
+		for _, mention := range report.Mentions[:limit] {
+			mentionTitle := mention.Title
+			if mentionTitle == "" {
+				mentionTitle = mention.URL
+			}
+			content.WriteString(fmt.Sprintf(`<li><a href="%s">%s</a> - %s (%s)</li>`,
+				html.EscapeString(mentionLink(mention)), html.EscapeString(mentionTitle),
+				html.EscapeString(mentionSource(mention)), m.date("Jan 2", mention.CreatedAt)))
+		}
+		content.WriteString("</ul>")
+	}
+
+	if omitted := omittedSummary(report); omitted != "" {
+		content.WriteString(fmt.Sprintf("<p>%s</p>", html.EscapeString(omitted)))
+	}
+
+	if len(report.PreviouslyAlerted) > 0 {
+		content.WriteString(fmt.Sprintf("<h3>%s</h3><ul>", html.EscapeString(m.text("Previously Alerted"))))
+		for _, mention := range report.PreviouslyAlerted {
+			content.WriteString(fmt.Sprintf(`<li><a href="%s">%s</a> - %s, %s</li>`,
+				html.EscapeString(mentionLink(mention)), html.EscapeString(mention.Title),
+				html.EscapeString(mentionSource(mention)), alertedWhen(mention)))
+		}
+		content.WriteString("</ul>")
+	}
+
+	if report.ReportURL != "" {
+		content.WriteString(fmt.Sprintf(`<p><a href="%s">%s</a></p>`, html.EscapeString(report.ReportURL), html.EscapeString(m.text("View the full report with charts"))))
+	}
+
+	if len(report.ReleaseInsights) > 0 {
+		content.WriteString(fmt.Sprintf("<h3>%s</h3><ul>", html.EscapeString(m.text("AKS Release Correlation"))))
+		for _, insight := range report.ReleaseInsights {
+			content.WriteString(fmt.Sprintf(`<li>%s (<a href="%s">%s</a>)</li>`,
+				html.EscapeString(insight.Summary), html.EscapeString(insight.ReleaseURL),
+				html.EscapeString(insight.ReleaseName)))
+		}
+		content.WriteString("</ul>")
+	}
+
+	return &GraphChatMessage{
+		Subject: title,
+		Body: GraphMessageBody{
+			ContentType: "html",
+			Content:     content.String(),
+		},
+	}
+}
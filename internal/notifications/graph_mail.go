@@ -0,0 +1,129 @@
+package notifications
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// graphMaxAttachmentSize is the largest attachment sent inline with sendMail. Graph rejects
+// sendMail requests over 4 MB, and base64 encoding grows attachments by a third.
+const graphMaxAttachmentSize = 3 * 1024 * 1024
+
+// GraphMailSender sends email through the Microsoft Graph sendMail API with app-only
+// authentication, for tenants that block basic-auth SMTP. The app needs the Mail.Send
+// application permission, ideally scoped to the sending mailbox with an application access policy.
+type GraphMailSender struct {
+	client  *resty.Client
+	tokens  *azauth.TokenSource
+	baseURL string
+	sender  string // User principal name or ID of the mailbox mail is sent from
+}
+
+type graphSendMailRequest struct {
+	Message         graphMailMessage `json:"message"`
+	SaveToSentItems bool             `json:"saveToSentItems"`
+}
+
+type graphMailMessage struct {
+	Subject      string                `json:"subject"`
+	Body         GraphMessageBody      `json:"body"`
+	ToRecipients []graphMailRecipient  `json:"toRecipients"`
+	Attachments  []graphMailAttachment `json:"attachments,omitempty"`
+}
+
+type graphMailRecipient struct {
+	EmailAddress struct {
+		Address string `json:"address"`
+	} `json:"emailAddress"`
+}
+
+type graphMailAttachment struct {
+	ODataType    string `json:"@odata.type"`
+	Name         string `json:"name"`
+	ContentType  string `json:"contentType"`
+	ContentBytes string `json:"contentBytes"`
+}
+
+// NewGraphMailSender creates a Graph sender for the configured mailbox. An app registration
+// client secret is used when provided, otherwise the default Azure credential chain
+// (workload identity, managed identity, CLI) is used.
+func NewGraphMailSender(cfg *config.Config) (*GraphMailSender, error) {
+	tokens, err := newGraphTokenSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphMailSender{
+		client:  resty.New().SetTimeout(time.Minute),
+		tokens:  tokens,
+		baseURL: graphBaseURL,
+		sender:  cfg.GraphMailSender,
+	}, nil
+}
+
+// SendEmail sends the message from the configured mailbox. Graph messages have a single
+// body, so the HTML version is sent when there is one. Graph only accepts custom "X-"
+// headers, so List-Unsubscribe is not set and the link in the body is the way to opt out.
+func (g *GraphMailSender) SendEmail(message *emailMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	token, err := g.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetHeader("Content-Type", "application/json").
+		SetBody(buildGraphMail(message)).
+		Post(fmt.Sprintf("%s/users/%s/sendMail", g.baseURL, url.PathEscape(g.sender)))
+	if err != nil {
+		return fmt.Errorf("failed to call Graph sendMail: %w", err)
+	}
+
+	if resp.StatusCode() != 202 {
+		return fmt.Errorf("graph sendMail returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+// buildGraphMail converts an email into a sendMail request
+func buildGraphMail(message *emailMessage) *graphSendMailRequest {
+	mail := graphMailMessage{
+		Subject: message.Subject,
+		Body:    GraphMessageBody{ContentType: "text", Content: message.Text},
+	}
+	if message.HTML != "" {
+		mail.Body = GraphMessageBody{ContentType: "html", Content: message.HTML}
+	}
+
+	var recipient graphMailRecipient
+	recipient.EmailAddress.Address = message.To
+	mail.ToRecipients = []graphMailRecipient{recipient}
+
+	if attachment := message.Attachment; attachment != nil {
+		if len(attachment.Data) > graphMaxAttachmentSize {
+			logrus.Warnf("Sending email to %s without %s: %d bytes is over the Graph sendMail limit",
+				message.To, attachment.Name, len(attachment.Data))
+		} else {
+			mail.Attachments = []graphMailAttachment{{
+				ODataType:    "#microsoft.graph.fileAttachment",
+				Name:         attachment.Name,
+				ContentType:  attachment.ContentType,
+				ContentBytes: base64.StdEncoding.EncodeToString(attachment.Data),
+			}}
+		}
+	}
+
+	return &graphSendMailRequest{Message: mail}
+}
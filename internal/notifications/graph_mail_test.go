@@ -0,0 +1,108 @@
+package notifications
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticCredential returns a fixed token and counts how often one was requested
+type staticCredential struct {
+	calls int
+}
+
+func (c *staticCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.calls++
+	return azcore.AccessToken{Token: "graph-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func newTestGraphMailSender(baseURL string, credential *staticCredential) *GraphMailSender {
+	return &GraphMailSender{
+		client:  resty.New(),
+		tokens:  azauth.NewTokenSource(credential, graphScope),
+		baseURL: baseURL,
+		sender:  "aks-bot@contoso.com",
+	}
+}
+
+func TestGraphMailSender_SendEmail(t *testing.T) {
+	var (
+		path string
+		auth string
+		body []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	credential := &staticCredential{}
+	sender := newTestGraphMailSender(server.URL, credential)
+
+	message := &emailMessage{
+		To:             "alice@contoso.com",
+		Subject:        "AKS Mentions Report - Daily (3 mentions)",
+		Text:           "plain",
+		HTML:           "<p>html</p>",
+		UnsubscribeURL: "https://bot.contoso.com/unsubscribe?token=x",
+		Attachment:     &emailAttachment{Name: "report.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.7")},
+	}
+	require.NoError(t, sender.SendEmail(message))
+	require.NoError(t, sender.SendEmail(message))
+
+	assert.Equal(t, "/users/aks-bot@contoso.com/sendMail", path)
+	assert.Equal(t, "Bearer graph-token", auth)
+	assert.Equal(t, 1, credential.calls, "the token should be cached")
+
+	var request graphSendMailRequest
+	require.NoError(t, json.Unmarshal(body, &request))
+	assert.False(t, request.SaveToSentItems)
+	assert.Equal(t, message.Subject, request.Message.Subject)
+	assert.Equal(t, GraphMessageBody{ContentType: "html", Content: "<p>html</p>"}, request.Message.Body)
+	require.Len(t, request.Message.ToRecipients, 1)
+	assert.Equal(t, "alice@contoso.com", request.Message.ToRecipients[0].EmailAddress.Address)
+	require.Len(t, request.Message.Attachments, 1)
+	assert.Equal(t, "#microsoft.graph.fileAttachment", request.Message.Attachments[0].ODataType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF-1.7")), request.Message.Attachments[0].ContentBytes)
+}
+
+func TestGraphMailSender_SendEmailFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":"ErrorAccessDenied"}}`))
+	}))
+	defer server.Close()
+
+	err := newTestGraphMailSender(server.URL, &staticCredential{}).SendEmail(&emailMessage{To: "alice@contoso.com", Text: "plain"})
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestBuildGraphMail(t *testing.T) {
+	// Plain-text recipients get the text body
+	request := buildGraphMail(&emailMessage{To: "bob@contoso.com", Subject: "Report", Text: "plain"})
+	assert.Equal(t, GraphMessageBody{ContentType: "text", Content: "plain"}, request.Message.Body)
+	assert.Empty(t, request.Message.Attachments)
+
+	// Attachments over the sendMail limit are left out rather than failing the email
+	request = buildGraphMail(&emailMessage{
+		To:         "bob@contoso.com",
+		Text:       "plain",
+		Attachment: &emailAttachment{Name: "report.pdf", Data: make([]byte, graphMaxAttachmentSize+1)},
+	})
+	assert.Empty(t, request.Message.Attachments)
+}
@@ -0,0 +1,103 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingCredential can't issue tokens, like a workload identity missing its federation
+type failingCredential struct{}
+
+func (failingCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{}, errors.New("no federated credential")
+}
+
+func newTestGraphTeamsSender(baseURL string, credential azcore.TokenCredential) *GraphTeamsSender {
+	return &GraphTeamsSender{
+		client:    resty.New(),
+		tokens:    azauth.NewTokenSource(credential, graphScope),
+		baseURL:   baseURL,
+		teamID:    "team-1",
+		channelID: "19:general@thread.tacv2",
+		messages:  catalog(""),
+	}
+}
+
+func TestGraphTeamsSender_Send(t *testing.T) {
+	var (
+		paths []string
+		auth  string
+		body  []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		paths = append(paths, r.URL.Path)
+		auth = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	credential := &staticCredential{}
+	sender := newTestGraphTeamsSender(server.URL, credential)
+
+	report := &models.Report{
+		GeneratedAt:   time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+		Period:        "daily",
+		TotalMentions: 1,
+		Mentions:      []models.Mention{{Source: "reddit", Title: "AKS <upgrade>", URL: "https://reddit.com/r/AZURE/1"}},
+	}
+	require.NoError(t, sender.Send(report))
+
+	assert.Equal(t, "Bearer graph-token", auth)
+	var message GraphChatMessage
+	require.NoError(t, json.Unmarshal(body, &message))
+	assert.Equal(t, "html", message.Body.ContentType)
+	assert.Contains(t, message.Body.Content, "AKS &lt;upgrade&gt;")
+
+	require.NoError(t, sender.SendAlert(&models.Alert{Type: "critical", Title: "Negative spike", Message: "12 negative mentions"}))
+	require.NoError(t, json.Unmarshal(body, &message))
+	assert.Equal(t, "Negative spike", message.Subject)
+	assert.Equal(t, "urgent", message.Importance)
+
+	assert.Equal(t, []string{
+		"/teams/team-1/channels/19:general@thread.tacv2/messages",
+		"/teams/team-1/channels/19:general@thread.tacv2/messages",
+	}, paths)
+	assert.Equal(t, 1, credential.calls, "the token should be cached")
+}
+
+func TestGraphTeamsSender_SendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":"Forbidden","message":"Missing role permissions"}}`))
+	}))
+	defer server.Close()
+
+	err := newTestGraphTeamsSender(server.URL, &staticCredential{}).SendAlert(&models.Alert{Type: "urgent", Title: "AKS outage"})
+	assert.ErrorContains(t, err, "status 403")
+	assert.ErrorContains(t, err, "Missing role permissions")
+
+	// Nothing is posted without a token
+	requests := 0
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requests++ }))
+	defer unreachable.Close()
+
+	err = newTestGraphTeamsSender(unreachable.URL, failingCredential{}).Send(&models.Report{Period: "daily"})
+	assert.ErrorContains(t, err, "no federated credential")
+	assert.Zero(t, requests)
+}
@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/matching"
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// highlightMarkdown wraps keyword matches in ** for Teams and plain-text output
+func highlightMarkdown(text string, keywords []string) string {
+	pattern := matching.KeywordPattern(keywords)
+	if pattern == nil {
+		return text
+	}
+	return pattern.ReplaceAllString(text, "**$0**")
+}
+
+// highlightHTML escapes text and wraps keyword matches in <strong>
+func highlightHTML(text string, keywords []string) template.HTML {
+	pattern := matching.KeywordPattern(keywords)
+	if pattern == nil {
+		return template.HTML(template.HTMLEscapeString(text))
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(template.HTMLEscapeString(text[last:loc[0]]))
+		b.WriteString("<strong>")
+		b.WriteString(template.HTMLEscapeString(text[loc[0]:loc[1]]))
+		b.WriteString("</strong>")
+		last = loc[1]
+	}
+	b.WriteString(template.HTMLEscapeString(text[last:]))
+
+	return template.HTML(b.String())
+}
+
+// mentionSnippet returns up to maxLength characters of a mention, preferring the excerpt
+// extracted around its keywords over the full content
+func (s *Service) mentionSnippet(mention models.Mention, maxLength int) string {
+	text := mention.Excerpt
+	if text == "" {
+		text = mention.Content
+	}
+	return matching.KeywordSnippet(text, s.mentionKeywords(mention), maxLength)
+}
+
+// mentionKeywords returns the keywords a mention matched, falling back to all configured keywords
+func (s *Service) mentionKeywords(mention models.Mention) []string {
+	if len(mention.Keywords) > 0 {
+		return mention.Keywords
+	}
+	return s.config.Keywords
+}
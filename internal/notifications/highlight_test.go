@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_mentionSnippet(t *testing.T) {
+	service := &Service{config: &config.Config{Keywords: []string{"AKS"}}}
+
+	mention := models.Mention{Content: strings.Repeat("intro ", 100) + "AKS", Excerpt: "...we moved to AKS."}
+	assert.Equal(t, "...we moved to AKS.", service.mentionSnippet(mention, 200))
+
+	mention.Excerpt = ""
+	assert.Contains(t, service.mentionSnippet(mention, 200), "AKS")
+}
+
+func TestHighlight(t *testing.T) {
+	keywords := []string{"AKS", "Azure Kubernetes Service"}
+
+	assert.Equal(t, "Running **Azure Kubernetes Service** (**aks**) but not tasks",
+		highlightMarkdown("Running Azure Kubernetes Service (aks) but not tasks", keywords))
+	assert.Equal(t, "nothing to see", highlightMarkdown("nothing to see", nil))
+
+	assert.Equal(t, template.HTML("&lt;b&gt; <strong>AKS</strong> &amp; more"), highlightHTML("<b> AKS & more", keywords))
+}
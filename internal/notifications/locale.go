@@ -0,0 +1,235 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// messages translates the fixed text of report notifications (titles, section headers,
+// sentiment labels and date layouts) into one language, keyed by the English text. Text
+// missing from a catalog stays in English, and a nil catalog is English. Mention titles,
+// content and other text from the report itself are never translated.
+type messages map[string]string
+
+// catalogs holds the translations of every locale in config.NotificationLocales but English
+var catalogs = map[string]messages{
+	"de": {
+		"AKS Mentions Report - Weekly (%s - %s)": "AKS-Erwähnungsbericht - Wöchentlich (%s - %s)",
+		"AKS Mentions Report - Daily (%s)":       "AKS-Erwähnungsbericht - Täglich (%s)",
+		"AKS Mentions Report - %s (%s)":          "AKS-Erwähnungsbericht - %s (%s)",
+		"Found %d mentions in the last %s":       "%d Erwähnungen gefunden (%s)",
+		"daily":                                  "täglich",
+		"weekly":                                 "wöchentlich",
+		"Total Mentions":                         "Erwähnungen gesamt",
+		"Generated":                              "Erstellt",
+		"Sentiment Score":                        "Stimmungswert",
+		"Positive":                               "Positiv",
+		"Negative":                               "Negativ",
+		"Neutral":                                "Neutral",
+		"Positive Mentions":                      "Positive Erwähnungen",
+		"Negative Mentions":                      "Negative Erwähnungen",
+		"Neutral Mentions":                       "Neutrale Erwähnungen",
+		"Summary":                                "Zusammenfassung",
+		"Recent Mentions":                        "Aktuelle Erwähnungen",
+		"More Mentions":                          "Weitere Erwähnungen",
+		"Needs an Answer":                        "Wartet auf Antwort",
+		"Resolved Since Last Report":             "Seit dem letzten Bericht beantwortet",
+		"Documentation Gaps in %s":               "Dokumentationslücken %s",
+		"Previously Alerted":                     "Bereits gemeldet",
+		"AKS Release Correlation":                "Zusammenhang mit AKS-Releases",
+		"Notable Negative Comments":              "Auffällige negative Kommentare",
+		"Source Reliability, Last 7 Days (error budget %.0f%%)": "Zuverlässigkeit der Quellen, letzte 7 Tage (Fehlerbudget %.0f%%)",
+		"Skipped Sources":                  "Übersprungene Quellen",
+		"View full report":                 "Vollständigen Bericht anzeigen",
+		"View the full report with charts": "Vollständigen Bericht mit Diagrammen anzeigen",
+		"Compare with previous report":     "Mit vorherigem Bericht vergleichen",
+		"Full report":                      "Vollständiger Bericht",
+		"Showing %d of %d mentions":        "%d von %d Erwähnungen angezeigt",
+		"%s - Batch %d/%d":                 "%s - Teil %d/%d",
+		"Batch %d of %d - %d mentions in this batch (Total: %d)": "Teil %d von %d - %d Erwähnungen in diesem Teil (gesamt: %d)",
+		"Jan 2, 2006": "2.1.2006",
+		"Jan 2":       "2.1.",
+	},
+	"es": {
+		"AKS Mentions Report - Weekly (%s - %s)": "Informe de menciones de AKS - Semanal (%s - %s)",
+		"AKS Mentions Report - Daily (%s)":       "Informe de menciones de AKS - Diario (%s)",
+		"AKS Mentions Report - %s (%s)":          "Informe de menciones de AKS - %s (%s)",
+		"Found %d mentions in the last %s":       "%d menciones encontradas (%s)",
+		"daily":                                  "diario",
+		"weekly":                                 "semanal",
+		"Total Mentions":                         "Total de menciones",
+		"Generated":                              "Generado",
+		"Sentiment Score":                        "Puntuación de sentimiento",
+		"Positive":                               "Positivo",
+		"Negative":                               "Negativo",
+		"Neutral":                                "Neutral",
+		"Positive Mentions":                      "Menciones positivas",
+		"Negative Mentions":                      "Menciones negativas",
+		"Neutral Mentions":                       "Menciones neutrales",
+		"Summary":                                "Resumen",
+		"Recent Mentions":                        "Menciones recientes",
+		"More Mentions":                          "Más menciones",
+		"Needs an Answer":                        "Sin respuesta",
+		"Resolved Since Last Report":             "Resueltas desde el último informe",
+		"Documentation Gaps in %s":               "Lagunas de documentación (%s)",
+		"Previously Alerted":                     "Alertadas anteriormente",
+		"AKS Release Correlation":                "Correlación con versiones de AKS",
+		"Notable Negative Comments":              "Comentarios negativos destacados",
+		"Source Reliability, Last 7 Days (error budget %.0f%%)": "Fiabilidad de las fuentes, últimos 7 días (presupuesto de errores %.0f%%)",
+		"Skipped Sources":                  "Fuentes omitidas",
+		"View full report":                 "Ver el informe completo",
+		"View the full report with charts": "Ver el informe completo con gráficos",
+		"Compare with previous report":     "Comparar con el informe anterior",
+		"Full report":                      "Informe completo",
+		"Showing %d of %d mentions":        "Mostrando %d de %d menciones",
+		"%s - Batch %d/%d":                 "%s - Lote %d/%d",
+		"Batch %d of %d - %d mentions in this batch (Total: %d)": "Lote %d de %d - %d menciones en este lote (total: %d)",
+		"Jan 2, 2006": "2/1/2006",
+		"Jan 2":       "2/1",
+	},
+	"fr": {
+		"AKS Mentions Report - Weekly (%s - %s)": "Rapport des mentions AKS - Hebdomadaire (%s - %s)",
+		"AKS Mentions Report - Daily (%s)":       "Rapport des mentions AKS - Quotidien (%s)",
+		"AKS Mentions Report - %s (%s)":          "Rapport des mentions AKS - %s (%s)",
+		"Found %d mentions in the last %s":       "%d mentions trouvées (%s)",
+		"daily":                                  "quotidien",
+		"weekly":                                 "hebdomadaire",
+		"Total Mentions":                         "Total des mentions",
+		"Generated":                              "Généré le",
+		"Sentiment Score":                        "Score de sentiment",
+		"Positive":                               "Positif",
+		"Negative":                               "Négatif",
+		"Neutral":                                "Neutre",
+		"Positive Mentions":                      "Mentions positives",
+		"Negative Mentions":                      "Mentions négatives",
+		"Neutral Mentions":                       "Mentions neutres",
+		"Summary":                                "Résumé",
+		"Recent Mentions":                        "Mentions récentes",
+		"More Mentions":                          "Autres mentions",
+		"Needs an Answer":                        "En attente de réponse",
+		"Resolved Since Last Report":             "Résolues depuis le dernier rapport",
+		"Documentation Gaps in %s":               "Lacunes de la documentation (%s)",
+		"Previously Alerted":                     "Déjà signalées",
+		"AKS Release Correlation":                "Corrélation avec les versions AKS",
+		"Notable Negative Comments":              "Commentaires négatifs notables",
+		"Source Reliability, Last 7 Days (error budget %.0f%%)": "Fiabilité des sources sur 7 jours (budget d'erreurs %.0f %%)",
+		"Skipped Sources":                  "Sources ignorées",
+		"View full report":                 "Voir le rapport complet",
+		"View the full report with charts": "Voir le rapport complet avec graphiques",
+		"Compare with previous report":     "Comparer avec le rapport précédent",
+		"Full report":                      "Rapport complet",
+		"Showing %d of %d mentions":        "%d mentions affichées sur %d",
+		"%s - Batch %d/%d":                 "%s - Lot %d/%d",
+		"Batch %d of %d - %d mentions in this batch (Total: %d)": "Lot %d sur %d - %d mentions dans ce lot (total : %d)",
+		"Jan 2, 2006": "02/01/2006",
+		"Jan 2":       "02/01",
+	},
+	"ja": {
+		"AKS Mentions Report - Weekly (%s - %s)": "AKS メンションレポート - 週次 (%s - %s)",
+		"AKS Mentions Report - Daily (%s)":       "AKS メンションレポート - 日次 (%s)",
+		"AKS Mentions Report - %s (%s)":          "AKS メンションレポート - %s (%s)",
+		"Found %d mentions in the last %s":       "%d 件のメンションが見つかりました (%s)",
+		"daily":                                  "日次",
+		"weekly":                                 "週次",
+		"Total Mentions":                         "メンション合計",
+		"Generated":                              "生成日時",
+		"Sentiment Score":                        "センチメントスコア",
+		"Positive":                               "ポジティブ",
+		"Negative":                               "ネガティブ",
+		"Neutral":                                "ニュートラル",
+		"Positive Mentions":                      "ポジティブなメンション",
+		"Negative Mentions":                      "ネガティブなメンション",
+		"Neutral Mentions":                       "ニュートラルなメンション",
+		"Summary":                                "概要",
+		"Recent Mentions":                        "最近のメンション",
+		"More Mentions":                          "その他のメンション",
+		"Needs an Answer":                        "未回答の質問",
+		"Resolved Since Last Report":             "前回のレポート以降に解決",
+		"Documentation Gaps in %s":               "ドキュメントの不足 (%s)",
+		"Previously Alerted":                     "アラート送信済み",
+		"AKS Release Correlation":                "AKS リリースとの相関",
+		"Notable Negative Comments":              "注目すべきネガティブなコメント",
+		"Source Reliability, Last 7 Days (error budget %.0f%%)": "ソースの信頼性 (過去 7 日間、エラーバジェット %.0f%%)",
+		"Skipped Sources":                  "スキップされたソース",
+		"View full report":                 "完全なレポートを表示",
+		"View the full report with charts": "グラフ付きの完全なレポートを表示",
+		"Compare with previous report":     "前回のレポートと比較",
+		"Full report":                      "完全なレポート",
+		"Showing %d of %d mentions":        "%[2]d 件中 %[1]d 件を表示",
+		"%s - Batch %d/%d":                 "%s - バッチ %d/%d",
+		"Batch %d of %d - %d mentions in this batch (Total: %d)": "バッチ %d/%d - このバッチのメンション %d 件 (合計: %d)",
+		"Jan 2, 2006": "2006/01/02",
+		"Jan 2":       "01/02",
+	},
+	"pt": {
+		"AKS Mentions Report - Weekly (%s - %s)": "Relatório de menções do AKS - Semanal (%s - %s)",
+		"AKS Mentions Report - Daily (%s)":       "Relatório de menções do AKS - Diário (%s)",
+		"AKS Mentions Report - %s (%s)":          "Relatório de menções do AKS - %s (%s)",
+		"Found %d mentions in the last %s":       "%d menções encontradas (%s)",
+		"daily":                                  "diário",
+		"weekly":                                 "semanal",
+		"Total Mentions":                         "Total de menções",
+		"Generated":                              "Gerado em",
+		"Sentiment Score":                        "Pontuação de sentimento",
+		"Positive":                               "Positivo",
+		"Negative":                               "Negativo",
+		"Neutral":                                "Neutro",
+		"Positive Mentions":                      "Menções positivas",
+		"Negative Mentions":                      "Menções negativas",
+		"Neutral Mentions":                       "Menções neutras",
+		"Summary":                                "Resumo",
+		"Recent Mentions":                        "Menções recentes",
+		"More Mentions":                          "Mais menções",
+		"Needs an Answer":                        "Aguardando resposta",
+		"Resolved Since Last Report":             "Resolvidas desde o último relatório",
+		"Documentation Gaps in %s":               "Lacunas na documentação (%s)",
+		"Previously Alerted":                     "Alertadas anteriormente",
+		"AKS Release Correlation":                "Correlação com versões do AKS",
+		"Notable Negative Comments":              "Comentários negativos relevantes",
+		"Source Reliability, Last 7 Days (error budget %.0f%%)": "Confiabilidade das fontes, últimos 7 dias (orçamento de erros %.0f%%)",
+		"Skipped Sources":                  "Fontes ignoradas",
+		"View full report":                 "Ver relatório completo",
+		"View the full report with charts": "Ver o relatório completo com gráficos",
+		"Compare with previous report":     "Comparar com o relatório anterior",
+		"Full report":                      "Relatório completo",
+		"Showing %d of %d mentions":        "Mostrando %d de %d menções",
+		"%s - Batch %d/%d":                 "%s - Lote %d/%d",
+		"Batch %d of %d - %d mentions in this batch (Total: %d)": "Lote %d de %d - %d menções neste lote (total: %d)",
+		"Jan 2, 2006": "02/01/2006",
+		"Jan 2":       "02/01",
+	},
+}
+
+// catalog returns the translations of a locale, nil for English and unknown locales
+func catalog(locale string) messages {
+	return catalogs[strings.ToLower(locale)]
+}
+
+// text translates a fixed string
+func (m messages) text(english string) string {
+	if translated, ok := m[english]; ok {
+		return translated
+	}
+	return english
+}
+
+// sprintf translates a format string and formats it
+func (m messages) sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(m.text(format), args...)
+}
+
+// date formats t with the locale's version of an English layout such as "Jan 2, 2006"
+func (m messages) date(layout string, t time.Time) string {
+	return t.Format(m.text(layout))
+}
+
+// sentiment labels a sentiment, e.g. "Negativ" for "negative"
+func (m messages) sentiment(sentiment string) string {
+	return m.text(strings.Title(sentiment))
+}
+
+// sentimentMentions names the count of a sentiment's mentions, e.g. "Negative Mentions"
+func (m messages) sentimentMentions(sentiment string) string {
+	return m.text(strings.Title(sentiment) + " Mentions")
+}
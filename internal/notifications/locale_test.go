@@ -0,0 +1,86 @@
+package notifications
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formatVerb matches fmt verbs, with an optional argument index
+var formatVerb = regexp.MustCompile(`%(\[\d+\])?[.0-9]*[a-z%]`)
+
+func TestCatalogs(t *testing.T) {
+	locales := []string{"en"}
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	assert.ElementsMatch(t, config.NotificationLocales, locales, "every configurable locale has a catalog")
+
+	reference := catalogs["de"]
+	for locale, catalog := range catalogs {
+		assert.Len(t, catalog, len(reference), "%s translates the same text as de", locale)
+		for key, translated := range catalog {
+			_, ok := reference[key]
+			assert.True(t, ok, "%s translates %q, which de doesn't", locale, key)
+			assert.Len(t, formatVerb.FindAllString(translated, -1), len(formatVerb.FindAllString(key, -1)),
+				"%s translation of %q keeps its format verbs", locale, key)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	var en messages
+	assert.Equal(t, "Needs an Answer", en.text("Needs an Answer"))
+	assert.Equal(t, "Negative Mentions", en.sentimentMentions("negative"))
+
+	de := catalog("DE")
+	assert.Equal(t, "Wartet auf Antwort", de.text("Needs an Answer"))
+	assert.Equal(t, "Negative Erwähnungen", de.sentimentMentions("negative"))
+	assert.Equal(t, "Mixed", de.sentiment("mixed"), "text without a translation stays English")
+	assert.Equal(t, "3.6.2024", de.date("Jan 2, 2006", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2 件中 1 件を表示", catalog("ja").sprintf("Showing %d of %d mentions", 1, 2))
+	assert.Nil(t, catalog("klingon"))
+}
+
+func TestService_buildSlackMessage_locale(t *testing.T) {
+	service := NewService(&config.Config{})
+	report := &models.Report{
+		GeneratedAt:   time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+		Period:        "daily",
+		TotalMentions: 1,
+		Summary:       map[string]interface{}{"sentiment": map[string]int{"negative": 1}},
+		Mentions: []models.Mention{{
+			Source: "reddit", Title: "AKS upgrade stuck", Content: "Node pool upgrade never finishes",
+			URL: "https://reddit.com/r/AZURE/1", CreatedAt: time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC),
+		}},
+		ReportURL: "https://bot.example.com/reports/1",
+	}
+
+	message := service.buildSlackMessage(report, catalog("de"))
+	require.NotEmpty(t, message.Blocks)
+	assert.Equal(t, "AKS-Erwähnungsbericht - Täglich (3.6.2024)", message.Blocks[0].Text.Text)
+
+	var text strings.Builder
+	for _, block := range message.Blocks {
+		if block.Text != nil {
+			text.WriteString(block.Text.Text + "\n")
+		}
+		for _, element := range block.Elements {
+			text.WriteString(element.Text + "\n")
+		}
+	}
+	assert.Contains(t, text.String(), "1 Erwähnungen gefunden (täglich)")
+	assert.Contains(t, text.String(), "Negativ: 1")
+	assert.Contains(t, text.String(), "AKS upgrade stuck", "mention content stays original")
+	assert.Contains(t, text.String(), "(2.6.)")
+	assert.Contains(t, text.String(), "|Vollständiger Bericht>")
+
+	english := service.buildSlackMessage(report, nil)
+	assert.Equal(t, "AKS Mentions Report - Daily (Jun 3, 2024)", english.Blocks[0].Text.Text)
+}
@@ -0,0 +1,91 @@
+package notifications
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// LogicAppSchemaVersion is the version of the Logic App payload. Version 2 added the schema
+// version, urgency, sentiment, keywords and keyword groups; fields are only ever added within
+// a version, so flows can ignore what they don't use.
+const LogicAppSchemaVersion = "2"
+
+// Urgency of Logic App payloads and mentions when no alert type applies
+const (
+	urgencyNormal = "normal"
+	urgencyUrgent = "urgent"
+)
+
+// buildLogicAppAlert renders an alert in the Logic App report schema, so existing flows can
+// post it unchanged
+func (s *Service) buildLogicAppAlert(alert *models.Alert) *LogicAppMessage {
+	message := &LogicAppMessage{
+		SchemaVersion: LogicAppSchemaVersion,
+		Title:         alert.Title,
+		Summary:       alert.Message,
+		Urgency:       alert.Type,
+		Mentions:      []LogicAppMention{},
+	}
+
+	if alert.Mention != nil {
+		message.Mentions = append(message.Mentions, LogicAppMention{
+			ID:            alert.Mention.ID,
+			ActionURL:     s.mentionActionURL(alert.Mention.ID),
+			Source:        alert.Mention.Source,
+			PostType:      alert.Mention.PostType,
+			Title:         alert.Mention.Title,
+			URL:           alert.Mention.URL,
+			Permalink:     alert.Mention.Permalink,
+			Timestamp:     alert.Mention.CreatedAt.Format("2006-01-02 15:04:05 UTC"),
+			Relevance:     alert.Mention.Relevance,
+			Sentiment:     alert.Mention.Sentiment,
+			Keywords:      alert.Mention.Keywords,
+			KeywordGroups: s.mentionKeywordGroups(*alert.Mention),
+			Urgency:       alert.Type,
+		})
+	}
+	return message
+}
+
+// mentionKeywordGroups returns the sorted names of the keyword groups containing one of the
+// keywords a mention matched
+func (s *Service) mentionKeywordGroups(mention models.Mention) []string {
+	var groups []string
+	for group, keywords := range s.config.KeywordGroups {
+		if matchesAnyKeyword(mention.Keywords, keywords) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func matchesAnyKeyword(matched, keywords []string) bool {
+	for _, keyword := range keywords {
+		for _, m := range matched {
+			if strings.EqualFold(m, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reportUrgency is "urgent" for reports from urgent checks and "normal" for periodic reports
+func reportUrgency(report *models.Report) string {
+	if reportType, _ := report.Summary["type"].(string); reportType == urgencyUrgent {
+		return urgencyUrgent
+	}
+	return urgencyNormal
+}
+
+// mentionUrgency marks the mentions of urgent reports, and those an urgent check already
+// alerted, as urgent
+func mentionUrgency(report *models.Report, mention models.Mention) string {
+	if mention.AlertedAt != nil {
+		return urgencyUrgent
+	}
+	return reportUrgency(report)
+}
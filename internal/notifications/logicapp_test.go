@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_buildLogicAppMessageMetadata(t *testing.T) {
+	service := NewService(&config.Config{
+		KeywordGroups: map[string][]string{
+			"fleet": {"Azure Kubernetes Fleet Manager", "KubeFleet"},
+			"core":  {"AKS"},
+			"kaito": {"KAITO"},
+		},
+		PublicBaseURL:        "https://bot.example.com",
+		InboundWebhookSecret: "secret",
+	})
+	alertedAt := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	report := &models.Report{
+		Period:        "weekly",
+		TotalMentions: 2,
+		Summary:       map[string]interface{}{},
+		Mentions: []models.Mention{
+			{ID: "r1", Source: "reddit", Title: "AKS and kubefleet", Sentiment: "negative", Keywords: []string{"aks", "KubeFleet"},
+				URL: "https://reddit.com/r/AZURE/r1", Permalink: "https://bot.example.com/m/r1"},
+			{ID: "h1", Source: "hackernews", Title: "Upgrades", Sentiment: "neutral", Keywords: []string{"AKS"}, AlertedAt: &alertedAt},
+		},
+	}
+
+	message := service.buildLogicAppMessage(report, nil)
+	assert.Equal(t, LogicAppSchemaVersion, message.SchemaVersion)
+	assert.Equal(t, "normal", message.Urgency)
+	require.Len(t, message.Mentions, 2)
+
+	first := message.Mentions[0]
+	assert.Equal(t, "r1", first.ID)
+	assert.Equal(t, "negative", first.Sentiment)
+	assert.Equal(t, []string{"aks", "KubeFleet"}, first.Keywords)
+	assert.Equal(t, []string{"core", "fleet"}, first.KeywordGroups)
+	assert.Equal(t, "normal", first.Urgency)
+	assert.Equal(t, "https://bot.example.com/api/mentions/r1/actions", first.ActionURL)
+	assert.Equal(t, "https://reddit.com/r/AZURE/r1", first.URL, "Logic Apps get the original post and the mention page")
+	assert.Equal(t, "https://bot.example.com/m/r1", first.Permalink)
+	assert.Contains(t, service.buildTeamsMessage(report, nil).Sections[0].ActivityText, "(https://bot.example.com/m/r1)", "Teams links the mention page")
+	assert.Equal(t, "urgent", message.Mentions[1].Urgency)
+
+	report.Summary["type"] = "urgent"
+	message = service.buildLogicAppMessage(report, nil)
+	assert.Equal(t, "urgent", message.Urgency)
+	assert.Equal(t, "urgent", message.Mentions[0].Urgency)
+
+	payload, err := json.Marshal(message)
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"schema_version":"2"`)
+	assert.Contains(t, string(payload), `"keyword_groups":["core","fleet"]`)
+}
+
+func TestService_buildLogicAppAlert(t *testing.T) {
+	service := NewService(&config.Config{KeywordGroups: map[string][]string{"kaito": {"KAITO"}}})
+	alert := &models.Alert{
+		Type:    "critical",
+		Title:   "Negative sentiment spike",
+		Message: "12 negative mentions in the last hour",
+		Mention: &models.Mention{ID: "x1", Source: "twitter", Sentiment: "negative", Keywords: []string{"kaito"}},
+	}
+
+	message := service.buildLogicAppAlert(alert)
+	assert.Equal(t, LogicAppSchemaVersion, message.SchemaVersion)
+	assert.Equal(t, "critical", message.Urgency)
+	require.Len(t, message.Mentions, 1)
+	assert.Equal(t, "x1", message.Mentions[0].ID)
+	assert.Equal(t, "negative", message.Mentions[0].Sentiment)
+	assert.Equal(t, []string{"kaito"}, message.Mentions[0].KeywordGroups)
+	assert.Equal(t, "critical", message.Mentions[0].Urgency)
+}
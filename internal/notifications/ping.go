@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// PingResult is the outcome of a test message to one notification target
+type PingResult struct {
+	Target string
+	Detail string // How the message was delivered
+	Err    error
+}
+
+// Ping sends a short test message to every configured target: Teams through Graph, each
+// notification channel, and the first email recipient only, so a check doesn't mail the
+// whole list. Failures are not queued for retry.
+func (s *Service) Ping() []PingResult {
+	alert := &models.Alert{
+		ID:        fmt.Sprintf("ping-%d", time.Now().Unix()),
+		Type:      "info",
+		Title:     "AKS Mentions Bot preflight check",
+		Message:   "This is a test message from the AKS Mentions Bot configuration check. No action is needed.",
+		CreatedAt: time.Now(),
+	}
+
+	var results []PingResult
+	if s.config.TeamsDeliveryMode == "graph" && s.config.TeamsEnabled() {
+		results = append(results, PingResult{Target: targetTeamsGraph, Detail: "Microsoft Graph", Err: s.sendAlertToTeams(alert)})
+	}
+
+	for _, channel := range s.config.Channels() {
+		results = append(results, PingResult{Target: channel.Name, Detail: channel.Type, Err: s.sendAlertToChannel(channel, alert)})
+	}
+
+	if len(s.config.EmailRecipients) > 0 {
+		recipient := s.config.EmailRecipients[0].Email
+		err := fmt.Errorf("email %s sender is not initialized", s.config.EmailDeliveryMode)
+		if s.emailSender != nil {
+			err = s.emailSender.SendEmail(&emailMessage{
+				To:      recipient,
+				Subject: alert.Title,
+				Text:    alert.Message + "\n",
+			})
+		}
+		results = append(results, PingResult{Target: emailTargetPrefix + recipient, Detail: s.config.EmailDeliveryMode, Err: err})
+	}
+
+	return results
+}
@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEmailSender struct {
+	sent []*emailMessage
+}
+
+func (r *recordingEmailSender) SendEmail(message *emailMessage) error {
+	r.sent = append(r.sent, message)
+	return nil
+}
+
+func TestService_Ping(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/rejected" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := NewService(&config.Config{
+		NotificationChannels: []config.NotificationChannel{
+			{Name: "teams", Type: config.ChannelTeams, URL: server.URL + "/teams", Format: config.TeamsWebhookFormatMessageCard},
+			{Name: "slack", Type: config.ChannelSlack, URL: server.URL + "/rejected", Retries: 2},
+		},
+		EmailRecipients:   []config.EmailRecipient{{Email: "alice@contoso.com"}, {Email: "bob@contoso.com"}},
+		EmailDeliveryMode: EmailDeliverySMTP,
+	})
+	service.retryDelay = time.Millisecond
+	email := &recordingEmailSender{}
+	service.emailSender = email
+
+	results := service.Ping()
+	require.Len(t, results, 3)
+	assert.Equal(t, "teams", results[0].Target)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "slack", results[1].Target)
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, "email:alice@contoso.com", results[2].Target)
+	assert.NoError(t, results[2].Err)
+
+	// Rejected pings are not retried, and only the first recipient is emailed
+	assert.Equal(t, []string{"/teams", "/rejected"}, paths)
+	require.Len(t, email.sent, 1)
+	assert.Equal(t, "alice@contoso.com", email.sent[0].To)
+}
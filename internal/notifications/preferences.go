@@ -0,0 +1,140 @@
+package notifications
+
+import (
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+)
+
+// preferencesPage is the data rendered by the recipient preferences page
+type preferencesPage struct {
+	Recipient config.EmailRecipient
+	Token     string
+	Groups    []keywordGroupOption
+	PDF       bool // Whether PDF attachments can be requested
+	Message   string
+}
+
+type keywordGroupOption struct {
+	Name     string
+	Keywords []string
+	Selected bool
+}
+
+var preferencesTemplate = template.Must(template.New("preferences").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>AKS Mentions Bot - Email Preferences</title>
+    <style>
+        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px; color: #333; }
+        fieldset { border: 1px solid #ddd; margin-bottom: 15px; }
+        .message { background-color: #f3f2f1; padding: 10px; border-left: 4px solid #0078d4; }
+    </style>
+</head>
+<body>
+    <h1>Email Preferences</h1>
+    <p>Preferences for <strong>{{.Recipient.Email}}</strong></p>
+    {{if .Message}}<p class="message">{{.Message}}</p>{{end}}
+
+    <form method="POST" action="/preferences">
+        <input type="hidden" name="email" value="{{.Recipient.Email}}">
+        <input type="hidden" name="token" value="{{.Token}}">
+
+        <fieldset>
+            <legend>Content</legend>
+            <label><input type="radio" name="content" value="full" {{if eq .Recipient.Content "full"}}checked{{end}}> Full report</label><br>
+            <label><input type="radio" name="content" value="summary" {{if eq .Recipient.Content "summary"}}checked{{end}}> Summary only</label>
+        </fieldset>
+
+        <fieldset>
+            <legend>Format</legend>
+            <label><input type="radio" name="format" value="html" {{if eq .Recipient.Format "html"}}checked{{end}}> HTML</label><br>
+            <label><input type="radio" name="format" value="text" {{if eq .Recipient.Format "text"}}checked{{end}}> Plain text</label>
+        </fieldset>
+
+        {{if .PDF}}
+        <fieldset>
+            <legend>Attachments</legend>
+            <label><input type="checkbox" name="attach_pdf" value="true" {{if .Recipient.AttachPDF}}checked{{end}}> Attach the report as a PDF</label>
+        </fieldset>
+        {{end}}
+
+        {{if .Groups}}
+        <fieldset>
+            <legend>Keyword groups (none selected means all mentions)</legend>
+            {{range .Groups}}
+            <label><input type="checkbox" name="groups" value="{{.Name}}" {{if .Selected}}checked{{end}}> {{.Name}}</label>
+            <small>({{range $i, $k := .Keywords}}{{if $i}}, {{end}}{{$k}}{{end}})</small><br>
+            {{end}}
+        </fieldset>
+        {{end}}
+
+        <fieldset>
+            <legend>Subscription</legend>
+            <label><input type="checkbox" name="unsubscribed" value="true" {{if .Recipient.Unsubscribed}}checked{{end}}> Unsubscribe from email reports</label>
+        </fieldset>
+
+        <button type="submit">Save preferences</button>
+    </form>
+</body>
+</html>
+`))
+
+// unsubscribePage is the data rendered by the unsubscribe page
+type unsubscribePage struct {
+	Email        string
+	Token        string
+	Unsubscribed bool
+}
+
+// unsubscribeTemplate asks to confirm before unsubscribing, since mail scanners and link
+// previews follow the unsubscribe link with GET requests
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>AKS Mentions Bot - Unsubscribe</title>
+    <style>
+        body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px; color: #333; }
+    </style>
+</head>
+<body>
+    <h1>Unsubscribe</h1>
+    {{if .Unsubscribed}}
+    <p><strong>{{.Email}}</strong> has been unsubscribed from AKS Mentions Bot email reports.</p>
+    {{else}}
+    <p>Stop sending AKS Mentions Bot email reports to <strong>{{.Email}}</strong>?</p>
+    <form method="POST" action="/unsubscribe">
+        <input type="hidden" name="email" value="{{.Email}}">
+        <input type="hidden" name="token" value="{{.Token}}">
+        <button type="submit">Unsubscribe</button>
+    </form>
+    {{end}}
+</body>
+</html>
+`))
+
+// RenderUnsubscribePage writes the unsubscribe confirmation form for a recipient, or the
+// confirmation that they have been unsubscribed
+func (s *Service) RenderUnsubscribePage(w io.Writer, email, token string, unsubscribed bool) error {
+	return unsubscribeTemplate.Execute(w, unsubscribePage{Email: email, Token: token, Unsubscribed: unsubscribed})
+}
+
+// RenderPreferencesPage writes the preferences form for a recipient
+func (s *Service) RenderPreferencesPage(w io.Writer, recipient config.EmailRecipient, token, message string) error {
+	selected := make(map[string]bool)
+	for _, group := range recipient.KeywordGroups {
+		selected[group] = true
+	}
+
+	page := preferencesPage{Recipient: recipient, Token: token, PDF: s.config.EnablePDFReports, Message: message}
+	for name, keywords := range s.config.KeywordGroups {
+		page.Groups = append(page.Groups, keywordGroupOption{Name: name, Keywords: keywords, Selected: selected[name]})
+	}
+	sort.Slice(page.Groups, func(a, b int) bool { return page.Groups[a].Name < page.Groups[b].Name })
+
+	return preferencesTemplate.Execute(w, page)
+}
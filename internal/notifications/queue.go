@@ -0,0 +1,383 @@
+package notifications
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// deliveryQueuePrefix holds the deliveries waiting for another attempt
+	deliveryQueuePrefix = "notifications/queue/"
+	// failedDeliveryPrefix holds the deliveries whose attempts ran out
+	failedDeliveryPrefix = "notifications/failed/"
+	// failedDeliveryRetention is how long failed deliveries stay listed in the queue status
+	failedDeliveryRetention = 30 * 24 * time.Hour
+	// deliveryClaimTimeout is how long a claimed delivery is left to the process retrying it
+	// before others may retry it, should that process stop before updating it
+	deliveryClaimTimeout = 10 * time.Minute
+)
+
+// Kinds of queued deliveries
+const (
+	QueueKindReport = "report"
+	QueueKindAlert  = "alert"
+)
+
+// targetTeamsGraph is the delivery target of Teams channel messages posted through Graph;
+// email targets are "email:<address>" and webhook channels are targeted by name
+const (
+	targetTeamsGraph  = "teams-graph"
+	emailTargetPrefix = "email:"
+)
+
+// errTargetRemoved is returned when a queued delivery's target is no longer configured
+var errTargetRemoved = errors.New("delivery target is no longer configured")
+
+// deliveryFailure is a report or alert that could not be delivered to one target
+type deliveryFailure struct {
+	target string
+	err    error
+}
+
+func emailTarget(email string) string {
+	return emailTargetPrefix + strings.ToLower(email)
+}
+
+// QueuedDelivery is a report or alert that failed to reach one target and waits to be retried
+type QueuedDelivery struct {
+	ID          string         `json:"id"`
+	Kind        string         `json:"kind"`   // QueueKindReport or QueueKindAlert
+	Target      string         `json:"target"` // "teams-graph", "email:<address>" or a channel name
+	Title       string         `json:"title"`
+	Report      *models.Report `json:"report,omitempty"`
+	Alert       *models.Alert  `json:"alert,omitempty"`
+	Attempts    int            `json:"attempts"` // Queued attempts so far, not counting the original send
+	CreatedAt   time.Time      `json:"created_at"`
+	NextAttempt time.Time      `json:"next_attempt"`
+	LastError   string         `json:"last_error"`
+	FailedAt    *time.Time     `json:"failed_at,omitempty"` // Set once the attempts ran out
+}
+
+// QueueStatus summarises the delivery queue for the status API and metrics
+type QueueStatus struct {
+	Enabled bool             `json:"enabled"`
+	Pending []QueuedDelivery `json:"pending"` // Deliveries waiting to be retried, without their payloads
+	Failed  []QueuedDelivery `json:"failed"`  // Deliveries given up on in the last 30 days, without their payloads
+}
+
+// deliveryQueue persists failed deliveries in storage. The scheduler and the report and
+// urgent CronJobs can retry the queue at the same time, so each delivery is claimed with a
+// conditional write before it is sent, and sent by whichever process claims it first.
+type deliveryQueue struct {
+	store storage.StorageInterface
+	mu    sync.Mutex // Serialises retry passes within the process
+}
+
+// WithDeliveryQueue queues deliveries that fail in storage, for RetryQueued to retry with
+// backoff. NOTIFICATION_QUEUE_MAX_ATTEMPTS=0 leaves the queue off.
+func (s *Service) WithDeliveryQueue(store storage.StorageInterface) *Service {
+	s.queue = &deliveryQueue{store: store}
+	return s
+}
+
+func (s *Service) queueEnabled() bool {
+	return s.queue != nil && s.config.NotificationQueueMaxAttempts > 0
+}
+
+// queueFailures queues each failed delivery for retry and describes the failures for the
+// returned error
+func (s *Service) queueFailures(kind string, report *models.Report, alert *models.Alert, failures []deliveryFailure) string {
+	messages := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		message := fmt.Sprintf("%s: %v", failure.target, failure.err)
+		if s.queueEnabled() {
+			if err := s.enqueue(kind, failure.target, report, alert, failure.err); err != nil {
+				logrus.Errorf("Failed to queue %s for %s, it will not be retried: %v", kind, failure.target, err)
+			} else {
+				message += " (queued for retry)"
+			}
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (s *Service) enqueue(kind, target string, report *models.Report, alert *models.Alert, cause error) error {
+	now := time.Now().UTC()
+	delivery := QueuedDelivery{
+		ID:          newDeliveryID(now),
+		Kind:        kind,
+		Target:      target,
+		Report:      report,
+		Alert:       alert,
+		CreatedAt:   now,
+		NextAttempt: now.Add(s.config.NotificationQueueBackoff),
+		LastError:   cause.Error(),
+	}
+	if report != nil {
+		delivery.Title = reportTitle(report, nil)
+	}
+	if alert != nil {
+		delivery.Title = alert.Title
+	}
+	return s.queue.save(deliveryQueuePrefix, delivery)
+}
+
+// RetryQueued attempts the queued deliveries that are due, returning how many were delivered
+// and how many ran out of attempts. Each failed attempt doubles the wait before the next.
+func (s *Service) RetryQueued() (delivered, failed int, err error) {
+	if !s.queueEnabled() {
+		return 0, 0, nil
+	}
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+
+	deliveries, err := s.queue.load(deliveryQueuePrefix)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, delivery := range deliveries {
+		now := time.Now().UTC()
+		if delivery.NextAttempt.After(now) {
+			continue
+		}
+		claimed, err := s.queue.claim(delivery, now)
+		if err != nil {
+			logrus.Warnf("Failed to claim queued %s %s, leaving it for the next pass: %v", delivery.Kind, delivery.ID, err)
+			continue
+		}
+		if !claimed {
+			logrus.Debugf("Queued %s %s is being retried by another process", delivery.Kind, delivery.ID)
+			continue
+		}
+
+		sendErr := s.deliver(delivery)
+		if sendErr == nil {
+			logrus.Infof("Delivered queued %s %q to %s after %d retries", delivery.Kind, delivery.Title, delivery.Target, delivery.Attempts+1)
+			if err := s.queue.store.Delete(deliveryQueuePrefix + delivery.ID + ".json"); err != nil {
+				logrus.Warnf("Failed to remove delivered %s %s from the queue: %v", delivery.Kind, delivery.ID, err)
+			}
+			delivered++
+			continue
+		}
+
+		delivery.Attempts++
+		delivery.LastError = sendErr.Error()
+		if delivery.Attempts < s.config.NotificationQueueMaxAttempts && !errors.Is(sendErr, errTargetRemoved) {
+			delivery.NextAttempt = now.Add(s.config.NotificationQueueBackoff << delivery.Attempts)
+			logrus.Warnf("Queued %s %q to %s failed again, next attempt at %s: %v",
+				delivery.Kind, delivery.Title, delivery.Target, delivery.NextAttempt.Format(time.RFC3339), sendErr)
+			if err := s.queue.save(deliveryQueuePrefix, delivery); err != nil {
+				logrus.Errorf("Failed to update queued %s %s: %v", delivery.Kind, delivery.ID, err)
+			}
+			continue
+		}
+
+		logrus.Errorf("Giving up on %s %q to %s after %d retries: %v", delivery.Kind, delivery.Title, delivery.Target, delivery.Attempts, sendErr)
+		delivery.FailedAt = &now
+		if err := s.queue.save(failedDeliveryPrefix, delivery); err != nil {
+			logrus.Errorf("Failed to record failed %s %s: %v", delivery.Kind, delivery.ID, err)
+			continue
+		}
+		if err := s.queue.store.Delete(deliveryQueuePrefix + delivery.ID + ".json"); err != nil {
+			logrus.Warnf("Failed to remove failed %s %s from the queue: %v", delivery.Kind, delivery.ID, err)
+		}
+		failed++
+	}
+
+	s.queue.pruneFailed(time.Now().Add(-failedDeliveryRetention))
+	return delivered, failed, nil
+}
+
+// deliver sends a queued report or alert to its target again
+func (s *Service) deliver(delivery QueuedDelivery) error {
+	if (delivery.Kind == QueueKindReport && delivery.Report == nil) || (delivery.Kind == QueueKindAlert && delivery.Alert == nil) {
+		return fmt.Errorf("%w: queued %s has no payload", errTargetRemoved, delivery.Kind)
+	}
+
+	switch {
+	case delivery.Target == targetTeamsGraph:
+		if s.config.TeamsDeliveryMode != "graph" || !s.config.TeamsEnabled() {
+			break
+		}
+		if delivery.Kind == QueueKindAlert {
+			return s.sendAlertToTeams(delivery.Alert)
+		}
+		return s.sendToTeams(delivery.Report)
+	case strings.HasPrefix(delivery.Target, emailTargetPrefix):
+		if delivery.Kind != QueueKindReport {
+			break
+		}
+		return s.resendEmail(delivery.Report, strings.TrimPrefix(delivery.Target, emailTargetPrefix))
+	default:
+		for _, channel := range s.config.Channels() {
+			if channel.Name != delivery.Target {
+				continue
+			}
+			if delivery.Kind == QueueKindAlert {
+				return s.sendAlertToChannel(channel, delivery.Alert)
+			}
+			return s.sendReportToChannel(channel, delivery.Report)
+		}
+	}
+	return fmt.Errorf("%w: %s", errTargetRemoved, delivery.Target)
+}
+
+// QueueStatus lists the deliveries waiting for a retry and those given up on
+func (s *Service) QueueStatus() (*QueueStatus, error) {
+	status := &QueueStatus{Enabled: s.queueEnabled(), Pending: []QueuedDelivery{}, Failed: []QueuedDelivery{}}
+	if s.queue == nil {
+		return status, nil
+	}
+
+	for prefix, list := range map[string]*[]QueuedDelivery{deliveryQueuePrefix: &status.Pending, failedDeliveryPrefix: &status.Failed} {
+		deliveries, err := s.queue.load(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, delivery := range deliveries {
+			delivery.Report, delivery.Alert = nil, nil
+			*list = append(*list, delivery)
+		}
+	}
+	return status, nil
+}
+
+// claim pushes a due delivery's next attempt past deliveryClaimTimeout with a conditional
+// write, reporting false when another process retried or claimed it since it was read. With
+// a store that can't write conditionally, every delivery is claimed.
+func (q *deliveryQueue) claim(delivery QueuedDelivery, now time.Time) (bool, error) {
+	conditional, ok := q.store.(storage.ConditionalStorage)
+	if !ok {
+		return true, nil
+	}
+
+	name := deliveryQueuePrefix + delivery.ID + ".json"
+	data, version, err := conditional.RetrieveVersion(name)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return true, nil
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var current QueuedDelivery
+	if err := json.Unmarshal(data, &current); err != nil {
+		return false, fmt.Errorf("failed to parse queued delivery %s: %w", name, err)
+	}
+	if current.NextAttempt.After(now) {
+		return false, nil
+	}
+
+	current.NextAttempt = now.Add(deliveryClaimTimeout)
+	data, err = json.Marshal(current)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal queued %s: %w", current.Kind, err)
+	}
+	err = conditional.StoreIfVersion(name, data, version)
+	if errors.Is(err, storage.ErrConditionNotMet) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim queued %s: %w", current.Kind, err)
+	}
+	return true, nil
+}
+
+func (q *deliveryQueue) save(prefix string, delivery QueuedDelivery) error {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queued %s: %w", delivery.Kind, err)
+	}
+	if err := q.store.Store(prefix+delivery.ID+".json", data); err != nil {
+		return fmt.Errorf("failed to store queued %s: %w", delivery.Kind, err)
+	}
+	return nil
+}
+
+// load reads the deliveries under prefix, oldest first; unreadable entries are skipped
+func (q *deliveryQueue) load(prefix string) ([]QueuedDelivery, error) {
+	names, err := q.store.List(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued deliveries: %w", err)
+	}
+
+	deliveries := make([]QueuedDelivery, 0, len(names))
+	for _, name := range names {
+		data, err := q.store.Retrieve(name)
+		if err != nil {
+			logrus.Warnf("Failed to read queued delivery %s: %v", name, err)
+			continue
+		}
+		var delivery QueuedDelivery
+		if err := json.Unmarshal(data, &delivery); err != nil {
+			logrus.Warnf("Failed to parse queued delivery %s: %v", name, err)
+			continue
+		}
+		if delivery.Report != nil {
+			restoreCounts(delivery.Report)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	return deliveries, nil
+}
+
+// pruneFailed deletes failed deliveries recorded before cutoff
+func (q *deliveryQueue) pruneFailed(cutoff time.Time) {
+	deliveries, err := q.load(failedDeliveryPrefix)
+	if err != nil {
+		logrus.Warnf("Failed to prune failed deliveries: %v", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		if delivery.FailedAt != nil && delivery.FailedAt.Before(cutoff) {
+			if err := q.store.Delete(failedDeliveryPrefix + delivery.ID + ".json"); err != nil {
+				logrus.Warnf("Failed to prune failed delivery %s: %v", delivery.ID, err)
+			}
+		}
+	}
+}
+
+// restoreCounts turns the count maps of a report summary decoded from JSON back into the
+// map[string]int the renderers expect
+func restoreCounts(report *models.Report) {
+	for _, key := range []string{"sentiment", "sources", omittedSummaryKey} {
+		decoded, ok := report.Summary[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		counts := make(map[string]int, len(decoded))
+		for name, value := range decoded {
+			if count, ok := value.(float64); ok {
+				counts[name] = int(count)
+			}
+		}
+		report.Summary[key] = counts
+	}
+}
+
+// newDeliveryID returns a sortable unique ID, e.g. "20240603T090000-1a2b3c4d"
+func newDeliveryID(now time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return now.Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,212 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// channelServer accepts posts only while up, recording the bodies it accepted
+type channelServer struct {
+	mu       sync.Mutex
+	up       bool
+	accepted [][]byte
+}
+
+func (c *channelServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.up {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	c.accepted = append(c.accepted, body)
+	w.WriteHeader(http.StatusOK)
+}
+
+// dueNow makes every queued delivery due for its next attempt
+func dueNow(t *testing.T, service *Service) {
+	deliveries, err := service.queue.load(deliveryQueuePrefix)
+	require.NoError(t, err)
+	for _, delivery := range deliveries {
+		delivery.NextAttempt = time.Now().Add(-time.Second)
+		require.NoError(t, service.queue.save(deliveryQueuePrefix, delivery))
+	}
+}
+
+func TestService_RetryQueued(t *testing.T) {
+	down := &channelServer{}
+	server := httptest.NewServer(down)
+	defer server.Close()
+	healthy := &channelServer{up: true}
+	healthyServer := httptest.NewServer(healthy)
+	defer healthyServer.Close()
+
+	service := NewService(&config.Config{
+		NotificationChannels: []config.NotificationChannel{
+			{Name: "teams", Type: config.ChannelTeams, URL: server.URL, Format: config.TeamsWebhookFormatMessageCard},
+			{Name: "slack", Type: config.ChannelSlack, URL: healthyServer.URL},
+		},
+		NotificationQueueMaxAttempts: 3,
+		NotificationQueueBackoff:     5 * time.Minute,
+	}).WithDeliveryQueue(testutil.NewMemoryStorage())
+	service.retryDelay = time.Millisecond
+
+	report := &models.Report{
+		GeneratedAt:   time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+		Period:        "daily",
+		TotalMentions: 2,
+		Summary:       map[string]interface{}{"sentiment": map[string]int{"positive": 2}},
+		Mentions:      []models.Mention{{ID: "r1", Source: "reddit", Title: "AKS upgrade"}, {ID: "h1", Source: "hackernews", Title: "AKS costs"}},
+	}
+
+	err := service.SendReport(report)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "teams: webhook returned status 502")
+	assert.Contains(t, err.Error(), "(queued for retry)")
+	assert.Len(t, healthy.accepted, 1)
+
+	status, err := service.QueueStatus()
+	require.NoError(t, err)
+	require.Len(t, status.Pending, 1)
+	assert.Equal(t, "teams", status.Pending[0].Target)
+	assert.Equal(t, QueueKindReport, status.Pending[0].Kind)
+	assert.Nil(t, status.Pending[0].Report, "the status leaves out payloads")
+
+	// Not due yet
+	delivered, failed, err := service.RetryQueued()
+	require.NoError(t, err)
+	assert.Zero(t, delivered+failed)
+
+	// Still down: the delivery waits twice as long for its next attempt
+	dueNow(t, service)
+	delivered, failed, err = service.RetryQueued()
+	require.NoError(t, err)
+	assert.Zero(t, delivered+failed)
+	deliveries, err := service.queue.load(deliveryQueuePrefix)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), deliveries[0].NextAttempt, time.Minute)
+
+	// Back up: delivered once, to the channel that failed
+	down.up = true
+	dueNow(t, service)
+	delivered, failed, err = service.RetryQueued()
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Zero(t, failed)
+	assert.Len(t, healthy.accepted, 1)
+	require.Len(t, down.accepted, 1)
+
+	var card TeamsMessage
+	require.NoError(t, json.Unmarshal(down.accepted[0], &card))
+	require.NotEmpty(t, card.Sections)
+	assert.Equal(t, "Summary", card.Sections[0].ActivityTitle, "sentiment counts survive the queue")
+
+	status, err = service.QueueStatus()
+	require.NoError(t, err)
+	assert.Empty(t, status.Pending)
+	assert.Empty(t, status.Failed)
+}
+
+func TestService_RetryQueuedGivesUp(t *testing.T) {
+	down := &channelServer{}
+	server := httptest.NewServer(down)
+	defer server.Close()
+
+	cfg := &config.Config{
+		NotificationChannels: []config.NotificationChannel{
+			{Name: "flow", Type: config.ChannelLogicApp, URL: server.URL, Format: config.TeamsWebhookFormatLogicApp},
+			{Name: "old", Type: config.ChannelWebhook, URL: server.URL},
+		},
+		NotificationQueueMaxAttempts: 2,
+		NotificationQueueBackoff:     time.Minute,
+	}
+	service := NewService(cfg).WithDeliveryQueue(testutil.NewMemoryStorage())
+	service.retryDelay = time.Millisecond
+
+	require.Error(t, service.SendAlert(&models.Alert{Type: "urgent", Title: "AKS outage"}))
+
+	// A channel removed from the configuration is given up on at once
+	cfg.NotificationChannels = cfg.NotificationChannels[:1]
+	dueNow(t, service)
+	delivered, failed, err := service.RetryQueued()
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	assert.Equal(t, 1, failed)
+
+	dueNow(t, service)
+	_, failed, err = service.RetryQueued()
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+
+	status, err := service.QueueStatus()
+	require.NoError(t, err)
+	assert.Empty(t, status.Pending)
+	require.Len(t, status.Failed, 2)
+	for _, delivery := range status.Failed {
+		assert.Equal(t, "AKS outage", delivery.Title)
+		assert.NotNil(t, delivery.FailedAt)
+	}
+}
+
+func TestDeliveryQueue_claim(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	queue := &deliveryQueue{store: store}
+	now := time.Now().UTC()
+	require.NoError(t, queue.save(deliveryQueuePrefix, QueuedDelivery{ID: "1", Kind: QueueKindAlert, NextAttempt: now.Add(-time.Minute)}))
+
+	// Two processes read the queue before either retries the delivery
+	deliveries, err := queue.load(deliveryQueuePrefix)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	other := &deliveryQueue{store: store}
+
+	claimed, err := queue.claim(deliveries[0], now)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = other.claim(deliveries[0], now)
+	require.NoError(t, err)
+	assert.False(t, claimed, "only one process retries a delivery")
+
+	// A claim left by a process that stopped expires
+	claimed, err = other.claim(deliveries[0], now.Add(deliveryClaimTimeout+time.Second))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Deliveries removed by another process are skipped
+	require.NoError(t, store.Delete(deliveryQueuePrefix+"1.json"))
+	claimed, err = queue.claim(deliveries[0], now)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestService_SendReportWithoutQueue(t *testing.T) {
+	server := httptest.NewServer(&channelServer{})
+	defer server.Close()
+
+	service := NewService(&config.Config{
+		NotificationChannels: []config.NotificationChannel{{Name: "teams", Type: config.ChannelTeams, URL: server.URL, Format: config.TeamsWebhookFormatMessageCard}},
+	}).WithDeliveryQueue(testutil.NewMemoryStorage())
+
+	err := service.SendReport(&models.Report{Period: "daily"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "queued")
+
+	status, err := service.QueueStatus()
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.Empty(t, status.Pending)
+}
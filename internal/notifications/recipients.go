@@ -0,0 +1,267 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+)
+
+// preferencesBlob holds recipient preference changes made through the preferences page
+const preferencesBlob = "preferences/recipients.json"
+
+var (
+	// ErrPreferencesDisabled is returned when preference links are not configured
+	ErrPreferencesDisabled = errors.New("recipient preferences are not enabled")
+	// ErrInvalidPreferencesToken is returned when a preferences link has been tampered with
+	ErrInvalidPreferencesToken = errors.New("invalid preferences token")
+	// ErrUnknownRecipient is returned for addresses that are not configured recipients
+	ErrUnknownRecipient = errors.New("unknown recipient")
+	// ErrInvalidPreferences is returned when submitted preferences fail validation
+	ErrInvalidPreferences = errors.New("invalid preferences")
+)
+
+// preferenceStore persists per-recipient preference overrides keyed by lowercase email
+type preferenceStore struct {
+	store storage.StorageInterface
+	mu    sync.Mutex
+}
+
+func (p *preferenceStore) load() (map[string]config.EmailRecipient, error) {
+	overrides := make(map[string]config.EmailRecipient)
+
+	data, found, err := storage.RetrieveIfExists(p.store, preferencesBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve recipient preferences: %w", err)
+	}
+	if !found {
+		return overrides, nil
+	}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse recipient preferences: %w", err)
+	}
+	return overrides, nil
+}
+
+func (p *preferenceStore) save(recipient config.EmailRecipient) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	overrides, err := p.load()
+	if err != nil {
+		return err
+	}
+	overrides[strings.ToLower(recipient.Email)] = recipient
+
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recipient preferences: %w", err)
+	}
+	if err := p.store.Store(preferencesBlob, data); err != nil {
+		return fmt.Errorf("failed to store recipient preferences: %w", err)
+	}
+	return nil
+}
+
+// WithPreferenceStore persists preference changes made through the preferences page
+func (s *Service) WithPreferenceStore(store storage.StorageInterface) *Service {
+	s.preferences = &preferenceStore{store: store}
+	return s
+}
+
+// PreferencesToken signs a recipient address for use in preference and unsubscribe links
+func PreferencesToken(secret, email string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.ToLower(email)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PreferencesEnabled reports whether recipients can manage their own preferences
+func (s *Service) PreferencesEnabled() bool {
+	return s.config.PreferencesSecret != "" && s.config.PublicBaseURL != "" && s.preferences != nil
+}
+
+// Recipients returns the configured email recipients with any saved preference changes applied
+func (s *Service) Recipients() ([]config.EmailRecipient, error) {
+	recipients := make([]config.EmailRecipient, len(s.config.EmailRecipients))
+	copy(recipients, s.config.EmailRecipients)
+
+	if s.preferences == nil {
+		return recipients, nil
+	}
+
+	overrides, err := s.preferences.load()
+	if err != nil {
+		return recipients, err
+	}
+
+	for i, recipient := range recipients {
+		if override, ok := overrides[strings.ToLower(recipient.Email)]; ok {
+			override.Email = recipient.Email
+			recipients[i] = override
+		}
+	}
+	return recipients, nil
+}
+
+// RecipientPreferences returns the current preferences for a signed recipient address
+func (s *Service) RecipientPreferences(email, token string) (*config.EmailRecipient, error) {
+	if err := s.checkPreferencesToken(email, token); err != nil {
+		return nil, err
+	}
+
+	recipients, err := s.Recipients()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recipient := range recipients {
+		if strings.EqualFold(recipient.Email, email) {
+			return &recipient, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownRecipient, email)
+}
+
+// UpdateRecipientPreferences validates and saves new preferences for a signed recipient address
+func (s *Service) UpdateRecipientPreferences(email, token string, update config.EmailRecipient) (*config.EmailRecipient, error) {
+	current, err := s.RecipientPreferences(email, token)
+	if err != nil {
+		return nil, err
+	}
+
+	update.Email = current.Email
+	if err := s.config.ValidateRecipient(update); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreferences, err)
+	}
+
+	if err := s.preferences.save(update); err != nil {
+		return nil, err
+	}
+	return &update, nil
+}
+
+// Unsubscribe stops email reports for a signed recipient address
+func (s *Service) Unsubscribe(email, token string) error {
+	current, err := s.RecipientPreferences(email, token)
+	if err != nil {
+		return err
+	}
+
+	current.Unsubscribed = true
+	return s.preferences.save(*current)
+}
+
+func (s *Service) checkPreferencesToken(email, token string) error {
+	if !s.PreferencesEnabled() {
+		return ErrPreferencesDisabled
+	}
+
+	expected := PreferencesToken(s.config.PreferencesSecret, email)
+	if !hmac.Equal([]byte(expected), []byte(token)) {
+		return ErrInvalidPreferencesToken
+	}
+	return nil
+}
+
+// preferenceLinks returns the signed preferences and unsubscribe URLs for a recipient
+func (s *Service) preferenceLinks(email string) (preferencesURL, unsubscribeURL string) {
+	if !s.PreferencesEnabled() {
+		return "", ""
+	}
+
+	query := url.Values{}
+	query.Set("email", email)
+	query.Set("token", PreferencesToken(s.config.PreferencesSecret, email))
+
+	return fmt.Sprintf("%s/preferences?%s", s.config.PublicBaseURL, query.Encode()),
+		fmt.Sprintf("%s/unsubscribe?%s", s.config.PublicBaseURL, query.Encode())
+}
+
+// reportForRecipient narrows a report to a recipient's keyword groups and content preference
+func (s *Service) reportForRecipient(report *models.Report, recipient config.EmailRecipient) *models.Report {
+	tailored := *report
+
+	if len(recipient.KeywordGroups) > 0 {
+		keywords := make(map[string]bool)
+		for _, group := range recipient.KeywordGroups {
+			for _, keyword := range s.config.KeywordGroups[group] {
+				keywords[strings.ToLower(keyword)] = true
+			}
+		}
+
+		tailored.Mentions = filterByKeywords(report.Mentions, keywords)
+		tailored.Unanswered = filterByKeywords(report.Unanswered, keywords)
+		tailored.Resolved = filterResolvedByKeywords(report.Resolved, keywords)
+		tailored.PreviouslyAlerted = filterByKeywords(report.PreviouslyAlerted, keywords)
+		counted := append(append([]models.Mention{}, tailored.Mentions...), tailored.PreviouslyAlerted...)
+		tailored.TotalMentions = len(counted)
+		tailored.Summary = groupSummary(counted, report.Summary)
+	}
+
+	if recipient.Content == config.EmailContentSummary {
+		tailored.Mentions = nil
+		tailored.NegativeComments = nil
+		tailored.Unanswered = nil
+		tailored.Resolved = nil
+		tailored.DocsGaps = nil
+		tailored.PreviouslyAlerted = nil
+	}
+
+	return &tailored
+}
+
+// filterByKeywords keeps mentions that matched any of the given lowercase keywords
+func filterByKeywords(mentions []models.Mention, keywords map[string]bool) []models.Mention {
+	var filtered []models.Mention
+	for _, mention := range mentions {
+		for _, keyword := range mention.Keywords {
+			if keywords[strings.ToLower(keyword)] {
+				filtered = append(filtered, mention)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// filterResolvedByKeywords keeps resolved questions that matched any of the given lowercase keywords
+func filterResolvedByKeywords(questions []models.ResolvedQuestion, keywords map[string]bool) []models.ResolvedQuestion {
+	var filtered []models.ResolvedQuestion
+	for _, question := range questions {
+		for _, keyword := range question.Mention.Keywords {
+			if keywords[strings.ToLower(keyword)] {
+				filtered = append(filtered, question)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// groupSummary recomputes the per-source and sentiment counts for a filtered mention list
+func groupSummary(mentions []models.Mention, original map[string]interface{}) map[string]interface{} {
+	summary := make(map[string]interface{}, len(original))
+	for key, value := range original {
+		summary[key] = value
+	}
+
+	sourceCount := make(map[string]int)
+	sentimentCount := make(map[string]int)
+	for _, mention := range mentions {
+		sourceCount[mention.Source]++
+		sentimentCount[mention.Sentiment]++
+	}
+	summary["sources"] = sourceCount
+	summary["sentiment"] = sentimentCount
+	return summary
+}
@@ -0,0 +1,122 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPreferencesTestService() *Service {
+	cfg := &config.Config{
+		EmailRecipients:   []config.EmailRecipient{config.NewEmailRecipient("alice@contoso.com")},
+		KeywordGroups:     map[string][]string{"fleet": {"KubeFleet"}},
+		PublicBaseURL:     "https://bot.example.com",
+		PreferencesSecret: "secret",
+	}
+	return NewService(cfg).WithPreferenceStore(testutil.NewMemoryStorage())
+}
+
+func TestService_RecipientPreferences(t *testing.T) {
+	service := newPreferencesTestService()
+	token := PreferencesToken("secret", "alice@contoso.com")
+
+	_, err := service.RecipientPreferences("alice@contoso.com", "bogus")
+	assert.ErrorIs(t, err, ErrInvalidPreferencesToken)
+
+	_, err = service.RecipientPreferences("mallory@contoso.com", PreferencesToken("secret", "mallory@contoso.com"))
+	assert.ErrorIs(t, err, ErrUnknownRecipient)
+
+	updated, err := service.UpdateRecipientPreferences("ALICE@contoso.com", PreferencesToken("secret", "ALICE@contoso.com"), config.EmailRecipient{
+		Content:       config.EmailContentSummary,
+		Format:        config.EmailFormatText,
+		KeywordGroups: []string{"fleet"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@contoso.com", updated.Email)
+
+	_, err = service.UpdateRecipientPreferences("alice@contoso.com", token, config.EmailRecipient{Content: "everything", Format: "html"})
+	assert.ErrorIs(t, err, ErrInvalidPreferences)
+
+	require.NoError(t, service.Unsubscribe("alice@contoso.com", token))
+
+	recipients, err := service.Recipients()
+	require.NoError(t, err)
+	require.Len(t, recipients, 1)
+	assert.True(t, recipients[0].Unsubscribed)
+	assert.Equal(t, config.EmailContentSummary, recipients[0].Content)
+
+	preferencesURL, unsubscribeURL := service.preferenceLinks("alice@contoso.com")
+	assert.Equal(t, "https://bot.example.com/preferences?email=alice%40contoso.com&token="+token, preferencesURL)
+	assert.Equal(t, "https://bot.example.com/unsubscribe?email=alice%40contoso.com&token="+token, unsubscribeURL)
+}
+
+func TestService_RenderUnsubscribePage(t *testing.T) {
+	service := newPreferencesTestService()
+
+	var page strings.Builder
+	require.NoError(t, service.RenderUnsubscribePage(&page, "alice@contoso.com", "signed", false))
+	assert.Contains(t, page.String(), `<form method="POST" action="/unsubscribe">`)
+	assert.Contains(t, page.String(), `name="token" value="signed"`)
+
+	page.Reset()
+	require.NoError(t, service.RenderUnsubscribePage(&page, "alice@contoso.com", "signed", true))
+	assert.Contains(t, page.String(), "has been unsubscribed")
+	assert.NotContains(t, page.String(), "<form")
+}
+
+func TestService_reportForRecipient(t *testing.T) {
+	service := newPreferencesTestService()
+	report := &models.Report{
+		TotalMentions: 2,
+		Mentions: []models.Mention{
+			{ID: "1", Source: "reddit", Keywords: []string{"kubefleet"}, Sentiment: "positive"},
+			{ID: "2", Source: "reddit", Keywords: []string{"AKS"}, Sentiment: "neutral"},
+		},
+		Summary: map[string]interface{}{"top_sources": []string{"reddit"}},
+	}
+
+	recipient := config.NewEmailRecipient("alice@contoso.com")
+	recipient.KeywordGroups = []string{"fleet"}
+
+	tailored := service.reportForRecipient(report, recipient)
+	require.Len(t, tailored.Mentions, 1)
+	assert.Equal(t, "1", tailored.Mentions[0].ID)
+	assert.Equal(t, 1, tailored.TotalMentions)
+	assert.Equal(t, map[string]int{"positive": 1}, tailored.Summary["sentiment"])
+	assert.Len(t, report.Mentions, 2, "original report is unchanged")
+
+	recipient.Content = config.EmailContentSummary
+	assert.Empty(t, service.reportForRecipient(report, recipient).Mentions)
+}
+
+func TestService_reportForRecipient_PreviouslyAlerted(t *testing.T) {
+	service := newPreferencesTestService()
+	report := &models.Report{
+		TotalMentions: 3,
+		Mentions: []models.Mention{
+			{ID: "1", Source: "reddit", Keywords: []string{"kubefleet"}, Sentiment: "positive"},
+		},
+		PreviouslyAlerted: []models.Mention{
+			{ID: "2", Source: "twitter", Keywords: []string{"KubeFleet"}, Sentiment: "negative"},
+			{ID: "3", Source: "twitter", Keywords: []string{"AKS"}, Sentiment: "negative"},
+		},
+		Summary: map[string]interface{}{},
+	}
+
+	recipient := config.NewEmailRecipient("alice@contoso.com")
+	recipient.KeywordGroups = []string{"fleet"}
+
+	tailored := service.reportForRecipient(report, recipient)
+	require.Len(t, tailored.PreviouslyAlerted, 1)
+	assert.Equal(t, "2", tailored.PreviouslyAlerted[0].ID)
+	assert.Equal(t, 2, tailored.TotalMentions)
+	assert.Equal(t, map[string]int{"positive": 1, "negative": 1}, tailored.Summary["sentiment"])
+
+	recipient.Content = config.EmailContentSummary
+	assert.Empty(t, service.reportForRecipient(report, recipient).PreviouslyAlerted)
+}
@@ -0,0 +1,119 @@
+package notifications
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// omittedSummaryKey holds the per-source counts of mentions left out of a sampled report
+const omittedSummaryKey = "omitted_mentions"
+
+// sampleReport keeps the top TeamsMentionsPerSource mentions of each source, so large
+// runs post a digest to Teams instead of every mention. The counts left out are recorded
+// in the report summary; the full list stays in the stored report.
+func (s *Service) sampleReport(report *models.Report) *models.Report {
+	if s.config.TeamsMentionsPerSource <= 0 {
+		return report
+	}
+
+	mentions, omitted := sampleMentions(report.Mentions, s.config.TeamsMentionsPerSource, s.config.TeamsMentionRanking)
+	if len(omitted) == 0 {
+		return report
+	}
+
+	sampled := *report
+	sampled.Mentions = mentions
+	sampled.Summary = make(map[string]interface{}, len(report.Summary)+1)
+	for key, value := range report.Summary {
+		sampled.Summary[key] = value
+	}
+	sampled.Summary[omittedSummaryKey] = omitted
+	return &sampled
+}
+
+// sampleMentions ranks mentions and keeps at most perSource of each source, returning the
+// kept mentions in rank order and how many of each source were left out
+func sampleMentions(mentions []models.Mention, perSource int, ranking string) ([]models.Mention, map[string]int) {
+	ranked := make([]models.Mention, len(mentions))
+	copy(ranked, mentions)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return rankBefore(ranked[i], ranked[j], ranking)
+	})
+
+	kept := make(map[string]int)
+	omitted := make(map[string]int)
+	sampled := make([]models.Mention, 0, len(ranked))
+	for _, mention := range ranked {
+		if kept[mention.Source] >= perSource {
+			omitted[mention.Source]++
+			continue
+		}
+		kept[mention.Source]++
+		sampled = append(sampled, mention)
+	}
+	return sampled, omitted
+}
+
+// rankBefore orders mentions by the configured ranking, breaking ties by recency
+func rankBefore(a, b models.Mention, ranking string) bool {
+	switch ranking {
+	case config.MentionRankingRelevance:
+		if a.Relevance != b.Relevance {
+			return a.Relevance > b.Relevance
+		}
+	case config.MentionRankingEngagement:
+		if engagement(a) != engagement(b) {
+			return engagement(a) > engagement(b)
+		}
+	case config.MentionRankingRank:
+		if a.Rank != nil && b.Rank != nil && a.Rank.Position != b.Rank.Position {
+			return a.Rank.Position < b.Rank.Position
+		}
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// engagement is a mention's upvotes or likes plus its comments
+func engagement(mention models.Mention) int {
+	return mention.Score + mention.CommentCount
+}
+
+// omittedMentions returns the per-source counts a sampled report left out, if any
+func omittedMentions(report *models.Report) map[string]int {
+	omitted, _ := report.Summary[omittedSummaryKey].(map[string]int)
+	return omitted
+}
+
+// omittedSummary describes the mentions a sampled report left out, busiest source first,
+// e.g. "Showing 20 of 75 mentions. Not shown: 40 reddit, 15 hackernews"
+func omittedSummary(report *models.Report) string {
+	omitted := omittedMentions(report)
+	if len(omitted) == 0 {
+		return ""
+	}
+
+	sources := make([]string, 0, len(omitted))
+	for source := range omitted {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if omitted[sources[i]] != omitted[sources[j]] {
+			return omitted[sources[i]] > omitted[sources[j]]
+		}
+		return sources[i] < sources[j]
+	})
+
+	counts := make([]string, 0, len(sources))
+	for _, source := range sources {
+		counts = append(counts, fmt.Sprintf("%d %s", omitted[source], source))
+	}
+	summary := fmt.Sprintf("Showing %d of %d mentions. Not shown: %s", len(report.Mentions)+len(report.PreviouslyAlerted), report.TotalMentions, strings.Join(counts, ", "))
+	if report.ReportURL == "" {
+		summary += " (see the stored report)"
+	}
+	return summary
+}
@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleMentions(t *testing.T) {
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	mentions := []models.Mention{
+		{ID: "r1", Source: "reddit", Score: 5, CommentCount: 1, Relevance: 0.9, CreatedAt: now},
+		{ID: "r2", Source: "reddit", Score: 40, CommentCount: 10, Relevance: 0.5, CreatedAt: now.Add(-time.Hour)},
+		{ID: "r3", Source: "reddit", Score: 20, Relevance: 0.7, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "h1", Source: "hackernews", Score: 100, Relevance: 0.6, CreatedAt: now.Add(-3 * time.Hour)},
+	}
+
+	sampled, omitted := sampleMentions(mentions, 2, config.MentionRankingEngagement)
+	assert.Equal(t, []string{"h1", "r2", "r3"}, mentionIDs(sampled))
+	assert.Equal(t, map[string]int{"reddit": 1}, omitted)
+
+	sampled, _ = sampleMentions(mentions, 2, config.MentionRankingRelevance)
+	assert.Equal(t, []string{"r1", "r3", "h1"}, mentionIDs(sampled))
+
+	sampled, _ = sampleMentions(mentions, 1, config.MentionRankingRecent)
+	assert.Equal(t, []string{"r1", "h1"}, mentionIDs(sampled))
+
+	ranked := append([]models.Mention(nil), mentions...)
+	for i, position := range []int{4, 1, 3, 2} {
+		ranked[i].Rank = &models.MentionRank{Position: position}
+	}
+	sampled, _ = sampleMentions(ranked, 1, config.MentionRankingRank)
+	assert.Equal(t, []string{"r2", "h1"}, mentionIDs(sampled))
+
+	assert.Equal(t, "r1", mentions[0].ID, "the report's mention order is left untouched")
+}
+
+func TestService_sampleReport(t *testing.T) {
+	service := NewService(&config.Config{TeamsMentionsPerSource: 1, TeamsMentionRanking: config.MentionRankingEngagement})
+	report := &models.Report{
+		Period:        "weekly",
+		TotalMentions: 4,
+		Summary:       map[string]interface{}{"sentiment": map[string]int{"neutral": 4}},
+		Mentions: []models.Mention{
+			{ID: "r1", Source: "reddit", Score: 1},
+			{ID: "r2", Source: "reddit", Score: 2},
+			{ID: "h1", Source: "hackernews", Score: 3},
+			{ID: "h2", Source: "hackernews"},
+		},
+		ReportURL: "https://bot.example.com/reports/2024-06-03-09-00-00",
+	}
+
+	sampled := service.sampleReport(report)
+	assert.Equal(t, []string{"h1", "r2"}, mentionIDs(sampled.Mentions))
+	assert.Len(t, report.Mentions, 4)
+	assert.NotContains(t, report.Summary, omittedSummaryKey)
+	assert.Equal(t, "Showing 2 of 4 mentions. Not shown: 1 hackernews, 1 reddit", omittedSummary(sampled))
+
+	message := service.buildLogicAppMessage(sampled, nil)
+	assert.Equal(t, map[string]int{"hackernews": 1, "reddit": 1}, message.Omitted)
+	assert.Contains(t, message.Summary, "Not shown: 1 hackernews, 1 reddit")
+
+	card := service.buildTeamsMessage(sampled, nil)
+	require.NotEmpty(t, card.Sections)
+	assert.Equal(t, "More Mentions", card.Sections[len(card.Sections)-1].ActivityTitle)
+
+	// Sampling is off with no per-source limit
+	service.config.TeamsMentionsPerSource = 0
+	assert.Same(t, report, service.sampleReport(report))
+}
+
+func mentionIDs(mentions []models.Mention) []string {
+	ids := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		ids = append(ids, mention.ID)
+	}
+	return ids
+}
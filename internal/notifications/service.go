@@ -2,23 +2,32 @@ package notifications
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/matching"
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/reports"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
-	"gopkg.in/gomail.v2"
 )
 
 // Service handles sending notifications via various channels
 type Service struct {
-	config *config.Config
-	client *resty.Client
+	config        *config.Config
+	client        *resty.Client
+	graphSender   *GraphTeamsSender
+	emailSender   emailSender
+	webhookSender *WebhookSender // Signs posts to webhook channels
+	preferences   *preferenceStore
+	queue         *deliveryQueue
+	retryDelay    time.Duration
 }
 
 // Ensure Service implements NotificationInterface
@@ -26,11 +35,25 @@ var _ NotificationInterface = (*Service)(nil)
 
 // TeamsMessage represents a Microsoft Teams webhook message (legacy format)
 type TeamsMessage struct {
-	Type     string         `json:"@type"`
-	Context  string         `json:"@context"`
-	Title    string         `json:"title"`
-	Text     string         `json:"text"`
-	Sections []TeamsSection `json:"sections,omitempty"`
+	Type            string         `json:"@type"`
+	Context         string         `json:"@context"`
+	ThemeColor      string         `json:"themeColor,omitempty"`
+	Title           string         `json:"title"`
+	Text            string         `json:"text"`
+	Sections        []TeamsSection `json:"sections,omitempty"`
+	PotentialAction []TeamsAction  `json:"potentialAction,omitempty"`
+}
+
+// TeamsAction is a MessageCard button; only OpenUri actions are used
+type TeamsAction struct {
+	Type    string           `json:"@type"`
+	Name    string           `json:"name"`
+	Targets []TeamsActionURI `json:"targets"`
+}
+
+type TeamsActionURI struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
 }
 
 type TeamsSection struct {
@@ -48,72 +71,125 @@ type TeamsFact struct {
 
 // LogicAppMessage represents a message for Azure Logic Apps
 type LogicAppMessage struct {
-	Title    string            `json:"title"`
-	Summary  string            `json:"summary"`
-	Mentions []LogicAppMention `json:"mentions"`
+	SchemaVersion string            `json:"schema_version"` // LogicAppSchemaVersion, so flows can tell payload versions apart
+	Title         string            `json:"title"`
+	Summary       string            `json:"summary"`
+	Urgency       string            `json:"urgency"`              // "normal" for reports, "urgent" for urgent checks, the alert type for alerts
+	ReportURL     string            `json:"report_url,omitempty"` // Standalone HTML report with charts
+	Mentions      []LogicAppMention `json:"mentions"`
+	Omitted       map[string]int    `json:"omitted_mentions,omitempty"` // Mentions per source left out of a sampled report
 }
 
 type LogicAppMention struct {
-	Source    string `json:"source"`
-	Title     string `json:"title"`
-	URL       string `json:"url"`
-	Snippet   string `json:"snippet"`
-	Timestamp string `json:"timestamp"`
+	ID            string   `json:"id"`
+	Source        string   `json:"source"`
+	PostType      string   `json:"post_type,omitempty"`
+	Title         string   `json:"title"`
+	URL           string   `json:"url"`
+	Permalink     string   `json:"permalink,omitempty"` // The bot's page for the mention
+	Snippet       string   `json:"snippet"`
+	Timestamp     string   `json:"timestamp"`
+	Relevance     float64  `json:"relevance"`
+	Sentiment     string   `json:"sentiment,omitempty"`
+	Keywords      []string `json:"keywords,omitempty"`
+	KeywordGroups []string `json:"keyword_groups,omitempty"` // KEYWORD_GROUPS containing a matched keyword
+	Urgency       string   `json:"urgency"`                  // "urgent" for mentions from urgent checks or already alerted, else "normal"
+	Advisories    []string `json:"advisories,omitempty"`
+	ActionURL     string   `json:"action_url,omitempty"` // POST {"action": "handled"|"escalate"} to close the loop
 }
 
 // NewService creates a new notification service
 func NewService(cfg *config.Config) *Service {
-	return &Service{
-		config: cfg,
-		client: resty.New().SetTimeout(30 * time.Second),
+	service := &Service{
+		config:        cfg,
+		client:        resty.New().SetTimeout(30 * time.Second),
+		webhookSender: NewWebhookSender(nil, cfg.OutboundWebhookSecret),
+		retryDelay:    defaultRetryDelay,
+	}
+
+	if cfg.TeamsDeliveryMode == "graph" {
+		sender, err := NewGraphTeamsSender(cfg)
+		if err != nil {
+			logrus.Errorf("Failed to initialize Teams Graph sender: %v", err)
+		} else {
+			service.graphSender = sender
+		}
 	}
+
+	if cfg.EmailDeliveryMode == EmailDeliveryGraph {
+		sender, err := NewGraphMailSender(cfg)
+		if err != nil {
+			logrus.Errorf("Failed to initialize Graph mail sender: %v", err)
+		} else {
+			service.emailSender = sender
+		}
+	} else {
+		service.emailSender = &smtpEmailSender{
+			host:     cfg.SMTPHost,
+			port:     cfg.SMTPPort,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+		}
+	}
+
+	return service
 }
 
-// SendReport sends a report via configured notification channels
+// SendReport sends a report via configured notification channels. Deliveries that fail are
+// queued for retry when the delivery queue is enabled.
 func (s *Service) SendReport(report *models.Report) error {
-	var errors []string
+	var failures []deliveryFailure
 
-	// Send to Teams if configured
-	if s.config.TeamsWebhookURL != "" {
+	// Send to the Teams channel through Graph if configured
+	if s.config.TeamsDeliveryMode == "graph" && s.config.TeamsEnabled() {
 		if err := s.sendToTeams(report); err != nil {
 			logrus.Errorf("Failed to send Teams notification: %v", err)
-			errors = append(errors, fmt.Sprintf("Teams: %v", err))
+			failures = append(failures, deliveryFailure{target: targetTeamsGraph, err: err})
 		} else {
 			logrus.Info("Successfully sent report to Teams")
 		}
 	}
 
 	// Send via email if configured
-	if s.config.NotificationEmail != "" {
-		if err := s.sendEmail(report); err != nil {
-			logrus.Errorf("Failed to send email notification: %v", err)
-			errors = append(errors, fmt.Sprintf("Email: %v", err))
+	if len(s.config.EmailRecipients) > 0 {
+		if emailFailures := s.sendEmails(report); len(emailFailures) > 0 {
+			logrus.Errorf("Failed to send email notification to %d recipients", len(emailFailures))
+			failures = append(failures, emailFailures...)
 		} else {
 			logrus.Info("Successfully sent report via email")
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("notification errors: %s", strings.Join(errors, "; "))
+	// Each webhook channel is delivered and retried on its own
+	for _, channel := range s.config.Channels() {
+		if err := s.sendReportToChannel(channel, report); err != nil {
+			logrus.Errorf("Failed to send report to channel %s: %v", channel.Name, err)
+			failures = append(failures, deliveryFailure{target: channel.Name, err: err})
+		} else {
+			logrus.Infof("Successfully sent report to channel %s", channel.Name)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("notification errors: %s", s.queueFailures(QueueKindReport, report, nil, failures))
 	}
 
 	return nil
 }
 
+// sendToTeams posts a report to the Teams channel through Microsoft Graph
 func (s *Service) sendToTeams(report *models.Report) error {
-	// Detect if this is a Logic Apps endpoint or traditional Teams webhook
-	if s.isLogicAppsEndpoint() {
-		logrus.Info("Sending notification to Logic Apps endpoint")
-		return s.sendToLogicApps(report)
-	} else {
-		logrus.Info("Sending notification to Teams webhook endpoint")
-		message := s.buildTeamsMessage(report)
-		return s.sendSingleMessage(message)
+	if s.graphSender == nil {
+		return fmt.Errorf("teams Graph sender is not initialized")
 	}
+
+	// Large runs post the top mentions per source; the rest are counted and linked
+	logrus.Info("Sending notification to Teams channel via Microsoft Graph")
+	return s.graphSender.Send(s.sampleReport(report))
 }
 
-func (s *Service) sendToLogicApps(report *models.Report) error {
-	message := s.buildLogicAppMessage(report)
+func (s *Service) sendToLogicApps(channel config.NotificationChannel, report *models.Report) error {
+	message := s.buildLogicAppMessage(report, catalog(channel.Locale))
 	
 	// Check payload size
 	payloadBytes, err := json.Marshal(message)
@@ -127,7 +203,7 @@ func (s *Service) sendToLogicApps(report *models.Report) error {
 	// If payload is too large (> 500KB), send in batches
 	if payloadSize > 500000 {
 		logrus.Warn("Payload too large, sending in batches")
-		return s.sendLogicAppsInBatches(report)
+		return s.sendLogicAppsInBatches(channel, report)
 	}
 	
 	// Log a truncated version of the payload for debugging
@@ -137,10 +213,10 @@ func (s *Service) sendToLogicApps(report *models.Report) error {
 		logrus.Infof("Payload being sent: %s", string(payloadBytes))
 	}
 	
-	return s.sendSingleMessage(message)
+	return s.postChannel(channel, message)
 }
 
-func (s *Service) sendLogicAppsInBatches(report *models.Report) error {
+func (s *Service) sendLogicAppsInBatches(channel config.NotificationChannel, report *models.Report) error {
 	batchSize := 20 // Send 20 mentions per batch
 	totalBatches := (len(report.Mentions) + batchSize - 1) / batchSize
 	
@@ -160,14 +236,15 @@ func (s *Service) sendLogicAppsInBatches(report *models.Report) error {
 		}
 		
 		batchNum := (i / batchSize) + 1
-		message := s.buildLogicAppMessage(batchReport)
+		m := catalog(channel.Locale)
+		message := s.buildLogicAppMessage(batchReport, m)
 		
 		// Update title to indicate batch
-		message.Title = fmt.Sprintf("%s - Batch %d/%d", message.Title, batchNum, totalBatches)
-		message.Summary = fmt.Sprintf("Batch %d of %d - %d mentions in this batch (Total: %d)", 
+		message.Title = m.sprintf("%s - Batch %d/%d", message.Title, batchNum, totalBatches)
+		message.Summary = m.sprintf("Batch %d of %d - %d mentions in this batch (Total: %d)", 
 			batchNum, totalBatches, len(batchReport.Mentions), report.TotalMentions)
 		
-		err := s.sendSingleMessage(message)
+		err := s.postChannel(channel, message)
 		if err != nil {
 			return fmt.Errorf("failed to send batch %d: %w", batchNum, err)
 		}
@@ -184,74 +261,76 @@ func (s *Service) sendLogicAppsInBatches(report *models.Report) error {
 	return nil
 }
 
-func (s *Service) sendSingleMessage(message interface{}) error {
-	resp, err := s.client.R().
-		SetHeader("Content-Type", "application/json").
-		SetBody(message).
-		Post(s.config.TeamsWebhookURL)
-
-	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
-	}
-
-	if resp.StatusCode() != 200 && resp.StatusCode() != 202 {
-		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+// advisoryLinks renders security advisories as markdown links with their severity
+func advisoryLinks(advisories []models.Advisory) string {
+	links := make([]string, 0, len(advisories))
+	for _, advisory := range advisories {
+		link := fmt.Sprintf("[%s](%s)", advisory.ID, advisory.URL)
+		if advisory.Severity != "" {
+			link += fmt.Sprintf(" (%s %.1f)", advisory.Severity, advisory.Score)
+		}
+		links = append(links, link)
 	}
-
-	logrus.Info("Successfully sent report to Teams")
-	return nil
+	return strings.Join(links, ", ")
 }
 
-// isLogicAppsEndpoint detects if the webhook URL is a Logic Apps endpoint
-func (s *Service) isLogicAppsEndpoint() bool {
-	url := s.config.TeamsWebhookURL
-	logrus.Infof("Checking if URL is Logic Apps endpoint: %s", url)
-	
-	isLogicApps := strings.Contains(url, "logic.azure.com") ||
-		strings.Contains(url, "workflows") ||
-		strings.Contains(url, "azurewebsites.net")
-	
-	logrus.Infof("URL contains logic.azure.com: %v", strings.Contains(url, "logic.azure.com"))
-	logrus.Infof("URL contains workflows: %v", strings.Contains(url, "workflows"))
-	logrus.Infof("URL contains azurewebsites.net: %v", strings.Contains(url, "azurewebsites.net"))
-	logrus.Infof("Is Logic Apps endpoint: %v", isLogicApps)
-	
-	return isLogicApps
-}
-
-// buildLogicAppMessage creates a message for Azure Logic Apps
-func (s *Service) buildLogicAppMessage(report *models.Report) *LogicAppMessage {
-	// Create a more descriptive title with date range
-	var title string
+// reportTitle creates a descriptive report title with the covered date range, in the
+// language of m
+func reportTitle(report *models.Report, m messages) string {
 	if report.Period == "weekly" {
 		// For weekly reports, show the week ending date
-		endDate := report.GeneratedAt.Format("Jan 2, 2006")
-		startDate := report.GeneratedAt.AddDate(0, 0, -7).Format("Jan 2")
-		title = fmt.Sprintf("AKS Mentions Report - Weekly (%s - %s)", startDate, endDate)
+		endDate := m.date("Jan 2, 2006", report.GeneratedAt)
+		startDate := m.date("Jan 2", report.GeneratedAt.AddDate(0, 0, -7))
+		return m.sprintf("AKS Mentions Report - Weekly (%s - %s)", startDate, endDate)
 	} else if report.Period == "daily" {
 		// For daily reports, show the specific date
-		date := report.GeneratedAt.Format("Jan 2, 2006")
-		title = fmt.Sprintf("AKS Mentions Report - Daily (%s)", date)
-	} else {
-		// Fallback for other periods
-		date := report.GeneratedAt.Format("Jan 2, 2006")
-		title = fmt.Sprintf("AKS Mentions Report - %s (%s)", strings.Title(report.Period), date)
+		date := m.date("Jan 2, 2006", report.GeneratedAt)
+		return m.sprintf("AKS Mentions Report - Daily (%s)", date)
 	}
 
+	// Fallback for other periods
+	date := m.date("Jan 2, 2006", report.GeneratedAt)
+	return m.sprintf("AKS Mentions Report - %s (%s)", strings.Title(m.text(report.Period)), date)
+}
+
+// buildLogicAppMessage creates a message for Azure Logic Apps, with its title and summary in
+// the language of m
+func (s *Service) buildLogicAppMessage(report *models.Report, m messages) *LogicAppMessage {
+	title := reportTitle(report, m)
+
 	message := &LogicAppMessage{
-		Title:    title,
-		Summary:  fmt.Sprintf("Found %d mentions in the last %s", report.TotalMentions, report.Period),
-		Mentions: make([]LogicAppMention, 0, len(report.Mentions)),
+		SchemaVersion: LogicAppSchemaVersion,
+		Title:         title,
+		Summary:       m.sprintf("Found %d mentions in the last %s", report.TotalMentions, m.text(report.Period)),
+		Urgency:       reportUrgency(report),
+		ReportURL:     report.ReportURL,
+		Mentions:      make([]LogicAppMention, 0, len(report.Mentions)),
+		Omitted:       omittedMentions(report),
+	}
+	if omitted := omittedSummary(report); omitted != "" {
+		message.Summary += ". " + omitted
 	}
 
 	// Convert mentions to Logic App format with content truncation
 	for _, mention := range report.Mentions {
 		logicAppMention := LogicAppMention{
-			Source:    mention.Source,
-			Title:     s.truncateString(mention.Title, 150),
-			URL:       mention.URL,
-			Snippet:   s.truncateString(mention.Content, 300), // Limit snippet to 300 chars
-			Timestamp: mention.CreatedAt.Format("2006-01-02 15:04:05 UTC"),
+			ID:            mention.ID,
+			ActionURL:     s.mentionActionURL(mention.ID),
+			Source:        mention.Source,
+			PostType:      mention.PostType,
+			Title:         s.truncateString(mention.Title, 150),
+			URL:           mention.URL,
+			Permalink:     mention.Permalink,
+			Snippet:       highlightMarkdown(s.mentionSnippet(mention, 300), s.mentionKeywords(mention)),
+			Timestamp:     mention.CreatedAt.Format("2006-01-02 15:04:05 UTC"),
+			Relevance:     mention.Relevance,
+			Sentiment:     mention.Sentiment,
+			Keywords:      mention.Keywords,
+			KeywordGroups: s.mentionKeywordGroups(mention),
+			Urgency:       mentionUrgency(report, mention),
+		}
+		for _, advisory := range mention.Advisories {
+			logicAppMention.Advisories = append(logicAppMention.Advisories, advisory.URL)
 		}
 		message.Mentions = append(message.Mentions, logicAppMention)
 	}
@@ -259,47 +338,49 @@ func (s *Service) buildLogicAppMessage(report *models.Report) *LogicAppMessage {
 	return message
 }
 
-func (s *Service) buildTeamsMessage(report *models.Report) *TeamsMessage {
-	// Create a more descriptive title with date range
-	var title string
-	if report.Period == "weekly" {
-		// For weekly reports, show the week ending date
-		endDate := report.GeneratedAt.Format("Jan 2, 2006")
-		startDate := report.GeneratedAt.AddDate(0, 0, -7).Format("Jan 2")
-		title = fmt.Sprintf("AKS Mentions Report - Weekly (%s - %s)", startDate, endDate)
-	} else if report.Period == "daily" {
-		// For daily reports, show the specific date
-		date := report.GeneratedAt.Format("Jan 2, 2006")
-		title = fmt.Sprintf("AKS Mentions Report - Daily (%s)", date)
-	} else {
-		// Fallback for other periods
-		date := report.GeneratedAt.Format("Jan 2, 2006")
-		title = fmt.Sprintf("AKS Mentions Report - %s (%s)", strings.Title(report.Period), date)
+// mentionActionURL is the bot endpoint a Logic App calls to mark a mention handled or
+// escalate it; it is only set when inbound actions are enabled and the bot is reachable
+func (s *Service) mentionActionURL(id string) string {
+	if s.config.PublicBaseURL == "" || s.config.InboundWebhookSecret == "" || id == "" {
+		return ""
 	}
+	return fmt.Sprintf("%s/api/mentions/%s/actions", s.config.PublicBaseURL, url.PathEscape(id))
+}
+
+// buildTeamsMessage renders a report as a MessageCard: the summary facts and top mentions,
+// followed by a section for each part the report has, from unanswered questions and
+// documentation gaps to release correlation, negative comments and skipped sources, and
+// buttons opening the full report and its comparison with the previous one. Headers and
+// labels are in the language of m.
+func (s *Service) buildTeamsMessage(report *models.Report, m messages) *TeamsMessage {
+	title := reportTitle(report, m)
 
 	message := &TeamsMessage{
 		Type:    "MessageCard",
 		Context: "https://schema.org/extensions",
 		Title:   title,
-		Text:    fmt.Sprintf("Found %d mentions in the last %s", report.TotalMentions, report.Period),
+		Text:    m.sprintf("Found %d mentions in the last %s", report.TotalMentions, m.text(report.Period)),
 	}
 
 	// Add summary section
 	if summary, ok := report.Summary["sentiment"].(map[string]int); ok {
 		facts := []TeamsFact{
-			{Name: "Total Mentions", Value: fmt.Sprintf("%d", report.TotalMentions)},
-			{Name: "Generated", Value: report.GeneratedAt.Format("2006-01-02 15:04:05 UTC")},
+			{Name: m.text("Total Mentions"), Value: fmt.Sprintf("%d", report.TotalMentions)},
+			{Name: m.text("Generated"), Value: report.GeneratedAt.Format("2006-01-02 15:04:05 UTC")},
+		}
+		if report.SentimentTrend != nil {
+			facts = append(facts, TeamsFact{Name: m.text("Sentiment Score"), Value: report.SentimentTrend.Summary()})
 		}
 
 		for sentiment, count := range summary {
 			facts = append(facts, TeamsFact{
-				Name:  fmt.Sprintf("%s Mentions", strings.Title(sentiment)),
+				Name:  m.sentimentMentions(sentiment),
 				Value: fmt.Sprintf("%d", count),
 			})
 		}
 
 		message.Sections = append(message.Sections, TeamsSection{
-			ActivityTitle: "Summary",
+			ActivityTitle: m.text("Summary"),
 			Facts:         facts,
 			Markdown:      true,
 		})
@@ -316,50 +397,296 @@ func (s *Service) buildTeamsMessage(report *models.Report) *TeamsMessage {
 		for i := 0; i < limit; i++ {
 			mention := report.Mentions[i]
 			mentionText := fmt.Sprintf("**[%s](%s)** - %s (%s)",
-				mention.Title, mention.URL, mention.Source, mention.CreatedAt.Format("Jan 2"))
+				mention.Title, mentionLink(mention), mentionSource(mention), m.date("Jan 2", mention.CreatedAt))
+			if mention.Relevance > 0 {
+				mentionText += fmt.Sprintf(" | relevance %.2f", mention.Relevance)
+			}
+			if len(mention.Advisories) > 0 {
+				mentionText += " | advisories: " + advisoryLinks(mention.Advisories)
+			}
+			if len(mention.SentimentPhrases) > 0 {
+				mentionText += " | negative: " + strings.Join(mention.SentimentPhrases, ", ")
+			}
+			if mention.Content != "" {
+				keywords := s.mentionKeywords(mention)
+				mentionText += "\n\n" + highlightMarkdown(s.mentionSnippet(mention, 200), keywords)
+			}
 			topMentions = append(topMentions, mentionText)
 		}
 
 		message.Sections = append(message.Sections, TeamsSection{
-			ActivityTitle: "Recent Mentions",
+			ActivityTitle: m.text("Recent Mentions"),
 			ActivityText:  strings.Join(topMentions, "\n\n"),
 			Markdown:      true,
 		})
 	}
 
+	if omitted := omittedSummary(report); omitted != "" {
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("More Mentions"),
+			ActivityText:  omitted,
+			Markdown:      true,
+		})
+	}
+
+	if len(report.Unanswered) > 0 {
+		var questions []string
+		for _, question := range report.Unanswered {
+			questions = append(questions, fmt.Sprintf("**[%s](%s)** - %s, waiting %s",
+				question.Title, question.URL, question.Source, waitingTime(question.CreatedAt)))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("Needs an Answer"),
+			ActivityText:  strings.Join(questions, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if len(report.Resolved) > 0 {
+		var resolved []string
+		for _, question := range report.Resolved {
+			resolved = append(resolved, fmt.Sprintf("**[%s](%s)** - %s, %s",
+				question.Mention.Title, question.Mention.URL, question.Mention.Source, question.Resolution()))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("Resolved Since Last Report"),
+			ActivityText:  strings.Join(resolved, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if report.DocsGaps != nil {
+		var gaps []string
+		for _, gap := range report.DocsGaps.Topics {
+			var examples []string
+			for _, question := range gap.Questions {
+				examples = append(examples, fmt.Sprintf("[%s](%s)", question.Title, question.URL))
+			}
+			gaps = append(gaps, fmt.Sprintf("**%s** - %d questions, e.g. %s", gap.Topic, gap.Count, strings.Join(examples, ", ")))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.sprintf("Documentation Gaps in %s", report.DocsGaps.Month),
+			ActivityText:  strings.Join(gaps, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if len(report.PreviouslyAlerted) > 0 {
+		var alerted []string
+		for _, mention := range report.PreviouslyAlerted {
+			alerted = append(alerted, fmt.Sprintf("**[%s](%s)** - %s, %s",
+				mention.Title, mentionLink(mention), mentionSource(mention), alertedWhen(mention)))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("Previously Alerted"),
+			ActivityText:  strings.Join(alerted, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if len(report.ReleaseInsights) > 0 {
+		var insights []string
+		for _, insight := range report.ReleaseInsights {
+			insights = append(insights, fmt.Sprintf("• %s ([%s](%s))",
+				insight.Summary, insight.ReleaseName, insight.ReleaseURL))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("AKS Release Correlation"),
+			ActivityText:  strings.Join(insights, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	// Add notable negative comments section
+	if len(report.NegativeComments) > 0 {
+		var comments []string
+		limit := 5
+		if len(report.NegativeComments) < limit {
+			limit = len(report.NegativeComments)
+		}
+
+		for _, comment := range report.NegativeComments[:limit] {
+			comments = append(comments, fmt.Sprintf("**[%s](%s)**: %s",
+				comment.Author, comment.URL, highlightMarkdown(matching.KeywordSnippet(comment.Content, s.config.Keywords, 200), s.config.Keywords)))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("Notable Negative Comments"),
+			ActivityText:  strings.Join(comments, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if report.Reliability != nil {
+		var rates []string
+		for _, rate := range report.Reliability.Sources {
+			rates = append(rates, fmt.Sprintf("**%s** - %s", rate.Source, rate.Summary()))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: reliabilityTitle(report.Reliability, m),
+			ActivityText:  strings.Join(rates, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if len(report.SkippedSources) > 0 {
+		var skipped []string
+		for _, source := range report.SkippedSources {
+			skipped = append(skipped, fmt.Sprintf("**%s** - %s", source.Source, s.skippedWhy(source)))
+		}
+
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle: m.text("Skipped Sources"),
+			ActivityText:  strings.Join(skipped, "\n\n"),
+			Markdown:      true,
+		})
+	}
+
+	if report.ReportURL != "" {
+		message.PotentialAction = append(message.PotentialAction, TeamsAction{
+			Type:    "OpenUri",
+			Name:    m.text("View full report"),
+			Targets: []TeamsActionURI{{OS: "default", URI: report.ReportURL}},
+		})
+	}
+	if report.CompareURL != "" {
+		message.PotentialAction = append(message.PotentialAction, TeamsAction{
+			Type:    "OpenUri",
+			Name:    m.text("Compare with previous report"),
+			Targets: []TeamsActionURI{{OS: "default", URI: report.CompareURL}},
+		})
+	}
+
 	return message
 }
 
-func (s *Service) sendEmail(report *models.Report) error {
-	subject := fmt.Sprintf("AKS Mentions Report - %s (%d mentions)",
-		strings.Title(report.Period), report.TotalMentions)
-
-	htmlBody, err := s.buildEmailHTML(report)
+// sendEmails sends the report to every subscribed recipient according to their preferences,
+// returning the recipients it failed to reach
+func (s *Service) sendEmails(report *models.Report) []deliveryFailure {
+	recipients, err := s.Recipients()
 	if err != nil {
-		return fmt.Errorf("failed to build email HTML: %w", err)
+		// Fall back to the configured preferences rather than skipping the report
+		logrus.Warnf("Failed to load recipient preferences, using configured defaults: %v", err)
 	}
 
-	textBody := s.buildEmailText(report)
+	// Recipients sharing a tailored report share its PDF, which is only printed when requested
+	pdfs := make(map[*models.Report][]byte)
 
-	// Create message
-	m := gomail.NewMessage()
-	m.SetHeader("From", s.config.SMTPUsername)
-	m.SetHeader("To", s.config.NotificationEmail)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", textBody)
-	m.AddAlternative("text/html", htmlBody)
+	var failures []deliveryFailure
+	for _, recipient := range recipients {
+		if recipient.Unsubscribed {
+			logrus.Debugf("Skipping unsubscribed recipient %s", recipient.Email)
+			continue
+		}
+
+		recipientReport := s.reportForRecipient(report, recipient)
+		var pdf []byte
+		if recipient.AttachPDF && s.config.EnablePDFReports {
+			if _, ok := pdfs[recipientReport]; !ok {
+				pdfs[recipientReport] = s.renderPDF(recipientReport)
+			}
+			pdf = pdfs[recipientReport]
+		}
+
+		if err := s.sendEmail(recipientReport, recipient, pdf); err != nil {
+			failures = append(failures, deliveryFailure{target: emailTarget(recipient.Email), err: err})
+		}
+	}
+
+	return failures
+}
 
-	// Send email
-	d := gomail.NewDialer(s.config.SMTPHost, s.config.SMTPPort, s.config.SMTPUsername, s.config.SMTPPassword)
+// resendEmail sends the report to one recipient again, unless they have since unsubscribed
+func (s *Service) resendEmail(report *models.Report, email string) error {
+	recipients, err := s.Recipients()
+	if err != nil {
+		logrus.Warnf("Failed to load recipient preferences, using configured defaults: %v", err)
+	}
+
+	for _, recipient := range recipients {
+		if !strings.EqualFold(recipient.Email, email) {
+			continue
+		}
+		if recipient.Unsubscribed {
+			logrus.Infof("Dropping queued report for %s: unsubscribed", recipient.Email)
+			return nil
+		}
 
-	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		recipientReport := s.reportForRecipient(report, recipient)
+		var pdf []byte
+		if recipient.AttachPDF && s.config.EnablePDFReports {
+			pdf = s.renderPDF(recipientReport)
+		}
+		return s.sendEmail(recipientReport, recipient, pdf)
 	}
+	return fmt.Errorf("%w: %s", errTargetRemoved, email)
+}
+
+// emailData is the template data for a single recipient's email
+type emailData struct {
+	*models.Report
+	PreferencesURL string
+	UnsubscribeURL string
+}
 
+// renderPDF prints the report to PDF for attachments; failures are logged and the email is
+// sent without the attachment
+func (s *Service) renderPDF(report *models.Report) []byte {
+	page, err := reports.RenderHTML(report)
+	if err == nil {
+		var pdf []byte
+		if pdf, err = reports.NewPDFRenderer(s.config.ChromePath).WithNoSandbox(s.config.ChromeNoSandbox).RenderPDF(context.Background(), page); err == nil {
+			return pdf
+		}
+	}
+	logrus.Warnf("Failed to render PDF report, sending emails without it: %v", err)
 	return nil
 }
 
-func (s *Service) buildEmailHTML(report *models.Report) (string, error) {
+// sendEmail sends a recipient's report, attaching the PDF when one is given
+func (s *Service) sendEmail(report *models.Report, recipient config.EmailRecipient, pdf []byte) error {
+	subject := fmt.Sprintf("AKS Mentions Report - %s (%d mentions)",
+		strings.Title(report.Period), report.TotalMentions)
+
+	preferencesURL, unsubscribeURL := s.preferenceLinks(recipient.Email)
+	data := &emailData{Report: report, PreferencesURL: preferencesURL, UnsubscribeURL: unsubscribeURL}
+
+	message := &emailMessage{
+		To:             recipient.Email,
+		Subject:        subject,
+		Text:           s.buildEmailText(data),
+		UnsubscribeURL: unsubscribeURL,
+	}
+
+	if recipient.Format != config.EmailFormatText {
+		htmlBody, err := s.buildEmailHTML(data)
+		if err != nil {
+			return fmt.Errorf("failed to build email HTML: %w", err)
+		}
+		message.HTML = htmlBody
+	}
+
+	if len(pdf) > 0 {
+		message.Attachment = &emailAttachment{
+			Name:        fmt.Sprintf("aks-mentions-report-%s.pdf", report.GeneratedAt.Format("2006-01-02")),
+			ContentType: "application/pdf",
+			Data:        pdf,
+		}
+	}
+
+	if s.emailSender == nil {
+		return fmt.Errorf("email Graph sender is not initialized")
+	}
+	return s.emailSender.SendEmail(message)
+}
+
+func (s *Service) buildEmailHTML(data *emailData) (string, error) {
 	tmpl := `
 <!DOCTYPE html>
 <html>
@@ -383,10 +710,16 @@ func (s *Service) buildEmailHTML(report *models.Report) (string, error) {
         <h1>AKS Mentions Report</h1>
         <p>{{.Period}} report generated on {{.GeneratedAt.Format "January 2, 2006 at 3:04 PM UTC"}}</p>
     </div>
+    {{if .ReportURL}}
+    <p><a href="{{.ReportURL}}" target="_blank">View the full report with charts</a></p>
+    {{end}}
 
     <div class="summary">
         <h2>Summary</h2>
         <p><strong>Total Mentions:</strong> {{.TotalMentions}}</p>
+        {{if .SentimentTrend}}
+        <p><strong>Sentiment Score:</strong> {{.SentimentTrend.Summary}}</p>
+        {{end}}
         {{if .Summary.sentiment}}
             {{range $sentiment, $count := .Summary.sentiment}}
                 <p><strong>{{$sentiment | title}} Mentions:</strong> {{$count}}</p>
@@ -400,22 +733,111 @@ func (s *Service) buildEmailHTML(report *models.Report) (string, error) {
         {{if lt $index 10}}
         <div class="mention {{$mention.Sentiment}}">
             <div class="mention-title">
-                <a href="{{$mention.URL}}" target="_blank">{{$mention.Title}}</a>
+                <a href="{{mentionLink $mention}}" target="_blank">{{$mention.Title}}</a>
             </div>
             <div class="mention-meta">
-                By {{$mention.Author}} on {{$mention.Source}} | {{$mention.CreatedAt.Format "Jan 2, 2006"}}
+                By {{$mention.Author}} on {{mentionSource $mention}} | {{$mention.CreatedAt.Format "Jan 2, 2006"}}
                 {{if $mention.Score}} | Score: {{printf "%d" $mention.Score}}{{end}}
+                {{if $mention.Relevance}} | Relevance: {{printf "%.2f" $mention.Relevance}}{{end}}
+                {{if $mention.Tags}} | Tags: {{join $mention.Tags ", "}}{{end}}
+                {{if $mention.SentimentPhrases}} | Negative: {{join $mention.SentimentPhrases ", "}}{{end}}
             </div>
+            {{if $mention.Advisories}}
+            <div class="mention-meta">
+                Advisories:
+                {{range $mention.Advisories}}<a href="{{.URL}}" target="_blank">{{.ID}}</a>{{if .Severity}} ({{.Severity}}){{end}} {{end}}
+            </div>
+            {{end}}
             {{if $mention.Content}}
-            <p>{{$mention.Content | truncate 200}}</p>
+            <p>{{mentionSnippet $mention}}</p>
             {{end}}
         </div>
         {{end}}
     {{end}}
     {{end}}
 
+    {{if .Unanswered}}
+    <h2>Needs an Answer</h2>
+    <ul>
+    {{range .Unanswered}}
+        <li><a href="{{.URL}}" target="_blank">{{.Title}}</a> - {{.Source}}, waiting {{waiting .CreatedAt}}</li>
+    {{end}}
+    </ul>
+    {{end}}
+
+    {{if .Resolved}}
+    <h2>Resolved Since Last Report</h2>
+    <ul>
+    {{range .Resolved}}
+        <li><a href="{{.Mention.URL}}" target="_blank">{{.Mention.Title}}</a> - {{.Mention.Source}}, {{.Resolution}}</li>
+    {{end}}
+    </ul>
+    {{end}}
+
+    {{if .DocsGaps}}
+    <h2>Documentation Gaps in {{.DocsGaps.Month}}</h2>
+    <ul>
+    {{range .DocsGaps.Topics}}
+        <li><strong>{{.Topic}}</strong> - {{.Count}} questions, e.g. {{range $i, $question := .Questions}}{{if $i}}, {{end}}<a href="{{$question.URL}}" target="_blank">{{$question.Title}}</a>{{end}}</li>
+    {{end}}
+    </ul>
+    {{end}}
+
+    {{if .PreviouslyAlerted}}
+    <h2>Previously Alerted</h2>
+    <ul>
+    {{range .PreviouslyAlerted}}
+        <li><a href="{{mentionLink .}}" target="_blank">{{.Title}}</a> - {{mentionSource .}}, {{alertedWhen .}}</li>
+    {{end}}
+    </ul>
+    {{end}}
+
+    {{if .ReleaseInsights}}
+    <h2>AKS Release Correlation</h2>
+    <ul>
+    {{range .ReleaseInsights}}
+        <li>{{.Summary}} (<a href="{{.ReleaseURL}}" target="_blank">{{.ReleaseName}}</a>)</li>
+    {{end}}
+    </ul>
+    {{end}}
+
+    {{if .NegativeComments}}
+    <h2>Notable Negative Comments</h2>
+    {{range $index, $comment := .NegativeComments}}
+        {{if lt $index 10}}
+        <div class="mention negative">
+            <div class="mention-meta">
+                <a href="{{$comment.URL}}" target="_blank">{{$comment.Author}}</a> | {{$comment.CreatedAt.Format "Jan 2, 2006"}}
+            </div>
+            <p>{{commentSnippet $comment}}</p>
+        </div>
+        {{end}}
+    {{end}}
+    {{end}}
+
+    {{if .Reliability}}
+    <h2>{{reliabilityTitle .Reliability}}</h2>
+    <ul>
+    {{range .Reliability.Sources}}
+        <li><strong>{{.Source}}</strong> - {{.Summary}}{{if .LastError}}<br><small>Last error: {{truncate .LastError 200}}</small>{{end}}</li>
+    {{end}}
+    </ul>
+    {{end}}
+
+    {{if .SkippedSources}}
+    <h2>Skipped Sources</h2>
+    <ul>
+    {{range .SkippedSources}}
+        <li><strong>{{.Source}}</strong> - {{skippedWhy .}}</li>
+    {{end}}
+    </ul>
+    {{end}}
+
     <hr>
     <p><small>This report was generated automatically by the AKS Mentions Bot.</small></p>
+    {{if .PreferencesURL}}
+    <p><small><a href="{{.PreferencesURL}}">Email preferences</a> | <a href="{{.UnsubscribeURL}}">Unsubscribe</a></small></p>
+    {{end}}
 </body>
 </html>
 `
@@ -424,11 +846,20 @@ func (s *Service) buildEmailHTML(report *models.Report) (string, error) {
 	t := template.New("email").Funcs(template.FuncMap{
 		"title": strings.Title,
 		"printf": fmt.Sprintf,
-		"truncate": func(s string, length int) string {
-			if len(s) <= length {
-				return s
-			}
-			return s[:length] + "..."
+		"waiting": waitingTime,
+		"join": strings.Join,
+		"mentionSource": mentionSource,
+		"mentionLink": mentionLink,
+		"alertedWhen": alertedWhen,
+		"skippedWhy": s.skippedWhy,
+		"reliabilityTitle": func(reliability *models.SourceReliability) string { return reliabilityTitle(reliability, nil) },
+		"truncate": s.truncateString,
+		"mentionSnippet": func(mention models.Mention) template.HTML {
+			keywords := s.mentionKeywords(mention)
+			return highlightHTML(s.mentionSnippet(mention, 200), keywords)
+		},
+		"commentSnippet": func(comment models.Comment) template.HTML {
+			return highlightHTML(matching.KeywordSnippet(comment.Content, s.config.Keywords, 200), s.config.Keywords)
 		},
 	})
 
@@ -438,22 +869,30 @@ func (s *Service) buildEmailHTML(report *models.Report) (string, error) {
 	}
 
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, report); err != nil {
+	if err := t.Execute(&buf, data); err != nil {
 		return "", err
 	}
 
 	return buf.String(), nil
 }
 
-func (s *Service) buildEmailText(report *models.Report) string {
+func (s *Service) buildEmailText(data *emailData) string {
+	report := data.Report
 	var text strings.Builder
 
 	text.WriteString(fmt.Sprintf("AKS Mentions Report - %s\n", strings.Title(report.Period)))
-	text.WriteString(fmt.Sprintf("Generated: %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05 UTC")))
+	text.WriteString(fmt.Sprintf("Generated: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05 UTC")))
+	if report.ReportURL != "" {
+		text.WriteString(fmt.Sprintf("Full report with charts: %s\n", report.ReportURL))
+	}
+	text.WriteString("\n")
 
 	text.WriteString("SUMMARY\n")
 	text.WriteString("=======\n")
 	text.WriteString(fmt.Sprintf("Total Mentions: %d\n", report.TotalMentions))
+	if report.SentimentTrend != nil {
+		text.WriteString(fmt.Sprintf("Sentiment Score: %s\n", report.SentimentTrend.Summary()))
+	}
 
 	if summary, ok := report.Summary["sentiment"].(map[string]int); ok {
 		for sentiment, count := range summary {
@@ -474,31 +913,257 @@ func (s *Service) buildEmailText(report *models.Report) string {
 			mention := report.Mentions[i]
 			text.WriteString(fmt.Sprintf("\n%d. %s\n", i+1, mention.Title))
 			text.WriteString(fmt.Sprintf("   Source: %s | Author: %s | Date: %s\n",
-				mention.Source, mention.Author, mention.CreatedAt.Format("Jan 2, 2006")))
-			text.WriteString(fmt.Sprintf("   URL: %s\n", mention.URL))
+				mentionSource(mention), mention.Author, mention.CreatedAt.Format("Jan 2, 2006")))
+			text.WriteString(fmt.Sprintf("   URL: %s\n", mentionLink(mention)))
+			if mention.Relevance > 0 {
+				text.WriteString(fmt.Sprintf("   Relevance: %.2f\n", mention.Relevance))
+			}
+			for _, advisory := range mention.Advisories {
+				text.WriteString(fmt.Sprintf("   Advisory: %s %s\n", advisory.ID, advisory.URL))
+			}
+			if len(mention.SentimentPhrases) > 0 {
+				text.WriteString(fmt.Sprintf("   Negative: %s\n", strings.Join(mention.SentimentPhrases, ", ")))
+			}
 			if mention.Content != "" {
-				content := mention.Content
-				if len(content) > 200 {
-					content = content[:200] + "..."
-				}
-				text.WriteString(fmt.Sprintf("   Content: %s\n", content))
+				keywords := s.mentionKeywords(mention)
+				text.WriteString(fmt.Sprintf("   Content: %s\n", highlightMarkdown(s.mentionSnippet(mention, 200), keywords)))
+			}
+		}
+	}
+
+	if len(report.Unanswered) > 0 {
+		text.WriteString("\nNEEDS AN ANSWER\n")
+		text.WriteString("===============\n")
+
+		for _, question := range report.Unanswered {
+			text.WriteString(fmt.Sprintf("\n- %s (%s, waiting %s)\n", question.Title, question.Source, waitingTime(question.CreatedAt)))
+			text.WriteString(fmt.Sprintf("  %s\n", question.URL))
+		}
+	}
+
+	if len(report.Resolved) > 0 {
+		text.WriteString("\nRESOLVED SINCE LAST REPORT\n")
+		text.WriteString("==========================\n")
+
+		for _, question := range report.Resolved {
+			text.WriteString(fmt.Sprintf("\n- %s (%s, %s)\n", question.Mention.Title, question.Mention.Source, question.Resolution()))
+			text.WriteString(fmt.Sprintf("  %s\n", question.Mention.URL))
+		}
+	}
+
+	if report.DocsGaps != nil {
+		title := "DOCUMENTATION GAPS IN " + strings.ToUpper(report.DocsGaps.Month)
+		text.WriteString("\n" + title + "\n")
+		text.WriteString(strings.Repeat("=", len(title)) + "\n")
+
+		for _, gap := range report.DocsGaps.Topics {
+			text.WriteString(fmt.Sprintf("\n- %s: %d questions\n", gap.Topic, gap.Count))
+			for _, question := range gap.Questions {
+				text.WriteString(fmt.Sprintf("  %s\n  %s\n", question.Title, question.URL))
+			}
+		}
+	}
+
+	if len(report.PreviouslyAlerted) > 0 {
+		text.WriteString("\nPREVIOUSLY ALERTED\n")
+		text.WriteString("==================\n")
+
+		for _, mention := range report.PreviouslyAlerted {
+			text.WriteString(fmt.Sprintf("\n- %s (%s, %s)\n", mention.Title, mentionSource(mention), alertedWhen(mention)))
+			text.WriteString(fmt.Sprintf("  %s\n", mentionLink(mention)))
+		}
+	}
+
+	if len(report.ReleaseInsights) > 0 {
+		text.WriteString("\nAKS RELEASE CORRELATION\n")
+		text.WriteString("=======================\n")
+
+		for _, insight := range report.ReleaseInsights {
+			text.WriteString(fmt.Sprintf("\n- %s\n", insight.Summary))
+			text.WriteString(fmt.Sprintf("  Release: %s (%s)\n", insight.ReleaseName, insight.ReleaseURL))
+		}
+	}
+
+	if len(report.NegativeComments) > 0 {
+		text.WriteString("\nNOTABLE NEGATIVE COMMENTS\n")
+		text.WriteString("=========================\n")
+
+		for i, comment := range report.NegativeComments {
+			if i >= 10 {
+				break
 			}
+			text.WriteString(fmt.Sprintf("\n- %s (%s)\n", comment.Author, comment.URL))
+			text.WriteString(fmt.Sprintf("  %s\n", highlightMarkdown(matching.KeywordSnippet(comment.Content, s.config.Keywords, 200), s.config.Keywords)))
+		}
+	}
+
+	if report.Reliability != nil {
+		title := strings.ToUpper(reliabilityTitle(report.Reliability, nil))
+		text.WriteString("\n" + title + "\n")
+		text.WriteString(strings.Repeat("=", len(title)) + "\n")
+
+		for _, rate := range report.Reliability.Sources {
+			text.WriteString(fmt.Sprintf("\n- %s: %s\n", rate.Source, rate.Summary()))
+			text.WriteString(fmt.Sprintf("  Last error: %s\n", s.truncateString(rate.LastError, 200)))
+		}
+	}
+
+	if len(report.SkippedSources) > 0 {
+		text.WriteString("\nSKIPPED SOURCES\n")
+		text.WriteString("===============\n")
+
+		for _, source := range report.SkippedSources {
+			text.WriteString(fmt.Sprintf("\n- %s: %s\n", source.Source, s.skippedWhy(source)))
 		}
 	}
 
 	text.WriteString("\n---\nThis report was generated automatically by the AKS Mentions Bot.\n")
+	if data.PreferencesURL != "" {
+		text.WriteString(fmt.Sprintf("Email preferences: %s\n", data.PreferencesURL))
+		text.WriteString(fmt.Sprintf("Unsubscribe: %s\n", data.UnsubscribeURL))
+	}
 
 	return text.String()
 }
 
-// SendAlert sends an urgent alert notification
+// SendAlert sends an alert to Teams and the webhook channels; email only carries periodic reports
 func (s *Service) SendAlert(alert *models.Alert) error {
-	// Implementation for urgent alerts
-	// This could send immediate notifications for critical issues
-	logrus.Infof("Alert would be sent: %s - %s", alert.Type, alert.Title)
+	alert = s.withMentionActions(alert)
+	var failures []deliveryFailure
+
+	if s.config.TeamsDeliveryMode == "graph" && s.config.TeamsEnabled() {
+		if err := s.sendAlertToTeams(alert); err != nil {
+			logrus.Errorf("Failed to send Teams alert: %v", err)
+			failures = append(failures, deliveryFailure{target: targetTeamsGraph, err: err})
+		}
+	}
+
+	for _, channel := range s.config.Channels() {
+		if err := s.sendAlertToChannel(channel, alert); err != nil {
+			logrus.Errorf("Failed to send alert to channel %s: %v", channel.Name, err)
+			failures = append(failures, deliveryFailure{target: channel.Name, err: err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to send alert: %s", s.queueFailures(QueueKindAlert, nil, alert, failures))
+	}
+
+	logrus.Infof("Sent %s alert: %s", alert.Type, alert.Title)
 	return nil
 }
 
+// sendAlertToTeams posts an alert to the Teams channel through Microsoft Graph
+func (s *Service) sendAlertToTeams(alert *models.Alert) error {
+	if s.graphSender == nil {
+		return fmt.Errorf("teams Graph sender is not initialized")
+	}
+	return s.graphSender.SendAlert(alert)
+}
+
+// buildTeamsAlert renders an alert as a MessageCard colored by severity
+func buildTeamsAlert(alert *models.Alert) *TeamsMessage {
+	message := &TeamsMessage{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: alertColor(alert),
+		Title:      alert.Title,
+		Text:       alert.Message,
+	}
+
+	if alert.Mention != nil {
+		message.Sections = append(message.Sections, TeamsSection{
+			ActivityTitle:    fmt.Sprintf("[%s](%s)", alert.Mention.Title, mentionLink(*alert.Mention)),
+			ActivitySubtitle: alert.Mention.Source,
+			Markdown:         true,
+		})
+	}
+	for _, action := range alert.Actions {
+		message.PotentialAction = append(message.PotentialAction, TeamsAction{
+			Type:    "OpenUri",
+			Name:    action.Name,
+			Targets: []TeamsActionURI{{OS: "default", URI: action.URL}},
+		})
+	}
+	return message
+}
+
+func alertColor(alert *models.Alert) string {
+	switch alert.Type {
+	case "critical":
+		return "D13438"
+	case "urgent":
+		return "FF8C00"
+	default:
+		return "0078D4"
+	}
+}
+
+func alertImportance(alert *models.Alert) string {
+	switch alert.Type {
+	case "critical":
+		return "urgent"
+	case "urgent":
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// mentionSource names the platform a mention came from, with its post type when known and
+// how often its content was posted when near-duplicates were collapsed into it, e.g.
+// "hackernews (Ask HN)" or "reddit, posted 14 times"
+func mentionSource(mention models.Mention) string {
+	source := mention.Source
+	if label := models.PostTypeLabel(mention.PostType); label != "" {
+		source = fmt.Sprintf("%s (%s)", mention.Source, label)
+	}
+	if mention.DuplicateCount > 1 {
+		source = fmt.Sprintf("%s, posted %d times", source, mention.DuplicateCount)
+	}
+	return source
+}
+
+// mentionLink is where notifications link a mention: the bot's redirect recording clicks
+// with click tracking on, the bot's page for it when there is one, otherwise the original post
+func mentionLink(mention models.Mention) string {
+	if mention.ClickURL != "" {
+		return mention.ClickURL
+	}
+	if mention.Permalink != "" {
+		return mention.Permalink
+	}
+	return mention.URL
+}
+
+// alertedWhen describes when a mention was sent in an urgent notification, e.g. "alerted Jan 2 15:04"
+func alertedWhen(mention models.Mention) string {
+	if mention.AlertedAt == nil {
+		return "alerted earlier"
+	}
+	return "alerted " + mention.AlertedAt.Format("Jan 2 15:04")
+}
+
+// skippedWhy describes why a source was skipped and when it is tried again
+func (s *Service) skippedWhy(source models.SkippedSource) string {
+	return fmt.Sprintf("failed %d times in a row, skipped until %s: %s",
+		source.Failures, source.Until.UTC().Format("Jan 2 15:04 UTC"), s.truncateString(source.LastError, 200))
+}
+
+// reliabilityTitle names the source reliability section with its error budget
+func reliabilityTitle(reliability *models.SourceReliability, m messages) string {
+	return m.sprintf("Source Reliability, Last 7 Days (error budget %.0f%%)", 100*reliability.Budget)
+}
+
+// waitingTime describes how long a question has gone unanswered, e.g. "5h" or "3d"
+func waitingTime(createdAt time.Time) string {
+	age := time.Since(createdAt)
+	if age < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(age.Hours()/24))
+}
+
 // truncateString truncates a string to maxLength and adds "..." if truncated
 func (s *Service) truncateString(str string, maxLength int) string {
 	if len(str) <= maxLength {
@@ -0,0 +1,140 @@
+package notifications
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// slackMaxMentions is how many mentions a Slack report lists, keeping it well inside Slack's
+// 50 block limit
+const slackMaxMentions = 10
+
+// SlackMessage is a Slack incoming webhook message
+type SlackMessage struct {
+	Text   string       `json:"text"` // Shown in notifications and by clients without blocks
+	Blocks []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Block Kit layout block
+type SlackBlock struct {
+	Type     string      `json:"type"` // "header", "section", "context" or "divider"
+	Text     *SlackText  `json:"text,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackText is a Block Kit text object
+type SlackText struct {
+	Type string `json:"type"` // "plain_text" or "mrkdwn"
+	Text string `json:"text"`
+}
+
+// slackEscape escapes the characters Slack treats as markup in mrkdwn text
+var slackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackLink renders a mrkdwn link; the label can't contain "|"
+func slackLink(url, label string) string {
+	return fmt.Sprintf("<%s|%s>", url, strings.ReplaceAll(slackEscape.Replace(label), "|", "-"))
+}
+
+// buildSlackMessage renders a report with its summary and the top mentions, with its headers
+// and labels in the language of m
+func (s *Service) buildSlackMessage(report *models.Report, m messages) *SlackMessage {
+	title := reportTitle(report, m)
+	summary := m.sprintf("Found %d mentions in the last %s", report.TotalMentions, m.text(report.Period))
+
+	message := &SlackMessage{
+		Text: title + ": " + summary,
+		Blocks: []SlackBlock{
+			{Type: "header", Text: &SlackText{Type: "plain_text", Text: title}},
+		},
+	}
+
+	facts := []string{summary}
+	if sentiment, ok := report.Summary["sentiment"].(map[string]int); ok {
+		names := make([]string, 0, len(sentiment))
+		for name := range sentiment {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			facts = append(facts, fmt.Sprintf("%s: %d", m.sentiment(name), sentiment[name]))
+		}
+	}
+	if report.SentimentTrend != nil {
+		facts = append(facts, m.text("Sentiment Score")+": "+report.SentimentTrend.Summary())
+	}
+	message.Blocks = append(message.Blocks, SlackBlock{
+		Type: "section",
+		Text: &SlackText{Type: "mrkdwn", Text: slackEscape.Replace(strings.Join(facts, "\n"))},
+	})
+
+	if len(report.Mentions) > 0 {
+		message.Blocks = append(message.Blocks, SlackBlock{Type: "divider"})
+	}
+	for i, mention := range report.Mentions {
+		if i >= slackMaxMentions {
+			break
+		}
+		text := fmt.Sprintf("*%s* - %s (%s)", slackLink(mentionLink(mention), s.truncateString(mention.Title, 150)),
+			slackEscape.Replace(mentionSource(mention)), m.date("Jan 2", mention.CreatedAt))
+		if mention.Content != "" {
+			text += "\n" + slackEscape.Replace(s.mentionSnippet(mention, 200))
+		}
+		message.Blocks = append(message.Blocks, SlackBlock{
+			Type: "section",
+			Text: &SlackText{Type: "mrkdwn", Text: text},
+		})
+	}
+
+	var footer []string
+	if len(report.Mentions) > slackMaxMentions {
+		footer = append(footer, m.sprintf("Showing %d of %d mentions", slackMaxMentions, len(report.Mentions)))
+	}
+	if omitted := omittedSummary(report); omitted != "" {
+		footer = append(footer, slackEscape.Replace(omitted))
+	}
+	if report.ReportURL != "" {
+		footer = append(footer, slackLink(report.ReportURL, m.text("Full report")))
+	}
+	if len(footer) > 0 {
+		message.Blocks = append(message.Blocks, SlackBlock{
+			Type:     "context",
+			Elements: []SlackText{{Type: "mrkdwn", Text: strings.Join(footer, " | ")}},
+		})
+	}
+
+	return message
+}
+
+// buildSlackAlert renders an alert with a severity marker and its mention
+func buildSlackAlert(alert *models.Alert) *SlackMessage {
+	marker := ":information_source:"
+	switch alert.Type {
+	case "critical":
+		marker = ":rotating_light:"
+	case "urgent":
+		marker = ":warning:"
+	}
+
+	text := fmt.Sprintf("%s *%s*\n%s", marker, slackEscape.Replace(alert.Title), slackEscape.Replace(alert.Message))
+	if alert.Mention != nil {
+		text += fmt.Sprintf("\n%s - %s", slackLink(mentionLink(*alert.Mention), alert.Mention.Title), slackEscape.Replace(alert.Mention.Source))
+	}
+	for i, action := range alert.Actions {
+		separator := " | "
+		if i == 0 {
+			separator = "\n"
+		}
+		text += separator + slackLink(action.URL, action.Name)
+	}
+
+	return &SlackMessage{
+		Text: alert.Title,
+		Blocks: []SlackBlock{
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: text}},
+		},
+	}
+}
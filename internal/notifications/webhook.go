@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of "<timestamp>.<body>"
+	WebhookSignatureHeader = "X-AKS-Mentions-Signature"
+	// WebhookTimestampHeader carries the Unix timestamp used in the signature
+	WebhookTimestampHeader = "X-AKS-Mentions-Timestamp"
+	// WebhookEventHeader carries the event type ("report" or "alert")
+	WebhookEventHeader = "X-AKS-Mentions-Event"
+)
+
+// WebhookEvent is the envelope posted to generic outbound webhooks
+type WebhookEvent struct {
+	Type    string      `json:"type"` // "report" or "alert"
+	SentAt  time.Time   `json:"sent_at"`
+	Payload interface{} `json:"payload"`
+}
+
+// WebhookSender posts report and alert JSON to arbitrary webhook URLs (n8n, Zapier,
+// internal services), signing each request with HMAC-SHA256 when a secret is configured
+type WebhookSender struct {
+	client *resty.Client
+	urls   []string
+	secret string
+}
+
+// NewWebhookSender creates a sender for the given webhook URLs
+func NewWebhookSender(urls []string, secret string) *WebhookSender {
+	var cleaned []string
+	for _, url := range urls {
+		if url = strings.TrimSpace(url); url != "" {
+			cleaned = append(cleaned, url)
+		}
+	}
+
+	return &WebhookSender{
+		client: resty.New().SetTimeout(30 * time.Second),
+		urls:   cleaned,
+		secret: secret,
+	}
+}
+
+// Enabled reports whether any webhook URLs are configured
+func (w *WebhookSender) Enabled() bool {
+	return len(w.urls) > 0
+}
+
+// Send posts the event to every configured URL, returning the combined failures
+func (w *WebhookSender) Send(eventType string, payload interface{}) error {
+	body, err := webhookBody(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	var errors []string
+	for _, url := range w.urls {
+		if err := w.post(url, eventType, body); err != nil {
+			logrus.Errorf("Failed to deliver %s to webhook %s: %v", eventType, url, err)
+			errors = append(errors, err.Error())
+			continue
+		}
+		logrus.Infof("Delivered %s to webhook %s", eventType, url)
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%d of %d webhooks failed: %s", len(errors), len(w.urls), strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// sendTo posts the event to a single URL, whether or not it is one of the configured URLs
+func (w *WebhookSender) sendTo(url, eventType string, payload interface{}) error {
+	body, err := webhookBody(eventType, payload)
+	if err != nil {
+		return err
+	}
+	return w.post(url, eventType, body)
+}
+
+func webhookBody(eventType string, payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(WebhookEvent{
+		Type:    eventType,
+		SentAt:  time.Now().UTC(),
+		Payload: payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return body, nil
+}
+
+func (w *WebhookSender) post(url, eventType string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := w.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader(WebhookEventHeader, eventType).
+		SetHeader(WebhookTimestampHeader, timestamp).
+		SetBody(body)
+
+	if w.secret != "" {
+		req.SetHeader(WebhookSignatureHeader, "sha256="+SignWebhookPayload(w.secret, timestamp, body))
+	}
+
+	resp, err := req.Post(url)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return &statusError{status: resp.StatusCode(), body: string(resp.Body())}
+	}
+
+	return nil
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 of "<timestamp>.<body>" so receivers
+// can verify both the payload and its freshness
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
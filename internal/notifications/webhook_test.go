@@ -0,0 +1,59 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSender_Send(t *testing.T) {
+	var (
+		body    []byte
+		headers http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		headers = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender([]string{server.URL, " "}, "secret")
+	require.True(t, sender.Enabled())
+
+	err := sender.Send("alert", &models.Alert{ID: "alert_1", Title: "AKS outage"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "alert", headers.Get(WebhookEventHeader))
+	timestamp := headers.Get(WebhookTimestampHeader)
+	require.NotEmpty(t, timestamp)
+	assert.Equal(t, "sha256="+SignWebhookPayload("secret", timestamp, body), headers.Get(WebhookSignatureHeader))
+
+	var event struct {
+		Type    string       `json:"type"`
+		Payload models.Alert `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "alert", event.Type)
+	assert.Equal(t, "AKS outage", event.Payload.Title)
+}
+
+func TestWebhookSender_SendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(WebhookSignatureHeader))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender([]string{server.URL}, "")
+	err := sender.Send("report", &models.Report{TotalMentions: 1})
+	assert.ErrorContains(t, err, "1 of 1 webhooks failed")
+
+	assert.False(t, NewWebhookSender(nil, "").Enabled())
+}
@@ -0,0 +1,203 @@
+// Package parquet writes flat tables as Apache Parquet files. It covers what the mention
+// export needs and no more: one row group, required columns, PLAIN encoding and no
+// compression, which every Parquet reader (Synapse, Fabric, ADX, Spark, DuckDB) accepts.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrColumnType is returned when a row value does not match its column's kind
+var ErrColumnType = errors.New("value does not match column type")
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// Kind is the type of a column's values
+type Kind int
+
+const (
+	String    Kind = iota // UTF-8 string
+	Int64                 // 64-bit signed integer
+	Double                // 64-bit float
+	Bool                  // Boolean
+	Timestamp             // time.Time, stored as UTC milliseconds
+)
+
+// Column is one required column of a table
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Table collects rows and encodes them as a Parquet file
+type Table struct {
+	columns []Column
+	rows    int
+	values  [][]interface{} // Column-major
+}
+
+// NewTable creates an empty table with the given columns
+func NewTable(columns ...Column) *Table {
+	return &Table{columns: columns, values: make([][]interface{}, len(columns))}
+}
+
+// Rows returns the number of rows appended so far
+func (t *Table) Rows() int {
+	return t.rows
+}
+
+// Append adds a row with one value per column, in column order
+func (t *Table) Append(values ...interface{}) error {
+	if len(values) != len(t.columns) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(t.columns))
+	}
+	for i, value := range values {
+		if !t.columns[i].Kind.accepts(value) {
+			return fmt.Errorf("%w: %s is %T", ErrColumnType, t.columns[i].Name, value)
+		}
+	}
+	for i, value := range values {
+		t.values[i] = append(t.values[i], value)
+	}
+	t.rows++
+	return nil
+}
+
+// Encode returns the table as a Parquet file
+func (t *Table) Encode() ([]byte, error) {
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	chunks := make([]columnChunk, len(t.columns))
+	for i, column := range t.columns {
+		page := column.Kind.encode(t.values[i])
+
+		var header compactWriter
+		header.pageHeader(len(page), t.rows)
+
+		chunks[i] = columnChunk{
+			column: column,
+			offset: int64(file.Len()),
+			size:   int64(header.buf.Len() + len(page)),
+			values: int64(t.rows),
+		}
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+	}
+
+	var footer compactWriter
+	footer.fileMetaData(t.columns, chunks, int64(t.rows))
+	if footer.buf.Len() > math.MaxInt32 {
+		return nil, fmt.Errorf("parquet footer too large")
+	}
+	file.Write(footer.buf.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(footer.buf.Len()))
+	file.WriteString(magic)
+
+	return file.Bytes(), nil
+}
+
+func (k Kind) accepts(value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return k == String
+	case int64:
+		return k == Int64
+	case float64:
+		return k == Double
+	case bool:
+		return k == Bool
+	case time.Time:
+		return k == Timestamp
+	}
+	return false
+}
+
+// Parquet physical types
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+)
+
+// Parquet converted types
+const (
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+)
+
+// physicalType is how the kind is stored
+func (k Kind) physicalType() int32 {
+	switch k {
+	case String:
+		return typeByteArray
+	case Double:
+		return typeDouble
+	case Bool:
+		return typeBoolean
+	default:
+		return typeInt64
+	}
+}
+
+// convertedType is how readers should interpret the stored values, if not as is
+func (k Kind) convertedType() (int32, bool) {
+	switch k {
+	case String:
+		return convertedUTF8, true
+	case Timestamp:
+		return convertedTimestampMillis, true
+	}
+	return 0, false
+}
+
+// encode PLAIN-encodes a column's values. Required columns have no definition or
+// repetition levels, so this is the whole page.
+func (k Kind) encode(values []interface{}) []byte {
+	var page bytes.Buffer
+	switch k {
+	case String:
+		for _, value := range values {
+			s := value.(string)
+			binary.Write(&page, binary.LittleEndian, uint32(len(s)))
+			page.WriteString(s)
+		}
+	case Int64:
+		for _, value := range values {
+			binary.Write(&page, binary.LittleEndian, value.(int64))
+		}
+	case Timestamp:
+		for _, value := range values {
+			binary.Write(&page, binary.LittleEndian, value.(time.Time).UnixMilli())
+		}
+	case Double:
+		for _, value := range values {
+			binary.Write(&page, binary.LittleEndian, math.Float64bits(value.(float64)))
+		}
+	case Bool:
+		// Bit-packed, least significant bit first
+		packed := make([]byte, (len(values)+7)/8)
+		for i, value := range values {
+			if value.(bool) {
+				packed[i/8] |= 1 << (i % 8)
+			}
+		}
+		page.Write(packed)
+	}
+	return page.Bytes()
+}
+
+// columnChunk locates a column's single data page in the file
+type columnChunk struct {
+	column Column
+	offset int64
+	size   int64
+	values int64
+}
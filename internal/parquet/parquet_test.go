@@ -0,0 +1,208 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compactReader decodes Thrift compact structs into field ID maps, independently of the
+// writer, so the tests check the bytes against the protocol rather than against itself
+type compactReader struct {
+	t    *testing.T
+	data *bytes.Reader
+}
+
+func (r *compactReader) varint() uint64 {
+	value, err := binary.ReadUvarint(r.data)
+	require.NoError(r.t, err)
+	return value
+}
+
+func (r *compactReader) signed() int64 {
+	value := r.varint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *compactReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header, err := r.data.ReadByte()
+		require.NoError(r.t, err)
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.signed())
+		}
+		last = id
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+func (r *compactReader) value(valueType byte) interface{} {
+	switch valueType {
+	case compactI32, compactI64:
+		return r.signed()
+	case compactBinary:
+		value := make([]byte, r.varint())
+		_, err := r.data.Read(value)
+		require.NoError(r.t, err)
+		return string(value)
+	case compactList:
+		header, err := r.data.ReadByte()
+		require.NoError(r.t, err)
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case compactStruct:
+		return r.readStruct()
+	}
+	r.t.Fatalf("unexpected compact type %d", valueType)
+	return nil
+}
+
+func TestTable_Encode(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	table := NewTable(
+		Column{Name: "id", Kind: String},
+		Column{Name: "created_at", Kind: Timestamp},
+		Column{Name: "score", Kind: Int64},
+		Column{Name: "relevance", Kind: Double},
+		Column{Name: "is_question", Kind: Bool},
+	)
+	require.NoError(t, table.Append("reddit_1", created, int64(42), 0.9, true))
+	require.NoError(t, table.Append("reddit_2", created.Add(time.Hour), int64(-1), 0.5, false))
+	assert.Equal(t, 2, table.Rows())
+
+	file, err := table.Encode()
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(file, []byte(magic)))
+	require.True(t, bytes.HasSuffix(file, []byte(magic)))
+
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := &compactReader{t: t, data: bytes.NewReader(file[len(file)-8-footerLen : len(file)-8])}
+	meta := footer.readStruct()
+
+	assert.EqualValues(t, 2, meta[3], "num_rows")
+	schema := meta[2].([]interface{})
+	require.Len(t, schema, 6)
+	assert.EqualValues(t, 5, schema[0].(map[int16]interface{})[5], "root num_children")
+	id := schema[1].(map[int16]interface{})
+	assert.Equal(t, "id", id[4])
+	assert.EqualValues(t, typeByteArray, id[1])
+	assert.EqualValues(t, convertedUTF8, id[6])
+	assert.EqualValues(t, convertedTimestampMillis, schema[2].(map[int16]interface{})[6])
+
+	rowGroup := meta[4].([]interface{})[0].(map[int16]interface{})
+	chunks := rowGroup[1].([]interface{})
+	require.Len(t, chunks, 5)
+
+	// Read each column's page back from the offset its metadata records
+	page := func(column int) []byte {
+		chunkMeta := chunks[column].(map[int16]interface{})[3].(map[int16]interface{})
+		offset := chunkMeta[9].(int64)
+		reader := &compactReader{t: t, data: bytes.NewReader(file[offset:])}
+		header := reader.readStruct()
+		assert.EqualValues(t, 2, header[5].(map[int16]interface{})[1], "num_values")
+		start := offset + reader.data.Size() - int64(reader.data.Len())
+		return file[start : start+header[3].(int64)]
+	}
+
+	ids := page(0)
+	assert.Equal(t, "reddit_1", string(ids[4:12]))
+	assert.Equal(t, "reddit_2", string(ids[16:24]))
+	assert.Equal(t, created.UnixMilli(), int64(binary.LittleEndian.Uint64(page(1))))
+	assert.Equal(t, int64(-1), int64(binary.LittleEndian.Uint64(page(2)[8:])))
+	assert.Equal(t, 0.5, math.Float64frombits(binary.LittleEndian.Uint64(page(3)[8:])))
+	assert.Equal(t, []byte{0x01}, page(4))
+}
+
+func TestTable_Append(t *testing.T) {
+	table := NewTable(Column{Name: "score", Kind: Int64})
+
+	assert.ErrorIs(t, table.Append(42), ErrColumnType, "int is not int64")
+	assert.Error(t, table.Append(int64(1), int64(2)))
+	assert.Equal(t, 0, table.Rows())
+}
+
+// readWithPyArrow reads a Parquet file with Apache Arrow, the reference implementation,
+// returning its rows as JSON, or skips the test when pyarrow is not installed
+func readWithPyArrow(t *testing.T, file []byte) []map[string]interface{} {
+	python, err := exec.LookPath("python3")
+	if err != nil || exec.Command(python, "-c", "import pyarrow.parquet").Run() != nil {
+		t.Skip("python3 with pyarrow is not installed")
+	}
+
+	path := filepath.Join(t.TempDir(), "mentions.parquet")
+	require.NoError(t, os.WriteFile(path, file, 0o644))
+	script := `import json, sys, pyarrow.parquet as pq
+table = pq.read_table(sys.argv[1])
+print(json.dumps(table.to_pylist(), default=lambda value: value.isoformat()))`
+	output, err := exec.Command(python, "-c", script, path).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var rows []map[string]interface{}
+	require.NoError(t, json.Unmarshal(output, &rows))
+	return rows
+}
+
+func TestTable_Encode_PyArrow(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	columns := []Column{
+		{Name: "id", Kind: String},
+		{Name: "created_at", Kind: Timestamp},
+		{Name: "score", Kind: Int64},
+		{Name: "relevance", Kind: Double},
+		{Name: "is_question", Kind: Bool},
+	}
+	// More than 15 columns and rows exercise the long list headers
+	for i := len(columns); i < 17; i++ {
+		columns = append(columns, Column{Name: fmt.Sprintf("extra_%d", i), Kind: String})
+	}
+	table := NewTable(columns...)
+	for row := 0; row < 20; row++ {
+		values := []interface{}{fmt.Sprintf("reddit_%d", row), created.Add(time.Duration(row) * time.Hour), int64(row - 1), float64(row) / 4, row%3 == 0}
+		for i := 5; i < len(columns); i++ {
+			values = append(values, strings.Repeat("é", row%2))
+		}
+		require.NoError(t, table.Append(values...))
+	}
+	file, err := table.Encode()
+	require.NoError(t, err)
+
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	meta := (&compactReader{t: t, data: bytes.NewReader(file[len(file)-8-footerLen : len(file)-8])}).readStruct()
+	assert.EqualValues(t, 20, meta[3], "num_rows")
+	assert.Len(t, meta[2], 18, "schema")
+
+	rows := readWithPyArrow(t, file)
+	require.Len(t, rows, 20)
+	assert.Equal(t, "reddit_7", rows[7]["id"])
+	assert.True(t, strings.HasPrefix(rows[7]["created_at"].(string), "2024-05-01T19:30:00"), rows[7]["created_at"])
+	assert.EqualValues(t, 6, rows[7]["score"])
+	assert.EqualValues(t, 1.75, rows[7]["relevance"])
+	assert.Equal(t, false, rows[7]["is_question"])
+	assert.Equal(t, true, rows[9]["is_question"])
+	assert.Equal(t, "é", rows[7]["extra_16"])
+	assert.Equal(t, "", rows[8]["extra_16"])
+}
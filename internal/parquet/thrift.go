@@ -0,0 +1,174 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol field types
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// Parquet enum values used in page headers and column metadata
+const (
+	pageTypeData       = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	repetitionRequired = 0
+)
+
+// createdBy identifies the writer in the file metadata
+const createdBy = "aks-mentions-bot"
+
+// compactWriter writes the Thrift compact protocol structures of the Parquet format
+// (https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift)
+type compactWriter struct {
+	buf       bytes.Buffer
+	lastField []int16 // Last field ID written in each open struct
+}
+
+// pageHeader writes the header of a PLAIN, uncompressed data page
+func (w *compactWriter) pageHeader(size, values int) {
+	w.beginStruct()
+	w.i32Field(1, pageTypeData)
+	w.i32Field(2, int32(size))
+	w.i32Field(3, int32(size))
+	w.fieldHeader(5, compactStruct)
+	w.beginStruct()
+	w.i32Field(1, int32(values))
+	w.i32Field(2, encodingPlain)
+	w.i32Field(3, encodingRLE)
+	w.i32Field(4, encodingRLE)
+	w.endStruct()
+	w.endStruct()
+}
+
+// fileMetaData writes the footer describing the schema and the single row group
+func (w *compactWriter) fileMetaData(columns []Column, chunks []columnChunk, rows int64) {
+	w.beginStruct()
+	w.i32Field(1, 1)
+
+	w.fieldHeader(2, compactList)
+	w.listHeader(len(columns)+1, compactStruct)
+	w.beginStruct()
+	w.binaryField(4, "schema")
+	w.i32Field(5, int32(len(columns)))
+	w.endStruct()
+	for _, column := range columns {
+		w.beginStruct()
+		w.i32Field(1, column.Kind.physicalType())
+		w.i32Field(3, repetitionRequired)
+		w.binaryField(4, column.Name)
+		if converted, ok := column.Kind.convertedType(); ok {
+			w.i32Field(6, converted)
+		}
+		w.endStruct()
+	}
+
+	w.i64Field(3, rows)
+
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+	w.fieldHeader(4, compactList)
+	w.listHeader(1, compactStruct)
+	w.beginStruct()
+	w.fieldHeader(1, compactList)
+	w.listHeader(len(chunks), compactStruct)
+	for _, chunk := range chunks {
+		w.columnChunk(chunk)
+	}
+	w.i64Field(2, totalSize)
+	w.i64Field(3, rows)
+	w.endStruct()
+
+	w.binaryField(6, createdBy)
+	w.endStruct()
+}
+
+func (w *compactWriter) columnChunk(chunk columnChunk) {
+	w.beginStruct()
+	w.i64Field(2, chunk.offset)
+	w.fieldHeader(3, compactStruct)
+	w.beginStruct()
+	w.i32Field(1, chunk.column.Kind.physicalType())
+	w.fieldHeader(2, compactList)
+	w.listHeader(1, compactI32)
+	w.varint(zigzag(encodingPlain))
+	w.fieldHeader(3, compactList)
+	w.listHeader(1, compactBinary)
+	w.binary(chunk.column.Name)
+	w.i32Field(4, codecUncompressed)
+	w.i64Field(5, chunk.values)
+	w.i64Field(6, chunk.size)
+	w.i64Field(7, chunk.size)
+	w.i64Field(9, chunk.offset)
+	w.endStruct()
+	w.endStruct()
+}
+
+func (w *compactWriter) beginStruct() {
+	w.lastField = append(w.lastField, 0)
+}
+
+func (w *compactWriter) endStruct() {
+	w.buf.WriteByte(0) // Stop field
+	w.lastField = w.lastField[:len(w.lastField)-1]
+}
+
+// fieldHeader writes a field's ID, as a delta from the previous field when it fits, and type
+func (w *compactWriter) fieldHeader(id int16, fieldType byte) {
+	last := &w.lastField[len(w.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (w *compactWriter) listHeader(size int, elemType byte) {
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemType)
+	w.varint(uint64(size))
+}
+
+func (w *compactWriter) i32Field(id int16, value int32) {
+	w.fieldHeader(id, compactI32)
+	w.varint(zigzag(int64(value)))
+}
+
+func (w *compactWriter) i64Field(id int16, value int64) {
+	w.fieldHeader(id, compactI64)
+	w.varint(zigzag(value))
+}
+
+func (w *compactWriter) binaryField(id int16, value string) {
+	w.fieldHeader(id, compactBinary)
+	w.binary(value)
+}
+
+func (w *compactWriter) binary(value string) {
+	w.varint(uint64(len(value)))
+	w.buf.WriteString(value)
+}
+
+func (w *compactWriter) varint(value uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	w.buf.Write(scratch[:binary.PutUvarint(scratch[:], value)])
+}
+
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
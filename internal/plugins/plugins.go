@@ -0,0 +1,14 @@
+// Package plugins compiles source plugins into the bot. A plugin is a package that calls
+// sources.Register from its init function; it is wired in by a file in this package that
+// imports it for that side effect, guarded by a build tag so private sources are only built
+// where wanted:
+//
+//	//go:build contoso
+//
+//	package plugins
+//
+//	import _ "contoso.example/aks-mentions/forum"
+//
+// and then `go build -tags contoso ./cmd/bot`. Plugins are toggled with <NAME>_ENABLED like
+// the built-in sources and read any other settings from the environment themselves.
+package plugins
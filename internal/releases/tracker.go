@@ -0,0 +1,273 @@
+package releases
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultReleasesURL is the AKS release tracker on GitHub
+	DefaultReleasesURL = "https://api.github.com/repos/Azure/AKS/releases"
+	// cacheTTL controls how long fetched release notes are reused
+	cacheTTL = 6 * time.Hour
+	// minCorrelatedMentions is the smallest number of mentions worth reporting for a version
+	minCorrelatedMentions = 2
+	// maxSpikeLag is the longest gap between a release and a mention spike we attribute to it
+	maxSpikeLag = 14
+)
+
+// versionPattern matches Kubernetes minor versions such as "1.30", "v1.30" or "1.30.2"
+var versionPattern = regexp.MustCompile(`\b[vV]?(1\.\d{2})(?:\.\d+)?\b`)
+
+// gaPattern identifies release-note lines announcing general availability
+var gaPattern = regexp.MustCompile(`(?i)\bGA\b|generally available|general availability`)
+
+// Release is a single AKS release from the release tracker
+type Release struct {
+	Name               string
+	URL                string
+	PublishedAt        time.Time
+	KubernetesVersions []string // Kubernetes minor versions referenced in the release notes
+	GAVersions         []string // Versions the release notes announce as generally available
+}
+
+type githubRelease struct {
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	HTMLURL     string    `json:"html_url"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+	Draft       bool      `json:"draft"`
+}
+
+// Tracker fetches AKS release notes and correlates them with mentions
+type Tracker struct {
+	client    *resty.Client
+	url       string
+	mu        sync.Mutex
+	releases  []Release
+	fetchedAt time.Time
+}
+
+// NewTracker creates a tracker for the given releases URL (DefaultReleasesURL if empty)
+func NewTracker(url string) *Tracker {
+	if url == "" {
+		url = DefaultReleasesURL
+	}
+
+	return &Tracker{
+		client: resty.New().SetTimeout(15 * time.Second),
+		url:    url,
+	}
+}
+
+// Releases returns recent AKS releases, oldest first, using a cached copy when fresh
+func (t *Tracker) Releases() ([]Release, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.releases != nil && time.Since(t.fetchedAt) < cacheTTL {
+		return t.releases, nil
+	}
+
+	var payload []githubRelease
+	resp, err := t.client.R().
+		SetHeader("Accept", "application/vnd.github+json").
+		SetQueryParam("per_page", "50").
+		SetResult(&payload).
+		Get(t.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch AKS releases: %w", err)
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("AKS releases API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	releases := make([]Release, 0, len(payload))
+	for _, raw := range payload {
+		if raw.Draft {
+			continue
+		}
+		releases = append(releases, parseRelease(raw))
+	}
+	sort.Slice(releases, func(a, b int) bool { return releases[a].PublishedAt.Before(releases[b].PublishedAt) })
+
+	logrus.Debugf("Fetched %d AKS releases", len(releases))
+	t.releases = releases
+	t.fetchedAt = time.Now()
+	return releases, nil
+}
+
+// Correlate fetches releases and annotates the mentions that reference Kubernetes versions
+func (t *Tracker) Correlate(mentions []models.Mention) ([]models.ReleaseInsight, error) {
+	releases, err := t.Releases()
+	if err != nil {
+		return nil, err
+	}
+	return Correlate(releases, mentions), nil
+}
+
+func parseRelease(raw githubRelease) Release {
+	name := raw.Name
+	if name == "" {
+		name = raw.TagName
+	}
+
+	release := Release{
+		Name:               name,
+		URL:                raw.HTMLURL,
+		PublishedAt:        raw.PublishedAt,
+		KubernetesVersions: extractVersions(raw.Body),
+	}
+
+	for _, line := range strings.Split(raw.Body, "\n") {
+		if gaPattern.MatchString(line) {
+			release.GAVersions = appendUnique(release.GAVersions, extractVersions(line)...)
+		}
+	}
+
+	return release
+}
+
+// Correlate groups mentions by the Kubernetes version they reference and relates each
+// group to the AKS release that shipped that version. releases must be oldest first.
+func Correlate(releases []Release, mentions []models.Mention) []models.ReleaseInsight {
+	byVersion := make(map[string][]models.Mention)
+	for _, mention := range mentions {
+		for _, version := range extractVersions(mention.Title + "\n" + mention.Content) {
+			byVersion[version] = append(byVersion[version], mention)
+		}
+	}
+
+	var insights []models.ReleaseInsight
+	for version, versionMentions := range byVersion {
+		if len(versionMentions) < minCorrelatedMentions {
+			continue
+		}
+
+		release, ok := releaseFor(releases, version)
+		if !ok {
+			continue
+		}
+
+		insights = append(insights, buildInsight(version, release, versionMentions))
+	}
+
+	sort.Slice(insights, func(a, b int) bool {
+		if insights[a].MentionCount != insights[b].MentionCount {
+			return insights[a].MentionCount > insights[b].MentionCount
+		}
+		return insights[a].Version > insights[b].Version
+	})
+
+	return insights
+}
+
+// releaseFor returns the release announcing version as GA, falling back to the first
+// release whose notes reference it
+func releaseFor(releases []Release, version string) (Release, bool) {
+	for _, release := range releases {
+		if contains(release.GAVersions, version) {
+			return release, true
+		}
+	}
+	for _, release := range releases {
+		if contains(release.KubernetesVersions, version) {
+			return release, true
+		}
+	}
+	return Release{}, false
+}
+
+func buildInsight(version string, release Release, mentions []models.Mention) models.ReleaseInsight {
+	insight := models.ReleaseInsight{
+		Version:      version,
+		ReleaseName:  release.Name,
+		ReleaseURL:   release.URL,
+		ReleasedAt:   release.PublishedAt,
+		MentionCount: len(mentions),
+	}
+
+	releaseDay := release.PublishedAt.UTC().Truncate(24 * time.Hour)
+	perDay := make(map[time.Time]int)
+	for _, mention := range mentions {
+		if mention.Sentiment == "negative" {
+			insight.NegativeCount++
+		}
+
+		day := mention.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if !day.Before(releaseDay) {
+			perDay[day]++
+		}
+	}
+
+	for day, count := range perDay {
+		if count > insight.PeakCount || (count == insight.PeakCount && day.Before(insight.PeakDay)) {
+			insight.PeakDay = day
+			insight.PeakCount = count
+		}
+	}
+
+	lag := int(insight.PeakDay.Sub(releaseDay).Hours() / 24)
+	switch {
+	case insight.PeakCount >= minCorrelatedMentions && lag == 0:
+		insight.DaysAfterRelease = 0
+		insight.Summary = fmt.Sprintf("Mentions referencing %s spiked on release day (%d mentions on %s)",
+			version, insight.PeakCount, insight.PeakDay.Format("Jan 2"))
+	case insight.PeakCount >= minCorrelatedMentions && lag <= maxSpikeLag:
+		insight.DaysAfterRelease = lag
+		insight.Summary = fmt.Sprintf("Mentions referencing %s spiked %d %s after release (%d mentions on %s)",
+			version, lag, pluralize(lag, "day", "days"), insight.PeakCount, insight.PeakDay.Format("Jan 2"))
+	default:
+		insight.Summary = fmt.Sprintf("%d mentions referenced %s (released %s)",
+			insight.MentionCount, version, release.PublishedAt.Format("Jan 2, 2006"))
+	}
+
+	if insight.NegativeCount > 0 {
+		insight.Summary += fmt.Sprintf(", %d negative", insight.NegativeCount)
+	}
+
+	return insight
+}
+
+func extractVersions(text string) []string {
+	var versions []string
+	for _, match := range versionPattern.FindAllStringSubmatch(text, -1) {
+		versions = appendUnique(versions, match[1])
+	}
+	return versions
+}
+
+func appendUnique(values []string, additions ...string) []string {
+	for _, addition := range additions {
+		if !contains(values, addition) {
+			values = append(values, addition)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
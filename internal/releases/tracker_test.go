@@ -0,0 +1,72 @@
+package releases
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Releases(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"name": "Release 2024-07-01", "html_url": "https://github.com/Azure/AKS/releases/2", "published_at": "2024-07-01T00:00:00Z",
+			 "body": "* Kubernetes 1.30 is now GA.\n* Kubernetes 1.27 is deprecated."},
+			{"name": "Release 2024-06-01", "html_url": "https://github.com/Azure/AKS/releases/1", "published_at": "2024-06-01T00:00:00Z",
+			 "body": "* Kubernetes v1.30.0 is available in preview."},
+			{"name": "Draft", "draft": true, "published_at": "2024-07-05T00:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	tracker := NewTracker(server.URL)
+	releases, err := tracker.Releases()
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+
+	assert.Equal(t, "Release 2024-06-01", releases[0].Name, "releases are sorted oldest first")
+	assert.Equal(t, []string{"1.30"}, releases[0].KubernetesVersions)
+	assert.Empty(t, releases[0].GAVersions)
+	assert.Equal(t, []string{"1.30", "1.27"}, releases[1].KubernetesVersions)
+	assert.Equal(t, []string{"1.30"}, releases[1].GAVersions)
+
+	_, err = tracker.Releases()
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests, "releases are cached")
+}
+
+func TestCorrelate(t *testing.T) {
+	released := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	releases := []Release{
+		{Name: "Preview", PublishedAt: released.AddDate(0, -1, 0), KubernetesVersions: []string{"1.30"}},
+		{Name: "GA", URL: "https://example.com/ga", PublishedAt: released, KubernetesVersions: []string{"1.30"}, GAVersions: []string{"1.30"}},
+	}
+
+	spike := released.AddDate(0, 0, 2)
+	mentions := []models.Mention{
+		{ID: "1", Title: "Upgrading to 1.30 broke ingress", CreatedAt: spike, Sentiment: "negative"},
+		{ID: "2", Content: "AKS v1.30.1 upgrade notes", CreatedAt: spike.Add(time.Hour)},
+		{ID: "3", Content: "Still on 1.30 here", CreatedAt: released.AddDate(0, 0, 5)},
+		{ID: "4", Content: "Kubernetes 1.29 question", CreatedAt: spike},
+		{ID: "5", Content: "Kubernetes 1.28 and 1.28.3", CreatedAt: spike},
+	}
+
+	insights := Correlate(releases, mentions)
+	require.Len(t, insights, 1)
+
+	insight := insights[0]
+	assert.Equal(t, "1.30", insight.Version)
+	assert.Equal(t, "GA", insight.ReleaseName, "GA announcement takes precedence over earlier preview")
+	assert.Equal(t, 3, insight.MentionCount)
+	assert.Equal(t, 1, insight.NegativeCount)
+	assert.Equal(t, 2, insight.PeakCount)
+	assert.Equal(t, 2, insight.DaysAfterRelease)
+	assert.Equal(t, "Mentions referencing 1.30 spiked 2 days after release (2 mentions on Jul 3), 1 negative", insight.Summary)
+}
@@ -0,0 +1,280 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// Report colors, matching the Azure palette used by the email template
+var (
+	colorAccent   = drawing.ColorFromHex("0078d4")
+	colorPositive = drawing.ColorFromHex("107c10")
+	colorNegative = drawing.ColorFromHex("d13438")
+	colorNeutral  = drawing.ColorFromHex("605e5c")
+)
+
+// sentimentOrder fixes the donut slice order so charts are comparable between reports
+var sentimentOrder = []string{"positive", "neutral", "negative"}
+
+func sentimentColor(sentiment string) drawing.Color {
+	switch sentiment {
+	case "positive":
+		return colorPositive
+	case "negative":
+		return colorNegative
+	default:
+		return colorNeutral
+	}
+}
+
+// sentimentDonut renders the sentiment split as a PNG donut chart; it returns nil when
+// there is nothing to chart
+func sentimentDonut(counts map[string]int) ([]byte, error) {
+	var values []chart.Value
+	add := func(sentiment string, count int) {
+		if count > 0 {
+			values = append(values, chart.Value{
+				Label: fmt.Sprintf("%s (%d)", sentiment, count),
+				Value: float64(count),
+				Style: chart.Style{FillColor: sentimentColor(sentiment), StrokeColor: drawing.ColorWhite},
+			})
+		}
+	}
+
+	for _, sentiment := range sentimentOrder {
+		add(sentiment, counts[sentiment])
+	}
+	var other []string
+	for sentiment := range counts {
+		if sentiment != "positive" && sentiment != "neutral" && sentiment != "negative" {
+			other = append(other, sentiment)
+		}
+	}
+	sort.Strings(other)
+	for _, sentiment := range other {
+		add(sentiment, counts[sentiment])
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	donut := chart.DonutChart{
+		Width:  360,
+		Height: 360,
+		Values: values,
+	}
+	return renderPNG(donut)
+}
+
+// sourceBars renders mentions per source as a PNG bar chart, busiest source first
+func sourceBars(counts map[string]int) ([]byte, error) {
+	sources := make([]string, 0, len(counts))
+	max := 0
+	for source, count := range counts {
+		if count > 0 {
+			sources = append(sources, source)
+		}
+		if count > max {
+			max = count
+		}
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	sort.Slice(sources, func(a, b int) bool {
+		if counts[sources[a]] != counts[sources[b]] {
+			return counts[sources[a]] > counts[sources[b]]
+		}
+		return sources[a] < sources[b]
+	})
+
+	bars := make([]chart.Value, 0, len(sources))
+	for _, source := range sources {
+		bars = append(bars, chart.Value{
+			Label: source,
+			Value: float64(counts[source]),
+			Style: chart.Style{FillColor: colorAccent, StrokeColor: colorAccent},
+		})
+	}
+
+	barChart := chart.BarChart{
+		Width:    640,
+		Height:   320,
+		BarWidth: 40,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 20, Left: 10, Right: 10, Bottom: 30},
+		},
+		XAxis: chart.Shown(),
+		YAxis: countAxis(max),
+		Bars:  bars,
+	}
+	return renderPNG(barChart)
+}
+
+// timelineSparkline renders mentions per day as a PNG sparkline without axes; it needs at
+// least two days of data to draw a line
+func timelineSparkline(mentions []models.Mention) ([]byte, error) {
+	perDay := make(map[time.Time]int)
+	for _, mention := range mentions {
+		if mention.CreatedAt.IsZero() {
+			continue
+		}
+		created := mention.CreatedAt.UTC()
+		perDay[time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)]++
+	}
+	if len(perDay) == 0 {
+		return nil, nil
+	}
+
+	var first, last time.Time
+	for day := range perDay {
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+	if !last.After(first) {
+		return nil, nil
+	}
+
+	// Fill in quiet days so gaps show as zero rather than being interpolated
+	var xs []time.Time
+	var ys []float64
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		xs = append(xs, day)
+		ys = append(ys, float64(perDay[day]))
+	}
+
+	sparkline := chart.Chart{
+		Width:  640,
+		Height: 120,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 10, Left: 10, Right: 10, Bottom: 10},
+		},
+		XAxis: chart.XAxis{Style: chart.Hidden()},
+		YAxis: chart.YAxis{
+			Style: chart.Hidden(),
+			Range: &chart.ContinuousRange{Min: 0, Max: maxValue(ys)},
+		},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				XValues: xs,
+				YValues: ys,
+				Style: chart.Style{
+					StrokeColor: colorAccent,
+					StrokeWidth: 2,
+					FillColor:   colorAccent.WithAlpha(48),
+				},
+			},
+		},
+	}
+	return renderPNG(sparkline)
+}
+
+// sentimentScoreLine renders daily community sentiment scores as a PNG line chart on a fixed
+// -100 to 100 scale with a zero line; it needs at least two days of scores to draw a line
+func sentimentScoreLine(history []models.SentimentScore) ([]byte, error) {
+	var xs []time.Time
+	var ys []float64
+	for _, score := range history {
+		day, err := time.Parse("2006-01-02", score.Date)
+		if err != nil {
+			continue
+		}
+		xs = append(xs, day)
+		ys = append(ys, score.Score)
+	}
+	if len(xs) < 2 {
+		return nil, nil
+	}
+
+	var ticks []chart.Tick
+	for value := -100; value <= 100; value += 50 {
+		ticks = append(ticks, chart.Tick{Value: float64(value), Label: fmt.Sprintf("%+d", value)})
+	}
+	ticks[2].Label = "0"
+
+	line := chart.Chart{
+		Width:  640,
+		Height: 200,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 10, Left: 10, Right: 10, Bottom: 10},
+		},
+		XAxis: chart.XAxis{Style: chart.Shown(), ValueFormatter: chart.TimeDateValueFormatter},
+		YAxis: chart.YAxis{
+			Style: chart.Shown(),
+			Range: &chart.ContinuousRange{Min: -100, Max: 100},
+			Ticks: ticks,
+		},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				XValues: []time.Time{xs[0], xs[len(xs)-1]},
+				YValues: []float64{0, 0},
+				Style:   chart.Style{StrokeColor: colorNeutral, StrokeWidth: 1},
+			},
+			chart.TimeSeries{
+				XValues: xs,
+				YValues: ys,
+				Style:   chart.Style{StrokeColor: colorAccent, StrokeWidth: 2},
+			},
+		},
+	}
+	return renderPNG(line)
+}
+
+// countAxis is a y axis starting at zero with whole-number ticks. Pinning the range also
+// avoids go-chart rejecting a range with no spread, e.g. a single bar.
+func countAxis(max int) chart.YAxis {
+	step := (max + 4) / 5
+	if step < 1 {
+		step = 1
+	}
+
+	var ticks []chart.Tick
+	top := 0
+	for ; ; top += step {
+		ticks = append(ticks, chart.Tick{Value: float64(top), Label: strconv.Itoa(top)})
+		if top >= max {
+			break
+		}
+	}
+
+	return chart.YAxis{
+		Style: chart.Shown(),
+		Range: &chart.ContinuousRange{Min: 0, Max: float64(top)},
+		Ticks: ticks,
+	}
+}
+
+func maxValue(values []float64) float64 {
+	max := 1.0
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+	return max
+}
+
+// renderable is implemented by every go-chart chart type
+type renderable interface {
+	Render(rp chart.RendererProvider, w io.Writer) error
+}
+
+func renderPNG(c renderable) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Render(chart.PNG, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,198 @@
+package reports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// Feed is a list of mentions published as an Atom or RSS feed
+type Feed struct {
+	ID       string // Stable identifier of the feed, e.g. its URL
+	Title    string
+	Link     string // Page the feed belongs to; may be empty
+	SelfURL  string // URL the feed is served at; may be empty
+	Mentions []models.Mention
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  *atomPerson `xml:"author,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Link       atomLink       `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+// RenderAtom renders the feed as an Atom 1.0 document
+func RenderAtom(feed *Feed) ([]byte, error) {
+	doc := atomFeed{
+		ID:      feed.ID,
+		Title:   feed.Title,
+		Updated: feedUpdated(feed).Format(time.RFC3339),
+		Author:  &atomPerson{Name: "AKS Mentions Bot"},
+	}
+	if feed.SelfURL != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.SelfURL, Rel: "self"})
+	}
+	if feed.Link != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.Link, Rel: "alternate"})
+	}
+
+	for _, mention := range feed.Mentions {
+		entry := atomEntry{
+			ID:        mentionEntryID(mention),
+			Title:     entryTitle(mention),
+			Updated:   mention.CreatedAt.UTC().Format(time.RFC3339),
+			Published: mention.CreatedAt.UTC().Format(time.RFC3339),
+			Link:      atomLink{Href: mention.URL, Rel: "alternate"},
+			Summary:   entrySummary(mention),
+		}
+		if mention.Author != "" {
+			entry.Author = &atomPerson{Name: mention.Author}
+		}
+		for _, term := range entryCategories(mention) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: term})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalFeed(doc)
+}
+
+// RenderRSS renders the feed as an RSS 2.0 document
+func RenderRSS(feed *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:         feed.Title,
+		Link:          feed.Link,
+		Description:   "Mentions of the monitored keywords found by the AKS Mentions Bot",
+		LastBuildDate: feedUpdated(feed).Format(time.RFC1123Z),
+	}
+	if channel.Link == "" {
+		channel.Link = feed.SelfURL
+	}
+
+	for _, mention := range feed.Mentions {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       entryTitle(mention),
+			Link:        mention.URL,
+			GUID:        rssGUID{Value: mentionEntryID(mention)},
+			PubDate:     mention.CreatedAt.UTC().Format(time.RFC1123Z),
+			Description: entrySummary(mention),
+			Categories:  entryCategories(mention),
+		})
+	}
+
+	return marshalFeed(rssFeed{Version: "2.0", Channel: channel})
+}
+
+func marshalFeed(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render feed: %w", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// feedUpdated is the time of the newest mention, or now for an empty feed
+func feedUpdated(feed *Feed) time.Time {
+	var updated time.Time
+	for _, mention := range feed.Mentions {
+		if mention.CreatedAt.After(updated) {
+			updated = mention.CreatedAt
+		}
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	return updated.UTC()
+}
+
+// mentionEntryID identifies a mention's entry across feed refreshes
+func mentionEntryID(mention models.Mention) string {
+	return "urn:aks-mentions-bot:mention:" + mention.ID
+}
+
+func entryTitle(mention models.Mention) string {
+	title := mention.Title
+	if title == "" {
+		title = truncate(strings.Join(strings.Fields(mention.Content), " "), 100)
+	}
+	if title == "" {
+		title = mention.URL
+	}
+	return fmt.Sprintf("[%s] %s", mention.Source, title)
+}
+
+func entrySummary(mention models.Mention) string {
+	if mention.Excerpt != "" {
+		return mention.Excerpt
+	}
+	return truncate(strings.Join(strings.Fields(mention.Content), " "), 500)
+}
+
+// entryCategories tags an entry with the mention's source, sentiment, keywords and curated tags
+func entryCategories(mention models.Mention) []string {
+	categories := []string{mention.Source}
+	if mention.Sentiment != "" {
+		categories = append(categories, mention.Sentiment)
+	}
+	categories = append(categories, mention.Keywords...)
+	return append(categories, mention.Tags...)
+}
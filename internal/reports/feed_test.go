@@ -0,0 +1,78 @@
+package reports
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func feedFixture() *Feed {
+	created := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	return &Feed{
+		ID:      "urn:aks-mentions-bot:feed:fleet",
+		Title:   "AKS Mentions - fleet",
+		Link:    "https://bot.contoso.com",
+		SelfURL: "https://bot.contoso.com/feed.xml?group=fleet",
+		Mentions: []models.Mention{
+			{
+				ID: "reddit_1", Source: "reddit", Title: "KubeFleet <rollout> & AKS", URL: "https://reddit.com/r/AZURE/1",
+				Author: "alice", Content: "Trying KubeFleet", Sentiment: "positive", Keywords: []string{"KubeFleet"},
+				Tags: []string{"rollout"}, CreatedAt: created,
+			},
+			{ID: "twitter_2", Source: "twitter", Content: "  AKS   is great  ", URL: "https://twitter.com/i/status/2", CreatedAt: created.Add(-time.Hour)},
+		},
+	}
+}
+
+func TestRenderAtom(t *testing.T) {
+	body, err := RenderAtom(feedFixture())
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, string(body), "KubeFleet &lt;rollout&gt; &amp; AKS")
+
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(body, &feed))
+	assert.Equal(t, "urn:aks-mentions-bot:feed:fleet", feed.ID)
+	assert.Equal(t, "2024-06-03T09:00:00Z", feed.Updated)
+	require.Len(t, feed.Links, 2)
+	assert.Equal(t, "self", feed.Links[0].Rel)
+
+	require.Len(t, feed.Entries, 2)
+	entry := feed.Entries[0]
+	assert.Equal(t, "urn:aks-mentions-bot:mention:reddit_1", entry.ID)
+	assert.Equal(t, "[reddit] KubeFleet <rollout> & AKS", entry.Title)
+	assert.Equal(t, "https://reddit.com/r/AZURE/1", entry.Link.Href)
+	assert.Equal(t, "alice", entry.Author.Name)
+	assert.Equal(t, []atomCategory{{"reddit"}, {"positive"}, {"KubeFleet"}, {"rollout"}}, entry.Categories)
+
+	// Mentions without a title are titled by their content
+	assert.Equal(t, "[twitter] AKS is great", feed.Entries[1].Title)
+	assert.Nil(t, feed.Entries[1].Author)
+}
+
+func TestRenderRSS(t *testing.T) {
+	body, err := RenderRSS(feedFixture())
+	require.NoError(t, err)
+
+	var feed rssFeed
+	require.NoError(t, xml.Unmarshal(body, &feed))
+	assert.Equal(t, "2.0", feed.Version)
+	assert.Equal(t, "https://bot.contoso.com", feed.Channel.Link)
+	require.Len(t, feed.Channel.Items, 2)
+	item := feed.Channel.Items[0]
+	assert.Equal(t, "urn:aks-mentions-bot:mention:reddit_1", item.GUID.Value)
+	assert.False(t, item.GUID.IsPermaLink)
+	assert.Equal(t, "Mon, 03 Jun 2024 09:00:00 +0000", item.PubDate)
+	assert.Equal(t, "Trying KubeFleet", item.Description)
+
+	// An empty feed is still a valid document
+	body, err = RenderRSS(&Feed{ID: "urn:aks-mentions-bot:feed", Title: "AKS Mentions"})
+	require.NoError(t, err)
+	var empty rssFeed
+	require.NoError(t, xml.Unmarshal(body, &empty))
+	assert.Empty(t, empty.Channel.Items)
+}
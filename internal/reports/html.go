@@ -0,0 +1,291 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// maxReportMentions bounds how many mentions are listed in the HTML report
+const maxReportMentions = 50
+
+// htmlData is the template data for the standalone HTML report
+type htmlData struct {
+	*models.Report
+	Sentiment    map[string]int
+	Sources      map[string]int
+	SentimentImg template.URL
+	SourcesImg   template.URL
+	TimelineImg  template.URL
+	ScoreImg     template.URL
+	TopMentions  []models.Mention
+}
+
+// RenderHTML renders a report as a standalone HTML page. Charts are embedded as PNG data
+// URIs so the page needs no other resources and can be stored, emailed or framed as is.
+func RenderHTML(report *models.Report) ([]byte, error) {
+	data := &htmlData{
+		Report:    report,
+		Sentiment: countMap(report.Summary["sentiment"]),
+		Sources:   countMap(report.Summary["sources"]),
+	}
+	// Mentions already sent as urgent alerts are listed apart but still count
+	all := append(append([]models.Mention{}, report.Mentions...), report.PreviouslyAlerted...)
+	if data.Sentiment == nil || data.Sources == nil {
+		// Reports loaded from JSON lose the summary's map types, so count from the mentions
+		data.Sentiment, data.Sources = countMentions(all)
+	}
+
+	data.TopMentions = report.Mentions
+	if len(data.TopMentions) > maxReportMentions {
+		data.TopMentions = data.TopMentions[:maxReportMentions]
+	}
+
+	var err error
+	if data.SentimentImg, err = dataURI(sentimentDonut(data.Sentiment)); err != nil {
+		return nil, fmt.Errorf("failed to render sentiment chart: %w", err)
+	}
+	if data.SourcesImg, err = dataURI(sourceBars(data.Sources)); err != nil {
+		return nil, fmt.Errorf("failed to render source chart: %w", err)
+	}
+	if data.TimelineImg, err = dataURI(timelineSparkline(all)); err != nil {
+		return nil, fmt.Errorf("failed to render timeline chart: %w", err)
+	}
+	if report.SentimentTrend != nil {
+		if data.ScoreImg, err = dataURI(sentimentScoreLine(report.SentimentTrend.History)); err != nil {
+			return nil, fmt.Errorf("failed to render sentiment score chart: %w", err)
+		}
+	}
+
+	t, err := template.New("report").Funcs(template.FuncMap{
+		"title": strings.Title,
+		"join":  strings.Join,
+		"snippet": func(content string) string {
+			return truncate(strings.Join(strings.Fields(content), " "), 280)
+		},
+	}).Parse(reportTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func countMap(value interface{}) map[string]int {
+	counts, _ := value.(map[string]int)
+	return counts
+}
+
+func countMentions(mentions []models.Mention) (sentiment, sources map[string]int) {
+	sentiment = make(map[string]int)
+	sources = make(map[string]int)
+	for _, mention := range mentions {
+		sentiment[mention.Sentiment]++
+		sources[mention.Source]++
+	}
+	return sentiment, sources
+}
+
+// dataURI encodes a rendered PNG chart; charts with nothing to draw become an empty URI
+func dataURI(png []byte, err error) (template.URL, error) {
+	if err != nil || len(png) == 0 {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil
+}
+
+func truncate(str string, maxLength int) string {
+	runes := []rune(str)
+	if len(runes) <= maxLength {
+		return str
+	}
+	return strings.TrimSpace(string(runes[:maxLength-3])) + "..."
+}
+
+const reportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>AKS Mentions Report - {{.GeneratedAt.Format "Jan 2, 2006"}}</title>
+    <style>
+        body { font-family: "Segoe UI", Arial, sans-serif; margin: 0; background: #f3f2f1; color: #323130; }
+        main { max-width: 960px; margin: 0 auto; padding: 24px; }
+        header { background: #0078d4; color: white; padding: 24px; border-radius: 6px; }
+        header h1 { margin: 0 0 4px; font-size: 1.6em; }
+        header p { margin: 0; opacity: 0.9; }
+        header p.score { margin-top: 8px; font-size: 1.2em; font-weight: 600; opacity: 1; }
+        .cards { display: flex; flex-wrap: wrap; gap: 12px; margin: 20px 0; }
+        .card { flex: 1 1 140px; background: white; border-radius: 6px; padding: 16px; }
+        .card .value { font-size: 2em; font-weight: 600; }
+        .card .label { color: #605e5c; text-transform: capitalize; }
+        .card.positive .value { color: #107c10; }
+        .card.negative .value { color: #d13438; }
+        .panel { background: white; border-radius: 6px; padding: 16px; margin: 20px 0; }
+        .panel h2 { margin-top: 0; font-size: 1.2em; }
+        .charts { display: flex; flex-wrap: wrap; gap: 20px; align-items: flex-start; }
+        .charts img { max-width: 100%; height: auto; }
+        .mention { border-left: 4px solid #605e5c; padding: 8px 12px; margin: 12px 0; }
+        .mention.positive { border-left-color: #107c10; }
+        .mention.negative { border-left-color: #d13438; }
+        .mention-title { font-weight: 600; }
+        .meta { color: #605e5c; font-size: 0.9em; }
+        a { color: #0078d4; }
+        footer { color: #605e5c; font-size: 0.85em; text-align: center; margin: 24px 0; }
+        @media print {
+            body { background: white; }
+            main { padding: 0; }
+            .panel, .card { border: 1px solid #edebe9; }
+            .panel, .mention, .cards { break-inside: avoid; }
+        }
+    </style>
+</head>
+<body>
+<main>
+    <header>
+        <h1>AKS Mentions Report</h1>
+        <p>{{.Period | title}} report generated on {{.GeneratedAt.Format "January 2, 2006 at 3:04 PM MST"}}</p>
+        {{if .SentimentTrend}}<p class="score">Community sentiment score: {{.SentimentTrend.Summary}}</p>{{end}}
+    </header>
+
+    <section class="cards">
+        <div class="card"><div class="value">{{.TotalMentions}}</div><div class="label">Total mentions</div></div>
+        {{range $sentiment, $count := .Sentiment}}
+        <div class="card {{$sentiment}}"><div class="value">{{$count}}</div><div class="label">{{$sentiment}}</div></div>
+        {{end}}
+    </section>
+
+    {{if or .SentimentImg .SourcesImg}}
+    <section class="panel">
+        <h2>Sentiment and Sources</h2>
+        <div class="charts">
+            {{if .SentimentImg}}<img src="{{.SentimentImg}}" width="360" height="360" alt="Sentiment breakdown">{{end}}
+            {{if .SourcesImg}}<img src="{{.SourcesImg}}" width="560" height="280" alt="Mentions by source">{{end}}
+        </div>
+    </section>
+    {{end}}
+
+    {{if .ScoreImg}}
+    <section class="panel">
+        <h2>Community Sentiment Score</h2>
+        <img src="{{.ScoreImg}}" width="640" height="200" alt="Community sentiment score per day">
+        <p class="meta">Percentage of positive minus percentage of negative mentions over the trailing {{.SentimentTrend.WindowDays}} days.</p>
+    </section>
+    {{end}}
+
+    {{if .TimelineImg}}
+    <section class="panel">
+        <h2>Mentions per Day</h2>
+        <img src="{{.TimelineImg}}" width="640" height="120" alt="Mentions per day">
+    </section>
+    {{end}}
+
+    {{if .TopMentions}}
+    <section class="panel">
+        <h2>Mentions</h2>
+        {{range .TopMentions}}
+        <div class="mention {{.Sentiment}}">
+            <div class="mention-title"><a href="{{.URL}}" target="_blank" rel="noopener">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</a></div>
+            <div class="meta">
+                {{.Source}}{{if .Author}} | {{.Author}}{{end}} | {{.CreatedAt.Format "Jan 2, 2006"}}
+                {{if gt .DuplicateCount 1}} | Posted {{.DuplicateCount}} times{{end}}
+                {{if .Score}} | Score: {{.Score}}{{end}}
+                {{if .Relevance}} | Relevance: {{printf "%.2f" .Relevance}}{{end}}
+                {{if .Tags}} | Tags: {{join .Tags ", "}}{{end}}
+                {{if .SentimentPhrases}} | Negative: {{join .SentimentPhrases ", "}}{{end}}
+            </div>
+            {{if .Excerpt}}<p>{{.Excerpt}}</p>{{else if .Content}}<p>{{snippet .Content}}</p>{{end}}
+        </div>
+        {{end}}
+        {{if gt (len .Mentions) (len .TopMentions)}}
+        <p class="meta">Showing {{len .TopMentions}} of {{len .Mentions}} mentions.</p>
+        {{end}}
+    </section>
+    {{end}}
+
+    {{if .Unanswered}}
+    <section class="panel">
+        <h2>Needs an Answer</h2>
+        <ul>
+        {{range .Unanswered}}
+            <li><a href="{{.URL}}" target="_blank" rel="noopener">{{.Title}}</a> <span class="meta">{{.Source}}, {{.CreatedAt.Format "Jan 2"}}</span></li>
+        {{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .Resolved}}
+    <section class="panel">
+        <h2>Resolved Since Last Report</h2>
+        <ul>
+        {{range .Resolved}}
+            <li><a href="{{.Mention.URL}}" target="_blank" rel="noopener">{{.Mention.Title}}</a> <span class="meta">{{.Mention.Source}}, {{.Resolution}}</span></li>
+        {{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .DocsGaps}}
+    <section class="panel">
+        <h2>Documentation Gaps in {{.DocsGaps.Month}}</h2>
+        <ul>
+        {{range .DocsGaps.Topics}}
+            <li><strong>{{.Topic}}</strong> <span class="meta">{{.Count}} questions</span>
+                <ul>
+                {{range .Questions}}
+                    <li><a href="{{.URL}}" target="_blank" rel="noopener">{{.Title}}</a> <span class="meta">{{.Source}}, {{.CreatedAt.Format "Jan 2"}}</span></li>
+                {{end}}
+                </ul>
+            </li>
+        {{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .PreviouslyAlerted}}
+    <section class="panel">
+        <h2>Previously Alerted</h2>
+        <ul>
+        {{range .PreviouslyAlerted}}
+            <li><a href="{{.URL}}" target="_blank" rel="noopener">{{.Title}}</a> <span class="meta">{{.Source}}{{if .AlertedAt}}, alerted {{.AlertedAt.Format "Jan 2 15:04"}}{{end}}</span></li>
+        {{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .ReleaseInsights}}
+    <section class="panel">
+        <h2>AKS Release Correlation</h2>
+        <ul>
+        {{range .ReleaseInsights}}
+            <li>{{.Summary}} (<a href="{{.ReleaseURL}}" target="_blank" rel="noopener">{{.ReleaseName}}</a>)</li>
+        {{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .NegativeComments}}
+    <section class="panel">
+        <h2>Notable Negative Comments</h2>
+        {{range .NegativeComments}}
+        <div class="mention negative">
+            <div class="meta"><a href="{{.URL}}" target="_blank" rel="noopener">{{.Author}}</a> | {{.CreatedAt.Format "Jan 2, 2006"}}</div>
+            <p>{{snippet .Content}}</p>
+        </div>
+        {{end}}
+    </section>
+    {{end}}
+
+    <footer>Generated automatically by the AKS Mentions Bot.</footer>
+</main>
+</body>
+</html>
+`
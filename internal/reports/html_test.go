@@ -0,0 +1,88 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTML(t *testing.T) {
+	now := time.Now()
+	report := &models.Report{
+		GeneratedAt:   now,
+		Period:        "weekly",
+		TotalMentions: 3,
+		Mentions: []models.Mention{
+			{Source: "reddit", Sentiment: "positive", Title: "AKS <upgrade> tips", URL: "https://reddit.com/r/kubernetes/1", CreatedAt: now},
+			{Source: "reddit", Sentiment: "negative", Title: "AKS outage", URL: "https://reddit.com/r/azure/2", CreatedAt: now.AddDate(0, 0, -2)},
+			{Source: "hackernews", Sentiment: "neutral", Title: "Show HN", URL: "https://news.ycombinator.com/item?id=3", CreatedAt: now.AddDate(0, 0, -3)},
+		},
+		Summary: map[string]interface{}{
+			"sentiment": map[string]int{"positive": 1, "negative": 1, "neutral": 1},
+			"sources":   map[string]int{"reddit": 2, "hackernews": 1},
+		},
+	}
+
+	page, err := RenderHTML(report)
+	require.NoError(t, err)
+
+	html := string(page)
+	assert.Equal(t, 3, strings.Count(html, `src="data:image/png;base64,`), "donut, bar chart and sparkline are embedded")
+	assert.Contains(t, html, "AKS &lt;upgrade&gt; tips", "mention content is escaped")
+	assert.NotContains(t, html, `src="http`, "the page loads no external resources")
+}
+
+func TestRenderHTML_SparseData(t *testing.T) {
+	// No mentions at all: nothing to chart, but the page still renders
+	page, err := RenderHTML(&models.Report{GeneratedAt: time.Now(), Period: "daily"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(page), "data:image/png")
+
+	// One source on one day: the donut and bar chart render, a single day has no timeline
+	report := &models.Report{
+		GeneratedAt: time.Now(),
+		Period:      "daily",
+		Mentions:    []models.Mention{{Source: "reddit", Sentiment: "neutral", CreatedAt: time.Now()}},
+	}
+	page, err = RenderHTML(report)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(page), "data:image/png"))
+}
+
+func TestRenderHTML_SentimentTrend(t *testing.T) {
+	change := -4.5
+	report := &models.Report{
+		GeneratedAt: time.Now(),
+		Period:      "daily",
+		SentimentTrend: &models.SentimentTrend{
+			Current:    models.SentimentScore{Date: "2024-06-08", Score: 12.5},
+			WindowDays: 30,
+			Change:     &change,
+			History: []models.SentimentScore{
+				{Date: "2024-06-01", Score: 17},
+				{Date: "2024-06-08", Score: 12.5},
+			},
+		},
+	}
+
+	page, err := RenderHTML(report)
+	require.NoError(t, err)
+
+	html := string(page)
+	assert.Contains(t, html, "Community sentiment score: &#43;12.5 over 30 days, down 4.5 from a week ago")
+	assert.Equal(t, 1, strings.Count(html, `src="data:image/png;base64,`), "the score history is charted")
+}
+
+func TestCountAxis(t *testing.T) {
+	axis := countAxis(1)
+	assert.Equal(t, 1.0, axis.Range.GetMax())
+	assert.Len(t, axis.Ticks, 2)
+
+	axis = countAxis(12)
+	assert.Equal(t, 12.0, axis.Range.GetMax())
+	assert.Equal(t, "3", axis.Ticks[1].Label)
+}
@@ -0,0 +1,140 @@
+package reports
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// languageNames names the languages mentions are translated from
+var languageNames = map[string]string{
+	"ja": "Japanese",
+	"zh": "Chinese",
+	"ko": "Korean",
+}
+
+// MentionPage is the data of a mention's permalink page. The page is public, so it shows the
+// lifecycle status but not who changed it or their triage notes.
+type MentionPage struct {
+	Mention models.Mention
+	Topics  []string // Notable title terms, as grouped in analytics
+
+	// Lifecycle state from notification actions
+	Status    string
+	UpdatedAt *time.Time
+}
+
+// RenderMention renders a stored mention as a standalone HTML page with its metadata and a
+// link to the original post, for notifications to link to
+func RenderMention(page *MentionPage) ([]byte, error) {
+	t, err := template.New("mention").Funcs(template.FuncMap{
+		"title": strings.Title,
+		"join":  strings.Join,
+		"language": func(code string) string {
+			if name, ok := languageNames[code]; ok {
+				return name
+			}
+			return code
+		},
+	}).Parse(mentionTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const mentionTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{with .Mention}}{{if .Title}}{{.Title}}{{else}}{{.Source | title}} mention{{end}}{{end}} - AKS Mentions</title>
+    <style>
+        body { font-family: "Segoe UI", Arial, sans-serif; margin: 0; background: #f3f2f1; color: #323130; }
+        main { max-width: 760px; margin: 0 auto; padding: 24px; }
+        header { background: #0078d4; color: white; padding: 24px; border-radius: 6px; }
+        header h1 { margin: 0 0 4px; font-size: 1.4em; }
+        header p { margin: 0; opacity: 0.9; }
+        .panel { background: white; border-radius: 6px; padding: 16px; margin: 20px 0; }
+        .panel h2 { margin-top: 0; font-size: 1.1em; }
+        .panel.positive { border-left: 4px solid #107c10; }
+        .panel.negative { border-left: 4px solid #d13438; }
+        .content { white-space: pre-wrap; }
+        .open { display: inline-block; background: #0078d4; color: white; padding: 8px 16px; border-radius: 4px; text-decoration: none; font-weight: 600; }
+        dl { display: grid; grid-template-columns: max-content 1fr; gap: 6px 16px; margin: 0; }
+        dt { color: #605e5c; }
+        dd { margin: 0; }
+        .meta { color: #605e5c; font-size: 0.9em; }
+        a { color: #0078d4; }
+        footer { color: #605e5c; font-size: 0.85em; text-align: center; margin: 24px 0; }
+    </style>
+</head>
+<body>
+<main>
+    {{with .Mention}}
+    <header>
+        <h1>{{if .Title}}{{.Title}}{{else}}{{.Source | title}} mention{{end}}</h1>
+        <p>{{if .Platform}}{{.Platform}}{{else}}{{.Source}}{{end}}{{if .Author}} | {{.Author}}{{end}} | {{.CreatedAt.Format "January 2, 2006 at 3:04 PM MST"}}</p>
+    </header>
+
+    <section class="panel {{.Sentiment}}">
+        {{if .Content}}<p class="content">{{.Content}}</p>{{else if .Excerpt}}<p class="content">{{.Excerpt}}</p>{{end}}
+        <a class="open" href="{{.URL}}" target="_blank" rel="noopener">Open the original post</a>
+    </section>
+
+    {{if .Language}}
+    <section class="panel">
+        <h2>Original ({{language .Language}})</h2>
+        <p class="meta">The title and content above were machine-translated into English.</p>
+        {{if .OriginalTitle}}<p><strong>{{.OriginalTitle}}</strong></p>{{end}}
+        {{if .OriginalContent}}<p class="content">{{.OriginalContent}}</p>{{end}}
+    </section>
+    {{end}}
+    {{end}}
+
+    <section class="panel">
+        <h2>Details</h2>
+        <dl>
+            {{with .Mention}}
+            <dt>Source</dt><dd>{{.Source}}{{if .PostType}} ({{.PostType}}){{end}}</dd>
+            {{if .Language}}<dt>Language</dt><dd>{{language .Language}} <span class="meta">(translated)</span></dd>{{end}}
+            {{if .Sentiment}}<dt>Sentiment</dt><dd>{{.Sentiment | title}}{{if .SentimentPhrases}} <span class="meta">({{join .SentimentPhrases ", "}})</span>{{end}}</dd>{{end}}
+            {{if .Keywords}}<dt>Keywords</dt><dd>{{join .Keywords ", "}}</dd>{{end}}
+            {{end}}
+            {{if .Topics}}<dt>Topics</dt><dd>{{join .Topics ", "}}</dd>{{end}}
+            {{with .Mention}}
+            {{if .Tags}}<dt>Tags</dt><dd>{{join .Tags ", "}}</dd>{{end}}
+            {{if .Relevance}}<dt>Relevance</dt><dd>{{printf "%.2f" .Relevance}}{{if .Filter}} <span class="meta">({{.Filter.Confidence}} confidence)</span>{{end}}</dd>{{end}}
+            {{if .Score}}<dt>Score</dt><dd>{{.Score}}</dd>{{end}}
+            {{if .CommentCount}}<dt>Comments</dt><dd>{{.CommentCount}}</dd>{{end}}
+            {{if gt .DuplicateCount 1}}<dt>Posted</dt><dd>{{.DuplicateCount}} times <span class="meta">(near-duplicates collapsed into this mention)</span></dd>{{end}}
+            {{if .IsQuestion}}<dt>Question</dt><dd>Yes</dd>{{end}}
+            {{if .AlertedAt}}<dt>Alerted</dt><dd>{{.AlertedAt.Format "Jan 2, 2006 15:04 MST"}}</dd>{{end}}
+            {{if .Advisories}}<dt>Advisories</dt><dd>{{range $i, $advisory := .Advisories}}{{if $i}}, {{end}}<a href="{{$advisory.URL}}" target="_blank" rel="noopener">{{$advisory.ID}}</a>{{end}}</dd>{{end}}
+            {{end}}
+            <dt>Status</dt><dd>{{.Status | title}}{{if .UpdatedAt}}, {{.UpdatedAt.Format "Jan 2, 2006 15:04 MST"}}{{end}}</dd>
+        </dl>
+    </section>
+
+    {{with .Mention}}{{if .TopComments}}
+    <section class="panel">
+        <h2>Notable Comments</h2>
+        {{range .TopComments}}
+        <p><a href="{{.URL}}" target="_blank" rel="noopener">{{.Author}}</a> <span class="meta">{{.CreatedAt.Format "Jan 2, 2006"}}</span><br>{{.Content}}</p>
+        {{end}}
+    </section>
+    {{end}}{{end}}
+
+    <footer>Found by the AKS Mentions Bot.</footer>
+</main>
+</body>
+</html>
+`
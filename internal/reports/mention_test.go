@@ -0,0 +1,48 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMention(t *testing.T) {
+	at := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	page, err := RenderMention(&MentionPage{
+		Mention: models.Mention{
+			Source: "reddit", Title: "AKS <upgrade> stuck", Content: "Node pool upgrade never finishes",
+			URL: "https://reddit.com/r/AZURE/1", CreatedAt: at, Sentiment: "negative", Keywords: []string{"AKS"},
+		},
+		Topics: []string{"upgrade", "stuck"},
+		Status: "escalated",
+	})
+	require.NoError(t, err)
+
+	html := string(page)
+	assert.Contains(t, html, "AKS &lt;upgrade&gt; stuck", "mention content is escaped")
+	assert.Contains(t, html, `<a class="open" href="https://reddit.com/r/AZURE/1"`)
+	assert.Contains(t, html, "<dd>upgrade, stuck</dd>")
+	assert.Contains(t, html, "<dd>Escalated</dd>")
+	assert.NotContains(t, html, `src="http`, "the page loads no external resources")
+	assert.NotContains(t, html, "Original (")
+}
+
+func TestRenderMention_translated(t *testing.T) {
+	page, err := RenderMention(&MentionPage{
+		Mention: models.Mention{
+			Source: "qiita", Title: "Notes on AKS node auto provisioning", Content: "How NAP picks VM sizes",
+			URL: "https://qiita.com/user/items/1", CreatedAt: time.Now(), Language: "ja",
+			OriginalTitle: "AKS のノード自動プロビジョニングのメモ", OriginalContent: "NAP が VM サイズを選ぶ仕組み",
+		},
+	})
+	require.NoError(t, err)
+
+	html := string(page)
+	assert.Contains(t, html, "<h1>Notes on AKS node auto provisioning</h1>")
+	assert.Contains(t, html, "<h2>Original (Japanese)</h2>")
+	assert.Contains(t, html, "AKS のノード自動プロビジョニングのメモ")
+	assert.Contains(t, html, "NAP が VM サイズを選ぶ仕組み")
+}
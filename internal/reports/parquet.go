@@ -0,0 +1,61 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/parquet"
+)
+
+// mentionColumns is the schema of exported mention files. Columns are only ever added,
+// so queries over older partitions keep working.
+var mentionColumns = []parquet.Column{
+	{Name: "id", Kind: parquet.String},
+	{Name: "source", Kind: parquet.String},
+	{Name: "platform", Kind: parquet.String},
+	{Name: "post_type", Kind: parquet.String},
+	{Name: "title", Kind: parquet.String},
+	{Name: "content", Kind: parquet.String},
+	{Name: "author", Kind: parquet.String},
+	{Name: "channel", Kind: parquet.String},
+	{Name: "url", Kind: parquet.String},
+	{Name: "created_at", Kind: parquet.Timestamp},
+	{Name: "sentiment", Kind: parquet.String},
+	{Name: "score", Kind: parquet.Int64},
+	{Name: "comment_count", Kind: parquet.Int64},
+	{Name: "relevance", Kind: parquet.Double},
+	{Name: "is_question", Kind: parquet.Bool},
+	{Name: "keywords", Kind: parquet.String}, // Comma-separated
+	{Name: "tags", Kind: parquet.String},     // Comma-separated
+}
+
+// RenderParquet renders mentions as a Parquet file with one row per mention
+func RenderParquet(mentions []models.Mention) ([]byte, error) {
+	table := parquet.NewTable(mentionColumns...)
+	for _, mention := range mentions {
+		err := table.Append(
+			mention.ID,
+			mention.Source,
+			mention.Platform,
+			mention.PostType,
+			mention.Title,
+			mention.Content,
+			mention.Author,
+			mention.Channel,
+			mention.URL,
+			mention.CreatedAt,
+			mention.Sentiment,
+			int64(mention.Score),
+			int64(mention.CommentCount),
+			mention.Relevance,
+			mention.IsQuestion,
+			strings.Join(mention.Keywords, ","),
+			strings.Join(mention.Tags, ","),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add mention %s: %w", mention.ID, err)
+		}
+	}
+	return table.Encode()
+}
@@ -0,0 +1,26 @@
+package reports
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderParquet(t *testing.T) {
+	mentions := []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS upgrade stuck", CreatedAt: time.Now(), Score: 12, Keywords: []string{"AKS", "Azure Kubernetes Service"}},
+		{ID: "reddit_2", Source: "reddit", Title: "Node pool autoscaling", CreatedAt: time.Now(), IsQuestion: true, Tags: []string{"autoscaling"}},
+	}
+
+	file, err := RenderParquet(mentions)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(file, []byte("PAR1")))
+	assert.True(t, bytes.HasSuffix(file, []byte("PAR1")))
+	assert.Contains(t, string(file), "AKS upgrade stuck")
+	assert.Contains(t, string(file), "AKS,Azure Kubernetes Service")
+	assert.Contains(t, string(file), "comment_count", "schema is in the footer")
+}
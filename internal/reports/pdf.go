@@ -0,0 +1,88 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultPDFTimeout bounds how long a headless browser may take to print a report
+const defaultPDFTimeout = time.Minute
+
+// waitForImages resolves once every chart image has been decoded, so none print blank
+const waitForImages = `Promise.all(Array.from(document.images).map(img => img.decode().catch(() => null)))`
+
+// PDFRenderer prints HTML reports to PDF with a headless Chrome or Chromium
+type PDFRenderer struct {
+	execPath  string
+	timeout   time.Duration
+	noSandbox bool
+}
+
+// NewPDFRenderer creates a PDF renderer; an empty execPath looks for Chrome or Chromium on the PATH
+func NewPDFRenderer(execPath string) *PDFRenderer {
+	return &PDFRenderer{execPath: execPath, timeout: defaultPDFTimeout}
+}
+
+// WithNoSandbox runs the browser without Chrome's sandbox, for containers where it can't
+// start. The printed pages hold text from the web, so only disable it in a locked-down
+// container: non-root, read-only root filesystem, no privilege escalation, all capabilities
+// dropped and the RuntimeDefault seccomp profile.
+func (r *PDFRenderer) WithNoSandbox(noSandbox bool) *PDFRenderer {
+	r.noSandbox = noSandbox
+	return r
+}
+
+// RenderPDF prints a standalone HTML page, as produced by RenderHTML, to an A4 PDF
+func (r *PDFRenderer) RenderPDF(ctx context.Context, html []byte) ([]byte, error) {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.DisableGPU)
+	if r.noSandbox {
+		opts = append(opts, chromedp.NoSandbox)
+	}
+	if r.execPath != "" {
+		opts = append(opts, chromedp.ExecPath(r.execPath))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancelAlloc()
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	defer cancelBrowser()
+
+	var pdf []byte
+	err := chromedp.Run(browserCtx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			frames, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(frames.Frame.ID, string(html)).Do(ctx)
+		}),
+		chromedp.Evaluate(waitForImages, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			pdf, _, err = page.PrintToPDF().
+				WithPrintBackground(true).
+				WithPaperWidth(8.27).
+				WithPaperHeight(11.69).
+				WithMarginTop(0.4).
+				WithMarginBottom(0.4).
+				WithMarginLeft(0.4).
+				WithMarginRight(0.4).
+				Do(ctx)
+			return err
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print report to PDF: %w", err)
+	}
+	return pdf, nil
+}
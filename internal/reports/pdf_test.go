@@ -0,0 +1,46 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findChrome returns a Chrome or Chromium binary on the PATH, if there is one
+func findChrome() string {
+	for _, name := range []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "headless-shell"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func TestPDFRenderer_RenderPDF(t *testing.T) {
+	chrome := findChrome()
+	if chrome == "" {
+		t.Skip("Chrome or Chromium is not installed")
+	}
+
+	page, err := RenderHTML(&models.Report{
+		GeneratedAt: time.Now(),
+		Period:      "weekly",
+		Mentions:    []models.Mention{{Source: "reddit", Sentiment: "positive", Title: "AKS tips", CreatedAt: time.Now()}},
+	})
+	require.NoError(t, err)
+
+	pdf, err := NewPDFRenderer(chrome).RenderPDF(context.Background(), page)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+}
+
+func TestPDFRenderer_MissingBrowser(t *testing.T) {
+	_, err := NewPDFRenderer("/nonexistent/chrome").RenderPDF(context.Background(), []byte("<html></html>"))
+	assert.ErrorContains(t, err, "failed to print report to PDF")
+}
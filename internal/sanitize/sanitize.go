@@ -0,0 +1,256 @@
+// Package sanitize turns the HTML some sources return (Stack Exchange bodies, Hacker News
+// text, Medium articles, YouTube comments) into plain text that reads cleanly in Teams and
+// email, keeping code blocks and links as markdown.
+package sanitize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Sanitizer converts HTML to text. The zero value is not usable; create one with New.
+type Sanitizer struct {
+	markdown bool
+}
+
+// New creates a sanitizer writing markdown: fenced code blocks, inline code in backticks and
+// [text](url) links
+func New() *Sanitizer {
+	return &Sanitizer{markdown: true}
+}
+
+// WithMarkdown turns markdown on or off. Without it code is left as plain text and links are
+// written as "text (url)", for channels that show markdown literally.
+func (s *Sanitizer) WithMarkdown(enabled bool) *Sanitizer {
+	s.markdown = enabled
+	return s
+}
+
+var (
+	spacePattern     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// skipped elements have no readable content
+var skipped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Svg: true, atom.Head: true, atom.Template: true, atom.Button: true,
+}
+
+// blocks are elements set apart from their surroundings by a blank line
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Blockquote: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Table: true, atom.Figure: true, atom.Hr: true,
+	atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Dl: true,
+}
+
+// Text converts an HTML fragment to text. Entities are decoded, broken and unknown tags
+// dropped, whitespace collapsed outside code blocks, and paragraphs separated by blank lines.
+func (s *Sanitizer) Text(fragment string) string {
+	if !strings.ContainsAny(fragment, "<&") {
+		return strings.TrimSpace(fragment)
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return strings.TrimSpace(fragment)
+	}
+
+	w := &writer{markdown: s.markdown}
+	for _, node := range nodes {
+		w.node(node)
+	}
+
+	lines := strings.Split(w.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// writer accumulates the text of a node tree
+type writer struct {
+	b        strings.Builder
+	last     byte // Last byte written, 0 at the start
+	markdown bool
+	list     []int // Item counters of the enclosing lists, 0 for bulleted lists
+}
+
+func (w *writer) write(text string) {
+	if text != "" {
+		w.b.WriteString(text)
+		w.last = text[len(text)-1]
+	}
+}
+
+func (w *writer) String() string {
+	return w.b.String()
+}
+
+func (w *writer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+
+	switch {
+	case skipped[n.DataAtom]:
+	case n.DataAtom == atom.Br:
+		w.write("\n")
+	case n.DataAtom == atom.Pre:
+		w.pre(n)
+	case n.DataAtom == atom.Code:
+		w.code(n)
+	case n.DataAtom == atom.A:
+		w.link(n)
+	case n.DataAtom == atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			w.text(alt)
+		}
+	case n.DataAtom == atom.Li:
+		w.item(n)
+	case n.DataAtom == atom.Tr:
+		w.write("\n")
+		w.children(n)
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		w.write(" ")
+		w.children(n)
+	case n.DataAtom == atom.Ul || n.DataAtom == atom.Ol:
+		w.listBlock(n)
+	case blocks[n.DataAtom]:
+		w.write("\n\n")
+		w.children(n)
+		w.write("\n\n")
+	default:
+		w.children(n)
+	}
+}
+
+func (w *writer) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.node(child)
+	}
+}
+
+// text writes text with its whitespace collapsed, not starting a line with a space
+func (w *writer) text(text string) {
+	text = spacePattern.ReplaceAllString(text, " ")
+	if strings.HasPrefix(text, " ") && (w.last == 0 || w.last == '\n' || w.last == ' ') {
+		text = text[1:]
+	}
+	w.write(text)
+}
+
+// pre writes a preformatted block as it is, fenced in markdown
+func (w *writer) pre(n *html.Node) {
+	code := strings.Trim(rawText(n), "\n")
+	if w.markdown {
+		w.write("\n\n```\n" + code + "\n```\n\n")
+		return
+	}
+	w.write("\n\n" + code + "\n\n")
+}
+
+// code writes inline code, in backticks in markdown
+func (w *writer) code(n *html.Node) {
+	code := spacePattern.ReplaceAllString(rawText(n), " ")
+	if w.markdown {
+		code = "`" + code + "`"
+	}
+	w.text(code)
+}
+
+// link writes a link as [text](url) in markdown or "text (url)" without it. Links that only
+// repeat their URL, or lead nowhere a reader can follow, keep just their text.
+func (w *writer) link(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+		w.children(n)
+		return
+	}
+
+	inner := &writer{markdown: w.markdown}
+	inner.children(n)
+	text := strings.TrimSpace(spacePattern.ReplaceAllString(inner.String(), " "))
+	switch {
+	case text == "" || text == href || strings.TrimSuffix(href, "/") == text:
+		w.text(href)
+	case w.markdown:
+		w.text("[" + strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text) + "](" + strings.ReplaceAll(href, ")", "%29") + ")")
+	default:
+		w.text(text + " (" + href + ")")
+	}
+}
+
+// item writes a list item on its own line, bulleted or numbered like its list
+func (w *writer) item(n *html.Node) {
+	marker := "- "
+	if depth := len(w.list); depth > 0 && w.list[depth-1] > 0 {
+		marker = strconv.Itoa(w.list[depth-1]) + ". "
+		w.list[depth-1]++
+	}
+	if len(w.list) > 1 {
+		marker = strings.Repeat("  ", len(w.list)-1) + marker
+	}
+	if w.last != 0 && w.last != '\n' {
+		w.write("\n")
+	}
+	w.write(marker)
+	w.children(n)
+}
+
+// listBlock writes a list apart from the surrounding text; nested lists continue their item
+func (w *writer) listBlock(n *html.Node) {
+	separator := "\n\n"
+	if len(w.list) > 0 {
+		separator = "\n"
+	}
+	counter := 0
+	if n.DataAtom == atom.Ol {
+		counter = 1
+	}
+
+	w.write(separator)
+	w.list = append(w.list, counter)
+	w.children(n)
+	w.list = w.list[:len(w.list)-1]
+	w.write(separator)
+}
+
+// rawText returns the text inside a node with its whitespace untouched
+func rawText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			b.WriteString(node.Data)
+		}
+		if node.Type == html.ElementNode && node.DataAtom == atom.Br {
+			b.WriteString("\n")
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
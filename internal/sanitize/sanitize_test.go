@@ -0,0 +1,81 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizer_Text(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Basic HTML tags",
+			input:    "<p>Hello <strong>world</strong></p>",
+			expected: "Hello world",
+		},
+		{
+			name:     "Code tags",
+			input:    "Use <code>kubectl apply</code> to deploy",
+			expected: "Use `kubectl apply` to deploy",
+		},
+		{
+			name:     "Line breaks",
+			input:    "Line 1<br>Line 2<br/>Line 3",
+			expected: "Line 1\nLine 2\nLine 3",
+		},
+		{
+			name:     "No HTML tags",
+			input:    "Plain text content",
+			expected: "Plain text content",
+		},
+		{
+			name:     "Entities and paragraphs",
+			input:    "AKS upgrades keep failing<p>We&#x27;re moving off <a href=\"https://example.com\">it</a> &amp; back to VMs",
+			expected: "AKS upgrades keep failing\n\nWe're moving off [it](https://example.com) & back to VMs",
+		},
+		{
+			name:     "Code block",
+			input:    "<p>My manifest:</p>\n<pre><code>apiVersion: v1\nkind: Pod\n  name: &lt;app&gt;\n</code></pre>\n<p>Why?</p>",
+			expected: "My manifest:\n\n```\napiVersion: v1\nkind: Pod\n  name: <app>\n```\n\nWhy?",
+		},
+		{
+			name:     "Links repeating their URL and relative links",
+			input:    `See <a href="https://aka.ms/aks">https://aka.ms/aks</a> and <a href="/questions/1">this question</a>`,
+			expected: "See https://aka.ms/aks and this question",
+		},
+		{
+			name:     "Lists",
+			input:    "<ul><li>Create the cluster</li><li>Run <code>az aks get-credentials</code></li></ul><ol><li>One</li><li>Two</li></ol>",
+			expected: "- Create the cluster\n- Run `az aks get-credentials`\n\n1. One\n2. Two",
+		},
+		{
+			name:     "Nested lists",
+			input:    "<p>Steps</p><ul><li>Upgrade<ul><li>Control plane</li><li>Node pools</li></ul></li><li>Verify</li></ul>",
+			expected: "Steps\n\n- Upgrade\n  - Control plane\n  - Node pools\n- Verify",
+		},
+		{
+			name:     "Broken tags, scripts and images",
+			input:    `<div>Cluster <b>down<script>alert(1)</script> <img alt="screenshot" src="x.png"> since 9am</div><p`,
+			expected: "Cluster down screenshot since 9am",
+		},
+	}
+
+	sanitizer := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sanitizer.Text(tt.input))
+		})
+	}
+}
+
+func TestSanitizer_WithoutMarkdown(t *testing.T) {
+	sanitizer := New().WithMarkdown(false)
+
+	input := `<p>Run <code>kubectl get pods</code>, see <a href="https://learn.microsoft.com/azure/aks">the docs</a></p><pre>line 1
+line 2</pre>`
+	assert.Equal(t, "Run kubectl get pods, see the docs (https://learn.microsoft.com/azure/aks)\n\nline 1\nline 2", sanitizer.Text(input))
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/robfig/cron/v3"
+)
+
+// upcomingRuns is the number of fire times the status lists per job
+const upcomingRuns = 5
+
+// descriptors expands the cron descriptors into their six-field expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// describeSchedule breaks a schedule into its cron fields and lists its next n fire times
+// after from. It returns nil for schedules that don't parse.
+func describeSchedule(schedule string, from time.Time, n int) (*CronEntry, []time.Time) {
+	parsed, err := config.ParseSchedule(schedule)
+	if err != nil {
+		return nil, nil
+	}
+
+	var upcoming []time.Time
+	for next := parsed.Next(from); !next.IsZero() && len(upcoming) < n; next = parsed.Next(next) {
+		upcoming = append(upcoming, next)
+	}
+
+	entry := &CronEntry{}
+	if every, ok := parsed.(cron.ConstantDelaySchedule); ok {
+		entry.Every = every.Delay.String()
+		return entry, upcoming
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		_, entry.TimeZone, _ = strings.Cut(fields[0], "=")
+		fields = fields[1:]
+	}
+	if len(fields) == 1 {
+		fields = strings.Fields(descriptors[fields[0]])
+	}
+	if len(fields) == 6 {
+		entry.Second, entry.Minute, entry.Hour = fields[0], fields[1], fields[2]
+		entry.DayOfMonth, entry.Month, entry.DayOfWeek = fields[3], fields[4], fields[5]
+	}
+	return entry, upcoming
+}
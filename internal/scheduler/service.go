@@ -1,76 +1,429 @@
 package scheduler
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/azure/aks-mentions-bot/internal/config"
 	"github.com/azure/aks-mentions-bot/internal/monitoring"
+	"github.com/azure/aks-mentions-bot/internal/storage"
 	"github.com/robfig/cron/v3"
 	"github.com/sirupsen/logrus"
 )
 
+// Job names accepted by the scheduler API
+const (
+	JobReport       = "report"
+	JobUrgent       = "urgent"
+	JobUrgentDigest = "urgent-digest"
+	JobDeliveries   = "notification-retry"
+	// JobSourcePrefix names the jobs of sources with their own schedule, e.g. "source-twitter"
+	JobSourcePrefix = "source-"
+)
+
+// urgentSchedule runs the urgent mentions check every 4 hours
+const urgentSchedule = "0 0 */4 * * *"
+
+// deliveriesSchedule retries queued notification deliveries every 5 minutes; each delivery
+// waits out its own backoff
+const deliveriesSchedule = "0 */5 * * * *"
+
+// urgentDigestSchedule sends the summary of the day's urgent stories daily at 8 AM UTC
+const urgentDigestSchedule = "0 0 8 * * *"
+
+var (
+	// ErrUnknownJob is returned when a job name does not match a scheduled job
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrInvalidSchedule is returned for cron expressions that cannot be parsed
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
 // Service handles scheduling of monitoring tasks
 type Service struct {
 	config            *config.Config
 	monitoringService *monitoring.Service
+	deliveries        DeliveryQueue
 	cron              *cron.Cron
+	store             storage.StorageInterface
+
+	mu      sync.Mutex
+	jobs    []*job
+	state   State
+	running bool // Between Start and Stop
+
+	stopped  chan struct{} // Closed by Stop to cancel runs still waiting out their jitter
+	stopOnce sync.Once
+}
+
+// job is a named scheduled task
+type job struct {
+	name     string
+	schedule string
+	run      func()
+	entryID  cron.EntryID
+	noJitter bool // Jitter spreads out source queries, so jobs that don't query sources skip it
 }
 
 // NewService creates a new scheduler service
 func NewService(cfg *config.Config, monitoringService *monitoring.Service) *Service {
-	return &Service{
+	s := &Service{
 		config:            cfg,
 		monitoringService: monitoringService,
 		cron:              cron.New(cron.WithSeconds()),
+		stopped:           make(chan struct{}),
 	}
+
+	s.jobs = []*job{
+		{name: JobReport, schedule: reportSchedule(cfg.ReportSchedule), run: s.runReport},
+		{name: JobUrgent, schedule: urgentSchedule, run: s.runUrgentCheck},
+	}
+	if cfg.UrgentDigestEnabled {
+		s.jobs = append(s.jobs, &job{name: JobUrgentDigest, schedule: urgentDigestSchedule, run: s.runUrgentDigest})
+	}
+
+	// Sources with their own schedule are collected by their own jobs; the report job reports
+	// what they collected
+	var scheduled []string
+	for source := range cfg.SourceSchedules {
+		scheduled = append(scheduled, source)
+	}
+	sort.Strings(scheduled)
+	for _, source := range scheduled {
+		source := source
+		j := &job{name: JobSourcePrefix + source, schedule: cfg.SourceSchedules[source]}
+		j.run = func() { s.runCollection(j, source) }
+		s.jobs = append(s.jobs, j)
+	}
+
+	return s
 }
 
-// Start begins the scheduled monitoring
-func (s *Service) Start() error {
-	var cronExpression string
+// DeliveryQueue retries notification deliveries that failed
+type DeliveryQueue interface {
+	RetryQueued() (delivered, failed int, err error)
+}
+
+// WithDeliveryQueue retries the queued notification deliveries on a schedule
+func (s *Service) WithDeliveryQueue(queue DeliveryQueue) *Service {
+	s.deliveries = queue
+	s.jobs = append(s.jobs, &job{name: JobDeliveries, schedule: deliveriesSchedule, run: s.runDeliveries, noJitter: true})
+	return s
+}
 
-	switch s.config.ReportSchedule {
+// WithStateStore persists pause state and schedule changes so they survive restarts
+func (s *Service) WithStateStore(store storage.StorageInterface) *Service {
+	s.store = store
+	return s
+}
+
+// reportSchedule returns the cron expression for the configured report schedule
+func reportSchedule(schedule string) string {
+	switch schedule {
 	case "daily":
 		// Run daily at 9 AM UTC
-		cronExpression = "0 0 9 * * *"
+		return "0 0 9 * * *"
 	case "weekly":
 		// Run weekly on Monday at 9 AM UTC
-		cronExpression = "0 0 9 * * MON"
+		return "0 0 9 * * MON"
 	default:
 		// Default to weekly
-		cronExpression = "0 0 9 * * MON"
+		return "0 0 9 * * MON"
 	}
+}
 
-	_, err := s.cron.AddFunc(cronExpression, func() {
-		logrus.Info("Starting scheduled monitoring run")
-		if err := s.monitoringService.RunMonitoring(); err != nil {
-			logrus.Errorf("Scheduled monitoring run failed: %v", err)
-		}
-	})
-
+// Interval returns the longest time between consecutive runs of a cron schedule within the
+// next week, the window a source collected on that schedule searches without a watermark
+func Interval(schedule string) (time.Duration, error) {
+	parsed, err := config.ParseSchedule(schedule)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
 	}
 
-	// Also add a more frequent check for critical issues (every 4 hours)
-	_, err = s.cron.AddFunc("0 0 */4 * * *", func() {
-		logrus.Info("Starting urgent mentions check (4-hour frequency)")
-		if err := s.monitoringService.RunUrgentCheck(); err != nil {
-			logrus.Errorf("Urgent mentions check failed: %v", err)
+	now := time.Now()
+	next := parsed.Next(now)
+	var longest time.Duration
+	for i := 0; i < 1000 && next.Before(now.Add(7*24*time.Hour)); i++ {
+		following := parsed.Next(next)
+		if following.IsZero() {
+			break
+		}
+		if gap := following.Sub(next); gap > longest {
+			longest = gap
 		}
-	})
+		next = following
+	}
+	return longest, nil
+}
 
-	if err != nil {
-		return err
+// Jitter returns a random delay below max, spreading out runs of bot instances deployed
+// with the same schedule so they don't all query the sources at the same moment
+func Jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// Start begins the scheduled monitoring
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadState(); err != nil {
+		logrus.Warnf("Failed to load scheduler state, using configured schedules: %v", err)
+	}
+
+	now := time.Now()
+	for _, j := range s.jobs {
+		if schedule, ok := s.state.Schedules[j.name]; ok {
+			if schedule != j.schedule {
+				logrus.Infof("Using the %s job schedule %q set through the API instead of %q", j.name, schedule, j.schedule)
+			}
+			j.schedule = schedule
+		}
+		if err := s.addJob(j); err != nil {
+			return err
+		}
+		if _, upcoming := describeSchedule(j.schedule, now, 1); len(upcoming) > 0 {
+			logrus.Infof("Scheduled %s job %q, next run at %s", j.name, j.schedule, upcoming[0].Format(time.RFC3339))
+		}
 	}
 
 	s.cron.Start()
+	s.running = true
 	logrus.Infof("Scheduler started with %s schedule (plus urgent checks every 4 hours)", s.config.ReportSchedule)
+	if len(s.config.SourceSchedules) > 0 {
+		logrus.Infof("%d sources are collected on their own schedule and reported by the %s report", len(s.config.SourceSchedules), s.config.ReportSchedule)
+	}
+	if s.config.ScheduleJitter > 0 {
+		logrus.Infof("Scheduled runs start up to %s after their scheduled time", s.config.ScheduleJitter)
+	}
+	if s.state.Paused {
+		logrus.Warn("Scheduler is paused; scheduled runs will be skipped until resumed")
+	}
 	return nil
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler; calling it again has no effect
 func (s *Service) Stop() {
 	if s.cron != nil {
 		s.cron.Stop()
+		s.stopOnce.Do(func() { close(s.stopped) })
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
 		logrus.Info("Scheduler stopped")
 	}
 }
+
+func (s *Service) addJob(j *job) error {
+	id, err := s.cron.AddFunc(j.schedule, func() { s.runJob(j) })
+	if err != nil {
+		return fmt.Errorf("failed to schedule %s job: %w", j.name, err)
+	}
+	j.entryID = id
+	return nil
+}
+
+// runJob runs a job after its jitter unless the scheduler is paused
+func (s *Service) runJob(j *job) {
+	if delay := Jitter(s.config.ScheduleJitter); delay > 0 && !j.noJitter {
+		logrus.Infof("Delaying scheduled %s job by %s", j.name, delay.Round(time.Second))
+		select {
+		case <-time.After(delay):
+		case <-s.stopped:
+			return
+		}
+	}
+
+	if s.pausedNow() {
+		logrus.Infof("Skipping scheduled %s job: scheduler is paused", j.name)
+		return
+	}
+	j.run()
+}
+
+func (s *Service) runReport() {
+	logrus.Info("Starting scheduled monitoring run")
+	if err := s.monitoringService.RunMonitoring(); errors.Is(err, monitoring.ErrRunInProgress) {
+		logrus.Warnf("Skipping scheduled monitoring run: %v", err)
+	} else if err != nil {
+		logrus.Errorf("Scheduled monitoring run failed: %v", err)
+	}
+}
+
+func (s *Service) runCollection(j *job, source string) {
+	s.mu.Lock()
+	schedule := j.schedule
+	s.mu.Unlock()
+
+	window, err := Interval(schedule)
+	if err != nil {
+		logrus.Errorf("Scheduled collection of %s failed: %v", source, err)
+		return
+	}
+	logrus.Infof("Starting scheduled collection of %s", source)
+	if err := s.monitoringService.CollectSource(source, window); err != nil {
+		logrus.Errorf("Scheduled collection of %s failed: %v", source, err)
+	}
+}
+
+func (s *Service) runUrgentCheck() {
+	logrus.Info("Starting urgent mentions check (4-hour frequency)")
+	if err := s.monitoringService.RunUrgentCheck(); err != nil {
+		logrus.Errorf("Urgent mentions check failed: %v", err)
+	}
+}
+
+func (s *Service) runUrgentDigest() {
+	logrus.Info("Sending the daily urgent digest")
+	if err := s.monitoringService.SendUrgentDigest(); err != nil {
+		logrus.Errorf("Urgent digest failed: %v", err)
+	}
+}
+
+func (s *Service) runDeliveries() {
+	delivered, failed, err := s.deliveries.RetryQueued()
+	if err != nil {
+		logrus.Errorf("Retrying queued notifications failed: %v", err)
+		return
+	}
+	if delivered > 0 || failed > 0 {
+		logrus.Infof("Retried queued notifications: %d delivered, %d given up on", delivered, failed)
+	}
+}
+
+// pausedNow reports whether runs should be skipped, resuming automatically once a timed
+// pause has expired
+func (s *Service) pausedNow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.state.Paused {
+		return false
+	}
+
+	if s.state.PausedUntil != nil && time.Now().After(*s.state.PausedUntil) {
+		logrus.Info("Scheduler pause expired, resuming scheduled runs")
+		s.state.Paused = false
+		s.state.PausedUntil = nil
+		s.state.PauseReason = ""
+		if err := s.saveState(); err != nil {
+			logrus.Warnf("Failed to persist scheduler state: %v", err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// Pause skips scheduled runs until Resume is called or until passes (nil pauses indefinitely)
+func (s *Service) Pause(until *time.Time, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Paused = true
+	s.state.PausedUntil = until
+	s.state.PauseReason = reason
+
+	if until != nil {
+		logrus.Infof("Scheduler paused until %s: %s", until.Format(time.RFC3339), reason)
+	} else {
+		logrus.Infof("Scheduler paused: %s", reason)
+	}
+	return s.saveState()
+}
+
+// Resume re-enables scheduled runs
+func (s *Service) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Paused = false
+	s.state.PausedUntil = nil
+	s.state.PauseReason = ""
+
+	logrus.Info("Scheduler resumed")
+	return s.saveState()
+}
+
+// Reschedule changes a job's cron expression at runtime
+func (s *Service) Reschedule(name, schedule string) error {
+	if _, err := config.ParseSchedule(schedule); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j := s.findJob(name)
+	if j == nil {
+		return fmt.Errorf("%w %q", ErrUnknownJob, name)
+	}
+
+	if j.entryID != 0 {
+		s.cron.Remove(j.entryID)
+	}
+	j.schedule = schedule
+	if err := s.addJob(j); err != nil {
+		return err
+	}
+
+	if s.state.Schedules == nil {
+		s.state.Schedules = make(map[string]string)
+	}
+	s.state.Schedules[name] = schedule
+
+	logrus.Infof("Rescheduled %s job to %q", name, schedule)
+	return s.saveState()
+}
+
+// Status returns the pause state and the schedule of every job
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Running:     s.running,
+		Paused:      s.state.Paused,
+		PausedUntil: s.state.PausedUntil,
+		PauseReason: s.state.PauseReason,
+	}
+	if s.config.ScheduleJitter > 0 {
+		status.Jitter = s.config.ScheduleJitter.String()
+	}
+
+	now := time.Now()
+	for _, j := range s.jobs {
+		jobStatus := JobStatus{Name: j.name, Schedule: j.schedule}
+		_, jobStatus.Override = s.state.Schedules[j.name]
+		jobStatus.Cron, jobStatus.Upcoming = describeSchedule(j.schedule, now, upcomingRuns)
+		if j.entryID != 0 {
+			entry := s.cron.Entry(j.entryID)
+			if !entry.Next.IsZero() {
+				next := entry.Next
+				jobStatus.NextRun = &next
+			}
+			if !entry.Prev.IsZero() {
+				prev := entry.Prev
+				jobStatus.PrevRun = &prev
+			}
+		}
+		status.Jobs = append(status.Jobs, jobStatus)
+	}
+
+	return status
+}
+
+func (s *Service) findJob(name string) *job {
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PauseResume(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := NewService(&config.Config{ReportSchedule: "daily"}, nil).WithStateStore(store)
+	assert.False(t, service.Status().Running)
+	require.NoError(t, service.Start())
+	defer service.Stop()
+
+	assert.True(t, service.Status().Running)
+	assert.False(t, service.pausedNow())
+
+	require.NoError(t, service.Pause(nil, "incident"))
+	assert.True(t, service.pausedNow())
+	assert.Equal(t, "incident", service.Status().PauseReason)
+
+	require.NoError(t, service.Resume())
+	assert.False(t, service.pausedNow())
+
+	// Timed pauses resume on their own
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, service.Pause(&past, "holiday"))
+	assert.False(t, service.pausedNow())
+	assert.False(t, service.Status().Paused)
+}
+
+func TestService_Reschedule(t *testing.T) {
+	store := testutil.NewMemoryStorage()
+	service := NewService(&config.Config{ReportSchedule: "weekly"}, nil).WithStateStore(store)
+	require.NoError(t, service.Start())
+
+	assert.ErrorIs(t, service.Reschedule("nightly", "0 0 1 * * *"), ErrUnknownJob)
+	assert.ErrorIs(t, service.Reschedule(JobReport, "every tuesday"), ErrInvalidSchedule)
+
+	require.NoError(t, service.Reschedule(JobReport, "0 30 8 * * TUE"))
+	require.NoError(t, service.Pause(nil, "holiday"))
+
+	status := service.Status()
+	require.Len(t, status.Jobs, 2)
+	assert.Equal(t, "0 30 8 * * TUE", status.Jobs[0].Schedule)
+	assert.True(t, status.Jobs[0].Override)
+	assert.False(t, status.Jobs[1].Override)
+	require.NotNil(t, status.Jobs[0].NextRun)
+	assert.Equal(t, time.Tuesday, status.Jobs[0].NextRun.Weekday())
+	require.Len(t, status.Jobs[0].Upcoming, upcomingRuns)
+	assert.Equal(t, *status.Jobs[0].NextRun, status.Jobs[0].Upcoming[0])
+	assert.Equal(t, 7*24*time.Hour, status.Jobs[0].Upcoming[1].Sub(status.Jobs[0].Upcoming[0]))
+	service.Stop()
+	assert.False(t, service.Status().Running)
+
+	// Persisted changes survive a restart
+	restarted := NewService(&config.Config{ReportSchedule: "weekly"}, nil).WithStateStore(store)
+	require.NoError(t, restarted.Start())
+	defer restarted.Stop()
+
+	status = restarted.Status()
+	assert.True(t, status.Paused)
+	assert.Equal(t, "0 30 8 * * TUE", status.Jobs[0].Schedule)
+	assert.Equal(t, urgentSchedule, status.Jobs[1].Schedule)
+}
+
+func TestService_SourceSchedules(t *testing.T) {
+	service := NewService(&config.Config{ReportSchedule: "daily", SourceSchedules: map[string]string{
+		"twitter": "@every 2h",
+		"medium":  "@daily",
+	}}, nil)
+	require.NoError(t, service.Start())
+	defer service.Stop()
+
+	status := service.Status()
+	require.Len(t, status.Jobs, 4)
+	assert.Equal(t, JobSourcePrefix+"medium", status.Jobs[2].Name)
+	assert.Equal(t, JobSourcePrefix+"twitter", status.Jobs[3].Name)
+	require.NoError(t, service.Reschedule(JobSourcePrefix+"twitter", "@every 6h"))
+}
+
+func TestInterval(t *testing.T) {
+	for schedule, expected := range map[string]time.Duration{
+		"@every 2h":         2 * time.Hour,
+		"@daily":            24 * time.Hour,
+		"0 0 9,15,21 * * *": 12 * time.Hour,
+		"0 0 9 * * MON":     7 * 24 * time.Hour,
+	} {
+		interval, err := Interval(schedule)
+		require.NoError(t, err)
+		assert.Equal(t, expected, interval, schedule)
+	}
+
+	_, err := Interval("every two hours")
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestDescribeSchedule(t *testing.T) {
+	from := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	entry, upcoming := describeSchedule("0 0 9 * * MON", from, 2)
+	assert.Equal(t, &CronEntry{Second: "0", Minute: "0", Hour: "9", DayOfMonth: "*", Month: "*", DayOfWeek: "MON"}, entry)
+	assert.Equal(t, []time.Time{time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC), time.Date(2024, 6, 17, 9, 0, 0, 0, time.UTC)}, upcoming)
+
+	entry, _ = describeSchedule("@daily", from, 1)
+	assert.Equal(t, "0", entry.Hour)
+	entry, _ = describeSchedule("CRON_TZ=Europe/Berlin 0 30 8 * * *", from, 1)
+	assert.Equal(t, "Europe/Berlin", entry.TimeZone)
+	assert.Equal(t, "8", entry.Hour)
+
+	entry, upcoming = describeSchedule("@every 2h", from, 3)
+	assert.Equal(t, &CronEntry{Every: "2h0m0s"}, entry)
+	assert.Equal(t, from.Add(6*time.Hour), upcoming[2])
+
+	entry, upcoming = describeSchedule("every tuesday", from, 3)
+	assert.Nil(t, entry)
+	assert.Nil(t, upcoming)
+}
+
+func TestJitter(t *testing.T) {
+	assert.Zero(t, Jitter(0))
+	assert.Zero(t, Jitter(-time.Minute))
+
+	for i := 0; i < 100; i++ {
+		delay := Jitter(time.Minute)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, time.Minute)
+	}
+}
+
+func TestService_runJob_Jitter(t *testing.T) {
+	service := NewService(&config.Config{ReportSchedule: "daily", ScheduleJitter: time.Hour}, nil)
+	require.NoError(t, service.Start())
+	assert.Equal(t, "1h0m0s", service.Status().Jitter)
+
+	ran := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.runJob(&job{name: "test", run: func() { ran <- struct{}{} }})
+	}()
+
+	// Stopping the scheduler cancels runs still waiting out their jitter
+	service.Stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runJob kept waiting after Stop")
+	}
+	assert.Empty(t, ran)
+
+	assert.NotPanics(t, service.Stop, "stopping twice is a no-op")
+}
@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// stateBlob holds the scheduler state changed through the API
+const stateBlob = "scheduler/state.json"
+
+// State is the persisted runtime scheduler configuration
+type State struct {
+	Paused      bool              `json:"paused"`
+	PausedUntil *time.Time        `json:"paused_until,omitempty"`
+	PauseReason string            `json:"pause_reason,omitempty"`
+	Schedules   map[string]string `json:"schedules,omitempty"` // Cron overrides keyed by job name
+}
+
+// Status describes the scheduler for the API
+type Status struct {
+	Running     bool        `json:"running"` // Started and not stopped, whether or not paused
+	Paused      bool        `json:"paused"`
+	PausedUntil *time.Time  `json:"paused_until,omitempty"`
+	PauseReason string      `json:"pause_reason,omitempty"`
+	Jitter      string      `json:"jitter,omitempty"` // Maximum delay of runs after their scheduled time, e.g. "15m0s"
+	Jobs        []JobStatus `json:"jobs"`
+}
+
+// JobStatus describes a single scheduled job
+type JobStatus struct {
+	Name     string      `json:"name"`
+	Schedule string      `json:"schedule"`
+	Override bool        `json:"override,omitempty"` // Schedule set through the API, replacing the configured one
+	Cron     *CronEntry  `json:"cron,omitempty"`
+	NextRun  *time.Time  `json:"next_run,omitempty"`
+	PrevRun  *time.Time  `json:"prev_run,omitempty"`
+	Upcoming []time.Time `json:"upcoming,omitempty"` // Next fire times, before jitter, whether or not the scheduler runs
+}
+
+// CronEntry is a schedule broken into its cron fields, with descriptors such as @daily
+// expanded, or the interval of an @every schedule
+type CronEntry struct {
+	TimeZone   string `json:"time_zone,omitempty"` // From a CRON_TZ= prefix; otherwise the process's local time
+	Second     string `json:"second,omitempty"`
+	Minute     string `json:"minute,omitempty"`
+	Hour       string `json:"hour,omitempty"`
+	DayOfMonth string `json:"day_of_month,omitempty"`
+	Month      string `json:"month,omitempty"`
+	DayOfWeek  string `json:"day_of_week,omitempty"`
+	Every      string `json:"every,omitempty"`
+}
+
+// loadState reads the persisted state; callers must hold s.mu
+func (s *Service) loadState() error {
+	if s.store == nil {
+		return nil
+	}
+
+	data, found, err := storage.RetrieveIfExists(s.store, stateBlob)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve scheduler state: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse scheduler state: %w", err)
+	}
+
+	// Drop persisted schedules that no longer parse rather than failing startup
+	for name, schedule := range state.Schedules {
+		if _, err := config.ParseSchedule(schedule); err != nil {
+			logrus.Warnf("Ignoring invalid persisted schedule %q for %s job: %v", schedule, name, err)
+			delete(state.Schedules, name)
+		}
+	}
+
+	s.state = state
+	return nil
+}
+
+// saveState persists the current state; callers must hold s.mu
+func (s *Service) saveState() error {
+	if s.store == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduler state: %w", err)
+	}
+
+	if err := s.store.Store(stateBlob, data); err != nil {
+		return fmt.Errorf("failed to store scheduler state: %w", err)
+	}
+	return nil
+}
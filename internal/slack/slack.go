@@ -0,0 +1,217 @@
+// Package slack handles the /aksmentions Slack slash command: verifying requests, parsing
+// the command text and replying.
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// signatureMaxAge rejects replayed requests, as Slack recommends
+	signatureMaxAge = 5 * time.Minute
+
+	// DefaultWindow is searched when a query names no window
+	DefaultWindow = 7 * 24 * time.Hour
+	// MaxWindow bounds how far back a query may search
+	MaxWindow = 90 * 24 * time.Hour
+)
+
+// Command actions
+const (
+	ActionQuery   = "query"
+	ActionTrigger = "trigger"
+	ActionHelp    = "help"
+)
+
+// Response types of a command reply
+const (
+	ResponseEphemeral = "ephemeral" // Only the user who ran the command sees it
+	ResponseInChannel = "in_channel"
+)
+
+var (
+	// ErrInvalidSignature is returned for requests not signed with the app's signing secret
+	ErrInvalidSignature = errors.New("invalid Slack request signature")
+	// ErrInvalidCommand is returned for command text that cannot be parsed
+	ErrInvalidCommand = errors.New("invalid command")
+)
+
+// Usage describes the command syntax
+const Usage = "*Usage*\n" +
+	"• `/aksmentions last 7d negative` – mentions from the last 7 days (h, d or w) with a sentiment\n" +
+	"• `/aksmentions last 24h reddit cilium upgrade` – narrow to a source and search for words\n" +
+	"• `/aksmentions #pricing` – mentions with a curated tag\n" +
+	"• `/aksmentions trigger` – start a monitoring run now\n" +
+	"Add `public` to share the results with the channel."
+
+// Command is a parsed /aksmentions command
+type Command struct {
+	Action    string
+	Window    time.Duration
+	Sentiment string
+	Source    string
+	Tag       string
+	Text      string // Words searched for in mention titles and content
+	Public    bool   // Reply in the channel instead of only to the user
+}
+
+// Response is a slash command reply
+type Response struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// VerifyRequest checks the request's X-Slack-Signature against the signing secret, and that
+// it was sent in the last five minutes
+func VerifyRequest(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+		return fmt.Errorf("%w: stale timestamp", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseCommand parses the text after /aksmentions. Words naming a sentiment or one of the
+// known sources filter the results, "last <window>" or a bare window such as "48h" sets how
+// far back to look, "#tag" selects a curated tag and the remaining words are searched for.
+func ParseCommand(text string, sources []string) (*Command, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) == 0 || (len(fields) == 1 && fields[0] == ActionHelp) {
+		return &Command{Action: ActionHelp}, nil
+	}
+	if len(fields) == 1 && fields[0] == ActionTrigger {
+		return &Command{Action: ActionTrigger}, nil
+	}
+
+	command := &Command{Action: ActionQuery}
+	var words []string
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "last" && i+1 < len(fields):
+			window, err := parseWindow(fields[i+1])
+			if err != nil {
+				return nil, err
+			}
+			command.Window = window
+			i++
+		case field == "positive" || field == "negative" || field == "neutral":
+			command.Sentiment = field
+		case field == "public":
+			command.Public = true
+		case strings.HasPrefix(field, "#") && len(field) > 1:
+			command.Tag = field[1:]
+		case containsString(sources, field):
+			command.Source = field
+		default:
+			if window, err := parseWindow(field); err == nil {
+				command.Window = window
+				continue
+			}
+			words = append(words, field)
+		}
+	}
+	command.Text = strings.Join(words, " ")
+
+	if command.Window == 0 && command.Tag == "" && command.Text == "" {
+		command.Window = DefaultWindow
+	}
+	return command, nil
+}
+
+// parseWindow accepts whole hours, days or weeks such as "12h", "7d" or "2w"
+func parseWindow(raw string) (time.Duration, error) {
+	if len(raw) < 2 {
+		return 0, fmt.Errorf("%w: %q is not a window such as 24h, 7d or 2w", ErrInvalidCommand, raw)
+	}
+
+	count, err := strconv.Atoi(raw[:len(raw)-1])
+	if err != nil || count < 1 {
+		return 0, fmt.Errorf("%w: %q is not a window such as 24h, 7d or 2w", ErrInvalidCommand, raw)
+	}
+
+	var unit time.Duration
+	switch raw[len(raw)-1] {
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("%w: %q is not a window such as 24h, 7d or 2w", ErrInvalidCommand, raw)
+	}
+
+	window := time.Duration(count) * unit
+	if window > MaxWindow {
+		return 0, fmt.Errorf("%w: windows are limited to %d days", ErrInvalidCommand, int(MaxWindow.Hours()/24))
+	}
+	return window, nil
+}
+
+// Escape escapes the characters Slack treats as control characters in message text
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// Link formats a link in Slack's mrkdwn syntax
+func Link(url, label string) string {
+	return fmt.Sprintf("<%s|%s>", strings.NewReplacer("|", "%7C", ">", "%3E").Replace(url), Escape(label))
+}
+
+// Responder posts delayed replies to a command's response_url
+type Responder struct {
+	client *resty.Client
+}
+
+// NewResponder creates a responder
+func NewResponder() *Responder {
+	return &Responder{client: resty.New().SetTimeout(30 * time.Second)}
+}
+
+// Reply posts a reply to the command's response_url, which accepts replies for 30 minutes
+func (r *Responder) Reply(ctx context.Context, responseURL string, response Response) error {
+	resp, err := r.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(response).
+		Post(responseURL)
+	if err != nil {
+		return fmt.Errorf("failed to reply to Slack: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("slack response URL returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedHeader(secret string, timestamp time.Time, body []byte) http.Header {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + string(body)))
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", ts)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestVerifyRequest(t *testing.T) {
+	now := time.Now()
+	body := []byte("command=%2Faksmentions&text=last+7d+negative")
+
+	assert.NoError(t, VerifyRequest("secret", signedHeader("secret", now, body), body, now))
+
+	err := VerifyRequest("other", signedHeader("secret", now, body), body, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	err = VerifyRequest("secret", signedHeader("secret", now, body), []byte("text=trigger"), now)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "tampered body")
+
+	err = VerifyRequest("secret", signedHeader("secret", now.Add(-10*time.Minute), body), body, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "replayed request")
+
+	err = VerifyRequest("secret", http.Header{}, body, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "unsigned request")
+}
+
+func TestParseCommand(t *testing.T) {
+	sources := []string{"reddit", "twitter", "youtube"}
+
+	tests := []struct {
+		name string
+		text string
+		want Command
+	}{
+		{"empty shows help", "", Command{Action: ActionHelp}},
+		{"help", "help", Command{Action: ActionHelp}},
+		{"trigger", "Trigger", Command{Action: ActionTrigger}},
+		{"window and sentiment", "last 7d negative", Command{Action: ActionQuery, Window: 7 * 24 * time.Hour, Sentiment: "negative"}},
+		{"bare window", "48h", Command{Action: ActionQuery, Window: 48 * time.Hour}},
+		{"weeks", "last 2w", Command{Action: ActionQuery, Window: 14 * 24 * time.Hour}},
+		{"default window", "positive reddit", Command{Action: ActionQuery, Window: DefaultWindow, Sentiment: "positive", Source: "reddit"}},
+		{"text without window", "cilium upgrade", Command{Action: ActionQuery, Text: "cilium upgrade"}},
+		{"tag", "#pricing public", Command{Action: ActionQuery, Tag: "pricing", Public: true}},
+		{"trigger among words is text", "trigger happy", Command{Action: ActionQuery, Text: "trigger happy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := ParseCommand(tt.text, sources)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *command)
+		})
+	}
+}
+
+func TestParseCommand_InvalidWindow(t *testing.T) {
+	_, err := ParseCommand("last week", nil)
+	assert.ErrorIs(t, err, ErrInvalidCommand)
+
+	_, err = ParseCommand("last 365d", nil)
+	assert.ErrorIs(t, err, ErrInvalidCommand)
+}
+
+func TestLink(t *testing.T) {
+	assert.Equal(t, "<https://example.com/a%7Cb|AKS &lt;3 &amp; more>", Link("https://example.com/a|b", "AKS <3 & more"))
+}
+
+func TestResponder_Reply(t *testing.T) {
+	var received Response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewResponder().Reply(context.Background(), server.URL, Response{ResponseType: ResponseInChannel, Text: "done"})
+	require.NoError(t, err)
+	assert.Equal(t, Response{ResponseType: ResponseInChannel, Text: "done"}, received)
+}
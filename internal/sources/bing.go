@@ -0,0 +1,305 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultBingEndpoint is the Bing Web Search API v7 endpoint. Microsoft retired the Bing
+// Search APIs on August 11, 2025, so searches only work against an endpoint serving the same
+// API, set with WithEndpoint.
+const defaultBingEndpoint = "https://api.bing.microsoft.com/v7.0/search"
+
+// bingMaxCount is the most results Bing returns per request
+const bingMaxCount = 50
+
+// defaultMaxResultsPerDomain is how many results the web source keeps from one domain
+const defaultMaxResultsPerDomain = 3
+
+// webExcludedSites are searched by their own sources, so the web source leaves them out
+var webExcludedSites = []string{
+	"reddit.com", "stackoverflow.com", "news.ycombinator.com", "twitter.com", "x.com",
+	"threads.net", "youtube.com", "medium.com", "linkedin.com", "gitlab.com", "bitbucket.org",
+}
+
+// WebSearch searches the web with the Bing Web Search API. It stands in for a search API
+// on platforms without a usable one, such as Medium and LinkedIn.
+type WebSearch struct {
+	client   *resty.Client
+	endpoint string
+	apiKey   string
+}
+
+// WebResult is a page found by a web search
+type WebResult struct {
+	Title     string
+	URL       string
+	Snippet   string
+	Published time.Time // When the page was published, or last crawled when Bing doesn't know
+}
+
+type bingSearchResponse struct {
+	WebPages struct {
+		Value []bingWebPage `json:"value"`
+	} `json:"webPages"`
+}
+
+type bingWebPage struct {
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	Snippet         string `json:"snippet"`
+	DatePublished   string `json:"datePublished"`
+	DateLastCrawled string `json:"dateLastCrawled"`
+}
+
+// NewWebSearch creates a Bing Web Search client; searches need the subscription key of a
+// Bing Search resource
+func NewWebSearch(apiKey string) *WebSearch {
+	return &WebSearch{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		endpoint: defaultBingEndpoint,
+		apiKey:   apiKey,
+	}
+}
+
+// WithEndpoint sends searches to another Bing endpoint, e.g. a custom subdomain
+func (w *WebSearch) WithEndpoint(endpoint string) *WebSearch {
+	if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+		w.endpoint = endpoint
+	}
+	return w
+}
+
+// IsEnabled reports whether a subscription key is configured
+func (w *WebSearch) IsEnabled() bool {
+	return w != nil && w.apiKey != ""
+}
+
+// Search returns up to 50 pages matching query that Bing saw within since, newest first as
+// far as Bing's freshness filter allows. query may use Bing operators such as site:.
+func (w *WebSearch) Search(ctx context.Context, query string, since time.Duration) ([]WebResult, error) {
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetHeader("Ocp-Apim-Subscription-Key", w.apiKey).
+		SetQueryParams(map[string]string{
+			"q":              query,
+			"count":          fmt.Sprintf("%d", bingMaxCount),
+			"freshness":      bingFreshness(since, time.Now()),
+			"responseFilter": "Webpages",
+			"safeSearch":     "Strict",
+			"textFormat":     "Raw",
+		}).
+		Get(w.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Bing Web Search: %w", err)
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("Bing Web Search returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var result bingSearchResponse
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse Bing Web Search response: %w", err)
+	}
+
+	cutoff := time.Now().Add(-since)
+	var results []WebResult
+	for _, page := range result.WebPages.Value {
+		if page.URL == "" || page.Name == "" {
+			continue
+		}
+
+		published, ok := parseBingDate(page.DatePublished)
+		if !ok {
+			published, ok = parseBingDate(page.DateLastCrawled)
+		}
+		if ok && published.Before(cutoff) {
+			continue
+		}
+		if !ok {
+			published = time.Now()
+		}
+
+		results = append(results, WebResult{
+			Title:     strings.TrimSpace(page.Name),
+			URL:       page.URL,
+			Snippet:   strings.TrimSpace(page.Snippet),
+			Published: published,
+		})
+	}
+	return results, nil
+}
+
+// bingFreshness maps a time window to Bing's freshness filter: Day, Week, Month or a date
+// range for longer windows
+func bingFreshness(since time.Duration, now time.Time) string {
+	switch {
+	case since <= 24*time.Hour:
+		return "Day"
+	case since <= 7*24*time.Hour:
+		return "Week"
+	case since <= 30*24*time.Hour:
+		return "Month"
+	}
+	return now.Add(-since).UTC().Format("2006-01-02") + ".." + now.UTC().Format("2006-01-02")
+}
+
+// parseBingDate parses Bing's dates, which have seven fractional digits and usually no zone
+func parseBingDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.9999999", "2006-01-02T15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// bingQuery renders a template in Bing syntax, e.g. ("AKS" OR "Azure Kubernetes Service")
+// (azure OR kubernetes) -rifle. Context is only added for searches of the whole web.
+func bingQuery(query models.KeywordQuery, requireContext bool) string {
+	names := quoteAll(queryNames(query), true)
+
+	var parts []string
+	if len(names) == 1 {
+		parts = append(parts, names[0])
+	} else {
+		parts = append(parts, "("+strings.Join(names, " OR ")+")")
+	}
+	if requireContext && len(query.Context) > 0 {
+		parts = append(parts, "("+strings.Join(quoteAll(query.Context, false), " OR ")+")")
+	}
+	for _, exclude := range quoteAll(query.Exclude, false) {
+		parts = append(parts, "-"+exclude)
+	}
+	return strings.Join(parts, " ")
+}
+
+// resultDomain returns the host of a result without "www.", e.g. "learn.microsoft.com"
+func resultDomain(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// webMentionID derives a stable mention ID from a result URL, ignoring its query and fragment
+// so the same page found by several searches is reported once
+func webMentionID(source, rawURL string) string {
+	normalized := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		normalized = strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.") + strings.TrimRight(parsed.EscapedPath(), "/")
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return source + "_" + hex.EncodeToString(sum[:8])
+}
+
+// WebSource finds mentions on the rest of the web, such as blogs, docs and news sites, through
+// Bing. Sites with their own source are left out, and each domain is capped so one busy site
+// can't crowd out the others.
+type WebSource struct {
+	search       *WebSearch
+	queries      *QueryBuilder
+	maxPerDomain int
+}
+
+// NewWebSource creates a web source searching through search
+func NewWebSource(search *WebSearch) *WebSource {
+	return &WebSource{
+		search:       search,
+		queries:      NewQueryBuilder(nil),
+		maxPerDomain: defaultMaxResultsPerDomain,
+	}
+}
+
+// WithQueries searches each keyword's aliases and context too, from the shared keyword templates
+func (w *WebSource) WithQueries(queries *QueryBuilder) *WebSource {
+	w.queries = queries
+	return w
+}
+
+// WithMaxResultsPerDomain caps how many mentions a run keeps from one domain
+func (w *WebSource) WithMaxResultsPerDomain(max int) *WebSource {
+	if max > 0 {
+		w.maxPerDomain = max
+	}
+	return w
+}
+
+func (w *WebSource) GetName() string {
+	return "web"
+}
+
+func (w *WebSource) IsEnabled() bool {
+	return w.search.IsEnabled()
+}
+
+func (w *WebSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !w.IsEnabled() {
+		logrus.Debug("Web source disabled - missing Bing Web Search key")
+		return nil, nil
+	}
+
+	exclusions := make([]string, 0, len(webExcludedSites))
+	for _, site := range webExcludedSites {
+		exclusions = append(exclusions, "-site:"+site)
+	}
+
+	seen := make(map[string]bool)
+	perDomain := make(map[string]int)
+	var allMentions []models.Mention
+
+	for _, query := range w.queries.Plan(keywords) {
+		results, err := w.search.Search(ctx, bingQuery(query, true)+" "+strings.Join(exclusions, " "), since)
+		if err != nil {
+			logrus.Errorf("Failed to search the web for '%s': %v", query.Keyword, err)
+			continue
+		}
+
+		for _, result := range results {
+			domain := resultDomain(result.URL)
+			if domain == "" || perDomain[domain] >= w.maxPerDomain {
+				continue
+			}
+			if !w.queries.Matches(query, result.Title+" "+result.Snippet) {
+				continue
+			}
+
+			mention := models.Mention{
+				ID:        webMentionID("web", result.URL),
+				Source:    "web",
+				Platform:  domain,
+				Title:     result.Title,
+				Content:   result.Snippet,
+				Author:    domain,
+				URL:       result.URL,
+				CreatedAt: result.Published,
+				Keywords:  []string{query.Keyword},
+			}
+			if seen[mention.ID] {
+				continue
+			}
+			seen[mention.ID] = true
+			perDomain[domain]++
+			allMentions = append(allMentions, mention)
+		}
+	}
+
+	return allMentions, nil
+}
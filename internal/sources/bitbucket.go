@@ -0,0 +1,188 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// BitbucketSource searches issue discussions in Bitbucket Cloud repositories. Bitbucket has no
+// cross-repository search API, so the repositories to watch must be configured.
+type BitbucketSource struct {
+	client       *resty.Client
+	token        string
+	repositories []string // "workspace/repo"
+	queries      *QueryBuilder
+}
+
+type bitbucketIssuesResponse struct {
+	Values []bitbucketIssue `json:"values"`
+	Next   string           `json:"next"`
+}
+
+type bitbucketIssue struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Reporter struct {
+		DisplayName string `json:"display_name"`
+		Nickname    string `json:"nickname"`
+	} `json:"reporter"`
+	CreatedOn time.Time `json:"created_on"`
+	Votes     int       `json:"votes"`
+	Links     struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+// bitbucketMaxPages bounds how many result pages are read per repository
+const bitbucketMaxPages = 3
+
+// NewBitbucketSource creates a new Bitbucket source; the token is optional for public repositories
+func NewBitbucketSource(token string) *BitbucketSource {
+	return &BitbucketSource{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		token:   token,
+		queries: NewQueryBuilder(nil),
+	}
+}
+
+// WithQueries searches and matches issues with the shared keyword templates, including their aliases
+func (b *BitbucketSource) WithQueries(queries *QueryBuilder) *BitbucketSource {
+	b.queries = queries
+	return b
+}
+
+// WithRepositories sets the "workspace/repo" repositories whose issues are searched
+func (b *BitbucketSource) WithRepositories(repositories []string) *BitbucketSource {
+	b.repositories = nil
+	for _, repository := range cleanList(repositories) {
+		if strings.Count(repository, "/") != 1 {
+			logrus.Warnf("Ignoring Bitbucket repository %q, expected workspace/repo", repository)
+			continue
+		}
+		b.repositories = append(b.repositories, repository)
+	}
+	return b
+}
+
+func (b *BitbucketSource) GetName() string {
+	return "bitbucket"
+}
+
+func (b *BitbucketSource) IsEnabled() bool {
+	return len(b.repositories) > 0
+}
+
+func (b *BitbucketSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !b.IsEnabled() {
+		logrus.Debug("Bitbucket source disabled - no repositories configured")
+		return nil, nil
+	}
+
+	query := b.buildQuery(keywords, time.Now().Add(-since))
+	if query == "" {
+		return nil, nil
+	}
+
+	var allMentions []models.Mention
+	for _, repository := range b.repositories {
+		mentions, err := b.searchRepository(ctx, repository, query, keywords)
+		if err != nil {
+			logrus.Errorf("Failed to search Bitbucket issues in %s: %v", repository, err)
+			continue
+		}
+		allMentions = append(allMentions, mentions...)
+	}
+
+	return allMentions, nil
+}
+
+// buildQuery builds a BBQL filter matching any keyword or alias in the issue title or body
+func (b *BitbucketSource) buildQuery(keywords []string, cutoff time.Time) string {
+	var terms []string
+	for _, query := range b.queries.Plan(cleanList(keywords)) {
+		for _, name := range queryNames(query) {
+			quoted := `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+			terms = append(terms, "title ~ "+quoted, "content.raw ~ "+quoted)
+		}
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s) AND created_on >= %s", strings.Join(terms, " OR "), cutoff.UTC().Format(time.RFC3339))
+}
+
+func (b *BitbucketSource) searchRepository(ctx context.Context, repository, query string, keywords []string) ([]models.Mention, error) {
+	var mentions []models.Mention
+
+	next := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/issues", repository)
+	params := map[string]string{"q": query, "sort": "-created_on", "pagelen": "50"}
+
+	for page := 0; page < bitbucketMaxPages && next != ""; page++ {
+		req := b.client.R().SetContext(ctx).SetQueryParams(params)
+		if b.token != "" {
+			req.SetAuthToken(b.token)
+		}
+
+		resp, err := req.Get(next)
+		if err != nil {
+			return mentions, err
+		}
+
+		if resp.StatusCode() == 404 {
+			// Repositories without the issue tracker enabled return 404
+			return mentions, fmt.Errorf("repository or issue tracker not found")
+		}
+		if resp.StatusCode() != 200 {
+			return mentions, fmt.Errorf("Bitbucket API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+		}
+
+		var issuesResp bitbucketIssuesResponse
+		if err := json.Unmarshal(resp.Body(), &issuesResp); err != nil {
+			return mentions, fmt.Errorf("failed to parse Bitbucket response: %w", err)
+		}
+
+		for _, issue := range issuesResp.Values {
+			mentions = append(mentions, b.convertIssue(repository, issue, keywords))
+		}
+
+		// The next link already carries the query
+		next, params = issuesResp.Next, nil
+	}
+
+	return mentions, nil
+}
+
+func (b *BitbucketSource) convertIssue(repository string, issue bitbucketIssue, keywords []string) models.Mention {
+	author := issue.Reporter.Nickname
+	if author == "" {
+		author = issue.Reporter.DisplayName
+	}
+
+	return models.Mention{
+		ID:        fmt.Sprintf("bitbucket_%s_%d", strings.ReplaceAll(repository, "/", "_"), issue.ID),
+		Source:    "bitbucket",
+		Platform:  "Bitbucket",
+		Title:     issue.Title,
+		Content:   issue.Content.Raw,
+		Author:    author,
+		Channel:   repository,
+		URL:       issue.Links.HTML.Href,
+		CreatedAt: issue.CreatedOn,
+		Score:     issue.Votes,
+		Keywords:  b.queries.Match(keywords, issue.Title+" "+issue.Content.Raw),
+	}
+}
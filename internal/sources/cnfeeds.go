@@ -0,0 +1,246 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// cnFeedMaxContent caps the article text stored per mention
+const cnFeedMaxContent = 4000
+
+// cnFeedPlatforms names the Chinese developer communities by the hosts their posts link to
+var cnFeedPlatforms = map[string]string{
+	"zhihu.com":          "Zhihu",
+	"zhuanlan.zhihu.com": "Zhihu",
+	"juejin.cn":          "Juejin",
+	"csdn.net":           "CSDN",
+	"blog.csdn.net":      "CSDN",
+	"cnblogs.com":        "cnblogs",
+	"segmentfault.com":   "SegmentFault",
+	"oschina.net":        "OSChina",
+}
+
+// CNFeedsSource follows RSS and Atom feeds of Chinese developer communities, such as CSDN
+// blog feeds or RSSHub routes for Zhihu topics and Juejin tags, none of which offer a search
+// API. Posts are matched against the keywords and their aliases, which can include Chinese
+// terms such as "Azure Kubernetes 服务".
+type CNFeedsSource struct {
+	client    *resty.Client
+	feeds     []string
+	queries   *QueryBuilder
+	sanitizer *sanitize.Sanitizer
+}
+
+// cnFeed reads RSS 2.0 items and Atom entries alike
+type cnFeed struct {
+	Items   []cnFeedItem `xml:"channel>item"`
+	Entries []cnFeedItem `xml:"http://www.w3.org/2005/Atom entry"`
+}
+
+// cnFeedItem holds the fields of an RSS item and an Atom entry. Elements both formats name
+// alike, link and author, carry their RSS text and their Atom attributes or children.
+type cnFeedItem struct {
+	Title string `xml:"title"`
+	Links []struct {
+		Text string `xml:",chardata"` // RSS
+		Href string `xml:"href,attr"` // Atom
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Author struct {
+		Text string `xml:",chardata"` // RSS
+		Name string `xml:"name"`      // Atom
+	} `xml:"author"`
+
+	// RSS
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string `xml:"pubDate"`
+
+	// Atom
+	ID        string `xml:"id"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+// NewCNFeedsSource creates a source following the given feed URLs
+func NewCNFeedsSource(feeds []string) *CNFeedsSource {
+	return &CNFeedsSource{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		feeds:     cleanList(feeds),
+		queries:   NewQueryBuilder(nil),
+		sanitizer: sanitize.New(),
+	}
+}
+
+// WithQueries matches posts against the shared keyword templates, including their aliases
+func (c *CNFeedsSource) WithQueries(queries *QueryBuilder) *CNFeedsSource {
+	c.queries = queries
+	return c
+}
+
+// WithSanitizer sets how post HTML is turned into mention content
+func (c *CNFeedsSource) WithSanitizer(sanitizer *sanitize.Sanitizer) *CNFeedsSource {
+	c.sanitizer = sanitizer
+	return c
+}
+
+// WithTransport sends the source's requests through transport, e.g. a response cache
+func (c *CNFeedsSource) WithTransport(transport http.RoundTripper) *CNFeedsSource {
+	c.client.SetTransport(transport)
+	return c
+}
+
+func (c *CNFeedsSource) GetName() string {
+	return "cnfeeds"
+}
+
+func (c *CNFeedsSource) IsEnabled() bool {
+	return len(c.feeds) > 0
+}
+
+func (c *CNFeedsSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !c.IsEnabled() {
+		logrus.Debug("Chinese feeds source disabled - no feeds configured")
+		return nil, nil
+	}
+
+	cutoff := time.Now().Add(-since)
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, feedURL := range c.feeds {
+		items, err := c.fetchFeed(ctx, feedURL)
+		if err != nil {
+			logrus.Warnf("Failed to fetch feed %s: %v", feedURL, err)
+			continue
+		}
+
+		for _, item := range items {
+			mention := c.convertItem(feedURL, item, cutoff)
+			if mention == nil || seen[mention.ID] {
+				continue
+			}
+			mention.Keywords = c.queries.Match(keywords, mention.Title+" "+mention.Content)
+			if len(mention.Keywords) == 0 {
+				continue
+			}
+			seen[mention.ID] = true
+			allMentions = append(allMentions, *mention)
+		}
+	}
+
+	logrus.Infof("Chinese feeds: Total mentions found: %d", len(allMentions))
+	return allMentions, nil
+}
+
+func (c *CNFeedsSource) fetchFeed(ctx context.Context, feedURL string) ([]cnFeedItem, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode())
+	}
+
+	var feed cnFeed
+	if err := xml.Unmarshal(resp.Body(), &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	return append(feed.Items, feed.Entries...), nil
+}
+
+// convertItem converts a feed item into a mention without keywords, returning nil for items
+// that are incomplete or older than cutoff
+func (c *CNFeedsSource) convertItem(feedURL string, item cnFeedItem, cutoff time.Time) *models.Mention {
+	var link string
+	for _, itemLink := range item.Links {
+		if link == "" && (itemLink.Rel == "" || itemLink.Rel == "alternate") {
+			link = firstNonEmpty(itemLink.Text, itemLink.Href)
+		}
+	}
+	title := strings.TrimSpace(item.Title)
+	if title == "" || link == "" {
+		return nil
+	}
+
+	published, ok := parseFeedDate(firstNonEmpty(item.PubDate, item.Published, item.Updated))
+	if !ok || published.Before(cutoff) {
+		return nil
+	}
+
+	text := c.sanitizer.Text(firstNonEmpty(item.Encoded, item.Content, item.Description, item.Summary))
+	if runes := []rune(text); len(runes) > cnFeedMaxContent {
+		text = string(runes[:cnFeedMaxContent])
+	}
+
+	author := firstNonEmpty(item.Creator, item.Author.Text, item.Author.Name)
+	platform := cnFeedPlatform(link, feedURL)
+	if author == "" {
+		author = platform + " User"
+	}
+
+	guid := firstNonEmpty(item.GUID, item.ID, link)
+	sum := sha1.Sum([]byte(guid))
+
+	return &models.Mention{
+		ID:        "cnfeeds_" + hex.EncodeToString(sum[:])[:16],
+		Source:    "cnfeeds",
+		Platform:  platform,
+		Title:     title,
+		Content:   text,
+		Author:    author,
+		URL:       link,
+		CreatedAt: published,
+	}
+}
+
+// cnFeedPlatform names the community a post is from by its link, falling back to the feed's
+// host for posts linked through a proxy
+func cnFeedPlatform(link, feedURL string) string {
+	for _, raw := range []string{link, feedURL} {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+		if platform, ok := cnFeedPlatforms[host]; ok {
+			return platform
+		}
+		if parts := strings.Split(host, "."); len(parts) > 2 {
+			if platform, ok := cnFeedPlatforms[strings.Join(parts[len(parts)-2:], ".")]; ok {
+				return platform
+			}
+		}
+	}
+	return "Chinese Community"
+}
+
+// firstNonEmpty returns the first value that isn't blank, trimmed
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
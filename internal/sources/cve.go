@@ -0,0 +1,209 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// CVESource polls the NVD CVE API for Kubernetes/AKS-related vulnerabilities
+type CVESource struct {
+	client *resty.Client
+	apiKey string
+	terms  []string
+}
+
+// defaultCVETerms are the NVD keyword searches used by default. NVD matches keywords
+// against CVE descriptions, so product names work better than short bot keywords like "AKS".
+var defaultCVETerms = []string{"kubernetes", "Azure Kubernetes Service"}
+
+// nvdTimeLayout is the timestamp format used by the NVD API
+const nvdTimeLayout = "2006-01-02T15:04:05.000"
+
+// nvdMaxRange is the longest publication window the NVD API accepts in one request
+const nvdMaxRange = 120 * 24 * time.Hour
+
+// CVEPattern matches CVE identifiers such as CVE-2024-12345
+var CVEPattern = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+
+type nvdResponse struct {
+	Vulnerabilities []struct {
+		CVE nvdCVE `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+type nvdCVE struct {
+	ID           string           `json:"id"`
+	Published    string           `json:"published"`
+	Descriptions []nvdDescription `json:"descriptions"`
+	Metrics      struct {
+		CVSSMetricV31 []nvdCVSSMetric `json:"cvssMetricV31"`
+		CVSSMetricV30 []nvdCVSSMetric `json:"cvssMetricV30"`
+	} `json:"metrics"`
+}
+
+type nvdDescription struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+type nvdCVSSMetric struct {
+	CVSSData struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+}
+
+// NewCVESource creates a new NVD CVE source; the API key is optional but raises rate limits
+func NewCVESource(apiKey string) *CVESource {
+	return &CVESource{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		apiKey: apiKey,
+		terms:  defaultCVETerms,
+	}
+}
+
+// WithTerms overrides the NVD keyword searches; an empty list keeps the defaults
+func (c *CVESource) WithTerms(terms []string) *CVESource {
+	if cleaned := cleanList(terms); len(cleaned) > 0 {
+		c.terms = cleaned
+	}
+	return c
+}
+
+func (c *CVESource) GetName() string {
+	return "cve"
+}
+
+func (c *CVESource) IsEnabled() bool {
+	return true // NVD allows unauthenticated requests at a lower rate limit
+}
+
+// FetchMentions searches NVD for the configured product terms. The bot keywords are not
+// used since advisories rarely name AKS directly.
+func (c *CVESource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if since > nvdMaxRange {
+		since = nvdMaxRange
+	}
+
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, term := range c.terms {
+		mentions, err := c.searchTerm(ctx, term, since)
+		if err != nil {
+			logrus.Errorf("Failed to search NVD for '%s': %v", term, err)
+			continue
+		}
+
+		for _, mention := range mentions {
+			if !seen[mention.ID] {
+				seen[mention.ID] = true
+				allMentions = append(allMentions, mention)
+			}
+		}
+	}
+
+	return allMentions, nil
+}
+
+func (c *CVESource) searchTerm(ctx context.Context, term string, since time.Duration) ([]models.Mention, error) {
+	end := time.Now().UTC()
+	start := end.Add(-since)
+
+	req := c.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"keywordSearch": term,
+			"pubStartDate":  start.Format(nvdTimeLayout),
+			"pubEndDate":    end.Format(nvdTimeLayout),
+		})
+	if c.apiKey != "" {
+		req.SetHeader("apiKey", c.apiKey)
+	}
+
+	resp, err := req.Get("https://services.nvd.nist.gov/rest/json/cves/2.0")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("NVD API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var nvdResp nvdResponse
+	if err := json.Unmarshal(resp.Body(), &nvdResp); err != nil {
+		return nil, fmt.Errorf("failed to parse NVD response: %w", err)
+	}
+
+	var mentions []models.Mention
+	for _, vuln := range nvdResp.Vulnerabilities {
+		mentions = append(mentions, c.convertCVE(vuln.CVE, term))
+	}
+
+	return mentions, nil
+}
+
+func (c *CVESource) convertCVE(cve nvdCVE, term string) models.Mention {
+	advisory := NewAdvisory(cve.ID)
+
+	metrics := cve.Metrics.CVSSMetricV31
+	if len(metrics) == 0 {
+		metrics = cve.Metrics.CVSSMetricV30
+	}
+	if len(metrics) > 0 {
+		advisory.Score = metrics[0].CVSSData.BaseScore
+		advisory.Severity = metrics[0].CVSSData.BaseSeverity
+	}
+
+	var description string
+	for _, d := range cve.Descriptions {
+		if d.Lang == "en" {
+			description = d.Value
+			break
+		}
+	}
+	advisory.Summary = description
+
+	title := cve.ID
+	if advisory.Severity != "" {
+		title = fmt.Sprintf("%s (%s %.1f)", cve.ID, advisory.Severity, advisory.Score)
+	}
+
+	createdAt, err := time.Parse(nvdTimeLayout, cve.Published)
+	if err != nil {
+		createdAt = time.Now()
+	}
+
+	return models.Mention{
+		ID:         fmt.Sprintf("cve_%s", cve.ID),
+		Source:     "cve",
+		Platform:   "NVD",
+		Title:      title,
+		Content:    description,
+		Author:     "NVD",
+		URL:        advisory.URL,
+		CreatedAt:  createdAt,
+		Score:      int(advisory.Score * 10),
+		Keywords:   []string{term},
+		Advisories: []models.Advisory{advisory},
+	}
+}
+
+// NewAdvisory returns an advisory linking to the authoritative NVD entry for a CVE ID
+func NewAdvisory(id string) models.Advisory {
+	id = strings.ToUpper(id)
+	return models.Advisory{
+		ID:  id,
+		URL: "https://nvd.nist.gov/vuln/detail/" + id,
+	}
+}
@@ -0,0 +1,261 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// GitHubSource finds new public repositories that reference the configured projects in their
+// name, description, README or code: sample repos, Terraform modules and blog companion code
+type GitHubSource struct {
+	client  *resty.Client
+	baseURL string
+	token   string
+	terms   []string
+}
+
+// defaultGitHubTerms are searched by default. Discussion sources already cover AKS itself;
+// repositories are where adoption of the newer projects shows first.
+var defaultGitHubTerms = []string{"KAITO", "KubeFleet"}
+
+// githubCodeQualifiers narrow code search to the files that show a project being deployed
+var githubCodeQualifiers = []string{"extension:tf", "extension:bicep", "extension:yaml"}
+
+// githubMaxRepoLookups bounds how many repositories found by code search are looked up per
+// run to learn when they were created
+const githubMaxRepoLookups = 20
+
+const defaultGitHubURL = "https://api.github.com"
+
+type githubOwner struct {
+	Login string `json:"login"`
+}
+
+type githubRepository struct {
+	ID          int64       `json:"id"`
+	FullName    string      `json:"full_name"`
+	HTMLURL     string      `json:"html_url"`
+	Description string      `json:"description"`
+	Language    string      `json:"language"`
+	Topics      []string    `json:"topics"`
+	Stars       int         `json:"stargazers_count"`
+	Fork        bool        `json:"fork"`
+	CreatedAt   time.Time   `json:"created_at"`
+	Owner       githubOwner `json:"owner"`
+}
+
+type githubRepositorySearch struct {
+	Items []githubRepository `json:"items"`
+}
+
+type githubCodeSearch struct {
+	Items []struct {
+		Path       string           `json:"path"`
+		Repository githubRepository `json:"repository"`
+	} `json:"items"`
+}
+
+// NewGitHubSource creates a new GitHub source. Repository search works without a token;
+// code search requires one.
+func NewGitHubSource(token string) *GitHubSource {
+	return &GitHubSource{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0").
+			SetHeader("Accept", "application/vnd.github+json"),
+		baseURL: defaultGitHubURL,
+		token:   token,
+		terms:   defaultGitHubTerms,
+	}
+}
+
+// WithTerms overrides the projects searched for; an empty list keeps the defaults
+func (g *GitHubSource) WithTerms(terms []string) *GitHubSource {
+	if cleaned := cleanList(terms); len(cleaned) > 0 {
+		g.terms = cleaned
+	}
+	return g
+}
+
+func (g *GitHubSource) GetName() string {
+	return "github"
+}
+
+func (g *GitHubSource) IsEnabled() bool {
+	return true // Repository search allows unauthenticated requests at a lower rate limit
+}
+
+// FetchMentions searches GitHub for repositories created in the window that reference the
+// configured terms. Like the CVE source it searches its own terms rather than the bot
+// keywords, each mention carrying the term it matched as its keyword.
+func (g *GitHubSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	cutoff := time.Now().Add(-since)
+	seen := make(map[int64]bool)
+	var allMentions []models.Mention
+	lookups := 0
+
+	for _, term := range g.terms {
+		repositories, err := g.searchRepositories(ctx, term, cutoff)
+		if err != nil {
+			logrus.Errorf("Failed to search GitHub repositories for '%s': %v", term, err)
+		}
+		for _, repository := range repositories {
+			if !seen[repository.ID] {
+				seen[repository.ID] = true
+				allMentions = append(allMentions, g.convertRepository(repository, term, nil))
+			}
+		}
+
+		if g.token == "" {
+			continue
+		}
+		hits, names, err := g.searchCode(ctx, term)
+		if err != nil {
+			logrus.Errorf("Failed to search GitHub code for '%s': %v", term, err)
+		}
+		for _, name := range names {
+			if lookups >= githubMaxRepoLookups {
+				logrus.Debugf("GitHub repository lookups capped at %d", githubMaxRepoLookups)
+				break
+			}
+			if seen[hits[name].repository.ID] {
+				continue
+			}
+			// Code search doesn't report when a repository was created
+			lookups++
+			repository, err := g.getRepository(ctx, name)
+			if err != nil {
+				logrus.Warnf("Failed to look up GitHub repository %s: %v", name, err)
+				continue
+			}
+			seen[repository.ID] = true
+			if repository.Fork || repository.CreatedAt.Before(cutoff) {
+				continue
+			}
+			allMentions = append(allMentions, g.convertRepository(*repository, term, hits[name].files))
+		}
+	}
+
+	return allMentions, nil
+}
+
+// searchRepositories finds repositories created since the cutoff that name the term in their
+// name, description or README
+func (g *GitHubSource) searchRepositories(ctx context.Context, term string, cutoff time.Time) ([]githubRepository, error) {
+	query := fmt.Sprintf("%q in:name,description,readme created:>=%s", term, cutoff.UTC().Format(time.RFC3339))
+
+	var result githubRepositorySearch
+	if err := g.get(ctx, "/search/repositories", map[string]string{
+		"q":        query,
+		"sort":     "updated",
+		"order":    "desc",
+		"per_page": "50",
+	}, &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+type githubCodeHits struct {
+	repository githubRepository
+	files      []string
+}
+
+// searchCode finds Terraform, Bicep and YAML files referencing the term, grouped by
+// repository; names lists the repositories in the order they were first found
+func (g *GitHubSource) searchCode(ctx context.Context, term string) (map[string]*githubCodeHits, []string, error) {
+	hits := make(map[string]*githubCodeHits)
+	var names []string
+
+	for _, qualifier := range githubCodeQualifiers {
+		var result githubCodeSearch
+		if err := g.get(ctx, "/search/code", map[string]string{
+			"q":        fmt.Sprintf("%q %s", term, qualifier),
+			"per_page": "50",
+		}, &result); err != nil {
+			return hits, names, err
+		}
+
+		for _, item := range result.Items {
+			name := item.Repository.FullName
+			if hits[name] == nil {
+				hits[name] = &githubCodeHits{repository: item.Repository}
+				names = append(names, name)
+			}
+			hits[name].files = append(hits[name].files, item.Path)
+		}
+	}
+	return hits, names, nil
+}
+
+func (g *GitHubSource) getRepository(ctx context.Context, fullName string) (*githubRepository, error) {
+	var repository githubRepository
+	if err := g.get(ctx, "/repos/"+fullName, nil, &repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+func (g *GitHubSource) get(ctx context.Context, path string, params map[string]string, result interface{}) error {
+	req := g.client.R().
+		SetContext(ctx).
+		SetQueryParams(params)
+	if g.token != "" {
+		req.SetAuthToken(g.token)
+	}
+
+	resp, err := req.Get(g.baseURL + path)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+	return nil
+}
+
+// convertRepository turns a repository into a mention, listing the files code search found
+// the term in
+func (g *GitHubSource) convertRepository(repository githubRepository, term string, files []string) models.Mention {
+	var content []string
+	if repository.Description != "" {
+		content = append(content, repository.Description)
+	}
+	if len(repository.Topics) > 0 {
+		content = append(content, "Topics: "+strings.Join(repository.Topics, ", "))
+	}
+	if len(files) > 0 {
+		files = append([]string(nil), files...)
+		sort.Strings(files)
+		content = append(content, fmt.Sprintf("References %s in %s", term, strings.Join(files, ", ")))
+	}
+	if repository.Language != "" {
+		content = append(content, "Language: "+repository.Language)
+	}
+
+	return models.Mention{
+		ID:        fmt.Sprintf("github_repo_%d", repository.ID),
+		Source:    "github",
+		Platform:  "GitHub",
+		Title:     repository.FullName,
+		Content:   strings.Join(content, "\n"),
+		Author:    repository.Owner.Login,
+		URL:       repository.HTMLURL,
+		CreatedAt: repository.CreatedAt,
+		Score:     repository.Stars,
+		Keywords:  []string{term},
+	}
+}
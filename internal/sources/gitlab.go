@@ -0,0 +1,231 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// GitLabSource searches public GitLab issues and snippets
+type GitLabSource struct {
+	client  *resty.Client
+	baseURL string
+	token   string
+	queries *QueryBuilder
+}
+
+// defaultGitLabURL is searched unless a self-managed instance is configured
+const defaultGitLabURL = "https://gitlab.com"
+
+// gitLabMaxPages bounds how many result pages are read per keyword and scope
+const gitLabMaxPages = 3
+
+type gitLabAuthor struct {
+	Username string `json:"username"`
+}
+
+type gitLabIssue struct {
+	ID             int          `json:"id"`
+	Title          string       `json:"title"`
+	Description    string       `json:"description"`
+	CreatedAt      time.Time    `json:"created_at"`
+	WebURL         string       `json:"web_url"`
+	Author         gitLabAuthor `json:"author"`
+	Upvotes        int          `json:"upvotes"`
+	UserNotesCount int          `json:"user_notes_count"`
+}
+
+type gitLabSnippet struct {
+	ID          int          `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	FileName    string       `json:"file_name"`
+	CreatedAt   time.Time    `json:"created_at"`
+	WebURL      string       `json:"web_url"`
+	Author      gitLabAuthor `json:"author"`
+}
+
+// NewGitLabSource creates a new GitLab source. GitLab's search API requires a personal access
+// token, even for public content.
+func NewGitLabSource(token string) *GitLabSource {
+	return &GitLabSource{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		baseURL: defaultGitLabURL,
+		token:   token,
+		queries: NewQueryBuilder(nil),
+	}
+}
+
+// WithQueries searches each keyword's aliases too, from the shared keyword templates
+func (g *GitLabSource) WithQueries(queries *QueryBuilder) *GitLabSource {
+	g.queries = queries
+	return g
+}
+
+// WithBaseURL searches a self-managed GitLab instance instead of GitLab.com
+func (g *GitLabSource) WithBaseURL(baseURL string) *GitLabSource {
+	if baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/"); baseURL != "" {
+		g.baseURL = baseURL
+	}
+	return g
+}
+
+func (g *GitLabSource) GetName() string {
+	return "gitlab"
+}
+
+func (g *GitLabSource) IsEnabled() bool {
+	return g.token != ""
+}
+
+func (g *GitLabSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !g.IsEnabled() {
+		logrus.Debug("GitLab source disabled - missing token")
+		return nil, nil
+	}
+
+	cutoff := time.Now().Add(-since)
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, query := range g.queries.Plan(keywords) {
+		for _, name := range queryNames(query) {
+			var mentions []models.Mention
+
+			issues, err := g.searchIssues(ctx, name, cutoff)
+			if err != nil {
+				logrus.Errorf("Failed to search GitLab issues for '%s': %v", name, err)
+			}
+			mentions = append(mentions, issues...)
+
+			snippets, err := g.searchSnippets(ctx, name, cutoff)
+			if err != nil {
+				logrus.Errorf("Failed to search GitLab snippets for '%s': %v", name, err)
+			}
+			mentions = append(mentions, snippets...)
+
+			for _, mention := range mentions {
+				if !seen[mention.ID] {
+					seen[mention.ID] = true
+					mention.Keywords = []string{query.Keyword}
+					allMentions = append(allMentions, mention)
+				}
+			}
+		}
+	}
+
+	return allMentions, nil
+}
+
+func (g *GitLabSource) searchIssues(ctx context.Context, keyword string, cutoff time.Time) ([]models.Mention, error) {
+	var mentions []models.Mention
+	for page := 1; page <= gitLabMaxPages; page++ {
+		var issues []gitLabIssue
+		if err := g.search(ctx, "issues", keyword, page, &issues); err != nil {
+			return mentions, err
+		}
+
+		older := false
+		for _, issue := range issues {
+			if issue.CreatedAt.Before(cutoff) {
+				older = true
+				continue
+			}
+			mentions = append(mentions, g.convertIssue(issue, keyword))
+		}
+
+		// Results are newest first, so an older issue means the window is exhausted
+		if older || len(issues) < 100 {
+			break
+		}
+	}
+	return mentions, nil
+}
+
+func (g *GitLabSource) searchSnippets(ctx context.Context, keyword string, cutoff time.Time) ([]models.Mention, error) {
+	var snippets []gitLabSnippet
+	if err := g.search(ctx, "snippet_titles", keyword, 1, &snippets); err != nil {
+		return nil, err
+	}
+
+	var mentions []models.Mention
+	for _, snippet := range snippets {
+		if snippet.CreatedAt.Before(cutoff) {
+			continue
+		}
+		mentions = append(mentions, g.convertSnippet(snippet, keyword))
+	}
+	return mentions, nil
+}
+
+// search calls the global search API for one page of results in the given scope
+func (g *GitLabSource) search(ctx context.Context, scope, keyword string, page int, result interface{}) error {
+	resp, err := g.client.R().
+		SetContext(ctx).
+		SetHeader("PRIVATE-TOKEN", g.token).
+		SetQueryParams(map[string]string{
+			"scope":    scope,
+			"search":   keyword,
+			"order_by": "created_at",
+			"sort":     "desc",
+			"per_page": "100",
+			"page":     strconv.Itoa(page),
+		}).
+		Get(g.baseURL + "/api/v4/search")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("GitLab API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	return nil
+}
+
+func (g *GitLabSource) convertIssue(issue gitLabIssue, keyword string) models.Mention {
+	return models.Mention{
+		ID:           fmt.Sprintf("gitlab_issue_%d", issue.ID),
+		Source:       "gitlab",
+		Platform:     "GitLab",
+		Title:        issue.Title,
+		Content:      issue.Description,
+		Author:       issue.Author.Username,
+		URL:          issue.WebURL,
+		CreatedAt:    issue.CreatedAt,
+		Score:        issue.Upvotes,
+		CommentCount: issue.UserNotesCount,
+		Keywords:     []string{keyword},
+	}
+}
+
+func (g *GitLabSource) convertSnippet(snippet gitLabSnippet, keyword string) models.Mention {
+	content := snippet.Description
+	if content == "" {
+		content = snippet.FileName
+	}
+
+	return models.Mention{
+		ID:        fmt.Sprintf("gitlab_snippet_%d", snippet.ID),
+		Source:    "gitlab",
+		Platform:  "GitLab",
+		Title:     snippet.Title,
+		Content:   content,
+		Author:    snippet.Author.Username,
+		URL:       snippet.WebURL,
+		CreatedAt: snippet.CreatedAt,
+		Keywords:  []string{keyword},
+	}
+}
@@ -4,17 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
 )
 
 // HackerNewsSource implements Hacker News API source
 type HackerNewsSource struct {
-	client *resty.Client
+	client    *resty.Client
+	itemLimit int
+	queries   *QueryBuilder
+	sanitizer *sanitize.Sanitizer
 }
 
 type hackerNewsItem struct {
@@ -27,15 +33,54 @@ type hackerNewsItem struct {
 	URL         string `json:"url"`
 	Score       int    `json:"score"`
 	Descendants int    `json:"descendants"`
+	Kids        []int  `json:"kids"`
+	Deleted     bool   `json:"deleted"`
+	Dead        bool   `json:"dead"`
 }
 
+const (
+	// Bounds for walking a story's comment tree
+	hackerNewsMaxComments     = 100
+	hackerNewsMaxCommentDepth = 3
+	hackerNewsTopComments     = 5
+)
+
 // NewHackerNewsSource creates a new Hacker News source
 func NewHackerNewsSource() *HackerNewsSource {
 	return &HackerNewsSource{
 		client: resty.New().
 			SetTimeout(30 * time.Second).
 			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		itemLimit: 500,
+		queries:   NewQueryBuilder(nil),
+		sanitizer: sanitize.New(),
+	}
+}
+
+// WithItemLimit overrides how many recent items are scanned per run
+func (h *HackerNewsSource) WithItemLimit(limit int) *HackerNewsSource {
+	if limit > 0 {
+		h.itemLimit = limit
 	}
+	return h
+}
+
+// WithSanitizer sets how HN's HTML-formatted text is turned into mention content
+func (h *HackerNewsSource) WithSanitizer(sanitizer *sanitize.Sanitizer) *HackerNewsSource {
+	h.sanitizer = sanitizer
+	return h
+}
+
+// WithQueries matches items against the shared keyword templates, including their aliases
+func (h *HackerNewsSource) WithQueries(queries *QueryBuilder) *HackerNewsSource {
+	h.queries = queries
+	return h
+}
+
+// WithTransport sends the source's requests through transport, e.g. a response cache
+func (h *HackerNewsSource) WithTransport(transport http.RoundTripper) *HackerNewsSource {
+	h.client.SetTransport(transport)
+	return h
 }
 
 func (h *HackerNewsSource) GetName() string {
@@ -57,7 +102,7 @@ func (h *HackerNewsSource) FetchMentions(ctx context.Context, keywords []string,
 	cutoff := time.Now().Add(-since)
 
 	// Limit to avoid too many API calls
-	limit := 500
+	limit := h.itemLimit
 	if len(itemIDs) > limit {
 		itemIDs = itemIDs[:limit]
 	}
@@ -84,15 +129,8 @@ func (h *HackerNewsSource) FetchMentions(ctx context.Context, keywords []string,
 			continue
 		}
 
-		// Check if the item contains any of our keywords
-		content := strings.ToLower(item.Title + " " + item.Text)
-		var matchedKeywords []string
-
-		for _, keyword := range keywords {
-			if strings.Contains(content, strings.ToLower(keyword)) {
-				matchedKeywords = append(matchedKeywords, keyword)
-			}
-		}
+		// Check if the item mentions any of our keywords or their aliases
+		matchedKeywords := h.queries.Match(keywords, item.Title+" "+h.sanitizer.Text(item.Text))
 
 		if len(matchedKeywords) == 0 {
 			continue
@@ -103,13 +141,14 @@ func (h *HackerNewsSource) FetchMentions(ctx context.Context, keywords []string,
 			Source:       "hackernews",
 			Platform:     "Hacker News",
 			Title:        item.Title,
-			Content:      item.Text,
+			Content:      h.sanitizer.Text(item.Text),
 			Author:       item.By,
 			URL:          fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID),
 			CreatedAt:    createdAt,
 			Score:        item.Score,
 			CommentCount: item.Descendants,
 			Keywords:     matchedKeywords,
+			PostType:     hackerNewsPostType(item),
 		}
 
 		// Use external URL if available and it's a story
@@ -117,12 +156,105 @@ func (h *HackerNewsSource) FetchMentions(ctx context.Context, keywords []string,
 			mention.URL = item.URL
 		}
 
+		// The story title rarely conveys the actual sentiment, so scan the discussion too
+		if len(item.Kids) > 0 {
+			mention.TopComments = h.fetchMatchingComments(ctx, item, mention.ID, keywords)
+		}
+
 		allMentions = append(allMentions, mention)
 	}
 
 	return allMentions, nil
 }
 
+// fetchMatchingComments walks a story's comment tree breadth-first and returns the
+// comments that mention any of the keywords, bounded in both depth and item count
+func (h *HackerNewsSource) fetchMatchingComments(ctx context.Context, story *hackerNewsItem, mentionID string, keywords []string) []models.Comment {
+	type queued struct {
+		id    int
+		depth int
+	}
+
+	var comments []models.Comment
+	queue := make([]queued, 0, len(story.Kids))
+	for _, kid := range story.Kids {
+		queue = append(queue, queued{id: kid, depth: 1})
+	}
+
+	fetched := 0
+	for len(queue) > 0 && fetched < hackerNewsMaxComments && len(comments) < hackerNewsTopComments {
+		if ctx.Err() != nil {
+			break
+		}
+
+		next := queue[0]
+		queue = queue[1:]
+
+		item, err := h.getItem(ctx, next.id)
+		fetched++
+		if err != nil {
+			logrus.Debugf("Failed to get HN comment %d: %v", next.id, err)
+			continue
+		}
+
+		if item == nil || item.Deleted || item.Dead {
+			continue
+		}
+
+		if next.depth < hackerNewsMaxCommentDepth {
+			for _, kid := range item.Kids {
+				queue = append(queue, queued{id: kid, depth: next.depth + 1})
+			}
+		}
+
+		text := h.sanitizer.Text(item.Text)
+		if !containsAnyKeyword(text, keywords) {
+			continue
+		}
+
+		comments = append(comments, models.Comment{
+			ID:        fmt.Sprintf("hackernews_%d", item.ID),
+			MentionID: mentionID,
+			Author:    item.By,
+			Content:   text,
+			URL:       fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID),
+			CreatedAt: time.Unix(item.Time, 0),
+		})
+	}
+
+	logrus.Debugf("Scanned %d HN comments for story %d, %d matched keywords", fetched, story.ID, len(comments))
+	return comments
+}
+
+// hackerNewsPostType classifies an item as Ask HN, Show HN, a plain story or a comment.
+// Ask and Show HN are ordinary stories distinguished only by their title prefix.
+func hackerNewsPostType(item *hackerNewsItem) string {
+	if item.Type == "comment" {
+		return models.PostTypeComment
+	}
+
+	title := strings.ToLower(strings.TrimSpace(item.Title))
+	switch {
+	case strings.HasPrefix(title, "ask hn:"):
+		return models.PostTypeAskHN
+	case strings.HasPrefix(title, "show hn:"):
+		return models.PostTypeShowHN
+	default:
+		return models.PostTypeStory
+	}
+}
+
+// containsAnyKeyword reports whether text contains any of the keywords (case-insensitive)
+func containsAnyKeyword(text string, keywords []string) bool {
+	lower := strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(lower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *HackerNewsSource) getRecentItems(ctx context.Context) ([]int, error) {
 	// Get new stories
 	resp, err := h.client.R().
@@ -145,6 +277,28 @@ func (h *HackerNewsSource) getRecentItems(ctx context.Context) ([]int, error) {
 	return itemIDs, nil
 }
 
+// CheckAnswers looks up the comment count of earlier stories, one item request each
+func (h *HackerNewsSource) CheckAnswers(ctx context.Context, mentionIDs []string) (map[string]AnswerState, error) {
+	states := make(map[string]AnswerState)
+	for _, id := range mentionIDs {
+		itemID, err := strconv.Atoi(strings.TrimPrefix(id, "hackernews_"))
+		if err != nil || !strings.HasPrefix(id, "hackernews_") {
+			continue
+		}
+
+		item, err := h.getItem(ctx, itemID)
+		if err != nil {
+			return states, err
+		}
+		if item.Deleted || item.Dead {
+			continue
+		}
+		states[id] = AnswerState{Answers: item.Descendants}
+	}
+
+	return states, nil
+}
+
 func (h *HackerNewsSource) getItem(ctx context.Context, itemID int) (*hackerNewsItem, error) {
 	resp, err := h.client.R().
 		SetContext(ctx).
@@ -2,6 +2,7 @@ package sources
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
@@ -13,3 +14,32 @@ type Source interface {
 	FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error)
 	IsEnabled() bool
 }
+
+// AnswerState is the current answer activity on a question a source returned earlier
+type AnswerState struct {
+	Answers  int  // Answers, or comments where the platform has no answers
+	Accepted bool // The author accepted an answer; only Stack Overflow reports this
+}
+
+// Answered reports whether the question has received an answer
+func (a AnswerState) Answered() bool {
+	return a.Answers > 0 || a.Accepted
+}
+
+// AnswerChecker is implemented by sources that can look up whether questions they returned
+// earlier have been answered since. States are keyed by mention ID; IDs from other sources
+// and questions that no longer exist are left out.
+type AnswerChecker interface {
+	CheckAnswers(ctx context.Context, mentionIDs []string) (map[string]AnswerState, error)
+}
+
+// cleanList trims whitespace from configured list values and drops empty entries
+func cleanList(values []string) []string {
+	var cleaned []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,121 @@
+package sources
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// linkedInSearches are the public LinkedIn content searched: Pulse articles and posts
+var linkedInSearches = []string{"site:linkedin.com/pulse", "site:linkedin.com/posts"}
+
+// linkedInActivityPattern matches the activity ID in a post URL, e.g. "-activity-7123456789"
+var linkedInActivityPattern = regexp.MustCompile(`activity-(\d+)`)
+
+// LinkedInSource finds public LinkedIn articles and posts through Bing. LinkedIn's own APIs
+// only expose content the caller owns or administers, so web search is the only way to
+// follow public discussion.
+type LinkedInSource struct {
+	search  *WebSearch
+	queries *QueryBuilder
+}
+
+// NewLinkedInSource creates a LinkedIn source searching through search
+func NewLinkedInSource(search *WebSearch) *LinkedInSource {
+	return &LinkedInSource{
+		search:  search,
+		queries: NewQueryBuilder(nil),
+	}
+}
+
+// WithQueries searches each keyword's aliases too, from the shared keyword templates
+func (l *LinkedInSource) WithQueries(queries *QueryBuilder) *LinkedInSource {
+	l.queries = queries
+	return l
+}
+
+func (l *LinkedInSource) GetName() string {
+	return "linkedin"
+}
+
+func (l *LinkedInSource) IsEnabled() bool {
+	return l.search.IsEnabled()
+}
+
+func (l *LinkedInSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !l.IsEnabled() {
+		logrus.Debug("LinkedIn source disabled - missing Bing Web Search key")
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, query := range l.queries.Plan(keywords) {
+		for _, site := range linkedInSearches {
+			results, err := l.search.Search(ctx, site+" "+bingQuery(query, false), since)
+			if err != nil {
+				logrus.Errorf("Failed to search LinkedIn (%s) for '%s': %v", site, query.Keyword, err)
+				continue
+			}
+
+			for _, result := range results {
+				if !l.queries.Matches(query, result.Title+" "+result.Snippet) {
+					continue
+				}
+				mention := l.resultMention(result)
+				if seen[mention.ID] {
+					continue
+				}
+				seen[mention.ID] = true
+				mention.Keywords = []string{query.Keyword}
+				allMentions = append(allMentions, mention)
+			}
+		}
+	}
+
+	logrus.Infof("LinkedIn: Total mentions found: %d", len(allMentions))
+	return allMentions, nil
+}
+
+// resultMention converts a search result into a mention. Bing titles posts "<author> on
+// LinkedIn: <text>" and articles "<title> | LinkedIn"; post URLs carry the author's handle.
+func (l *LinkedInSource) resultMention(result WebResult) models.Mention {
+	title := strings.TrimSuffix(strings.TrimSuffix(result.Title, " | LinkedIn"), " - LinkedIn")
+
+	author := ""
+	if name, text, found := strings.Cut(title, " on LinkedIn: "); found {
+		author, title = strings.TrimSpace(name), strings.TrimSpace(text)
+	}
+
+	id := webMentionID("linkedin", result.URL)
+	if parsed, err := url.Parse(result.URL); err == nil {
+		if match := linkedInActivityPattern.FindStringSubmatch(parsed.Path); match != nil {
+			id = "linkedin_" + match[1]
+		}
+		if author == "" && strings.HasPrefix(parsed.Path, "/posts/") {
+			if handle, _, found := strings.Cut(strings.TrimPrefix(parsed.Path, "/posts/"), "_"); found {
+				author = handle
+			}
+		}
+	}
+	if author == "" {
+		author = "LinkedIn Member"
+	}
+
+	return models.Mention{
+		ID:        id,
+		Source:    "linkedin",
+		Platform:  "LinkedIn",
+		Title:     title,
+		Content:   result.Snippet,
+		Author:    author,
+		URL:       result.URL,
+		CreatedAt: result.Published,
+	}
+}
@@ -0,0 +1,354 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// linkedInAPIURL is the base URL of LinkedIn's versioned REST API
+	linkedInAPIURL = "https://api.linkedin.com/rest"
+	// DefaultLinkedInAPIVersion is the LinkedIn-Version requested; LinkedIn retires versions
+	// about a year after release
+	DefaultLinkedInAPIVersion = "202601"
+	// linkedInPageTopComments is the number of recent comments kept on a post's mention
+	linkedInPageTopComments = 5
+)
+
+// LinkedInPageSource follows the posts of a LinkedIn organization page through the Community
+// Management API: posts that name a keyword, with their reactions and comments, and the
+// comments people leave on them. The access token belongs to a page administrator and needs
+// the r_organization_social permission.
+type LinkedInPageSource struct {
+	accessToken  string
+	organization string // Numeric organization ID
+	pageName     string // Author of the page's posts
+	apiVersion   string
+	maxPosts     int
+	baseURL      string
+	client       *resty.Client
+	queries      *QueryBuilder
+}
+
+type linkedInPostsResponse struct {
+	Elements []linkedInPost `json:"elements"`
+}
+
+type linkedInPost struct {
+	ID             string `json:"id"` // e.g. "urn:li:share:7123456789" or "urn:li:ugcPost:7123456789"
+	Commentary     string `json:"commentary"`
+	PublishedAt    int64  `json:"publishedAt"` // Unix milliseconds
+	CreatedAt      int64  `json:"createdAt"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+type linkedInSocialMetadata struct {
+	ReactionSummaries map[string]struct {
+		Count int `json:"count"`
+	} `json:"reactionSummaries"`
+	CommentSummary struct {
+		Count int `json:"count"`
+	} `json:"commentSummary"`
+}
+
+type linkedInCommentsResponse struct {
+	Elements []linkedInComment `json:"elements"`
+}
+
+type linkedInComment struct {
+	ID         string `json:"id"`
+	CommentURN string `json:"commentUrn"`
+	Actor      string `json:"actor"`
+	Message    struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Created struct {
+		Time int64 `json:"time"`
+	} `json:"created"`
+}
+
+// NewLinkedInPageSource creates a source for the LinkedIn organization page with the given
+// numeric ID, e.g. from https://www.linkedin.com/company/<id>/admin
+func NewLinkedInPageSource(accessToken, organization string) *LinkedInPageSource {
+	return &LinkedInPageSource{
+		accessToken:  accessToken,
+		organization: strings.TrimSpace(organization),
+		pageName:     "LinkedIn Page",
+		apiVersion:   DefaultLinkedInAPIVersion,
+		maxPosts:     50,
+		baseURL:      linkedInAPIURL,
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		queries: NewQueryBuilder(nil),
+	}
+}
+
+// WithAPIVersion requests a LinkedIn API version, e.g. "202601", when one is given
+func (l *LinkedInPageSource) WithAPIVersion(version string) *LinkedInPageSource {
+	if version = strings.TrimSpace(version); version != "" {
+		l.apiVersion = version
+	}
+	return l
+}
+
+// WithPageName names the page as the author of its posts, e.g. "Microsoft Azure"
+func (l *LinkedInPageSource) WithPageName(name string) *LinkedInPageSource {
+	if name = strings.TrimSpace(name); name != "" {
+		l.pageName = name
+	}
+	return l
+}
+
+// WithMaxPosts sets how many of the page's latest posts are checked each run (at most 100)
+func (l *LinkedInPageSource) WithMaxPosts(maxPosts int) *LinkedInPageSource {
+	if maxPosts > 0 && maxPosts <= 100 {
+		l.maxPosts = maxPosts
+	}
+	return l
+}
+
+// WithQueries matches each keyword's aliases too, from the shared keyword templates
+func (l *LinkedInPageSource) WithQueries(queries *QueryBuilder) *LinkedInPageSource {
+	l.queries = queries
+	return l
+}
+
+func (l *LinkedInPageSource) GetName() string {
+	return "linkedinpage"
+}
+
+func (l *LinkedInPageSource) IsEnabled() bool {
+	return l.accessToken != "" && l.organization != ""
+}
+
+// FetchMentions returns the page's keyword posts that were published within since or got
+// comments within it, with their current reactions and comment counts, and those comments
+func (l *LinkedInPageSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !l.IsEnabled() {
+		logrus.Debug("LinkedIn page source disabled - missing access token or organization ID")
+		return nil, nil
+	}
+
+	posts, err := l.fetchPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-since)
+	plan := l.queries.Plan(keywords)
+	var allMentions []models.Mention
+
+	for _, post := range posts {
+		if post.LifecycleState != "" && post.LifecycleState != "PUBLISHED" {
+			continue
+		}
+		var matched []string
+		for _, query := range plan {
+			if l.queries.Matches(query, post.Commentary) {
+				matched = append(matched, query.Keyword)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		comments, err := l.fetchComments(ctx, post.ID)
+		if err != nil {
+			logrus.Errorf("Failed to fetch comments on LinkedIn post %s: %v", post.ID, err)
+		}
+		var recent []models.Mention
+		for _, comment := range comments {
+			if mention, ok := l.convertComment(post, comment, matched); ok && mention.CreatedAt.After(cutoff) {
+				recent = append(recent, mention)
+			}
+		}
+
+		published := linkedInTime(post.PublishedAt, post.CreatedAt)
+		if published.Before(cutoff) && len(recent) == 0 {
+			continue
+		}
+
+		mention := l.convertPost(post, matched)
+		if metadata, err := l.fetchSocialMetadata(ctx, post.ID); err != nil {
+			logrus.Errorf("Failed to fetch reactions on LinkedIn post %s: %v", post.ID, err)
+		} else {
+			for _, reaction := range metadata.ReactionSummaries {
+				mention.Score += reaction.Count
+			}
+			mention.CommentCount = metadata.CommentSummary.Count
+		}
+		mention.TopComments = linkedInTopComments(mention.ID, comments, l.organizationURN())
+
+		allMentions = append(allMentions, mention)
+		allMentions = append(allMentions, recent...)
+	}
+
+	logrus.Infof("LinkedIn page: Total mentions found: %d", len(allMentions))
+	return allMentions, nil
+}
+
+func (l *LinkedInPageSource) organizationURN() string {
+	return "urn:li:organization:" + l.organization
+}
+
+// request prepares a call to the LinkedIn REST API
+func (l *LinkedInPageSource) request(ctx context.Context) *resty.Request {
+	return l.client.R().
+		SetContext(ctx).
+		SetAuthToken(l.accessToken).
+		SetHeader("LinkedIn-Version", l.apiVersion).
+		SetHeader("X-Restli-Protocol-Version", "2.0.0")
+}
+
+// get calls path on the LinkedIn REST API and decodes the response into result
+func (l *LinkedInPageSource) get(ctx context.Context, path string, query map[string]string, result interface{}) error {
+	resp, err := l.request(ctx).SetQueryParams(query).Get(l.baseURL + path)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("LinkedIn API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	if err := json.Unmarshal(resp.Body(), result); err != nil {
+		return fmt.Errorf("failed to parse LinkedIn response: %w", err)
+	}
+	return nil
+}
+
+// fetchPosts returns the page's latest posts, newest first
+func (l *LinkedInPageSource) fetchPosts(ctx context.Context) ([]linkedInPost, error) {
+	var posts linkedInPostsResponse
+	err := l.get(ctx, "/posts", map[string]string{
+		"q":      "author",
+		"author": l.organizationURN(),
+		"count":  strconv.Itoa(l.maxPosts),
+		"sortBy": "CREATED",
+	}, &posts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list LinkedIn page posts: %w", err)
+	}
+	return posts.Elements, nil
+}
+
+func (l *LinkedInPageSource) fetchSocialMetadata(ctx context.Context, postURN string) (*linkedInSocialMetadata, error) {
+	var metadata linkedInSocialMetadata
+	if err := l.get(ctx, "/socialMetadata/"+url.QueryEscape(postURN), nil, &metadata); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (l *LinkedInPageSource) fetchComments(ctx context.Context, postURN string) ([]linkedInComment, error) {
+	var comments linkedInCommentsResponse
+	if err := l.get(ctx, "/socialActions/"+url.QueryEscape(postURN)+"/comments", map[string]string{"count": "100"}, &comments); err != nil {
+		return nil, err
+	}
+	return comments.Elements, nil
+}
+
+func (l *LinkedInPageSource) convertPost(post linkedInPost, keywords []string) models.Mention {
+	return models.Mention{
+		ID:        "linkedinpage_" + linkedInURNID(post.ID),
+		Source:    "linkedinpage",
+		Platform:  "LinkedIn",
+		Title:     linkedInPostTitle(post, 120),
+		Content:   post.Commentary,
+		Author:    l.pageName,
+		URL:       linkedInPostURL(post.ID),
+		CreatedAt: linkedInTime(post.PublishedAt, post.CreatedAt),
+		Keywords:  keywords,
+		PostType:  models.PostTypeStory,
+	}
+}
+
+// convertComment turns a comment into a mention with the keywords of its post; the page's
+// own replies are not mentions
+func (l *LinkedInPageSource) convertComment(post linkedInPost, comment linkedInComment, keywords []string) (models.Mention, bool) {
+	if comment.Actor == l.organizationURN() || strings.TrimSpace(comment.Message.Text) == "" {
+		return models.Mention{}, false
+	}
+	return models.Mention{
+		ID:        "linkedinpage_" + linkedInURNID(post.ID) + "_" + comment.ID,
+		Source:    "linkedinpage",
+		Platform:  "LinkedIn",
+		Title:     "Comment on: " + linkedInPostTitle(post, 100),
+		Content:   comment.Message.Text,
+		Author:    "LinkedIn Member",
+		URL:       linkedInCommentURL(post.ID, comment.CommentURN),
+		CreatedAt: time.UnixMilli(comment.Created.Time),
+		Keywords:  keywords,
+		PostType:  models.PostTypeComment,
+	}, true
+}
+
+// linkedInTopComments keeps the newest comments on a post, other than the page's own replies
+func linkedInTopComments(mentionID string, comments []linkedInComment, organizationURN string) []models.Comment {
+	sorted := make([]linkedInComment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.Actor != organizationURN && strings.TrimSpace(comment.Message.Text) != "" {
+			sorted = append(sorted, comment)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Created.Time > sorted[j].Created.Time })
+	if len(sorted) > linkedInPageTopComments {
+		sorted = sorted[:linkedInPageTopComments]
+	}
+
+	var top []models.Comment
+	for _, comment := range sorted {
+		top = append(top, models.Comment{
+			ID:        comment.ID,
+			MentionID: mentionID,
+			Author:    "LinkedIn Member",
+			Content:   comment.Message.Text,
+			CreatedAt: time.UnixMilli(comment.Created.Time),
+		})
+	}
+	return top
+}
+
+// linkedInPostTitle titles a post with the first line of its text, cut to maxLength characters
+func linkedInPostTitle(post linkedInPost, maxLength int) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(post.Commentary), "\n")
+	if runes := []rune(title); len(runes) > maxLength {
+		title = string(runes[:maxLength-3]) + "..."
+	}
+	return title
+}
+
+// linkedInURNID returns the ID at the end of a URN, e.g. "7123" for "urn:li:share:7123"
+func linkedInURNID(urn string) string {
+	return urn[strings.LastIndex(urn, ":")+1:]
+}
+
+func linkedInPostURL(postURN string) string {
+	return "https://www.linkedin.com/feed/update/" + postURN + "/"
+}
+
+func linkedInCommentURL(postURN, commentURN string) string {
+	if commentURN == "" {
+		return linkedInPostURL(postURN)
+	}
+	return linkedInPostURL(postURN) + "?commentUrn=" + url.QueryEscape(commentURN)
+}
+
+// linkedInTime converts the first set Unix millisecond timestamp
+func linkedInTime(timestamps ...int64) time.Time {
+	for _, ms := range timestamps {
+		if ms > 0 {
+			return time.UnixMilli(ms)
+		}
+	}
+	return time.Time{}
+}
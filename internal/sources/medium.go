@@ -2,18 +2,36 @@ package sources
 
 import (
 	"context"
+	"encoding/xml"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
 )
 
+// mediumMaxContent caps how much article text is stored per mention; keyword matching
+// always sees the full article
+const mediumMaxContent = 4000
+
+// mediumRelevanceExcerpt is how much of the article the relevance filter reads
+const mediumRelevanceExcerpt = 1000
+
 // MediumSource implements Medium.com scraping source
 type MediumSource struct {
-	client *resty.Client
+	client       *resty.Client
+	tags         []string // Fixed tag list overriding per-keyword tag generation
+	publications []string // Publication feeds to follow, e.g. "itnext"
+	authors      []string // Author feeds to follow, e.g. "@jane"
+	queries      *QueryBuilder
+	search       *WebSearch // Bing search for articles outside the feeds, nil to skip it
+	sanitizer    *sanitize.Sanitizer
 }
 
 // NewMediumSource creates a new Medium source
@@ -22,7 +40,59 @@ func NewMediumSource() *MediumSource {
 		client: resty.New().
 			SetTimeout(30*time.Second).
 			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		queries:   NewQueryBuilder(nil),
+		sanitizer: sanitize.New(),
+	}
+}
+
+// WithQueries matches followed feeds against the shared keyword templates, including their aliases
+func (m *MediumSource) WithQueries(queries *QueryBuilder) *MediumSource {
+	m.queries = queries
+	return m
+}
+
+// WithSanitizer sets how article HTML is turned into mention content
+func (m *MediumSource) WithSanitizer(sanitizer *sanitize.Sanitizer) *MediumSource {
+	m.sanitizer = sanitizer
+	return m
+}
+
+// WithWebSearch also searches medium.com through Bing for articles the feeds don't carry
+func (m *MediumSource) WithWebSearch(search *WebSearch) *MediumSource {
+	m.search = search
+	return m
+}
+
+// WithTransport sends the source's requests through transport, e.g. a response cache
+func (m *MediumSource) WithTransport(transport http.RoundTripper) *MediumSource {
+	m.client.SetTransport(transport)
+	return m
+}
+
+// WithTags sets a fixed list of tags to follow instead of deriving tags from each keyword
+func (m *MediumSource) WithTags(tags []string) *MediumSource {
+	m.tags = cleanList(tags)
+	return m
+}
+
+// WithPublications follows the feeds of the given publications, e.g. "itnext" or
+// "microsoftazure", matching keywords against each article's full text
+func (m *MediumSource) WithPublications(publications []string) *MediumSource {
+	m.publications = nil
+	for _, publication := range cleanList(publications) {
+		publication = strings.TrimPrefix(strings.TrimPrefix(publication, "https://medium.com/"), "medium.com/")
+		m.publications = append(m.publications, strings.Trim(publication, "/"))
 	}
+	return m
+}
+
+// WithAuthors follows the feeds of the given authors, with or without a leading "@"
+func (m *MediumSource) WithAuthors(authors []string) *MediumSource {
+	m.authors = nil
+	for _, author := range cleanList(authors) {
+		m.authors = append(m.authors, "@"+strings.TrimPrefix(author, "@"))
+	}
+	return m
 }
 
 func (m *MediumSource) GetName() string {
@@ -36,6 +106,24 @@ func (m *MediumSource) IsEnabled() bool {
 func (m *MediumSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
 	var allMentions []models.Mention
 
+	for _, tag := range m.tags {
+		tagMentions, err := m.fetchFromRSS(ctx, tag, since)
+		if err != nil {
+			logrus.Warnf("Failed to fetch Medium RSS for tag '%s': %v", tag, err)
+			continue
+		}
+		allMentions = append(allMentions, tagMentions...)
+	}
+
+	for _, feedPath := range append(append([]string{}, m.publications...), m.authors...) {
+		feedMentions, err := m.fetchFollowed(ctx, feedPath, keywords, since)
+		if err != nil {
+			logrus.Warnf("Failed to fetch Medium RSS for '%s': %v", feedPath, err)
+			continue
+		}
+		allMentions = append(allMentions, feedMentions...)
+	}
+
 	for _, keyword := range keywords {
 		mentions, err := m.searchKeyword(ctx, keyword, since)
 		if err != nil {
@@ -53,8 +141,11 @@ func (m *MediumSource) searchKeyword(ctx context.Context, keyword string, since
 	// Medium provides RSS feeds for tags: https://medium.com/feed/tag/{tag}
 	var mentions []models.Mention
 
-	// Try different tag variations for the keyword
-	tags := m.generateTags(keyword)
+	// Try different tag variations for the keyword (fixed tags are fetched once in FetchMentions)
+	var tags []string
+	if len(m.tags) == 0 {
+		tags = m.generateTags(keyword)
+	}
 
 	for _, tag := range tags {
 		tagMentions, err := m.fetchFromRSS(ctx, tag, since)
@@ -65,12 +156,14 @@ func (m *MediumSource) searchKeyword(ctx context.Context, keyword string, since
 		mentions = append(mentions, tagMentions...)
 	}
 
-	// Also try Medium's search via Google (public content)
-	searchMentions, err := m.searchViaGoogle(ctx, keyword, since)
-	if err != nil {
-		logrus.Warnf("Failed to search Medium via Google for '%s': %v", keyword, err)
-	} else {
-		mentions = append(mentions, searchMentions...)
+	// Also search the web for articles outside the tag feeds
+	if m.search.IsEnabled() {
+		searchMentions, err := m.searchViaBing(ctx, keyword, since)
+		if err != nil {
+			logrus.Warnf("Failed to search Medium via Bing for '%s': %v", keyword, err)
+		} else {
+			mentions = append(mentions, searchMentions...)
+		}
 	}
 
 	return mentions, nil
@@ -109,113 +202,102 @@ func (m *MediumSource) generateTags(keyword string) []string {
 }
 
 func (m *MediumSource) fetchFromRSS(ctx context.Context, tag string, since time.Duration) ([]models.Mention, error) {
-	url := fmt.Sprintf("https://medium.com/feed/tag/%s", tag)
-
-	resp, err := m.client.R().
-		SetContext(ctx).
-		Get(url)
-
+	items, err := m.fetchFeed(ctx, "tag/"+tag)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("RSS feed returned status %d", resp.StatusCode())
+	cutoff := time.Now().Add(-since)
+	var mentions []models.Mention
+	for _, item := range items {
+		if mention := m.processFeedItem(item, cutoff); mention != nil {
+			mention.Keywords = []string{tag}
+			mentions = append(mentions, *mention)
+		}
 	}
-
-	return m.parseRSSFeed(resp.String(), tag, since)
+	return mentions, nil
 }
 
-func (m *MediumSource) parseRSSFeed(rssContent, tag string, since time.Duration) ([]models.Mention, error) {
-	var mentions []models.Mention
-	cutoff := time.Now().Add(-since)
-
-	// Simple RSS parsing - in production, you'd use a proper XML parser
-	// This is a basic implementation that looks for item patterns
-	lines := strings.Split(rssContent, "\n")
-
-	var currentItem map[string]string
-	inItem := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.Contains(line, "<item>") {
-			inItem = true
-			currentItem = make(map[string]string)
-			continue
-		}
+// fetchFollowed fetches a publication or author feed and keeps the articles whose full
+// text mentions one of the keywords. Unlike tag feeds these are not topic-filtered by
+// Medium, so every article must match a keyword.
+func (m *MediumSource) fetchFollowed(ctx context.Context, feedPath string, keywords []string, since time.Duration) ([]models.Mention, error) {
+	items, err := m.fetchFeed(ctx, feedPath)
+	if err != nil {
+		return nil, err
+	}
 
-		if strings.Contains(line, "</item>") && inItem {
-			inItem = false
-			if mention := m.processRSSItem(currentItem, tag, cutoff); mention != nil {
-				mentions = append(mentions, *mention)
-			}
+	cutoff := time.Now().Add(-since)
+	var mentions []models.Mention
+	for _, item := range items {
+		mention := m.processFeedItem(item, cutoff)
+		if mention == nil {
 			continue
 		}
 
-		if inItem {
-			if strings.Contains(line, "<title>") {
-				currentItem["title"] = m.extractXMLContent(line, "title")
-			} else if strings.Contains(line, "<link>") {
-				currentItem["link"] = m.extractXMLContent(line, "link")
-			} else if strings.Contains(line, "<description>") {
-				currentItem["description"] = m.extractXMLContent(line, "description")
-			} else if strings.Contains(line, "<pubDate>") {
-				currentItem["pubDate"] = m.extractXMLContent(line, "pubDate")
-			} else if strings.Contains(line, "<dc:creator>") {
-				currentItem["creator"] = m.extractXMLContent(line, "dc:creator")
-			}
+		mention.Keywords = m.queries.Match(keywords, mention.Title+" "+m.articleText(item))
+		if len(mention.Keywords) > 0 {
+			mentions = append(mentions, *mention)
 		}
 	}
-
 	return mentions, nil
 }
 
-func (m *MediumSource) extractXMLContent(line, tag string) string {
-	start := strings.Index(line, fmt.Sprintf("<%s>", tag))
-	end := strings.Index(line, fmt.Sprintf("</%s>", tag))
-
-	if start != -1 && end != -1 {
-		start += len(tag) + 2
-		if start < end {
-			content := line[start:end]
-			// Basic HTML entity decoding
-			content = strings.ReplaceAll(content, "&lt;", "<")
-			content = strings.ReplaceAll(content, "&gt;", ">")
-			content = strings.ReplaceAll(content, "&amp;", "&")
-			content = strings.ReplaceAll(content, "&quot;", "\"")
-			return content
-		}
+// mediumFeed is the subset of a Medium RSS feed the source reads
+type mediumFeed struct {
+	Items []mediumFeedItem `xml:"channel>item"`
+}
+
+type mediumFeedItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string `xml:"pubDate"`
+}
+
+// fetchFeed fetches a Medium RSS feed by path: "tag/<tag>", "<publication>" or "@<author>"
+func (m *MediumSource) fetchFeed(ctx context.Context, feedPath string) ([]mediumFeedItem, error) {
+	resp, err := m.client.R().
+		SetContext(ctx).
+		Get("https://medium.com/feed/" + feedPath)
+
+	if err != nil {
+		return nil, err
 	}
 
-	// Handle self-closing tags like <link />
-	if strings.Contains(line, fmt.Sprintf("<%s>", tag)) && strings.Contains(line, "/>") {
-		return strings.TrimSpace(line)
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("RSS feed returned status %d", resp.StatusCode())
 	}
 
-	return ""
+	return m.parseRSSFeed(resp.Body())
 }
 
-func (m *MediumSource) processRSSItem(item map[string]string, tag string, cutoff time.Time) *models.Mention {
-	title, ok := item["title"]
-	if !ok || title == "" {
-		return nil
+func (m *MediumSource) parseRSSFeed(data []byte) ([]mediumFeedItem, error) {
+	var feed mediumFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
 	}
+	return feed.Items, nil
+}
 
-	link, ok := item["link"]
-	if !ok || link == "" {
+// processFeedItem converts a feed item into a mention, returning nil for items that are
+// incomplete, older than cutoff or not about Azure Kubernetes Service
+func (m *MediumSource) processFeedItem(item mediumFeedItem, cutoff time.Time) *models.Mention {
+	title := strings.TrimSpace(item.Title)
+	link := strings.TrimSpace(item.Link)
+	if title == "" || link == "" {
 		return nil
 	}
 
 	// Parse publication date
 	pubDate := time.Now()
-	if dateStr, ok := item["pubDate"]; ok {
-		if parsed, err := time.Parse(time.RFC1123Z, dateStr); err == nil {
-			pubDate = parsed
-		} else if parsed, err := time.Parse(time.RFC1123, dateStr); err == nil {
-			pubDate = parsed
-		}
+	if parsed, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
+		pubDate = parsed
+	} else if parsed, err := time.Parse(time.RFC1123, item.PubDate); err == nil {
+		pubDate = parsed
 	}
 
 	// Check if article is recent enough
@@ -223,34 +305,62 @@ func (m *MediumSource) processRSSItem(item map[string]string, tag string, cutoff
 		return nil
 	}
 
-	description := item["description"]
-	if description == "" {
-		description = title
+	text := m.articleText(item)
+	if text == "" {
+		text = title
 	}
 
-	author := item["creator"]
+	author := strings.TrimSpace(item.Creator)
 	if author == "" {
 		author = "Medium Author"
 	}
 
-	// Apply Medium-specific relevance filtering
-	if !m.isRelevantMediumArticle(title, description) {
+	// Judge relevance on the opening of the article; full articles often compare AKS with
+	// other clouds further down, which would trip the negative indicators
+	excerpt := text
+	if len(excerpt) > mediumRelevanceExcerpt {
+		excerpt = excerpt[:mediumRelevanceExcerpt]
+	}
+	if !m.isRelevantMediumArticle(title, excerpt) {
 		return nil
 	}
 
+	if len(text) > mediumMaxContent {
+		text = text[:mediumMaxContent]
+	}
+
 	return &models.Mention{
-		ID:        fmt.Sprintf("medium_%s_%d", tag, pubDate.Unix()),
+		ID:        m.mentionID(item, pubDate),
 		Source:    "medium",
 		Platform:  "Medium",
 		Title:     title,
-		Content:   description,
+		Content:   text,
 		Author:    author,
 		URL:       link,
 		CreatedAt: pubDate,
-		Keywords:  []string{tag},
 	}
 }
 
+// mentionID derives a stable ID from the post GUID (https://medium.com/p/<id>) so the
+// same article found through several feeds is only reported once
+func (m *MediumSource) mentionID(item mediumFeedItem, pubDate time.Time) string {
+	guid := strings.TrimRight(strings.TrimSpace(item.GUID), "/")
+	if i := strings.LastIndex(guid, "/"); i >= 0 && i < len(guid)-1 {
+		return "medium_" + guid[i+1:]
+	}
+	return fmt.Sprintf("medium_%d_%s", pubDate.Unix(), strings.ToLower(strings.Join(strings.Fields(item.Title), "-")))
+}
+
+// articleText returns the plain text of the full article, falling back to the description
+func (m *MediumSource) articleText(item mediumFeedItem) string {
+	content := item.Content
+	if strings.TrimSpace(content) == "" {
+		content = item.Description
+	}
+
+	return m.sanitizer.Text(content)
+}
+
 func (m *MediumSource) isRelevantMediumArticle(title, content string) bool {
 	combinedText := strings.ToLower(title + " " + content)
 
@@ -323,244 +433,65 @@ func (m *MediumSource) isRelevantMediumArticle(title, content string) bool {
 	return false
 }
 
-func (m *MediumSource) searchViaGoogle(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	// Search Medium via Google search (public approach)
-	// This is a fallback method for broader searches
-	query := fmt.Sprintf("site:medium.com %s", keyword)
-
-	// Note: This is a simplified approach. In production, you might want to:
-	// 1. Use Google Custom Search API
-	// 2. Use a proper web scraping service
-	// 3. Implement more sophisticated parsing
-
-	logrus.Infof("Medium: Would search Google for: %s (simplified implementation)", query)
-
-	// For now, return empty results to avoid hitting Google without proper API
-	return []models.Mention{}, nil
-}
-
-func (m *MediumSource) deduplicateMentions(mentions []models.Mention) []models.Mention {
-	seen := make(map[string]bool)
-	var unique []models.Mention
-
-	for _, mention := range mentions {
-		if !seen[mention.ID] {
-			seen[mention.ID] = true
-			unique = append(unique, mention)
-		}
-	}
-
-	return unique
-}
-
-// LinkedInSource implements LinkedIn content search source
-type LinkedInSource struct {
-	client *resty.Client
-}
-
-// NewLinkedInSource creates a new LinkedIn source
-func NewLinkedInSource() *LinkedInSource {
-	return &LinkedInSource{
-		client: resty.New().
-			SetTimeout(30*time.Second).
-			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+// searchViaBing finds articles Medium's tag feeds missed with a site:medium.com web search,
+// keeping those that match the keyword and pass the relevance filter
+func (m *MediumSource) searchViaBing(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
+	query := m.queries.Template(keyword)
+	results, err := m.search.Search(ctx, "site:medium.com "+bingQuery(query, false), since)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func (l *LinkedInSource) GetName() string {
-	return "linkedin"
-}
-
-func (l *LinkedInSource) IsEnabled() bool {
-	return true // LinkedIn source now uses hybrid search approach for real content
-}
-
-func (l *LinkedInSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
-	logrus.Infof("LinkedIn: Fetching mentions with %d keywords, time window: %v", len(keywords), since)
-	
-	var allMentions []models.Mention
-
-	// Process each keyword separately for better results
-	for _, keyword := range keywords {
-		mentions, err := l.searchKeyword(ctx, keyword, since)
-		if err != nil {
-			logrus.Warnf("LinkedIn: Error searching for keyword '%s': %v", keyword, err)
+	var mentions []models.Mention
+	for _, result := range results {
+		title := strings.TrimSuffix(strings.TrimSuffix(result.Title, " | Medium"), " - Medium")
+		if !m.queries.Matches(query, title+" "+result.Snippet) || !m.isRelevantMediumArticle(title, result.Snippet) {
 			continue
 		}
-		allMentions = append(allMentions, mentions...)
-	}
-
-	// Deduplicate mentions
-	allMentions = l.deduplicateMentions(allMentions)
-	
-	logrus.Infof("LinkedIn: Total mentions found: %d", len(allMentions))
-	return allMentions, nil
-}
 
-func (l *LinkedInSource) searchKeyword(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	// LinkedIn doesn't have public RSS feeds like Medium, but we can try a few approaches:
-	// 1. Use Google search to find LinkedIn posts (limited but public)
-	// 2. Search for LinkedIn pulse articles (public content)
-	// 3. Note: Full LinkedIn API requires special partnership access
-
-	var mentions []models.Mention
-
-	// Search LinkedIn Pulse articles via Google (public approach)
-	pulseMentions, err := l.searchLinkedInPulse(ctx, keyword, since)
-	if err != nil {
-		logrus.Warnf("Failed to search LinkedIn Pulse for '%s': %v", keyword, err)
-	} else {
-		mentions = append(mentions, pulseMentions...)
-	}
-
-	// Search for public LinkedIn posts via Google
-	postMentions, err := l.searchPublicPosts(ctx, keyword, since)
-	if err != nil {
-		logrus.Warnf("Failed to search LinkedIn posts for '%s': %v", keyword, err)
-	} else {
-		mentions = append(mentions, postMentions...)
-	}
-
-	// If no results found via search, create a informational mention
-	if len(mentions) == 0 {
 		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_search_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn",
-			Title:     fmt.Sprintf("LinkedIn search for: %s", keyword),
-			Content:   fmt.Sprintf("LinkedIn search performed for '%s'. Note: LinkedIn requires API access for comprehensive data.", keyword),
-			Author:    "LinkedIn Bot",
-			URL:       fmt.Sprintf("https://www.linkedin.com/search/results/content/?keywords=%s", strings.ReplaceAll(keyword, " ", "%20")),
-			CreatedAt: time.Now(),
+			ID:        m.resultID(result.URL),
+			Source:    "medium",
+			Platform:  "Medium",
+			Title:     title,
+			Content:   result.Snippet,
+			Author:    m.resultAuthor(result.URL),
+			URL:       result.URL,
+			CreatedAt: result.Published,
 			Keywords:  []string{keyword},
 		})
 	}
-
 	return mentions, nil
 }
 
-func (l *LinkedInSource) searchLinkedInPulse(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	logrus.Infof("LinkedIn: Searching Pulse articles for keyword: %s", keyword)
+// mediumPostIDPattern matches the post ID Medium ends article URLs with, e.g. "-1a2b3c4d5e6f"
+var mediumPostIDPattern = regexp.MustCompile(`-([0-9a-f]{8,12})/?$`)
 
-	// Generate realistic LinkedIn Pulse content based on keyword
-	mentions := l.generateLinkedInPulseContent(keyword, since)
-	
-	return mentions, nil
-}
-
-func (l *LinkedInSource) searchPublicPosts(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	logrus.Infof("LinkedIn: Searching public posts for keyword: %s", keyword)
-
-	// Generate realistic LinkedIn post content based on keyword
-	mentions := l.generateLinkedInPostContent(keyword, since)
-	
-	return mentions, nil
-}
-
-func (l *LinkedInSource) generateLinkedInPulseContent(keyword string, since time.Duration) []models.Mention {
-	var mentions []models.Mention
-	baseTime := time.Now().Add(-since)
-	
-	// Generate realistic Pulse articles based on AKS/Azure keywords
-	if strings.Contains(strings.ToLower(keyword), "aks") || strings.Contains(strings.ToLower(keyword), "azure kubernetes") {
-		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_pulse_aks_best_practices_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn Pulse",
-			Title:     "Production-Ready AKS: Lessons Learned from 2+ Years",
-			Content:   "After managing Azure Kubernetes Service clusters in production for over two years, here are the critical lessons learned for running enterprise workloads. Key topics: node pool strategies, networking configuration, security best practices, and cost optimization.",
-			Author:    "Michael Rodriguez, Principal Cloud Architect",
-			URL:       "https://www.linkedin.com/pulse/production-ready-aks-lessons-learned-2-years-michael-rodriguez",
-			CreatedAt: baseTime.Add(time.Duration(l.randomBetween(1, int(since.Hours()))) * time.Hour),
-			Keywords:  []string{keyword},
-		})
-		
-		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_pulse_aks_migration_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn Pulse",
-			Title:     "Migrating from EKS to AKS: A Complete Guide",
-			Content:   "Our engineering team recently completed a successful migration from Amazon EKS to Azure Kubernetes Service. This article covers the migration strategy, challenges faced, tooling used, and performance comparisons post-migration.",
-			Author:    "Sarah Kim, DevOps Engineering Manager",
-			URL:       "https://www.linkedin.com/pulse/migrating-eks-aks-complete-guide-sarah-kim",
-			CreatedAt: baseTime.Add(time.Duration(l.randomBetween(1, int(since.Hours()))) * time.Hour),
-			Keywords:  []string{keyword},
-		})
+// resultID gives a searched article the ID its feed item would get, so an article found by
+// both is reported once
+func (m *MediumSource) resultID(rawURL string) string {
+	path := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		path = parsed.Path
 	}
-	
-	if strings.Contains(strings.ToLower(keyword), "kubernetes") || strings.Contains(strings.ToLower(keyword), "azure") {
-		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_pulse_k8s_security_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn Pulse",
-			Title:     "Kubernetes Security in Azure: Beyond the Basics",
-			Content:   "Security in AKS goes far beyond basic RBAC. This deep dive covers pod security standards, network policies, Azure Policy integration, secrets management with Key Vault, and monitoring with Microsoft Sentinel.",
-			Author:    "David Park, Security Architect",
-			URL:       "https://www.linkedin.com/pulse/kubernetes-security-azure-beyond-basics-david-park",
-			CreatedAt: baseTime.Add(time.Duration(l.randomBetween(1, int(since.Hours()))) * time.Hour),
-			Keywords:  []string{keyword},
-		})
+	if match := mediumPostIDPattern.FindStringSubmatch(path); match != nil {
+		return "medium_" + match[1]
 	}
-	
-	return mentions
+	return webMentionID("medium", rawURL)
 }
 
-func (l *LinkedInSource) generateLinkedInPostContent(keyword string, since time.Duration) []models.Mention {
-	var mentions []models.Mention
-	baseTime := time.Now().Add(-since)
-	
-	// Generate realistic LinkedIn posts and discussions
-	if strings.Contains(strings.ToLower(keyword), "aks") || strings.Contains(strings.ToLower(keyword), "azure kubernetes") {
-		// Microsoft official posts
-		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_post_ms_announcement_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn Company",
-			Title:     "Azure Kubernetes Service introduces enhanced security features",
-			Content:   "🚀 Exciting news! AKS now includes improved pod security standards, advanced network policies, and deeper integration with Azure Security Center. These enhancements provide enterprise-grade security for your containerized workloads. Learn more about implementing these features in your clusters.",
-			Author:    "Microsoft Azure",
-			URL:       "https://www.linkedin.com/company/microsoft/posts/aks-security-features-update",
-			CreatedAt: baseTime.Add(time.Duration(l.randomBetween(1, int(since.Hours()))) * time.Hour),
-			Keywords:  []string{keyword},
-		})
-		
-		// Community discussions
-		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_post_community_issue_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn Discussion",
-			Title:     "AKS autoscaling behavior - anyone else seeing this?",
-			Content:   "Has anyone experienced issues with AKS cluster autoscaler getting stuck during scale-up operations? We're seeing nodes getting stuck in 'NotReady' state intermittently. Running AKS 1.28.x with Standard load balancer. Any insights appreciated! #AKS #Azure #Kubernetes",
-			Author:    "Alex Thompson, Platform Engineer",
-			URL:       "https://www.linkedin.com/posts/alex-thompson-devops_aks-autoscaling-issue",
-			CreatedAt: baseTime.Add(time.Duration(l.randomBetween(1, int(since.Hours()))) * time.Hour),
-			Keywords:  []string{keyword},
-		})
-		
-		mentions = append(mentions, models.Mention{
-			ID:        fmt.Sprintf("linkedin_post_cost_tips_%d", time.Now().Unix()),
-			Source:    "linkedin",
-			Platform:  "LinkedIn Discussion",
-			Title:     "AKS cost optimization tips that actually work",
-			Content:   "After 6 months of optimizing our AKS costs, here's what made the biggest impact: 1) Right-sizing node pools (saved 35%), 2) Using spot instances for dev/test (saved 20%), 3) Implementing resource quotas (saved 15%). Total savings: 40% reduction in monthly costs without performance impact.",
-			Author:    "Jessica Chen, Cloud Cost Engineer",
-			URL:       "https://www.linkedin.com/posts/jessica-chen-cloud_aks-cost-optimization-tips",
-			CreatedAt: baseTime.Add(time.Duration(l.randomBetween(1, int(since.Hours()))) * time.Hour),
-			Keywords:  []string{keyword},
-		})
-	}
-	
-	return mentions
-}
-
-func (l *LinkedInSource) randomBetween(min, max int) int {
-	if max <= min {
-		return min
+// resultAuthor reads the author from an article URL such as https://medium.com/@jane/...
+func (m *MediumSource) resultAuthor(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+		if strings.HasPrefix(segment, "@") {
+			return segment
+		}
 	}
-	return min + (int(time.Now().UnixNano()) % (max - min))
+	return "Medium Author"
 }
 
-func (l *LinkedInSource) deduplicateMentions(mentions []models.Mention) []models.Mention {
+func (m *MediumSource) deduplicateMentions(mentions []models.Mention) []models.Mention {
 	seen := make(map[string]bool)
 	var unique []models.Mention
 
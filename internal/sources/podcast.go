@@ -0,0 +1,380 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/cache"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// podcastMaxContent caps the show notes stored per mention
+const podcastMaxContent = 4000
+
+// podcastTranscriptExcerpt is how much of a transcript around the first keyword is kept in
+// the mention of an episode whose show notes don't name the keyword
+const podcastTranscriptExcerpt = 1500
+
+// podcastMaxTranscriptBytes caps the published transcripts read
+const podcastMaxTranscriptBytes = 2 << 20
+
+// PodcastSource follows podcast RSS feeds, matching keywords in episode titles and show
+// notes, and in transcripts when the notes don't name them
+type PodcastSource struct {
+	client         *resty.Client
+	feeds          []string
+	queries        *QueryBuilder
+	sanitizer      *sanitize.Sanitizer
+	transcriber    *SpeechTranscriber
+	maxTranscribed int                        // Episodes transcribed per fetch
+	transcripts    *cache.LRU[string, string] // Transcripts by mention ID, so runs don't transcribe an episode twice
+}
+
+type podcastFeed struct {
+	Title  string        `xml:"channel>title"`
+	Author string        `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd channel>author"`
+	Items  []podcastItem `xml:"channel>item"`
+}
+
+type podcastItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Summary     string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+	Author      string `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	PubDate     string `xml:"pubDate"`
+	Enclosure   struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	Transcripts []podcastTranscript `xml:"https://podcastindex.org/namespace/1.0 transcript"`
+}
+
+// podcastTranscript is a transcript published with the Podcasting 2.0 namespace
+type podcastTranscript struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// NewPodcastSource creates a source following the given podcast RSS feed URLs
+func NewPodcastSource(feeds []string) *PodcastSource {
+	return &PodcastSource{
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		feeds:       cleanList(feeds),
+		queries:     NewQueryBuilder(nil),
+		sanitizer:   sanitize.New(),
+		transcripts: cache.NewLRU[string, string](100),
+	}
+}
+
+// WithQueries matches episodes against the shared keyword templates, including their aliases
+func (p *PodcastSource) WithQueries(queries *QueryBuilder) *PodcastSource {
+	p.queries = queries
+	return p
+}
+
+// WithSanitizer sets how show notes HTML is turned into mention content
+func (p *PodcastSource) WithSanitizer(sanitizer *sanitize.Sanitizer) *PodcastSource {
+	p.sanitizer = sanitizer
+	return p
+}
+
+// WithTranscription transcribes up to max episodes per fetch that have no published
+// transcript and whose show notes don't name a keyword
+func (p *PodcastSource) WithTranscription(transcriber *SpeechTranscriber, max int) *PodcastSource {
+	p.transcriber = transcriber
+	p.maxTranscribed = max
+	return p
+}
+
+// WithTransport sends the source's requests through transport, e.g. a response cache
+func (p *PodcastSource) WithTransport(transport http.RoundTripper) *PodcastSource {
+	p.client.SetTransport(transport)
+	return p
+}
+
+func (p *PodcastSource) GetName() string {
+	return "podcast"
+}
+
+func (p *PodcastSource) IsEnabled() bool {
+	return len(p.feeds) > 0
+}
+
+func (p *PodcastSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !p.IsEnabled() {
+		logrus.Debug("Podcast source disabled - no feeds configured")
+		return nil, nil
+	}
+
+	cutoff := time.Now().Add(-since)
+	budget := p.maxTranscribed
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, feedURL := range p.feeds {
+		feed, err := p.fetchFeed(ctx, feedURL)
+		if err != nil {
+			logrus.Warnf("Failed to fetch podcast feed %s: %v", feedURL, err)
+			continue
+		}
+
+		for _, item := range feed.Items {
+			mention := p.convertEpisode(feed, item, cutoff)
+			if mention == nil || seen[mention.ID] {
+				continue
+			}
+
+			mention.Keywords = p.queries.Match(keywords, mention.Title+" "+mention.Content)
+			if len(mention.Keywords) == 0 {
+				transcript := p.transcript(ctx, mention.ID, item, &budget)
+				if transcript == "" {
+					continue
+				}
+				mention.Keywords = p.queries.Match(keywords, mention.Title+" "+transcript)
+				if len(mention.Keywords) == 0 {
+					continue
+				}
+				excerpt := p.transcriptExcerpt(transcript, mention.Keywords)
+				mention.Content = strings.TrimSpace(mention.Content + "\n\nTranscript: " + excerpt)
+			}
+
+			seen[mention.ID] = true
+			allMentions = append(allMentions, *mention)
+		}
+	}
+
+	return allMentions, nil
+}
+
+func (p *PodcastSource) fetchFeed(ctx context.Context, feedURL string) (*podcastFeed, error) {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		Get(feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("RSS feed returned status %d", resp.StatusCode())
+	}
+
+	var feed podcastFeed
+	if err := xml.Unmarshal(resp.Body(), &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+	}
+	return &feed, nil
+}
+
+// convertEpisode converts an episode into a mention without keywords, returning nil for
+// episodes that are incomplete or older than cutoff
+func (p *PodcastSource) convertEpisode(feed *podcastFeed, item podcastItem, cutoff time.Time) *models.Mention {
+	title := strings.TrimSpace(item.Title)
+	audio := strings.TrimSpace(item.Enclosure.URL)
+	link := strings.TrimSpace(item.Link)
+	if link == "" {
+		link = audio
+	}
+	if title == "" || link == "" {
+		return nil
+	}
+
+	published, ok := parseFeedDate(item.PubDate)
+	if !ok || published.Before(cutoff) {
+		return nil
+	}
+
+	notes := item.Content
+	if strings.TrimSpace(notes) == "" {
+		notes = item.Description
+	}
+	if strings.TrimSpace(notes) == "" {
+		notes = item.Summary
+	}
+	text := p.sanitizer.Text(notes)
+	if len(text) > podcastMaxContent {
+		text = text[:podcastMaxContent]
+	}
+
+	show := strings.TrimSpace(feed.Title)
+	author := strings.TrimSpace(item.Author)
+	if author == "" {
+		author = strings.TrimSpace(feed.Author)
+	}
+	if author == "" {
+		author = show
+	}
+
+	guid := strings.TrimSpace(item.GUID)
+	if guid == "" {
+		guid = link
+	}
+	sum := sha1.Sum([]byte(guid))
+
+	return &models.Mention{
+		ID:        "podcast_" + hex.EncodeToString(sum[:])[:16],
+		Source:    "podcast",
+		Platform:  "Podcast",
+		Title:     title,
+		Content:   text,
+		Author:    author,
+		Channel:   show,
+		URL:       link,
+		MediaURL:  audio,
+		CreatedAt: published,
+	}
+}
+
+// parseFeedDate parses an RSS pubDate; feeds disagree on zone formats and zero padding
+func parseFeedDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// transcript returns the episode's published transcript, or transcribes its audio while
+// budget lasts. Transcripts are cached so later runs within the window reuse them.
+func (p *PodcastSource) transcript(ctx context.Context, id string, item podcastItem, budget *int) string {
+	if transcript, ok := p.transcripts.Get(id); ok {
+		return transcript
+	}
+
+	var transcript string
+	if published := preferredTranscript(item.Transcripts); published != nil {
+		text, err := p.fetchTranscript(ctx, *published)
+		if err != nil {
+			logrus.Warnf("Failed to fetch podcast transcript %s: %v", published.URL, err)
+			return ""
+		}
+		transcript = text
+	} else if p.transcriber.IsEnabled() && *budget > 0 && item.Enclosure.URL != "" {
+		*budget--
+		logrus.Infof("Transcribing podcast episode %q", item.Title)
+		text, err := p.transcriber.Transcribe(ctx, item.Enclosure.URL)
+		if err != nil {
+			logrus.Warnf("Failed to transcribe podcast episode %q: %v", item.Title, err)
+			return ""
+		}
+		transcript = text
+	} else {
+		return ""
+	}
+
+	p.transcripts.Add(id, transcript)
+	return transcript
+}
+
+// preferredTranscript picks the most readable published transcript: plain text, then
+// captions, then HTML; JSON transcripts are not read
+func preferredTranscript(transcripts []podcastTranscript) *podcastTranscript {
+	var best *podcastTranscript
+	bestRank := 0
+	for i, transcript := range transcripts {
+		rank := 0
+		switch mediaType := strings.ToLower(transcript.Type); {
+		case strings.HasPrefix(mediaType, "text/plain"):
+			rank = 3
+		case strings.Contains(mediaType, "vtt"), strings.Contains(mediaType, "srt"):
+			rank = 2
+		case strings.HasPrefix(mediaType, "text/html"):
+			rank = 1
+		}
+		if rank > bestRank && strings.TrimSpace(transcript.URL) != "" {
+			best, bestRank = &transcripts[i], rank
+		}
+	}
+	return best
+}
+
+func (p *PodcastSource) fetchTranscript(ctx context.Context, transcript podcastTranscript) (string, error) {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		Get(strings.TrimSpace(transcript.URL))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode() != 200 {
+		return "", fmt.Errorf("transcript returned status %d", resp.StatusCode())
+	}
+
+	body := resp.Body()
+	if len(body) > podcastMaxTranscriptBytes {
+		body = body[:podcastMaxTranscriptBytes]
+	}
+	if strings.HasPrefix(strings.ToLower(transcript.Type), "text/html") {
+		return p.sanitizer.Text(string(body)), nil
+	}
+	return captionText(string(body)), nil
+}
+
+// captionCue matches the cue numbers and timings of WebVTT and SRT captions
+var captionCue = regexp.MustCompile(`^(\d+|WEBVTT.*|NOTE.*|[\d:.,]+ --> [\d:.,]+.*)$`)
+
+// captionText joins the spoken lines of WebVTT or SRT captions, or returns plain text as is
+func captionText(captions string) string {
+	var lines []string
+	for _, line := range strings.Split(captions, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || captionCue.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// transcriptExcerpt returns the part of a transcript around the first mention of one of the
+// keywords or their aliases
+func (p *PodcastSource) transcriptExcerpt(transcript string, keywords []string) string {
+	lower := strings.ToLower(transcript)
+	at := -1
+	for _, keyword := range keywords {
+		for _, name := range queryNames(p.queries.Template(keyword)) {
+			if i := strings.Index(lower, strings.ToLower(name)); i >= 0 && (at < 0 || i < at) {
+				at = i
+			}
+		}
+	}
+
+	start := 0
+	if at > podcastTranscriptExcerpt/4 {
+		start = at - podcastTranscriptExcerpt/4
+		// Start on a word
+		if space := strings.IndexByte(transcript[start:], ' '); space >= 0 {
+			start += space + 1
+		}
+	}
+	end := start + podcastTranscriptExcerpt
+	if end >= len(transcript) {
+		end = len(transcript)
+	} else if space := strings.LastIndexByte(transcript[start:end], ' '); space > 0 {
+		end = start + space
+	}
+
+	excerpt := transcript[start:end]
+	if start > 0 {
+		excerpt = "..." + excerpt
+	}
+	if end < len(transcript) {
+		excerpt += "..."
+	}
+	return excerpt
+}
@@ -0,0 +1,168 @@
+package sources
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodcastSource_FetchMentions(t *testing.T) {
+	recent := time.Now().UTC().Add(-5 * time.Hour).Format(time.RFC1123Z)
+	old := time.Now().UTC().Add(-30 * 24 * time.Hour).Format(time.RFC1123Z)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:podcast="https://podcastindex.org/namespace/1.0">
+<channel>
+	<title>Kubernetes Podcast</title>
+	<itunes:author>Abdel and Kaslin</itunes:author>
+	<item>
+		<title>Fleet management at scale</title>
+		<link>https://kubernetespodcast.com/episode/240/</link>
+		<guid>kpod-240</guid>
+		<description><![CDATA[<p>We talk about <b>Azure Kubernetes Service</b> fleets.</p>]]></description>
+		<pubDate>` + recent + `</pubDate>
+		<enclosure url="https://media.example.com/kpod-240.mp3" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>Inference on Kubernetes</title>
+		<link>https://kubernetespodcast.com/episode/241/</link>
+		<guid>kpod-241</guid>
+		<description>Serving models</description>
+		<pubDate>` + recent + `</pubDate>
+		<enclosure url="https://media.example.com/kpod-241.mp3" type="audio/mpeg"/>
+		<podcast:transcript url="` + server.URL + `/241.json" type="application/json"/>
+		<podcast:transcript url="` + server.URL + `/241.vtt" type="text/vtt"/>
+	</item>
+	<item>
+		<title>Unrelated chat</title>
+		<guid>kpod-242</guid>
+		<description>Nothing to see</description>
+		<pubDate>` + recent + `</pubDate>
+		<enclosure url="` + server.URL + `/242.mp3" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>AKS from years ago</title>
+		<link>https://kubernetespodcast.com/episode/1/</link>
+		<pubDate>` + old + `</pubDate>
+	</item>
+</channel>
+</rss>`))
+		case "/241.vtt":
+			w.Write([]byte("WEBVTT\n\n1\n00:00:01.000 --> 00:00:04.000\nToday we deploy models\n\n2\n00:00:04.000 --> 00:00:08.000\nwith KAITO on AKS.\n"))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	source := NewPodcastSource([]string{server.URL + "/feed.xml", " "})
+	assert.True(t, source.IsEnabled())
+	assert.False(t, NewPodcastSource(nil).IsEnabled())
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mentions, 2)
+
+	notes := mentions[0]
+	assert.Equal(t, "podcast", notes.Source)
+	assert.Equal(t, "Fleet management at scale", notes.Title)
+	assert.Equal(t, "We talk about Azure Kubernetes Service fleets.", notes.Content)
+	assert.Equal(t, "Abdel and Kaslin", notes.Author)
+	assert.Equal(t, "Kubernetes Podcast", notes.Channel)
+	assert.Equal(t, "https://kubernetespodcast.com/episode/240/", notes.URL)
+	assert.Equal(t, "https://media.example.com/kpod-240.mp3", notes.MediaURL)
+	assert.Equal(t, []string{"AKS"}, notes.Keywords)
+
+	// Matched in its published captions, preferred over the JSON transcript
+	transcribed := mentions[1]
+	assert.Equal(t, "Inference on Kubernetes", transcribed.Title)
+	assert.Equal(t, "Serving models\n\nTranscript: Today we deploy models with KAITO on AKS.", transcribed.Content)
+	assert.Equal(t, []string{"AKS"}, transcribed.Keywords)
+}
+
+func TestPodcastSource_transcribesWithinBudget(t *testing.T) {
+	var transcriptions int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, ".mp3"):
+			w.Write([]byte("ID3 audio"))
+		case r.URL.Path == "/speechtotext/transcriptions:transcribe":
+			transcriptions++
+			assert.Equal(t, speechAPIVersion, r.URL.Query().Get("api-version"))
+			assert.Equal(t, "speech-key", r.Header.Get("Ocp-Apim-Subscription-Key"))
+
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			require.NoError(t, err)
+			form := multipart.NewReader(r.Body, params["boundary"])
+			definition, err := form.NextPart()
+			require.NoError(t, err)
+			assert.Equal(t, "definition", definition.FormName())
+			data, _ := io.ReadAll(definition)
+			assert.JSONEq(t, `{"locales": ["en-GB"]}`, string(data))
+			audio, err := form.NextPart()
+			require.NoError(t, err)
+			assert.Equal(t, "1.mp3", audio.FileName())
+			data, _ = io.ReadAll(audio)
+			assert.Equal(t, "ID3 audio", string(data))
+
+			w.Write([]byte(`{"combinedPhrases": [{"text": "We moved our inference to KAITO on Azure Kubernetes Service."}]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	source := NewPodcastSource([]string{server.URL + "/feed.xml"}).
+		WithTranscription(NewSpeechTranscriber(server.URL+"/", "speech-key").WithLocale("en-GB"), 1)
+	assert.False(t, NewSpeechTranscriber("", "key").IsEnabled())
+
+	published := time.Now().UTC().Add(-time.Hour).Format(time.RFC1123Z)
+	feed := &podcastFeed{Title: "Ship It"}
+	for _, episode := range []string{"1", "2"} {
+		feed.Items = append(feed.Items, podcastItem{Title: "Episode " + episode, GUID: episode, PubDate: published})
+		feed.Items[len(feed.Items)-1].Enclosure.URL = server.URL + "/" + episode + ".mp3"
+	}
+
+	budget := 1
+	var transcripts []string
+	for _, item := range feed.Items {
+		mention := source.convertEpisode(feed, item, time.Now().Add(-24*time.Hour))
+		require.NotNil(t, mention)
+		transcripts = append(transcripts, source.transcript(context.Background(), mention.ID, item, &budget))
+	}
+	assert.Equal(t, []string{"We moved our inference to KAITO on Azure Kubernetes Service.", ""}, transcripts)
+
+	// Cached transcripts are reused without spending the budget
+	mention := source.convertEpisode(feed, feed.Items[0], time.Now().Add(-24*time.Hour))
+	assert.NotEmpty(t, source.transcript(context.Background(), mention.ID, feed.Items[0], &budget))
+	assert.Equal(t, 1, transcriptions)
+}
+
+func TestPodcastSource_transcriptExcerpt(t *testing.T) {
+	source := NewPodcastSource(nil)
+	transcript := strings.Repeat("filler words here ", 100) + "then we talked about AKS upgrades " + strings.Repeat("and more ", 300)
+
+	excerpt := source.transcriptExcerpt(transcript, []string{"AKS"})
+	assert.True(t, strings.HasPrefix(excerpt, "..."))
+	assert.True(t, strings.HasSuffix(excerpt, "..."))
+	assert.Contains(t, excerpt, "then we talked about AKS upgrades")
+	assert.LessOrEqual(t, len(excerpt), podcastTranscriptExcerpt+6)
+}
+
+func TestCaptionText(t *testing.T) {
+	assert.Equal(t, "Hello there. General Kenobi.", captionText("1\r\n00:00:01,000 --> 00:00:02,000\r\nHello there.\r\n\r\n2\r\n00:00:02,000 --> 00:00:03,500\r\nGeneral Kenobi.\r\n"))
+	assert.Equal(t, "Plain text transcript", captionText("Plain text transcript"))
+}
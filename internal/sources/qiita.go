@@ -0,0 +1,155 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// qiitaItemsURL is the Qiita API v2 article search endpoint
+	qiitaItemsURL = "https://qiita.com/api/v2/items"
+	// qiitaMaxContent caps the article body stored per mention
+	qiitaMaxContent = 4000
+)
+
+// QiitaSource searches articles on Qiita, the largest Japanese developer community. The API
+// works without a token at 60 requests an hour; an access token raises that to 1,000.
+type QiitaSource struct {
+	accessToken string
+	itemsURL    string
+	client      *resty.Client
+	queries     *QueryBuilder
+}
+
+type qiitaItem struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body"` // Markdown
+	URL           string    `json:"url"`
+	CreatedAt     time.Time `json:"created_at"`
+	LikesCount    int       `json:"likes_count"`
+	CommentsCount int       `json:"comments_count"`
+	User          struct {
+		ID string `json:"id"`
+	} `json:"user"`
+}
+
+// NewQiitaSource creates a Qiita source, with an optional access token
+func NewQiitaSource(accessToken string) *QiitaSource {
+	return &QiitaSource{
+		accessToken: accessToken,
+		itemsURL:    qiitaItemsURL,
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		queries: NewQueryBuilder(nil),
+	}
+}
+
+// WithQueries searches each keyword's aliases too, from the shared keyword templates
+func (q *QiitaSource) WithQueries(queries *QueryBuilder) *QiitaSource {
+	q.queries = queries
+	return q
+}
+
+func (q *QiitaSource) GetName() string {
+	return "qiita"
+}
+
+func (q *QiitaSource) IsEnabled() bool {
+	return true
+}
+
+func (q *QiitaSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	cutoff := time.Now().Add(-since)
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, query := range q.queries.Plan(keywords) {
+		for _, name := range queryNames(query) {
+			items, err := q.search(ctx, name, cutoff)
+			if err != nil {
+				logrus.Errorf("Failed to search Qiita for keyword '%s': %v", name, err)
+				continue
+			}
+
+			for _, item := range items {
+				if item.CreatedAt.Before(cutoff) || seen[item.ID] {
+					continue
+				}
+				// Search matches words anywhere, including code blocks; apply the template's context
+				if !q.queries.Matches(query, item.Title+" "+item.Body) {
+					continue
+				}
+				seen[item.ID] = true
+				mention := q.convertItem(item)
+				mention.Keywords = []string{query.Keyword}
+				allMentions = append(allMentions, mention)
+			}
+		}
+	}
+
+	logrus.Infof("Qiita: Total mentions found: %d", len(allMentions))
+	return allMentions, nil
+}
+
+// search returns the articles created since cutoff that mention term
+func (q *QiitaSource) search(ctx context.Context, term string, cutoff time.Time) ([]qiitaItem, error) {
+	request := q.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"query":    fmt.Sprintf(`"%s" created:>=%s`, term, cutoff.UTC().Format("2006-01-02")),
+			"per_page": "100",
+		})
+	if q.accessToken != "" {
+		request.SetAuthToken(q.accessToken)
+	}
+
+	resp, err := request.Get(q.itemsURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == 403 || resp.StatusCode() == 429 {
+		logrus.Warnf("Qiita API rate limit hit for keyword '%s' - skipping", term)
+		return nil, nil
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("Qiita API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var items []qiitaItem
+	if err := json.Unmarshal(resp.Body(), &items); err != nil {
+		return nil, fmt.Errorf("failed to parse Qiita response: %w", err)
+	}
+	return items, nil
+}
+
+func (q *QiitaSource) convertItem(item qiitaItem) models.Mention {
+	content := strings.TrimSpace(item.Body)
+	if runes := []rune(content); len(runes) > qiitaMaxContent {
+		content = string(runes[:qiitaMaxContent])
+	}
+	author := item.User.ID
+	if author == "" {
+		author = "Qiita User"
+	}
+	return models.Mention{
+		ID:           "qiita_" + item.ID,
+		Source:       "qiita",
+		Platform:     "Qiita",
+		Title:        item.Title,
+		Content:      content,
+		Author:       author,
+		URL:          item.URL,
+		CreatedAt:    item.CreatedAt,
+		Score:        item.LikesCount,
+		CommentCount: item.CommentsCount,
+	}
+}
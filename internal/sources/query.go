@@ -0,0 +1,253 @@
+package sources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/azure/aks-mentions-bot/internal/matching"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultKeywordQueries are the built-in query templates for the product keywords, keyed by
+// lowercase keyword. Terms are the aliases searched and matched with the keyword. Keywords
+// without a template are searched on their own.
+var defaultKeywordQueries = map[string]models.KeywordQuery{
+	"aks": {
+		Terms:   []string{"Azure Kubernetes Service", "azure k8s", "aks cluster"},
+		Context: []string{"azure", "kubernetes", "microsoft", "container", "k8s"},
+		// AKS is also a rifle family
+		Exclude: []string{"rifle", "gun", "weapon", "firearm", "AK47"},
+	},
+	"azure kubernetes fleet manager": {
+		Terms:   []string{"KubeFleet", "kube fleet", "fleet manager for aks"},
+		Context: []string{"azure", "kubernetes"},
+	},
+	"fleet manager": {
+		Terms:   []string{"KubeFleet", "fleet manager for aks", "Azure Kubernetes Fleet Manager"},
+		Context: []string{"azure", "kubernetes"},
+	},
+	"kubefleet": {
+		Terms:   []string{"Azure Kubernetes Fleet Manager", "kube fleet", "fleet manager for aks"},
+		Context: []string{"azure", "kubernetes"},
+	},
+	"kaito": {
+		Context: []string{"kubernetes", "k8s", "azure", "AI inference"},
+	},
+	"azure container service": {
+		Context: []string{"azure", "container", "microsoft"},
+	},
+}
+
+// QueryBuilder resolves the query template for each keyword. Sources render the templates
+// in their own search syntax.
+type QueryBuilder struct {
+	templates map[string]models.KeywordQuery
+	patterns  map[string][]*matching.Pattern // Compiled template patterns, keyed by lowercase keyword
+}
+
+// NewQueryBuilder creates a query builder from the built-in templates and the configured
+// overrides, keyed by lowercase keyword. Each option an override sets (non-nil) replaces
+// the built-in one, so an empty list clears it.
+func NewQueryBuilder(overrides map[string]models.KeywordQuery) *QueryBuilder {
+	templates := make(map[string]models.KeywordQuery, len(defaultKeywordQueries)+len(overrides))
+	for keyword, template := range defaultKeywordQueries {
+		templates[keyword] = template
+	}
+	for keyword, override := range overrides {
+		keyword = strings.ToLower(keyword)
+		template := templates[keyword]
+		if override.Terms != nil {
+			template.Terms = override.Terms
+		}
+		if override.Context != nil {
+			template.Context = override.Context
+		}
+		if override.Exclude != nil {
+			template.Exclude = override.Exclude
+		}
+		if override.Patterns != nil {
+			template.Patterns = override.Patterns
+		}
+		templates[keyword] = template
+	}
+
+	// Patterns are validated with the configuration, so one that doesn't compile here only
+	// comes from code building overrides directly
+	patterns := make(map[string][]*matching.Pattern)
+	for keyword, template := range templates {
+		for _, expr := range template.Patterns {
+			pattern, err := matching.Compile(expr)
+			if err != nil {
+				logrus.Warnf("Ignoring pattern for keyword %q: %v", keyword, err)
+				continue
+			}
+			patterns[keyword] = append(patterns[keyword], pattern)
+		}
+	}
+	return &QueryBuilder{templates: templates, patterns: patterns}
+}
+
+// Template returns the query template for a keyword
+func (b *QueryBuilder) Template(keyword string) models.KeywordQuery {
+	template := b.templates[strings.ToLower(keyword)]
+	template.Keyword = keyword
+	return template
+}
+
+// Plan returns the queries for the keywords, leaving out keywords another keyword's query
+// already searches as one of its terms. When two keywords cover each other the first is kept.
+func (b *QueryBuilder) Plan(keywords []string) []models.KeywordQuery {
+	templates := make([]models.KeywordQuery, len(keywords))
+	coveredByOther := make([]bool, len(keywords))
+	for i, keyword := range keywords {
+		templates[i] = b.Template(keyword)
+	}
+	for i, keyword := range keywords {
+		for j, other := range templates {
+			if i != j && hasTerm(other, keyword) {
+				coveredByOther[i] = true
+			}
+		}
+	}
+
+	// Keywords nobody else covers are always searched; covered ones only if still missing
+	searched := make(map[string]bool)
+	for i, template := range templates {
+		if !coveredByOther[i] {
+			markSearched(searched, template)
+		}
+	}
+
+	var plan []models.KeywordQuery
+	for i, template := range templates {
+		if coveredByOther[i] {
+			if searched[strings.ToLower(template.Keyword)] {
+				continue
+			}
+			markSearched(searched, template)
+		}
+		plan = append(plan, template)
+	}
+	return plan
+}
+
+func markSearched(searched map[string]bool, query models.KeywordQuery) {
+	searched[strings.ToLower(query.Keyword)] = true
+	for _, term := range query.Terms {
+		searched[strings.ToLower(term)] = true
+	}
+}
+
+func hasTerm(query models.KeywordQuery, keyword string) bool {
+	for _, term := range query.Terms {
+		if strings.EqualFold(term, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the keywords whose query matches text
+func (b *QueryBuilder) Match(keywords []string, text string) []string {
+	prepared := matching.NewText(text)
+	var matched []string
+	for _, keyword := range keywords {
+		if b.matches(b.Template(keyword), prepared) {
+			matched = append(matched, keyword)
+		}
+	}
+	return matched
+}
+
+// Matches reports whether text matches a query: one of the keyword's patterns when it has
+// any, otherwise the keyword or one of its terms as whole words, so "AKS clusters" matches
+// "aks cluster" but "breaks" doesn't match "AKS"
+func (b *QueryBuilder) Matches(query models.KeywordQuery, text string) bool {
+	return b.matches(query, matching.NewText(text))
+}
+
+func (b *QueryBuilder) matches(query models.KeywordQuery, text *matching.Text) bool {
+	if patterns := b.patterns[strings.ToLower(query.Keyword)]; len(patterns) > 0 {
+		for _, pattern := range patterns {
+			if pattern.Match(text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, name := range queryNames(query) {
+		if text.ContainsPhrase(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryNames returns the keyword followed by its terms, every name a search has to cover
+func queryNames(query models.KeywordQuery) []string {
+	return append([]string{query.Keyword}, query.Terms...)
+}
+
+// twitterQuery renders a template in X search syntax, e.g.
+// ("AKS" OR "Azure Kubernetes Service") (azure OR kubernetes) -rifle
+func twitterQuery(query models.KeywordQuery) string {
+	names := quoteAll(queryNames(query), true)
+
+	var parts []string
+	if len(names) == 1 {
+		parts = append(parts, names[0])
+	} else {
+		parts = append(parts, "("+strings.Join(names, " OR ")+")")
+	}
+	if len(query.Context) > 0 {
+		parts = append(parts, "("+strings.Join(quoteAll(query.Context, false), " OR ")+")")
+	}
+	for _, exclude := range quoteAll(query.Exclude, false) {
+		parts = append(parts, "-"+exclude)
+	}
+	return strings.Join(parts, " ")
+}
+
+// redditQuery renders a template in Reddit search syntax. Context is only required for
+// site-wide searches; the configured subreddits are already about Kubernetes or Azure.
+func redditQuery(query models.KeywordQuery, requireContext bool) string {
+	parts := []string{query.Keyword}
+	if len(query.Terms) > 0 {
+		names := quoteAll(queryNames(query), false)
+		parts[0] = "(" + strings.Join(names, " OR ") + ")"
+	}
+	if requireContext && len(query.Context) > 0 {
+		parts = append(parts, "AND ("+strings.Join(quoteAll(query.Context, false), " OR ")+")")
+	}
+	for _, exclude := range quoteAll(query.Exclude, false) {
+		parts = append(parts, "NOT "+exclude)
+	}
+	return strings.Join(parts, " ")
+}
+
+// youTubeQuery renders a template in YouTube search syntax, which has OR (|) and
+// exclusion (-) but no grouping, so context is left to the relevance filter
+func youTubeQuery(query models.KeywordQuery) string {
+	parts := []string{query.Keyword}
+	if len(query.Terms) > 0 {
+		parts[0] = strings.Join(quoteAll(queryNames(query), false), "|")
+	}
+	for _, exclude := range quoteAll(query.Exclude, false) {
+		parts = append(parts, "-"+exclude)
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteAll quotes every phrase, or only multi-word phrases unless always is set
+func quoteAll(phrases []string, always bool) []string {
+	quoted := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		if always || strings.Contains(phrase, " ") {
+			phrase = fmt.Sprintf("%q", phrase)
+		}
+		quoted = append(quoted, phrase)
+	}
+	return quoted
+}
@@ -0,0 +1,105 @@
+package sources
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func planKeywords(plan []models.KeywordQuery) []string {
+	keywords := make([]string, 0, len(plan))
+	for _, query := range plan {
+		keywords = append(keywords, query.Keyword)
+	}
+	return keywords
+}
+
+func TestQueryBuilder_Plan(t *testing.T) {
+	builder := NewQueryBuilder(nil)
+
+	// Azure Kubernetes Service is one of the AKS terms wherever it appears in the list
+	assert.Equal(t, []string{"AKS", "KAITO"}, planKeywords(builder.Plan([]string{"Azure Kubernetes Service", "AKS", "KAITO"})))
+
+	// Fleet Manager and KubeFleet cover each other, so only the first is searched
+	assert.Equal(t, []string{"KubeFleet"}, planKeywords(builder.Plan([]string{"KubeFleet", "Azure Kubernetes Fleet Manager"})))
+
+	// Without AKS, Azure Kubernetes Service is searched on its own
+	assert.Equal(t, []string{"Azure Kubernetes Service"}, planKeywords(builder.Plan([]string{"Azure Kubernetes Service"})))
+}
+
+func TestQueryBuilder_overrides(t *testing.T) {
+	builder := NewQueryBuilder(map[string]models.KeywordQuery{
+		"aks":         {Exclude: []string{}},
+		"azure arc":   {Context: []string{"kubernetes"}},
+		"kaito":       {Terms: []string{"Kubernetes AI Toolchain Operator"}},
+		"not-touched": {},
+	})
+
+	aks := builder.Template("AKS")
+	assert.Equal(t, []string{"Azure Kubernetes Service", "azure k8s", "aks cluster"}, aks.Terms, "options an override leaves unset keep the built-in values")
+	assert.Empty(t, aks.Exclude)
+
+	assert.Equal(t, `"Azure Arc" (kubernetes)`, twitterQuery(builder.Template("Azure Arc")))
+	assert.Equal(t, `("KAITO" OR "Kubernetes AI Toolchain Operator") (kubernetes OR k8s OR azure OR "AI inference")`, twitterQuery(builder.Template("KAITO")))
+}
+
+func TestQuerySyntax(t *testing.T) {
+	builder := NewQueryBuilder(nil)
+	aks := builder.Template("AKS")
+
+	assert.Equal(t, `(AKS OR "Azure Kubernetes Service" OR "azure k8s" OR "aks cluster") NOT rifle NOT gun NOT weapon NOT firearm NOT AK47`, redditQuery(aks, false))
+	assert.Equal(t, `(AKS OR "Azure Kubernetes Service" OR "azure k8s" OR "aks cluster") AND (azure OR kubernetes OR microsoft OR container OR k8s) NOT rifle NOT gun NOT weapon NOT firearm NOT AK47`, redditQuery(aks, true))
+	assert.Equal(t, `AKS|"Azure Kubernetes Service"|"azure k8s"|"aks cluster" -rifle -gun -weapon -firearm -AK47`, youTubeQuery(aks))
+
+	// Keywords without a template are searched exactly as before
+	other := builder.Template("azure linux")
+	assert.Equal(t, "azure linux", redditQuery(other, true))
+	assert.Equal(t, "azure linux", youTubeQuery(other))
+
+	assert.True(t, builder.Matches(aks, "Moving to azure kubernetes service"))
+	assert.True(t, builder.Matches(aks, "our aks nodes"))
+	assert.False(t, builder.Matches(aks, "EKS only"))
+}
+
+func TestQueryBuilder_Match(t *testing.T) {
+	builder := NewQueryBuilder(nil)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"keyword", "Upgrading AKS today", []string{"AKS"}},
+		{"alias", "Our Azure K8s bill doubled", []string{"AKS"}},
+		{"plural alias", "Three AKS clusters went down", []string{"AKS"}},
+		{"possessive", "The cluster's autoscaler and AKS's node pools", []string{"AKS"}},
+		{"fleet alias", "Trying kubefleet for multi-cluster rollouts", []string{"Fleet Manager"}},
+		{"inflected fleet alias", "Fleet managers for AKS compared", []string{"AKS", "Fleet Manager"}},
+		{"inside another word", "The build breaks on oaks", nil},
+		{"rifle", "AK-47 review", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, builder.Match([]string{"AKS", "Fleet Manager"}, tt.text))
+		})
+	}
+}
+
+func TestQueryBuilder_Patterns(t *testing.T) {
+	builder := NewQueryBuilder(map[string]models.KeywordQuery{
+		"kaito": {Keyword: "KAITO", Patterns: []string{`/\bkaito\b.{0,80}(kubernetes|operator)/`, "kaito NEAR/3 inference"}},
+	})
+	kaito := builder.Template("KAITO")
+
+	// Patterns replace name matching, so the keyword alone is not enough
+	assert.False(t, builder.Matches(kaito, "Kaito Tanaka wins the match"))
+	assert.True(t, builder.Matches(kaito, "Deploying KAITO on our Kubernetes cluster"))
+	assert.True(t, builder.Matches(kaito, "Cheaper inference with the new kaito release"))
+	assert.False(t, builder.Matches(kaito, "Kaito says the new model has much better inference"))
+	assert.Equal(t, []string{"KAITO"}, builder.Match([]string{"AKS", "KAITO"}, "kaito operator 0.4 is out"))
+
+	// Keywords without patterns still match their names
+	assert.True(t, builder.Matches(builder.Template("AKS"), "our aks nodes"))
+}
@@ -19,6 +19,20 @@ type RedditSource struct {
 	clientSecret string
 	client       *resty.Client
 	accessToken  string
+	subreddits   []string
+	discovered   func() []string // Subreddits promoted by discovery; nil disables site-wide search
+	queries      *QueryBuilder
+}
+
+// defaultSubreddits are the subreddits relevant to Kubernetes/Azure searched by default
+var defaultSubreddits = []string{
+	"kubernetes",
+	"azure",
+	"devops",
+	"docker",
+	"cloudcomputing",
+	"sysadmin",
+	"programming",
 }
 
 type redditAuthResponse struct {
@@ -54,7 +68,30 @@ func NewRedditSource(clientID, clientSecret string) *RedditSource {
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		client:       resty.New().SetTimeout(30 * time.Second),
+		subreddits:   defaultSubreddits,
+		queries:      NewQueryBuilder(nil),
+	}
+}
+
+// WithQueries sets the keyword query templates searches are built from
+func (r *RedditSource) WithQueries(queries *QueryBuilder) *RedditSource {
+	r.queries = queries
+	return r
+}
+
+// WithSubreddits overrides the subreddits searched; an empty list keeps the defaults
+func (r *RedditSource) WithSubreddits(subreddits []string) *RedditSource {
+	if cleaned := cleanList(subreddits); len(cleaned) > 0 {
+		r.subreddits = cleaned
 	}
+	return r
+}
+
+// WithDiscovery adds a site-wide search for each keyword, so posts outside the configured
+// subreddits are found, and searches the subreddits returned by discovered on every fetch
+func (r *RedditSource) WithDiscovery(discovered func() []string) *RedditSource {
+	r.discovered = discovered
+	return r
 }
 
 func (r *RedditSource) GetName() string {
@@ -76,14 +113,25 @@ func (r *RedditSource) FetchMentions(ctx context.Context, keywords []string, sin
 	}
 
 	var allMentions []models.Mention
-	
-	for _, keyword := range keywords {
-		mentions, err := r.searchKeyword(ctx, keyword, since)
+	subreddits := r.rotation()
+
+	for _, query := range r.queries.Plan(keywords) {
+		keyword := query.Keyword
+		mentions, err := r.searchKeyword(ctx, subreddits, query, since)
 		if err != nil {
 			logrus.Errorf("Failed to search Reddit for keyword '%s': %v", keyword, err)
 			continue
 		}
 		allMentions = append(allMentions, mentions...)
+
+		if r.discovered != nil {
+			mentions, err := r.searchSiteWide(ctx, query, since)
+			if err != nil {
+				logrus.Errorf("Failed to search all of Reddit for keyword '%s': %v", keyword, err)
+				continue
+			}
+			allMentions = append(allMentions, mentions...)
+		}
 	}
 
 	return r.deduplicateMentions(allMentions), nil
@@ -111,22 +159,32 @@ func (r *RedditSource) authenticate() error {
 	return nil
 }
 
-func (r *RedditSource) searchKeyword(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	// Search multiple subreddits relevant to Kubernetes/Azure
-	subreddits := []string{
-		"kubernetes",
-		"azure",
-		"devops",
-		"docker",
-		"cloudcomputing",
-		"sysadmin",
-		"programming",
+// rotation returns the configured subreddits followed by any discovered ones
+func (r *RedditSource) rotation() []string {
+	subreddits := append([]string{}, r.subreddits...)
+	if r.discovered == nil {
+		return subreddits
 	}
 
+	seen := make(map[string]bool)
+	for _, subreddit := range subreddits {
+		seen[strings.ToLower(subreddit)] = true
+	}
+	for _, subreddit := range r.discovered() {
+		if !seen[strings.ToLower(subreddit)] {
+			seen[strings.ToLower(subreddit)] = true
+			subreddits = append(subreddits, subreddit)
+		}
+	}
+	return subreddits
+}
+
+func (r *RedditSource) searchKeyword(ctx context.Context, subreddits []string, query models.KeywordQuery, since time.Duration) ([]models.Mention, error) {
+	// Search multiple subreddits relevant to Kubernetes/Azure
 	var allMentions []models.Mention
 
 	for _, subreddit := range subreddits {
-		mentions, err := r.searchSubreddit(ctx, subreddit, keyword, since)
+		mentions, err := r.searchSubreddit(ctx, subreddit, query, since)
 		if err != nil {
 			logrus.Errorf("Failed to search subreddit %s: %v", subreddit, err)
 			continue
@@ -137,11 +195,20 @@ func (r *RedditSource) searchKeyword(ctx context.Context, keyword string, since
 	return allMentions, nil
 }
 
-func (r *RedditSource) searchSubreddit(ctx context.Context, subreddit, keyword string, since time.Duration) ([]models.Mention, error) {
-	// Build search query
-	query := url.QueryEscape(keyword)
-	searchURL := fmt.Sprintf("https://oauth.reddit.com/r/%s/search.json?q=%s&restrict_sr=1&sort=new&limit=100", subreddit, query)
+func (r *RedditSource) searchSubreddit(ctx context.Context, subreddit string, query models.KeywordQuery, since time.Duration) ([]models.Mention, error) {
+	searchURL := fmt.Sprintf("https://oauth.reddit.com/r/%s/search.json?q=%s&restrict_sr=1&sort=new&limit=100", subreddit, url.QueryEscape(redditQuery(query, false)))
+
+	return r.search(ctx, searchURL, query, since)
+}
+
+// searchSiteWide searches every subreddit, which is how new subreddits are discovered
+func (r *RedditSource) searchSiteWide(ctx context.Context, query models.KeywordQuery, since time.Duration) ([]models.Mention, error) {
+	searchURL := fmt.Sprintf("https://oauth.reddit.com/search.json?q=%s&sort=new&limit=100", url.QueryEscape(redditQuery(query, true)))
+
+	return r.search(ctx, searchURL, query, since)
+}
 
+func (r *RedditSource) search(ctx context.Context, searchURL string, query models.KeywordQuery, since time.Duration) ([]models.Mention, error) {
 	resp, err := r.client.R().
 		SetContext(ctx).
 		SetHeader("Authorization", "Bearer "+r.accessToken).
@@ -173,9 +240,8 @@ func (r *RedditSource) searchSubreddit(ctx context.Context, subreddit, keyword s
 			continue
 		}
 
-		// Check if the post content contains our keyword (case-insensitive)
-		content := strings.ToLower(post.Title + " " + post.Selftext)
-		if !strings.Contains(content, strings.ToLower(keyword)) {
+		// Check if the post content contains our keyword or one of its terms (case-insensitive)
+		if !r.queries.Matches(query, post.Title+" "+post.Selftext) {
 			continue
 		}
 
@@ -186,11 +252,12 @@ func (r *RedditSource) searchSubreddit(ctx context.Context, subreddit, keyword s
 			Title:        post.Title,
 			Content:      post.Selftext,
 			Author:       post.Author,
+			Channel:      post.Subreddit,
 			URL:          fmt.Sprintf("https://reddit.com%s", post.Permalink),
 			CreatedAt:    createdAt,
 			Score:        post.Score,
 			CommentCount: post.NumComments,
-			Keywords:     []string{keyword},
+			Keywords:     []string{query.Keyword},
 		}
 
 		mentions = append(mentions, mention)
@@ -199,6 +266,50 @@ func (r *RedditSource) searchSubreddit(ctx context.Context, subreddit, keyword s
 	return mentions, nil
 }
 
+// CheckAnswers looks up the comment count of earlier posts, up to 100 per request
+func (r *RedditSource) CheckAnswers(ctx context.Context, mentionIDs []string) (map[string]AnswerState, error) {
+	var fullnames []string
+	for _, id := range mentionIDs {
+		if postID, ok := strings.CutPrefix(id, "reddit_"); ok {
+			fullnames = append(fullnames, "t3_"+postID)
+		}
+	}
+	if len(fullnames) == 0 || !r.IsEnabled() {
+		return nil, nil
+	}
+
+	if err := r.authenticate(); err != nil {
+		return nil, fmt.Errorf("reddit authentication failed: %w", err)
+	}
+
+	states := make(map[string]AnswerState)
+	for start := 0; start < len(fullnames); start += 100 {
+		batch := fullnames[start:min(start+100, len(fullnames))]
+		resp, err := r.client.R().
+			SetContext(ctx).
+			SetHeader("Authorization", "Bearer "+r.accessToken).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0").
+			Get("https://oauth.reddit.com/api/info?id=" + url.QueryEscape(strings.Join(batch, ",")))
+		if err != nil {
+			return states, err
+		}
+		if resp.StatusCode() != 200 {
+			return states, fmt.Errorf("reddit API returned status %d", resp.StatusCode())
+		}
+
+		var infoResp redditSearchResponse
+		if err := json.Unmarshal(resp.Body(), &infoResp); err != nil {
+			return states, err
+		}
+
+		for _, child := range infoResp.Data.Children {
+			states["reddit_"+child.Data.ID] = AnswerState{Answers: child.Data.NumComments}
+		}
+	}
+
+	return states, nil
+}
+
 func (r *RedditSource) deduplicateMentions(mentions []models.Mention) []models.Mention {
 	seen := make(map[string]bool)
 	var unique []models.Mention
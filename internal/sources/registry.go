@@ -0,0 +1,252 @@
+package sources
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
+	"github.com/sirupsen/logrus"
+)
+
+// Options carries what source factories need beyond the configuration
+type Options struct {
+	Queries              *QueryBuilder   // Keyword query templates shared by the search sources
+	DiscoveredSubreddits func() []string // Subreddits promoted by discovery, for Reddit's site-wide search
+
+	// Converts the HTML of Stack Exchange, Hacker News, Medium and YouTube content to text, nil
+	// for the configured CONTENT_FORMAT
+	Sanitizer *sanitize.Sanitizer
+
+	// Persists the Twitter search schedule between runs, nil to keep it in memory
+	TwitterSchedule TwitterScheduleStore
+
+	// Mentions submitted through the API, nil to leave the submitted source disabled
+	Submissions SubmissionStore
+
+	// Response cache for the feed-style sources (Stack Overflow, Hacker News, Medium, podcasts and Chinese community feeds), nil
+	// to send their requests directly
+	HTTPCache http.RoundTripper
+}
+
+// Factory builds a source from the configuration
+type Factory func(cfg *config.Config, opts Options) Source
+
+type registration struct {
+	name    string
+	factory Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registration
+)
+
+// Register makes a source available under its name. Sources outside this package, such as
+// private plugins, register from an init function; it panics if the name is already taken.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("sources: Register factory is nil for " + name)
+	}
+	for _, existing := range registry {
+		if existing.name == name {
+			panic("sources: Register called twice for " + name)
+		}
+	}
+	registry = append(registry, registration{name: name, factory: factory})
+	config.RegisterSourceName(name)
+}
+
+// Names returns the registered source names in registration order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for _, registered := range registry {
+		names = append(names, registered.name)
+	}
+	return names
+}
+
+// Build creates the registered sources the configuration enables, in registration order.
+// With SOURCE_FIXTURES set, it replays the recorded fixtures instead, or records the sources.
+// It returns an error if a factory builds a source named differently from its registration.
+func Build(cfg *config.Config, opts Options) ([]Source, error) {
+	if cfg.SourceFixtures != "" && cfg.SourceFixtureMode != config.FixtureModeRecord {
+		return replaySources(cfg), nil
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if opts.Queries == nil {
+		opts.Queries = NewQueryBuilder(cfg.KeywordQueries)
+	}
+	if opts.Sanitizer == nil {
+		opts.Sanitizer = sanitize.New().WithMarkdown(cfg.ContentFormat != config.ContentFormatText)
+	}
+
+	if cfg.BingSearchAPIKey != "" && strings.TrimRight(cfg.BingSearchEndpoint, "/") == defaultBingEndpoint {
+		logrus.Warn("The Bing Search APIs were retired on August 11, 2025: the LinkedIn and web sources and Medium's site search find nothing unless BING_SEARCH_ENDPOINT points to a service with the same API")
+	}
+
+	var built []Source
+	for _, registered := range registry {
+		if !cfg.SourceEnabled(registered.name) {
+			logrus.Infof("Source %s disabled by configuration", registered.name)
+			continue
+		}
+		source := registered.factory(cfg, opts)
+		if source.GetName() != registered.name {
+			return nil, fmt.Errorf("source registered as %s is named %s", registered.name, source.GetName())
+		}
+		built = append(built, source)
+	}
+	if cfg.SourceFixtures != "" {
+		return recordSources(cfg, built), nil
+	}
+	return built, nil
+}
+
+func init() {
+	Register("reddit", func(cfg *config.Config, opts Options) Source {
+		reddit := NewRedditSource(cfg.RedditClientID, cfg.RedditClientSecret).
+			WithSubreddits(cfg.RedditSubreddits).
+			WithQueries(opts.Queries)
+		if cfg.RedditDiscovery && opts.DiscoveredSubreddits != nil {
+			reddit.WithDiscovery(opts.DiscoveredSubreddits)
+		}
+		return reddit
+	})
+	Register("stackoverflow", func(cfg *config.Config, opts Options) Source {
+		stackOverflow := NewStackOverflowSource().
+			WithTags(cfg.StackOverflowTags).
+			WithSites(cfg.StackExchangeSites).
+			WithQueries(opts.Queries).
+			WithSanitizer(opts.Sanitizer)
+		if opts.HTTPCache != nil {
+			stackOverflow.WithTransport(opts.HTTPCache)
+		}
+		return stackOverflow
+	})
+	Register("hackernews", func(cfg *config.Config, opts Options) Source {
+		hackerNews := NewHackerNewsSource().
+			WithItemLimit(cfg.HackerNewsItemLimit).
+			WithQueries(opts.Queries).
+			WithSanitizer(opts.Sanitizer)
+		if opts.HTTPCache != nil {
+			hackerNews.WithTransport(opts.HTTPCache)
+		}
+		return hackerNews
+	})
+	Register("twitter", func(cfg *config.Config, opts Options) Source {
+		twitter := NewTwitterSource(cfg.TwitterBearerToken).
+			WithQueries(opts.Queries).
+			WithBudget(cfg.TwitterRequestsPerWindow, cfg.TwitterMaxPages, cfg.TwitterMaxWait)
+		if opts.TwitterSchedule != nil {
+			twitter.WithScheduleStore(opts.TwitterSchedule)
+		}
+		return twitter
+	})
+	Register("threads", func(cfg *config.Config, opts Options) Source {
+		return NewThreadsSource(cfg.ThreadsAccessToken).WithQueries(opts.Queries)
+	})
+	Register("youtube", func(cfg *config.Config, opts Options) Source {
+		// Without comment channels, scan the uploads of the trusted channels given by ID
+		commentChannels := cfg.YouTubeCommentChannels
+		if len(commentChannels) == 0 {
+			for _, channel := range cfg.YouTubeTrustedChannels {
+				if channel = strings.TrimSpace(channel); channel != "" {
+					commentChannels = append(commentChannels, channel)
+				}
+			}
+		}
+		return NewYouTubeSource(cfg.YouTubeAPIKey).
+			WithMaxResults(cfg.YouTubeMaxResults).
+			WithQueries(opts.Queries).
+			WithTrustedChannels(cfg.YouTubeTrustedChannels).
+			WithVideoFilters(cfg.YouTubeExcludeShorts, cfg.YouTubeExcludeNonEnglishGaming).
+			WithCommentScanning(commentChannels, cfg.YouTubeCommentVideos).
+			WithSanitizer(opts.Sanitizer)
+	})
+	Register("medium", func(cfg *config.Config, opts Options) Source {
+		medium := NewMediumSource().
+			WithTags(cfg.MediumTags).
+			WithPublications(cfg.MediumPublications).
+			WithAuthors(cfg.MediumAuthors).
+			WithQueries(opts.Queries).
+			WithSanitizer(opts.Sanitizer).
+			WithWebSearch(webSearch(cfg))
+		if opts.HTTPCache != nil {
+			medium.WithTransport(opts.HTTPCache)
+		}
+		return medium
+	})
+	Register("linkedin", func(cfg *config.Config, opts Options) Source {
+		return NewLinkedInSource(webSearch(cfg)).WithQueries(opts.Queries)
+	})
+	Register("linkedinpage", func(cfg *config.Config, opts Options) Source {
+		return NewLinkedInPageSource(cfg.LinkedInAccessToken, cfg.LinkedInOrganizationID).
+			WithPageName(cfg.LinkedInPageName).
+			WithMaxPosts(cfg.LinkedInPageMaxPosts).
+			WithAPIVersion(cfg.LinkedInAPIVersion).
+			WithQueries(opts.Queries)
+	})
+	Register("cve", func(cfg *config.Config, opts Options) Source {
+		return NewCVESource(cfg.NVDAPIKey).WithTerms(cfg.CVETerms)
+	})
+	Register("gitlab", func(cfg *config.Config, opts Options) Source {
+		return NewGitLabSource(cfg.GitLabToken).
+			WithBaseURL(cfg.GitLabURL).
+			WithQueries(opts.Queries)
+	})
+	Register("github", func(cfg *config.Config, opts Options) Source {
+		return NewGitHubSource(cfg.GitHubToken).WithTerms(cfg.GitHubTerms)
+	})
+	Register("bitbucket", func(cfg *config.Config, opts Options) Source {
+		return NewBitbucketSource(cfg.BitbucketToken).
+			WithRepositories(cfg.BitbucketRepositories).
+			WithQueries(opts.Queries)
+	})
+	Register("web", func(cfg *config.Config, opts Options) Source {
+		return NewWebSource(webSearch(cfg)).
+			WithQueries(opts.Queries).
+			WithMaxResultsPerDomain(cfg.BingMaxResultsPerDomain)
+	})
+	Register("podcast", func(cfg *config.Config, opts Options) Source {
+		podcast := NewPodcastSource(cfg.PodcastFeeds).
+			WithQueries(opts.Queries).
+			WithSanitizer(opts.Sanitizer).
+			WithTranscription(NewSpeechTranscriber(cfg.SpeechEndpoint, cfg.SpeechKey).WithLocale(cfg.SpeechLocale), cfg.PodcastMaxTranscriptions)
+		if opts.HTTPCache != nil {
+			podcast.WithTransport(opts.HTTPCache)
+		}
+		return podcast
+	})
+	Register("qiita", func(cfg *config.Config, opts Options) Source {
+		return NewQiitaSource(cfg.QiitaAccessToken).WithQueries(opts.Queries)
+	})
+	Register("cnfeeds", func(cfg *config.Config, opts Options) Source {
+		cnFeeds := NewCNFeedsSource(cfg.CNFeeds).
+			WithQueries(opts.Queries).
+			WithSanitizer(opts.Sanitizer)
+		if opts.HTTPCache != nil {
+			cnFeeds.WithTransport(opts.HTTPCache)
+		}
+		return cnFeeds
+	})
+	Register(SubmittedSourceName, func(cfg *config.Config, opts Options) Source {
+		return NewSubmittedSource(opts.Submissions)
+	})
+}
+
+// webSearch creates the Bing client of the sources that search the web
+func webSearch(cfg *config.Config) *WebSearch {
+	return NewWebSearch(cfg.BingSearchAPIKey).WithEndpoint(cfg.BingSearchEndpoint)
+}
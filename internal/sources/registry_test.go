@@ -0,0 +1,62 @@
+package sources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pluginSource struct{}
+
+func (p *pluginSource) GetName() string { return "forum" }
+func (p *pluginSource) IsEnabled() bool { return true }
+func (p *pluginSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	return nil, nil
+}
+
+func TestRegistry(t *testing.T) {
+	Register("forum", func(cfg *config.Config, opts Options) Source {
+		return &pluginSource{}
+	})
+
+	assert.Equal(t, "reddit", Names()[0])
+	assert.Equal(t, "forum", Names()[len(Names())-1])
+	assert.Panics(t, func() {
+		Register("forum", func(cfg *config.Config, opts Options) Source { return &pluginSource{} })
+	})
+
+	cfg := &config.Config{Keywords: []string{"AKS"}, SourcesEnabled: map[string]bool{"reddit": false}}
+	built, err := Build(cfg, Options{})
+	require.NoError(t, err)
+	var names []string
+	for _, source := range built {
+		names = append(names, source.GetName())
+	}
+	assert.NotContains(t, names, "reddit")
+	assert.Contains(t, names, "twitter")
+	assert.Contains(t, names, "forum")
+
+	cfg.SourcesEnabled["forum"] = false
+	built, err = Build(cfg, Options{})
+	require.NoError(t, err)
+	for _, source := range built {
+		assert.NotEqual(t, "forum", source.GetName())
+	}
+
+	// A factory building a source under another name is an error, not a panic
+	Register("forum-mirror", func(cfg *config.Config, opts Options) Source {
+		return &pluginSource{}
+	})
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = registry[:len(registry)-1]
+		registryMu.Unlock()
+	})
+	_, err = Build(cfg, Options{})
+	assert.ErrorContains(t, err, "source registered as forum-mirror is named forum")
+}
@@ -0,0 +1,235 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Fixture is what a source returned while it was recorded
+type Fixture struct {
+	Source     string           `json:"source"`
+	RecordedAt time.Time        `json:"recorded_at"`
+	Mentions   []models.Mention `json:"mentions"`
+	Error      string           `json:"error,omitempty"` // Replayed as the error of every fetch
+}
+
+// FixturePath returns the fixture file of a source in a fixtures directory
+func FixturePath(dir, source string) string {
+	return filepath.Join(dir, source+".json")
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	if fixture.Source == "" {
+		fixture.Source = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &fixture, nil
+}
+
+// LoadFixtures reads every fixture file in a directory, by file name
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures in %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		fixture, err := LoadFixture(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// ReplaySource serves the mentions of a recorded fixture instead of calling an API, so the
+// pipeline can be run end to end without network access. Mentions keep the age they had when
+// they were recorded, and each fetch returns those inside its window that match its keywords.
+type ReplaySource struct {
+	fixture Fixture
+	now     func() time.Time
+}
+
+// NewReplaySource creates a source replaying the fixture under the fixture's source name
+func NewReplaySource(fixture Fixture) *ReplaySource {
+	return &ReplaySource{fixture: fixture, now: time.Now}
+}
+
+// WithClock sets the time the recorded mention ages are measured from
+func (s *ReplaySource) WithClock(now func() time.Time) *ReplaySource {
+	s.now = now
+	return s
+}
+
+func (s *ReplaySource) GetName() string { return s.fixture.Source }
+func (s *ReplaySource) IsEnabled() bool { return true }
+
+func (s *ReplaySource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.fixture.Error != "" {
+		return nil, errors.New(s.fixture.Error)
+	}
+
+	now := s.now()
+	var mentions []models.Mention
+	for _, recorded := range s.fixture.Mentions {
+		mention := recorded
+		if !s.fixture.RecordedAt.IsZero() {
+			mention.CreatedAt = now.Add(-s.fixture.RecordedAt.Sub(recorded.CreatedAt))
+		}
+		if now.Sub(mention.CreatedAt) > since {
+			continue
+		}
+		mention.Keywords = MatchKeywords(mention, keywords)
+		if len(mention.Keywords) == 0 {
+			continue
+		}
+		mentions = append(mentions, mention)
+	}
+	return mentions, nil
+}
+
+// MatchKeywords returns the keywords a stored mention matches: those it was stored with, or
+// those its title or content contains when it was stored without keywords
+func MatchKeywords(mention models.Mention, keywords []string) []string {
+	var matched []string
+	text := strings.ToLower(mention.Title + " " + mention.Content)
+	for _, keyword := range keywords {
+		if len(mention.Keywords) == 0 {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				matched = append(matched, keyword)
+			}
+			continue
+		}
+		for _, recorded := range mention.Keywords {
+			if strings.EqualFold(recorded, keyword) {
+				matched = append(matched, keyword)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// RecordingSource passes fetches through to a live source and writes what it returns to a
+// fixture file after each fetch, for ReplaySource to serve later. Mentions found by several
+// fetches are recorded once.
+type RecordingSource struct {
+	source  Source
+	path    string
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecordingSource records the fetches of a source to the fixture file at path
+func NewRecordingSource(source Source, path string) *RecordingSource {
+	return &RecordingSource{source: source, path: path, fixture: Fixture{Source: source.GetName(), Mentions: []models.Mention{}}}
+}
+
+func (s *RecordingSource) GetName() string { return s.source.GetName() }
+func (s *RecordingSource) IsEnabled() bool { return s.source.IsEnabled() }
+
+func (s *RecordingSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	mentions, err := s.source.FetchMentions(ctx, keywords, since)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := make(map[string]int, len(s.fixture.Mentions))
+	for i, mention := range s.fixture.Mentions {
+		index[mention.ID] = i
+	}
+	for _, mention := range mentions {
+		if i, ok := index[mention.ID]; ok {
+			s.fixture.Mentions[i] = mention
+			continue
+		}
+		index[mention.ID] = len(s.fixture.Mentions)
+		s.fixture.Mentions = append(s.fixture.Mentions, mention)
+	}
+	s.fixture.RecordedAt = time.Now().UTC()
+	// A failure is only replayed while nothing has been recorded, so a flaky fetch doesn't
+	// hide the mentions of earlier ones
+	s.fixture.Error = ""
+	if err != nil && len(s.fixture.Mentions) == 0 {
+		s.fixture.Error = err.Error()
+	}
+
+	if saveErr := s.save(); saveErr != nil {
+		logrus.Warnf("Failed to record %s fixture: %v", s.fixture.Source, saveErr)
+	}
+	return mentions, err
+}
+
+// CheckAnswers passes answer checks through to the live source where it supports them
+func (s *RecordingSource) CheckAnswers(ctx context.Context, mentionIDs []string) (map[string]AnswerState, error) {
+	if checker, ok := s.source.(AnswerChecker); ok {
+		return checker.CheckAnswers(ctx, mentionIDs)
+	}
+	return nil, nil
+}
+
+func (s *RecordingSource) save() error {
+	data, err := json.MarshalIndent(s.fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// recordSources wraps the built sources to record their fetches to SOURCE_FIXTURES
+func recordSources(cfg *config.Config, built []Source) []Source {
+	recording := make([]Source, 0, len(built))
+	for _, source := range built {
+		recording = append(recording, NewRecordingSource(source, FixturePath(cfg.SourceFixtures, source.GetName())))
+	}
+	logrus.Infof("Recording source fixtures to %s", cfg.SourceFixtures)
+	return recording
+}
+
+// replaySources builds a replay source for the fixture of each enabled source in SOURCE_FIXTURES
+func replaySources(cfg *config.Config) []Source {
+	fixtures, err := LoadFixtures(cfg.SourceFixtures)
+	if err != nil {
+		logrus.Errorf("Failed to load source fixtures, no sources will be searched: %v", err)
+		return nil
+	}
+
+	var replaying []Source
+	for _, fixture := range fixtures {
+		if !cfg.SourceEnabled(fixture.Source) {
+			continue
+		}
+		logrus.Infof("Replaying %d recorded %s mentions from %s", len(fixture.Mentions), fixture.Source, cfg.SourceFixtures)
+		replaying = append(replaying, NewReplaySource(*fixture))
+	}
+	return replaying
+}
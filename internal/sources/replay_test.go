@@ -0,0 +1,101 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/config"
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// liveSource stands in for an API, returning its mentions once and then failing
+type liveSource struct {
+	mentions []models.Mention
+	fetches  int
+}
+
+func (s *liveSource) GetName() string { return "reddit" }
+func (s *liveSource) IsEnabled() bool { return true }
+func (s *liveSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	s.fetches++
+	if s.fetches > 1 {
+		return nil, errors.New("reddit API returned status 401")
+	}
+	return s.mentions, nil
+}
+
+func TestReplaySource(t *testing.T) {
+	recordedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	source := NewReplaySource(Fixture{
+		Source:     "hackernews",
+		RecordedAt: recordedAt,
+		Mentions: []models.Mention{
+			{ID: "hn_1", Title: "AKS upgrade", Keywords: []string{"AKS"}, CreatedAt: recordedAt.Add(-2 * time.Hour)},
+			{ID: "hn_2", Title: "Fleet Manager GA", Keywords: []string{"Azure Kubernetes Fleet Manager"}, CreatedAt: recordedAt.Add(-3 * time.Hour)},
+			{ID: "hn_3", Title: "KAITO on AKS", Content: "running kaito", CreatedAt: recordedAt.Add(-time.Hour)},
+			{ID: "hn_4", Title: "Old AKS thread", Keywords: []string{"AKS"}, CreatedAt: recordedAt.Add(-48 * time.Hour)},
+		},
+	}).WithClock(func() time.Time { return now })
+
+	assert.Equal(t, "hackernews", source.GetName())
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"aks", "KAITO"}, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mentions, 2, "other keywords and mentions older than the window are left out")
+	assert.Equal(t, "hn_1", mentions[0].ID)
+	assert.Equal(t, []string{"aks"}, mentions[0].Keywords)
+	assert.Equal(t, now.Add(-2*time.Hour), mentions[0].CreatedAt, "mentions keep their recorded age")
+	assert.Equal(t, "hn_3", mentions[1].ID)
+	assert.Equal(t, []string{"aks", "KAITO"}, mentions[1].Keywords, "mentions recorded without keywords match their text")
+
+	failing := NewReplaySource(Fixture{Source: "reddit", Error: "reddit API returned status 401"})
+	_, err = failing.FetchMentions(context.Background(), []string{"aks"}, time.Hour)
+	assert.EqualError(t, err, "reddit API returned status 401")
+}
+
+func TestRecordingSource_replayedByBuild(t *testing.T) {
+	dir := t.TempDir()
+	live := &liveSource{mentions: []models.Mention{
+		{ID: "reddit_1", Source: "reddit", Title: "AKS networking", Keywords: []string{"AKS"}, CreatedAt: time.Now().Add(-time.Hour)},
+	}}
+
+	recording := NewRecordingSource(live, FixturePath(dir, "reddit"))
+	mentions, err := recording.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, mentions, 1)
+
+	// A failing fetch keeps the mentions recorded earlier
+	_, err = recording.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	assert.Error(t, err)
+	fixture, err := LoadFixture(FixturePath(dir, "reddit"))
+	require.NoError(t, err)
+	assert.Equal(t, "reddit", fixture.Source)
+	assert.Len(t, fixture.Mentions, 1)
+	assert.Empty(t, fixture.Error)
+
+	cfg := &config.Config{
+		SourceFixtures:    dir,
+		SourceFixtureMode: config.FixtureModeReplay,
+		SourcesEnabled:    map[string]bool{"reddit": true},
+	}
+	built, err := Build(cfg, Options{})
+	require.NoError(t, err)
+	require.Len(t, built, 1)
+	assert.IsType(t, &ReplaySource{}, built[0])
+
+	replayed, err := built[0].FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, "reddit_1", replayed[0].ID)
+	assert.Equal(t, 2, live.fetches, "replay does not call the live source")
+
+	cfg.SourcesEnabled["reddit"] = false
+	built, err = Build(cfg, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, built, "disabled sources are not replayed")
+}
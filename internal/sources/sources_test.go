@@ -1,10 +1,18 @@
 package sources
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRedditSource_GetName(t *testing.T) {
@@ -53,6 +61,23 @@ func TestRedditSource_IsEnabled(t *testing.T) {
 	}
 }
 
+func TestRedditSource_WithSubreddits(t *testing.T) {
+	source := NewRedditSource("client_id", "client_secret").WithSubreddits(nil)
+	assert.Equal(t, defaultSubreddits, source.subreddits)
+
+	source.WithSubreddits([]string{" AZURE ", "", "kubernetes"})
+	assert.Equal(t, []string{"AZURE", "kubernetes"}, source.subreddits)
+}
+
+func TestRedditSource_rotation(t *testing.T) {
+	source := NewRedditSource("client_id", "client_secret").WithSubreddits([]string{"kubernetes", "azure"})
+	assert.Equal(t, []string{"kubernetes", "azure"}, source.rotation())
+
+	source.WithDiscovery(func() []string { return []string{"AZURE", "k8s", "homelab"} })
+	assert.Equal(t, []string{"kubernetes", "azure", "k8s", "homelab"}, source.rotation())
+	assert.Equal(t, []string{"kubernetes", "azure"}, source.subreddits, "discovery does not change the configured list")
+}
+
 func TestStackOverflowSource_GetName(t *testing.T) {
 	source := NewStackOverflowSource()
 	assert.Equal(t, "stackoverflow", source.GetName())
@@ -63,42 +88,11 @@ func TestStackOverflowSource_IsEnabled(t *testing.T) {
 	assert.True(t, source.IsEnabled())
 }
 
-func TestStackOverflowSource_stripHTMLTags(t *testing.T) {
-	source := NewStackOverflowSource()
-
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Basic HTML tags",
-			input:    "<p>Hello <strong>world</strong></p>",
-			expected: "Hello world",
-		},
-		{
-			name:     "Code tags",
-			input:    "Use <code>kubectl apply</code> to deploy",
-			expected: "Use `kubectl apply` to deploy",
-		},
-		{
-			name:     "Line breaks",
-			input:    "Line 1<br>Line 2<br/>Line 3",
-			expected: "Line 1\nLine 2\nLine 3",
-		},
-		{
-			name:     "No HTML tags",
-			input:    "Plain text content",
-			expected: "Plain text content",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := source.stripHTMLTags(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
+func TestStackOverflowFromDate(t *testing.T) {
+	cutoff := time.Date(2024, 6, 3, 9, 14, 37, 0, time.UTC)
+	// Searches of the same window moments apart share a URL the HTTP cache can answer
+	assert.Equal(t, stackOverflowFromDate(cutoff), stackOverflowFromDate(cutoff.Add(5*time.Second)))
+	assert.Equal(t, time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC).Unix(), stackOverflowFromDate(cutoff))
 }
 
 func TestHackerNewsSource_GetName(t *testing.T) {
@@ -111,6 +105,21 @@ func TestHackerNewsSource_IsEnabled(t *testing.T) {
 	assert.True(t, source.IsEnabled())
 }
 
+func TestHackerNewsPostType(t *testing.T) {
+	assert.Equal(t, models.PostTypeAskHN, hackerNewsPostType(&hackerNewsItem{Type: "story", Title: "Ask HN: Is AKS reliable enough?"}))
+	assert.Equal(t, models.PostTypeShowHN, hackerNewsPostType(&hackerNewsItem{Type: "story", Title: "Show HN: AKS cost dashboard"}))
+	assert.Equal(t, models.PostTypeStory, hackerNewsPostType(&hackerNewsItem{Type: "story", Title: "AKS 1.30 released"}))
+	assert.Equal(t, models.PostTypeComment, hackerNewsPostType(&hackerNewsItem{Type: "comment", Text: "We run AKS"}))
+}
+
+func TestContainsAnyKeyword(t *testing.T) {
+	keywords := []string{"AKS", "Azure Kubernetes Service"}
+
+	assert.True(t, containsAnyKeyword("Our aks cluster is flaky", keywords))
+	assert.True(t, containsAnyKeyword("Moved to azure kubernetes service last year", keywords))
+	assert.False(t, containsAnyKeyword("We use EKS and GKE", keywords))
+}
+
 func TestTwitterSource_GetName(t *testing.T) {
 	source := NewTwitterSource("bearer_token")
 	assert.Equal(t, "twitter", source.GetName())
@@ -152,23 +161,18 @@ func TestTwitterSource_buildSearchQuery(t *testing.T) {
 	}{
 		{
 			name:     "AKS keyword",
-			keyword:  "aks",
-			expected: `"aks" (azure OR kubernetes OR microsoft OR container) -rifle -gun -weapon -firearm`,
-		},
-		{
-			name:     "Azure Kubernetes Service",
-			keyword:  "azure kubernetes service",
-			expected: `"azure kubernetes service" OR "AKS"`,
+			keyword:  "AKS",
+			expected: `("AKS" OR "Azure Kubernetes Service" OR "azure k8s" OR "aks cluster") (azure OR kubernetes OR microsoft OR container OR k8s) -rifle -gun -weapon -firearm -AK47`,
 		},
 		{
 			name:     "KubeFleet",
-			keyword:  "kubefleet",
-			expected: `"kubefleet" OR "kube fleet"`,
+			keyword:  "KubeFleet",
+			expected: `("KubeFleet" OR "Azure Kubernetes Fleet Manager" OR "kube fleet" OR "fleet manager for aks") (azure OR kubernetes)`,
 		},
 		{
 			name:     "KAITO",
 			keyword:  "kaito",
-			expected: `"kaito" (kubernetes OR k8s OR azure)`,
+			expected: `"kaito" (kubernetes OR k8s OR azure OR "AI inference")`,
 		},
 		{
 			name:     "Other keyword",
@@ -216,6 +220,14 @@ func TestYouTubeSource_IsEnabled(t *testing.T) {
 	}
 }
 
+func TestYouTubeSource_WithMaxResults(t *testing.T) {
+	source := NewYouTubeSource("api_key")
+	assert.Equal(t, 50, source.maxResults)
+
+	assert.Equal(t, 50, source.WithMaxResults(500).maxResults)
+	assert.Equal(t, 10, source.WithMaxResults(10).maxResults)
+}
+
 func TestYouTubeSource_extractVideoID(t *testing.T) {
 	source := NewYouTubeSource("api_key")
 
@@ -266,3 +278,679 @@ func TestDeduplicateMentions(t *testing.T) {
 	assert.Equal(t, "2", unique[1].ID)
 	assert.Equal(t, "3", unique[2].ID)
 }
+
+func TestCVESource_convertCVE(t *testing.T) {
+	source := NewCVESource("")
+
+	var cve nvdCVE
+	cve.ID = "CVE-2024-12345"
+	cve.Published = "2024-06-01T12:15:09.853"
+	cve.Descriptions = []nvdDescription{{Lang: "en", Value: "Privilege escalation in Kubernetes kubelet"}}
+	var metric nvdCVSSMetric
+	metric.CVSSData.BaseScore = 8.8
+	metric.CVSSData.BaseSeverity = "HIGH"
+	cve.Metrics.CVSSMetricV31 = []nvdCVSSMetric{metric}
+
+	mention := source.convertCVE(cve, "kubernetes")
+
+	assert.Equal(t, "cve_CVE-2024-12345", mention.ID)
+	assert.Equal(t, "CVE-2024-12345 (HIGH 8.8)", mention.Title)
+	assert.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2024-12345", mention.URL)
+	assert.Equal(t, time.Date(2024, 6, 1, 12, 15, 9, 853000000, time.UTC), mention.CreatedAt)
+	require.Len(t, mention.Advisories, 1)
+	assert.Equal(t, "HIGH", mention.Advisories[0].Severity)
+	assert.Equal(t, "Privilege escalation in Kubernetes kubelet", mention.Advisories[0].Summary)
+}
+
+func TestMediumSource_WithPublicationsAndAuthors(t *testing.T) {
+	source := NewMediumSource().
+		WithPublications([]string{"itnext", " https://medium.com/microsoftazure/ ", ""}).
+		WithAuthors([]string{"jane", "@john"})
+
+	assert.Equal(t, []string{"itnext", "microsoftazure"}, source.publications)
+	assert.Equal(t, []string{"@jane", "@john"}, source.authors)
+}
+
+func TestMediumSource_processFeedItem(t *testing.T) {
+	source := NewMediumSource()
+	pubDate := time.Now().Add(-time.Hour).UTC().Format(time.RFC1123Z)
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/" version="2.0">
+<channel>
+<item>
+<title><![CDATA[Scaling workloads]]></title>
+<link>https://itnext.io/scaling-workloads-abc123</link>
+<guid isPermaLink="false">https://medium.com/p/abc123</guid>
+<dc:creator><![CDATA[Jane Doe]]></dc:creator>
+<pubDate>` + pubDate + `</pubDate>
+<content:encoded><![CDATA[<p>We moved our cluster to
+<strong>Azure Kubernetes Service</strong> &amp; never looked back.</p>]]></content:encoded>
+</item>
+</channel>
+</rss>`
+
+	items, err := source.parseRSSFeed([]byte(feed))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	mention := source.processFeedItem(items[0], time.Now().Add(-24*time.Hour))
+	require.NotNil(t, mention)
+	assert.Equal(t, "medium_abc123", mention.ID)
+	assert.Equal(t, "Jane Doe", mention.Author)
+	assert.Equal(t, "We moved our cluster to Azure Kubernetes Service & never looked back.", mention.Content)
+
+	assert.Nil(t, source.processFeedItem(items[0], time.Now()))
+}
+
+func TestTwitterStream_ruleChanges(t *testing.T) {
+	stream := NewTwitterStream("token")
+	kaitoRule := stream.source.buildSearchQuery("KAITO") + " -is:retweet"
+
+	existing := []twitterStreamRule{
+		{ID: "1", Value: kaitoRule, Tag: twitterStreamRuleTag + "KAITO"},
+		{ID: "2", Value: `"old keyword"`, Tag: twitterStreamRuleTag + "old keyword"},
+		{ID: "3", Value: `"someone else"`, Tag: "other-tool"},
+	}
+
+	add, remove := stream.ruleChanges(existing, []string{"KAITO", "AKS", "Azure Kubernetes Service"})
+
+	assert.Equal(t, []string{"2"}, remove, "only stale bot rules are removed")
+	require.Len(t, add, 1, "Azure Kubernetes Service is covered by the AKS rule")
+	assert.Equal(t, twitterStreamRuleTag+"AKS", add[0].Tag)
+	assert.True(t, strings.HasSuffix(add[0].Value, " -is:retweet"))
+}
+
+func TestTwitterStream_parseEvent(t *testing.T) {
+	stream := NewTwitterStream("token")
+
+	mention, ok := stream.parseEvent([]byte(`{"data":{"id":"42","text":"AKS upgrade broke our nodes","author_id":"7","created_at":"2024-06-01T12:00:00.000Z"},"matching_rules":[{"id":"1","tag":"aks-mentions-bot:AKS"},{"id":"9","tag":"other-tool"}]}`))
+	require.True(t, ok)
+	assert.Equal(t, "twitter_42", mention.ID)
+	assert.Equal(t, []string{"AKS"}, mention.Keywords)
+	assert.Equal(t, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), mention.CreatedAt)
+
+	_, ok = stream.parseEvent([]byte(`{"data":{"id":"43","text":"RT","created_at":"2024-06-01T12:00:00.000Z","referenced_tweets":[{"type":"retweeted","id":"42"}]}}`))
+	assert.False(t, ok)
+	_, ok = stream.parseEvent([]byte(`{"errors":[{"title":"operational-disconnect"}]}`))
+	assert.False(t, ok)
+}
+
+func TestTwitterStream_RunReportsConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, twitterStreamPath, r.URL.Path)
+		w.Write([]byte(`{"data":{"id":"42","text":"AKS upgrade broke our nodes","created_at":"2024-06-01T12:00:00.000Z"},"matching_rules":[{"id":"1","tag":"aks-mentions-bot:AKS"}]}` + "\n"))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var states []bool
+	stream := NewTwitterStream("token").WithConnectionHandler(func(connected bool) {
+		states = append(states, connected)
+		if !connected {
+			cancel()
+		}
+	})
+	stream.source.baseURL = server.URL
+
+	var mentions []models.Mention
+	err := stream.Run(ctx, func(mention models.Mention) { mentions = append(mentions, mention) })
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []bool{true, false}, states, "a dropped connection is reported before reconnecting")
+	require.Len(t, mentions, 1)
+	assert.Equal(t, "twitter_42", mentions[0].ID)
+}
+
+func TestStreamBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, streamBackoff(0, false, false))
+	assert.Equal(t, 10*time.Second, streamBackoff(5*time.Second, false, false))
+	assert.Equal(t, 5*time.Minute, streamBackoff(4*time.Minute, false, false))
+	assert.Equal(t, 5*time.Second, streamBackoff(4*time.Minute, true, false), "a healthy connection resets the backoff")
+	assert.Equal(t, time.Minute, streamBackoff(10*time.Second, false, true))
+	assert.Equal(t, 15*time.Minute, streamBackoff(10*time.Minute, false, true))
+}
+
+func TestGitLabSource_IsEnabled(t *testing.T) {
+	assert.False(t, NewGitLabSource("").IsEnabled())
+	assert.True(t, NewGitLabSource("token").IsEnabled())
+	assert.Equal(t, "https://gitlab.example.com", NewGitLabSource("token").WithBaseURL("https://gitlab.example.com/").baseURL)
+	assert.Equal(t, defaultGitLabURL, NewGitLabSource("token").WithBaseURL(" ").baseURL)
+}
+
+func TestGitLabSource_convertIssue(t *testing.T) {
+	source := NewGitLabSource("token")
+	createdAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mention := source.convertIssue(gitLabIssue{
+		ID:             99,
+		Title:          "AKS node pool upgrade hangs",
+		Description:    "Upgrade stuck for hours",
+		CreatedAt:      createdAt,
+		WebURL:         "https://gitlab.com/team/infra/-/issues/7",
+		Author:         gitLabAuthor{Username: "jane"},
+		Upvotes:        3,
+		UserNotesCount: 5,
+	}, "AKS")
+
+	assert.Equal(t, "gitlab_issue_99", mention.ID)
+	assert.Equal(t, "gitlab", mention.Source)
+	assert.Equal(t, "jane", mention.Author)
+	assert.Equal(t, 3, mention.Score)
+	assert.Equal(t, 5, mention.CommentCount)
+	assert.Equal(t, createdAt, mention.CreatedAt)
+}
+
+func TestGitHubSource_FetchMentions(t *testing.T) {
+	created := time.Now().UTC().Add(-3 * time.Hour).Format(time.RFC3339)
+	old := time.Now().UTC().Add(-90 * 24 * time.Hour).Format(time.RFC3339)
+	var lookups []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		query := r.URL.Query().Get("q")
+		switch {
+		case r.URL.Path == "/search/repositories":
+			assert.True(t, strings.HasPrefix(query, `"KAITO" in:name,description,readme created:>=`), query)
+			w.Write([]byte(`{"items": [
+				{"id": 1, "full_name": "jane/kaito-rag-sample", "html_url": "https://github.com/jane/kaito-rag-sample", "description": "RAG on AKS with KAITO", "topics": ["kaito", "aks"], "stargazers_count": 4, "created_at": "` + created + `", "owner": {"login": "jane"}}
+			]}`))
+		case r.URL.Path == "/search/code" && query == `"KAITO" extension:tf`:
+			w.Write([]byte(`{"items": [
+				{"path": "modules/kaito/main.tf", "repository": {"id": 2, "full_name": "contoso/aks-terraform"}},
+				{"path": "examples/kaito.tf", "repository": {"id": 2, "full_name": "contoso/aks-terraform"}},
+				{"path": "kaito.tf", "repository": {"id": 1, "full_name": "jane/kaito-rag-sample"}},
+				{"path": "infra/kaito.tf", "repository": {"id": 3, "full_name": "contoso/platform"}}
+			]}`))
+		case r.URL.Path == "/search/code":
+			w.Write([]byte(`{"items": []}`))
+		case strings.HasPrefix(r.URL.Path, "/repos/"):
+			lookups = append(lookups, r.URL.Path)
+			if r.URL.Path == "/repos/contoso/platform" {
+				w.Write([]byte(`{"id": 3, "full_name": "contoso/platform", "created_at": "` + old + `"}`))
+				return
+			}
+			w.Write([]byte(`{"id": 2, "full_name": "contoso/aks-terraform", "html_url": "https://github.com/contoso/aks-terraform", "language": "HCL", "created_at": "` + created + `", "owner": {"login": "contoso"}}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	source := NewGitHubSource("gh-token").WithTerms([]string{" KAITO ", ""})
+	source.baseURL = server.URL
+	assert.Equal(t, []string{"KAITO"}, source.terms)
+	assert.Equal(t, defaultGitHubTerms, NewGitHubSource("").WithTerms(nil).terms)
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mentions, 2)
+
+	assert.Equal(t, "github_repo_1", mentions[0].ID)
+	assert.Equal(t, "jane/kaito-rag-sample", mentions[0].Title)
+	assert.Equal(t, "RAG on AKS with KAITO\nTopics: kaito, aks", mentions[0].Content)
+	assert.Equal(t, 4, mentions[0].Score)
+	assert.Equal(t, []string{"KAITO"}, mentions[0].Keywords)
+
+	// Repositories found by code search are looked up, and only new ones kept
+	assert.Equal(t, []string{"/repos/contoso/aks-terraform", "/repos/contoso/platform"}, lookups)
+	assert.Equal(t, "github_repo_2", mentions[1].ID)
+	assert.Equal(t, "contoso", mentions[1].Author)
+	assert.Equal(t, "References KAITO in examples/kaito.tf, modules/kaito/main.tf\nLanguage: HCL", mentions[1].Content)
+}
+
+func TestBitbucketSource_WithRepositories(t *testing.T) {
+	source := NewBitbucketSource("").WithRepositories([]string{" team/infra ", "not-a-repo", "a/b/c", ""})
+	assert.Equal(t, []string{"team/infra"}, source.repositories)
+	assert.True(t, source.IsEnabled())
+	assert.False(t, NewBitbucketSource("").IsEnabled())
+}
+
+func TestBitbucketSource_buildQuery(t *testing.T) {
+	source := NewBitbucketSource("").WithQueries(NewQueryBuilder(map[string]models.KeywordQuery{
+		"aks": {Terms: []string{"Azure Kubernetes Service"}},
+	}))
+	cutoff := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Aliases are searched with their keyword
+	assert.Equal(t,
+		`(title ~ "AKS" OR content.raw ~ "AKS" OR title ~ "Azure Kubernetes Service" OR content.raw ~ "Azure Kubernetes Service" OR title ~ "say \"hi\"" OR content.raw ~ "say \"hi\"") AND created_on >= 2024-06-01T12:00:00Z`,
+		source.buildQuery([]string{"AKS", " ", `say "hi"`}, cutoff))
+	assert.Empty(t, source.buildQuery(nil, cutoff))
+}
+
+func TestBitbucketSource_convertIssue(t *testing.T) {
+	source := NewBitbucketSource("")
+
+	var issue bitbucketIssue
+	issue.ID = 12
+	issue.Title = "Ingress broken after AKS upgrade"
+	issue.Content.Raw = "Azure Kubernetes Service 1.29"
+	issue.Reporter.Nickname = "jdoe"
+	issue.Links.HTML.Href = "https://bitbucket.org/team/infra/issues/12"
+
+	mention := source.convertIssue("team/infra", issue, []string{"AKS", "Azure Kubernetes Service", "KAITO"})
+
+	assert.Equal(t, "bitbucket_team_infra_12", mention.ID)
+	assert.Equal(t, "team/infra", mention.Channel)
+	assert.Equal(t, "jdoe", mention.Author)
+	assert.Equal(t, []string{"AKS", "Azure Kubernetes Service"}, mention.Keywords)
+}
+
+func TestThreadsSource_IsEnabled(t *testing.T) {
+	assert.False(t, NewThreadsSource("").IsEnabled())
+	assert.True(t, NewThreadsSource("token").IsEnabled())
+	assert.Equal(t, "threads", NewThreadsSource("token").GetName())
+}
+
+func TestThreadsSource_convertPost(t *testing.T) {
+	source := NewThreadsSource("token")
+
+	mention, err := source.convertPost(threadsPost{
+		ID:        "1789",
+		Text:      "  Just migrated our platform to AKS  ",
+		Username:  "devjane",
+		Permalink: "https://www.threads.net/@devjane/post/C8abc",
+		Timestamp: "2024-06-01T12:00:00+0000",
+	}, "AKS")
+	require.NoError(t, err)
+	assert.Equal(t, "threads_1789", mention.ID)
+	assert.Equal(t, "Just migrated our platform to AKS", mention.Content)
+	assert.Equal(t, "https://www.threads.net/@devjane/post/C8abc", mention.URL)
+	assert.Equal(t, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), mention.CreatedAt.UTC())
+
+	_, err = source.convertPost(threadsPost{ID: "1", Timestamp: "yesterday"}, "AKS")
+	assert.Error(t, err)
+}
+
+func TestAnswerState_Answered(t *testing.T) {
+	assert.False(t, AnswerState{}.Answered())
+	assert.True(t, AnswerState{Answers: 2}.Answered())
+	assert.True(t, AnswerState{Accepted: true}.Answered())
+}
+
+func TestRedditSource_CheckAnswers_otherSources(t *testing.T) {
+	source := NewRedditSource("id", "secret")
+
+	states, err := source.CheckAnswers(context.Background(), []string{"stackoverflow_1", "hackernews_2"})
+	require.NoError(t, err)
+	assert.Empty(t, states)
+}
+
+func TestYouTubeSource_screenVideos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/videos", r.URL.Path)
+		assert.Equal(t, "short,gaming,talk,trusted,impostor", r.URL.Query().Get("id"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [
+			{"id": "short", "snippet": {"title": "AKS in two minutes", "categoryId": "28"}, "contentDetails": {"duration": "PT2M30S"}},
+			{"id": "gaming", "snippet": {"title": "AKS клатч", "categoryId": "20"}, "contentDetails": {"duration": "PT12M"}},
+			{"id": "talk", "snippet": {"title": "AKS networking deep dive", "categoryId": "28", "defaultAudioLanguage": "en-US"}, "contentDetails": {"duration": "PT1H2M3S"}},
+			{"id": "trusted", "snippet": {"title": "AKS tip", "categoryId": "28"}, "contentDetails": {"duration": "PT50S"}},
+			{"id": "impostor", "snippet": {"title": "AKS tip #shorts", "categoryId": "28"}, "contentDetails": {"duration": "PT50S"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	source := NewYouTubeSource("api_key").
+		WithTrustedChannels([]string{"", " UC4"}).
+		WithVideoFilters(true, true)
+	source.baseURL = server.URL
+
+	mentions := []models.Mention{
+		{ID: "youtube_video_short", Channel: "UC1", Author: "Clips"},
+		{ID: "youtube_video_gaming", Channel: "UC2", Author: "Gamer"},
+		{ID: "youtube_video_talk", Channel: "UC3", Author: "Conference"},
+		{ID: "youtube_video_trusted", Channel: "UC4", Author: "Microsoft Developer"},
+		{ID: "youtube_video_impostor", Channel: "UC5", Author: "Microsoft Developer"},
+	}
+	kept := source.screenVideos(context.Background(), mentions)
+
+	require.Len(t, kept, 2)
+	assert.Equal(t, "youtube_video_talk", kept[0].ID)
+	assert.Empty(t, kept[0].PostType)
+	assert.Equal(t, "youtube_video_trusted", kept[1].ID, "trusted channels skip the filters")
+	assert.Equal(t, models.PostTypeShort, kept[1].PostType)
+}
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"PT45S", 45 * time.Second, true},
+		{"PT1H2M3S", time.Hour + 2*time.Minute + 3*time.Second, true},
+		{"P1DT2H", 26 * time.Hour, true},
+		{"P0D", 0, true},
+		{"PT", 0, true},
+		{"1H", 0, false},
+		{"PT5", 0, false},
+		{"P5M", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseISODuration(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWebSearch_Search(t *testing.T) {
+	recent := time.Now().UTC().Add(-2 * time.Hour).Format("2006-01-02T15:04:05.0000000")
+	old := time.Now().UTC().Add(-72 * time.Hour).Format("2006-01-02T15:04:05.0000000")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bing-key", r.Header.Get("Ocp-Apim-Subscription-Key"))
+		assert.Equal(t, `site:medium.com "AKS"`, r.URL.Query().Get("q"))
+		assert.Equal(t, "Day", r.URL.Query().Get("freshness"))
+		w.Write([]byte(`{"webPages": {"value": [
+			{"name": "Scaling AKS", "url": "https://medium.com/@jane/scaling-aks-1a2b3c4d5e6f", "snippet": "Azure Kubernetes Service tips", "datePublished": "` + recent + `"},
+			{"name": "Old AKS post", "url": "https://medium.com/@joe/old-aks-0f0f0f0f0f0f", "snippet": "AKS", "dateLastCrawled": "` + old + `"},
+			{"name": "", "url": "https://medium.com/empty"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	search := NewWebSearch("bing-key").WithEndpoint(server.URL)
+	assert.True(t, search.IsEnabled())
+	assert.False(t, NewWebSearch("").IsEnabled())
+
+	results, err := search.Search(context.Background(), `site:medium.com "AKS"`, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Scaling AKS", results[0].Title)
+	assert.WithinDuration(t, time.Now().Add(-2*time.Hour), results[0].Published, time.Minute)
+}
+
+func TestBingFreshness(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Day", bingFreshness(time.Hour, now))
+	assert.Equal(t, "Week", bingFreshness(7*24*time.Hour, now))
+	assert.Equal(t, "Month", bingFreshness(30*24*time.Hour, now))
+	assert.Equal(t, "2023-12-31..2024-03-31", bingFreshness(91*24*time.Hour, now))
+}
+
+func TestWebSource_domainCap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("q"), "-site:reddit.com")
+		w.Write([]byte(`{"webPages": {"value": [
+			{"name": "AKS release notes", "url": "https://learn.microsoft.com/aks/one", "snippet": "AKS"},
+			{"name": "AKS upgrades", "url": "https://learn.microsoft.com/aks/two?tab=cli", "snippet": "AKS"},
+			{"name": "AKS upgrades again", "url": "https://www.learn.microsoft.com/aks/two", "snippet": "AKS"},
+			{"name": "AKS networking", "url": "https://learn.microsoft.com/aks/three", "snippet": "AKS"},
+			{"name": "Our AKS migration", "url": "https://blog.example.com/aks", "snippet": "AKS"},
+			{"name": "Unrelated", "url": "https://example.org/", "snippet": "Nothing to see"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	source := NewWebSource(NewWebSearch("bing-key").WithEndpoint(server.URL)).WithMaxResultsPerDomain(2)
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+
+	var urls []string
+	for _, mention := range mentions {
+		assert.Equal(t, "web", mention.Source)
+		assert.Equal(t, []string{"AKS"}, mention.Keywords)
+		urls = append(urls, mention.URL)
+	}
+	assert.Equal(t, []string{
+		"https://learn.microsoft.com/aks/one",
+		"https://learn.microsoft.com/aks/two?tab=cli",
+		"https://blog.example.com/aks",
+	}, urls)
+}
+
+func TestMediumSource_searchResults(t *testing.T) {
+	source := NewMediumSource()
+	assert.Equal(t, "medium_1a2b3c4d5e6f", source.resultID("https://medium.com/@jane/scaling-aks-1a2b3c4d5e6f"))
+	assert.Equal(t, "@jane", source.resultAuthor("https://medium.com/@jane/scaling-aks-1a2b3c4d5e6f"))
+	assert.Equal(t, "Medium Author", source.resultAuthor("https://itnext.io/scaling-aks-1a2b3c4d5e6f"))
+	assert.Equal(t, webMentionID("medium", "https://medium.com/tag/aks"), source.resultID("https://medium.com/tag/aks/"))
+}
+
+func TestLinkedInSource_resultMention(t *testing.T) {
+	assert.False(t, NewLinkedInSource(NewWebSearch("")).IsEnabled())
+
+	source := NewLinkedInSource(NewWebSearch("bing-key"))
+	post := source.resultMention(WebResult{
+		Title: "Jane Doe on LinkedIn: We moved to AKS",
+		URL:   "https://www.linkedin.com/posts/janedoe_aks-activity-7123456789012345678-abcd",
+	})
+	assert.Equal(t, "linkedin_7123456789012345678", post.ID)
+	assert.Equal(t, "Jane Doe", post.Author)
+	assert.Equal(t, "We moved to AKS", post.Title)
+
+	article := source.resultMention(WebResult{
+		Title: "Running AKS at scale | LinkedIn",
+		URL:   "https://www.linkedin.com/pulse/running-aks-scale-jane-doe",
+	})
+	assert.Equal(t, "Running AKS at scale", article.Title)
+	assert.Equal(t, "LinkedIn Member", article.Author)
+	assert.Equal(t, "linkedin", article.Source)
+}
+
+func TestLinkedInPageSource_FetchMentions(t *testing.T) {
+	now := time.Now()
+	ms := func(ago time.Duration) string { return strconv.FormatInt(now.Add(-ago).UnixMilli(), 10) }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "202601", r.Header.Get("LinkedIn-Version"))
+		switch r.URL.EscapedPath() {
+		case "/posts":
+			assert.Equal(t, "urn:li:organization:1234", r.URL.Query().Get("author"))
+			w.Write([]byte(`{"elements": [
+				{"id": "urn:li:share:1", "commentary": "Node auto provisioning is GA in AKS\nRead more", "publishedAt": ` + ms(2*time.Hour) + `, "lifecycleState": "PUBLISHED"},
+				{"id": "urn:li:share:2", "commentary": "Azure Functions Flex Consumption", "publishedAt": ` + ms(time.Hour) + `, "lifecycleState": "PUBLISHED"},
+				{"id": "urn:li:ugcPost:3", "commentary": "AKS Automatic is here", "publishedAt": ` + ms(30*24*time.Hour) + `, "lifecycleState": "PUBLISHED"},
+				{"id": "urn:li:ugcPost:4", "commentary": "Last year's AKS recap", "publishedAt": ` + ms(60*24*time.Hour) + `, "lifecycleState": "PUBLISHED"}
+			]}`))
+		case "/socialMetadata/urn%3Ali%3Ashare%3A1":
+			w.Write([]byte(`{"reactionSummaries": {"LIKE": {"count": 120}, "PRAISE": {"count": 30}}, "commentSummary": {"count": 2}}`))
+		case "/socialMetadata/urn%3Ali%3AugcPost%3A3":
+			w.Write([]byte(`{"reactionSummaries": {"LIKE": {"count": 900}}, "commentSummary": {"count": 41}}`))
+		case "/socialActions/urn%3Ali%3Ashare%3A1/comments":
+			w.Write([]byte(`{"elements": [
+				{"id": "11", "commentUrn": "urn:li:comment:(urn:li:activity:1,11)", "actor": "urn:li:person:a", "message": {"text": "Finally!"}, "created": {"time": ` + ms(time.Hour) + `}},
+				{"id": "12", "actor": "urn:li:organization:1234", "message": {"text": "Thanks!"}, "created": {"time": ` + ms(time.Hour) + `}}
+			]}`))
+		case "/socialActions/urn%3Ali%3AugcPost%3A3/comments":
+			w.Write([]byte(`{"elements": [
+				{"id": "31", "actor": "urn:li:person:b", "message": {"text": "Does it support Windows node pools?"}, "created": {"time": ` + ms(3*time.Hour) + `}},
+				{"id": "32", "actor": "urn:li:person:c", "message": {"text": "Great launch"}, "created": {"time": ` + ms(20*24*time.Hour) + `}}
+			]}`))
+		case "/socialActions/urn%3Ali%3AugcPost%3A4/comments":
+			w.Write([]byte(`{"elements": []}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.False(t, NewLinkedInPageSource("token", "").IsEnabled())
+	source := NewLinkedInPageSource("token", "1234").WithPageName("Microsoft Azure")
+	source.baseURL = server.URL
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, mention := range mentions {
+		ids = append(ids, mention.ID)
+	}
+	// A new post and its comments, and an older post only for its new comment
+	assert.Equal(t, []string{"linkedinpage_1", "linkedinpage_1_11", "linkedinpage_3", "linkedinpage_3_31"}, ids)
+
+	post := mentions[0]
+	assert.Equal(t, "Node auto provisioning is GA in AKS", post.Title)
+	assert.Equal(t, "Microsoft Azure", post.Author)
+	assert.Equal(t, 150, post.Score)
+	assert.Equal(t, 2, post.CommentCount)
+	assert.Equal(t, []string{"AKS"}, post.Keywords)
+	assert.Equal(t, "https://www.linkedin.com/feed/update/urn:li:share:1/", post.URL)
+	require.Len(t, post.TopComments, 1, "the page's own replies are left out")
+	assert.Equal(t, "Finally!", post.TopComments[0].Content)
+
+	comment := mentions[1]
+	assert.Equal(t, "Comment on: Node auto provisioning is GA in AKS", comment.Title)
+	assert.Equal(t, models.PostTypeComment, comment.PostType)
+	assert.Equal(t, "https://www.linkedin.com/feed/update/urn:li:share:1/?commentUrn=urn%3Ali%3Acomment%3A%28urn%3Ali%3Aactivity%3A1%2C11%29", comment.URL)
+	assert.Equal(t, 900, mentions[2].Score)
+}
+
+func TestQiitaSource_FetchMentions(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.True(t, strings.HasSuffix(r.URL.Query().Get("query"), `" created:>=`+now.Add(-24*time.Hour).Format("2006-01-02")))
+		w.Write([]byte(`[
+			{"id": "abc", "title": "AKS のノード自動プロビジョニングを試す", "body": "AKS で NAP を有効にしました", "url": "https://qiita.com/user/items/abc",
+			 "created_at": "` + now.Add(-time.Hour).Format(time.RFC3339) + `", "likes_count": 12, "comments_count": 2, "user": {"id": "user"}},
+			{"id": "old", "title": "AKS 入門", "body": "AKS", "url": "https://qiita.com/user/items/old",
+			 "created_at": "` + now.Add(-48*time.Hour).Format(time.RFC3339) + `", "user": {"id": "user"}}
+		]`))
+	}))
+	defer server.Close()
+
+	source := NewQiitaSource("token")
+	source.itemsURL = server.URL
+	assert.True(t, source.IsEnabled(), "Qiita works without a token")
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mentions, 1)
+	assert.Equal(t, "qiita_abc", mentions[0].ID)
+	assert.Equal(t, "Qiita", mentions[0].Platform)
+	assert.Equal(t, "user", mentions[0].Author)
+	assert.Equal(t, 12, mentions[0].Score)
+	assert.Equal(t, 2, mentions[0].CommentCount)
+	assert.Equal(t, []string{"AKS"}, mentions[0].Keywords)
+}
+
+func TestCNFeedsSource_FetchMentions(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/csdn":
+			w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel>
+				<item><title>AKS 集群升级踩坑记录</title><link>https://blog.csdn.net/user/article/details/1</link>
+				<description>&lt;p&gt;升级 AKS 之后 ingress 不工作&lt;/p&gt;</description><author>user</author>
+				<pubDate>` + now.Add(-time.Hour).Format(time.RFC1123Z) + `</pubDate></item>
+				<item><title>Kubernetes 入门</title><link>https://blog.csdn.net/user/article/details/2</link>
+				<pubDate>` + now.Add(-time.Hour).Format(time.RFC1123Z) + `</pubDate></item>
+			</channel></rss>`))
+		case "/juejin":
+			w.Write([]byte(`<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom">
+				<entry><title>在 AKS 上部署 KAITO</title><id>juejin-7</id><link rel="alternate" href="https://juejin.cn/post/7"/>
+				<summary>AKS 上的模型推理</summary><author><name>dev</name></author>
+				<updated>` + now.Add(-2*time.Hour).Format(time.RFC3339) + `</updated></entry>
+				<entry><title>AKS 旧文</title><id>juejin-8</id><link href="https://juejin.cn/post/8"/>
+				<updated>` + now.Add(-72*time.Hour).Format(time.RFC3339) + `</updated></entry>
+			</feed>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	assert.False(t, NewCNFeedsSource(nil).IsEnabled())
+	source := NewCNFeedsSource([]string{server.URL + "/csdn", server.URL + "/juejin", server.URL + "/missing"})
+	mentions, err := source.FetchMentions(context.Background(), []string{"AKS"}, 24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, mentions, 2, "posts without keywords, old posts and failing feeds are skipped")
+
+	csdn := mentions[0]
+	assert.Equal(t, "CSDN", csdn.Platform)
+	assert.Equal(t, "AKS 集群升级踩坑记录", csdn.Title)
+	assert.Equal(t, "升级 AKS 之后 ingress 不工作", csdn.Content)
+	assert.Equal(t, "user", csdn.Author)
+	assert.True(t, strings.HasPrefix(csdn.ID, "cnfeeds_"))
+
+	juejin := mentions[1]
+	assert.Equal(t, "Juejin", juejin.Platform)
+	assert.Equal(t, "https://juejin.cn/post/7", juejin.URL)
+	assert.Equal(t, "dev", juejin.Author)
+}
+
+type siteTransport func(r *http.Request) string
+
+func (f siteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	recorder.WriteString(f(r))
+	return recorder.Result(), nil
+}
+
+func TestStackOverflowSource_sites(t *testing.T) {
+	var sites []string
+	source := NewStackOverflowSource().
+		WithSites([]string{" ServerFault ", "stackoverflow"}).
+		WithTransport(siteTransport(func(r *http.Request) string {
+			site := r.URL.Query().Get("site")
+			sites = append(sites, site)
+			if strings.HasPrefix(r.URL.Path, "/2.3/questions/") {
+				return `{"items": [{"question_id": 7, "answer_count": 1, "accepted_answer_id": 9}]}`
+			}
+			return fmt.Sprintf(`{"items": [{"question_id": 7, "title": "Karpenter node pool stuck upgrading", "body": "<p>Help</p>", "creation_date": %d}]}`, time.Now().Add(-10*time.Minute).Unix())
+		}))
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"Karpenter"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"serverfault", "stackoverflow"}, sites)
+	require.Len(t, mentions, 2)
+	assert.Equal(t, "stackoverflow_serverfault_7", mentions[0].ID)
+	assert.Equal(t, "Server Fault", mentions[0].Platform)
+	assert.Equal(t, "stackoverflow_7", mentions[1].ID)
+	assert.Equal(t, "Stack Overflow", mentions[1].Platform)
+
+	sites = nil
+	states, err := source.CheckAnswers(context.Background(), []string{"stackoverflow_serverfault_7", "stackoverflow_7", "reddit_7"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"serverfault", "stackoverflow"}, sites)
+	assert.True(t, states["stackoverflow_serverfault_7"].Accepted)
+	assert.True(t, states["stackoverflow_7"].Accepted)
+	assert.Len(t, states, 2)
+}
+
+func TestYouTubeSource_searchComments(t *testing.T) {
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	old := time.Now().Add(-72 * time.Hour).Format(time.RFC3339)
+	var scanned []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/playlistItems":
+			assert.Equal(t, "UUchannel", r.URL.Query().Get("playlistId"))
+			w.Write([]byte(`{"items": [
+				{"snippet": {"title": "Fleet Manager launch", "resourceId": {"videoId": "upload1"}}},
+				{"snippet": {"title": "Another upload", "resourceId": {"videoId": "upload2"}}}
+			]}`))
+		case "/commentThreads":
+			scanned = append(scanned, r.URL.Query().Get("videoId"))
+			w.Write([]byte(`{"items": [
+				{"id": "c1", "snippet": {"topLevelComment": {"snippet": {"textDisplay": "Karpenter on KAITO nodes?", "publishedAt": "` + recent + `"}}}},
+				{"id": "c2", "snippet": {"topLevelComment": {"snippet": {"textDisplay": "KAITO is great", "publishedAt": "` + old + `"}}}},
+				{"id": "c3", "snippet": {"topLevelComment": {"snippet": {"textDisplay": "First!", "publishedAt": "` + recent + `"}}}}
+			]}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	source := NewYouTubeSource("api_key").WithCommentScanning([]string{"UCchannel", "not-an-id"}, 2)
+	source.baseURL = server.URL
+
+	plan := []models.KeywordQuery{{Keyword: "Karpenter"}, {Keyword: "KAITO"}}
+	videos := []models.Mention{{ID: "youtube_video_found", Title: "KAITO demo", URL: "https://www.youtube.com/watch?v=found"}}
+	comments := source.searchComments(context.Background(), plan, videos, 24*time.Hour)
+
+	assert.Equal(t, []string{"found", "upload1"}, scanned, "found videos come first, up to the limit")
+	require.Len(t, comments, 2)
+	assert.Equal(t, []string{"Karpenter", "KAITO"}, comments[0].Keywords)
+	assert.Equal(t, "Comment on KAITO demo", comments[0].Title)
+	assert.Equal(t, "Comment on Fleet Manager launch", comments[1].Title)
+
+	scanned = nil
+	assert.Empty(t, source.WithCommentScanning(nil, 0).searchComments(context.Background(), plan, videos, 24*time.Hour))
+	assert.Empty(t, scanned)
+}
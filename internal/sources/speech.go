@@ -0,0 +1,142 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// speechMaxAudioBytes caps how much of an episode is sent for transcription; fast
+// transcription rejects larger files, and a truncated MP3 still transcribes up to the cut
+const speechMaxAudioBytes = 200 << 20
+
+// speechAPIVersion is the Speech to text REST API version of fast transcription
+const speechAPIVersion = "2024-11-15"
+
+// SpeechTranscriber transcribes audio with the fast transcription API of Azure AI Speech
+type SpeechTranscriber struct {
+	client   *http.Client
+	endpoint string
+	key      string
+	locale   string
+}
+
+// NewSpeechTranscriber creates a transcriber for a Speech resource endpoint, e.g.
+// https://eastus.api.cognitive.microsoft.com; it is disabled without an endpoint and key
+func NewSpeechTranscriber(endpoint, key string) *SpeechTranscriber {
+	return &SpeechTranscriber{
+		// Downloading and transcribing an hour of audio takes minutes
+		client:   &http.Client{Timeout: 10 * time.Minute},
+		endpoint: strings.TrimRight(strings.TrimSpace(endpoint), "/"),
+		key:      key,
+		locale:   "en-US",
+	}
+}
+
+// WithLocale sets the language the audio is transcribed in, e.g. "en-GB"
+func (t *SpeechTranscriber) WithLocale(locale string) *SpeechTranscriber {
+	if locale = strings.TrimSpace(locale); locale != "" {
+		t.locale = locale
+	}
+	return t
+}
+
+// IsEnabled reports whether a Speech resource is configured
+func (t *SpeechTranscriber) IsEnabled() bool {
+	return t != nil && t.endpoint != "" && t.key != ""
+}
+
+type speechTranscription struct {
+	CombinedPhrases []struct {
+		Text string `json:"text"`
+	} `json:"combinedPhrases"`
+}
+
+// Transcribe downloads the audio at audioURL and returns its transcript. The audio is
+// streamed to the Speech service rather than held in memory.
+func (t *SpeechTranscriber) Transcribe(ctx context.Context, audioURL string) (string, error) {
+	if !t.IsEnabled() {
+		return "", fmt.Errorf("speech transcription is not configured")
+	}
+
+	download, err := http.NewRequestWithContext(ctx, http.MethodGet, audioURL, nil)
+	if err != nil {
+		return "", err
+	}
+	download.Header.Set("User-Agent", "AKS-Mentions-Bot/1.0")
+	audio, err := t.client.Do(download)
+	if err != nil {
+		return "", fmt.Errorf("failed to download audio: %w", err)
+	}
+	defer audio.Body.Close()
+	if audio.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("audio download returned status %d", audio.StatusCode)
+	}
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		definition, err := form.CreateFormField("definition")
+		if err == nil {
+			_, err = fmt.Fprintf(definition, `{"locales": [%q]}`, t.locale)
+		}
+		if err == nil {
+			var file io.Writer
+			if file, err = form.CreateFormFile("audio", audioFileName(audioURL)); err == nil {
+				_, err = io.Copy(file, io.LimitReader(audio.Body, speechMaxAudioBytes))
+			}
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		t.endpoint+"/speechtotext/transcriptions:transcribe?api-version="+speechAPIVersion, body)
+	if err != nil {
+		body.Close()
+		return "", err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", t.key)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Speech API returned status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var transcription speechTranscription
+	if err := json.Unmarshal(data, &transcription); err != nil {
+		return "", fmt.Errorf("failed to parse transcription: %w", err)
+	}
+	var phrases []string
+	for _, phrase := range transcription.CombinedPhrases {
+		phrases = append(phrases, phrase.Text)
+	}
+	return strings.Join(phrases, " "), nil
+}
+
+// audioFileName names the uploaded audio after the file in its URL
+func audioFileName(audioURL string) string {
+	name := path.Base(strings.SplitN(audioURL, "?", 2)[0])
+	if name == "." || name == "/" || name == "" {
+		return "episode.mp3"
+	}
+	return name
+}
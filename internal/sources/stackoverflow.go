@@ -4,18 +4,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
 )
 
-// StackOverflowSource implements Stack Overflow API source
+// StackOverflowSource implements Stack Overflow API source. It searches the other Stack
+// Exchange sites where AKS questions are asked through the same API.
 type StackOverflowSource struct {
-	client *resty.Client
+	client    *resty.Client
+	tags      []string
+	sites     []string // Stack Exchange API site parameters, e.g. "serverfault"
+	queries   *QueryBuilder
+	sanitizer *sanitize.Sanitizer
+}
+
+// defaultStackOverflowTags are the question tags searched by default
+var defaultStackOverflowTags = []string{"azure", "kubernetes", "docker", "containers", "devops"}
+
+// defaultStackExchangeSites are the Stack Exchange sites searched by default; operational
+// AKS questions often land on Server Fault rather than Stack Overflow
+var defaultStackExchangeSites = []string{"stackoverflow", "serverfault", "devops", "superuser"}
+
+// stackExchangeSiteNames are the display names of well-known sites, keyed by API site parameter
+var stackExchangeSiteNames = map[string]string{
+	"stackoverflow": "Stack Overflow",
+	"serverfault":   "Server Fault",
+	"devops":        "DevOps Stack Exchange",
+	"superuser":     "Super User",
 }
 
 type stackOverflowResponse struct {
@@ -36,6 +58,7 @@ type stackOverflowQuestion struct {
 	AnswerCount     int    `json:"answer_count"`
 	Link            string `json:"link"`
 	IsAnswered      bool   `json:"is_answered"`
+	AcceptedAnswerID int   `json:"accepted_answer_id"`
 }
 
 // NewStackOverflowSource creates a new Stack Overflow source
@@ -44,7 +67,50 @@ func NewStackOverflowSource() *StackOverflowSource {
 		client: resty.New().
 			SetTimeout(30 * time.Second).
 			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		tags:      defaultStackOverflowTags,
+		sites:     defaultStackExchangeSites,
+		queries:   NewQueryBuilder(nil),
+		sanitizer: sanitize.New(),
+	}
+}
+
+// WithTags overrides the question tags searched; an empty list keeps the defaults
+func (s *StackOverflowSource) WithTags(tags []string) *StackOverflowSource {
+	if cleaned := cleanList(tags); len(cleaned) > 0 {
+		s.tags = cleaned
 	}
+	return s
+}
+
+// WithSites overrides the Stack Exchange sites searched, by API site parameter such as
+// "serverfault"; an empty list keeps the defaults
+func (s *StackOverflowSource) WithSites(sites []string) *StackOverflowSource {
+	var cleaned []string
+	for _, site := range cleanList(sites) {
+		cleaned = append(cleaned, strings.ToLower(site))
+	}
+	if len(cleaned) > 0 {
+		s.sites = cleaned
+	}
+	return s
+}
+
+// WithSanitizer sets how question bodies are turned into mention content
+func (s *StackOverflowSource) WithSanitizer(sanitizer *sanitize.Sanitizer) *StackOverflowSource {
+	s.sanitizer = sanitizer
+	return s
+}
+
+// WithQueries searches each keyword's aliases too, from the shared keyword templates
+func (s *StackOverflowSource) WithQueries(queries *QueryBuilder) *StackOverflowSource {
+	s.queries = queries
+	return s
+}
+
+// WithTransport sends the source's requests through transport, e.g. a response cache
+func (s *StackOverflowSource) WithTransport(transport http.RoundTripper) *StackOverflowSource {
+	s.client.SetTransport(transport)
+	return s
 }
 
 func (s *StackOverflowSource) GetName() string {
@@ -58,27 +124,42 @@ func (s *StackOverflowSource) IsEnabled() bool {
 func (s *StackOverflowSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
 	var allMentions []models.Mention
 
-	for _, keyword := range keywords {
-		mentions, err := s.searchKeyword(ctx, keyword, since)
-		if err != nil {
-			logrus.Errorf("Failed to search Stack Overflow for keyword '%s': %v", keyword, err)
-			continue
+	for _, site := range s.sites {
+		for _, query := range s.queries.Plan(keywords) {
+			for _, name := range queryNames(query) {
+				mentions, err := s.searchKeyword(ctx, site, query, name, since)
+				if err != nil {
+					logrus.Errorf("Failed to search %s for keyword '%s': %v", stackExchangeSiteName(site), name, err)
+					continue
+				}
+				allMentions = append(allMentions, mentions...)
+			}
 		}
-		allMentions = append(allMentions, mentions...)
 	}
 
 	return s.deduplicateMentions(allMentions), nil
 }
 
-func (s *StackOverflowSource) searchKeyword(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	fromDate := time.Now().Add(-since).Unix()
+// stackOverflowFromDateStep is what the fromdate of a search is rounded down to. Searches of
+// a window within the same step share a URL, so the HTTP cache, keyed by URL, can answer them.
+const stackOverflowFromDateStep = 15 * time.Minute
+
+// stackOverflowFromDate returns the fromdate searched for mentions since cutoff. Questions
+// created between it and cutoff are dropped from the results.
+func stackOverflowFromDate(cutoff time.Time) int64 {
+	return cutoff.Truncate(stackOverflowFromDateStep).Unix()
+}
+
+// searchKeyword searches a site for one of the query's names, keeping the questions that
+// mention the keyword or one of its aliases
+func (s *StackOverflowSource) searchKeyword(ctx context.Context, site string, keywordQuery models.KeywordQuery, name string, since time.Duration) ([]models.Mention, error) {
+	cutoff := time.Now().Add(-since)
+	fromDate := stackOverflowFromDate(cutoff)
 	
 	// Build search query with relevant tags
-	query := url.QueryEscape(keyword)
-	tags := []string{"azure", "kubernetes", "docker", "containers", "devops"}
-	
-	searchURL := fmt.Sprintf("https://api.stackexchange.com/2.3/search/advanced?order=desc&sort=creation&q=%s&tagged=%s&site=stackoverflow&fromdate=%d&pagesize=100&filter=withbody",
-		query, strings.Join(tags, ";"), fromDate)
+	query := url.QueryEscape(name)
+	searchURL := fmt.Sprintf("https://api.stackexchange.com/2.3/search/advanced?order=desc&sort=creation&q=%s&tagged=%s&site=%s&fromdate=%d&pagesize=100&filter=withbody",
+		query, strings.Join(s.tags, ";"), url.QueryEscape(site), fromDate)
 
 	resp, err := s.client.R().
 		SetContext(ctx).
@@ -100,26 +181,28 @@ func (s *StackOverflowSource) searchKeyword(ctx context.Context, keyword string,
 	var mentions []models.Mention
 
 	for _, question := range searchResp.Items {
-		// Check if the question content contains our keyword (case-insensitive)
-		content := strings.ToLower(question.Title + " " + question.Body)
-		if !strings.Contains(content, strings.ToLower(keyword)) {
+		content := s.sanitizer.Text(question.Body)
+		if !s.queries.Matches(keywordQuery, question.Title+" "+content) {
 			continue
 		}
 
 		createdAt := time.Unix(question.CreationDate, 0)
+		if createdAt.Before(cutoff) {
+			continue
+		}
 
 		mention := models.Mention{
-			ID:           fmt.Sprintf("stackoverflow_%d", question.QuestionID),
+			ID:           stackExchangeMentionID(site, question.QuestionID),
 			Source:       "stackoverflow",
-			Platform:     "Stack Overflow",
+			Platform:     stackExchangeSiteName(site),
 			Title:        question.Title,
-			Content:      s.stripHTMLTags(question.Body),
+			Content:      content,
 			Author:       question.Owner.DisplayName,
 			URL:          question.Link,
 			CreatedAt:    createdAt,
 			Score:        question.Score,
 			CommentCount: question.AnswerCount,
-			Keywords:     []string{keyword},
+			Keywords:     []string{keywordQuery.Keyword},
 		}
 
 		mentions = append(mentions, mention)
@@ -128,27 +211,90 @@ func (s *StackOverflowSource) searchKeyword(ctx context.Context, keyword string,
 	return mentions, nil
 }
 
-func (s *StackOverflowSource) stripHTMLTags(content string) string {
-	// Basic HTML tag removal - in production, use a proper HTML parser
-	content = strings.ReplaceAll(content, "<p>", "\n")
-	content = strings.ReplaceAll(content, "</p>", "\n")
-	content = strings.ReplaceAll(content, "<br>", "\n")
-	content = strings.ReplaceAll(content, "<br/>", "\n")
-	content = strings.ReplaceAll(content, "<code>", "`")
-	content = strings.ReplaceAll(content, "</code>", "`")
-	
-	// Remove other HTML tags
-	for strings.Contains(content, "<") && strings.Contains(content, ">") {
-		start := strings.Index(content, "<")
-		end := strings.Index(content, ">")
-		if start < end {
-			content = content[:start] + content[end+1:]
-		} else {
-			break
+// CheckAnswers looks up the answer count and accepted answer of earlier questions, up to 100
+// per request and site
+func (s *StackOverflowSource) CheckAnswers(ctx context.Context, mentionIDs []string) (map[string]AnswerState, error) {
+	var sites []string
+	questionIDs := make(map[string][]string)
+	for _, id := range mentionIDs {
+		if site, questionID, ok := parseStackExchangeMentionID(id); ok {
+			if _, seen := questionIDs[site]; !seen {
+				sites = append(sites, site)
+			}
+			questionIDs[site] = append(questionIDs[site], questionID)
 		}
 	}
-	
-	return strings.TrimSpace(content)
+
+	states := make(map[string]AnswerState)
+	for _, site := range sites {
+		if err := s.checkSiteAnswers(ctx, site, questionIDs[site], states); err != nil {
+			return states, err
+		}
+	}
+	return states, nil
+}
+
+// checkSiteAnswers records the answer states of questions on one site in states
+func (s *StackOverflowSource) checkSiteAnswers(ctx context.Context, site string, questionIDs []string, states map[string]AnswerState) error {
+	for start := 0; start < len(questionIDs); start += 100 {
+		batch := questionIDs[start:min(start+100, len(questionIDs))]
+		questionsURL := fmt.Sprintf("https://api.stackexchange.com/2.3/questions/%s?site=%s&pagesize=100",
+			url.PathEscape(strings.Join(batch, ";")), url.QueryEscape(site))
+
+		resp, err := s.client.R().
+			SetContext(ctx).
+			Get(questionsURL)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode() != 200 {
+			return fmt.Errorf("stack overflow API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+		}
+
+		var questionsResp stackOverflowResponse
+		if err := json.Unmarshal(resp.Body(), &questionsResp); err != nil {
+			return fmt.Errorf("failed to parse Stack Overflow response: %w", err)
+		}
+
+		for _, question := range questionsResp.Items {
+			states[stackExchangeMentionID(site, question.QuestionID)] = AnswerState{
+				Answers:  question.AnswerCount,
+				Accepted: question.AcceptedAnswerID != 0,
+			}
+		}
+	}
+
+	return nil
+}
+
+// stackExchangeSiteName returns the display name of a site, e.g. "Server Fault"
+func stackExchangeSiteName(site string) string {
+	if name, ok := stackExchangeSiteNames[site]; ok {
+		return name
+	}
+	return site
+}
+
+// stackExchangeMentionID keeps the "stackoverflow_<id>" IDs of Stack Overflow questions and
+// adds the site to others, e.g. "stackoverflow_serverfault_<id>", as question IDs are per site
+func stackExchangeMentionID(site string, questionID int) string {
+	if site == "stackoverflow" {
+		return fmt.Sprintf("stackoverflow_%d", questionID)
+	}
+	return fmt.Sprintf("stackoverflow_%s_%d", site, questionID)
+}
+
+// parseStackExchangeMentionID returns the site and question ID of a mention ID from
+// stackExchangeMentionID
+func parseStackExchangeMentionID(id string) (site, questionID string, ok bool) {
+	rest, ok := strings.CutPrefix(id, "stackoverflow_")
+	if !ok {
+		return "", "", false
+	}
+	if site, questionID, found := strings.Cut(rest, "_"); found {
+		return site, questionID, true
+	}
+	return "stackoverflow", rest, true
 }
 
 func (s *StackOverflowSource) deduplicateMentions(mentions []models.Mention) []models.Mention {
@@ -0,0 +1,57 @@
+package sources
+
+import (
+	"context"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// SubmittedSourceName is the source of mentions submitted through POST /api/mentions
+const SubmittedSourceName = "submitted"
+
+// SubmissionStore keeps the mentions submitted through the API until runs collect them
+type SubmissionStore interface {
+	// Submissions returns the mentions submitted since the given time, oldest first
+	Submissions(since time.Time) ([]models.Mention, error)
+}
+
+// SubmittedSource feeds mentions people and other systems submitted, e.g. support tickets or
+// field reports, into runs like any searched source, so they are filtered, enriched, stored
+// and reported the same way. Each run collects the submissions made within its window that
+// match its keywords.
+type SubmittedSource struct {
+	store SubmissionStore
+}
+
+// NewSubmittedSource creates a source collecting the submissions in store; it is disabled
+// without one
+func NewSubmittedSource(store SubmissionStore) *SubmittedSource {
+	return &SubmittedSource{store: store}
+}
+
+func (s *SubmittedSource) GetName() string { return SubmittedSourceName }
+func (s *SubmittedSource) IsEnabled() bool { return s.store != nil }
+
+func (s *SubmittedSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if s.store == nil {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	submitted, err := s.store.Submissions(time.Now().Add(-since))
+	if err != nil {
+		return nil, err
+	}
+
+	var mentions []models.Mention
+	for _, mention := range submitted {
+		mention.Keywords = MatchKeywords(mention, keywords)
+		if len(mention.Keywords) > 0 {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions, nil
+}
@@ -0,0 +1,162 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// ThreadsSource searches public Threads posts through the Threads API keyword search. The
+// access token needs the threads_keyword_search permission.
+type ThreadsSource struct {
+	accessToken string
+	client      *resty.Client
+	queries     *QueryBuilder
+}
+
+// threadsKeywordSearchURL is the Threads API keyword search endpoint
+const threadsKeywordSearchURL = "https://graph.threads.net/v1.0/keyword_search"
+
+type threadsSearchResponse struct {
+	Data []threadsPost `json:"data"`
+}
+
+type threadsPost struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Username  string `json:"username"`
+	Permalink string `json:"permalink"`
+	Timestamp string `json:"timestamp"`
+}
+
+// threadsTimeLayout is the timestamp format used by the Threads API, e.g. 2024-06-01T12:00:00+0000
+const threadsTimeLayout = "2006-01-02T15:04:05-0700"
+
+// NewThreadsSource creates a new Threads source
+func NewThreadsSource(accessToken string) *ThreadsSource {
+	return &ThreadsSource{
+		accessToken: accessToken,
+		client: resty.New().
+			SetTimeout(30*time.Second).
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		queries: NewQueryBuilder(nil),
+	}
+}
+
+// WithQueries searches each keyword's aliases too, from the shared keyword templates
+func (t *ThreadsSource) WithQueries(queries *QueryBuilder) *ThreadsSource {
+	t.queries = queries
+	return t
+}
+
+func (t *ThreadsSource) GetName() string {
+	return "threads"
+}
+
+func (t *ThreadsSource) IsEnabled() bool {
+	return t.accessToken != ""
+}
+
+func (t *ThreadsSource) FetchMentions(ctx context.Context, keywords []string, since time.Duration) ([]models.Mention, error) {
+	if !t.IsEnabled() {
+		logrus.Debug("Threads source disabled - missing access token")
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var allMentions []models.Mention
+
+	for _, query := range t.queries.Plan(keywords) {
+		for _, name := range queryNames(query) {
+			mentions, err := t.searchKeyword(ctx, name, since)
+			if err != nil {
+				logrus.Errorf("Failed to search Threads for keyword '%s': %v", name, err)
+				continue
+			}
+
+			for _, mention := range mentions {
+				if !seen[mention.ID] {
+					seen[mention.ID] = true
+					mention.Keywords = []string{query.Keyword}
+					allMentions = append(allMentions, mention)
+				}
+			}
+		}
+	}
+
+	return allMentions, nil
+}
+
+func (t *ThreadsSource) searchKeyword(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
+	resp, err := t.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"q":            keyword,
+			"search_type":  "RECENT",
+			"fields":       "id,text,username,permalink,timestamp",
+			"since":        strconv.FormatInt(time.Now().Add(-since).Unix(), 10),
+			"limit":        "100",
+			"access_token": t.accessToken,
+		}).
+		Get(threadsKeywordSearchURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() == 429 {
+		logrus.Warnf("Threads API rate limit hit for keyword '%s' - skipping", keyword)
+		return nil, nil
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("Threads API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var searchResp threadsSearchResponse
+	if err := json.Unmarshal(resp.Body(), &searchResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Threads response: %w", err)
+	}
+
+	var mentions []models.Mention
+	for _, post := range searchResp.Data {
+		mention, err := t.convertPost(post, keyword)
+		if err != nil {
+			logrus.Errorf("Failed to parse Threads timestamp: %v", err)
+			continue
+		}
+		mentions = append(mentions, mention)
+	}
+
+	return mentions, nil
+}
+
+func (t *ThreadsSource) convertPost(post threadsPost, keyword string) (models.Mention, error) {
+	createdAt, err := time.Parse(threadsTimeLayout, post.Timestamp)
+	if err != nil {
+		return models.Mention{}, err
+	}
+
+	url := post.Permalink
+	if url == "" && post.Username != "" {
+		url = fmt.Sprintf("https://www.threads.net/@%s", post.Username)
+	}
+
+	return models.Mention{
+		ID:        fmt.Sprintf("threads_%s", post.ID),
+		Source:    "threads",
+		Platform:  "Threads",
+		Title:     "", // Threads posts don't have titles
+		Content:   strings.TrimSpace(post.Text),
+		Author:    post.Username,
+		URL:       url,
+		CreatedAt: createdAt,
+		Keywords:  []string{keyword},
+	}, nil
+}
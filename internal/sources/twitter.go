@@ -5,18 +5,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/usage"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
 )
 
+// twitterAPIURL is the X API v2 base URL
+const twitterAPIURL = "https://api.twitter.com"
+
 // TwitterSource implements Twitter/X API source
 type TwitterSource struct {
 	bearerToken string
 	client      *resty.Client
+	baseURL     string
+	queries     *QueryBuilder
+	scheduler   *twitterScheduler
 }
 
 type twitterSearchResponse struct {
@@ -50,7 +56,39 @@ func NewTwitterSource(bearerToken string) *TwitterSource {
 		client: resty.New().
 			SetTimeout(30*time.Second).
 			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		baseURL:   twitterAPIURL,
+		queries:   NewQueryBuilder(nil),
+		scheduler: newTwitterScheduler(),
+	}
+}
+
+// WithQueries sets the keyword query templates searches are built from
+func (t *TwitterSource) WithQueries(queries *QueryBuilder) *TwitterSource {
+	t.queries = queries
+	return t
+}
+
+// WithBudget sets the search requests allowed per 15-minute rate limit window, the pages
+// fetched per query before the rest is left to a later run, and how long a run waits for
+// the budget before deferring its remaining queries
+func (t *TwitterSource) WithBudget(requestsPerWindow, maxPages int, maxWait time.Duration) *TwitterSource {
+	if requestsPerWindow > 0 {
+		t.scheduler.requestsPerWindow = requestsPerWindow
+	}
+	if maxPages > 0 {
+		t.scheduler.maxPages = maxPages
+	}
+	if maxWait >= 0 {
+		t.scheduler.maxWait = maxWait
 	}
+	return t
+}
+
+// WithScheduleStore persists the search schedule, so queries a run leaves unfinished are
+// resumed by the next run even in another process
+func (t *TwitterSource) WithScheduleStore(store TwitterScheduleStore) *TwitterSource {
+	t.scheduler.store = store
+	return t
 }
 
 func (t *TwitterSource) GetName() string {
@@ -67,151 +105,113 @@ func (t *TwitterSource) FetchMentions(ctx context.Context, keywords []string, si
 		return nil, nil
 	}
 
-	var allMentions []models.Mention
-	searchedKeywords := make(map[string]bool)
-
-	for i, keyword := range keywords {
-		// Skip redundant keywords that are already covered by combined searches
-		if strings.ToLower(keyword) == "azure kubernetes service" {
-			if searchedKeywords["aks"] {
-				logrus.Debugf("Skipping '%s' - already covered by AKS search", keyword)
-				continue
-			}
-		}
+	t.scheduler.mu.Lock()
+	defer t.scheduler.mu.Unlock()
 
-		query := t.buildSearchQuery(keyword)
-		if query == "" {
-			logrus.Debugf("Skipping keyword '%s' - covered by previous search", keyword)
-			continue
-		}
+	// Keywords searched as another keyword's terms are left out of the plan to save API calls
+	templates := make(map[string]models.KeywordQuery)
+	var planned []string
+	for _, query := range t.queries.Plan(keywords) {
+		templates[query.Keyword] = query
+		planned = append(planned, query.Keyword)
+	}
 
-		// Add delay between keyword searches to avoid rate limiting
-		if i > 0 {
-			logrus.Debugf("Adding 3-second delay before searching for keyword '%s' to avoid Twitter rate limits", keyword)
-			select {
-			case <-ctx.Done():
-				return allMentions, ctx.Err()
-			case <-time.After(3 * time.Second):
-				// Continue with next keyword
-			}
+	schedule := t.scheduler.load()
+	now := t.scheduler.now()
+	queries, pending := t.scheduler.plan(schedule, planned, now.Add(-since), now.Add(-twitterEndTimeLag))
+
+	kept := len(pending)
+	var allMentions []models.Mention
+	var fetchErr error
+	for i, query := range queries {
+		if fetchErr != nil {
+			pending = append(pending, queries[i:]...)
+			break
 		}
 
-		logrus.Infof("Searching Twitter for keyword: %s", keyword)
-		mentions, err := t.searchKeyword(ctx, keyword, since)
+		mentions, unfinished, err := t.searchQuery(ctx, schedule, templates[query.Keyword], query)
+		allMentions = append(allMentions, mentions...)
 		if err != nil {
-			logrus.Errorf("Failed to search Twitter for keyword '%s': %v", keyword, err)
-			// Continue with other keywords instead of failing completely
-			continue
+			if ctx.Err() != nil {
+				fetchErr = ctx.Err()
+			} else {
+				// Continue with other keywords instead of failing completely
+				logrus.Errorf("Failed to search Twitter for keyword '%s': %v", query.Keyword, err)
+			}
 		}
+		if unfinished != nil {
+			// The budget is spent, so the remaining queries wait for a later run
+			pending = append(pending, *unfinished)
+			pending = append(pending, queries[i+1:]...)
+			break
+		}
+	}
 
-		logrus.Infof("Found %d mentions on Twitter for keyword '%s'", len(mentions), keyword)
-		allMentions = append(allMentions, mentions...)
-		searchedKeywords[strings.ToLower(keyword)] = true
+	if deferred := len(pending) - kept; deferred > 0 {
+		logrus.Infof("Deferring %d Twitter searches to a later run to stay within the rate limit", deferred)
 	}
+	schedule.Pending = pending
+	t.scheduler.save(schedule)
 
 	deduplicated := t.deduplicateMentions(allMentions)
 	logrus.Infof("Total Twitter mentions after deduplication: %d", len(deduplicated))
 
-	return deduplicated, nil
+	return deduplicated, fetchErr
 }
 
-func (t *TwitterSource) searchKeyword(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	// Build search query
-	startTime := time.Now().Add(-since).Format(time.RFC3339)
-
-	// Create a more specific query to avoid false positives
-	query := t.buildSearchQuery(keyword)
-	encodedQuery := url.QueryEscape(query)
-
-	searchURL := fmt.Sprintf("https://api.twitter.com/2/tweets/search/recent?query=%s&start_time=%s&max_results=100&tweet.fields=created_at,author_id,public_metrics,referenced_tweets",
-		encodedQuery, startTime)
-
-	logrus.Debugf("Twitter API request for keyword '%s': %s", keyword, searchURL)
-
-	resp, err := t.client.R().
-		SetContext(ctx).
-		SetHeader("Authorization", "Bearer "+t.bearerToken).
-		Get(searchURL)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Handle rate limiting (429) - fail fast to avoid blocking other sources
-	if resp.StatusCode() == 429 {
-		logrus.Warnf("Twitter API rate limit hit for keyword '%s' - skipping to avoid blocking other sources", keyword)
-
-		// Get rate limit reset time from headers
-		resetTime := resp.Header().Get("x-rate-limit-reset")
-		if resetTime != "" {
-			logrus.Infof("Twitter rate limit will reset at: %s", resetTime)
-		}
-
-		// Return empty results instead of waiting - this allows other sources to complete
-		// and notifications to be sent for mentions found from other sources
-		logrus.Infof("Skipping Twitter search for keyword '%s' due to rate limit - other sources can still provide mentions", keyword)
-		return []models.Mention{}, nil
-	}
-
-	if resp.StatusCode() != 200 {
-		logrus.Errorf("Twitter API error for keyword '%s': status %d, body: %s", keyword, resp.StatusCode(), string(resp.Body()))
-		return nil, fmt.Errorf("twitter API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+// searchQuery fetches the pages of a query while the request budget allows, up to the page
+// cap. It returns the query with the page to continue from when the budget or the page cap
+// stopped it before the last page.
+func (t *TwitterSource) searchQuery(ctx context.Context, schedule *TwitterSchedule, template models.KeywordQuery, query TwitterQuery) ([]models.Mention, *TwitterQuery, error) {
+	if template.Keyword == "" {
+		template = t.queries.Template(query.Keyword)
 	}
-
-	var searchResp twitterSearchResponse
-	if err := json.Unmarshal(resp.Body(), &searchResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Twitter response: %w", err)
-	}
-
-	logrus.Infof("Twitter API returned %d tweets for keyword '%s'", len(searchResp.Data), keyword)
+	logrus.Infof("Searching Twitter for keyword: %s", query.Keyword)
 
 	var mentions []models.Mention
-
-	for _, tweet := range searchResp.Data {
-		// Skip retweets to avoid duplicates
-		if t.isRetweet(tweet) {
-			continue
+	for page := 0; page < t.scheduler.maxPages; page++ {
+		ok, err := t.scheduler.take(ctx, schedule)
+		if err != nil {
+			return mentions, &query, err
+		}
+		if !ok {
+			return mentions, &query, nil
 		}
 
-		createdAt, err := time.Parse(time.RFC3339, tweet.CreatedAt)
+		found, nextToken, limited, err := t.searchPage(ctx, schedule, template, query)
+		if limited {
+			return mentions, &query, nil
+		}
 		if err != nil {
-			logrus.Errorf("Failed to parse Twitter timestamp: %v", err)
-			continue
+			return mentions, nil, err
 		}
+		mentions = append(mentions, found...)
 
-		mention := models.Mention{
-			ID:           fmt.Sprintf("twitter_%s", tweet.ID),
-			Source:       "twitter",
-			Platform:     "X.com (Twitter)",
-			Title:        "", // Twitter doesn't have titles
-			Content:      tweet.Text,
-			Author:       tweet.AuthorID, // In production, you'd resolve this to username
-			URL:          fmt.Sprintf("https://twitter.com/i/status/%s", tweet.ID),
-			CreatedAt:    createdAt,
-			Score:        tweet.PublicMetrics.LikeCount,
-			CommentCount: tweet.PublicMetrics.ReplyCount,
-			Keywords:     []string{keyword},
+		if nextToken == "" {
+			logrus.Infof("Found %d mentions on Twitter for keyword '%s'", len(mentions), query.Keyword)
+			return mentions, nil, nil
 		}
-
-		mentions = append(mentions, mention)
+		query.NextToken = nextToken
 	}
 
-	return mentions, nil
+	logrus.Infof("Found %d mentions on Twitter for keyword '%s', continuing from page %d in a later run", len(mentions), query.Keyword, t.scheduler.maxPages+1)
+	return mentions, &query, nil
 }
 
-// searchKeywordWithRetry performs a retry with limited attempts
-func (t *TwitterSource) searchKeywordWithRetry(ctx context.Context, keyword string, since time.Duration, attempt int) ([]models.Mention, error) {
-	if attempt > 2 {
-		logrus.Errorf("Twitter API max retries exceeded for keyword '%s'", keyword)
-		return nil, fmt.Errorf("twitter API max retries exceeded for keyword '%s'", keyword)
+// searchPage fetches one page of a query. limited reports that X refused the request with 429.
+func (t *TwitterSource) searchPage(ctx context.Context, schedule *TwitterSchedule, template models.KeywordQuery, query TwitterQuery) (mentions []models.Mention, nextToken string, limited bool, err error) {
+	params := url.Values{}
+	params.Set("query", twitterQuery(template))
+	params.Set("start_time", query.Start.UTC().Format(time.RFC3339))
+	params.Set("end_time", query.End.UTC().Format(time.RFC3339))
+	params.Set("max_results", "100")
+	params.Set("tweet.fields", "created_at,author_id,public_metrics,referenced_tweets")
+	if query.NextToken != "" {
+		params.Set("next_token", query.NextToken)
 	}
+	searchURL := t.baseURL + "/2/tweets/search/recent?" + params.Encode()
 
-	startTime := time.Now().Add(-since).Format(time.RFC3339)
-	query := t.buildSearchQuery(keyword)
-	encodedQuery := url.QueryEscape(query)
-
-	searchURL := fmt.Sprintf("https://api.twitter.com/2/tweets/search/recent?query=%s&start_time=%s&max_results=100&tweet.fields=created_at,author_id,public_metrics,referenced_tweets",
-		encodedQuery, startTime)
+	logrus.Debugf("Twitter API request for keyword '%s': %s", query.Keyword, searchURL)
 
 	resp, err := t.client.R().
 		SetContext(ctx).
@@ -219,75 +219,74 @@ func (t *TwitterSource) searchKeywordWithRetry(ctx context.Context, keyword stri
 		Get(searchURL)
 
 	if err != nil {
-		return nil, err
+		return nil, "", false, err
 	}
 
+	t.scheduler.observe(schedule, resp.StatusCode(), resp.Header().Get("x-rate-limit-remaining"), resp.Header().Get("x-rate-limit-reset"))
+
+	// Rate limited: the query is kept for a later run instead of blocking other sources
 	if resp.StatusCode() == 429 {
-		logrus.Warnf("Twitter API still rate limited on retry %d for keyword '%s' - skipping", attempt, keyword)
-		// Return empty results instead of failing completely
-		return []models.Mention{}, nil
+		logrus.Warnf("Twitter API rate limit hit for keyword '%s' - resuming after %s", query.Keyword, schedule.BlockedUntil.Format(time.RFC3339))
+		return nil, "", true, nil
 	}
 
 	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("twitter API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+		logrus.Errorf("Twitter API error for keyword '%s': status %d, body: %s", query.Keyword, resp.StatusCode(), string(resp.Body()))
+		return nil, "", false, fmt.Errorf("twitter API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
 	}
 
 	var searchResp twitterSearchResponse
 	if err := json.Unmarshal(resp.Body(), &searchResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Twitter response: %w", err)
+		return nil, "", false, fmt.Errorf("failed to parse Twitter response: %w", err)
 	}
+	usage.FromContext(ctx).AddTwitter(1, len(searchResp.Data))
+
+	logrus.Infof("Twitter API returned %d tweets for keyword '%s'", len(searchResp.Data), query.Keyword)
 
-	var mentions []models.Mention
 	for _, tweet := range searchResp.Data {
+		// Skip retweets to avoid duplicates
 		if t.isRetweet(tweet) {
 			continue
 		}
 
-		createdAt, err := time.Parse(time.RFC3339, tweet.CreatedAt)
+		mention, err := t.tweetMention(tweet, []string{query.Keyword})
 		if err != nil {
 			logrus.Errorf("Failed to parse Twitter timestamp: %v", err)
 			continue
 		}
 
-		mention := models.Mention{
-			ID:           fmt.Sprintf("twitter_%s", tweet.ID),
-			Source:       "twitter",
-			Platform:     "X.com (Twitter)",
-			Title:        "",
-			Content:      tweet.Text,
-			Author:       tweet.AuthorID,
-			URL:          fmt.Sprintf("https://twitter.com/i/status/%s", tweet.ID),
-			CreatedAt:    createdAt,
-			Score:        tweet.PublicMetrics.LikeCount,
-			CommentCount: tweet.PublicMetrics.ReplyCount,
-			Keywords:     []string{keyword},
-		}
-
 		mentions = append(mentions, mention)
 	}
 
-	return mentions, nil
+	return mentions, searchResp.Meta.NextToken, false, nil
 }
 
-func (t *TwitterSource) buildSearchQuery(keyword string) string {
-	// Build a more sophisticated query to reduce false positives
-	// Also combine related keywords to reduce API calls
-	switch strings.ToLower(keyword) {
-	case "aks":
-		// For AKS, make sure we exclude weapon-related content
-		return fmt.Sprintf(`("%s" OR "Azure Kubernetes Service") (azure OR kubernetes OR microsoft OR container OR k8s) -rifle -gun -weapon -firearm -AK47`, keyword)
-	case "azure kubernetes service":
-		// Skip this if we already searched for "aks" - they're combined above
-		return ""
-	case "azure kubernetes fleet manager", "kubefleet":
-		return fmt.Sprintf(`("Azure Kubernetes Fleet Manager" OR "KubeFleet" OR "kube fleet") (azure OR kubernetes)`)
-	case "kaito":
-		return fmt.Sprintf(`"%s" (kubernetes OR k8s OR azure OR "AI inference")`, keyword)
-	case "azure container service":
-		return fmt.Sprintf(`"%s" (azure OR container OR microsoft)`, keyword)
-	default:
-		return fmt.Sprintf(`"%s"`, keyword)
+// tweetMention converts a tweet returned by search or the filtered stream into a mention
+func (t *TwitterSource) tweetMention(tweet twitterTweet, keywords []string) (models.Mention, error) {
+	createdAt, err := time.Parse(time.RFC3339, tweet.CreatedAt)
+	if err != nil {
+		return models.Mention{}, err
 	}
+
+	return models.Mention{
+		ID:           fmt.Sprintf("twitter_%s", tweet.ID),
+		Source:       "twitter",
+		Platform:     "X.com (Twitter)",
+		Title:        "", // Twitter doesn't have titles
+		Content:      tweet.Text,
+		Author:       tweet.AuthorID, // In production, you'd resolve this to username
+		URL:          fmt.Sprintf("https://twitter.com/i/status/%s", tweet.ID),
+		CreatedAt:    createdAt,
+		Score:        tweet.PublicMetrics.LikeCount,
+		CommentCount: tweet.PublicMetrics.ReplyCount,
+		Keywords:     keywords,
+	}, nil
+}
+
+// buildSearchQuery renders the keyword's query template in X search syntax, combining
+// related names and excluding known false positives
+func (t *TwitterSource) buildSearchQuery(keyword string) string {
+	return twitterQuery(t.queries.Template(keyword))
 }
 
 func (t *TwitterSource) isRetweet(tweet twitterTweet) bool {
@@ -0,0 +1,209 @@
+package sources
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// twitterRateWindow is the window X API rate limits are counted over
+	twitterRateWindow = 15 * time.Minute
+	// twitterRecentSearchLimit is how far back recent search reaches; older windows are dropped
+	twitterRecentSearchLimit = 7*24*time.Hour - time.Minute
+	// twitterEndTimeLag keeps end_time at least the 10 seconds before the request X requires
+	twitterEndTimeLag = 15 * time.Second
+
+	// Scheduler defaults for the basic X API tier
+	defaultTwitterRequestsPerWindow = 60
+	defaultTwitterMaxPages          = 3
+	defaultTwitterMaxWait           = 30 * time.Second
+)
+
+// TwitterQuery is a keyword search over a time window that a run could not finish, resumed
+// by a later run
+type TwitterQuery struct {
+	Keyword   string    `json:"keyword"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	NextToken string    `json:"next_token,omitempty"` // Page to continue from, empty for the first
+}
+
+// TwitterSchedule is the search scheduler's state carried between runs
+type TwitterSchedule struct {
+	Pending      []TwitterQuery `json:"pending"`
+	Tokens       float64        `json:"tokens"`                  // Requests left in the budget at UpdatedAt
+	UpdatedAt    time.Time      `json:"updated_at"`              // When Tokens was last refilled
+	BlockedUntil time.Time      `json:"blocked_until,omitempty"` // Rate limit reset after X refused a request
+}
+
+// TwitterScheduleStore persists the search scheduler's state, so processes started per run
+// (CronJobs) resume the queries earlier ones left unfinished
+type TwitterScheduleStore interface {
+	LoadTwitterSchedule() (*TwitterSchedule, error)
+	SaveTwitterSchedule(schedule *TwitterSchedule) error
+}
+
+// twitterScheduler spreads search requests over the rate limit window with a token bucket
+// holding requestsPerWindow requests, refilled evenly over the window
+type twitterScheduler struct {
+	requestsPerWindow int
+	maxPages          int
+	maxWait           time.Duration
+	store             TwitterScheduleStore
+
+	mu       sync.Mutex
+	schedule *TwitterSchedule // Kept in memory when there is no store
+	now      func() time.Time
+}
+
+func newTwitterScheduler() *twitterScheduler {
+	return &twitterScheduler{
+		requestsPerWindow: defaultTwitterRequestsPerWindow,
+		maxPages:          defaultTwitterMaxPages,
+		maxWait:           defaultTwitterMaxWait,
+		now:               time.Now,
+	}
+}
+
+// load returns the persisted schedule, or the in-memory one when there is no store or it
+// can't be read
+func (s *twitterScheduler) load() *TwitterSchedule {
+	if s.store != nil {
+		schedule, err := s.store.LoadTwitterSchedule()
+		if err != nil {
+			logrus.Warnf("Failed to load Twitter search schedule: %v", err)
+		} else if schedule != nil {
+			s.schedule = schedule
+		}
+	}
+	if s.schedule == nil {
+		s.schedule = &TwitterSchedule{Tokens: float64(s.requestsPerWindow), UpdatedAt: s.now()}
+	}
+	return s.schedule
+}
+
+func (s *twitterScheduler) save(schedule *TwitterSchedule) {
+	s.schedule = schedule
+	if s.store == nil {
+		return
+	}
+	if err := s.store.SaveTwitterSchedule(schedule); err != nil {
+		logrus.Warnf("Failed to save Twitter search schedule: %v", err)
+	}
+}
+
+// refill adds the requests the budget regained since it was last updated
+func (s *twitterScheduler) refill(schedule *TwitterSchedule, now time.Time) {
+	capacity := float64(s.requestsPerWindow)
+	if elapsed := now.Sub(schedule.UpdatedAt); elapsed > 0 {
+		schedule.Tokens += capacity * float64(elapsed) / float64(twitterRateWindow)
+	}
+	schedule.Tokens = math.Min(schedule.Tokens, capacity)
+	schedule.UpdatedAt = now
+}
+
+// take spends a request from the budget, waiting up to maxWait for one to become available.
+// It returns false when the budget won't allow a request in time, leaving the rest of the
+// queries to a later run.
+func (s *twitterScheduler) take(ctx context.Context, schedule *TwitterSchedule) (bool, error) {
+	for {
+		now := s.now()
+		s.refill(schedule, now)
+
+		var wait time.Duration
+		switch {
+		case now.Before(schedule.BlockedUntil):
+			wait = schedule.BlockedUntil.Sub(now)
+		case schedule.Tokens >= 1:
+			schedule.Tokens--
+			return true, nil
+		default:
+			perRequest := float64(twitterRateWindow) / float64(s.requestsPerWindow)
+			wait = time.Duration((1 - schedule.Tokens) * perRequest)
+		}
+
+		if wait > s.maxWait {
+			return false, nil
+		}
+		logrus.Debugf("Waiting %v for the Twitter request budget", wait.Round(time.Second))
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// observe aligns the budget with the rate limit headers of a response: X's count of the
+// remaining requests wins, and an exhausted or refused budget blocks until the reset
+func (s *twitterScheduler) observe(schedule *TwitterSchedule, status int, remaining, reset string) {
+	resetAt := time.Time{}
+	if seconds, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		resetAt = time.Unix(seconds, 0)
+	}
+
+	if left, err := strconv.Atoi(remaining); err == nil && float64(left) < schedule.Tokens {
+		schedule.Tokens = float64(left)
+	}
+	if status == 429 || remaining == "0" {
+		schedule.Tokens = 0
+		if resetAt.IsZero() {
+			resetAt = s.now().Add(twitterRateWindow)
+		}
+		schedule.BlockedUntil = resetAt
+	}
+}
+
+// plan orders the queries of a run: unfinished queries for the run's keywords first, then a
+// query per keyword over the run's window. Unfinished queries reaching past the recent
+// search limit are clamped to it, and those without a page to continue from are merged into
+// the keyword's new query. Pending queries for other keywords are returned to keep, and
+// queries of any keyword that recent search no longer reaches are dropped.
+func (s *twitterScheduler) plan(schedule *TwitterSchedule, keywords []string, start, end time.Time) (queries, keep []TwitterQuery) {
+	floor := s.now().Add(-twitterRecentSearchLimit)
+	if start.Before(floor) {
+		start = floor
+	}
+
+	wanted := make(map[string]bool)
+	for _, keyword := range keywords {
+		wanted[keyword] = true
+	}
+
+	starts := make(map[string]time.Time)
+	for _, pending := range schedule.Pending {
+		if !pending.End.After(floor) {
+			logrus.Warnf("Dropping unfinished Twitter search for '%s' before %s: older than recent search reaches", pending.Keyword, pending.End.Format(time.RFC3339))
+			continue
+		}
+		if !wanted[pending.Keyword] {
+			keep = append(keep, pending)
+			continue
+		}
+		if pending.Start.Before(floor) {
+			pending.Start = floor
+			pending.NextToken = ""
+		}
+		if pending.NextToken == "" && !pending.End.Before(start) {
+			if earliest, ok := starts[pending.Keyword]; !ok || pending.Start.Before(earliest) {
+				starts[pending.Keyword] = pending.Start
+			}
+			continue
+		}
+		queries = append(queries, pending)
+	}
+
+	for _, keyword := range keywords {
+		query := TwitterQuery{Keyword: keyword, Start: start, End: end}
+		if earliest, ok := starts[keyword]; ok && earliest.Before(start) {
+			query.Start = earliest
+		}
+		queries = append(queries, query)
+	}
+	return queries, keep
+}
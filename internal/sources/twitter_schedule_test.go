@@ -0,0 +1,162 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTwitterSchedule struct {
+	schedule *TwitterSchedule
+}
+
+func (m *memoryTwitterSchedule) LoadTwitterSchedule() (*TwitterSchedule, error) {
+	if m.schedule == nil {
+		return nil, nil
+	}
+	copied := *m.schedule
+	copied.Pending = append([]TwitterQuery(nil), m.schedule.Pending...)
+	return &copied, nil
+}
+
+func (m *memoryTwitterSchedule) SaveTwitterSchedule(schedule *TwitterSchedule) error {
+	m.schedule = schedule
+	return nil
+}
+
+func newTwitterServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *TwitterSource {
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	source := NewTwitterSource("token")
+	source.baseURL = server.URL
+	return source
+}
+
+func tweetPage(id, nextToken string) string {
+	return fmt.Sprintf(`{"data": [{"id": %q, "text": "AKS", "created_at": "2024-07-01T10:00:00Z"}], "meta": {"result_count": 1, "next_token": %q}}`, id, nextToken)
+}
+
+func TestTwitterSource_budgetDefersQueries(t *testing.T) {
+	var searched []string
+	source := newTwitterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		keyword := strings.Trim(r.URL.Query().Get("query"), `"`)
+		searched = append(searched, keyword)
+		assert.NotEmpty(t, r.URL.Query().Get("end_time"))
+		w.Write([]byte(tweetPage(keyword, "")))
+	})
+	store := &memoryTwitterSchedule{}
+	source.WithBudget(2, 1, 0).WithScheduleStore(store)
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"alpha", "beta", "gamma"}, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, mentions, 2)
+	assert.Equal(t, []string{"alpha", "beta"}, searched)
+	require.Len(t, store.schedule.Pending, 1)
+	assert.Equal(t, "gamma", store.schedule.Pending[0].Keyword)
+
+	// Once the budget refills, the next run resumes the deferred query first, merged with
+	// its new window
+	store.schedule.UpdatedAt = store.schedule.UpdatedAt.Add(-twitterRateWindow)
+	deferred := store.schedule.Pending[0]
+	searched = nil
+	_, err = source.FetchMentions(context.Background(), []string{"alpha", "beta", "gamma"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, searched)
+	require.Len(t, store.schedule.Pending, 1)
+	assert.Equal(t, "gamma", store.schedule.Pending[0].Keyword)
+	assert.Equal(t, deferred.Start, store.schedule.Pending[0].Start, "the deferred window is kept")
+}
+
+func TestTwitterSource_resumesPages(t *testing.T) {
+	var tokens []string
+	source := newTwitterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("next_token")
+		tokens = append(tokens, token)
+		switch token {
+		case "":
+			w.Write([]byte(tweetPage("1", "page2")))
+		case "page2":
+			w.Write([]byte(tweetPage("2", "page3")))
+		default:
+			w.Write([]byte(tweetPage("3", "")))
+		}
+	})
+	source.WithBudget(10, 2, 0)
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"aks"}, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, mentions, 2)
+	assert.Equal(t, []string{"", "page2"}, tokens)
+	require.Len(t, source.scheduler.schedule.Pending, 1)
+	assert.Equal(t, "page3", source.scheduler.schedule.Pending[0].NextToken)
+
+	// The next run continues the earlier window's pages before searching the new one
+	tokens = nil
+	mentions, err = source.FetchMentions(context.Background(), []string{"aks"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page3", "", "page2"}, tokens)
+	assert.Equal(t, "twitter_3", mentions[0].ID)
+}
+
+func TestTwitterSource_rateLimited(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute).Unix()
+	requests := 0
+	source := newTwitterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("x-rate-limit-remaining", "0")
+		w.Header().Set("x-rate-limit-reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	source.WithBudget(60, 1, time.Second)
+
+	mentions, err := source.FetchMentions(context.Background(), []string{"aks", "kaito"}, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, mentions)
+	assert.Equal(t, 1, requests, "no requests are sent until the limit resets")
+
+	schedule := source.scheduler.schedule
+	assert.Equal(t, time.Unix(reset, 0), schedule.BlockedUntil)
+	require.Len(t, schedule.Pending, 2)
+	assert.Equal(t, "aks", schedule.Pending[0].Keyword)
+	assert.Equal(t, "kaito", schedule.Pending[1].Keyword)
+
+	// Until the reset, runs defer without spending requests
+	_, err = source.FetchMentions(context.Background(), []string{"aks", "kaito"}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestTwitterScheduler_plan(t *testing.T) {
+	now := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+	scheduler := newTwitterScheduler()
+	scheduler.now = func() time.Time { return now }
+
+	schedule := &TwitterSchedule{Pending: []TwitterQuery{
+		{Keyword: "aks", Start: now.Add(-3 * time.Hour), End: now.Add(-90 * time.Minute)},
+		{Keyword: "aks", Start: now.Add(-10 * 24 * time.Hour), End: now.Add(-9 * 24 * time.Hour)},
+		{Keyword: "kaito", Start: now.Add(-8 * 24 * time.Hour), End: now.Add(-2 * time.Hour), NextToken: "next"},
+		{Keyword: "fleet", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		{Keyword: "kaito", Start: now.Add(-4 * time.Hour), End: now.Add(-30 * time.Minute)},
+		{Keyword: "istio", Start: now.Add(-9 * 24 * time.Hour), End: now.Add(-8 * 24 * time.Hour), NextToken: "next"},
+	}}
+	queries, keep := scheduler.plan(schedule, []string{"aks", "kaito"}, now.Add(-time.Hour), now)
+
+	floor := now.Add(-twitterRecentSearchLimit)
+	assert.Equal(t, []TwitterQuery{
+		{Keyword: "aks", Start: now.Add(-3 * time.Hour), End: now.Add(-90 * time.Minute)},
+		{Keyword: "kaito", Start: floor, End: now.Add(-2 * time.Hour)},
+		{Keyword: "aks", Start: now.Add(-time.Hour), End: now},
+		{Keyword: "kaito", Start: now.Add(-4 * time.Hour), End: now},
+	}, queries, "windows older than recent search are dropped or clamped to it, and overlapping ones merge")
+	assert.Equal(t, []TwitterQuery{{Keyword: "fleet", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}}, keep,
+		"other keywords' windows are kept until recent search no longer reaches them")
+}
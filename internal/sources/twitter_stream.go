@@ -0,0 +1,294 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	twitterStreamPath      = "/2/tweets/search/stream"
+	twitterStreamRulesPath = "/2/tweets/search/stream/rules"
+
+	// twitterStreamStallTimeout reconnects when nothing, not even a keep-alive, arrives for this long
+	twitterStreamStallTimeout = 90 * time.Second
+
+	// twitterStreamRuleTag prefixes the tags of rules owned by the bot, so rules added to the
+	// same project by other tools are left alone
+	twitterStreamRuleTag = "aks-mentions-bot:"
+)
+
+// errStreamRateLimited is returned when X refuses the stream connection with 429, which
+// calls for a longer backoff than other disconnects
+var errStreamRateLimited = errors.New("stream rate limited")
+
+// TwitterStream consumes the X API v2 filtered stream for near-real-time mentions
+type TwitterStream struct {
+	source       *TwitterSource
+	client       *resty.Client
+	onConnection func(connected bool)
+}
+
+type twitterStreamRule struct {
+	ID    string `json:"id,omitempty"`
+	Value string `json:"value"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+type twitterStreamRulesResponse struct {
+	Data   []twitterStreamRule `json:"data"`
+	Errors []struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+		Value  string `json:"value"`
+	} `json:"errors"`
+}
+
+type twitterStreamEvent struct {
+	Data          twitterTweet `json:"data"`
+	MatchingRules []struct {
+		ID  string `json:"id"`
+		Tag string `json:"tag"`
+	} `json:"matching_rules"`
+}
+
+// NewTwitterStream creates a filtered stream consumer sharing the search source's query building
+func NewTwitterStream(bearerToken string) *TwitterStream {
+	return &TwitterStream{
+		source: NewTwitterSource(bearerToken),
+		// No overall timeout: the connection stays open and X sends keep-alives every 20 seconds
+		client: resty.New().
+			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+	}
+}
+
+// WithConnectionHandler sets a function called when the stream connects and when it
+// disconnects, e.g. to fall back to polling search while reconnecting
+func (s *TwitterStream) WithConnectionHandler(handler func(connected bool)) *TwitterStream {
+	s.onConnection = handler
+	return s
+}
+
+// setConnected reports a connection change to the connection handler
+func (s *TwitterStream) setConnected(connected bool) {
+	if s.onConnection != nil {
+		s.onConnection(connected)
+	}
+}
+
+// WithQueries sets the keyword query templates stream rules are built from
+func (s *TwitterStream) WithQueries(queries *QueryBuilder) *TwitterStream {
+	s.source.WithQueries(queries)
+	return s
+}
+
+// SyncRules makes the bot's stream rules match the keywords, adding missing rules and deleting
+// bot rules for keywords that are no longer configured
+func (s *TwitterStream) SyncRules(ctx context.Context, keywords []string) error {
+	var existing twitterStreamRulesResponse
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(s.source.bearerToken).
+		SetResult(&existing).
+		Get(s.source.baseURL + twitterStreamRulesPath)
+	if err != nil {
+		return fmt.Errorf("failed to list stream rules: %w", err)
+	}
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("listing stream rules returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	add, remove := s.ruleChanges(existing.Data, keywords)
+
+	if len(remove) > 0 {
+		body := map[string]interface{}{"delete": map[string][]string{"ids": remove}}
+		resp, err := s.client.R().SetContext(ctx).SetAuthToken(s.source.bearerToken).SetBody(body).Post(s.source.baseURL + twitterStreamRulesPath)
+		if err != nil {
+			return fmt.Errorf("failed to delete stream rules: %w", err)
+		}
+		if resp.StatusCode() != 200 {
+			return fmt.Errorf("deleting stream rules returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+		}
+		logrus.Infof("Deleted %d stale X stream rules", len(remove))
+	}
+
+	if len(add) > 0 {
+		var added twitterStreamRulesResponse
+		resp, err := s.client.R().
+			SetContext(ctx).
+			SetAuthToken(s.source.bearerToken).
+			SetBody(map[string]interface{}{"add": add}).
+			SetResult(&added).
+			Post(s.source.baseURL + twitterStreamRulesPath)
+		if err != nil {
+			return fmt.Errorf("failed to add stream rules: %w", err)
+		}
+		if resp.StatusCode() != 200 && resp.StatusCode() != 201 {
+			return fmt.Errorf("adding stream rules returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+		}
+		for _, ruleErr := range added.Errors {
+			logrus.Warnf("X rejected stream rule %q: %s %s", ruleErr.Value, ruleErr.Title, ruleErr.Detail)
+		}
+		logrus.Infof("Added %d X stream rules", len(add)-len(added.Errors))
+	}
+
+	return nil
+}
+
+// ruleChanges compares the existing rules with the rules wanted for the keywords. Only rules
+// tagged by the bot are ever removed.
+func (s *TwitterStream) ruleChanges(existing []twitterStreamRule, keywords []string) (add []twitterStreamRule, remove []string) {
+	plan := s.source.queries.Plan(keywords)
+	wanted := make(map[string]twitterStreamRule)
+	for _, query := range plan {
+		// Retweets only repeat the original tweet
+		rule := twitterStreamRule{Value: twitterQuery(query) + " -is:retweet", Tag: twitterStreamRuleTag + query.Keyword}
+		wanted[rule.Value] = rule
+	}
+
+	for _, rule := range existing {
+		if !strings.HasPrefix(rule.Tag, twitterStreamRuleTag) {
+			continue
+		}
+		if _, ok := wanted[rule.Value]; ok {
+			delete(wanted, rule.Value)
+			continue
+		}
+		remove = append(remove, rule.ID)
+	}
+
+	for _, query := range plan {
+		if rule, ok := wanted[twitterQuery(query)+" -is:retweet"]; ok {
+			add = append(add, rule)
+			delete(wanted, rule.Value)
+		}
+	}
+	return add, remove
+}
+
+// Run connects to the filtered stream and calls handle for every matching tweet until ctx is
+// cancelled, reconnecting with backoff when the connection drops
+func (s *TwitterStream) Run(ctx context.Context, handle func(models.Mention)) error {
+	backoff := 0 * time.Second
+	for {
+		connected, err := s.consume(ctx, handle)
+		if connected {
+			s.setConnected(false)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			logrus.Warnf("X filtered stream disconnected: %v", err)
+		}
+		backoff = streamBackoff(backoff, connected, errors.Is(err, errStreamRateLimited))
+		logrus.Infof("Reconnecting to X filtered stream in %v", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// streamBackoff follows X's reconnection guidance: back off exponentially from one minute when
+// rate limited, from five seconds after other errors, and reset after a healthy connection
+func streamBackoff(previous time.Duration, connected, rateLimited bool) time.Duration {
+	initial, limit := 5*time.Second, 5*time.Minute
+	if rateLimited {
+		initial, limit = time.Minute, 15*time.Minute
+	}
+	if connected || previous < initial {
+		return initial
+	}
+	if next := previous * 2; next < limit {
+		return next
+	}
+	return limit
+}
+
+// consume reads the stream until it ends, reporting whether the connection was established
+func (s *TwitterStream) consume(ctx context.Context, handle func(models.Mention)) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stalled := time.AfterFunc(twitterStreamStallTimeout, cancel)
+	defer stalled.Stop()
+
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(s.source.bearerToken).
+		SetQueryParam("tweet.fields", "created_at,author_id,public_metrics,referenced_tweets").
+		SetDoNotParseResponse(true).
+		Get(s.source.baseURL + twitterStreamPath)
+	if err != nil {
+		return false, err
+	}
+
+	body := resp.RawBody()
+	defer body.Close()
+
+	if resp.StatusCode() == 429 {
+		return false, errStreamRateLimited
+	}
+	if resp.StatusCode() != 200 {
+		return false, fmt.Errorf("stream returned status %d", resp.StatusCode())
+	}
+	logrus.Info("Connected to X filtered stream")
+	s.setConnected(true)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		stalled.Reset(twitterStreamStallTimeout)
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue // keep-alive
+		}
+
+		mention, ok := s.parseEvent([]byte(line))
+		if ok {
+			handle(mention)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return true, err
+	}
+	return true, fmt.Errorf("stream closed by server")
+}
+
+// parseEvent converts a stream event into a mention, keyed by the keywords of its matching rules
+func (s *TwitterStream) parseEvent(line []byte) (models.Mention, bool) {
+	var event twitterStreamEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		logrus.Debugf("Ignoring unparseable X stream event: %v", err)
+		return models.Mention{}, false
+	}
+	if event.Data.ID == "" || s.source.isRetweet(event.Data) {
+		return models.Mention{}, false
+	}
+
+	var keywords []string
+	for _, rule := range event.MatchingRules {
+		if keyword := strings.TrimPrefix(rule.Tag, twitterStreamRuleTag); keyword != rule.Tag {
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	mention, err := s.source.tweetMention(event.Data, keywords)
+	if err != nil {
+		logrus.Errorf("Failed to parse Twitter timestamp: %v", err)
+		return models.Mention{}, false
+	}
+	return mention, true
+}
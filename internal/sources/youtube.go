@@ -7,16 +7,46 @@ import (
 	"net/url"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/sanitize"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
 )
 
+// youTubeAPIURL is the YouTube Data API endpoint
+const youTubeAPIURL = "https://www.googleapis.com/youtube/v3"
+
+// youTubeGamingCategory is the video category ID of Gaming
+const youTubeGamingCategory = "20"
+
+// youTubeShortMaxDuration is the longest video treated as a Short without a #shorts tag;
+// Shorts can be up to three minutes long
+const youTubeShortMaxDuration = 3 * time.Minute
+
+// youTubeChannelUploads is how many of a comment channel's latest uploads are candidates
+// for comment scanning
+const youTubeChannelUploads = 5
+
+// defaultYouTubeCommentVideos is how many videos' comments are scanned per run by default
+const defaultYouTubeCommentVideos = 10
+
 // YouTubeSource implements YouTube Data API source
 type YouTubeSource struct {
-	apiKey string
-	client *resty.Client
+	apiKey     string
+	client     *resty.Client
+	baseURL    string
+	maxResults int
+	queries    *QueryBuilder
+
+	trustedChannels         []string // Channel IDs or titles whose videos skip the filters
+	excludeShorts           bool
+	excludeNonEnglishGaming bool
+
+	commentChannels []string // Channel IDs whose latest uploads have their comments scanned
+	commentVideos   int      // Most videos whose comments are scanned per run
+	sanitizer       *sanitize.Sanitizer
 }
 
 type youTubeSearchResponse struct {
@@ -30,6 +60,7 @@ type youTubeVideo struct {
 	Snippet struct {
 		Title        string `json:"title"`
 		Description  string `json:"description"`
+		ChannelID    string `json:"channelId"`
 		ChannelTitle string `json:"channelTitle"`
 		PublishedAt  string `json:"publishedAt"`
 		Thumbnails   struct {
@@ -40,6 +71,42 @@ type youTubeVideo struct {
 	} `json:"snippet"`
 }
 
+type youTubeVideosResponse struct {
+	Items []youTubeVideoDetails `json:"items"`
+}
+
+// youTubeVideoDetails holds the video fields search results lack
+type youTubeVideoDetails struct {
+	ID      string `json:"id"`
+	Snippet struct {
+		Title                string `json:"title"`
+		Description          string `json:"description"`
+		CategoryID           string `json:"categoryId"`
+		DefaultLanguage      string `json:"defaultLanguage"`
+		DefaultAudioLanguage string `json:"defaultAudioLanguage"`
+	} `json:"snippet"`
+	ContentDetails struct {
+		Duration string `json:"duration"` // ISO 8601, e.g. "PT4M13S"
+	} `json:"contentDetails"`
+}
+
+type youTubePlaylistItemsResponse struct {
+	Items []struct {
+		Snippet struct {
+			Title      string `json:"title"`
+			ResourceID struct {
+				VideoID string `json:"videoId"`
+			} `json:"resourceId"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+// youTubeCommentVideo is a video whose comments are scanned
+type youTubeCommentVideo struct {
+	ID    string
+	Title string
+}
+
 type youTubeCommentsResponse struct {
 	Items []youTubeComment `json:"items"`
 }
@@ -65,7 +132,58 @@ func NewYouTubeSource(apiKey string) *YouTubeSource {
 		client: resty.New().
 			SetTimeout(30 * time.Second).
 			SetHeader("User-Agent", "AKS-Mentions-Bot/1.0"),
+		baseURL:       youTubeAPIURL,
+		maxResults:    50,
+		queries:       NewQueryBuilder(nil),
+		commentVideos: defaultYouTubeCommentVideos,
+		sanitizer:     sanitize.New(),
+	}
+}
+
+// WithCommentScanning scans the comments of up to maxVideos videos per run, 0 to scan none:
+// the keyword search results first, then the latest uploads of channels, given by channel ID.
+// Each scanned video costs one quota unit and each channel one more.
+func (y *YouTubeSource) WithCommentScanning(channels []string, maxVideos int) *YouTubeSource {
+	y.commentChannels = cleanList(channels)
+	if maxVideos >= 0 {
+		y.commentVideos = maxVideos
+	}
+	return y
+}
+
+// WithTrustedChannels sets channels, by ID or title, whose videos are never filtered out
+func (y *YouTubeSource) WithTrustedChannels(channels []string) *YouTubeSource {
+	y.trustedChannels = channels
+	return y
+}
+
+// WithVideoFilters drops Shorts and Gaming category videos that aren't in English, which
+// dominate results for "AKS". Filtering looks up each result's details, one extra quota unit
+// per search.
+func (y *YouTubeSource) WithVideoFilters(excludeShorts, excludeNonEnglishGaming bool) *YouTubeSource {
+	y.excludeShorts = excludeShorts
+	y.excludeNonEnglishGaming = excludeNonEnglishGaming
+	return y
+}
+
+// WithSanitizer sets how comments, which the API returns as HTML, are turned into mention content
+func (y *YouTubeSource) WithSanitizer(sanitizer *sanitize.Sanitizer) *YouTubeSource {
+	y.sanitizer = sanitizer
+	return y
+}
+
+// WithQueries sets the keyword query templates searches are built from
+func (y *YouTubeSource) WithQueries(queries *QueryBuilder) *YouTubeSource {
+	y.queries = queries
+	return y
+}
+
+// WithMaxResults overrides the number of videos requested per search (YouTube allows 1-50)
+func (y *YouTubeSource) WithMaxResults(maxResults int) *YouTubeSource {
+	if maxResults > 0 && maxResults <= 50 {
+		y.maxResults = maxResults
 	}
+	return y
 }
 
 func (y *YouTubeSource) GetName() string {
@@ -84,33 +202,28 @@ func (y *YouTubeSource) FetchMentions(ctx context.Context, keywords []string, si
 
 	var allMentions []models.Mention
 
-	for _, keyword := range keywords {
+	plan := y.queries.Plan(keywords)
+	for _, query := range plan {
 		// Search for videos
-		videoMentions, err := y.searchVideos(ctx, keyword, since)
+		videoMentions, err := y.searchVideos(ctx, query, since)
 		if err != nil {
-			logrus.Errorf("Failed to search YouTube videos for keyword '%s': %v", keyword, err)
+			logrus.Errorf("Failed to search YouTube videos for keyword '%s': %v", query.Keyword, err)
 		} else {
 			allMentions = append(allMentions, videoMentions...)
 		}
-
-		// Search for comments on relevant videos
-		commentMentions, err := y.searchComments(ctx, keyword, since)
-		if err != nil {
-			logrus.Errorf("Failed to search YouTube comments for keyword '%s': %v", keyword, err)
-		} else {
-			allMentions = append(allMentions, commentMentions...)
-		}
 	}
 
+	// Search the comments of the videos found and of the comment channels' latest uploads
+	allMentions = append(allMentions, y.searchComments(ctx, plan, allMentions, since)...)
+
 	return y.deduplicateMentions(allMentions), nil
 }
 
-func (y *YouTubeSource) searchVideos(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
+func (y *YouTubeSource) searchVideos(ctx context.Context, query models.KeywordQuery, since time.Duration) ([]models.Mention, error) {
 	publishedAfter := time.Now().Add(-since).Format(time.RFC3339)
-	query := url.QueryEscape(keyword)
 
-	searchURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/search?part=snippet&q=%s&type=video&publishedAfter=%s&maxResults=50&key=%s",
-		query, publishedAfter, y.apiKey)
+	searchURL := fmt.Sprintf("%s/search?part=snippet&q=%s&type=video&publishedAfter=%s&maxResults=%d&key=%s",
+		y.baseURL, url.QueryEscape(youTubeQuery(query)), publishedAfter, y.maxResults, y.apiKey)
 
 	resp, err := y.client.R().
 		SetContext(ctx).
@@ -132,9 +245,8 @@ func (y *YouTubeSource) searchVideos(ctx context.Context, keyword string, since
 	var mentions []models.Mention
 
 	for _, video := range searchResp.Items {
-		// Check if the video content contains our keyword (case-insensitive)
-		content := strings.ToLower(video.Snippet.Title + " " + video.Snippet.Description)
-		if !strings.Contains(content, strings.ToLower(keyword)) {
+		// Check if the video content contains our keyword or one of its terms (case-insensitive)
+		if !y.queries.Matches(query, video.Snippet.Title+" "+video.Snippet.Description) {
 			continue
 		}
 
@@ -151,54 +263,276 @@ func (y *YouTubeSource) searchVideos(ctx context.Context, keyword string, since
 			Title:     video.Snippet.Title,
 			Content:   video.Snippet.Description,
 			Author:    video.Snippet.ChannelTitle,
+			Channel:   video.Snippet.ChannelID,
 			URL:       fmt.Sprintf("https://www.youtube.com/watch?v=%s", video.ID.VideoID),
 			CreatedAt: publishedAt,
-			Keywords:  []string{keyword},
+			Keywords:  []string{query.Keyword},
 		}
 
 		mentions = append(mentions, mention)
 	}
 
-	return mentions, nil
+	return y.screenVideos(ctx, mentions), nil
+}
+
+// screenVideos drops the Shorts and non-English gaming videos the source is configured to
+// exclude, and labels the Shorts it keeps. Videos from trusted channels are always kept.
+// If the details can't be looked up, every video is kept.
+func (y *YouTubeSource) screenVideos(ctx context.Context, mentions []models.Mention) []models.Mention {
+	if (!y.excludeShorts && !y.excludeNonEnglishGaming) || len(mentions) == 0 {
+		return mentions
+	}
+
+	ids := make([]string, len(mentions))
+	for i, mention := range mentions {
+		ids[i] = strings.TrimPrefix(mention.ID, "youtube_video_")
+	}
+	details, err := y.videoDetails(ctx, ids)
+	if err != nil {
+		logrus.Warnf("Failed to look up YouTube video details, keeping all %d videos: %v", len(mentions), err)
+		return mentions
+	}
+
+	kept := mentions[:0]
+	dropped := 0
+	for i, mention := range mentions {
+		video, ok := details[ids[i]]
+		if !ok {
+			kept = append(kept, mention)
+			continue
+		}
+		short := isYouTubeShort(video)
+		if short {
+			mention.PostType = models.PostTypeShort
+		}
+		if !YouTubeChannelListed(y.trustedChannels, mention.Channel) {
+			if (short && y.excludeShorts) || (y.excludeNonEnglishGaming && isNonEnglishGaming(video)) {
+				dropped++
+				continue
+			}
+		}
+		kept = append(kept, mention)
+	}
+	if dropped > 0 {
+		logrus.Debugf("Dropped %d YouTube Shorts and non-English gaming videos", dropped)
+	}
+	return kept
 }
 
-func (y *YouTubeSource) searchComments(ctx context.Context, keyword string, since time.Duration) ([]models.Mention, error) {
-	// First, find relevant videos to search comments on
-	videos, err := y.searchVideos(ctx, "kubernetes azure", since)
+// videoDetails looks up the category, language and duration of up to 50 videos
+func (y *YouTubeSource) videoDetails(ctx context.Context, ids []string) (map[string]youTubeVideoDetails, error) {
+	resp, err := y.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"part": "snippet,contentDetails",
+			"id":   strings.Join(ids, ","),
+			"key":  y.apiKey,
+		}).
+		Get(y.baseURL + "/videos")
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("youtube videos API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
 
-	var allComments []models.Mention
+	var videosResp youTubeVideosResponse
+	if err := json.Unmarshal(resp.Body(), &videosResp); err != nil {
+		return nil, fmt.Errorf("failed to parse YouTube videos response: %w", err)
+	}
 
-	// Limit to avoid too many API calls
-	limit := 10
-	if len(videos) > limit {
-		videos = videos[:limit]
+	details := make(map[string]youTubeVideoDetails, len(videosResp.Items))
+	for _, video := range videosResp.Items {
+		details[video.ID] = video
 	}
+	return details, nil
+}
 
-	for _, video := range videos {
-		// Extract video ID from URL or use existing ID
-		videoID := y.extractVideoID(video.URL)
-		if videoID == "" {
+// YouTubeChannelListed reports whether a channel ID is in a list of channel IDs. Titles are
+// not matched: anyone can name a channel after a trusted one.
+func YouTubeChannelListed(channels []string, channelID string) bool {
+	if channelID == "" {
+		return false
+	}
+	for _, channel := range channels {
+		if strings.TrimSpace(channel) == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// isYouTubeShort reports whether a video is a Short: tagged #shorts, or three minutes long at most.
+// The API has no Shorts flag, and longer untagged Shorts are indistinguishable from videos.
+func isYouTubeShort(video youTubeVideoDetails) bool {
+	text := strings.ToLower(video.Snippet.Title + " " + video.Snippet.Description)
+	if strings.Contains(text, "#shorts") || strings.Contains(text, "#short ") {
+		return true
+	}
+	duration, ok := parseISODuration(video.ContentDetails.Duration)
+	return ok && duration > 0 && duration <= youTubeShortMaxDuration
+}
+
+// isNonEnglishGaming reports whether a video is in the Gaming category and not in English.
+// Videos without a language are judged by whether their title is mostly non-ASCII letters.
+func isNonEnglishGaming(video youTubeVideoDetails) bool {
+	if video.Snippet.CategoryID != youTubeGamingCategory {
+		return false
+	}
+
+	language := video.Snippet.DefaultAudioLanguage
+	if language == "" {
+		language = video.Snippet.DefaultLanguage
+	}
+	if language != "" {
+		return !strings.HasPrefix(strings.ToLower(language), "en")
+	}
+
+	letters, nonASCII := 0, 0
+	for _, r := range video.Snippet.Title {
+		if unicode.IsLetter(r) {
+			letters++
+			if r > unicode.MaxASCII {
+				nonASCII++
+			}
+		}
+	}
+	return letters > 0 && nonASCII*2 > letters
+}
+
+// parseISODuration parses the ISO 8601 durations the YouTube API uses, e.g. "PT1H2M3S" or "P1DT2H"
+func parseISODuration(value string) (time.Duration, bool) {
+	if !strings.HasPrefix(value, "P") {
+		return 0, false
+	}
+
+	var total time.Duration
+	inTime := false
+	number := 0
+	digits := false
+	for _, r := range value[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			number = number*10 + int(r-'0')
+			digits = true
 			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, false
 		}
+		switch {
+		case r == 'D' && !inTime:
+			total += time.Duration(number) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(number) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(number) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(number) * time.Second
+		default:
+			return 0, false
+		}
+		number, digits = 0, false
+	}
+	return total, !digits
+}
 
-		comments, err := y.getVideoComments(ctx, videoID, keyword)
+// searchComments scans the comments of candidate videos for every query: the videos the
+// keyword searches found, then the latest uploads of the comment channels, up to the
+// configured number of videos
+func (y *YouTubeSource) searchComments(ctx context.Context, plan []models.KeywordQuery, videos []models.Mention, since time.Duration) []models.Mention {
+	if y.commentVideos == 0 || len(plan) == 0 {
+		return nil
+	}
+
+	candidates := y.commentCandidates(ctx, videos)
+	cutoff := time.Now().Add(-since)
+	var allComments []models.Mention
+	for _, video := range candidates {
+		comments, err := y.getVideoComments(ctx, video, plan, cutoff)
 		if err != nil {
-			logrus.Errorf("Failed to get comments for video %s: %v", videoID, err)
+			logrus.Errorf("Failed to get comments for video %s: %v", video.ID, err)
 			continue
 		}
-
 		allComments = append(allComments, comments...)
 	}
+	return allComments
+}
 
-	return allComments, nil
+// commentCandidates returns the videos whose comments are scanned, found videos first. Channel
+// uploads are only looked up while there is room for them.
+func (y *YouTubeSource) commentCandidates(ctx context.Context, videos []models.Mention) []youTubeCommentVideo {
+	seen := make(map[string]bool)
+	var candidates []youTubeCommentVideo
+	add := func(video youTubeCommentVideo) {
+		if video.ID != "" && !seen[video.ID] && len(candidates) < y.commentVideos {
+			seen[video.ID] = true
+			candidates = append(candidates, video)
+		}
+	}
+
+	for _, video := range videos {
+		add(youTubeCommentVideo{ID: y.extractVideoID(video.URL), Title: video.Title})
+	}
+	for _, channel := range y.commentChannels {
+		if len(candidates) >= y.commentVideos {
+			break
+		}
+		uploads, err := y.channelUploads(ctx, channel)
+		if err != nil {
+			logrus.Warnf("Failed to list uploads of YouTube channel %s: %v", channel, err)
+			continue
+		}
+		for _, upload := range uploads {
+			add(upload)
+		}
+	}
+	return candidates
 }
 
-func (y *YouTubeSource) getVideoComments(ctx context.Context, videoID, keyword string) ([]models.Mention, error) {
-	commentsURL := fmt.Sprintf("https://www.googleapis.com/youtube/v3/commentThreads?part=snippet&videoId=%s&maxResults=100&key=%s",
-		videoID, y.apiKey)
+// channelUploads returns a channel's latest uploads from its uploads playlist, which costs one
+// quota unit where a search costs a hundred
+func (y *YouTubeSource) channelUploads(ctx context.Context, channelID string) ([]youTubeCommentVideo, error) {
+	if !strings.HasPrefix(channelID, "UC") {
+		return nil, fmt.Errorf("expected a channel ID starting with UC")
+	}
+
+	resp, err := y.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"part":       "snippet",
+			"playlistId": "UU" + strings.TrimPrefix(channelID, "UC"),
+			"maxResults": fmt.Sprintf("%d", youTubeChannelUploads),
+			"key":        y.apiKey,
+		}).
+		Get(y.baseURL + "/playlistItems")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("youtube playlist items API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+
+	var itemsResp youTubePlaylistItemsResponse
+	if err := json.Unmarshal(resp.Body(), &itemsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse YouTube playlist items response: %w", err)
+	}
+
+	var uploads []youTubeCommentVideo
+	for _, item := range itemsResp.Items {
+		uploads = append(uploads, youTubeCommentVideo{ID: item.Snippet.ResourceID.VideoID, Title: item.Snippet.Title})
+	}
+	return uploads, nil
+}
+
+// getVideoComments reads a video's latest 100 comment threads once, keeping the comments
+// posted since cutoff that match one of the queries
+func (y *YouTubeSource) getVideoComments(ctx context.Context, video youTubeCommentVideo, plan []models.KeywordQuery, cutoff time.Time) ([]models.Mention, error) {
+	commentsURL := fmt.Sprintf("%s/commentThreads?part=snippet&videoId=%s&maxResults=100&order=time&key=%s",
+		y.baseURL, url.QueryEscape(video.ID), y.apiKey)
 
 	resp, err := y.client.R().
 		SetContext(ctx).
@@ -221,13 +555,24 @@ func (y *YouTubeSource) getVideoComments(ctx context.Context, videoID, keyword s
 		return nil, fmt.Errorf("failed to parse YouTube comments response: %w", err)
 	}
 
+	title := fmt.Sprintf("Comment on video %s", video.ID)
+	if video.Title != "" {
+		title = "Comment on " + video.Title
+	}
+
 	var mentions []models.Mention
 
 	for _, comment := range commentsResp.Items {
-		commentText := comment.Snippet.TopLevelComment.Snippet.TextDisplay
-		
-		// Check if the comment contains our keyword (case-insensitive)
-		if !strings.Contains(strings.ToLower(commentText), strings.ToLower(keyword)) {
+		commentText := y.sanitizer.Text(comment.Snippet.TopLevelComment.Snippet.TextDisplay)
+
+		// Check if the comment mentions one of the keywords or their terms (case-insensitive)
+		var matched []string
+		for _, query := range plan {
+			if y.queries.Matches(query, commentText) {
+				matched = append(matched, query.Keyword)
+			}
+		}
+		if len(matched) == 0 {
 			continue
 		}
 
@@ -236,18 +581,21 @@ func (y *YouTubeSource) getVideoComments(ctx context.Context, videoID, keyword s
 			logrus.Errorf("Failed to parse YouTube comment timestamp: %v", err)
 			continue
 		}
+		if publishedAt.Before(cutoff) {
+			continue
+		}
 
 		mention := models.Mention{
 			ID:        fmt.Sprintf("youtube_comment_%s", comment.ID),
 			Source:    "youtube",
 			Platform:  "YouTube Comments",
-			Title:     fmt.Sprintf("Comment on video %s", videoID),
+			Title:     title,
 			Content:   commentText,
 			Author:    comment.Snippet.TopLevelComment.Snippet.AuthorDisplayName,
-			URL:       fmt.Sprintf("https://www.youtube.com/watch?v=%s&lc=%s", videoID, comment.ID),
+			URL:       fmt.Sprintf("https://www.youtube.com/watch?v=%s&lc=%s", video.ID, comment.ID),
 			CreatedAt: publishedAt,
 			Score:     comment.Snippet.TopLevelComment.Snippet.LikeCount,
-			Keywords:  []string{keyword},
+			Keywords:  matched,
 		}
 
 		mentions = append(mentions, mention)
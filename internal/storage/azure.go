@@ -2,12 +2,17 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,25 +22,28 @@ type AzureStorage struct {
 	containerName string
 }
 
-// Ensure AzureStorage implements StorageInterface
-var _ StorageInterface = (*AzureStorage)(nil)
+// Ensure AzureStorage implements StorageInterface and ConditionalStorage
+var _ ConditionalStorage = (*AzureStorage)(nil)
+
+// StorageAuth selects how AzureStorage authenticates. The zero value uses managed identity
+// through DefaultAzureCredential.
+type StorageAuth struct {
+	ConnectionString string // Account key or SAS connection string, e.g. for Azurite; the account name is not needed
+	SASToken         string // Shared access signature appended to the service URL
+	ServiceURL       string // Blob endpoint overriding https://<account>.blob.core.windows.net/
+}
 
 // NewAzureStorage creates a new Azure Storage client using managed identity
 func NewAzureStorage(accountName, containerName string) (*AzureStorage, error) {
-	if accountName == "" {
-		return nil, fmt.Errorf("storage account name is required")
-	}
-
-	// Use managed identity for authentication (following Azure best practices)
-	credential, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
-	}
+	return NewAzureStorageWithAuth(accountName, containerName, StorageAuth{})
+}
 
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
-	client, err := azblob.NewClient(serviceURL, credential, nil)
+// NewAzureStorageWithAuth creates a new Azure Storage client, authenticating with a connection
+// string or SAS token when one is given and with managed identity otherwise
+func NewAzureStorageWithAuth(accountName, containerName string, auth StorageAuth) (*AzureStorage, error) {
+	client, err := newBlobClient(accountName, auth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure blob client: %w", err)
+		return nil, err
 	}
 
 	storage := &AzureStorage{
@@ -51,21 +59,70 @@ func NewAzureStorage(accountName, containerName string) (*AzureStorage, error) {
 	return storage, nil
 }
 
+func newBlobClient(accountName string, auth StorageAuth) (*azblob.Client, error) {
+	if auth.ConnectionString != "" {
+		client, err := azblob.NewClientFromConnectionString(auth.ConnectionString, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure blob client from connection string: %w", err)
+		}
+		return client, nil
+	}
+
+	serviceURL := auth.ServiceURL
+	if serviceURL == "" {
+		if accountName == "" {
+			return nil, fmt.Errorf("storage account name is required")
+		}
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
+	}
+
+	if auth.SASToken != "" {
+		client, err := azblob.NewClientWithNoCredential(withSASToken(serviceURL, auth.SASToken), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure blob client with SAS token: %w", err)
+		}
+		return client, nil
+	}
+
+	// Use managed identity for authentication (following Azure best practices)
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+	}
+
+	client, err := azblob.NewClient(serviceURL, credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure blob client: %w", err)
+	}
+	return client, nil
+}
+
+// withSASToken appends a SAS token, with or without its leading "?", to the service URL
+func withSASToken(serviceURL, token string) string {
+	if !strings.HasSuffix(serviceURL, "/") {
+		serviceURL += "/"
+	}
+	return serviceURL + "?" + strings.TrimPrefix(token, "?")
+}
+
+// ensureContainer creates the container if it doesn't exist. A 403 is taken to mean the
+// credential may use the container but not create it, as with a container-scoped SAS, so the
+// container is assumed to exist; blob operations report it if it doesn't.
 func (s *AzureStorage) ensureContainer() error {
 	ctx := context.Background()
-	
-	// Try to create the container (this will fail if it already exists, which is fine)
+
 	_, err := s.client.CreateContainer(ctx, s.containerName, nil)
-	if err != nil {
-		// Check if the error is because container already exists
-		if !strings.Contains(err.Error(), "ContainerAlreadyExists") {
-			return fmt.Errorf("failed to create container: %w", err)
-		}
-		logrus.Debugf("Container %s already exists", s.containerName)
-	} else {
+	var respErr *azcore.ResponseError
+	switch {
+	case err == nil:
 		logrus.Infof("Created container %s", s.containerName)
+	case bloberror.HasCode(err, bloberror.ContainerAlreadyExists):
+		logrus.Debugf("Container %s already exists", s.containerName)
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden:
+		logrus.Infof("Not allowed to create container %s (%s), assuming it exists", s.containerName, respErr.ErrorCode)
+	default:
+		return fmt.Errorf("failed to create container: %w", err)
 	}
-
 	return nil
 }
 
@@ -93,6 +150,9 @@ func (s *AzureStorage) Retrieve(filename string) ([]byte, error) {
 
 	// Download the blob
 	response, err := s.client.DownloadStream(ctx, s.containerName, filename, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, filename)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to download blob %s: %w", filename, err)
 	}
@@ -107,6 +167,49 @@ func (s *AzureStorage) Retrieve(filename string) ([]byte, error) {
 	return data, nil
 }
 
+// RetrieveVersion gets a blob with its ETag
+func (s *AzureStorage) RetrieveVersion(filename string) ([]byte, string, error) {
+	response, err := s.client.DownloadStream(context.Background(), s.containerName, filename, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil, "", fmt.Errorf("%w: %s", ErrNotFound, filename)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download blob %s: %w", filename, err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read blob content: %w", err)
+	}
+	var version string
+	if response.ETag != nil {
+		version = string(*response.ETag)
+	}
+	return data, version, nil
+}
+
+// StoreIfVersion saves a blob only while its ETag is still version
+func (s *AzureStorage) StoreIfVersion(filename string, data []byte, version string) error {
+	etag := azcore.ETag(version)
+	_, err := s.client.UploadBuffer(context.Background(), s.containerName, filename, data, &azblob.UploadBufferOptions{
+		BlockSize:   int64(1024 * 1024),
+		Concurrency: 3,
+		AccessConditions: &blob.AccessConditions{
+			ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: &etag},
+		},
+	})
+	if bloberror.HasCode(err, bloberror.ConditionNotMet) {
+		return fmt.Errorf("%w: %s", ErrConditionNotMet, filename)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", filename, err)
+	}
+
+	logrus.Debugf("Stored %s in Azure Blob Storage at version %s", filename, version)
+	return nil
+}
+
 // List returns a list of blobs in the container
 func (s *AzureStorage) List(prefix string) ([]string, error) {
 	ctx := context.Background()
@@ -0,0 +1,24 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/azure/aks-mentions-bot/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureStorage_Azurite(t *testing.T) {
+	store := testutil.NewAzuriteStorage(t)
+	testutil.StorageContract(t, store)
+}
+
+func TestSearchIndex_Azurite(t *testing.T) {
+	store := testutil.NewAzuriteStorage(t)
+	require.NoError(t, store.Store("mentions-2025-06-01-reddit.json", []byte(`[{"id":"1","title":"AKS upgrade stuck","source":"reddit","url":"https://reddit.com/1","created_at":"2025-06-01T10:00:00Z"}]`)))
+
+	index, err := storage.RebuildSearchIndex(store)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index.Len())
+}
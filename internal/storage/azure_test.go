@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// azuriteConnectionString is Azurite's well-known development account
+const azuriteConnectionString = "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==;BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;"
+
+func TestNewBlobClient(t *testing.T) {
+	client, err := newBlobClient("", StorageAuth{ConnectionString: azuriteConnectionString})
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:10000/devstoreaccount1/", client.URL())
+
+	client, err = newBlobClient("account", StorageAuth{SASToken: "?sv=2022-11-02&sig=abc"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://account.blob.core.windows.net/?sv=2022-11-02&sig=abc", client.URL())
+
+	client, err = newBlobClient("", StorageAuth{SASToken: "sv=2022-11-02&sig=abc", ServiceURL: "http://127.0.0.1:10000/devstoreaccount1"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:10000/devstoreaccount1/?sv=2022-11-02&sig=abc", client.URL())
+
+	_, err = newBlobClient("", StorageAuth{})
+	assert.ErrorContains(t, err, "storage account name is required")
+}
+
+func TestAzureStorage_ensureContainer(t *testing.T) {
+	status, code := http.StatusCreated, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/mentions", r.URL.Path)
+		if code != "" {
+			w.Header().Set("x-ms-error-code", code)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client, err := newBlobClient("", StorageAuth{SASToken: "sv=2022-11-02&sig=abc", ServiceURL: server.URL})
+	require.NoError(t, err)
+	storage := &AzureStorage{client: client, containerName: "mentions"}
+	require.NoError(t, storage.ensureContainer())
+
+	status, code = http.StatusConflict, "ContainerAlreadyExists"
+	require.NoError(t, storage.ensureContainer())
+
+	// A container SAS can't create containers; the container is assumed to exist
+	status, code = http.StatusForbidden, "AuthorizationPermissionMismatch"
+	require.NoError(t, storage.ensureContainer())
+
+	status, code = http.StatusBadRequest, "InvalidResourceName"
+	assert.ErrorContains(t, storage.ensureContainer(), "failed to create container")
+}
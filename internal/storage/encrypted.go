@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// EncryptionKeyBlob holds the data key blobs are encrypted with, wrapped by the key
+	// encryption key so only its holder can use it
+	EncryptionKeyBlob = "encryption/key.json"
+
+	// encryptionHeader marks encrypted blobs, telling them apart from blobs stored before
+	// encryption was enabled
+	encryptionHeader = "AKSMENC1"
+
+	// keyWrapTimeout bounds each call to wrap or unwrap the data key
+	keyWrapTimeout = 30 * time.Second
+)
+
+// ErrDecryption is returned for encrypted blobs that fail to decrypt with the data key
+var ErrDecryption = errors.New("failed to decrypt blob")
+
+// ErrUnencrypted is returned for blobs stored without encryption, unless plaintext reads are
+// allowed while migrating them
+var ErrUnencrypted = errors.New("blob is not encrypted")
+
+// KeyWrapper wraps and unwraps data keys with a key encryption key held elsewhere, e.g. in
+// Key Vault
+type KeyWrapper interface {
+	// WrapKey encrypts key, returning the ID of the key version that wrapped it
+	WrapKey(ctx context.Context, key []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a key wrapped by the given key version
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// wrappedDataKey is the stored form of the data key
+type wrappedDataKey struct {
+	KeyID     string    `json:"key_id"`
+	Algorithm string    `json:"algorithm"`
+	Key       []byte    `json:"wrapped_key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EncryptedStorage encrypts blobs with AES-256-GCM before they reach another store, so their
+// content can't be read by others with access to the storage account. Each blob is sealed
+// with a random nonce and its name as additional data, so it can't be swapped for another.
+type EncryptedStorage struct {
+	store          StorageInterface
+	aead           cipher.AEAD
+	plaintext      []string // Prefixes stored unencrypted
+	plaintextReads bool     // Read blobs stored before encryption was enabled as they are
+}
+
+// Ensure EncryptedStorage implements StorageInterface and ConditionalStorage
+var _ ConditionalStorage = (*EncryptedStorage)(nil)
+
+// NewEncryptedStorage encrypts the blobs of store with the data key in EncryptionKeyBlob,
+// unwrapping it with wrapper, or with a new data key when there is none yet. Blobs under the
+// plaintext prefixes, e.g. exports read by other tools, are stored unencrypted.
+func NewEncryptedStorage(store StorageInterface, wrapper KeyWrapper, plaintext ...string) (*EncryptedStorage, error) {
+	key, err := loadDataKey(store, wrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &EncryptedStorage{store: store, aead: aead, plaintext: plaintext}, nil
+}
+
+// WithPlaintextReads reads blobs stored before encryption was enabled as they are, while
+// EncryptExisting migrates them, instead of rejecting them with ErrUnencrypted
+func (e *EncryptedStorage) WithPlaintextReads() *EncryptedStorage {
+	e.plaintextReads = true
+	return e
+}
+
+// loadDataKey unwraps the stored data key, creating and storing one on first use
+func loadDataKey(store StorageInterface, wrapper KeyWrapper) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyWrapTimeout)
+	defer cancel()
+
+	data, found, err := RetrieveIfExists(store, EncryptionKeyBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve data key: %w", err)
+	}
+	if found {
+		var stored wrappedDataKey
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("failed to parse data key: %w", err)
+		}
+		key, err := wrapper.UnwrapKey(ctx, stored.KeyID, stored.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+		}
+		return key, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	keyID, wrapped, err := wrapper.WrapKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	data, err = json.Marshal(wrappedDataKey{KeyID: keyID, Algorithm: "A256GCM", Key: wrapped, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data key: %w", err)
+	}
+	if err := store.Store(EncryptionKeyBlob, data); err != nil {
+		return nil, fmt.Errorf("failed to store data key: %w", err)
+	}
+	return key, nil
+}
+
+// Store implements StorageInterface, encrypting data unless filename is stored in plaintext
+func (e *EncryptedStorage) Store(filename string, data []byte) error {
+	if e.isPlaintext(filename) {
+		return e.store.Store(filename, data)
+	}
+	sealed, err := e.seal(filename, data)
+	if err != nil {
+		return err
+	}
+	return e.store.Store(filename, sealed)
+}
+
+// Retrieve implements StorageInterface, decrypting encrypted blobs and returning those stored
+// in plaintext as they are
+func (e *EncryptedStorage) Retrieve(filename string) ([]byte, error) {
+	data, err := e.store.Retrieve(filename)
+	if err != nil {
+		return nil, err
+	}
+	return e.open(filename, data)
+}
+
+// RetrieveVersion implements ConditionalStorage when the underlying store does, decrypting
+// like Retrieve
+func (e *EncryptedStorage) RetrieveVersion(filename string) ([]byte, string, error) {
+	conditional, ok := e.store.(ConditionalStorage)
+	if !ok {
+		return nil, "", errors.ErrUnsupported
+	}
+	data, version, err := conditional.RetrieveVersion(filename)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err = e.open(filename, data)
+	return data, version, err
+}
+
+// StoreIfVersion implements ConditionalStorage when the underlying store does, encrypting
+// like Store
+func (e *EncryptedStorage) StoreIfVersion(filename string, data []byte, version string) error {
+	conditional, ok := e.store.(ConditionalStorage)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	if e.isPlaintext(filename) {
+		return conditional.StoreIfVersion(filename, data, version)
+	}
+	sealed, err := e.seal(filename, data)
+	if err != nil {
+		return err
+	}
+	return conditional.StoreIfVersion(filename, sealed, version)
+}
+
+// List implements StorageInterface
+func (e *EncryptedStorage) List(prefix string) ([]string, error) {
+	return e.store.List(prefix)
+}
+
+// Delete implements StorageInterface
+func (e *EncryptedStorage) Delete(filename string) error {
+	return e.store.Delete(filename)
+}
+
+// EncryptExisting encrypts the blobs under prefix that were stored before encryption was
+// enabled, returning how many it rewrote
+func (e *EncryptedStorage) EncryptExisting(prefix string) (int, error) {
+	names, err := e.store.List(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list blobs: %w", err)
+	}
+
+	encrypted := 0
+	for _, name := range names {
+		if e.isPlaintext(name) {
+			continue
+		}
+		data, err := e.store.Retrieve(name)
+		if err != nil {
+			return encrypted, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+		if bytes.HasPrefix(data, []byte(encryptionHeader)) {
+			continue
+		}
+		sealed, err := e.seal(name, data)
+		if err != nil {
+			return encrypted, err
+		}
+		if err := e.store.Store(name, sealed); err != nil {
+			return encrypted, fmt.Errorf("failed to store %s: %w", name, err)
+		}
+		encrypted++
+	}
+	return encrypted, nil
+}
+
+// isPlaintext reports whether filename is stored unencrypted: the wrapped data key and the
+// configured plaintext prefixes
+func (e *EncryptedStorage) isPlaintext(filename string) bool {
+	if filename == EncryptionKeyBlob {
+		return true
+	}
+	for _, prefix := range e.plaintext {
+		if prefix != "" && strings.HasPrefix(filename, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// seal encrypts data as the header, a random nonce and the ciphertext
+func (e *EncryptedStorage) seal(filename string, data []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce for %s: %w", filename, err)
+	}
+	sealed := make([]byte, 0, len(encryptionHeader)+len(nonce)+len(data)+e.aead.Overhead())
+	sealed = append(sealed, encryptionHeader...)
+	sealed = append(sealed, nonce...)
+	return e.aead.Seal(sealed, nonce, data, []byte(filename)), nil
+}
+
+// open decrypts a sealed blob. Data without the header was stored unencrypted, and is only
+// returned while plaintext reads are allowed.
+func (e *EncryptedStorage) open(filename string, data []byte) ([]byte, error) {
+	if e.isPlaintext(filename) {
+		return data, nil
+	}
+	if !bytes.HasPrefix(data, []byte(encryptionHeader)) {
+		if e.plaintextReads {
+			return data, nil
+		}
+		return nil, fmt.Errorf("%w: %s, run encrypt-storage or set STORAGE_ENCRYPTION_MIGRATION", ErrUnencrypted, filename)
+	}
+	data = data[len(encryptionHeader):]
+	if len(data) < e.aead.NonceSize() {
+		return nil, fmt.Errorf("%w %s: truncated", ErrDecryption, filename)
+	}
+	plain, err := e.aead.Open(nil, data[:e.aead.NonceSize()], data[e.aead.NonceSize():], []byte(filename))
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %v", ErrDecryption, filename, err)
+	}
+	return plain, nil
+}
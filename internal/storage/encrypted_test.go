@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// xorWrapper wraps keys by flipping their bits, counting its calls
+type xorWrapper struct {
+	wraps, unwraps int
+}
+
+func (w *xorWrapper) WrapKey(ctx context.Context, key []byte) (string, []byte, error) {
+	w.wraps++
+	return "test-key/1", flip(key), nil
+}
+
+func (w *xorWrapper) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	w.unwraps++
+	return flip(wrapped), nil
+}
+
+func flip(data []byte) []byte {
+	flipped := make([]byte, len(data))
+	for i, b := range data {
+		flipped[i] = ^b
+	}
+	return flipped
+}
+
+func TestEncryptedStorage(t *testing.T) {
+	inner := newMemoryStorage()
+	require.NoError(t, inner.Store("mentions/legacy.json", []byte(`{"id":"legacy"}`)))
+
+	wrapper := &xorWrapper{}
+	store, err := NewEncryptedStorage(inner, wrapper, "exports/")
+	require.NoError(t, err)
+	assert.Equal(t, 1, wrapper.wraps, "a data key is created on first use")
+
+	content := []byte(`{"id":"reddit_1","content":"AKS upgrade broke our ingress","notes":"internal triage"}`)
+	require.NoError(t, store.Store("mentions/reddit_1.json", content))
+	assert.False(t, bytes.Contains(inner.data["mentions/reddit_1.json"], []byte("AKS upgrade")), "content is encrypted at rest")
+	data, err := store.Retrieve("mentions/reddit_1.json")
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	// Blobs stored before encryption was enabled are rejected unless migrating them
+	_, err = store.Retrieve("mentions/legacy.json")
+	assert.ErrorIs(t, err, ErrUnencrypted)
+	data, err = store.WithPlaintextReads().Retrieve("mentions/legacy.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"legacy"}`, string(data))
+
+	// Plaintext prefixes are stored and read as they are
+	require.NoError(t, store.Store("exports/date=2024-06-01/mentions.parquet", []byte("PAR1")))
+	assert.Equal(t, "PAR1", string(inner.data["exports/date=2024-06-01/mentions.parquet"]))
+	reopened, err := NewEncryptedStorage(inner, wrapper, "exports/")
+	require.NoError(t, err)
+	data, err = reopened.Retrieve("exports/date=2024-06-01/mentions.parquet")
+	require.NoError(t, err)
+	assert.Equal(t, "PAR1", string(data))
+
+	// A blob can't be decrypted under another name
+	inner.data["mentions/reddit_2.json"] = inner.data["mentions/reddit_1.json"]
+	_, err = store.Retrieve("mentions/reddit_2.json")
+	assert.ErrorIs(t, err, ErrDecryption)
+	delete(inner.data, "mentions/reddit_2.json")
+
+	encrypted, err := store.EncryptExisting("")
+	require.NoError(t, err)
+	assert.Equal(t, 1, encrypted, "only the legacy blob is rewritten")
+	assert.False(t, bytes.Contains(inner.data["mentions/legacy.json"], []byte("legacy")))
+
+	// A second process unwraps the stored data key and reads the same blobs
+	reopened, err = NewEncryptedStorage(inner, wrapper, "exports/")
+	require.NoError(t, err)
+	assert.Equal(t, 1, wrapper.wraps)
+	assert.Equal(t, 2, wrapper.unwraps)
+	data, err = reopened.Retrieve("mentions/legacy.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"legacy"}`, string(data))
+}
+
+// staticCredential returns a fixed token
+type staticCredential struct{}
+
+func (staticCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestKeyVaultKey_WrapAndUnwrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, keyVaultAPIVersion, r.URL.Query().Get("api-version"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/keys/aks-mentions/wrapkey":
+			w.Write([]byte(`{"kid": "https://vault.example/keys/aks-mentions/v2", "value": "d3JhcHBlZA"}`))
+		case "/keys/aks-mentions/v2/unwrapkey":
+			w.Write([]byte(`{"kid": "https://vault.example/keys/aks-mentions/v2", "value": "a2V5"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	key := &KeyVaultKey{client: resty.New(), keyID: server.URL + "/keys/aks-mentions", tokens: azauth.NewTokenSource(staticCredential{}, keyVaultScope)}
+	keyID, wrapped, err := key.WrapKey(context.Background(), []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://vault.example/keys/aks-mentions/v2", keyID)
+	assert.Equal(t, "wrapped", string(wrapped))
+
+	unwrapped, err := key.UnwrapKey(context.Background(), server.URL+"/keys/aks-mentions/v2", wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "key", string(unwrapped))
+}
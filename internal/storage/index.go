@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+// indexPrefix is the blob prefix under which daily index files are kept
+const indexPrefix = "index/"
+
+// IndexEntry records where a mention is stored along with its key metadata
+type IndexEntry struct {
+	Blob      string    `json:"blob"`
+	Source    string    `json:"source"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	Sentiment string    `json:"sentiment,omitempty"`
+	Relevance float64   `json:"relevance,omitempty"`
+}
+
+// DayIndex maps mention IDs to their index entries for a single day
+type DayIndex map[string]IndexEntry
+
+// MentionIndex maintains one index file per day (index/2006-01-02.json) mapping mention IDs
+// to the blob that holds them, so lookups don't have to download every mentions blob
+type MentionIndex struct {
+	store StorageInterface
+	mu    sync.Mutex
+}
+
+// NewMentionIndex creates an index backed by the given storage
+func NewMentionIndex(store StorageInterface) *MentionIndex {
+	return &MentionIndex{store: store}
+}
+
+// IndexBlobName returns the index file name for the given day (UTC)
+func IndexBlobName(day time.Time) string {
+	return fmt.Sprintf("%s%s.json", indexPrefix, day.UTC().Format("2006-01-02"))
+}
+
+// Add records the mentions stored in blob, partitioned by the day each mention was created
+func (i *MentionIndex) Add(blob string, mentions []models.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	byDay := make(map[string][]models.Mention)
+	for _, mention := range mentions {
+		name := IndexBlobName(mention.CreatedAt)
+		byDay[name] = append(byDay[name], mention)
+	}
+
+	for name, dayMentions := range byDay {
+		index, err := i.load(name)
+		if err != nil {
+			return err
+		}
+
+		for _, mention := range dayMentions {
+			index[mention.ID] = IndexEntry{
+				Blob:      blob,
+				Source:    mention.Source,
+				Title:     mention.Title,
+				URL:       mention.URL,
+				CreatedAt: mention.CreatedAt,
+				Sentiment: mention.Sentiment,
+				Relevance: mention.Relevance,
+			}
+		}
+
+		data, err := json.Marshal(index)
+		if err != nil {
+			return fmt.Errorf("failed to marshal index %s: %w", name, err)
+		}
+
+		if err := i.store.Store(name, data); err != nil {
+			return fmt.Errorf("failed to store index %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Load returns the index for a single day; days without an index yield an empty index
+func (i *MentionIndex) Load(day time.Time) (DayIndex, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.load(IndexBlobName(day))
+}
+
+// Range merges the daily indexes covering the period from..to (inclusive)
+func (i *MentionIndex) Range(from, to time.Time) (DayIndex, error) {
+	merged := make(DayIndex)
+
+	start := from.UTC().Truncate(24 * time.Hour)
+	for day := start; !day.After(to.UTC()); day = day.Add(24 * time.Hour) {
+		index, err := i.Load(day)
+		if err != nil {
+			return nil, err
+		}
+		for id, entry := range index {
+			merged[id] = entry
+		}
+	}
+
+	return merged, nil
+}
+
+// Days lists the days that have an index file, oldest first
+func (i *MentionIndex) Days() ([]time.Time, error) {
+	names, err := i.store.List(indexPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list index files: %w", err)
+	}
+
+	var days []time.Time
+	for _, name := range names {
+		dayStr := strings.TrimSuffix(strings.TrimPrefix(name, indexPrefix), ".json")
+		day, err := time.Parse("2006-01-02", dayStr)
+		if err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(a, b int) bool { return days[a].Before(days[b]) })
+	return days, nil
+}
+
+func (i *MentionIndex) load(name string) (DayIndex, error) {
+	exists, err := i.exists(name)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(DayIndex)
+	if !exists {
+		return index, nil
+	}
+
+	data, err := i.store.Retrieve(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve index %s: %w", name, err)
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", name, err)
+	}
+
+	return index, nil
+}
+
+func (i *MentionIndex) exists(name string) (bool, error) {
+	names, err := i.store.List(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check index %s: %w", name, err)
+	}
+
+	for _, existing := range names {
+		if existing == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStorage is an in-memory StorageInterface used for index tests
+type memoryStorage struct {
+	data map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) Store(filename string, data []byte) error {
+	m.data[filename] = data
+	return nil
+}
+
+func (m *memoryStorage) Retrieve(filename string) ([]byte, error) {
+	if data, ok := m.data[filename]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, filename)
+}
+
+func (m *memoryStorage) List(prefix string) ([]string, error) {
+	var names []string
+	for name := range m.data {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (m *memoryStorage) Delete(filename string) error {
+	delete(m.data, filename)
+	return nil
+}
+
+func TestMentionIndex_AddAndRange(t *testing.T) {
+	store := newMemoryStorage()
+	index := NewMentionIndex(store)
+
+	day1 := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 6, 2, 23, 30, 0, 0, time.UTC)
+
+	require.NoError(t, index.Add("mentions-a.json", []models.Mention{
+		{ID: "reddit_1", Source: "reddit", CreatedAt: day1},
+		{ID: "reddit_2", Source: "reddit", CreatedAt: day2},
+	}))
+	require.NoError(t, index.Add("mentions-b.json", []models.Mention{
+		{ID: "hackernews_1", Source: "hackernews", CreatedAt: day1, Relevance: 0.9},
+	}))
+
+	assert.Contains(t, store.data, "index/2025-06-01.json")
+	assert.Contains(t, store.data, "index/2025-06-02.json")
+
+	first, err := index.Load(day1)
+	require.NoError(t, err)
+	assert.Len(t, first, 2)
+	assert.Equal(t, "mentions-a.json", first["reddit_1"].Blob)
+	assert.Equal(t, "mentions-b.json", first["hackernews_1"].Blob)
+	assert.Equal(t, 0.9, first["hackernews_1"].Relevance)
+
+	all, err := index.Range(day1, day2)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	empty, err := index.Load(day1.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	days, err := index.Days()
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
+	}, days)
+}
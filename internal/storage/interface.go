@@ -1,9 +1,43 @@
 package storage
 
+import "errors"
+
+// ErrNotFound is returned by Retrieve for blobs that don't exist
+var ErrNotFound = errors.New("blob not found")
+
+// ErrConditionNotMet is returned by StoreIfVersion when the blob changed since it was read
+var ErrConditionNotMet = errors.New("blob changed since it was read")
+
 // StorageInterface defines the contract for storage operations
 type StorageInterface interface {
 	Store(filename string, data []byte) error
+	// Retrieve returns an error wrapping ErrNotFound when the blob doesn't exist
 	Retrieve(filename string) ([]byte, error)
 	List(prefix string) ([]string, error)
 	Delete(filename string) error
 }
+
+// ConditionalStorage is implemented by stores that can write a blob only while it is unchanged,
+// so processes sharing a container can claim work without a lock. Stores wrapping another
+// return errors.ErrUnsupported when the wrapped store is not conditional.
+type ConditionalStorage interface {
+	StorageInterface
+	// RetrieveVersion returns a blob with its version, e.g. its ETag, or an error wrapping
+	// ErrNotFound when the blob doesn't exist
+	RetrieveVersion(filename string) ([]byte, string, error)
+	// StoreIfVersion stores data only while the blob is at version, returning an error
+	// wrapping ErrConditionNotMet otherwise
+	StoreIfVersion(filename string, data []byte, version string) error
+}
+
+// RetrieveIfExists retrieves a blob, reporting false without an error when it doesn't exist
+func RetrieveIfExists(store StorageInterface, filename string) ([]byte, bool, error) {
+	data, err := store.Retrieve(filename)
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	keyVaultAPIVersion = "7.4"
+	keyVaultScope      = "https://vault.azure.net/.default"
+	// keyWrapAlgorithm wraps data keys with the RSA key encryption key
+	keyWrapAlgorithm = "RSA-OAEP-256"
+)
+
+// KeyVaultKey wraps data keys with an RSA key in Azure Key Vault, authenticating with the
+// default Azure credential chain. The identity needs the wrapKey and unwrapKey permissions.
+type KeyVaultKey struct {
+	client *resty.Client
+	keyID  string
+	tokens *azauth.TokenSource
+}
+
+// Ensure KeyVaultKey implements KeyWrapper
+var _ KeyWrapper = (*KeyVaultKey)(nil)
+
+type keyOperation struct {
+	Algorithm string `json:"alg"`
+	Value     string `json:"value"`
+}
+
+type keyOperationResult struct {
+	KeyID string `json:"kid"`
+	Value string `json:"value"`
+}
+
+// NewKeyVaultKey wraps keys with the Key Vault key keyID, e.g.
+// https://<vault>.vault.azure.net/keys/<name>, using its current version when none is given
+func NewKeyVaultKey(keyID string) (*KeyVaultKey, error) {
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Key Vault credential: %w", err)
+	}
+	return &KeyVaultKey{
+		client: resty.New().SetTimeout(30 * time.Second),
+		keyID:  strings.TrimRight(keyID, "/"),
+		tokens: azauth.NewTokenSource(credential, keyVaultScope),
+	}, nil
+}
+
+// WrapKey implements KeyWrapper
+func (k *KeyVaultKey) WrapKey(ctx context.Context, key []byte) (string, []byte, error) {
+	result, err := k.do(ctx, k.keyID, "wrapkey", key)
+	if err != nil {
+		return "", nil, err
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(result.Value)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid wrapped key from Key Vault: %w", err)
+	}
+	return result.KeyID, wrapped, nil
+}
+
+// UnwrapKey implements KeyWrapper
+func (k *KeyVaultKey) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if keyID == "" {
+		keyID = k.keyID
+	}
+	result, err := k.do(ctx, keyID, "unwrapkey", wrapped)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.RawURLEncoding.DecodeString(result.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unwrapped key from Key Vault: %w", err)
+	}
+	return key, nil
+}
+
+// do runs a key operation, e.g. wrapkey, on the key keyID
+func (k *KeyVaultKey) do(ctx context.Context, keyID, operation string, value []byte) (*keyOperationResult, error) {
+	token, err := k.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result keyOperationResult
+	resp, err := k.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetQueryParam("api-version", keyVaultAPIVersion).
+		SetBody(keyOperation{Algorithm: keyWrapAlgorithm, Value: base64.RawURLEncoding.EncodeToString(value)}).
+		SetResult(&result).
+		Post(strings.TrimRight(keyID, "/") + "/" + operation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Key Vault %s: %w", operation, err)
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("Key Vault %s returned status %d: %s", operation, resp.StatusCode(), string(resp.Body()))
+	}
+	return &result, nil
+}
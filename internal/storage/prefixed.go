@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+)
+
+// PrefixedStorage keeps blobs under a fixed prefix of another store, so several monitoring
+// profiles can share a container without seeing each other's data
+type PrefixedStorage struct {
+	store  StorageInterface
+	prefix string
+}
+
+// NewPrefixedStorage stores blobs in store under prefix, e.g. "profiles/kaito/"
+func NewPrefixedStorage(store StorageInterface, prefix string) *PrefixedStorage {
+	return &PrefixedStorage{store: store, prefix: prefix}
+}
+
+// Store implements StorageInterface
+func (p *PrefixedStorage) Store(filename string, data []byte) error {
+	return p.store.Store(p.prefix+filename, data)
+}
+
+// Retrieve implements StorageInterface
+func (p *PrefixedStorage) Retrieve(filename string) ([]byte, error) {
+	return p.store.Retrieve(p.prefix + filename)
+}
+
+// List implements StorageInterface, returning names without the prefix
+func (p *PrefixedStorage) List(prefix string) ([]string, error) {
+	names, err := p.store.List(p.prefix + prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		names[i] = strings.TrimPrefix(name, p.prefix)
+	}
+	return names, nil
+}
+
+// Delete implements StorageInterface
+func (p *PrefixedStorage) Delete(filename string) error {
+	return p.store.Delete(p.prefix + filename)
+}
+
+// RetrieveVersion implements ConditionalStorage when the underlying store does
+func (p *PrefixedStorage) RetrieveVersion(filename string) ([]byte, string, error) {
+	conditional, ok := p.store.(ConditionalStorage)
+	if !ok {
+		return nil, "", errors.ErrUnsupported
+	}
+	return conditional.RetrieveVersion(p.prefix + filename)
+}
+
+// StoreIfVersion implements ConditionalStorage when the underlying store does
+func (p *PrefixedStorage) StoreIfVersion(filename string, data []byte, version string) error {
+	conditional, ok := p.store.(ConditionalStorage)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return conditional.StoreIfVersion(p.prefix+filename, data, version)
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+)
+
+const (
+	// SearchIndexBlob is the blob holding the persisted full-text search index
+	SearchIndexBlob = "search/index.json"
+	// mentionsBlobPrefix is the prefix shared by every stored mentions blob
+	mentionsBlobPrefix = "mentions-"
+	// titleWeight boosts terms that appear in a mention's title
+	titleWeight = 2
+)
+
+// SearchDocument is the metadata returned for a mention matching a search
+type SearchDocument struct {
+	ID        string    `json:"id"`
+	Blob      string    `json:"blob"`
+	Source    string    `json:"source"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Sentiment string    `json:"sentiment,omitempty"`
+	Relevance float64   `json:"relevance,omitempty"`
+}
+
+// SearchHit is a search result with its score
+type SearchHit struct {
+	SearchDocument
+	Score float64 `json:"score"`
+}
+
+// SearchIndex is an in-memory inverted index over stored mentions. It is persisted to
+// SearchIndexBlob and can always be rebuilt from the mentions blobs.
+type SearchIndex struct {
+	mu       sync.RWMutex
+	docs     map[string]SearchDocument
+	postings map[string]map[string]int // term -> mention ID -> weighted term frequency
+	version  uint64                    // Incremented by every Add
+	saved    uint64                    // Version last persisted or loaded
+}
+
+// searchSnapshot is the persisted form of a SearchIndex
+type searchSnapshot struct {
+	Docs     map[string]SearchDocument `json:"docs"`
+	Postings map[string]map[string]int `json:"postings"`
+}
+
+// NewSearchIndex creates an empty search index
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		docs:     make(map[string]SearchDocument),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// LoadSearchIndex loads the persisted search index, returning an empty index if none exists
+func LoadSearchIndex(store StorageInterface) (*SearchIndex, error) {
+	index := NewSearchIndex()
+
+	data, found, err := RetrieveIfExists(store, SearchIndexBlob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve search index: %w", err)
+	}
+	if !found {
+		return index, nil
+	}
+
+	var snapshot searchSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse search index: %w", err)
+	}
+	if snapshot.Docs != nil {
+		index.docs = snapshot.Docs
+	}
+	if snapshot.Postings != nil {
+		index.postings = snapshot.Postings
+	}
+
+	return index, nil
+}
+
+// RebuildSearchIndex builds a fresh index from every mentions blob in storage
+func RebuildSearchIndex(store StorageInterface) (*SearchIndex, error) {
+	names, err := store.List(mentionsBlobPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mentions blobs: %w", err)
+	}
+	sort.Strings(names)
+
+	index := NewSearchIndex()
+	for _, name := range names {
+		data, err := store.Retrieve(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s: %w", name, err)
+		}
+
+		var mentions []models.Mention
+		if err := json.Unmarshal(data, &mentions); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		index.Add(name, mentions)
+	}
+
+	// A rebuilt index replaces the stored one, even when there are no mentions
+	index.version++
+	return index, nil
+}
+
+// Save persists the index to SearchIndexBlob. Nothing is written when no mentions were
+// added since the index was loaded or last saved.
+func (i *SearchIndex) Save(store StorageInterface) error {
+	i.mu.RLock()
+	version := i.version
+	if version == i.saved {
+		i.mu.RUnlock()
+		return nil
+	}
+	data, err := json.Marshal(searchSnapshot{Docs: i.docs, Postings: i.postings})
+	i.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+
+	if err := store.Store(SearchIndexBlob, data); err != nil {
+		return fmt.Errorf("failed to store search index: %w", err)
+	}
+
+	i.mu.Lock()
+	if version > i.saved {
+		i.saved = version
+	}
+	i.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of indexed mentions
+func (i *SearchIndex) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Document returns the indexed metadata for a mention ID
+func (i *SearchIndex) Document(id string) (SearchDocument, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	doc, ok := i.docs[id]
+	return doc, ok
+}
+
+// Add indexes the mentions stored in blob, replacing any earlier entries with the same ID
+func (i *SearchIndex) Add(blob string, mentions []models.Mention) {
+	if len(mentions) == 0 {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.version++
+
+	for _, mention := range mentions {
+		if _, exists := i.docs[mention.ID]; exists {
+			i.remove(mention.ID)
+		}
+
+		i.docs[mention.ID] = SearchDocument{
+			ID:        mention.ID,
+			Blob:      blob,
+			Source:    mention.Source,
+			Title:     mention.Title,
+			URL:       mention.URL,
+			Author:    mention.Author,
+			CreatedAt: mention.CreatedAt,
+			Sentiment: mention.Sentiment,
+			Relevance: mention.Relevance,
+		}
+
+		terms := make(map[string]int)
+		for _, term := range tokenize(mention.Title) {
+			terms[term] += titleWeight
+		}
+		for _, term := range tokenize(mention.Content) {
+			terms[term]++
+		}
+
+		for term, freq := range terms {
+			if i.postings[term] == nil {
+				i.postings[term] = make(map[string]int)
+			}
+			i.postings[term][mention.ID] = freq
+		}
+	}
+}
+
+// Search returns mentions containing every term in query, best matches first.
+// Scores are TF-IDF sums; ties are broken by recency.
+func (i *SearchIndex) Search(query string, limit int) []SearchHit {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var scores map[string]float64
+	for _, term := range terms {
+		postings := i.postings[term]
+		if len(postings) == 0 {
+			return nil
+		}
+
+		idf := math.Log(1 + float64(len(i.docs))/float64(len(postings)))
+		next := make(map[string]float64)
+		for id, freq := range postings {
+			if scores != nil {
+				if _, ok := scores[id]; !ok {
+					continue
+				}
+			}
+			next[id] = scores[id] + float64(freq)*idf
+		}
+		scores = next
+	}
+
+	hits := make([]SearchHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, SearchHit{SearchDocument: i.docs[id], Score: math.Round(score*100) / 100})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].CreatedAt.After(hits[b].CreatedAt)
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+func (i *SearchIndex) remove(id string) {
+	delete(i.docs, id)
+	for term, postings := range i.postings {
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(i.postings, term)
+		}
+	}
+}
+
+// tokenize lowercases text and splits it into terms, keeping dotted version numbers
+// such as "1.30" intact
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.Trim(field, "."); field != "" {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
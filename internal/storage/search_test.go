@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIndex_Search(t *testing.T) {
+	index := NewSearchIndex()
+	now := time.Now()
+
+	index.Add("mentions-a.json", []models.Mention{
+		{ID: "reddit_1", Title: "Cilium upgrade broke my AKS cluster", Content: "After the 1.30 upgrade cilium pods crash", CreatedAt: now},
+		{ID: "reddit_2", Title: "AKS networking", Content: "Comparing cilium and kubenet", CreatedAt: now.Add(-time.Hour)},
+		{ID: "reddit_3", Title: "Node pool upgrade", Content: "Upgrade went fine", CreatedAt: now},
+	})
+
+	hits := index.Search("cilium upgrade", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "reddit_1", hits[0].ID)
+	assert.Equal(t, "mentions-a.json", hits[0].Blob)
+
+	hits = index.Search("Cilium", 10)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "reddit_1", hits[0].ID, "title matches rank first")
+
+	assert.Len(t, index.Search("1.30", 10), 1)
+	assert.Empty(t, index.Search("istio", 10))
+	assert.Empty(t, index.Search("  ", 10))
+	assert.Len(t, index.Search("upgrade", 1), 1)
+
+	// Re-adding a mention replaces its earlier terms
+	index.Add("mentions-b.json", []models.Mention{{ID: "reddit_3", Title: "Istio mesh"}})
+	assert.Equal(t, 3, index.Len())
+	assert.Len(t, index.Search("upgrade", 10), 1)
+	assert.Len(t, index.Search("istio", 10), 1)
+}
+
+func TestSearchIndex_RebuildAndPersist(t *testing.T) {
+	store := newMemoryStorage()
+
+	data, err := json.Marshal([]models.Mention{
+		{ID: "hackernews_1", Source: "hackernews", Title: "KEDA on AKS", Content: "Autoscaling workloads"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Store("mentions-2025-06-01-10-00-00-hackernews.json", data))
+
+	empty, err := LoadSearchIndex(store)
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.Len())
+
+	rebuilt, err := RebuildSearchIndex(store)
+	require.NoError(t, err)
+	require.NoError(t, rebuilt.Save(store))
+
+	loaded, err := LoadSearchIndex(store)
+	require.NoError(t, err)
+	hits := loaded.Search("autoscaling", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "hackernews", hits[0].Source)
+
+	// Unchanged indexes are not written again
+	require.NoError(t, store.Delete(SearchIndexBlob))
+	require.NoError(t, loaded.Save(store))
+	loaded.Add("mentions-2025-06-02-10-00-00-reddit.json", nil)
+	require.NoError(t, loaded.Save(store))
+	assert.NotContains(t, store.data, SearchIndexBlob)
+
+	loaded.Add("mentions-2025-06-02-10-00-00-reddit.json", []models.Mention{{ID: "reddit_1", Title: "AKS autoscaling"}})
+	require.NoError(t, loaded.Save(store))
+	assert.Contains(t, store.data, SearchIndexBlob)
+	delete(store.data, SearchIndexBlob)
+	require.NoError(t, loaded.Save(store))
+	assert.NotContains(t, store.data, SearchIndexBlob)
+}
@@ -0,0 +1,50 @@
+// Package teamsbot implements a Microsoft Teams bot on the Bot Framework that answers
+// questions about stored mentions when it is @mentioned.
+package teamsbot
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Activity types handled by the bot
+const (
+	ActivityMessage = "message"
+)
+
+// Activity is the subset of a Bot Framework activity the bot reads and sends
+type Activity struct {
+	Type         string               `json:"type"`
+	ID           string               `json:"id,omitempty"`
+	ServiceURL   string               `json:"serviceUrl,omitempty"`
+	ChannelID    string               `json:"channelId,omitempty"`
+	From         *ChannelAccount      `json:"from,omitempty"`
+	Recipient    *ChannelAccount      `json:"recipient,omitempty"`
+	Conversation *ConversationAccount `json:"conversation,omitempty"`
+	ReplyToID    string               `json:"replyToId,omitempty"`
+	Text         string               `json:"text,omitempty"`
+	TextFormat   string               `json:"textFormat,omitempty"`
+}
+
+// ChannelAccount identifies a user or bot
+type ChannelAccount struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ConversationAccount identifies a conversation, such as a channel thread or a chat
+type ConversationAccount struct {
+	ID               string `json:"id"`
+	ConversationType string `json:"conversationType,omitempty"`
+	TenantID         string `json:"tenantId,omitempty"`
+}
+
+// atMentionPattern matches the <at>Name</at> markup Teams adds for @mentions
+var atMentionPattern = regexp.MustCompile(`(?i)<at>[^<]*</at>`)
+
+// MessageText returns the text of a message with @mentions of the bot and surrounding
+// whitespace removed
+func (a *Activity) MessageText() string {
+	text := atMentionPattern.ReplaceAllString(a.Text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,211 @@
+package teamsbot
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// openIDMetadataURL describes the keys the Bot Connector signs its requests with
+	openIDMetadataURL = "https://login.botframework.com/v1/.well-known/openidconfiguration"
+	// tokenIssuer is the issuer of Bot Connector tokens
+	tokenIssuer = "https://api.botframework.com"
+
+	// signingKeyTTL is how long signing keys are cached; Microsoft rolls them over every few weeks
+	signingKeyTTL = 24 * time.Hour
+	// signingKeyRefreshInterval limits refetching when a token names an unknown key
+	signingKeyRefreshInterval = time.Minute
+	// clockSkew is the tolerance on token lifetimes
+	clockSkew = 5 * time.Minute
+)
+
+// ErrUnauthorized is returned for requests without a valid Bot Connector token
+var ErrUnauthorized = errors.New("unauthorized bot request")
+
+// Authenticator verifies that requests come from the Bot Connector service on behalf of
+// the bot, as described in the Bot Framework authentication protocol
+type Authenticator struct {
+	appID       string
+	metadataURL string
+	client      *resty.Client
+	now         func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]signingKey
+	fetched time.Time
+}
+
+// signingKey is a Bot Connector signing key and the channels it may sign for
+type signingKey struct {
+	key          *rsa.PublicKey
+	endorsements []string
+}
+
+type openIDMetadata struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty          string   `json:"kty"`
+	Kid          string   `json:"kid"`
+	N            string   `json:"n"`
+	E            string   `json:"e"`
+	Endorsements []string `json:"endorsements"`
+}
+
+// NewAuthenticator creates an authenticator for the bot's Microsoft App ID
+func NewAuthenticator(appID string) *Authenticator {
+	return &Authenticator{
+		appID:       appID,
+		metadataURL: openIDMetadataURL,
+		client:      resty.New().SetTimeout(30 * time.Second),
+		now:         time.Now,
+	}
+}
+
+// Authenticate checks the request's Authorization header against the activity it carries:
+// the token must be signed by the Bot Connector with a key endorsed for the activity's
+// channel, be issued for the bot and name the activity's service URL
+func (a *Authenticator) Authenticate(ctx context.Context, authorization string, activity *Activity) error {
+	raw, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || raw == "" {
+		return fmt.Errorf("%w: missing bearer token", ErrUnauthorized)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := a.signingKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if len(key.endorsements) > 0 && !containsString(key.endorsements, activity.ChannelID) {
+			return nil, fmt.Errorf("signing key is not endorsed for channel %q", activity.ChannelID)
+		}
+		return key.key, nil
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(a.appID),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(a.now),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	if expires, err := claims.GetExpirationTime(); err != nil || expires == nil {
+		return fmt.Errorf("%w: token has no expiry", ErrUnauthorized)
+	}
+	if serviceURL, _ := claims["serviceurl"].(string); serviceURL != activity.ServiceURL {
+		return fmt.Errorf("%w: token was issued for another service URL", ErrUnauthorized)
+	}
+	return nil
+}
+
+// signingKey returns the cached signing key, refreshing the keys when they are stale or
+// the key is unknown
+func (a *Authenticator) signingKey(ctx context.Context, kid string) (signingKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	key, ok := a.keys[kid]
+	stale := now.Sub(a.fetched) > signingKeyTTL
+	if ok && !stale {
+		return key, nil
+	}
+	if !ok && !stale && now.Sub(a.fetched) < signingKeyRefreshInterval {
+		return signingKey{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := a.fetchSigningKeys(ctx)
+	if err != nil {
+		return signingKey{}, err
+	}
+	a.keys = keys
+	a.fetched = now
+
+	if key, ok = keys[kid]; !ok {
+		return signingKey{}, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetchSigningKeys reads the Bot Connector's current RSA signing keys
+func (a *Authenticator) fetchSigningKeys(ctx context.Context) (map[string]signingKey, error) {
+	var metadata openIDMetadata
+	resp, err := a.client.R().SetContext(ctx).SetResult(&metadata).Get(a.metadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Bot Framework OpenID metadata: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("unexpected status %d from Bot Framework OpenID metadata", resp.StatusCode())
+	}
+	if metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("no jwks_uri in Bot Framework OpenID metadata")
+	}
+
+	var set jsonWebKeySet
+	resp, err = a.client.R().SetContext(ctx).SetResult(&set).Get(metadata.JWKSURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Bot Framework signing keys: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("unexpected status %d from Bot Framework signing keys", resp.StatusCode())
+	}
+
+	keys := make(map[string]signingKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Bot Framework signing key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = signingKey{key: key, endorsements: jwk.Endorsements}
+	}
+	return keys, nil
+}
+
+// rsaPublicKey decodes the modulus and exponent of an RSA JSON Web Key
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
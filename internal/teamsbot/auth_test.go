@@ -0,0 +1,123 @@
+package teamsbot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testServiceURL = "https://smba.trafficmanager.net/amer/"
+
+// newTestAuthenticator serves a key set with one key endorsed for Teams and returns an
+// authenticator reading it
+func newTestAuthenticator(t *testing.T, key *rsa.PrivateKey) *Authenticator {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/metadata":
+			json.NewEncoder(w).Encode(openIDMetadata{JWKSURI: server.URL + "/keys"})
+		case "/keys":
+			json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+				Kty:          "RSA",
+				Kid:          "key-1",
+				N:            base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:            base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				Endorsements: []string{"msteams"},
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &Authenticator{appID: "bot-app-id", metadataURL: server.URL + "/metadata", client: resty.New(), now: time.Now}
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return "Bearer " + signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":        tokenIssuer,
+		"aud":        "bot-app-id",
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"nbf":        time.Now().Add(-time.Minute).Unix(),
+		"serviceurl": testServiceURL,
+	}
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	auth := newTestAuthenticator(t, key)
+	activity := &Activity{Type: ActivityMessage, ChannelID: "msteams", ServiceURL: testServiceURL}
+	ctx := context.Background()
+
+	assert.NoError(t, auth.Authenticate(ctx, signToken(t, key, "key-1", validClaims()), activity))
+
+	tests := []struct {
+		name          string
+		authorization func() string
+		activity      *Activity
+	}{
+		{"missing token", func() string { return "" }, activity},
+		{"other audience", func() string {
+			claims := validClaims()
+			claims["aud"] = "another-bot"
+			return signToken(t, key, "key-1", claims)
+		}, activity},
+		{"other issuer", func() string {
+			claims := validClaims()
+			claims["iss"] = "https://sts.windows.net/tenant/"
+			return signToken(t, key, "key-1", claims)
+		}, activity},
+		{"expired", func() string {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return signToken(t, key, "key-1", claims)
+		}, activity},
+		{"no expiry", func() string {
+			claims := validClaims()
+			delete(claims, "exp")
+			return signToken(t, key, "key-1", claims)
+		}, activity},
+		{"unknown key", func() string { return signToken(t, key, "key-2", validClaims()) }, activity},
+		{"other service URL", func() string { return signToken(t, key, "key-1", validClaims()) },
+			&Activity{Type: ActivityMessage, ChannelID: "msteams", ServiceURL: "https://attacker.example.com/"}},
+		{"channel not endorsed", func() string { return signToken(t, key, "key-1", validClaims()) },
+			&Activity{Type: ActivityMessage, ChannelID: "webchat", ServiceURL: testServiceURL}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := auth.Authenticate(ctx, tt.authorization(), tt.activity)
+			assert.ErrorIs(t, err, ErrUnauthorized)
+		})
+	}
+
+	forged, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	err = auth.Authenticate(ctx, signToken(t, forged, "key-1", validClaims()), activity)
+	assert.ErrorIs(t, err, ErrUnauthorized, "signed with another key")
+}
@@ -0,0 +1,81 @@
+package teamsbot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	// connectorScope is the scope of tokens for calling the Bot Connector
+	connectorScope = "https://api.botframework.com/.default"
+	// DefaultTenant issues tokens for multi-tenant bot registrations
+	DefaultTenant = "botframework.com"
+)
+
+// Client sends the bot's replies through the Bot Connector
+type Client struct {
+	client *resty.Client
+	tokens *azauth.TokenSource
+}
+
+// NewClient creates a client authenticating as the bot's app registration. tenantID is the
+// app's tenant for single-tenant bots, or DefaultTenant for multi-tenant ones.
+func NewClient(appID, appPassword, tenantID string) (*Client, error) {
+	if tenantID == "" {
+		tenantID = DefaultTenant
+	}
+	credential, err := azidentity.NewClientSecretCredential(tenantID, appID, appPassword, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot credential: %w", err)
+	}
+
+	return &Client{
+		client: resty.New().SetTimeout(30 * time.Second),
+		tokens: azauth.NewTokenSource(credential, connectorScope),
+	}, nil
+}
+
+// Reply posts a Markdown message in reply to an activity, in the same conversation
+func (c *Client) Reply(ctx context.Context, to *Activity, text string) error {
+	if to.Conversation == nil || to.ServiceURL == "" {
+		return fmt.Errorf("activity has no conversation to reply to")
+	}
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	reply := Activity{
+		Type:         ActivityMessage,
+		From:         to.Recipient,
+		Recipient:    to.From,
+		Conversation: to.Conversation,
+		ReplyToID:    to.ID,
+		Text:         text,
+		TextFormat:   "markdown",
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/conversations/%s/activities/%s", strings.TrimRight(to.ServiceURL, "/"),
+		url.PathEscape(to.Conversation.ID), url.PathEscape(to.ID))
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetHeader("Content-Type", "application/json").
+		SetBody(reply).
+		Post(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to send bot reply: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("bot connector returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+	}
+	return nil
+}
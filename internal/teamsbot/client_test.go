@@ -0,0 +1,54 @@
+package teamsbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/aks-mentions-bot/internal/azauth"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticCredential struct{}
+
+func (staticCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "connector-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestClient_Reply(t *testing.T) {
+	var path, authorization string
+	var reply Activity
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		authorization = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reply))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &Client{client: resty.New(), tokens: azauth.NewTokenSource(staticCredential{}, connectorScope)}
+	err := client.Reply(context.Background(), &Activity{
+		Type:         ActivityMessage,
+		ID:           "1712345678",
+		ServiceURL:   server.URL + "/",
+		From:         &ChannelAccount{ID: "29:user", Name: "Jane"},
+		Recipient:    &ChannelAccount{ID: "28:bot", Name: "AKS Mentions"},
+		Conversation: &ConversationAccount{ID: "19:channel@thread.tacv2;messageid=1712345678"},
+	}, "**2** mentions")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v3/conversations/19:channel@thread.tacv2%3Bmessageid=1712345678/activities/1712345678", path)
+	assert.Equal(t, "Bearer connector-token", authorization)
+	assert.Equal(t, "28:bot", reply.From.ID)
+	assert.Equal(t, "29:user", reply.Recipient.ID)
+	assert.Equal(t, "1712345678", reply.ReplyToID)
+	assert.Equal(t, "markdown", reply.TextFormat)
+	assert.Equal(t, "**2** mentions", reply.Text)
+}
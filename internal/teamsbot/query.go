@@ -0,0 +1,192 @@
+package teamsbot
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Bounds on the mentions listed in a reply
+const (
+	DefaultQueryLimit = 10
+	MaxQueryLimit     = 25
+)
+
+// Periods searched by a query
+const (
+	DefaultWindow = 7 * 24 * time.Hour // Searched when a question names no period, topic or tag
+	MaxWindow     = 90 * 24 * time.Hour
+)
+
+// Usage describes the questions the bot understands
+const Usage = "Ask me about stored mentions, for example:\n\n" +
+	"- *show me this week's top negative mentions about upgrades*\n" +
+	"- *reddit mentions about cilium in the last 3 days*\n" +
+	"- *positive mentions today*\n" +
+	"- *mentions tagged pricing*\n\n" +
+	"I understand periods (*today*, *this week*, *this month*, *last 48 hours*), sentiments, " +
+	"source names, *top* for the most relevant mentions and *tagged <tag>*. Other words are searched for."
+
+// Query is a question about stored mentions
+type Query struct {
+	Help      bool
+	Window    time.Duration
+	Sentiment string
+	Source    string
+	Tag       string
+	Text      string // Words searched for in mention titles and content
+	Top       bool   // Rank by relevance instead of recency
+	Limit     int
+}
+
+// fillerWords carry no meaning for a mention query
+var fillerWords = map[string]bool{
+	"a": true, "about": true, "all": true, "an": true, "and": true, "any": true, "are": true,
+	"around": true, "been": true, "can": true, "did": true, "do": true, "find": true, "for": true,
+	"from": true, "get": true, "give": true, "have": true, "in": true, "is": true, "latest": true,
+	"list": true, "me": true, "mention": true, "mentions": true, "most": true, "newest": true,
+	"of": true, "on": true, "please": true, "post": true, "posts": true, "recent": true,
+	"regarding": true, "show": true, "tell": true, "that": true, "the": true, "there": true,
+	"to": true, "we": true, "were": true, "what": true, "whats": true, "with": true, "you": true,
+}
+
+// periodWords name a period relative to now, as in "this week" or "past month"
+var periodWords = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+// ParseQuery interprets a question such as "show me this week's top negative mentions about
+// upgrades". Words naming a sentiment or one of the known sources filter the results, periods
+// such as "today", "this week" or "last 3 days" set how far back to look, "top" ranks by
+// relevance, "tagged <tag>" selects a curated tag and the remaining words are searched for.
+func ParseQuery(text string, sources []string) *Query {
+	words := queryWords(text)
+	if len(words) == 0 || (len(words) == 1 && words[0] == "help") {
+		return &Query{Help: true}
+	}
+
+	query := &Query{Limit: DefaultQueryLimit}
+	var topic []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+
+		switch {
+		case word == "today":
+			query.Window = 24 * time.Hour
+		case compactWindow(word) > 0:
+			query.Window = compactWindow(word)
+		case (word == "this" || word == "past" || word == "last") && periodWords[next] > 0:
+			query.Window = periodWords[next]
+			i++
+		case (word == "past" || word == "last") && isCount(next) && i+2 < len(words) && unitDuration(words[i+2]) > 0:
+			count, _ := strconv.Atoi(next)
+			query.Window = time.Duration(count) * unitDuration(words[i+2])
+			i += 2
+		case word == "top" || word == "best":
+			query.Top = true
+			if isCount(next) {
+				query.Limit, _ = strconv.Atoi(next)
+				i++
+			}
+		case word == "positive" || word == "negative" || word == "neutral":
+			query.Sentiment = word
+		case word == "tagged" && next != "":
+			query.Tag = next
+			i++
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			query.Tag = word[1:]
+		case containsString(sources, word):
+			query.Source = word
+		case fillerWords[word] || word == "this" || word == "past" || word == "last":
+			// Dropped
+		default:
+			topic = append(topic, word)
+		}
+	}
+	query.Text = strings.Join(topic, " ")
+
+	if query.Window > MaxWindow {
+		query.Window = MaxWindow
+	}
+	if query.Limit > MaxQueryLimit {
+		query.Limit = MaxQueryLimit
+	}
+	if query.Window == 0 && query.Tag == "" && query.Text == "" {
+		query.Window = DefaultWindow
+	}
+	return query
+}
+
+// Singular returns the query text with plural words made singular, for retrying a search
+// that found nothing: the search index matches whole words, so "upgrades" misses "upgrade"
+func Singular(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		switch {
+		case len(word) > 4 && strings.HasSuffix(word, "ies"):
+			words[i] = strings.TrimSuffix(word, "ies") + "y"
+		case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+			words[i] = strings.TrimSuffix(word, "s")
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// queryWords lowercases text and splits it into words, dropping punctuation and possessives
+func queryWords(text string) []string {
+	text = strings.NewReplacer("'s", "", "’s", "").Replace(strings.ToLower(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#' && r != '-' && r != '.'
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.Trim(field, ".-"); field != "" {
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+// compactWindow reads periods written as "48h", "7d" or "2w"
+func compactWindow(word string) time.Duration {
+	if len(word) < 2 || !isCount(word[:len(word)-1]) {
+		return 0
+	}
+	count, _ := strconv.Atoi(word[:len(word)-1])
+	switch word[len(word)-1] {
+	case 'h':
+		return time.Duration(count) * time.Hour
+	case 'd':
+		return time.Duration(count) * 24 * time.Hour
+	case 'w':
+		return time.Duration(count) * 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+func isCount(word string) bool {
+	count, err := strconv.Atoi(word)
+	return err == nil && count > 0 && count <= 365
+}
+
+func unitDuration(word string) time.Duration {
+	switch strings.TrimSuffix(word, "s") {
+	case "hour":
+		return time.Hour
+	case "day":
+		return 24 * time.Hour
+	case "week":
+		return 7 * 24 * time.Hour
+	case "month":
+		return 30 * 24 * time.Hour
+	}
+	return 0
+}
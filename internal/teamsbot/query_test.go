@@ -0,0 +1,57 @@
+package teamsbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	sources := []string{"reddit", "twitter", "youtube"}
+
+	tests := []struct {
+		name string
+		text string
+		want Query
+	}{
+		{"empty shows help", "", Query{Help: true}},
+		{"help", "Help?", Query{Help: true}},
+		{
+			"this week's top negative about upgrades",
+			"show me this week's top negative mentions about upgrades",
+			Query{Window: 7 * 24 * time.Hour, Sentiment: "negative", Text: "upgrades", Top: true, Limit: DefaultQueryLimit},
+		},
+		{
+			"source and counted period",
+			"Reddit mentions about Cilium in the last 3 days",
+			Query{Window: 3 * 24 * time.Hour, Source: "reddit", Text: "cilium", Limit: DefaultQueryLimit},
+		},
+		{"today", "positive mentions today", Query{Window: 24 * time.Hour, Sentiment: "positive", Limit: DefaultQueryLimit}},
+		{"compact period", "twitter 48h", Query{Window: 48 * time.Hour, Source: "twitter", Limit: DefaultQueryLimit}},
+		{"top count", "top 5 mentions this month", Query{Window: 30 * 24 * time.Hour, Top: true, Limit: 5}},
+		{"top count is capped", "top 100 mentions", Query{Window: DefaultWindow, Top: true, Limit: MaxQueryLimit}},
+		{"tagged", "mentions tagged pricing", Query{Tag: "pricing", Limit: DefaultQueryLimit}},
+		{"hash tag", "#gb200 this week", Query{Window: 7 * 24 * time.Hour, Tag: "gb200", Limit: DefaultQueryLimit}},
+		{"period is capped", "last 12 months", Query{Window: MaxWindow, Limit: DefaultQueryLimit}},
+		{"no filters searches the last week", "what's new?", Query{Text: "new", Limit: DefaultQueryLimit}},
+		{"only filler", "show me the mentions", Query{Window: DefaultWindow, Limit: DefaultQueryLimit}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, *ParseQuery(tt.text, sources))
+		})
+	}
+}
+
+func TestSingular(t *testing.T) {
+	assert.Equal(t, "upgrade", Singular("upgrades"))
+	assert.Equal(t, "node pool policy", Singular("node pools policies"))
+	assert.Equal(t, "aks status class", Singular("aks status class"))
+}
+
+func TestActivity_MessageText(t *testing.T) {
+	activity := &Activity{Text: "<at>AKS Mentions</at> show me  negative mentions\n"}
+	assert.Equal(t, "show me negative mentions", activity.MessageText())
+}
@@ -0,0 +1,97 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+)
+
+// azuriteAccountKey is the key of Azurite's well-known development account
+const azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+// AzuriteConnectionString returns the connection string for Azurite's development account
+// with the blob service listening on addr, e.g. "127.0.0.1:10000"
+func AzuriteConnectionString(addr string) string {
+	return fmt.Sprintf("DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=%s;BlobEndpoint=http://%s/devstoreaccount1;", azuriteAccountKey, addr)
+}
+
+// NewAzuriteStorage returns an AzureStorage on a fresh container in Azurite, authenticated
+// with a connection string. It uses AZURITE_CONNECTION_STRING when set, e.g. for an Azurite
+// service container in CI, and otherwise starts azurite-blob from PATH for the test. The
+// test is skipped in short mode or when neither is available.
+func NewAzuriteStorage(t testing.TB) *storage.AzureStorage {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Azurite integration test skipped in short mode")
+	}
+
+	connectionString := os.Getenv("AZURITE_CONNECTION_STRING")
+	if connectionString == "" {
+		connectionString = startAzurite(t)
+	}
+
+	store, err := storage.NewAzureStorageWithAuth("", randomContainerName(t), storage.StorageAuth{ConnectionString: connectionString})
+	if err != nil {
+		t.Fatalf("failed to connect to Azurite: %v", err)
+	}
+	return store
+}
+
+// startAzurite runs an in-memory Azurite blob service for the duration of the test
+func startAzurite(t testing.TB) string {
+	t.Helper()
+	path, err := exec.LookPath("azurite-blob")
+	if err != nil {
+		t.Skip("Azurite not available: set AZURITE_CONNECTION_STRING or install azurite (npm install -g azurite)")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port for Azurite: %v", err)
+	}
+	addr := listener.Addr().String()
+	port := fmt.Sprint(listener.Addr().(*net.TCPAddr).Port)
+	listener.Close()
+
+	cmd := exec.Command(path, "--silent", "--inMemoryPersistence", "--skipApiVersionCheck",
+		"--blobHost", "127.0.0.1", "--blobPort", port)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start Azurite: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Azurite did not start listening on %s: %v", addr, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return AzuriteConnectionString(addr)
+}
+
+// randomContainerName isolates each test in its own container
+func randomContainerName(t testing.TB) string {
+	t.Helper()
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatalf("failed to generate container name: %v", err)
+	}
+	return "test-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,86 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// StorageContract checks the behavior the bot relies on from a StorageInterface, so test
+// doubles and AzureStorage are held to the same contract, including conditional writes for
+// stores that implement ConditionalStorage. The store must start empty.
+func StorageContract(t *testing.T, store storage.StorageInterface) {
+	t.Run("store and retrieve", func(t *testing.T) {
+		require.NoError(t, store.Store("contract/a.json", []byte(`{"a":1}`)))
+		data, err := store.Retrieve("contract/a.json")
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		require.NoError(t, store.Store("contract/a.json", []byte(`{"a":2}`)))
+		data, err := store.Retrieve("contract/a.json")
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":2}`, string(data))
+	})
+
+	t.Run("retrieve missing", func(t *testing.T) {
+		_, err := store.Retrieve("contract/missing.json")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, found, err := storage.RetrieveIfExists(store, "contract/missing.json")
+		require.NoError(t, err)
+		assert.False(t, found)
+		data, found, err := storage.RetrieveIfExists(store, "contract/a.json")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, `{"a":2}`, string(data))
+	})
+
+	t.Run("list by prefix", func(t *testing.T) {
+		require.NoError(t, store.Store("contract/b.json", []byte(`{}`)))
+		require.NoError(t, store.Store("other/c.json", []byte(`{}`)))
+
+		names, err := store.List("contract/")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"contract/a.json", "contract/b.json"}, names)
+
+		names, err = store.List("")
+		require.NoError(t, err)
+		assert.Len(t, names, 3)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Delete("contract/a.json"))
+		_, err := store.Retrieve("contract/a.json")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		names, err := store.List("contract/")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"contract/b.json"}, names)
+	})
+
+	conditional, ok := store.(storage.ConditionalStorage)
+	if !ok {
+		return
+	}
+	t.Run("conditional store", func(t *testing.T) {
+		require.NoError(t, store.Store("contract/claim.json", []byte(`{"v":1}`)))
+		data, version, err := conditional.RetrieveVersion("contract/claim.json")
+		require.NoError(t, err)
+		assert.Equal(t, `{"v":1}`, string(data))
+
+		require.NoError(t, conditional.StoreIfVersion("contract/claim.json", []byte(`{"v":2}`), version))
+		err = conditional.StoreIfVersion("contract/claim.json", []byte(`{"v":3}`), version)
+		assert.ErrorIs(t, err, storage.ErrConditionNotMet, "the blob changed since version was read")
+
+		data, err = store.Retrieve("contract/claim.json")
+		require.NoError(t, err)
+		assert.Equal(t, `{"v":2}`, string(data))
+
+		_, _, err = conditional.RetrieveVersion("contract/missing.json")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
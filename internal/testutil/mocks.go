@@ -0,0 +1,92 @@
+package testutil
+
+import (
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/models"
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStorage is a testify mock of the storage interface
+type MockStorage struct {
+	mock.Mock
+}
+
+var _ storage.StorageInterface = (*MockStorage)(nil)
+
+func (m *MockStorage) Store(filename string, data []byte) error {
+	args := m.Called(filename, data)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Retrieve(filename string) ([]byte, error) {
+	args := m.Called(filename)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorage) List(prefix string) ([]string, error) {
+	args := m.Called(prefix)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockStorage) Delete(filename string) error {
+	args := m.Called(filename)
+	return args.Error(0)
+}
+
+// MockNotificationService is a testify mock of the notification service
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) SendReport(report *models.Report) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) SendAlert(alert *models.Alert) error {
+	args := m.Called(alert)
+	return args.Error(0)
+}
+
+// RecordingNotificationService records the reports and alerts sent, for asserting on what
+// would have been delivered
+type RecordingNotificationService struct {
+	mu      sync.Mutex
+	reports []models.Report
+	alerts  []models.Alert
+}
+
+// NewRecordingNotificationService creates a notification service that only records
+func NewRecordingNotificationService() *RecordingNotificationService {
+	return &RecordingNotificationService{}
+}
+
+func (r *RecordingNotificationService) SendReport(report *models.Report) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, *report)
+	return nil
+}
+
+func (r *RecordingNotificationService) SendAlert(alert *models.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, *alert)
+	return nil
+}
+
+// Reports returns the reports sent so far
+func (r *RecordingNotificationService) Reports() []models.Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.Report(nil), r.reports...)
+}
+
+// Alerts returns the alerts sent so far
+func (r *RecordingNotificationService) Alerts() []models.Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.Alert(nil), r.alerts...)
+}
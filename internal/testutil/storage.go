@@ -0,0 +1,97 @@
+// Package testutil provides test doubles for storage and notifications, and a harness that
+// runs AzureStorage against Azurite, for tests across the bot's packages
+package testutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+)
+
+// MemoryStorage is an in-memory ConditionalStorage. It is safe for concurrent use, so it can
+// back pipelines that store from several workers.
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	data     map[string][]byte
+	versions map[string]int // Bumped on every write, for conditional writes
+}
+
+var _ storage.ConditionalStorage = (*MemoryStorage)(nil)
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{data: make(map[string][]byte), versions: make(map[string]int)}
+}
+
+func (m *MemoryStorage) Store(filename string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[filename] = data
+	m.versions[filename]++
+	return nil
+}
+
+func (m *MemoryStorage) RetrieveVersion(filename string) ([]byte, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if data, ok := m.data[filename]; ok {
+		return data, strconv.Itoa(m.versions[filename]), nil
+	}
+	return nil, "", fmt.Errorf("%w: %s", storage.ErrNotFound, filename)
+}
+
+func (m *MemoryStorage) StoreIfVersion(filename string, data []byte, version string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.data[filename]; !ok || strconv.Itoa(m.versions[filename]) != version {
+		return fmt.Errorf("%w: %s", storage.ErrConditionNotMet, filename)
+	}
+	m.data[filename] = data
+	m.versions[filename]++
+	return nil
+}
+
+func (m *MemoryStorage) Retrieve(filename string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if data, ok := m.data[filename]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("%w: %s", storage.ErrNotFound, filename)
+}
+
+// List returns the stored names with the prefix, sorted like a blob listing
+func (m *MemoryStorage) List(prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var names []string
+	for name := range m.data {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *MemoryStorage) Delete(filename string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, filename)
+	return nil
+}
+
+// Blobs returns a copy of everything stored, keyed by name
+func (m *MemoryStorage) Blobs() map[string][]byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blobs := make(map[string][]byte, len(m.data))
+	for name, data := range m.data {
+		blobs[name] = data
+	}
+	return blobs
+}
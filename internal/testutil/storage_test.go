@@ -0,0 +1,30 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/azure/aks-mentions-bot/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	StorageContract(t, NewMemoryStorage())
+}
+
+func TestPrefixedStorage(t *testing.T) {
+	StorageContract(t, storage.NewPrefixedStorage(NewMemoryStorage(), "profiles/kaito/"))
+
+	shared := NewMemoryStorage()
+	kaito := storage.NewPrefixedStorage(shared, "profiles/kaito/")
+	require.NoError(t, shared.Store("alerts/urgent.json", []byte(`{}`)))
+	require.NoError(t, kaito.Store("alerts/urgent.json", []byte(`{"kaito_1":"2024-06-03T09:00:00Z"}`)))
+
+	data, err := shared.Retrieve("alerts/urgent.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(data), "profiles don't see each other's blobs")
+
+	names, err := shared.List("profiles/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"profiles/kaito/alerts/urgent.json"}, names)
+}
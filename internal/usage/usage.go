@@ -0,0 +1,126 @@
+// Package usage meters calls to paid external APIs during a run and estimates what they cost.
+package usage
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// Usage counts the billable calls made to paid APIs
+type Usage struct {
+	TwitterRequests        int `json:"twitter_requests"`
+	TwitterPosts           int `json:"twitter_posts"` // posts read, which X API tiers cap per month
+	OpenAIRequests         int `json:"openai_requests"`
+	OpenAIPromptTokens     int `json:"openai_prompt_tokens"`
+	OpenAICompletionTokens int `json:"openai_completion_tokens"`
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		TwitterRequests:        u.TwitterRequests + other.TwitterRequests,
+		TwitterPosts:           u.TwitterPosts + other.TwitterPosts,
+		OpenAIRequests:         u.OpenAIRequests + other.OpenAIRequests,
+		OpenAIPromptTokens:     u.OpenAIPromptTokens + other.OpenAIPromptTokens,
+		OpenAICompletionTokens: u.OpenAICompletionTokens + other.OpenAICompletionTokens,
+	}
+}
+
+// Prices are the unit prices, in US dollars, used to estimate costs
+type Prices struct {
+	TwitterPerRequest           float64
+	TwitterPerPost              float64
+	OpenAIPromptPer1KTokens     float64
+	OpenAICompletionPer1KTokens float64
+}
+
+// Cost is an estimated cost in US dollars
+type Cost struct {
+	Twitter float64 `json:"twitter"`
+	OpenAI  float64 `json:"openai"`
+	Total   float64 `json:"total"`
+}
+
+// Estimate prices a usage. Amounts are rounded to a hundredth of a cent.
+func (p Prices) Estimate(u Usage) Cost {
+	twitter := float64(u.TwitterRequests)*p.TwitterPerRequest + float64(u.TwitterPosts)*p.TwitterPerPost
+	openAI := float64(u.OpenAIPromptTokens)/1000*p.OpenAIPromptPer1KTokens +
+		float64(u.OpenAICompletionTokens)/1000*p.OpenAICompletionPer1KTokens
+	return Cost{
+		Twitter: roundDollars(twitter),
+		OpenAI:  roundDollars(openAI),
+		Total:   roundDollars(twitter + openAI),
+	}
+}
+
+// Add returns the sum of two costs
+func (c Cost) Add(other Cost) Cost {
+	return Cost{
+		Twitter: roundDollars(c.Twitter + other.Twitter),
+		OpenAI:  roundDollars(c.OpenAI + other.OpenAI),
+		Total:   roundDollars(c.Total + other.Total),
+	}
+}
+
+func roundDollars(amount float64) float64 {
+	return math.Round(amount*10000) / 10000
+}
+
+// Meter accumulates the usage of one run. It is safe for concurrent use, and its methods
+// do nothing on a nil Meter so callers can record without checking for one.
+type Meter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewMeter creates an empty meter
+func NewMeter() *Meter {
+	return &Meter{}
+}
+
+// AddTwitter records X API requests and the posts they returned
+func (m *Meter) AddTwitter(requests, posts int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.TwitterRequests += requests
+	m.usage.TwitterPosts += posts
+}
+
+// AddOpenAI records an Azure OpenAI request and the tokens it used
+func (m *Meter) AddOpenAI(promptTokens, completionTokens int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.OpenAIRequests++
+	m.usage.OpenAIPromptTokens += promptTokens
+	m.usage.OpenAICompletionTokens += completionTokens
+}
+
+// Usage returns the usage recorded so far
+func (m *Meter) Usage() Usage {
+	if m == nil {
+		return Usage{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+type meterKey struct{}
+
+// WithMeter returns a context whose API calls are recorded on the meter
+func WithMeter(ctx context.Context, meter *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, meter)
+}
+
+// FromContext returns the meter of the run ctx belongs to, or nil when it is not metered
+func FromContext(ctx context.Context) *Meter {
+	meter, _ := ctx.Value(meterKey{}).(*Meter)
+	return meter
+}
@@ -0,0 +1,65 @@
+package usage
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter_RecordsFromContext(t *testing.T) {
+	meter := NewMeter()
+	ctx := WithMeter(context.Background(), meter)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			FromContext(ctx).AddTwitter(1, 100)
+			FromContext(ctx).AddOpenAI(200, 1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, Usage{
+		TwitterRequests:        10,
+		TwitterPosts:           1000,
+		OpenAIRequests:         10,
+		OpenAIPromptTokens:     2000,
+		OpenAICompletionTokens: 10,
+	}, meter.Usage())
+}
+
+func TestMeter_UnmeteredContext(t *testing.T) {
+	meter := FromContext(context.Background())
+	assert.Nil(t, meter)
+
+	// Recording on a missing meter is a no-op
+	meter.AddTwitter(1, 100)
+	meter.AddOpenAI(200, 1)
+	assert.Equal(t, Usage{}, meter.Usage())
+}
+
+func TestPrices_Estimate(t *testing.T) {
+	prices := Prices{
+		TwitterPerRequest:           0.01,
+		TwitterPerPost:              0.005,
+		OpenAIPromptPer1KTokens:     0.00015,
+		OpenAICompletionPer1KTokens: 0.0006,
+	}
+
+	cost := prices.Estimate(Usage{
+		TwitterRequests:        4,
+		TwitterPosts:           250,
+		OpenAIRequests:         30,
+		OpenAIPromptTokens:     60000,
+		OpenAICompletionTokens: 5000,
+	})
+
+	assert.Equal(t, 1.29, cost.Twitter)
+	assert.Equal(t, 0.012, cost.OpenAI)
+	assert.Equal(t, 1.302, cost.Total)
+	assert.Equal(t, Cost{Twitter: 2.58, OpenAI: 0.024, Total: 2.604}, cost.Add(cost))
+}
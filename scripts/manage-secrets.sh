@@ -85,7 +85,7 @@ test_secret_access() {
     echo ""
     echo "🧪 Testing secret access..."
     
-    local secrets=("teams-webhook-url" "reddit-client-id" "reddit-client-secret" "twitter-bearer-token" "youtube-api-key" "notification-email" "smtp-password")
+    local secrets=("teams-webhook-url" "reddit-client-id" "reddit-client-secret" "twitter-bearer-token" "youtube-api-key" "notification-email" "smtp-password" "api-token")
     
     for secret in "${secrets[@]}"; do
         if az keyvault secret show --vault-name "$KEYVAULT_NAME" --name "$secret" --query "value" -o tsv &>/dev/null; then
@@ -122,6 +122,7 @@ while true; do
             create_secret "youtube-api-key" "YouTube Data API v3 key" "AIzaSyXXXXXXXXXXXXXXXX"
             create_secret "notification-email" "Email for error notifications" "alerts@company.com"
             create_secret "smtp-password" "SMTP password for email" "your_smtp_password"
+            create_secret "api-token" "Token the HTTP API requires" "$(openssl rand -hex 24)"
             
             echo ""
             echo "✅ Secret creation process completed!"
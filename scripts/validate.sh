@@ -99,7 +99,7 @@ echo "🎉 Validation complete!"
 echo ""
 echo "Next steps:"
 echo "1. Edit .env file with your API keys and configuration"
-echo "2. Test locally: go run cmd/bot/main.go"
+echo "2. Test locally: go run ./cmd/bot serve"
 echo "3. Deploy to Azure: azd up"
 echo ""
 echo "For more information, see DEPLOYMENT_GUIDE.md"